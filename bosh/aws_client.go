@@ -3,15 +3,15 @@ package bosh
 import (
 	"fmt"
 	"io"
-	"net"
 
-	"github.com/EngineerBetter/concourse-up/bosh/internal/boshcli"
-	"github.com/EngineerBetter/concourse-up/bosh/internal/workingdir"
+	"github.com/EngineerBetter/concourse-up/bosh/boshcli"
+	"github.com/EngineerBetter/concourse-up/bosh/postgres"
+	"github.com/EngineerBetter/concourse-up/bosh/workingdir"
 	"github.com/EngineerBetter/concourse-up/config"
 	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/jumpbox"
 	"github.com/EngineerBetter/concourse-up/terraform"
-	"github.com/lib/pq"
-	"golang.org/x/crypto/ssh"
+	"golang.org/x/net/proxy"
 )
 
 //AWSClient is an AWS specific implementation of IClient
@@ -24,46 +24,29 @@ type AWSClient struct {
 	stderr     io.Writer
 	provider   iaas.Provider
 	boshCLI    boshcli.ICLI
+	postgres   postgres.ICLI
+	// tunnel reaches the VMs of private deployments through the jumpbox, and is nil otherwise
+	tunnel *jumpbox.Tunnel
 }
 
-//NewAWSClient returns a AWS specific implementation of IClient
-func NewAWSClient(config config.Config, outputs terraform.Outputs, workingdir workingdir.IClient, stdout, stderr io.Writer, provider iaas.Provider, boshCLI boshcli.ICLI) (IClient, error) {
-	directorPublicIP, err := outputs.Get("DirectorPublicIP")
-	if err != nil {
-		return nil, fmt.Errorf("failed to get DirectorPublicIP from terraform outputs: [%v]", err)
-	}
-	addr := net.JoinHostPort(directorPublicIP, "22")
-	key, err := ssh.ParsePrivateKey([]byte(config.PrivateKey))
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse private key for bosh: [%v]", err)
-	}
-	conf := &ssh.ClientConfig{
-		User:            "vcap",
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-		Auth:            []ssh.AuthMethod{ssh.PublicKeys(key)},
-	}
-	var boshDBAddress, boshDBPort string
+func init() {
+	Register(iaas.AWS, IAAS{
+		NewClient:         NewAWSClient,
+		ConcourseVersions: awsConcourseVersions,
+		ConcourseSHAs:     awsConcourseSHAs,
+	})
+}
 
-	boshDBAddress, err = outputs.Get("BoshDBAddress")
-	if err != nil {
-		return nil, fmt.Errorf("failed to get BoshDBAddress from terraform outputs: [%v]", err)
-	}
-	boshDBPort, err = outputs.Get("BoshDBPort")
+//NewAWSClient returns a AWS specific implementation of IClient
+func NewAWSClient(config config.Config, outputs terraform.Outputs, workingdir workingdir.IClient, stdout, stderr io.Writer, provider iaas.Provider, boshCLI boshcli.ICLI, postgres postgres.ICLI, tunnel *jumpbox.Tunnel) (IClient, error) {
+	boshDBPort, err := outputs.Get("BoshDBPort")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get BoshDBPort from terraform outputs: [%v]", err)
 	}
 
-	db, err := newProxyOpener(addr, conf, &pq.Driver{},
-		fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=require",
-			config.RDSUsername,
-			config.RDSPassword,
-			boshDBAddress,
-			boshDBPort,
-			config.RDSDefaultDatabaseName,
-		),
-	)
+	db, err := newDirectorDBOpener(config, outputs, boshDBPort, tunnel)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create db proxyOpener: [%v]", err)
+		return nil, err
 	}
 
 	return &AWSClient{
@@ -75,10 +58,21 @@ func NewAWSClient(config config.Config, outputs terraform.Outputs, workingdir wo
 		stderr:     stderr,
 		provider:   provider,
 		boshCLI:    boshCLI,
+		postgres:   postgres,
+		tunnel:     tunnel,
 	}, nil
 }
 
 //Cleanup is AWS specific implementation of Cleanup
 func (client *AWSClient) Cleanup() error {
+	client.tunnel.Close()
 	return client.workingdir.Cleanup()
 }
+
+// dialer connects to the VMs of the deployment, through the jumpbox when it is private
+func (client *AWSClient) dialer() proxy.Dialer {
+	if client.tunnel == nil {
+		return proxy.Direct
+	}
+	return client.tunnel
+}
@@ -12,6 +12,10 @@ import (
 
 	"strings"
 
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/jumpbox"
+	"github.com/EngineerBetter/concourse-up/terraform"
+	"github.com/lib/pq"
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/net/proxy"
 )
@@ -19,9 +23,13 @@ import (
 // Opener will open new connections to a given database name.
 type Opener interface {
 	Open(name string) (*sql.DB, error)
+	URI(name string) (string, error)
 	Close() error
 }
 
+// defaultDatabaseNames are the databases created for Concourse, UAA and CredHub alongside the BOSH database
+var defaultDatabaseNames = []string{"concourse_atc", "uaa", "credhub"}
+
 type proxyOpener struct {
 	baseURI string
 	d       driver.Driver
@@ -30,26 +38,34 @@ type proxyOpener struct {
 }
 
 func (p *proxyOpener) Open(dbName string) (*sql.DB, error) {
-	p.start()
-	u, err := url.Parse(p.baseURI)
+	newURI, err := p.URI(dbName)
 	if err != nil {
 		return nil, err
 	}
-	u.Path = dbName
-	u.Host = p.l.Addr().String()
-	newURI := u.String()
 	connector := connectorFunc(func(_ context.Context) (driver.Conn, error) {
 		return p.d.Open(newURI)
 	})
 	return sql.OpenDB(connector), nil
 }
 
+// URI returns a connection string for dbName which goes through the tunnel
+func (p *proxyOpener) URI(dbName string) (string, error) {
+	p.start()
+	u, err := url.Parse(p.baseURI)
+	if err != nil {
+		return "", err
+	}
+	u.Path = dbName
+	u.Host = p.l.Addr().String()
+	return u.String(), nil
+}
+
 func (p *proxyOpener) Close() error {
 	p.start()
 	return p.l.Close()
 }
 
-func newProxyOpener(jumpboxAddr string, config *ssh.ClientConfig, d driver.Driver, uri string) (Opener, error) {
+func newProxyOpener(dial func() (proxy.Dialer, error), d driver.Driver, uri string) (Opener, error) {
 	u, err := url.Parse(uri)
 	if err != nil {
 		return nil, err
@@ -60,7 +76,7 @@ func newProxyOpener(jumpboxAddr string, config *ssh.ClientConfig, d driver.Drive
 	}
 	var startOnce sync.Once
 	f := func() {
-		p, err := ssh.Dial("tcp", jumpboxAddr, config)
+		p, err := dial()
 		if err != nil {
 			return //TODO: handle
 		}
@@ -84,6 +100,52 @@ func newProxyOpener(jumpboxAddr string, config *ssh.ClientConfig, d driver.Drive
 	}, nil
 }
 
+// newDirectorDBOpener returns an Opener for the BOSH database instance which tunnels through the director,
+// or through the jumpbox when tunnel is not nil
+func newDirectorDBOpener(config config.Config, outputs terraform.Outputs, dbPort string, tunnel *jumpbox.Tunnel) (Opener, error) {
+	dial := func() (proxy.Dialer, error) {
+		return tunnel, nil
+	}
+	if tunnel == nil {
+		directorPublicIP, err := outputs.Get("DirectorPublicIP")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get DirectorPublicIP from terraform outputs: [%v]", err)
+		}
+		addr := net.JoinHostPort(directorPublicIP, "22")
+		key, err := ssh.ParsePrivateKey([]byte(config.PrivateKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key for bosh: [%v]", err)
+		}
+		conf := &ssh.ClientConfig{
+			User:            "vcap",
+			HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(key)},
+		}
+		dial = func() (proxy.Dialer, error) {
+			return ssh.Dial("tcp", addr, conf)
+		}
+	}
+
+	boshDBAddress, err := outputs.Get("BoshDBAddress")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get BoshDBAddress from terraform outputs: [%v]", err)
+	}
+
+	db, err := newProxyOpener(dial, &pq.Driver{},
+		fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=require",
+			config.RDSUsername,
+			config.RDSPassword,
+			boshDBAddress,
+			dbPort,
+			config.RDSDefaultDatabaseName,
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create db proxyOpener: [%v]", err)
+	}
+	return db, nil
+}
+
 type connectorFunc func(context.Context) (driver.Conn, error)
 
 func (f connectorFunc) Connect(ctx context.Context) (driver.Conn, error) {
@@ -120,8 +182,7 @@ func (client *AWSClient) createDefaultDatabases() error {
 		return err
 	}
 	defer db.Close()
-	dbNames := []string{"concourse_atc", "uaa", "credhub"}
-	for _, dbName := range dbNames {
+	for _, dbName := range defaultDatabaseNames {
 		_, err := db.Exec("CREATE DATABASE " + dbName)
 		if err != nil && !strings.Contains(err.Error(),
 			fmt.Sprintf(`pq: database "%s" already exists`, dbName)) {
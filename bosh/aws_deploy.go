@@ -1,36 +1,36 @@
 package bosh
 
 import (
+	"fmt"
 	"net"
+	"strings"
 
+	"github.com/EngineerBetter/concourse-up/bosh/boshcli"
 	"github.com/EngineerBetter/concourse-up/bosh/internal/aws"
-	"github.com/EngineerBetter/concourse-up/bosh/internal/boshcli"
 	"github.com/EngineerBetter/concourse-up/db"
 	"github.com/apparentlymart/go-cidr/cidr"
 )
 
 // Deploy implements deploy for AWS client
 func (client *AWSClient) Deploy(state, creds []byte, detach bool) (newState, newCreds []byte, err error) {
-	state, creds, err = client.createEnv(client.boshCLI, state, creds, "")
-	if err != nil {
-		return state, creds, err
-	}
-
-	if err = client.updateCloudConfig(client.boshCLI); err != nil {
-		return state, creds, err
-	}
-	if err = client.uploadConcourseStemcell(client.boshCLI); err != nil {
-		return state, creds, err
-	}
-	if err = client.createDefaultDatabases(); err != nil {
-		return state, creds, err
-	}
-
-	creds, err = client.deployConcourse(creds, detach)
-	if err != nil {
-		return state, creds, err
-	}
+	return client.DeployFrom(state, creds, detach, "")
+}
 
+// DeployFrom implements deploy for AWS client, skipping the phases before from
+func (client *AWSClient) DeployFrom(state, creds []byte, detach bool, from string) (newState, newCreds []byte, err error) {
+	err = runPhases(from, []phase{
+		{PhaseCreateEnv, func() error {
+			state, creds, err = client.createEnv(client.boshCLI, state, creds, "")
+			return err
+		}},
+		{PhaseCloudConfig, func() error { return client.updateCloudConfig(client.boshCLI) }},
+		{PhaseStemcell, func() error { return client.uploadConcourseStemcell(client.boshCLI) }},
+		{PhaseDatabases, client.createDefaultDatabases},
+		{PhaseDeploy, func() error {
+			creds, err = client.deployConcourse(creds, detach)
+			return err
+		}},
+	})
 	return state, creds, err
 }
 
@@ -132,22 +132,26 @@ func (client *AWSClient) createEnv(bosh boshcli.ICLI, state, creds []byte, custo
 		return state, creds, err1
 	}
 
-	publicCIDR := client.config.PublicCIDR
-	_, pubCIDR, err1 := net.ParseCIDR(publicCIDR)
+	// The director of a private deployment lives in the private subnet, and is reached through the jumpbox
+	internalCIDR := client.config.PublicCIDR
+	if client.config.Private {
+		internalCIDR = client.config.PrivateCIDR
+	}
+	_, parsedInternalCIDR, err1 := net.ParseCIDR(internalCIDR)
 	if err1 != nil {
 		return state, creds, err1
 	}
-	internalGateway, err1 := cidr.Host(pubCIDR, 1)
+	internalGateway, err1 := cidr.Host(parsedInternalCIDR, 1)
 	if err1 != nil {
 		return state, creds, err1
 	}
-	directorInternalIP, err1 := cidr.Host(pubCIDR, 6)
+	directorInternalIP, err1 := cidr.Host(parsedInternalCIDR, 6)
 	if err1 != nil {
 		return state, creds, err1
 	}
 
 	err1 = bosh.CreateEnv(store, aws.Environment{
-		InternalCIDR:    client.config.PublicCIDR,
+		InternalCIDR:    internalCIDR,
 		InternalGateway: internalGateway.String(),
 		InternalIP:      directorInternalIP.String(),
 		AccessKeyID:     boshUserAccessKeyID,
@@ -162,6 +166,7 @@ func (client *AWSClient) createEnv(bosh boshcli.ICLI, state, creds []byte, custo
 		PrivateKey:           client.config.PrivateKey,
 		PublicSubnetID:       publicSubnetID,
 		PrivateSubnetID:      privateSubnetID,
+		Private:              client.config.Private,
 		ExternalIP:           directorPublicIP,
 		ATCSecurityGroup:     atcSecurityGroupID,
 		VMSecurityGroup:      vmSecurityGroupID,
@@ -185,62 +190,98 @@ func (client *AWSClient) createEnv(bosh boshcli.ICLI, state, creds []byte, custo
 }
 
 func (client *AWSClient) updateCloudConfig(bosh boshcli.ICLI) error {
-	publicSubnetID, err := client.outputs.Get("PublicSubnetID")
+	env, err := client.cloudConfigEnvironment()
 	if err != nil {
 		return err
 	}
+	return bosh.UpdateCloudConfig(env, env.ExternalIP, client.config.DirectorPassword, client.config.DirectorCACert)
+}
+
+// Diff reports the changes a deploy would make to the cloud config and the Concourse deployment
+func (client *AWSClient) Diff(creds []byte) (Diff, error) {
+	var diff Diff
+	env, err := client.cloudConfigEnvironment()
+	if err != nil {
+		return diff, err
+	}
+	diff.CloudConfig, err = client.boshCLI.DiffCloudConfig(env, env.ExternalIP, client.config.DirectorPassword, client.config.DirectorCACert)
+	if err != nil {
+		return diff, err
+	}
+	diff.Concourse, err = client.diffConcourse(creds)
+	return diff, err
+}
+
+func (client *AWSClient) cloudConfigEnvironment() (aws.Environment, error) {
+	publicSubnetID, err := client.outputs.Get("PublicSubnetID")
+	if err != nil {
+		return aws.Environment{}, err
+	}
 	privateSubnetID, err := client.outputs.Get("PrivateSubnetID")
 	if err != nil {
-		return err
+		return aws.Environment{}, err
 	}
 	aTCSecurityGroupID, err := client.outputs.Get("ATCSecurityGroupID")
 	if err != nil {
-		return err
+		return aws.Environment{}, err
 	}
 	vMsSecurityGroupID, err := client.outputs.Get("VMsSecurityGroupID")
 	if err != nil {
-		return err
+		return aws.Environment{}, err
 	}
 	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
 	if err != nil {
-		return err
+		return aws.Environment{}, err
 	}
 
 	publicCIDR := client.config.PublicCIDR
 	_, pubCIDR, err := net.ParseCIDR(publicCIDR)
 	if err != nil {
-		return err
+		return aws.Environment{}, err
 	}
 	pubGateway, err := cidr.Host(pubCIDR, 1)
 	if err != nil {
-		return err
+		return aws.Environment{}, err
 	}
 	publicCIDRGateway := pubGateway.String()
 	publicCIDRStatic, err := formatIPRange(publicCIDR, ", ", []int{6, 7})
 	if err != nil {
-		return err
+		return aws.Environment{}, err
 	}
 	publicCIDRReserved, err := formatIPRange(publicCIDR, "-", []int{1, 5})
 	if err != nil {
-		return err
+		return aws.Environment{}, err
 	}
 
 	privateCIDR := client.config.PrivateCIDR
 	_, privCIDR, err := net.ParseCIDR(privateCIDR)
 	if err != nil {
-		return err
+		return aws.Environment{}, err
 	}
 	privGateway, err := cidr.Host(privCIDR, 1)
 	if err != nil {
-		return err
+		return aws.Environment{}, err
 	}
 	privateCIDRGateway := privGateway.String()
 	privateCIDRReserved, err := formatIPRange(privateCIDR, "-", []int{1, 5})
 	if err != nil {
-		return err
+		return aws.Environment{}, err
+	}
+	// The director and web node of a private deployment use the addresses the public ones would
+	var privateCIDRStatic string
+	if client.config.Private {
+		privateCIDRStatic, err = formatIPRange(privateCIDR, ", ", []int{6, 7})
+		if err != nil {
+			return aws.Environment{}, err
+		}
+	}
+
+	extraZones, err := client.extraZones()
+	if err != nil {
+		return aws.Environment{}, err
 	}
 
-	return bosh.UpdateCloudConfig(aws.Environment{
+	return aws.Environment{
 		AZ:                  client.config.AvailabilityZone,
 		PublicSubnetID:      publicSubnetID,
 		PrivateSubnetID:     privateSubnetID,
@@ -256,7 +297,49 @@ func (client *AWSClient) updateCloudConfig(bosh boshcli.ICLI) error {
 		PrivateCIDR:         privateCIDR,
 		PrivateCIDRGateway:  privateCIDRGateway,
 		PrivateCIDRReserved: privateCIDRReserved,
-	}, directorPublicIP, client.config.DirectorPassword, client.config.DirectorCACert)
+		PrivateCIDRStatic:   privateCIDRStatic,
+		ExtraZones:          extraZones,
+	}, nil
+}
+
+// extraZones returns the zones workers are spread across besides the availability zone, each with its own private subnet
+func (client *AWSClient) extraZones() ([]aws.Zone, error) {
+	if len(client.config.ZonePrivateCIDRs) == 0 {
+		return nil, nil
+	}
+	subnetIDs, err := client.outputs.Get("ZonePrivateSubnetIDs")
+	if err != nil {
+		return nil, err
+	}
+	ids := strings.Split(subnetIDs, ",")
+	if len(ids) != len(client.config.ZonePrivateCIDRs) || len(client.config.Zones) != len(ids)+1 {
+		return nil, fmt.Errorf("expected the private subnets of %d zones but terraform created %d", len(client.config.Zones)-1, len(ids))
+	}
+
+	var zones []aws.Zone
+	for i, privateCIDR := range client.config.ZonePrivateCIDRs {
+		_, privCIDR, err := net.ParseCIDR(privateCIDR)
+		if err != nil {
+			return nil, err
+		}
+		privGateway, err := cidr.Host(privCIDR, 1)
+		if err != nil {
+			return nil, err
+		}
+		privateCIDRReserved, err := formatIPRange(privateCIDR, "-", []int{1, 5})
+		if err != nil {
+			return nil, err
+		}
+		zones = append(zones, aws.Zone{
+			Name:                zoneName(i + 1),
+			AvailabilityZone:    client.config.Zones[i+1],
+			PrivateCIDR:         privateCIDR,
+			PrivateCIDRGateway:  privGateway.String(),
+			PrivateCIDRReserved: privateCIDRReserved,
+			PrivateSubnetID:     ids[i],
+		})
+	}
+	return zones, nil
 }
 func (client *AWSClient) uploadConcourseStemcell(bosh boshcli.ICLI) error {
 	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
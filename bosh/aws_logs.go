@@ -0,0 +1,10 @@
+package bosh
+
+// Logs tails or downloads the logs of the Concourse deployment
+func (client *AWSClient) Logs(opts LogsOptions) error {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return err
+	}
+	return logs(client.boshCLI, directorPublicIP, client.config.DirectorPassword, client.config.DirectorCACert, client.stdout, opts)
+}
@@ -0,0 +1,28 @@
+package bosh
+
+import "os"
+
+const awsGatewayUser = "vcap"
+
+// SSH opens an interactive session on a Concourse VM, or on the director when instance is "director"
+func (client *AWSClient) SSH(instance string) error {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return err
+	}
+
+	if instance == DirectorInstance {
+		return sshDirector(client.dialer(), directorPublicIP, awsGatewayUser, client.config.PrivateKey, os.Stdin, os.Stdout, os.Stderr)
+	}
+
+	return sshInstance(
+		client.boshCLI,
+		client.workingdir,
+		directorPublicIP,
+		client.config.DirectorPassword,
+		client.config.DirectorCACert,
+		awsGatewayUser,
+		client.config.PrivateKey,
+		instance,
+	)
+}
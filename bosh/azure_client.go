@@ -0,0 +1,70 @@
+package bosh
+
+import (
+	"io"
+
+	"github.com/EngineerBetter/concourse-up/bosh/boshcli"
+	"github.com/EngineerBetter/concourse-up/bosh/postgres"
+	"github.com/EngineerBetter/concourse-up/bosh/workingdir"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/jumpbox"
+	"github.com/EngineerBetter/concourse-up/terraform"
+)
+
+//AzureClient is an Azure specific implementation of IClient
+type AzureClient struct {
+	config     config.Config
+	outputs    terraform.Outputs
+	workingdir workingdir.IClient
+	db         Opener
+	stdout     io.Writer
+	stderr     io.Writer
+	provider   iaas.Provider
+	boshCLI    boshcli.ICLI
+	postgres   postgres.ICLI
+}
+
+func init() {
+	Register(iaas.Azure, IAAS{
+		NewClient: func(config config.Config, outputs terraform.Outputs, workingdir workingdir.IClient, stdout, stderr io.Writer, provider iaas.Provider, boshCLI boshcli.ICLI, postgres postgres.ICLI, _ *jumpbox.Tunnel) (IClient, error) {
+			return NewAzureClient(config, outputs, workingdir, stdout, stderr, provider, boshCLI, postgres)
+		},
+		ConcourseVersions: azureConcourseVersions,
+		ConcourseSHAs:     azureConcourseSHAs,
+	})
+}
+
+//NewAzureClient returns an Azure specific implementation of IClient
+func NewAzureClient(config config.Config, outputs terraform.Outputs, workingdir workingdir.IClient, stdout, stderr io.Writer, provider iaas.Provider, boshCLI boshcli.ICLI, postgres postgres.ICLI) (IClient, error) {
+	// The database server only accepts connections from its firewall rules, which include the director
+	dbConfig := config
+	dbConfig.RDSUsername = azureDBRole(config)
+	db, err := newDirectorDBOpener(dbConfig, outputs, "5432", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AzureClient{
+		config:     config,
+		outputs:    outputs,
+		workingdir: workingdir,
+		db:         db,
+		stdout:     stdout,
+		stderr:     stderr,
+		provider:   provider,
+		boshCLI:    boshCLI,
+		postgres:   postgres,
+	}, nil
+}
+
+// azureDBRole returns the user name clients log into Azure Database for PostgreSQL with,
+// which names the server as well as the user
+func azureDBRole(config config.Config) string {
+	return config.RDSUsername + "@" + config.RDSDefaultDatabaseName
+}
+
+//Cleanup is Azure specific implementation of Cleanup
+func (client *AzureClient) Cleanup() error {
+	return client.workingdir.Cleanup()
+}
@@ -0,0 +1,24 @@
+package bosh
+
+import (
+	"fmt"
+	"strings"
+)
+
+// createDefaultDatabases creates the databases through the director, as RDSDefaultDatabaseName names
+// the Azure Database for PostgreSQL server rather than a database on it
+func (client *AzureClient) createDefaultDatabases() error {
+	db, err := client.db.Open("postgres")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	for _, dbName := range defaultDatabaseNames {
+		_, err := db.Exec("CREATE DATABASE " + dbName)
+		if err != nil && !strings.Contains(err.Error(),
+			fmt.Sprintf(`pq: database "%s" already exists`, dbName)) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package bosh
+
+import (
+	"fmt"
+	"os"
+)
+
+// Delete deletes a bosh director
+func (client *AzureClient) Delete(stateFileBytes []byte) ([]byte, error) {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve director IP: [%v]", err)
+	}
+
+	if err = client.boshCLI.RunAuthenticatedCommand(
+		"delete-deployment",
+		directorPublicIP,
+		client.config.DirectorPassword,
+		client.config.DirectorCACert,
+		false,
+		os.Stdout,
+		"--force",
+	); err != nil {
+		return nil, err
+	}
+
+	store := temporaryStore{
+		"state.json": stateFileBytes,
+	}
+	env, err := client.directorEnvironment("")
+	if err != nil {
+		return store["state.json"], err
+	}
+	err = client.boshCLI.DeleteEnv(store, env, client.config.DirectorPassword, client.config.DirectorCert, client.config.DirectorKey, client.config.DirectorCACert, nil)
+	return store["state.json"], err
+}
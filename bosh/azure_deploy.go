@@ -0,0 +1,265 @@
+package bosh
+
+import (
+	"net"
+
+	"github.com/EngineerBetter/concourse-up/bosh/boshcli"
+	"github.com/EngineerBetter/concourse-up/bosh/internal/azure"
+	"github.com/apparentlymart/go-cidr/cidr"
+)
+
+// Deploy deploys a new Bosh director or converges an existing deployment
+// Returns new contents of bosh state file
+func (client *AzureClient) Deploy(state, creds []byte, detach bool) (newState, newCreds []byte, err error) {
+	return client.DeployFrom(state, creds, detach, "")
+}
+
+// DeployFrom deploys like Deploy, skipping the phases before from
+func (client *AzureClient) DeployFrom(state, creds []byte, detach bool, from string) (newState, newCreds []byte, err error) {
+	boshCLI, err := boshcli.New(boshcli.DownloadBOSH())
+	if err != nil {
+		return state, creds, err
+	}
+
+	err = runPhases(from, []phase{
+		{PhaseCreateEnv, func() error {
+			state, creds, err = client.createEnv(boshCLI, state, creds, "")
+			return err
+		}},
+		{PhaseCloudConfig, func() error { return client.updateCloudConfig(boshCLI) }},
+		{PhaseStemcell, func() error { return client.uploadConcourseStemcell(boshCLI) }},
+		{PhaseDatabases, client.createDefaultDatabases},
+		{PhaseDeploy, func() error {
+			creds, err = client.deployConcourse(creds, detach)
+			return err
+		}},
+	})
+	return state, creds, err
+}
+
+// CreateEnv exposes bosh create-env functionality
+func (client *AzureClient) CreateEnv(state, creds []byte, customOps string) (newState, newCreds []byte, err error) {
+	return client.createEnv(client.boshCLI, state, creds, customOps)
+}
+
+// Recreate exposes BOSH recreate
+func (client *AzureClient) Recreate() error {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return err
+	}
+	return client.boshCLI.Recreate(azure.Environment{
+		ExternalIP: directorPublicIP,
+	}, directorPublicIP, client.config.DirectorPassword, client.config.DirectorCACert)
+}
+
+func (client *AzureClient) createEnv(bosh boshcli.ICLI, state, creds []byte, customOps string) (newState, newCreds []byte, err error) {
+	tags, err := splitTags(client.config.Tags)
+	if err != nil {
+		return state, creds, err
+	}
+	tags["concourse-up-project"] = client.config.Project
+	tags["concourse-up-component"] = "concourse"
+	store := temporaryStore{
+		"vars.yaml":  creds,
+		"state.json": state,
+	}
+
+	env, err := client.directorEnvironment(customOps)
+	if err != nil {
+		return state, creds, err
+	}
+	err = bosh.CreateEnv(store, env, client.config.DirectorPassword, client.config.DirectorCert, client.config.DirectorKey, client.config.DirectorCACert, tags)
+	return store["state.json"], store["vars.yaml"], err
+}
+
+// directorEnvironment returns the environment create-env and delete-env deploy the director with
+func (client *AzureClient) directorEnvironment(customOps string) (azure.Environment, error) {
+	resourceGroup, err := client.outputs.Get("ResourceGroup")
+	if err != nil {
+		return azure.Environment{}, err
+	}
+	network, err := client.outputs.Get("Network")
+	if err != nil {
+		return azure.Environment{}, err
+	}
+	publicSubnet, err := client.outputs.Get("PublicSubnetName")
+	if err != nil {
+		return azure.Environment{}, err
+	}
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return azure.Environment{}, err
+	}
+	directorSecurityGroup, err := client.outputs.Get("DirectorSecurityGroupName")
+	if err != nil {
+		return azure.Environment{}, err
+	}
+	vmsSecurityGroup, err := client.outputs.Get("VMsSecurityGroupName")
+	if err != nil {
+		return azure.Environment{}, err
+	}
+	subscriptionID, err := client.provider.Attr("subscription_id")
+	if err != nil {
+		return azure.Environment{}, err
+	}
+	tenantID, err := client.provider.Attr("tenant_id")
+	if err != nil {
+		return azure.Environment{}, err
+	}
+	clientID, err := client.provider.Attr("client_id")
+	if err != nil {
+		return azure.Environment{}, err
+	}
+	clientSecret, err := client.provider.Attr("client_secret")
+	if err != nil {
+		return azure.Environment{}, err
+	}
+
+	_, pubCIDR, err := net.ParseCIDR(client.config.PublicCIDR)
+	if err != nil {
+		return azure.Environment{}, err
+	}
+	internalGateway, err := cidr.Host(pubCIDR, 1)
+	if err != nil {
+		return azure.Environment{}, err
+	}
+	directorInternalIP, err := cidr.Host(pubCIDR, 6)
+	if err != nil {
+		return azure.Environment{}, err
+	}
+
+	return azure.Environment{
+		ClientID:              clientID,
+		ClientSecret:          clientSecret,
+		CustomOperations:      customOps,
+		DefaultSecurityGroup:  vmsSecurityGroup,
+		DirectorSecurityGroup: directorSecurityGroup,
+		ExternalIP:            directorPublicIP,
+		InternalCIDR:          client.config.PublicCIDR,
+		InternalGW:            internalGateway.String(),
+		InternalIP:            directorInternalIP.String(),
+		Network:               network,
+		PrivateKey:            client.config.PrivateKey,
+		PublicKey:             client.config.PublicKey,
+		PublicSubnet:          publicSubnet,
+		ResourceGroup:         resourceGroup,
+		SubscriptionID:        subscriptionID,
+		TenantID:              tenantID,
+	}, nil
+}
+
+// Locks implements locks for Azure client
+func (client *AzureClient) Locks() ([]byte, error) {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return nil, err
+	}
+	return client.boshCLI.Locks(azure.Environment{
+		ExternalIP: directorPublicIP,
+	}, directorPublicIP, client.config.DirectorPassword, client.config.DirectorCACert)
+}
+
+func (client *AzureClient) updateCloudConfig(bosh boshcli.ICLI) error {
+	env, err := client.cloudConfigEnvironment()
+	if err != nil {
+		return err
+	}
+	return bosh.UpdateCloudConfig(env, env.ExternalIP, client.config.DirectorPassword, client.config.DirectorCACert)
+}
+
+// Diff reports the changes a deploy would make to the cloud config and the Concourse deployment
+func (client *AzureClient) Diff(creds []byte) (Diff, error) {
+	var diff Diff
+	env, err := client.cloudConfigEnvironment()
+	if err != nil {
+		return diff, err
+	}
+	diff.CloudConfig, err = client.boshCLI.DiffCloudConfig(env, env.ExternalIP, client.config.DirectorPassword, client.config.DirectorCACert)
+	if err != nil {
+		return diff, err
+	}
+	diff.Concourse, err = client.diffConcourse(creds)
+	return diff, err
+}
+
+func (client *AzureClient) cloudConfigEnvironment() (azure.Environment, error) {
+	atcSecurityGroup, err := client.outputs.Get("ATCSecurityGroupName")
+	if err != nil {
+		return azure.Environment{}, err
+	}
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return azure.Environment{}, err
+	}
+	network, err := client.outputs.Get("Network")
+	if err != nil {
+		return azure.Environment{}, err
+	}
+	privateSubnet, err := client.outputs.Get("PrivateSubnetName")
+	if err != nil {
+		return azure.Environment{}, err
+	}
+	publicSubnet, err := client.outputs.Get("PublicSubnetName")
+	if err != nil {
+		return azure.Environment{}, err
+	}
+
+	publicCIDR := client.config.PublicCIDR
+	_, pubCIDR, err := net.ParseCIDR(publicCIDR)
+	if err != nil {
+		return azure.Environment{}, err
+	}
+	pubGateway, err := cidr.Host(pubCIDR, 1)
+	if err != nil {
+		return azure.Environment{}, err
+	}
+	publicCIDRStatic, err := formatIPRange(publicCIDR, ", ", []int{6, 7})
+	if err != nil {
+		return azure.Environment{}, err
+	}
+	publicCIDRReserved, err := formatIPRange(publicCIDR, "-", []int{1, 5})
+	if err != nil {
+		return azure.Environment{}, err
+	}
+
+	privateCIDR := client.config.PrivateCIDR
+	_, privCIDR, err := net.ParseCIDR(privateCIDR)
+	if err != nil {
+		return azure.Environment{}, err
+	}
+	privGateway, err := cidr.Host(privCIDR, 1)
+	if err != nil {
+		return azure.Environment{}, err
+	}
+	privateCIDRReserved, err := formatIPRange(privateCIDR, "-", []int{1, 5})
+	if err != nil {
+		return azure.Environment{}, err
+	}
+
+	return azure.Environment{
+		ATCSecurityGroup:    atcSecurityGroup,
+		ExternalIP:          directorPublicIP,
+		Network:             network,
+		PrivateCIDR:         privateCIDR,
+		PrivateCIDRGateway:  privGateway.String(),
+		PrivateCIDRReserved: privateCIDRReserved,
+		PrivateSubnet:       privateSubnet,
+		PublicCIDR:          publicCIDR,
+		PublicCIDRGateway:   pubGateway.String(),
+		PublicCIDRReserved:  publicCIDRReserved,
+		PublicCIDRStatic:    publicCIDRStatic,
+		PublicSubnet:        publicSubnet,
+		Zone:                client.provider.Zone(client.config.AvailabilityZone),
+	}, nil
+}
+
+func (client *AzureClient) uploadConcourseStemcell(bosh boshcli.ICLI) error {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return err
+	}
+	return bosh.UploadConcourseStemcell(azure.Environment{
+		ExternalIP: directorPublicIP,
+	}, directorPublicIP, client.config.DirectorPassword, client.config.DirectorCACert)
+}
@@ -0,0 +1,32 @@
+package bosh
+
+import (
+	"os"
+
+	"golang.org/x/net/proxy"
+)
+
+const azureGatewayUser = "vcap"
+
+// SSH opens an interactive session on a Concourse VM, or on the director when instance is "director"
+func (client *AzureClient) SSH(instance string) error {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return err
+	}
+
+	if instance == DirectorInstance {
+		return sshDirector(proxy.Direct, directorPublicIP, azureGatewayUser, client.config.PrivateKey, os.Stdin, os.Stdout, os.Stderr)
+	}
+
+	return sshInstance(
+		client.boshCLI,
+		client.workingdir,
+		directorPublicIP,
+		client.config.DirectorPassword,
+		client.config.DirectorCACert,
+		azureGatewayUser,
+		client.config.PrivateKey,
+		instance,
+	)
+}
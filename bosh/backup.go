@@ -0,0 +1,70 @@
+package bosh
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/EngineerBetter/concourse-up/bosh/boshcli"
+	"github.com/EngineerBetter/concourse-up/bosh/postgres"
+)
+
+// backupDatabases dumps each of the default databases, keyed by database name
+func backupDatabases(db Opener, pg postgres.ICLI) (map[string][]byte, error) {
+	dumps := make(map[string][]byte)
+	for _, name := range defaultDatabaseNames {
+		uri, err := db.URI(name)
+		if err != nil {
+			return nil, err
+		}
+		dump, err := pg.Dump(uri)
+		if err != nil {
+			return nil, fmt.Errorf("failed to back up database %s: [%v]", name, err)
+		}
+		dumps[name] = dump
+	}
+	return dumps, nil
+}
+
+// restoreDatabases replaces the contents of each database in dumps
+// The web instances are stopped for the duration so that nothing writes to the databases while they are replaced
+func restoreDatabases(db Opener, pg postgres.ICLI, boshCLI boshcli.ICLI, directorPublicIP, password, ca string, stdout io.Writer, dumps map[string][]byte) (err error) {
+	for name := range dumps {
+		if !isDefaultDatabase(name) {
+			return fmt.Errorf("unexpected database %q in backup", name)
+		}
+	}
+
+	if err = boshCLI.RunAuthenticatedCommand("stop", directorPublicIP, password, ca, false, stdout, "web"); err != nil {
+		return fmt.Errorf("failed to stop web instances before restore: [%v]", err)
+	}
+	defer func() {
+		err1 := boshCLI.RunAuthenticatedCommand("start", directorPublicIP, password, ca, false, stdout, "web")
+		if err == nil && err1 != nil {
+			err = fmt.Errorf("failed to start web instances after restore: [%v]", err1)
+		}
+	}()
+
+	for _, name := range defaultDatabaseNames {
+		dump, ok := dumps[name]
+		if !ok {
+			continue
+		}
+		uri, err := db.URI(name)
+		if err != nil {
+			return err
+		}
+		if err = pg.Restore(uri, dump); err != nil {
+			return fmt.Errorf("failed to restore database %s: [%v]", name, err)
+		}
+	}
+	return nil
+}
+
+func isDefaultDatabase(name string) bool {
+	for _, n := range defaultDatabaseNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package bosh
+
+import (
+	"errors"
+	"io"
+
+	"github.com/EngineerBetter/concourse-up/bosh/boshcli/boshclifakes"
+	"github.com/EngineerBetter/concourse-up/bosh/postgres/postgresfakes"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/terraform/terraformfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Backup and Restore", func() {
+	var client *AWSClient
+	var boshCLI *boshclifakes.FakeICLI
+	var postgresCLI *postgresfakes.FakeICLI
+	var actions []string
+
+	BeforeEach(func() {
+		actions = nil
+		boshCLI = &boshclifakes.FakeICLI{}
+		boshCLI.RunAuthenticatedCommandStub = func(action, ip, password, ca string, detach bool, stdout io.Writer, flags ...string) error {
+			actions = append(actions, action)
+			return nil
+		}
+		postgresCLI = &postgresfakes.FakeICLI{}
+		postgresCLI.DumpStub = func(uri string) ([]byte, error) {
+			return []byte("dump of " + uri), nil
+		}
+		postgresCLI.RestoreStub = func(uri string, dump []byte) error {
+			actions = append(actions, "restore "+uri)
+			return nil
+		}
+		outputs := &terraformfakes.FakeOutputs{}
+		outputs.GetReturns("99.99.99.99", nil)
+
+		client = &AWSClient{
+			config: config.Config{
+				DirectorCACert: "director-ca",
+				Secrets: config.Secrets{
+					DirectorPassword: "director-password",
+				},
+			},
+			outputs:  outputs,
+			db:       fakeOpener{"concourse_atc": nil, "uaa": nil, "credhub": nil},
+			stdout:   gbytes.NewBuffer(),
+			boshCLI:  boshCLI,
+			postgres: postgresCLI,
+		}
+	})
+
+	Describe("Backup", func() {
+		It("dumps every default database", func() {
+			dumps, err := client.Backup()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dumps).To(Equal(map[string][]byte{
+				"concourse_atc": []byte("dump of postgres://fake/concourse_atc"),
+				"uaa":           []byte("dump of postgres://fake/uaa"),
+				"credhub":       []byte("dump of postgres://fake/credhub"),
+			}))
+		})
+
+		It("names the database which failed", func() {
+			postgresCLI.DumpStub = nil
+			postgresCLI.DumpReturns(nil, errors.New("connection refused"))
+			_, err := client.Backup()
+			Expect(err).To(MatchError(ContainSubstring("concourse_atc")))
+		})
+	})
+
+	Describe("Restore", func() {
+		It("restores the databases while the web instances are stopped", func() {
+			err := client.Restore(map[string][]byte{
+				"credhub":       []byte("credhub dump"),
+				"concourse_atc": []byte("atc dump"),
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actions).To(Equal([]string{
+				"stop",
+				"restore postgres://fake/concourse_atc",
+				"restore postgres://fake/credhub",
+				"start",
+			}))
+
+			action, ip, password, ca, _, _, flags := boshCLI.RunAuthenticatedCommandArgsForCall(0)
+			Expect(action).To(Equal("stop"))
+			Expect(ip).To(Equal("99.99.99.99"))
+			Expect(password).To(Equal("director-password"))
+			Expect(ca).To(Equal("director-ca"))
+			Expect(flags).To(Equal([]string{"web"}))
+		})
+
+		It("starts the web instances again when a restore fails", func() {
+			postgresCLI.RestoreStub = nil
+			postgresCLI.RestoreReturns(errors.New("pg_restore failed"))
+			err := client.Restore(map[string][]byte{"uaa": []byte("uaa dump")})
+			Expect(err).To(MatchError(ContainSubstring("failed to restore database uaa")))
+			Expect(actions).To(Equal([]string{"stop", "start"}))
+		})
+
+		It("rejects unknown databases without stopping anything", func() {
+			err := client.Restore(map[string][]byte{"other": []byte("dump")})
+			Expect(err).To(MatchError(ContainSubstring(`unexpected database "other"`)))
+			Expect(actions).To(BeEmpty())
+		})
+	})
+})
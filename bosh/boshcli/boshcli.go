@@ -3,6 +3,7 @@ package boshcli
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -14,6 +15,8 @@ import (
 	"github.com/EngineerBetter/concourse-up/iaas"
 	"github.com/EngineerBetter/concourse-up/resource"
 	"github.com/EngineerBetter/concourse-up/util/yaml"
+	"github.com/pmezard/go-difflib/difflib"
+	yamlv2 "gopkg.in/yaml.v2"
 )
 
 //go:generate counterfeiter . ICLI
@@ -24,6 +27,7 @@ type ICLI interface {
 	Locks(config IAASEnvironment, ip, password, ca string) ([]byte, error)
 	Recreate(config IAASEnvironment, ip, password, ca string) error
 	UpdateCloudConfig(config IAASEnvironment, ip, password, ca string) error
+	DiffCloudConfig(config IAASEnvironment, ip, password, ca string) (string, error)
 	UploadConcourseStemcell(config IAASEnvironment, ip, password, ca string) error
 }
 
@@ -53,6 +57,22 @@ func DownloadBOSH() Option {
 	}
 }
 
+// AllProxy returns an Option which sends every connection the bosh-cli makes through the proxy at url
+func AllProxy(url string) Option {
+	return func(c *CLI) error {
+		execCmd := c.execCmd
+		c.execCmd = func(name string, args ...string) *exec.Cmd {
+			cmd := execCmd(name, args...)
+			if cmd.Env == nil {
+				cmd.Env = os.Environ()
+			}
+			cmd.Env = append(cmd.Env, "BOSH_ALL_PROXY="+url)
+			return cmd
+		}
+		return nil
+	}
+}
+
 // New provides a new CLI
 func New(ops ...Option) (ICLI, error) {
 	c := &CLI{
@@ -160,6 +180,69 @@ func (c *CLI) UpdateCloudConfig(config IAASEnvironment, ip, password, ca string)
 	return cmd.Run()
 }
 
+// DiffCloudConfig returns a unified diff between the director's current cloud config
+// and the one generated from config. It returns an empty string if they are the same
+func (c *CLI) DiffCloudConfig(config IAASEnvironment, ip, password, ca string) (string, error) {
+	cloudConfig, err := config.ConfigureDirectorCloudConfig()
+	if err != nil {
+		return "", err
+	}
+	caPath, err := writeTempFile([]byte(ca))
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(caPath)
+	ip = fmt.Sprintf("https://%s", ip)
+	var out bytes.Buffer
+	cmd := c.execCmd(c.boshPath, "--non-interactive", "--environment", ip, "--ca-cert", caPath, "--client", "admin", "--client-secret", password, "cloud-config", "--json")
+	cmd.Stderr = os.Stderr
+	cmd.Stdout = &out
+	if err = cmd.Run(); err != nil {
+		return "", err
+	}
+
+	var current struct {
+		Blocks []string `json:"Blocks"`
+	}
+	if err = json.Unmarshal(out.Bytes(), &current); err != nil {
+		return "", fmt.Errorf("failed to parse output of bosh cloud-config: [%v]", err)
+	}
+
+	return diffYAML(strings.Join(current.Blocks, ""), cloudConfig)
+}
+
+// diffYAML normalises both documents so that only semantic changes are reported
+func diffYAML(from, to string) (string, error) {
+	normalise := func(document string) (string, error) {
+		var v interface{}
+		if err := yamlv2.Unmarshal([]byte(document), &v); err != nil {
+			return "", err
+		}
+		out, err := yamlv2.Marshal(v)
+		return string(out), err
+	}
+
+	from, err := normalise(from)
+	if err != nil {
+		return "", err
+	}
+	to, err = normalise(to)
+	if err != nil {
+		return "", err
+	}
+	if from == to {
+		return "", nil
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(from),
+		B:        difflib.SplitLines(to),
+		FromFile: "current",
+		ToFile:   "proposed",
+		Context:  3,
+	})
+}
+
 // Locks runs bosh locks
 func (c *CLI) Locks(config IAASEnvironment, ip, password, ca string) ([]byte, error) {
 	var out bytes.Buffer
@@ -240,11 +323,16 @@ func (c *CLI) RunAuthenticatedCommand(action, ip, password, ca string, detach bo
 	if detach && action == "deploy" {
 		return c.detachedBoshCommand(stdout, flags...)
 	}
-	return c.boshCommand(stdout, flags...)
+	if action == "ssh" {
+		// ssh opens an interactive shell, so it is the only command which reads from the terminal
+		return c.boshCommand(os.Stdin, stdout, flags...)
+	}
+	return c.boshCommand(nil, stdout, flags...)
 }
 
-func (c *CLI) boshCommand(stdout io.Writer, flags ...string) error {
+func (c *CLI) boshCommand(stdin io.Reader, stdout io.Writer, flags ...string) error {
 	cmd := c.execCmd(c.boshPath, flags...)
+	cmd.Stdin = stdin
 	cmd.Stderr = os.Stderr
 	cmd.Stdout = stdout
 	return cmd.Run()
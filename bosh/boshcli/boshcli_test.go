@@ -3,11 +3,12 @@ package boshcli_test
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"strconv"
 	"strings"
 	"testing"
 
-	"github.com/EngineerBetter/concourse-up/bosh/internal/boshcli"
+	"github.com/EngineerBetter/concourse-up/bosh/boshcli"
 	"github.com/EngineerBetter/concourse-up/iaas"
 	"github.com/EngineerBetter/concourse-up/internal/fakeexec"
 	"github.com/stretchr/testify/require"
@@ -101,6 +102,38 @@ func TestCLI_UpdateCloudConfig(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestCLI_AllProxy(t *testing.T) {
+	var cmd *exec.Cmd
+	c, err := boshcli.New(boshcli.FakeExec(func(command string, args ...string) *exec.Cmd {
+		cmd = exec.Command(os.Args[0], "-test.run=TestExecCommandHelper")
+		cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
+		return cmd
+	}), boshcli.AllProxy("socks5://127.0.0.1:1080"))
+	require.NoError(t, err)
+	err = c.UpdateCloudConfig(mockIAASConfig{}, "ip", "password", "ca")
+	require.NoError(t, err)
+	require.Contains(t, cmd.Env, "GO_WANT_HELPER_PROCESS=1")
+	require.Contains(t, cmd.Env, "BOSH_ALL_PROXY=socks5://127.0.0.1:1080")
+}
+
+func TestCLI_RunAuthenticatedCommandStdin(t *testing.T) {
+	var cmd *exec.Cmd
+	c, err := boshcli.New(boshcli.FakeExec(func(command string, args ...string) *exec.Cmd {
+		cmd = exec.Command(os.Args[0], "-test.run=TestExecCommandHelper")
+		cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
+		return cmd
+	}))
+	require.NoError(t, err)
+
+	err = c.RunAuthenticatedCommand("instances", "ip", "password", "ca", false, os.Stdout)
+	require.NoError(t, err)
+	require.Nil(t, cmd.Stdin)
+
+	err = c.RunAuthenticatedCommand("ssh", "ip", "password", "ca", false, os.Stdout, "web/0")
+	require.NoError(t, err)
+	require.Equal(t, os.Stdin, cmd.Stdin)
+}
+
 func TestCLI_UploadConcourseStemcell(t *testing.T) {
 	e := fakeexec.New(t)
 	defer e.Finish()
@@ -121,3 +154,32 @@ func TestCLI_UploadConcourseStemcell(t *testing.T) {
 	require.NoError(t, err)
 
 }
+
+func TestCLI_DiffCloudConfig(t *testing.T) {
+	e := fakeexec.New(t)
+	defer e.Finish()
+	c, err := boshcli.New(boshcli.FakeExec(e.Cmd()))
+	require.NoError(t, err)
+	config := mockIAASConfig{}
+	e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+		require.Equal(t, "bosh", command)
+
+		require.Equal(t, "--non-interactive", args[0])
+		require.Equal(t, "https://ip", args[2])
+		require.Equal(t, "password", args[8])
+		require.Equal(t, "cloud-config", args[9])
+		require.Equal(t, "--json", args[10])
+	}).Outputs(`{"Blocks":["a Cloud Config\n"]}`)
+	e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+		require.Equal(t, "cloud-config", args[9])
+	}).Outputs(`{"Blocks":["an old Cloud Config\n"]}`)
+
+	diff, err := c.DiffCloudConfig(config, "ip", "password", "ca")
+	require.NoError(t, err)
+	require.Empty(t, diff)
+
+	diff, err = c.DiffCloudConfig(config, "ip", "password", "ca")
+	require.NoError(t, err)
+	require.Contains(t, diff, "-an old Cloud Config")
+	require.Contains(t, diff, "+a Cloud Config")
+}
@@ -5,7 +5,7 @@ import (
 	"io"
 	"sync"
 
-	"github.com/EngineerBetter/concourse-up/bosh/internal/boshcli"
+	"github.com/EngineerBetter/concourse-up/bosh/boshcli"
 )
 
 type FakeICLI struct {
@@ -43,6 +43,22 @@ type FakeICLI struct {
 	deleteEnvReturnsOnCall map[int]struct {
 		result1 error
 	}
+	DiffCloudConfigStub        func(boshcli.IAASEnvironment, string, string, string) (string, error)
+	diffCloudConfigMutex       sync.RWMutex
+	diffCloudConfigArgsForCall []struct {
+		arg1 boshcli.IAASEnvironment
+		arg2 string
+		arg3 string
+		arg4 string
+	}
+	diffCloudConfigReturns struct {
+		result1 string
+		result2 error
+	}
+	diffCloudConfigReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
 	LocksStub        func(boshcli.IAASEnvironment, string, string, string) ([]byte, error)
 	locksMutex       sync.RWMutex
 	locksArgsForCall []struct {
@@ -254,6 +270,72 @@ func (fake *FakeICLI) DeleteEnvReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeICLI) DiffCloudConfig(arg1 boshcli.IAASEnvironment, arg2 string, arg3 string, arg4 string) (string, error) {
+	fake.diffCloudConfigMutex.Lock()
+	ret, specificReturn := fake.diffCloudConfigReturnsOnCall[len(fake.diffCloudConfigArgsForCall)]
+	fake.diffCloudConfigArgsForCall = append(fake.diffCloudConfigArgsForCall, struct {
+		arg1 boshcli.IAASEnvironment
+		arg2 string
+		arg3 string
+		arg4 string
+	}{arg1, arg2, arg3, arg4})
+	fake.recordInvocation("DiffCloudConfig", []interface{}{arg1, arg2, arg3, arg4})
+	fake.diffCloudConfigMutex.Unlock()
+	if fake.DiffCloudConfigStub != nil {
+		return fake.DiffCloudConfigStub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.diffCloudConfigReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeICLI) DiffCloudConfigCallCount() int {
+	fake.diffCloudConfigMutex.RLock()
+	defer fake.diffCloudConfigMutex.RUnlock()
+	return len(fake.diffCloudConfigArgsForCall)
+}
+
+func (fake *FakeICLI) DiffCloudConfigCalls(stub func(boshcli.IAASEnvironment, string, string, string) (string, error)) {
+	fake.diffCloudConfigMutex.Lock()
+	defer fake.diffCloudConfigMutex.Unlock()
+	fake.DiffCloudConfigStub = stub
+}
+
+func (fake *FakeICLI) DiffCloudConfigArgsForCall(i int) (boshcli.IAASEnvironment, string, string, string) {
+	fake.diffCloudConfigMutex.RLock()
+	defer fake.diffCloudConfigMutex.RUnlock()
+	argsForCall := fake.diffCloudConfigArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeICLI) DiffCloudConfigReturns(result1 string, result2 error) {
+	fake.diffCloudConfigMutex.Lock()
+	defer fake.diffCloudConfigMutex.Unlock()
+	fake.DiffCloudConfigStub = nil
+	fake.diffCloudConfigReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeICLI) DiffCloudConfigReturnsOnCall(i int, result1 string, result2 error) {
+	fake.diffCloudConfigMutex.Lock()
+	defer fake.diffCloudConfigMutex.Unlock()
+	fake.DiffCloudConfigStub = nil
+	if fake.diffCloudConfigReturnsOnCall == nil {
+		fake.diffCloudConfigReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.diffCloudConfigReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeICLI) Locks(arg1 boshcli.IAASEnvironment, arg2 string, arg3 string, arg4 string) ([]byte, error) {
 	fake.locksMutex.Lock()
 	ret, specificReturn := fake.locksReturnsOnCall[len(fake.locksArgsForCall)]
@@ -582,6 +664,8 @@ func (fake *FakeICLI) Invocations() map[string][][]interface{} {
 	defer fake.createEnvMutex.RUnlock()
 	fake.deleteEnvMutex.RLock()
 	defer fake.deleteEnvMutex.RUnlock()
+	fake.diffCloudConfigMutex.RLock()
+	defer fake.diffCloudConfigMutex.RUnlock()
 	fake.locksMutex.RLock()
 	defer fake.locksMutex.RUnlock()
 	fake.recreateMutex.RLock()
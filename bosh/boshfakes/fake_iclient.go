@@ -8,6 +8,18 @@ import (
 )
 
 type FakeIClient struct {
+	BackupStub        func() (map[string][]byte, error)
+	backupMutex       sync.RWMutex
+	backupArgsForCall []struct {
+	}
+	backupReturns struct {
+		result1 map[string][]byte
+		result2 error
+	}
+	backupReturnsOnCall map[int]struct {
+		result1 map[string][]byte
+		result2 error
+	}
 	CleanupStub        func() error
 	cleanupMutex       sync.RWMutex
 	cleanupArgsForCall []struct {
@@ -65,6 +77,37 @@ type FakeIClient struct {
 		result2 []byte
 		result3 error
 	}
+	DeployFromStub        func([]byte, []byte, bool, string) ([]byte, []byte, error)
+	deployFromMutex       sync.RWMutex
+	deployFromArgsForCall []struct {
+		arg1 []byte
+		arg2 []byte
+		arg3 bool
+		arg4 string
+	}
+	deployFromReturns struct {
+		result1 []byte
+		result2 []byte
+		result3 error
+	}
+	deployFromReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 []byte
+		result3 error
+	}
+	DiffStub        func([]byte) (bosh.Diff, error)
+	diffMutex       sync.RWMutex
+	diffArgsForCall []struct {
+		arg1 []byte
+	}
+	diffReturns struct {
+		result1 bosh.Diff
+		result2 error
+	}
+	diffReturnsOnCall map[int]struct {
+		result1 bosh.Diff
+		result2 error
+	}
 	InstancesStub        func() ([]bosh.Instance, error)
 	instancesMutex       sync.RWMutex
 	instancesArgsForCall []struct {
@@ -89,6 +132,17 @@ type FakeIClient struct {
 		result1 []byte
 		result2 error
 	}
+	LogsStub        func(bosh.LogsOptions) error
+	logsMutex       sync.RWMutex
+	logsArgsForCall []struct {
+		arg1 bosh.LogsOptions
+	}
+	logsReturns struct {
+		result1 error
+	}
+	logsReturnsOnCall map[int]struct {
+		result1 error
+	}
 	RecreateStub        func() error
 	recreateMutex       sync.RWMutex
 	recreateArgsForCall []struct {
@@ -99,10 +153,87 @@ type FakeIClient struct {
 	recreateReturnsOnCall map[int]struct {
 		result1 error
 	}
+	RestoreStub        func(map[string][]byte) error
+	restoreMutex       sync.RWMutex
+	restoreArgsForCall []struct {
+		arg1 map[string][]byte
+	}
+	restoreReturns struct {
+		result1 error
+	}
+	restoreReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SSHStub        func(string) error
+	sSHMutex       sync.RWMutex
+	sSHArgsForCall []struct {
+		arg1 string
+	}
+	sSHReturns struct {
+		result1 error
+	}
+	sSHReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeIClient) Backup() (map[string][]byte, error) {
+	fake.backupMutex.Lock()
+	ret, specificReturn := fake.backupReturnsOnCall[len(fake.backupArgsForCall)]
+	fake.backupArgsForCall = append(fake.backupArgsForCall, struct {
+	}{})
+	fake.recordInvocation("Backup", []interface{}{})
+	fake.backupMutex.Unlock()
+	if fake.BackupStub != nil {
+		return fake.BackupStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.backupReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIClient) BackupCallCount() int {
+	fake.backupMutex.RLock()
+	defer fake.backupMutex.RUnlock()
+	return len(fake.backupArgsForCall)
+}
+
+func (fake *FakeIClient) BackupCalls(stub func() (map[string][]byte, error)) {
+	fake.backupMutex.Lock()
+	defer fake.backupMutex.Unlock()
+	fake.BackupStub = stub
+}
+
+func (fake *FakeIClient) BackupReturns(result1 map[string][]byte, result2 error) {
+	fake.backupMutex.Lock()
+	defer fake.backupMutex.Unlock()
+	fake.BackupStub = nil
+	fake.backupReturns = struct {
+		result1 map[string][]byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) BackupReturnsOnCall(i int, result1 map[string][]byte, result2 error) {
+	fake.backupMutex.Lock()
+	defer fake.backupMutex.Unlock()
+	fake.BackupStub = nil
+	if fake.backupReturnsOnCall == nil {
+		fake.backupReturnsOnCall = make(map[int]struct {
+			result1 map[string][]byte
+			result2 error
+		})
+	}
+	fake.backupReturnsOnCall[i] = struct {
+		result1 map[string][]byte
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeIClient) Cleanup() error {
 	fake.cleanupMutex.Lock()
 	ret, specificReturn := fake.cleanupReturnsOnCall[len(fake.cleanupArgsForCall)]
@@ -379,6 +510,153 @@ func (fake *FakeIClient) DeployReturnsOnCall(i int, result1 []byte, result2 []by
 	}{result1, result2, result3}
 }
 
+func (fake *FakeIClient) DeployFrom(arg1 []byte, arg2 []byte, arg3 bool, arg4 string) ([]byte, []byte, error) {
+	var arg1Copy []byte
+	if arg1 != nil {
+		arg1Copy = make([]byte, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	var arg2Copy []byte
+	if arg2 != nil {
+		arg2Copy = make([]byte, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.deployFromMutex.Lock()
+	ret, specificReturn := fake.deployFromReturnsOnCall[len(fake.deployFromArgsForCall)]
+	fake.deployFromArgsForCall = append(fake.deployFromArgsForCall, struct {
+		arg1 []byte
+		arg2 []byte
+		arg3 bool
+		arg4 string
+	}{arg1Copy, arg2Copy, arg3, arg4})
+	fake.recordInvocation("DeployFrom", []interface{}{arg1Copy, arg2Copy, arg3, arg4})
+	fake.deployFromMutex.Unlock()
+	if fake.DeployFromStub != nil {
+		return fake.DeployFromStub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	fakeReturns := fake.deployFromReturns
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeIClient) DeployFromCallCount() int {
+	fake.deployFromMutex.RLock()
+	defer fake.deployFromMutex.RUnlock()
+	return len(fake.deployFromArgsForCall)
+}
+
+func (fake *FakeIClient) DeployFromCalls(stub func([]byte, []byte, bool, string) ([]byte, []byte, error)) {
+	fake.deployFromMutex.Lock()
+	defer fake.deployFromMutex.Unlock()
+	fake.DeployFromStub = stub
+}
+
+func (fake *FakeIClient) DeployFromArgsForCall(i int) ([]byte, []byte, bool, string) {
+	fake.deployFromMutex.RLock()
+	defer fake.deployFromMutex.RUnlock()
+	argsForCall := fake.deployFromArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeIClient) DeployFromReturns(result1 []byte, result2 []byte, result3 error) {
+	fake.deployFromMutex.Lock()
+	defer fake.deployFromMutex.Unlock()
+	fake.DeployFromStub = nil
+	fake.deployFromReturns = struct {
+		result1 []byte
+		result2 []byte
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeIClient) DeployFromReturnsOnCall(i int, result1 []byte, result2 []byte, result3 error) {
+	fake.deployFromMutex.Lock()
+	defer fake.deployFromMutex.Unlock()
+	fake.DeployFromStub = nil
+	if fake.deployFromReturnsOnCall == nil {
+		fake.deployFromReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 []byte
+			result3 error
+		})
+	}
+	fake.deployFromReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 []byte
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeIClient) Diff(arg1 []byte) (bosh.Diff, error) {
+	var arg1Copy []byte
+	if arg1 != nil {
+		arg1Copy = make([]byte, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.diffMutex.Lock()
+	ret, specificReturn := fake.diffReturnsOnCall[len(fake.diffArgsForCall)]
+	fake.diffArgsForCall = append(fake.diffArgsForCall, struct {
+		arg1 []byte
+	}{arg1Copy})
+	fake.recordInvocation("Diff", []interface{}{arg1Copy})
+	fake.diffMutex.Unlock()
+	if fake.DiffStub != nil {
+		return fake.DiffStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.diffReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIClient) DiffCallCount() int {
+	fake.diffMutex.RLock()
+	defer fake.diffMutex.RUnlock()
+	return len(fake.diffArgsForCall)
+}
+
+func (fake *FakeIClient) DiffCalls(stub func([]byte) (bosh.Diff, error)) {
+	fake.diffMutex.Lock()
+	defer fake.diffMutex.Unlock()
+	fake.DiffStub = stub
+}
+
+func (fake *FakeIClient) DiffArgsForCall(i int) []byte {
+	fake.diffMutex.RLock()
+	defer fake.diffMutex.RUnlock()
+	argsForCall := fake.diffArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIClient) DiffReturns(result1 bosh.Diff, result2 error) {
+	fake.diffMutex.Lock()
+	defer fake.diffMutex.Unlock()
+	fake.DiffStub = nil
+	fake.diffReturns = struct {
+		result1 bosh.Diff
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) DiffReturnsOnCall(i int, result1 bosh.Diff, result2 error) {
+	fake.diffMutex.Lock()
+	defer fake.diffMutex.Unlock()
+	fake.DiffStub = nil
+	if fake.diffReturnsOnCall == nil {
+		fake.diffReturnsOnCall = make(map[int]struct {
+			result1 bosh.Diff
+			result2 error
+		})
+	}
+	fake.diffReturnsOnCall[i] = struct {
+		result1 bosh.Diff
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeIClient) Instances() ([]bosh.Instance, error) {
 	fake.instancesMutex.Lock()
 	ret, specificReturn := fake.instancesReturnsOnCall[len(fake.instancesArgsForCall)]
@@ -489,6 +767,66 @@ func (fake *FakeIClient) LocksReturnsOnCall(i int, result1 []byte, result2 error
 	}{result1, result2}
 }
 
+func (fake *FakeIClient) Logs(arg1 bosh.LogsOptions) error {
+	fake.logsMutex.Lock()
+	ret, specificReturn := fake.logsReturnsOnCall[len(fake.logsArgsForCall)]
+	fake.logsArgsForCall = append(fake.logsArgsForCall, struct {
+		arg1 bosh.LogsOptions
+	}{arg1})
+	fake.recordInvocation("Logs", []interface{}{arg1})
+	fake.logsMutex.Unlock()
+	if fake.LogsStub != nil {
+		return fake.LogsStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.logsReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeIClient) LogsCallCount() int {
+	fake.logsMutex.RLock()
+	defer fake.logsMutex.RUnlock()
+	return len(fake.logsArgsForCall)
+}
+
+func (fake *FakeIClient) LogsCalls(stub func(bosh.LogsOptions) error) {
+	fake.logsMutex.Lock()
+	defer fake.logsMutex.Unlock()
+	fake.LogsStub = stub
+}
+
+func (fake *FakeIClient) LogsArgsForCall(i int) bosh.LogsOptions {
+	fake.logsMutex.RLock()
+	defer fake.logsMutex.RUnlock()
+	argsForCall := fake.logsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIClient) LogsReturns(result1 error) {
+	fake.logsMutex.Lock()
+	defer fake.logsMutex.Unlock()
+	fake.LogsStub = nil
+	fake.logsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIClient) LogsReturnsOnCall(i int, result1 error) {
+	fake.logsMutex.Lock()
+	defer fake.logsMutex.Unlock()
+	fake.LogsStub = nil
+	if fake.logsReturnsOnCall == nil {
+		fake.logsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.logsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeIClient) Recreate() error {
 	fake.recreateMutex.Lock()
 	ret, specificReturn := fake.recreateReturnsOnCall[len(fake.recreateArgsForCall)]
@@ -541,9 +879,131 @@ func (fake *FakeIClient) RecreateReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeIClient) Restore(arg1 map[string][]byte) error {
+	fake.restoreMutex.Lock()
+	ret, specificReturn := fake.restoreReturnsOnCall[len(fake.restoreArgsForCall)]
+	fake.restoreArgsForCall = append(fake.restoreArgsForCall, struct {
+		arg1 map[string][]byte
+	}{arg1})
+	fake.recordInvocation("Restore", []interface{}{arg1})
+	fake.restoreMutex.Unlock()
+	if fake.RestoreStub != nil {
+		return fake.RestoreStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.restoreReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeIClient) RestoreCallCount() int {
+	fake.restoreMutex.RLock()
+	defer fake.restoreMutex.RUnlock()
+	return len(fake.restoreArgsForCall)
+}
+
+func (fake *FakeIClient) RestoreCalls(stub func(map[string][]byte) error) {
+	fake.restoreMutex.Lock()
+	defer fake.restoreMutex.Unlock()
+	fake.RestoreStub = stub
+}
+
+func (fake *FakeIClient) RestoreArgsForCall(i int) map[string][]byte {
+	fake.restoreMutex.RLock()
+	defer fake.restoreMutex.RUnlock()
+	argsForCall := fake.restoreArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIClient) RestoreReturns(result1 error) {
+	fake.restoreMutex.Lock()
+	defer fake.restoreMutex.Unlock()
+	fake.RestoreStub = nil
+	fake.restoreReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIClient) RestoreReturnsOnCall(i int, result1 error) {
+	fake.restoreMutex.Lock()
+	defer fake.restoreMutex.Unlock()
+	fake.RestoreStub = nil
+	if fake.restoreReturnsOnCall == nil {
+		fake.restoreReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.restoreReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIClient) SSH(arg1 string) error {
+	fake.sSHMutex.Lock()
+	ret, specificReturn := fake.sSHReturnsOnCall[len(fake.sSHArgsForCall)]
+	fake.sSHArgsForCall = append(fake.sSHArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("SSH", []interface{}{arg1})
+	fake.sSHMutex.Unlock()
+	if fake.SSHStub != nil {
+		return fake.SSHStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.sSHReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeIClient) SSHCallCount() int {
+	fake.sSHMutex.RLock()
+	defer fake.sSHMutex.RUnlock()
+	return len(fake.sSHArgsForCall)
+}
+
+func (fake *FakeIClient) SSHCalls(stub func(string) error) {
+	fake.sSHMutex.Lock()
+	defer fake.sSHMutex.Unlock()
+	fake.SSHStub = stub
+}
+
+func (fake *FakeIClient) SSHArgsForCall(i int) string {
+	fake.sSHMutex.RLock()
+	defer fake.sSHMutex.RUnlock()
+	argsForCall := fake.sSHArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIClient) SSHReturns(result1 error) {
+	fake.sSHMutex.Lock()
+	defer fake.sSHMutex.Unlock()
+	fake.SSHStub = nil
+	fake.sSHReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIClient) SSHReturnsOnCall(i int, result1 error) {
+	fake.sSHMutex.Lock()
+	defer fake.sSHMutex.Unlock()
+	fake.SSHStub = nil
+	if fake.sSHReturnsOnCall == nil {
+		fake.sSHReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.sSHReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeIClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.backupMutex.RLock()
+	defer fake.backupMutex.RUnlock()
 	fake.cleanupMutex.RLock()
 	defer fake.cleanupMutex.RUnlock()
 	fake.createEnvMutex.RLock()
@@ -552,12 +1012,22 @@ func (fake *FakeIClient) Invocations() map[string][][]interface{} {
 	defer fake.deleteMutex.RUnlock()
 	fake.deployMutex.RLock()
 	defer fake.deployMutex.RUnlock()
+	fake.deployFromMutex.RLock()
+	defer fake.deployFromMutex.RUnlock()
+	fake.diffMutex.RLock()
+	defer fake.diffMutex.RUnlock()
 	fake.instancesMutex.RLock()
 	defer fake.instancesMutex.RUnlock()
 	fake.locksMutex.RLock()
 	defer fake.locksMutex.RUnlock()
+	fake.logsMutex.RLock()
+	defer fake.logsMutex.RUnlock()
 	fake.recreateMutex.RLock()
 	defer fake.recreateMutex.RUnlock()
+	fake.restoreMutex.RLock()
+	defer fake.restoreMutex.RUnlock()
+	fake.sSHMutex.RLock()
+	defer fake.sSHMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
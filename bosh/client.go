@@ -10,9 +10,11 @@ import (
 
 	"github.com/EngineerBetter/concourse-up/terraform"
 
-	"github.com/EngineerBetter/concourse-up/bosh/internal/boshcli"
-	"github.com/EngineerBetter/concourse-up/bosh/internal/workingdir"
+	"github.com/EngineerBetter/concourse-up/bosh/boshcli"
+	"github.com/EngineerBetter/concourse-up/bosh/postgres"
+	"github.com/EngineerBetter/concourse-up/bosh/workingdir"
 	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/jumpbox"
 )
 
 // StateFilename is default name for bosh-init state file
@@ -21,16 +23,25 @@ const StateFilename = "director-state.json"
 // CredsFilename is default name for bosh-init creds file
 const CredsFilename = "director-creds.yml"
 
+// DirectorInstance is the name SSH accepts for the director itself
+const DirectorInstance = "director"
+
 //go:generate counterfeiter . IClient
 // IClient is a client for performing bosh-init commands
 type IClient interface {
 	Deploy([]byte, []byte, bool) ([]byte, []byte, error)
+	DeployFrom([]byte, []byte, bool, string) ([]byte, []byte, error)
 	Delete([]byte) ([]byte, error)
 	Cleanup() error
 	Instances() ([]Instance, error)
 	CreateEnv([]byte, []byte, string) ([]byte, []byte, error)
 	Recreate() error
 	Locks() ([]byte, error)
+	Diff([]byte) (Diff, error)
+	Backup() (map[string][]byte, error)
+	Restore(map[string][]byte) error
+	SSH(instance string) error
+	Logs(LogsOptions) error
 }
 
 // Instance represents a vm deployed by BOSH
@@ -40,6 +51,13 @@ type Instance struct {
 	State string
 }
 
+// Diff describes the changes a deploy would make to the cloud config and the Concourse deployment
+// Each field holds a diff, or is empty when there are no changes
+type Diff struct {
+	CloudConfig string `json:"cloud_config"`
+	Concourse   string `json:"concourse"`
+}
+
 // ClientFactory creates a new IClient
 type ClientFactory func(config config.Config, outputs terraform.Outputs, stdout, stderr io.Writer, provider iaas.Provider, versionFile []byte) (IClient, error)
 
@@ -50,18 +68,35 @@ func New(config config.Config, outputs terraform.Outputs, stdout, stderr io.Writ
 		return nil, err
 	}
 
-	boshCLI, err := boshcli.New(boshcli.DownloadBOSH())
+	boshOptions := []boshcli.Option{boshcli.DownloadBOSH()}
+	var tunnel *jumpbox.Tunnel
+	if config.Private {
+		tunnel, err = jumpbox.Open(config, outputs)
+		if err != nil {
+			return nil, err
+		}
+		keyPath, err := workingdir.SaveFileToWorkingDir("jumpbox.pem", []byte(config.PrivateKey))
+		if err != nil {
+			return nil, err
+		}
+		boshOptions = append(boshOptions, boshcli.AllProxy(tunnel.AllProxy(keyPath)))
+	}
+
+	boshCLI, err := boshcli.New(boshOptions...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create boshCLI: [%v]", err)
 	}
 
-	switch provider.IAAS() {
-	case iaas.AWS:
-		return NewAWSClient(config, outputs, workingdir, stdout, stderr, provider, boshCLI)
-	case iaas.GCP:
-		return NewGCPClient(config, outputs, workingdir, stdout, stderr, provider, boshCLI)
+	postgresCLI, err := postgres.New()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create postgres CLI: [%v]", err)
+	}
+
+	i, err := lookup(provider.IAAS())
+	if err != nil {
+		return nil, err
 	}
-	return nil, fmt.Errorf("IAAS not supported: %s", provider.IAAS())
+	return i.NewClient(config, outputs, workingdir, stdout, stderr, provider, boshCLI, postgresCLI, tunnel)
 }
 
 func instances(boshCLI boshcli.ICLI, ip, password, ca string) ([]Instance, error) {
@@ -109,24 +144,19 @@ func instances(boshCLI boshcli.ICLI, ip, password, ca string) ([]Instance, error
 }
 
 func saveFilesToWorkingDir(workingdir workingdir.IClient, provider iaas.Provider, creds []byte) error {
-	concourseVersionsContents, _ := provider.Choose(iaas.Choice{
-		AWS: awsConcourseVersions,
-		GCP: gcpConcourseVersions,
-	}).([]byte)
-	concourseSHAsContents, _ := provider.Choose(iaas.Choice{
-		AWS: awsConcourseSHAs,
-		GCP: gcpConcourseSHAs,
-	}).([]byte)
+	i, _ := lookup(provider.IAAS())
 
 	filesToSave := map[string][]byte{
-		concourseVersionsFilename:      concourseVersionsContents,
-		concourseSHAsFilename:          concourseSHAsContents,
+		concourseVersionsFilename:      i.ConcourseVersions,
+		concourseSHAsFilename:          i.ConcourseSHAs,
 		concourseManifestFilename:      concourseManifestContents,
 		concourseCompatibilityFilename: concourseCompatibility,
 		concourseGrafanaFilename:       concourseGrafana,
 		concourseGitHubAuthFilename:    concourseGitHubAuth,
 		credsFilename:                  creds,
 		extraTagsFilename:              extraTags,
+		workerAZsFilename:              workerAZs,
+		privateWebFilename:             privateWeb,
 	}
 
 	for filename, contents := range filesToSave {
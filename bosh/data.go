@@ -9,6 +9,8 @@ const concourseGrafanaFilename = "grafana_dashboard.yml"
 const concourseCompatibilityFilename = "cup_compatibility.yml"
 const concourseGitHubAuthFilename = "github-auth.yml"
 const extraTagsFilename = "extra_tags.yml"
+const workerAZsFilename = "worker_azs.yml"
+const privateWebFilename = "private_web.yml"
 const uaaCertFilename = "uaa-cert.yml"
 
 //go:generate go-bindata -pkg $GOPACKAGE -ignore \.git assets/... ../../concourse-up-ops/... ../resource/assets/...
@@ -16,9 +18,15 @@ var concourseGrafana = MustAsset("assets/grafana_dashboard.yml")
 var concourseCompatibility = MustAsset("assets/ops/cup_compatibility.yml")
 var concourseGitHubAuth = MustAsset("assets/ops/github-auth.yml")
 var extraTags = MustAsset("assets/ops/extra_tags.yml")
+var workerAZs = MustAsset("assets/ops/worker_azs.yml")
+var privateWeb = MustAsset("assets/ops/private_web.yml")
 var concourseManifestContents = MustAsset("../../concourse-up-ops/manifest.yml")
 var awsConcourseVersions = MustAsset("../../concourse-up-ops/ops/versions-aws.json")
 var awsConcourseSHAs = MustAsset("../../concourse-up-ops/ops/shas-aws.json")
 var gcpConcourseVersions = MustAsset("../../concourse-up-ops/ops/versions-gcp.json")
 var gcpConcourseSHAs = MustAsset("../../concourse-up-ops/ops/shas-gcp.json")
+var azureConcourseVersions = MustAsset("../../concourse-up-ops/ops/versions-azure.json")
+var azureConcourseSHAs = MustAsset("../../concourse-up-ops/ops/shas-azure.json")
+var openstackConcourseVersions = MustAsset("../../concourse-up-ops/ops/versions-openstack.json")
+var openstackConcourseSHAs = MustAsset("../../concourse-up-ops/ops/shas-openstack.json")
 var uaaCert = MustAsset("../resource/assets/gcp/uaa-cert.yml")
@@ -15,4 +15,11 @@ func (f fakeOpener) Open(name string) (*sql.DB, error) {
 	return db, nil
 }
 
+func (f fakeOpener) URI(name string) (string, error) {
+	if _, ok := f[name]; !ok {
+		return "", errors.New("database not found")
+	}
+	return "postgres://fake/" + name, nil
+}
+
 func (f fakeOpener) Close() error { return nil }
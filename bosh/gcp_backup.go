@@ -0,0 +1,32 @@
+package bosh
+
+// Backup dumps the Concourse, UAA and CredHub databases through the director
+func (client *GCPClient) Backup() (map[string][]byte, error) {
+	db, err := client.db()
+	if err != nil {
+		return nil, err
+	}
+	return backupDatabases(db, client.postgres)
+}
+
+// Restore replaces the Concourse, UAA and CredHub databases with dumps taken by Backup
+func (client *GCPClient) Restore(dumps map[string][]byte) error {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return err
+	}
+	db, err := client.db()
+	if err != nil {
+		return err
+	}
+	return restoreDatabases(
+		db,
+		client.postgres,
+		client.boshCLI,
+		directorPublicIP,
+		client.config.DirectorPassword,
+		client.config.DirectorCACert,
+		client.stdout,
+		dumps,
+	)
+}
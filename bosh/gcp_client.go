@@ -3,10 +3,12 @@ package bosh
 import (
 	"io"
 
-	"github.com/EngineerBetter/concourse-up/bosh/internal/boshcli"
-	"github.com/EngineerBetter/concourse-up/bosh/internal/workingdir"
+	"github.com/EngineerBetter/concourse-up/bosh/boshcli"
+	"github.com/EngineerBetter/concourse-up/bosh/postgres"
+	"github.com/EngineerBetter/concourse-up/bosh/workingdir"
 	"github.com/EngineerBetter/concourse-up/config"
 	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/jumpbox"
 	"github.com/EngineerBetter/concourse-up/terraform"
 )
 
@@ -19,10 +21,21 @@ type GCPClient struct {
 	stderr     io.Writer
 	provider   iaas.Provider
 	boshCLI    boshcli.ICLI
+	postgres   postgres.ICLI
+}
+
+func init() {
+	Register(iaas.GCP, IAAS{
+		NewClient: func(config config.Config, outputs terraform.Outputs, workingdir workingdir.IClient, stdout, stderr io.Writer, provider iaas.Provider, boshCLI boshcli.ICLI, postgres postgres.ICLI, _ *jumpbox.Tunnel) (IClient, error) {
+			return NewGCPClient(config, outputs, workingdir, stdout, stderr, provider, boshCLI, postgres)
+		},
+		ConcourseVersions: gcpConcourseVersions,
+		ConcourseSHAs:     gcpConcourseSHAs,
+	})
 }
 
 //NewGCPClient returns a GCP specific implementation of IClient
-func NewGCPClient(config config.Config, outputs terraform.Outputs, workingdir workingdir.IClient, stdout, stderr io.Writer, provider iaas.Provider, boshCLI boshcli.ICLI) (IClient, error) {
+func NewGCPClient(config config.Config, outputs terraform.Outputs, workingdir workingdir.IClient, stdout, stderr io.Writer, provider iaas.Provider, boshCLI boshcli.ICLI, postgres postgres.ICLI) (IClient, error) {
 	return &GCPClient{
 		config:     config,
 		outputs:    outputs,
@@ -31,9 +44,17 @@ func NewGCPClient(config config.Config, outputs terraform.Outputs, workingdir wo
 		stderr:     stderr,
 		provider:   provider,
 		boshCLI:    boshCLI,
+		postgres:   postgres,
 	}, nil
 }
 
+// db returns an Opener for the BOSH database. Only backup and restore use the database directly,
+// so it is built when they need it, rather than for every command
+func (client *GCPClient) db() (Opener, error) {
+	// Cloud SQL only accepts connections from authorised networks, which include the director
+	return newDirectorDBOpener(client.config, client.outputs, "5432", nil)
+}
+
 //Cleanup is GCP specific implementation of Cleanup
 func (client *GCPClient) Cleanup() error {
 	return client.workingdir.Cleanup()
@@ -1,13 +1,28 @@
 package bosh
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"strings"
 )
 
 func (client *GCPClient) deployConcourse(creds []byte, detach bool) ([]byte, error) {
+	return client.runConcourseDeploy(creds, detach, os.Stdout)
+}
+
+// diffConcourse runs bosh deploy --dry-run and returns the manifest diff it reports
+func (client *GCPClient) diffConcourse(creds []byte) (string, error) {
+	output := new(bytes.Buffer)
+	if _, err := client.runConcourseDeploy(creds, false, output, "--dry-run"); err != nil {
+		return "", fmt.Errorf("Error [%s] running `bosh deploy --dry-run`. stdout: [%s]", err, output.String())
+	}
+	return manifestDiff(output.String()), nil
+}
+
+func (client *GCPClient) runConcourseDeploy(creds []byte, detach bool, stdout io.Writer, extraFlags ...string) ([]byte, error) {
 
 	err := saveFilesToWorkingDir(client.workingdir, client.provider, creds)
 	if err != nil {
@@ -92,6 +107,11 @@ func (client *GCPClient) deployConcourse(creds []byte, detach bool) ([]byte, err
 	vmap["tags"] = t
 	flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(extraTagsFilename))
 
+	if len(client.config.Zones) > 1 {
+		vmap["worker_azs"] = workerAZsYaml(len(client.config.Zones))
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(workerAZsFilename))
+	}
+
 	vs := vars(vmap)
 
 	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
@@ -105,8 +125,8 @@ func (client *GCPClient) deployConcourse(creds []byte, detach bool) ([]byte, err
 		client.config.DirectorPassword,
 		client.config.DirectorCACert,
 		detach,
-		os.Stdout,
-		append(flagFiles, vs...)...)
+		stdout,
+		append(append(flagFiles, vs...), extraFlags...)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to run bosh deploy with commands %+v: [%v]", flagFiles, err)
 	}
@@ -3,7 +3,7 @@ package bosh
 import (
 	"net"
 
-	"github.com/EngineerBetter/concourse-up/bosh/internal/boshcli"
+	"github.com/EngineerBetter/concourse-up/bosh/boshcli"
 	"github.com/EngineerBetter/concourse-up/bosh/internal/gcp"
 	"github.com/apparentlymart/go-cidr/cidr"
 )
@@ -11,31 +11,29 @@ import (
 // Deploy deploys a new Bosh director or converges an existing deployment
 // Returns new contents of bosh state file
 func (client *GCPClient) Deploy(state, creds []byte, detach bool) (newState, newCreds []byte, err error) {
-	boshCLI, err := boshcli.New(boshcli.DownloadBOSH())
-	if err != nil {
-		return state, creds, err
-	}
-
-	state, creds, err = client.createEnv(boshCLI, state, creds, "")
-	if err != nil {
-		return state, creds, err
-	}
-
-	if err = client.updateCloudConfig(boshCLI); err != nil {
-		return state, creds, err
-	}
-	if err = client.uploadConcourseStemcell(boshCLI); err != nil {
-		return state, creds, err
-	}
-	if err = client.createDefaultDatabases(); err != nil {
-		return state, creds, err
-	}
+	return client.DeployFrom(state, creds, detach, "")
+}
 
-	creds, err = client.deployConcourse(creds, detach)
+// DeployFrom deploys like Deploy, skipping the phases before from
+func (client *GCPClient) DeployFrom(state, creds []byte, detach bool, from string) (newState, newCreds []byte, err error) {
+	boshCLI, err := boshcli.New(boshcli.DownloadBOSH())
 	if err != nil {
 		return state, creds, err
 	}
 
+	err = runPhases(from, []phase{
+		{PhaseCreateEnv, func() error {
+			state, creds, err = client.createEnv(boshCLI, state, creds, "")
+			return err
+		}},
+		{PhaseCloudConfig, func() error { return client.updateCloudConfig(boshCLI) }},
+		{PhaseStemcell, func() error { return client.uploadConcourseStemcell(boshCLI) }},
+		{PhaseDatabases, client.createDefaultDatabases},
+		{PhaseDeploy, func() error {
+			creds, err = client.deployConcourse(creds, detach)
+			return err
+		}},
+	})
 	return state, creds, err
 }
 
@@ -142,60 +140,86 @@ func (client *GCPClient) Locks() ([]byte, error) {
 }
 
 func (client *GCPClient) updateCloudConfig(bosh boshcli.ICLI) error {
+	env, err := client.cloudConfigEnvironment()
+	if err != nil {
+		return err
+	}
+	return bosh.UpdateCloudConfig(env, env.ExternalIP, client.config.DirectorPassword, client.config.DirectorCACert)
+}
+
+// Diff reports the changes a deploy would make to the cloud config and the Concourse deployment
+func (client *GCPClient) Diff(creds []byte) (Diff, error) {
+	var diff Diff
+	env, err := client.cloudConfigEnvironment()
+	if err != nil {
+		return diff, err
+	}
+	diff.CloudConfig, err = client.boshCLI.DiffCloudConfig(env, env.ExternalIP, client.config.DirectorPassword, client.config.DirectorCACert)
+	if err != nil {
+		return diff, err
+	}
+	diff.Concourse, err = client.diffConcourse(creds)
+	return diff, err
+}
 
+func (client *GCPClient) cloudConfigEnvironment() (gcp.Environment, error) {
 	privateSubnetwork, err := client.outputs.Get("PrivateSubnetworkName")
 	if err != nil {
-		return err
+		return gcp.Environment{}, err
 	}
 	publicSubnetwork, err := client.outputs.Get("PublicSubnetworkName")
 	if err != nil {
-		return err
+		return gcp.Environment{}, err
 	}
 	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
 	if err != nil {
-		return err
+		return gcp.Environment{}, err
 	}
 	network, err := client.outputs.Get("Network")
 	if err != nil {
-		return err
+		return gcp.Environment{}, err
 	}
 	zone := client.provider.Zone("")
 
 	publicCIDR := client.config.PublicCIDR
 	_, pubCIDR, err := net.ParseCIDR(publicCIDR)
 	if err != nil {
-		return err
+		return gcp.Environment{}, err
 	}
 	pubGateway, err := cidr.Host(pubCIDR, 1)
 	if err != nil {
-		return err
+		return gcp.Environment{}, err
 	}
 	publicCIDRGateway := pubGateway.String()
 
 	publicCIDRStatic, err := formatIPRange(publicCIDR, ", ", []int{6, 7})
 	if err != nil {
-		return err
+		return gcp.Environment{}, err
 	}
 	publicCIDRReserved, err := formatIPRange(publicCIDR, "-", []int{1, 5})
 	if err != nil {
-		return err
+		return gcp.Environment{}, err
 	}
 
 	privateCIDR := client.config.PrivateCIDR
 	_, privCIDR, err := net.ParseCIDR(privateCIDR)
 	if err != nil {
-		return err
+		return gcp.Environment{}, err
 	}
 	privGateway, err := cidr.Host(privCIDR, 1)
 	if err != nil {
-		return err
+		return gcp.Environment{}, err
 	}
 	privateCIDRGateway := privGateway.String()
 	privateCIDRReserved, err := formatIPRange(privateCIDR, "-", []int{1, 5})
 	if err != nil {
-		return err
+		return gcp.Environment{}, err
 	}
-	return bosh.UpdateCloudConfig(gcp.Environment{
+	var extraZones []gcp.Zone
+	for i := 1; i < len(client.config.Zones); i++ {
+		extraZones = append(extraZones, gcp.Zone{Name: zoneName(i), Zone: client.config.Zones[i]})
+	}
+	return gcp.Environment{
 		PublicCIDR:          client.config.PublicCIDR,
 		PublicCIDRGateway:   publicCIDRGateway,
 		PublicCIDRStatic:    publicCIDRStatic,
@@ -208,7 +232,9 @@ func (client *GCPClient) updateCloudConfig(bosh boshcli.ICLI) error {
 		PrivateSubnetwork:   privateSubnetwork,
 		Zone:                zone,
 		Network:             network,
-	}, directorPublicIP, client.config.DirectorPassword, client.config.DirectorCACert)
+		ExternalIP:          directorPublicIP,
+		ExtraZones:          extraZones,
+	}, nil
 }
 func (client *GCPClient) uploadConcourseStemcell(bosh boshcli.ICLI) error {
 	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
@@ -12,7 +12,7 @@ func vars(vars map[string]interface{}) []string {
 	for k, v := range vars {
 		switch v.(type) {
 		case string:
-			if k == "tags" {
+			if k == "tags" || k == "worker_azs" {
 				x = append(x, "--var", fmt.Sprintf("%s=%s", k, v))
 				continue
 			}
@@ -49,6 +49,20 @@ func splitTags(ts []string) (map[string]string, error) {
 	return m, nil
 }
 
+// zoneName returns the name in the cloud config of the zone at index i of the config's zones
+func zoneName(i int) string {
+	return fmt.Sprintf("z%d", i+1)
+}
+
+// workerAZsYaml returns the cloud config zones workers are spread across, as a YAML list
+func workerAZsYaml(zones int) string {
+	var names []string
+	for i := 0; i < zones; i++ {
+		names = append(names, zoneName(i))
+	}
+	return fmt.Sprintf("[%s]", strings.Join(names, ", "))
+}
+
 func formatIPRange(forCIDR, sep string, positions []int) (string, error) {
 	var ips []string
 	_, parsedCIDR, err := net.ParseCIDR(forCIDR)
@@ -62,4 +76,22 @@ func formatIPRange(forCIDR, sep string, positions []int) (string, error) {
 	}
 	s := fmt.Sprintf(`[%s]`, strings.Join(ips, sep))
 	return s, nil
-}
\ No newline at end of file
+}
+// manifestDiff extracts the manifest diff which bosh deploy prints before it starts a task
+func manifestDiff(output string) string {
+	var lines []string
+	inDiff := false
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "Using deployment") {
+			inDiff = true
+			continue
+		}
+		if strings.HasPrefix(line, "Task ") {
+			break
+		}
+		if inDiff {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
@@ -33,12 +33,15 @@ type Environment struct {
 	DefaultKeyName        string
 	DefaultSecurityGroups []string
 	ExternalIP            string
+	ExtraZones            []Zone
 	InternalCIDR          string
 	InternalGateway       string
 	InternalIP            string
+	Private               bool
 	PrivateCIDR           string
 	PrivateCIDRGateway    string
 	PrivateCIDRReserved   string
+	PrivateCIDRStatic     string
 	PrivateKey            string
 	PrivateSubnetID       string
 	PublicCIDR            string
@@ -55,7 +58,19 @@ type Environment struct {
 	WorkerType            string
 }
 
+// Zone is an availability zone, besides AZ, which workers are spread across using its own private subnet
+type Zone struct {
+	// Name is the name of the zone in the cloud config
+	Name                string
+	AvailabilityZone    string
+	PrivateCIDR         string
+	PrivateCIDRGateway  string
+	PrivateCIDRReserved string
+	PrivateSubnetID     string
+}
+
 var allOperations = resource.AWSCPIOps + resource.ExternalIPOps + resource.AWSDirectorCustomOps
+var privateOperations = resource.AWSCPIOps + resource.AWSDirectorCustomOps
 
 // ConfigureDirectorManifestCPI interpolates all the Environment parameters and
 // required release versions into ready to use Director manifest
@@ -63,7 +78,12 @@ func (e Environment) ConfigureDirectorManifestCPI() (string, error) {
 	cpiResource := resource.Get(resource.AWSCPI)
 	stemcellResource := resource.Get(resource.AWSStemcell)
 
-	return yaml.Interpolate(resource.DirectorManifest, allOperations+e.CustomOperations, map[string]interface{}{
+	operations, subnetID := allOperations, e.PublicSubnetID
+	if e.Private {
+		operations, subnetID = privateOperations, e.PrivateSubnetID
+	}
+
+	return yaml.Interpolate(resource.DirectorManifest, operations+e.CustomOperations, map[string]interface{}{
 		"cpi_url":                  cpiResource.URL,
 		"cpi_version":              cpiResource.Version,
 		"cpi_sha1":                 cpiResource.SHA1,
@@ -79,7 +99,7 @@ func (e Environment) ConfigureDirectorManifestCPI() (string, error) {
 		"default_key_name":         e.DefaultKeyName,
 		"default_security_groups":  e.DefaultSecurityGroups,
 		"private_key":              e.PrivateKey,
-		"subnet_id":                e.PublicSubnetID,
+		"subnet_id":                subnetID,
 		"external_ip":              e.ExternalIP,
 		"blobstore_bucket":         e.BlobstoreBucket,
 		"db_ca_cert":               e.DBCACert,
@@ -108,6 +128,8 @@ type awsCloudConfigParams struct {
 	PrivateCIDR         string
 	PrivateCIDRGateway  string
 	PrivateCIDRReserved string
+	PrivateCIDRStatic   string
+	ExtraZones          []Zone
 }
 
 // IAASCheck returns the IAAS provider
@@ -132,6 +154,8 @@ func (e Environment) ConfigureDirectorCloudConfig() (string, error) {
 		PrivateCIDR:         e.PrivateCIDR,
 		PrivateCIDRGateway:  e.PrivateCIDRGateway,
 		PrivateCIDRReserved: e.PrivateCIDRReserved,
+		PrivateCIDRStatic:   e.PrivateCIDRStatic,
+		ExtraZones:          e.ExtraZones,
 	}
 
 	cc, err := util.RenderTemplate("cloud-config", resource.AWSDirectorCloudConfig, templateParams)
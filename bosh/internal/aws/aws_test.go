@@ -246,6 +246,41 @@ func TestEnvironment_ConfigureDirectorCloudConfig(t *testing.T) {
 				return a == b, fmt.Sprintf("m5 worker templating failed")
 			},
 		},
+		{
+			name:    "Success- workers spread across zones",
+			fields:  fullTemplateParams,
+			want:    getFixture("../fixtures/aws_cloud_config_zones.yml"),
+			wantErr: false,
+			init: func(e Environment) Environment {
+				n := e
+				n.ExtraZones = []Zone{{
+					Name:                "z2",
+					AvailabilityZone:    "extra_az",
+					PrivateCIDR:         "extra_private_cidr",
+					PrivateCIDRGateway:  "extra_private_cidr_gateway",
+					PrivateCIDRReserved: "extra_private_cidr_reserved",
+					PrivateSubnetID:     "extra_private_subnet_id",
+				}}
+				return n
+			},
+			validate: func(a, b string) (bool, string) {
+				return a == b, fmt.Sprintf("templating failed while rendering extra zones")
+			},
+		},
+		{
+			name:    "Success- private deployment rendered",
+			fields:  fullTemplateParams,
+			want:    getFixture("../fixtures/aws_cloud_config_private.yml"),
+			wantErr: false,
+			init: func(e Environment) Environment {
+				n := e
+				n.PrivateCIDRStatic = "private_cidr_static"
+				return n
+			},
+			validate: func(a, b string) (bool, string) {
+				return a == b, fmt.Sprintf("templating failed while rendering a private deployment")
+			},
+		},
 		{
 			name:    "Success- m4 worker type is m4",
 			fields:  fullTemplateParams,
@@ -288,6 +323,11 @@ func listNodeFields(node parse.Node, res map[string]int) map[string]int {
 		res[re.FindStringSubmatch(node.String())[2]] = 1
 	}
 
+	if node.Type() == parse.NodeRange {
+		var re = regexp.MustCompile(`{{range\s\.(\w+)}}`)
+		res[re.FindStringSubmatch(node.String())[1]] = 1
+	}
+
 	if node.Type() == parse.NodeAction {
 		var re = regexp.MustCompile(`{{\.(.*)}}`)
 		res[re.FindStringSubmatch(node.String())[1]] = 1
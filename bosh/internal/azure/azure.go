@@ -0,0 +1,148 @@
+package azure
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/resource"
+	"github.com/EngineerBetter/concourse-up/util"
+	"github.com/EngineerBetter/concourse-up/util/yaml"
+)
+
+// Environment holds all the parameters Azure IAAS needs
+type Environment struct {
+	ATCSecurityGroup      string
+	ClientID              string
+	ClientSecret          string
+	CustomOperations      string
+	DefaultSecurityGroup  string
+	DirectorSecurityGroup string
+	ExternalIP            string
+	InternalCIDR          string
+	InternalGW            string
+	InternalIP            string
+	Network               string
+	PrivateCIDR           string
+	PrivateCIDRGateway    string
+	PrivateCIDRReserved   string
+	PrivateKey            string
+	PrivateSubnet         string
+	PublicCIDR            string
+	PublicCIDRGateway     string
+	PublicCIDRReserved    string
+	PublicCIDRStatic      string
+	PublicKey             string
+	PublicSubnet          string
+	ResourceGroup         string
+	SubscriptionID        string
+	TenantID              string
+	Zone                  string
+}
+
+var allOperations = resource.AzureCPIOps + resource.ExternalIPOps + resource.AzureDirectorCustomOps
+
+// ConfigureDirectorManifestCPI interpolates all the Environment parameters and
+// required release versions into ready to use Director manifest
+func (e Environment) ConfigureDirectorManifestCPI() (string, error) {
+	cpiResource := resource.Get(resource.AzureCPI)
+	stemcellResource := resource.Get(resource.AzureStemcell)
+
+	return yaml.Interpolate(resource.DirectorManifest, allOperations+e.CustomOperations, map[string]interface{}{
+		"cpi_url":                 cpiResource.URL,
+		"cpi_version":             cpiResource.Version,
+		"cpi_sha1":                cpiResource.SHA1,
+		"stemcell_url":            stemcellResource.URL,
+		"stemcell_sha1":           stemcellResource.SHA1,
+		"internal_cidr":           e.InternalCIDR,
+		"internal_gw":             e.InternalGW,
+		"internal_ip":             e.InternalIP,
+		"subscription_id":         e.SubscriptionID,
+		"tenant_id":               e.TenantID,
+		"client_id":               e.ClientID,
+		"client_secret":           e.ClientSecret,
+		"resource_group_name":     e.ResourceGroup,
+		"vnet_name":               e.Network,
+		"subnet_name":             e.PublicSubnet,
+		"default_security_group":  e.DefaultSecurityGroup,
+		"director_security_group": e.DirectorSecurityGroup,
+		"public_key":              e.PublicKey,
+		"private_key":             e.PrivateKey,
+		"external_ip":             e.ExternalIP,
+	})
+}
+
+type azureCloudConfigParams struct {
+	ATCSecurityGroup    string
+	Network             string
+	PrivateCIDR         string
+	PrivateCIDRGateway  string
+	PrivateCIDRReserved string
+	PrivateSubnet       string
+	PublicCIDR          string
+	PublicCIDRGateway   string
+	PublicCIDRReserved  string
+	PublicCIDRStatic    string
+	PublicSubnet        string
+	WebSizes            map[string]string
+	WorkerSizes         map[string]string
+	Zone                string
+}
+
+// IAASCheck returns the IAAS provider
+func (e Environment) IAASCheck() iaas.Name {
+	return iaas.Azure
+}
+
+// ConfigureDirectorCloudConfig inserts values from the environment into the config template passed as argument
+func (e Environment) ConfigureDirectorCloudConfig() (string, error) {
+	templateParams := azureCloudConfigParams{
+		ATCSecurityGroup:    e.ATCSecurityGroup,
+		Network:             e.Network,
+		PrivateCIDR:         e.PrivateCIDR,
+		PrivateCIDRGateway:  e.PrivateCIDRGateway,
+		PrivateCIDRReserved: e.PrivateCIDRReserved,
+		PrivateSubnet:       e.PrivateSubnet,
+		PublicCIDR:          e.PublicCIDR,
+		PublicCIDRGateway:   e.PublicCIDRGateway,
+		PublicCIDRReserved:  e.PublicCIDRReserved,
+		PublicCIDRStatic:    e.PublicCIDRStatic,
+		PublicSubnet:        e.PublicSubnet,
+		WebSizes:            iaas.AzureWebSizes,
+		WorkerSizes:         iaas.AzureWorkerSizes,
+		Zone:                e.Zone,
+	}
+
+	cc, err := util.RenderTemplate("cloud-config", resource.AzureDirectorCloudConfig, templateParams)
+	if cc == nil {
+		return "", err
+	}
+	return string(cc), err
+}
+
+// ConfigureConcourseStemcell returns the stemcell location string for an Azure specific stemcell for the required concourse version
+func (e Environment) ConfigureConcourseStemcell() (string, error) {
+	var ops []struct {
+		Path  string
+		Value json.RawMessage
+	}
+	err := json.Unmarshal([]byte(resource.AzureReleaseVersions), &ops)
+	if err != nil {
+		return "", err
+	}
+	var version string
+	for _, op := range ops {
+		if op.Path != "/stemcells/alias=xenial/version" {
+			continue
+		}
+		err := json.Unmarshal(op.Value, &version)
+		if err != nil {
+			return "", err
+		}
+	}
+	if version == "" {
+		return "", errors.New("did not find stemcell version in versions.json")
+	}
+	return fmt.Sprintf("https://bosh.io/d/stemcells/bosh-azure-hyperv-ubuntu-xenial-go_agent?v=%s", version), nil
+}
@@ -22,6 +22,7 @@ type Environment struct {
 	CustomOperations    string
 	DirectorName        string
 	ExternalIP          string
+	ExtraZones          []Zone
 	GcpCredentialsJSON  string
 	InternalCIDR        string
 	InternalGW          string
@@ -43,6 +44,13 @@ type Environment struct {
 	Zone                string
 }
 
+// Zone is a zone, besides Zone, which workers are spread across. The private subnetwork spans every zone of the region
+type Zone struct {
+	// Name is the name of the zone in the cloud config
+	Name string
+	Zone string
+}
+
 var allOperations = resource.GCPCPIOps + resource.GCPExternalIPOps + resource.GCPDirectorCustomOps + resource.GCPJumpboxUserOps
 
 // ConfigureDirectorManifestCPI interpolates all the Environment parameters and
@@ -82,6 +90,7 @@ type gcpCloudConfigParams struct {
 	PrivateCIDR         string
 	PrivateCIDRGateway  string
 	PrivateCIDRReserved string
+	ExtraZones          []Zone
 }
 
 // IAASCheck returns the IAAS provider
@@ -104,6 +113,7 @@ func (e Environment) ConfigureDirectorCloudConfig() (string, error) {
 		PrivateCIDR:         e.PrivateCIDR,
 		PrivateCIDRGateway:  e.PrivateCIDRGateway,
 		PrivateCIDRReserved: e.PrivateCIDRReserved,
+		ExtraZones:          e.ExtraZones,
 	}
 
 	cc, err := util.RenderTemplate("cloud-config", resource.GCPDirectorCloudConfig, templateParams)
@@ -201,6 +201,20 @@ func TestEnvironment_ConfigureDirectorCloudConfig(t *testing.T) {
 				return a == b, fmt.Sprintf("basic rendering expected to work")
 			},
 		},
+		{
+			name:    "Success- workers spread across zones",
+			fields:  fullTemplateParams,
+			want:    getFixture("../fixtures/gcp_cloud_config_zones.yml"),
+			wantErr: false,
+			init: func(e Environment) Environment {
+				n := e
+				n.ExtraZones = []Zone{{Name: "z2", Zone: "extra_zone"}, {Name: "z3", Zone: "another_zone"}}
+				return n
+			},
+			validate: func(a, b string) (bool, string) {
+				return a == b, fmt.Sprintf("templating failed while rendering extra zones")
+			},
+		},
 		{
 			name:    "Success- spot instance rendered",
 			fields:  fullTemplateParams,
@@ -302,6 +316,11 @@ func listNodeFields(node parse.Node, res map[string]int) map[string]int {
 		res[re.FindStringSubmatch(node.String())[2]] = 1
 	}
 
+	if node.Type() == parse.NodeRange {
+		var re = regexp.MustCompile(`{{range\s\.(\w+)}}`)
+		res[re.FindStringSubmatch(node.String())[1]] = 1
+	}
+
 	if node.Type() == parse.NodeAction {
 		var re = regexp.MustCompile(`{{\.(.*)}}`)
 		res[re.FindStringSubmatch(node.String())[1]] = 1
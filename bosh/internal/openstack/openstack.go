@@ -0,0 +1,152 @@
+package openstack
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/resource"
+	"github.com/EngineerBetter/concourse-up/util"
+	"github.com/EngineerBetter/concourse-up/util/yaml"
+)
+
+// Environment holds all the parameters OpenStack IAAS needs
+type Environment struct {
+	ATCSecurityGroup      string
+	AuthURL               string
+	CompilationFlavor     string
+	CustomOperations      string
+	DefaultKeyName        string
+	DefaultSecurityGroup  string
+	DirectorFlavor        string
+	DirectorSecurityGroup string
+	Domain                string
+	ExternalIP            string
+	InternalCIDR          string
+	InternalGW            string
+	InternalIP            string
+	NetworkID             string
+	Password              string
+	PrivateCIDR           string
+	PrivateCIDRGateway    string
+	PrivateCIDRReserved   string
+	PrivateKey            string
+	Project               string
+	PublicCIDR            string
+	PublicCIDRGateway     string
+	PublicCIDRReserved    string
+	PublicCIDRStatic      string
+	Region                string
+	Username              string
+	WebFlavors            map[string]string
+	WorkerFlavors         map[string]string
+	Zone                  string
+}
+
+var allOperations = resource.OpenStackCPIOps + resource.ExternalIPOps + resource.OpenStackDirectorCustomOps
+
+// ConfigureDirectorManifestCPI interpolates all the Environment parameters and
+// required release versions into ready to use Director manifest
+func (e Environment) ConfigureDirectorManifestCPI() (string, error) {
+	cpiResource := resource.Get(resource.OpenStackCPI)
+	stemcellResource := resource.Get(resource.OpenStackStemcell)
+
+	return yaml.Interpolate(resource.DirectorManifest, allOperations+e.CustomOperations, map[string]interface{}{
+		"cpi_url":                 cpiResource.URL,
+		"cpi_version":             cpiResource.Version,
+		"cpi_sha1":                cpiResource.SHA1,
+		"stemcell_url":            stemcellResource.URL,
+		"stemcell_sha1":           stemcellResource.SHA1,
+		"internal_cidr":           e.InternalCIDR,
+		"internal_gw":             e.InternalGW,
+		"internal_ip":             e.InternalIP,
+		"auth_url":                e.AuthURL,
+		"openstack_username":      e.Username,
+		"openstack_password":      e.Password,
+		"openstack_domain":        e.Domain,
+		"openstack_project":       e.Project,
+		"openstack_region":        e.Region,
+		"default_key_name":        e.DefaultKeyName,
+		"default_security_group":  e.DefaultSecurityGroup,
+		"director_security_group": e.DirectorSecurityGroup,
+		"director_flavor":         e.DirectorFlavor,
+		"net_id":                  e.NetworkID,
+		"private_key":             e.PrivateKey,
+		"external_ip":             e.ExternalIP,
+	})
+}
+
+type openstackCloudConfigParams struct {
+	ATCSecurityGroup    string
+	CompilationFlavor   string
+	NetworkID           string
+	PrivateCIDR         string
+	PrivateCIDRGateway  string
+	PrivateCIDRReserved string
+	PublicCIDR          string
+	PublicCIDRGateway   string
+	PublicCIDRReserved  string
+	PublicCIDRStatic    string
+	VMsSecurityGroup    string
+	WebFlavors          map[string]string
+	WorkerFlavors       map[string]string
+	Zone                string
+}
+
+// IAASCheck returns the IAAS provider
+func (e Environment) IAASCheck() iaas.Name {
+	return iaas.OpenStack
+}
+
+// ConfigureDirectorCloudConfig inserts values from the environment into the config template passed as argument
+func (e Environment) ConfigureDirectorCloudConfig() (string, error) {
+	templateParams := openstackCloudConfigParams{
+		ATCSecurityGroup:    e.ATCSecurityGroup,
+		CompilationFlavor:   e.CompilationFlavor,
+		NetworkID:           e.NetworkID,
+		PrivateCIDR:         e.PrivateCIDR,
+		PrivateCIDRGateway:  e.PrivateCIDRGateway,
+		PrivateCIDRReserved: e.PrivateCIDRReserved,
+		PublicCIDR:          e.PublicCIDR,
+		PublicCIDRGateway:   e.PublicCIDRGateway,
+		PublicCIDRReserved:  e.PublicCIDRReserved,
+		PublicCIDRStatic:    e.PublicCIDRStatic,
+		VMsSecurityGroup:    e.DefaultSecurityGroup,
+		WebFlavors:          e.WebFlavors,
+		WorkerFlavors:       e.WorkerFlavors,
+		Zone:                e.Zone,
+	}
+
+	cc, err := util.RenderTemplate("cloud-config", resource.OpenStackDirectorCloudConfig, templateParams)
+	if cc == nil {
+		return "", err
+	}
+	return string(cc), err
+}
+
+// ConfigureConcourseStemcell returns the stemcell location string for an OpenStack specific stemcell for the required concourse version
+func (e Environment) ConfigureConcourseStemcell() (string, error) {
+	var ops []struct {
+		Path  string
+		Value json.RawMessage
+	}
+	err := json.Unmarshal([]byte(resource.OpenStackReleaseVersions), &ops)
+	if err != nil {
+		return "", err
+	}
+	var version string
+	for _, op := range ops {
+		if op.Path != "/stemcells/alias=xenial/version" {
+			continue
+		}
+		err := json.Unmarshal(op.Value, &version)
+		if err != nil {
+			return "", err
+		}
+	}
+	if version == "" {
+		return "", errors.New("did not find stemcell version in versions.json")
+	}
+	return fmt.Sprintf("https://bosh.io/d/stemcells/bosh-openstack-kvm-ubuntu-xenial-go_agent?v=%s", version), nil
+}
@@ -0,0 +1,162 @@
+package openstack
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"testing"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/EngineerBetter/concourse-up/resource"
+)
+
+func TestEnvironment_ConfigureDirectorCloudConfig(t *testing.T) {
+
+	fullTemplateParams := Environment{
+		ATCSecurityGroup:     "atc_security_group",
+		CompilationFlavor:    "compilation_flavor",
+		DefaultSecurityGroup: "vms_security_group",
+		NetworkID:            "network_id",
+		PrivateCIDR:          "private_cidr",
+		PrivateCIDRGateway:   "private_cidr_gateway",
+		PrivateCIDRReserved:  "private_cidr_reserved",
+		PublicCIDR:           "public_cidr",
+		PublicCIDRGateway:    "public_cidr_gateway",
+		PublicCIDRReserved:   "public_cidr_reserved",
+		PublicCIDRStatic:     "public_cidr_static",
+		WebFlavors:           map[string]string{"small": "web_small_flavor", "large": "web_large_flavor"},
+		WorkerFlavors:        map[string]string{"xlarge": "worker_xlarge_flavor"},
+		Zone:                 "nova",
+	}
+
+	getFixture := func(f string) string {
+		contents, _ := ioutil.ReadFile(f)
+		return string(contents)
+	}
+
+	tests := []struct {
+		name    string
+		fields  Environment
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "Success- template rendered",
+			fields:  fullTemplateParams,
+			want:    getFixture("../fixtures/openstack_cloud_config_full.yml"),
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fields.ConfigureDirectorCloudConfig()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Environment.ConfigureDirectorCloudConfig()\nerror expected:  %v\nreceived error:  %v", tt.wantErr, err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("basic rendering expected to work")
+			}
+		})
+	}
+}
+
+func getStemcellFixture(fixture string) string {
+	stemcellBytes, _ := ioutil.ReadFile(fmt.Sprintf("../fixtures/%s.json", fixture))
+	return string(stemcellBytes)
+}
+
+func TestEnvironment_ConfigureConcourseStemcell(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+		fixture string
+	}{
+		{
+			name:    "parse versions and provide a valid stemcell url",
+			want:    "https://bosh.io/d/stemcells/bosh-openstack-kvm-ubuntu-xenial-go_agent?v=5",
+			wantErr: false,
+			fixture: "stemcell_version",
+		},
+		{
+			name:    "parse versions and indicate no stemcell was found",
+			want:    "",
+			wantErr: true,
+			fixture: "invalid_stemcell_version",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Environment{}
+			resource.OpenStackReleaseVersions = getStemcellFixture(tt.fixture)
+			got, err := e.ConfigureConcourseStemcell()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Environment.ConfigureConcourseStemcell() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Environment.ConfigureConcourseStemcell() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func listTemplFields(t *template.Template) map[string]int {
+	m := make(map[string]int)
+	return listNodeFields(t.Tree.Root, m)
+}
+
+func listNodeFields(node parse.Node, res map[string]int) map[string]int {
+	if node.Type() == parse.NodeIf {
+		var re = regexp.MustCompile(`{{(if|if eq)?\s\.(\w+)(}}|\s)`)
+		res[re.FindStringSubmatch(node.String())[2]] = 1
+	}
+
+	if node.Type() == parse.NodeRange {
+		var re = regexp.MustCompile(`{{range\s(?:\$\w+,\s\$\w+\s:=\s)?\.(\w+)}}`)
+		res[re.FindStringSubmatch(node.String())[1]] = 1
+	}
+
+	if node.Type() == parse.NodeAction {
+		var re = regexp.MustCompile(`{{\.(.*)}}`)
+		res[re.FindStringSubmatch(node.String())[1]] = 1
+	}
+	if ln, ok := node.(*parse.ListNode); ok {
+		for _, n := range ln.Nodes {
+			res = listNodeFields(n, res)
+		}
+	}
+	return res
+}
+
+func matchStructFields(c interface{}, res map[string]int) map[string]int {
+	e := reflect.TypeOf(c)
+
+	for i := 0; i < e.NumField(); i++ {
+		varName := e.Field(i).Name
+		if res[varName] == 0 {
+			res[varName] = -1
+		} else {
+			res[varName]++
+		}
+	}
+	return res
+}
+
+func Test_CloudConfigStructureTest(t *testing.T) {
+	t.Run("validating structure", func(t *testing.T) {
+		templ, err := template.New("template").Option("missingkey=error").Parse(resource.OpenStackDirectorCloudConfig)
+		if err != nil {
+			t.Errorf("cannot parse the template")
+		}
+		emptyOpenStackCloudConfigParams := openstackCloudConfigParams{}
+		for k, v := range matchStructFields(emptyOpenStackCloudConfigParams, listTemplFields(templ)) {
+			if v < 2 {
+				t.Errorf("Field with key name %s is not mapped properly", k)
+			}
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package bosh
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/EngineerBetter/concourse-up/bosh/boshcli"
+)
+
+// LogJobs are the jobs in the Concourse deployment whose logs can be fetched
+var LogJobs = []string{"web", "worker", "credhub", "uaa", "grafana"}
+
+// LogsOptions selects which logs Logs fetches and where they go
+type LogsOptions struct {
+	// Instance is an instance group, optionally with an index or ID. All instances are included when empty
+	Instance string
+	// Jobs limits the logs to these jobs. All jobs are included when empty
+	Jobs []string
+	// Follow tails the logs instead of downloading them
+	Follow bool
+	// Dir is the directory the tarball is downloaded to
+	Dir string
+}
+
+// Validate checks that every job is one of LogJobs
+func (o LogsOptions) Validate() error {
+	for _, job := range o.Jobs {
+		if !isLogJob(job) {
+			return fmt.Errorf("unknown job %q, choose from %s", job, strings.Join(LogJobs, ", "))
+		}
+	}
+	return nil
+}
+
+func isLogJob(job string) bool {
+	for _, j := range LogJobs {
+		if j == job {
+			return true
+		}
+	}
+	return false
+}
+
+func logs(boshCLI boshcli.ICLI, directorPublicIP, password, ca string, stdout io.Writer, opts LogsOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	var flags []string
+	if opts.Instance != "" {
+		flags = append(flags, opts.Instance)
+	}
+	for _, job := range opts.Jobs {
+		flags = append(flags, "--job", job)
+	}
+	if opts.Follow {
+		flags = append(flags, "--follow")
+	} else {
+		flags = append(flags, "--dir", opts.Dir)
+	}
+
+	return boshCLI.RunAuthenticatedCommand("logs", directorPublicIP, password, ca, false, stdout, flags...)
+}
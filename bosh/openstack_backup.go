@@ -0,0 +1,24 @@
+package bosh
+
+// Backup dumps the Concourse, UAA and CredHub databases through the director
+func (client *OpenStackClient) Backup() (map[string][]byte, error) {
+	return backupDatabases(client.db, client.postgres)
+}
+
+// Restore replaces the Concourse, UAA and CredHub databases with dumps taken by Backup
+func (client *OpenStackClient) Restore(dumps map[string][]byte) error {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return err
+	}
+	return restoreDatabases(
+		client.db,
+		client.postgres,
+		client.boshCLI,
+		directorPublicIP,
+		client.config.DirectorPassword,
+		client.config.DirectorCACert,
+		client.stdout,
+		dumps,
+	)
+}
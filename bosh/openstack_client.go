@@ -0,0 +1,76 @@
+package bosh
+
+import (
+	"io"
+
+	"github.com/EngineerBetter/concourse-up/bosh/boshcli"
+	"github.com/EngineerBetter/concourse-up/bosh/postgres"
+	"github.com/EngineerBetter/concourse-up/bosh/workingdir"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/jumpbox"
+	"github.com/EngineerBetter/concourse-up/terraform"
+)
+
+//OpenStackClient is an OpenStack specific implementation of IClient
+type OpenStackClient struct {
+	config     config.Config
+	outputs    terraform.Outputs
+	workingdir workingdir.IClient
+	db         Opener
+	stdout     io.Writer
+	stderr     io.Writer
+	provider   iaas.Provider
+	boshCLI    boshcli.ICLI
+	postgres   postgres.ICLI
+}
+
+// flavorProvider is implemented by providers which map sizes to flavors that users can configure
+type flavorProvider interface {
+	WebFlavors() map[string]string
+	WorkerFlavors() map[string]string
+}
+
+func init() {
+	Register(iaas.OpenStack, IAAS{
+		NewClient: func(config config.Config, outputs terraform.Outputs, workingdir workingdir.IClient, stdout, stderr io.Writer, provider iaas.Provider, boshCLI boshcli.ICLI, postgres postgres.ICLI, _ *jumpbox.Tunnel) (IClient, error) {
+			return NewOpenStackClient(config, outputs, workingdir, stdout, stderr, provider, boshCLI, postgres)
+		},
+		ConcourseVersions: openstackConcourseVersions,
+		ConcourseSHAs:     openstackConcourseSHAs,
+	})
+}
+
+//NewOpenStackClient returns an OpenStack specific implementation of IClient
+func NewOpenStackClient(config config.Config, outputs terraform.Outputs, workingdir workingdir.IClient, stdout, stderr io.Writer, provider iaas.Provider, boshCLI boshcli.ICLI, postgres postgres.ICLI) (IClient, error) {
+	// The Postgres VM is on the private subnet, which only the director and the other VMs can reach
+	db, err := newDirectorDBOpener(config, outputs, "5432", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OpenStackClient{
+		config:     config,
+		outputs:    outputs,
+		workingdir: workingdir,
+		db:         db,
+		stdout:     stdout,
+		stderr:     stderr,
+		provider:   provider,
+		boshCLI:    boshCLI,
+		postgres:   postgres,
+	}, nil
+}
+
+//Cleanup is OpenStack specific implementation of Cleanup
+func (client *OpenStackClient) Cleanup() error {
+	return client.workingdir.Cleanup()
+}
+
+// flavors returns the flavors of the web and worker sizes, which are the defaults unless the provider configures them
+func (client *OpenStackClient) flavors() (map[string]string, map[string]string) {
+	if provider, ok := client.provider.(flavorProvider); ok {
+		return provider.WebFlavors(), provider.WorkerFlavors()
+	}
+	return iaas.OpenStackWebFlavors, iaas.OpenStackWorkerFlavors
+}
@@ -0,0 +1,23 @@
+package bosh
+
+import (
+	"fmt"
+	"strings"
+)
+
+// createDefaultDatabases creates the databases on the Postgres VM, whose cloud-init created RDSDefaultDatabaseName
+func (client *OpenStackClient) createDefaultDatabases() error {
+	db, err := client.db.Open(client.config.RDSDefaultDatabaseName)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	for _, dbName := range defaultDatabaseNames {
+		_, err := db.Exec("CREATE DATABASE " + dbName)
+		if err != nil && !strings.Contains(err.Error(),
+			fmt.Sprintf(`pq: database "%s" already exists`, dbName)) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package bosh
+
+import (
+	"net"
+
+	"github.com/EngineerBetter/concourse-up/bosh/boshcli"
+	"github.com/EngineerBetter/concourse-up/bosh/internal/openstack"
+	"github.com/apparentlymart/go-cidr/cidr"
+)
+
+// Deploy deploys a new Bosh director or converges an existing deployment
+// Returns new contents of bosh state file
+func (client *OpenStackClient) Deploy(state, creds []byte, detach bool) (newState, newCreds []byte, err error) {
+	return client.DeployFrom(state, creds, detach, "")
+}
+
+// DeployFrom deploys like Deploy, skipping the phases before from
+func (client *OpenStackClient) DeployFrom(state, creds []byte, detach bool, from string) (newState, newCreds []byte, err error) {
+	boshCLI, err := boshcli.New(boshcli.DownloadBOSH())
+	if err != nil {
+		return state, creds, err
+	}
+
+	err = runPhases(from, []phase{
+		{PhaseCreateEnv, func() error {
+			state, creds, err = client.createEnv(boshCLI, state, creds, "")
+			return err
+		}},
+		{PhaseCloudConfig, func() error { return client.updateCloudConfig(boshCLI) }},
+		{PhaseStemcell, func() error { return client.uploadConcourseStemcell(boshCLI) }},
+		{PhaseDatabases, client.createDefaultDatabases},
+		{PhaseDeploy, func() error {
+			creds, err = client.deployConcourse(creds, detach)
+			return err
+		}},
+	})
+	return state, creds, err
+}
+
+// CreateEnv exposes bosh create-env functionality
+func (client *OpenStackClient) CreateEnv(state, creds []byte, customOps string) (newState, newCreds []byte, err error) {
+	return client.createEnv(client.boshCLI, state, creds, customOps)
+}
+
+// Recreate exposes BOSH recreate
+func (client *OpenStackClient) Recreate() error {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return err
+	}
+	return client.boshCLI.Recreate(openstack.Environment{
+		ExternalIP: directorPublicIP,
+	}, directorPublicIP, client.config.DirectorPassword, client.config.DirectorCACert)
+}
+
+func (client *OpenStackClient) createEnv(bosh boshcli.ICLI, state, creds []byte, customOps string) (newState, newCreds []byte, err error) {
+	tags, err := splitTags(client.config.Tags)
+	if err != nil {
+		return state, creds, err
+	}
+	tags["concourse-up-project"] = client.config.Project
+	tags["concourse-up-component"] = "concourse"
+	store := temporaryStore{
+		"vars.yaml":  creds,
+		"state.json": state,
+	}
+
+	env, err := client.directorEnvironment(customOps)
+	if err != nil {
+		return state, creds, err
+	}
+	err = bosh.CreateEnv(store, env, client.config.DirectorPassword, client.config.DirectorCert, client.config.DirectorKey, client.config.DirectorCACert, tags)
+	return store["state.json"], store["vars.yaml"], err
+}
+
+// directorEnvironment returns the environment create-env and delete-env deploy the director with
+func (client *OpenStackClient) directorEnvironment(customOps string) (openstack.Environment, error) {
+	networkID, err := client.outputs.Get("NetworkID")
+	if err != nil {
+		return openstack.Environment{}, err
+	}
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return openstack.Environment{}, err
+	}
+	directorSecurityGroup, err := client.outputs.Get("DirectorSecurityGroupID")
+	if err != nil {
+		return openstack.Environment{}, err
+	}
+	vmsSecurityGroup, err := client.outputs.Get("VMsSecurityGroupName")
+	if err != nil {
+		return openstack.Environment{}, err
+	}
+
+	attrs := make(map[string]string)
+	for _, attr := range []string{"auth_url", "username", "password", "project_name", "user_domain_name"} {
+		if attrs[attr], err = client.provider.Attr(attr); err != nil {
+			return openstack.Environment{}, err
+		}
+	}
+
+	_, pubCIDR, err := net.ParseCIDR(client.config.PublicCIDR)
+	if err != nil {
+		return openstack.Environment{}, err
+	}
+	internalGateway, err := cidr.Host(pubCIDR, 1)
+	if err != nil {
+		return openstack.Environment{}, err
+	}
+	directorInternalIP, err := cidr.Host(pubCIDR, 6)
+	if err != nil {
+		return openstack.Environment{}, err
+	}
+
+	webFlavors, _ := client.flavors()
+	return openstack.Environment{
+		AuthURL:               attrs["auth_url"],
+		CustomOperations:      customOps,
+		DefaultKeyName:        client.config.Deployment,
+		DefaultSecurityGroup:  vmsSecurityGroup,
+		DirectorFlavor:        webFlavors["medium"],
+		DirectorSecurityGroup: directorSecurityGroup,
+		Domain:                attrs["user_domain_name"],
+		ExternalIP:            directorPublicIP,
+		InternalCIDR:          client.config.PublicCIDR,
+		InternalGW:            internalGateway.String(),
+		InternalIP:            directorInternalIP.String(),
+		NetworkID:             networkID,
+		Password:              attrs["password"],
+		PrivateKey:            client.config.PrivateKey,
+		Project:               attrs["project_name"],
+		Region:                client.provider.Region(),
+		Username:              attrs["username"],
+	}, nil
+}
+
+// Locks implements locks for Azure client
+func (client *OpenStackClient) Locks() ([]byte, error) {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return nil, err
+	}
+	return client.boshCLI.Locks(openstack.Environment{
+		ExternalIP: directorPublicIP,
+	}, directorPublicIP, client.config.DirectorPassword, client.config.DirectorCACert)
+}
+
+func (client *OpenStackClient) updateCloudConfig(bosh boshcli.ICLI) error {
+	env, err := client.cloudConfigEnvironment()
+	if err != nil {
+		return err
+	}
+	return bosh.UpdateCloudConfig(env, env.ExternalIP, client.config.DirectorPassword, client.config.DirectorCACert)
+}
+
+// Diff reports the changes a deploy would make to the cloud config and the Concourse deployment
+func (client *OpenStackClient) Diff(creds []byte) (Diff, error) {
+	var diff Diff
+	env, err := client.cloudConfigEnvironment()
+	if err != nil {
+		return diff, err
+	}
+	diff.CloudConfig, err = client.boshCLI.DiffCloudConfig(env, env.ExternalIP, client.config.DirectorPassword, client.config.DirectorCACert)
+	if err != nil {
+		return diff, err
+	}
+	diff.Concourse, err = client.diffConcourse(creds)
+	return diff, err
+}
+
+func (client *OpenStackClient) cloudConfigEnvironment() (openstack.Environment, error) {
+	atcSecurityGroup, err := client.outputs.Get("ATCSecurityGroupName")
+	if err != nil {
+		return openstack.Environment{}, err
+	}
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return openstack.Environment{}, err
+	}
+	networkID, err := client.outputs.Get("NetworkID")
+	if err != nil {
+		return openstack.Environment{}, err
+	}
+	vmsSecurityGroup, err := client.outputs.Get("VMsSecurityGroupName")
+	if err != nil {
+		return openstack.Environment{}, err
+	}
+
+	publicCIDR := client.config.PublicCIDR
+	_, pubCIDR, err := net.ParseCIDR(publicCIDR)
+	if err != nil {
+		return openstack.Environment{}, err
+	}
+	pubGateway, err := cidr.Host(pubCIDR, 1)
+	if err != nil {
+		return openstack.Environment{}, err
+	}
+	publicCIDRStatic, err := formatIPRange(publicCIDR, ", ", []int{6, 7})
+	if err != nil {
+		return openstack.Environment{}, err
+	}
+	publicCIDRReserved, err := formatIPRange(publicCIDR, "-", []int{1, 5})
+	if err != nil {
+		return openstack.Environment{}, err
+	}
+
+	privateCIDR := client.config.PrivateCIDR
+	_, privCIDR, err := net.ParseCIDR(privateCIDR)
+	if err != nil {
+		return openstack.Environment{}, err
+	}
+	privGateway, err := cidr.Host(privCIDR, 1)
+	if err != nil {
+		return openstack.Environment{}, err
+	}
+	privateCIDRReserved, err := formatIPRange(privateCIDR, "-", []int{1, 5})
+	if err != nil {
+		return openstack.Environment{}, err
+	}
+
+	webFlavors, workerFlavors := client.flavors()
+	return openstack.Environment{
+		ATCSecurityGroup:     atcSecurityGroup,
+		CompilationFlavor:    webFlavors["medium"],
+		DefaultSecurityGroup: vmsSecurityGroup,
+		ExternalIP:           directorPublicIP,
+		NetworkID:            networkID,
+		PrivateCIDR:          privateCIDR,
+		PrivateCIDRGateway:   privGateway.String(),
+		PrivateCIDRReserved:  privateCIDRReserved,
+		PublicCIDR:           publicCIDR,
+		PublicCIDRGateway:    pubGateway.String(),
+		PublicCIDRReserved:   publicCIDRReserved,
+		PublicCIDRStatic:     publicCIDRStatic,
+		WebFlavors:           webFlavors,
+		WorkerFlavors:        workerFlavors,
+		Zone:                 client.provider.Zone(client.config.AvailabilityZone),
+	}, nil
+}
+
+func (client *OpenStackClient) uploadConcourseStemcell(bosh boshcli.ICLI) error {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return err
+	}
+	return bosh.UploadConcourseStemcell(openstack.Environment{
+		ExternalIP: directorPublicIP,
+	}, directorPublicIP, client.config.DirectorPassword, client.config.DirectorCACert)
+}
@@ -0,0 +1,32 @@
+package bosh
+
+import (
+	"os"
+
+	"golang.org/x/net/proxy"
+)
+
+const openstackGatewayUser = "vcap"
+
+// SSH opens an interactive session on a Concourse VM, or on the director when instance is "director"
+func (client *OpenStackClient) SSH(instance string) error {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return err
+	}
+
+	if instance == DirectorInstance {
+		return sshDirector(proxy.Direct, directorPublicIP, openstackGatewayUser, client.config.PrivateKey, os.Stdin, os.Stdout, os.Stderr)
+	}
+
+	return sshInstance(
+		client.boshCLI,
+		client.workingdir,
+		directorPublicIP,
+		client.config.DirectorPassword,
+		client.config.DirectorCACert,
+		openstackGatewayUser,
+		client.config.PrivateKey,
+		instance,
+	)
+}
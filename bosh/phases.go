@@ -0,0 +1,54 @@
+package bosh
+
+import "fmt"
+
+// Phases of a director deploy, in the order they run
+const (
+	PhaseCreateEnv   = "create-env"
+	PhaseCloudConfig = "cloud-config"
+	PhaseStemcell    = "stemcell"
+	PhaseDatabases   = "databases"
+	PhaseDeploy      = "bosh-deploy"
+)
+
+// DeployPhases lists the phases of a director deploy, in the order they run
+var DeployPhases = []string{PhaseCreateEnv, PhaseCloudConfig, PhaseStemcell, PhaseDatabases, PhaseDeploy}
+
+// PhaseError records the phase in which a deploy failed
+type PhaseError struct {
+	Phase string
+	Err   error
+}
+
+func (e *PhaseError) Error() string {
+	return e.Err.Error()
+}
+
+type phase struct {
+	name string
+	run  func() error
+}
+
+// runPhases runs each phase in turn starting from the named one, or from the first when from is empty
+func runPhases(from string, phases []phase) error {
+	start := 0
+	if from != "" {
+		start = -1
+		for i, p := range phases {
+			if p.name == from {
+				start = i
+				break
+			}
+		}
+		if start == -1 {
+			return fmt.Errorf("unknown deploy phase %q", from)
+		}
+	}
+
+	for _, p := range phases[start:] {
+		if err := p.run(); err != nil {
+			return &PhaseError{Phase: p.name, Err: err}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package bosh
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("runPhases", func() {
+	var ran []string
+
+	phases := func(failing string) []phase {
+		var p []phase
+		for _, name := range DeployPhases {
+			name := name
+			p = append(p, phase{name, func() error {
+				ran = append(ran, name)
+				if name == failing {
+					return errors.New("failed")
+				}
+				return nil
+			}})
+		}
+		return p
+	}
+
+	BeforeEach(func() {
+		ran = nil
+	})
+
+	It("runs every phase in order", func() {
+		Expect(runPhases("", phases(""))).To(Succeed())
+		Expect(ran).To(Equal(DeployPhases))
+	})
+
+	It("starts from the named phase", func() {
+		Expect(runPhases(PhaseStemcell, phases(""))).To(Succeed())
+		Expect(ran).To(Equal([]string{PhaseStemcell, PhaseDatabases, PhaseDeploy}))
+	})
+
+	It("reports the phase that failed", func() {
+		err := runPhases("", phases(PhaseCloudConfig))
+		Expect(err).To(Equal(&PhaseError{Phase: PhaseCloudConfig, Err: errors.New("failed")}))
+		Expect(err).To(MatchError("failed"))
+		Expect(ran).To(Equal([]string{PhaseCreateEnv, PhaseCloudConfig}))
+	})
+
+	It("rejects an unknown phase", func() {
+		Expect(runPhases("nope", phases(""))).To(MatchError(`unknown deploy phase "nope"`))
+		Expect(ran).To(BeEmpty())
+	})
+})
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+)
+
+//go:generate counterfeiter . ICLI
+// ICLI dumps and restores databases using the postgres client tools
+type ICLI interface {
+	Dump(uri string) ([]byte, error)
+	Restore(uri string, dump []byte) error
+}
+
+// CLI struct holds the abstraction of execCmd
+type CLI struct {
+	execCmd       func(string, ...string) *exec.Cmd
+	pgDumpPath    string
+	pgRestorePath string
+}
+
+// Option defines the arbitary element of Options for New
+type Option func(*CLI) error
+
+// New provides a new CLI
+// pg_dump and pg_restore are expected to be on the PATH
+func New(ops ...Option) (ICLI, error) {
+	c := &CLI{
+		execCmd:       exec.Command,
+		pgDumpPath:    "pg_dump",
+		pgRestorePath: "pg_restore",
+	}
+	for _, op := range ops {
+		if err := op(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Dump returns a custom format archive of the database at uri
+func (c *CLI) Dump(uri string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd, err := c.command(c.pgDumpPath, uri, "--format=custom", "--no-owner", "--no-privileges")
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("error running pg_dump: [%v] %s", err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
+// Restore replaces the contents of the database at uri with a dump taken by Dump
+func (c *CLI) Restore(uri string, dump []byte) error {
+	var stderr bytes.Buffer
+	cmd, err := c.command(c.pgRestorePath, uri, "--clean", "--if-exists", "--no-owner", "--no-privileges", "--single-transaction")
+	if err != nil {
+		return err
+	}
+	cmd.Stdin = bytes.NewReader(dump)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running pg_restore: [%v] %s", err, stderr.String())
+	}
+	return nil
+}
+
+// command returns the command to run the tool at path against the database at uri. The password is passed in
+// PGPASSWORD instead of the URI, as the command line can be read by other users
+func (c *CLI) command(path, uri string, args ...string) (*exec.Cmd, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		// The error would include the password
+		return nil, errors.New("invalid database URI")
+	}
+	var password string
+	if u.User != nil {
+		password, _ = u.User.Password()
+		u.User = url.User(u.User.Username())
+	}
+	cmd := c.execCmd(path, append(args, "--dbname="+u.String())...)
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	if password != "" {
+		cmd.Env = append(cmd.Env, "PGPASSWORD="+password)
+	}
+	return cmd, nil
+}
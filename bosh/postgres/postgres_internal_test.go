@@ -0,0 +1,10 @@
+package postgres
+
+import "os/exec"
+
+func FakeExec(execCmd func(string, ...string) *exec.Cmd) Option {
+	return func(c *CLI) error {
+		c.execCmd = execCmd
+		return nil
+	}
+}
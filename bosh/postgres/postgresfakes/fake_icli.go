@@ -0,0 +1,195 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package postgresfakes
+
+import (
+	"sync"
+
+	"github.com/EngineerBetter/concourse-up/bosh/postgres"
+)
+
+type FakeICLI struct {
+	DumpStub        func(string) ([]byte, error)
+	dumpMutex       sync.RWMutex
+	dumpArgsForCall []struct {
+		arg1 string
+	}
+	dumpReturns struct {
+		result1 []byte
+		result2 error
+	}
+	dumpReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
+	RestoreStub        func(string, []byte) error
+	restoreMutex       sync.RWMutex
+	restoreArgsForCall []struct {
+		arg1 string
+		arg2 []byte
+	}
+	restoreReturns struct {
+		result1 error
+	}
+	restoreReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeICLI) Dump(arg1 string) ([]byte, error) {
+	fake.dumpMutex.Lock()
+	ret, specificReturn := fake.dumpReturnsOnCall[len(fake.dumpArgsForCall)]
+	fake.dumpArgsForCall = append(fake.dumpArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("Dump", []interface{}{arg1})
+	fake.dumpMutex.Unlock()
+	if fake.DumpStub != nil {
+		return fake.DumpStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.dumpReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeICLI) DumpCallCount() int {
+	fake.dumpMutex.RLock()
+	defer fake.dumpMutex.RUnlock()
+	return len(fake.dumpArgsForCall)
+}
+
+func (fake *FakeICLI) DumpCalls(stub func(string) ([]byte, error)) {
+	fake.dumpMutex.Lock()
+	defer fake.dumpMutex.Unlock()
+	fake.DumpStub = stub
+}
+
+func (fake *FakeICLI) DumpArgsForCall(i int) string {
+	fake.dumpMutex.RLock()
+	defer fake.dumpMutex.RUnlock()
+	argsForCall := fake.dumpArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeICLI) DumpReturns(result1 []byte, result2 error) {
+	fake.dumpMutex.Lock()
+	defer fake.dumpMutex.Unlock()
+	fake.DumpStub = nil
+	fake.dumpReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeICLI) DumpReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.dumpMutex.Lock()
+	defer fake.dumpMutex.Unlock()
+	fake.DumpStub = nil
+	if fake.dumpReturnsOnCall == nil {
+		fake.dumpReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.dumpReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeICLI) Restore(arg1 string, arg2 []byte) error {
+	var arg2Copy []byte
+	if arg2 != nil {
+		arg2Copy = make([]byte, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.restoreMutex.Lock()
+	ret, specificReturn := fake.restoreReturnsOnCall[len(fake.restoreArgsForCall)]
+	fake.restoreArgsForCall = append(fake.restoreArgsForCall, struct {
+		arg1 string
+		arg2 []byte
+	}{arg1, arg2Copy})
+	fake.recordInvocation("Restore", []interface{}{arg1, arg2Copy})
+	fake.restoreMutex.Unlock()
+	if fake.RestoreStub != nil {
+		return fake.RestoreStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.restoreReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeICLI) RestoreCallCount() int {
+	fake.restoreMutex.RLock()
+	defer fake.restoreMutex.RUnlock()
+	return len(fake.restoreArgsForCall)
+}
+
+func (fake *FakeICLI) RestoreCalls(stub func(string, []byte) error) {
+	fake.restoreMutex.Lock()
+	defer fake.restoreMutex.Unlock()
+	fake.RestoreStub = stub
+}
+
+func (fake *FakeICLI) RestoreArgsForCall(i int) (string, []byte) {
+	fake.restoreMutex.RLock()
+	defer fake.restoreMutex.RUnlock()
+	argsForCall := fake.restoreArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeICLI) RestoreReturns(result1 error) {
+	fake.restoreMutex.Lock()
+	defer fake.restoreMutex.Unlock()
+	fake.RestoreStub = nil
+	fake.restoreReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeICLI) RestoreReturnsOnCall(i int, result1 error) {
+	fake.restoreMutex.Lock()
+	defer fake.restoreMutex.Unlock()
+	fake.RestoreStub = nil
+	if fake.restoreReturnsOnCall == nil {
+		fake.restoreReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.restoreReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeICLI) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.dumpMutex.RLock()
+	defer fake.dumpMutex.RUnlock()
+	fake.restoreMutex.RLock()
+	defer fake.restoreMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeICLI) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ postgres.ICLI = new(FakeICLI)
@@ -0,0 +1,42 @@
+package bosh
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/EngineerBetter/concourse-up/bosh/boshcli"
+	"github.com/EngineerBetter/concourse-up/bosh/postgres"
+	"github.com/EngineerBetter/concourse-up/bosh/workingdir"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/jumpbox"
+	"github.com/EngineerBetter/concourse-up/terraform"
+)
+
+// IAAS holds what BOSH needs to deploy the director and Concourse on an IAAS
+type IAAS struct {
+	// NewClient creates the IAAS specific client. tunnel is nil unless the deployment is private
+	NewClient func(config config.Config, outputs terraform.Outputs, workingdir workingdir.IClient, stdout, stderr io.Writer, provider iaas.Provider, boshCLI boshcli.ICLI, postgres postgres.ICLI, tunnel *jumpbox.Tunnel) (IClient, error)
+	// ConcourseVersions is the ops file which sets the versions of the Concourse releases and stemcell
+	ConcourseVersions []byte
+	// ConcourseSHAs is the ops file which sets the SHAs of the Concourse releases
+	ConcourseSHAs []byte
+}
+
+var iaases = make(map[iaas.Name]IAAS)
+
+// Register makes the BOSH client of an IAAS available to New
+func Register(name iaas.Name, i IAAS) {
+	if _, ok := iaases[name]; ok {
+		panic("bosh: Register called twice for " + name.String())
+	}
+	iaases[name] = i
+}
+
+func lookup(name iaas.Name) (IAAS, error) {
+	i, ok := iaases[name]
+	if !ok {
+		return IAAS{}, fmt.Errorf("IAAS not supported: %s", name)
+	}
+	return i, nil
+}
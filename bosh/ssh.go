@@ -0,0 +1,96 @@
+package bosh
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+
+	"github.com/EngineerBetter/concourse-up/bosh/boshcli"
+	"github.com/EngineerBetter/concourse-up/bosh/workingdir"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/terminal"
+	"golang.org/x/net/proxy"
+)
+
+const gatewayKeyFilename = "gateway.pem"
+
+// sshInstance runs an interactive bosh ssh session on instance, using the director as the gateway
+// The private key is written to the working directory so it is removed by Cleanup
+func sshInstance(boshCLI boshcli.ICLI, workingdir workingdir.IClient, directorPublicIP, password, ca, gatewayUser, privateKey, instance string) error {
+	keyPath, err := workingdir.SaveFileToWorkingDir(gatewayKeyFilename, []byte(privateKey))
+	if err != nil {
+		return fmt.Errorf("failed to save gateway private key: [%v]", err)
+	}
+	return boshCLI.RunAuthenticatedCommand(
+		"ssh",
+		directorPublicIP,
+		password,
+		ca,
+		false,
+		os.Stdout,
+		instance,
+		"--gw-host", directorPublicIP,
+		"--gw-user", gatewayUser,
+		"--gw-private-key", keyPath,
+	)
+}
+
+// sshDirector opens an interactive shell on the director, connecting to it with dialer
+func sshDirector(dialer proxy.Dialer, directorPublicIP, user, privateKey string, stdin *os.File, stdout, stderr io.Writer) error {
+	key, err := ssh.ParsePrivateKey([]byte(privateKey))
+	if err != nil {
+		return fmt.Errorf("failed to parse private key for director: [%v]", err)
+	}
+	addr := net.JoinHostPort(directorPublicIP, "22")
+	netConn, err := dialer.Dial("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to director: [%v]", err)
+	}
+	c, chans, reqs, err := ssh.NewClientConn(netConn, addr, &ssh.ClientConfig{
+		User:            user,
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(key)},
+	})
+	if err != nil {
+		netConn.Close()
+		return fmt.Errorf("failed to connect to director: [%v]", err)
+	}
+	conn := ssh.NewClient(c, chans, reqs)
+	defer conn.Close()
+
+	session, err := conn.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	session.Stdin = stdin
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	fd := int(stdin.Fd())
+	if terminal.IsTerminal(fd) {
+		state, err := terminal.MakeRaw(fd)
+		if err != nil {
+			return err
+		}
+		defer terminal.Restore(fd, state)
+
+		width, height, err := terminal.GetSize(fd)
+		if err != nil {
+			return err
+		}
+		term := os.Getenv("TERM")
+		if term == "" {
+			term = "xterm"
+		}
+		if err = session.RequestPty(term, height, width, ssh.TerminalModes{}); err != nil {
+			return err
+		}
+	}
+
+	if err = session.Shell(); err != nil {
+		return err
+	}
+	return session.Wait()
+}
@@ -0,0 +1,33 @@
+package bosh
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
+)
+
+const concourseVersionPath = "/releases/name=concourse/version"
+
+// ConcourseVersion returns the version of the Concourse release this build deploys on the provider's IAAS
+func ConcourseVersion(provider iaas.Provider) (string, error) {
+	i, _ := lookup(provider.IAAS())
+	return concourseVersion(i.ConcourseVersions)
+}
+
+func concourseVersion(versions []byte) (string, error) {
+	var ops []struct {
+		Path  string      `json:"path"`
+		Value interface{} `json:"value"`
+	}
+	if err := json.Unmarshal(versions, &ops); err != nil {
+		return "", fmt.Errorf("failed to parse concourse versions: [%v]", err)
+	}
+	for _, op := range ops {
+		if op.Path == concourseVersionPath {
+			return fmt.Sprint(op.Value), nil
+		}
+	}
+	return "", errors.New("no concourse release version was found")
+}
@@ -0,0 +1,22 @@
+package bosh
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("concourseVersion", func() {
+	It("finds the concourse release version", func() {
+		version, err := concourseVersion([]byte(`[
+			{"type": "replace", "path": "/stemcells/alias=xenial/version", "value": "170.9"},
+			{"type": "replace", "path": "/releases/name=concourse/version", "value": "5.0.1"}
+		]`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(version).To(Equal("5.0.1"))
+	})
+
+	It("returns an error when there is no concourse release", func() {
+		_, err := concourseVersion([]byte(`[{"type": "replace", "path": "/stemcells/alias=xenial/version", "value": "170.9"}]`))
+		Expect(err).To(MatchError("no concourse release version was found"))
+	})
+})
@@ -4,7 +4,7 @@ package workingdirfakes
 import (
 	"sync"
 
-	"github.com/EngineerBetter/concourse-up/bosh/internal/workingdir"
+	"github.com/EngineerBetter/concourse-up/bosh/workingdir"
 )
 
 type FakeIClient struct {
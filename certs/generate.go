@@ -110,6 +110,42 @@ func customNewDNSProviderServiceAccount(saFile string, config *gcloud.Config) (*
 	return gcloud.NewDNSProviderConfig(config)
 }
 
+// dnsProviders create the lego DNS providers which the IAASs name in their registrations
+var dnsProviders = map[string]func() (challenge.Provider, error){
+	"route53": func() (challenge.Provider, error) {
+		dnsConfig := route53.NewDefaultConfig()
+		dnsConfig.PropagationTimeout = 10 * time.Minute
+		dnsConfig.PollingInterval = 30 * time.Second
+		return route53.NewDNSProviderConfig(dnsConfig)
+	},
+	"gcloud": func() (challenge.Provider, error) {
+		dnsConfig := gcloud.NewDefaultConfig()
+		dnsConfig.PropagationTimeout = 10 * time.Minute
+		dnsConfig.PollingInterval = 30 * time.Second
+		return customNewDNSProviderServiceAccount(os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"), dnsConfig)
+	},
+}
+
+// newDNSProvider returns the DNS provider which answers the ACME challenges for the domains of provider's IAAS
+func newDNSProvider(provider iaas.Provider) (challenge.Provider, error) {
+	r, err := iaas.Lookup(provider.IAAS())
+	if err != nil {
+		return nil, err
+	}
+	if r.DNSProvider == "" {
+		records, ok := provider.(txtRecords)
+		if !ok {
+			return nil, fmt.Errorf("%s provider cannot manage DNS records", provider.IAAS())
+		}
+		return iaasDNSProvider{records: records}, nil
+	}
+	newProvider, ok := dnsProviders[r.DNSProvider]
+	if !ok {
+		return nil, fmt.Errorf("unknown DNS provider [%s] for %s", r.DNSProvider, provider.IAAS())
+	}
+	return newProvider()
+}
+
 // Generate generates certs for use in a bosh director manifest
 func Generate(constructor func(u *User) (*lego.Client, error), caName string, provider iaas.Provider, ipOrDomains ...string) (*Certs, error) {
 
@@ -126,32 +162,13 @@ func Generate(constructor func(u *User) (*lego.Client, error), caName string, pr
 	c.Challenge.Remove(challenge.HTTP01)
 	c.Challenge.Remove(challenge.TLSALPN01)
 
-	switch provider.IAAS() {
-	case iaas.AWS:
-		dnsConfig := route53.NewDefaultConfig()
-		dnsConfig.PropagationTimeout = 10 * time.Minute
-		dnsConfig.PollingInterval = 30 * time.Second
-		dnsProvider, err1 := route53.NewDNSProviderConfig(dnsConfig)
-		if err1 != nil {
-			return nil, err1
-		}
-
-		err1 = c.Challenge.SetDNS01Provider(dnsProvider)
-		if err1 != nil {
-			return nil, err1
-		}
-	case iaas.GCP:
-		dnsConfig := gcloud.NewDefaultConfig()
-		dnsConfig.PropagationTimeout = 10 * time.Minute
-		dnsConfig.PollingInterval = 30 * time.Second
-		dnsProvider, err1 := customNewDNSProviderServiceAccount(os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"), dnsConfig)
-		if err1 != nil {
-			return nil, err1
-		}
-		err1 = c.Challenge.SetDNS01Provider(dnsProvider)
-		if err1 != nil {
-			return nil, err1
-		}
+	dnsProvider, err := newDNSProvider(provider)
+	if err != nil {
+		return nil, err
+	}
+	err = c.Challenge.SetDNS01Provider(dnsProvider)
+	if err != nil {
+		return nil, err
 	}
 	u.r, err = c.Registration.Register(registration.RegisterOptions{TermsOfServiceAgreed: true})
 	if err != nil {
@@ -0,0 +1,35 @@
+package certs
+
+import (
+	"time"
+
+	"github.com/xenolf/lego/challenge/dns01"
+)
+
+// txtRecords manages TXT records in the DNS zones of the IAAS. *iaas.AzureProvider and *iaas.OpenStackProvider implement it
+type txtRecords interface {
+	SetTXTRecord(fqdn, value string, ttl int) error
+	DeleteTXTRecord(fqdn string) error
+}
+
+// iaasDNSProvider solves DNS-01 challenges with the DNS service of the IAAS, for the IAASs lego has no vendored provider for
+type iaasDNSProvider struct {
+	records txtRecords
+}
+
+// Present creates the TXT record which proves control of domain
+func (d iaasDNSProvider) Present(domain, token, keyAuth string) error {
+	fqdn, value := dns01.GetRecord(domain, keyAuth)
+	return d.records.SetTXTRecord(fqdn, value, 60)
+}
+
+// CleanUp deletes the TXT record Present created
+func (d iaasDNSProvider) CleanUp(domain, token, keyAuth string) error {
+	fqdn, _ := dns01.GetRecord(domain, keyAuth)
+	return d.records.DeleteTXTRecord(fqdn)
+}
+
+// Timeout returns how long to wait for the record to propagate, and how often to check, matching the other IAASs
+func (d iaasDNSProvider) Timeout() (timeout, interval time.Duration) {
+	return 10 * time.Minute, 30 * time.Second
+}
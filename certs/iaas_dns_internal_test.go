@@ -0,0 +1,44 @@
+package certs
+
+import (
+	"strings"
+	"testing"
+)
+
+type fakeTXTRecords struct {
+	set     map[string]string
+	deleted []string
+}
+
+func (f *fakeTXTRecords) SetTXTRecord(fqdn, value string, ttl int) error {
+	f.set[fqdn] = value
+	return nil
+}
+
+func (f *fakeTXTRecords) DeleteTXTRecord(fqdn string) error {
+	f.deleted = append(f.deleted, fqdn)
+	return nil
+}
+
+func TestIAASDNSProvider(t *testing.T) {
+	records := &fakeTXTRecords{set: map[string]string{}}
+	provider := iaasDNSProvider{records: records}
+
+	if err := provider.Present("ci.example.com", "token", "keyAuth"); err != nil {
+		t.Fatalf("Present() error = %v", err)
+	}
+	value, ok := records.set["_acme-challenge.ci.example.com."]
+	if !ok {
+		t.Fatalf("Present() set records %v, want _acme-challenge.ci.example.com.", records.set)
+	}
+	if value == "" || strings.Contains(value, "keyAuth") {
+		t.Errorf("Present() set the record to %q, want the digest of the key authorisation", value)
+	}
+
+	if err := provider.CleanUp("ci.example.com", "token", "keyAuth"); err != nil {
+		t.Fatalf("CleanUp() error = %v", err)
+	}
+	if len(records.deleted) != 1 || records.deleted[0] != "_acme-challenge.ci.example.com." {
+		t.Errorf("CleanUp() deleted %v, want [_acme-challenge.ci.example.com.]", records.deleted)
+	}
+}
@@ -0,0 +1,155 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// secretFlags have their values redacted in the audit log
+var secretFlags = map[string]bool{
+	"github-auth-client-secret": true,
+	"tls-key":                   true,
+}
+
+// audit is an audit entry for a command which is still running
+type audit struct {
+	configClient config.IClient
+	entry        config.AuditEntry
+	before       *config.Config
+	started      time.Time
+}
+
+func startAudit(c *cli.Context, provider iaas.Provider, configClient config.IClient, command string) *audit {
+	operator, err := provider.Identity()
+	if err != nil {
+		operator = fmt.Sprintf("unknown (%v)", err)
+	}
+
+	a := &audit{
+		configClient: configClient,
+		entry: config.AuditEntry{
+			Operator: operator,
+			Version:  c.App.Version,
+			Command:  command,
+			Flags:    setFlags(c),
+		},
+		started: time.Now(),
+	}
+	a.entry.Time = a.started.UTC()
+	a.before = loadConfigIfExists(configClient)
+	return a
+}
+
+// finish records the outcome of the command, returning err or the error recording it
+func (a *audit) finish(err error) error {
+	a.entry.Duration = time.Since(a.started).Round(time.Second).String()
+	a.entry.Outcome = config.AuditSuccess
+	if err != nil {
+		a.entry.Outcome = config.AuditFailure
+		a.entry.Error = err.Error()
+	}
+	if a.before != nil {
+		if after := loadConfigIfExists(a.configClient); after != nil {
+			a.entry.Changes = config.Diff(*a.before, *after)
+		}
+	}
+
+	err1 := config.AppendAuditEntry(a.configClient, a.entry)
+	if err != nil {
+		if err1 != nil {
+			return fmt.Errorf("%v (also failed to record the audit entry: %v)", err, err1)
+		}
+		return err
+	}
+	if err1 != nil {
+		return fmt.Errorf("%s succeeded but the audit entry could not be recorded: [%v]", a.entry.Command, err1)
+	}
+	return nil
+}
+
+// finishDeleted records the success of a command which deleted the config bucket, and the audit log with it.
+// The entry is written to stdout, and the log is kept with the entry appended in the file at path
+func (a *audit) finishDeleted(entries []config.AuditEntry, path string, stdout io.Writer) error {
+	a.entry.Duration = time.Since(a.started).Round(time.Second).String()
+	a.entry.Outcome = config.AuditSuccess
+	entries = append(entries, a.entry)
+
+	var log bytes.Buffer
+	encoder := json.NewEncoder(&log)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	line, err := json.Marshal(a.entry)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Audit entry: %s\n", line)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("%s succeeded but the audit log could not be kept: [%v]", a.entry.Command, err)
+	}
+	if err := ioutil.WriteFile(path, log.Bytes(), 0600); err != nil {
+		return fmt.Errorf("%s succeeded but the audit log could not be kept: [%v]", a.entry.Command, err)
+	}
+	fmt.Fprintf(stdout, "The audit log has been kept in %s\n", path)
+	return nil
+}
+
+// deletedAuditLogPath is where the audit log of a destroyed deployment is kept, in the user's cache dir
+func deletedAuditLogPath(name, namespace string) (string, error) {
+	path, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	if namespace != "" {
+		name = fmt.Sprintf("%s-%s", name, namespace)
+	}
+	return filepath.Join(path, "concourse-up", "audit", name+"-audit-log.jsonl"), nil
+}
+
+// withAuditEntry runs action and appends the outcome to the deployment's audit log
+func withAuditEntry(c *cli.Context, provider iaas.Provider, configClient config.IClient, command string, action func() error) error {
+	a := startAudit(c, provider, configClient, command)
+	return a.finish(action())
+}
+
+// setFlags lists the flags passed to the command, redacting secrets
+func setFlags(c *cli.Context) []config.FieldChange {
+	var flags []config.FieldChange
+	for _, name := range c.FlagNames() {
+		if !c.IsSet(name) {
+			continue
+		}
+		value := fmt.Sprint(c.Generic(name))
+		if secretFlags[name] {
+			value = config.Redacted
+		}
+		flags = append(flags, config.FieldChange{Field: name, To: value})
+	}
+	return flags
+}
+
+// loadConfigIfExists returns nil when the deployment has no config yet, or it cannot be read
+func loadConfigIfExists(configClient config.IClient) *config.Config {
+	exists, err := configClient.ConfigExists()
+	if err != nil || !exists {
+		return nil
+	}
+	conf, err := configClient.Load()
+	if err != nil {
+		return nil
+	}
+	return &conf
+}
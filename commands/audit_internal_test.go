@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EngineerBetter/concourse-up/config"
+)
+
+func Test_auditFinishDeleted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "concourse-up-audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit", "ci-audit-log.jsonl")
+
+	a := &audit{
+		entry:   config.AuditEntry{Command: "destroy", Operator: "operator"},
+		started: time.Now(),
+	}
+	var stdout bytes.Buffer
+	err = a.finishDeleted([]config.AuditEntry{{Command: "deploy", Outcome: config.AuditSuccess}}, path, &stdout)
+	if err != nil {
+		t.Fatalf("finishDeleted() error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), `"command":"destroy"`) || !strings.Contains(stdout.String(), path) {
+		t.Errorf("finishDeleted() wrote %q, want the entry and where the log is kept", stdout.String())
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"command":"deploy"`) || !strings.Contains(lines[1], `"outcome":"success"`) {
+		t.Errorf("kept audit log = %q, want the deploy then the successful destroy", contents)
+	}
+}
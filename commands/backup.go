@@ -0,0 +1,187 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/certs"
+	"github.com/EngineerBetter/concourse-up/commands/backup"
+	"github.com/EngineerBetter/concourse-up/concourse"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/fly"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/terraform"
+	"github.com/EngineerBetter/concourse-up/util"
+
+	"gopkg.in/urfave/cli.v1"
+)
+
+var initialBackupArgs backup.Args
+
+var backupFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "region",
+		Usage:       "(optional) AWS region",
+		EnvVar:      "AWS_REGION",
+		Destination: &initialBackupArgs.Region,
+	},
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(optional) IAAS, can be AWS, GCP, AZURE or OPENSTACK",
+		EnvVar:      "IAAS",
+		Value:       "AWS",
+		Destination: &initialBackupArgs.IAAS,
+	},
+	cli.StringFlag{
+		Name:        "namespace",
+		Usage:       "(optional) Specify a namespace for deployments in order to group them in a meaningful way",
+		EnvVar:      "NAMESPACE",
+		Destination: &initialBackupArgs.Namespace,
+	},
+	cli.StringFlag{
+		Name:        "output, o",
+		Usage:       "(optional) Write the backup to a local file instead of the config bucket",
+		Destination: &initialBackupArgs.Output,
+	},
+}
+
+var restoreFlags = backupFlags[:3]
+
+func backupAction(c *cli.Context, backupArgs backup.Args, provider iaas.Provider) error {
+	name := c.Args().Get(0)
+	if name == "" {
+		return errors.New("Usage is `concourse-up backup <name>`")
+	}
+
+	err := backupArgs.MarkSetFlags(c)
+	if err != nil {
+		return err
+	}
+
+	configClient, err := newConfigClient(provider, name, backupArgs.Namespace)
+	if err != nil {
+		return err
+	}
+	client, err := buildBackupClient(name, c.App.Version, provider, configClient)
+	if err != nil {
+		return err
+	}
+	location, err := client.Backup(backupArgs.Output)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(os.Stdout, "\nBACKUP WRITTEN TO %s\n", location)
+	return err
+}
+
+func restoreAction(c *cli.Context, backupArgs backup.Args, provider iaas.Provider) error {
+	name := c.Args().Get(0)
+	archive := c.Args().Get(1)
+	if name == "" || archive == "" {
+		return errors.New("Usage is `concourse-up restore <name> <backup>`")
+	}
+
+	if !NonInteractiveModeEnabled() {
+		confirm, err := util.CheckConfirmation(os.Stdin, os.Stdout, name)
+		if err != nil {
+			return err
+		}
+
+		if !confirm {
+			fmt.Println("Bailing out...")
+			return nil
+		}
+	}
+
+	err := backupArgs.MarkSetFlags(c)
+	if err != nil {
+		return err
+	}
+
+	configClient, err := newConfigClient(provider, name, backupArgs.Namespace)
+	if err != nil {
+		return err
+	}
+	client, err := buildBackupClient(name, c.App.Version, provider, configClient)
+	if err != nil {
+		return err
+	}
+	return withDeploymentLock(configClient, name, "restore", func() error {
+		return withAuditEntry(c, provider, configClient, "restore", func() error {
+			return client.Restore(archive)
+		})
+	})
+}
+
+func buildBackupClient(name, version string, provider iaas.Provider, configClient config.IClient) (*concourse.Client, error) {
+	terraformClient, err := terraform.New(provider.IAAS(), terraform.DownloadTerraform(), terraformStateBackend())
+	if err != nil {
+		return nil, err
+	}
+
+	tfInputVarsFactory, err := concourse.NewTFInputVarsFactory(provider)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating TFInputVarsFactory [%v]", err)
+	}
+
+	client := concourse.NewClient(
+		provider,
+		terraformClient,
+		tfInputVarsFactory,
+		bosh.New,
+		fly.New,
+		certs.Generate,
+		configClient,
+		nil,
+		os.Stdout,
+		os.Stderr,
+		util.FindUserIP,
+		certs.NewAcmeClient,
+		util.GeneratePasswordWithLength,
+		util.EightRandomLetters,
+		util.GenerateSSHKeyPair,
+		version,
+	)
+
+	return client, nil
+}
+
+var backupCmd = cli.Command{
+	Name:      "backup",
+	Aliases:   []string{"b"},
+	Usage:     "Backs up the Concourse, UAA and CredHub databases and the director state",
+	ArgsUsage: "<name>",
+	Flags:     backupFlags,
+	Action: func(c *cli.Context) error {
+		iaasName, err := iaas.Assosiate(initialBackupArgs.IAAS)
+		if err != nil {
+			return err
+		}
+		provider, err := iaas.New(iaasName, initialBackupArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on backup: [%v]", err)
+		}
+		return backupAction(c, initialBackupArgs, provider)
+	},
+}
+
+var restoreCmd = cli.Command{
+	Name:      "restore",
+	Aliases:   []string{"r"},
+	Usage:     "Restores a backup into a deployment",
+	ArgsUsage: "<name> <backup>",
+	Flags:     restoreFlags,
+	Action: func(c *cli.Context) error {
+		iaasName, err := iaas.Assosiate(initialBackupArgs.IAAS)
+		if err != nil {
+			return err
+		}
+		provider, err := iaas.New(iaasName, initialBackupArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on restore: [%v]", err)
+		}
+		return restoreAction(c, initialBackupArgs, provider)
+	},
+}
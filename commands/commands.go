@@ -1,19 +1,36 @@
 package commands
 
 import (
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/terraform"
 	cli "gopkg.in/urfave/cli.v1"
 )
 
 // Commands is a list of all supported CLI commands
 var Commands = []cli.Command{
+	backupCmd,
+	configCmd,
 	deployCmd,
 	destroyCmd,
+	exportCmd,
+	healthCmd,
+	historyCmd,
+	importCmd,
 	infoCmd,
+	listCmd,
+	logsCmd,
 	maintainCmd,
+	planCmd,
+	restoreCmd,
+	sshCmd,
+	unlockCmd,
 }
 
 var nonInteractive bool
 
+var configBackend string
+
 // GlobalFlags are the global CLIflags
 var GlobalFlags = []cli.Flag{
 	cli.BoolFlag{
@@ -22,9 +39,31 @@ var GlobalFlags = []cli.Flag{
 		Usage:       "Non interactive",
 		Destination: &nonInteractive,
 	},
+	cli.StringFlag{
+		Name:        "config-backend",
+		EnvVar:      config.BackendEnvVar,
+		Usage:       "Where to store deployment config: bucket (default), dir:///path, vault://host:port/mount/path or s3://host:port/bucket/path",
+		Destination: &configBackend,
+	},
 }
 
 // NonInteractiveModeEnabled returns true if --non-interactive true has been passed in
 func NonInteractiveModeEnabled() bool {
 	return nonInteractive
 }
+
+// newConfigClient returns a config client for the deployment, using the backend chosen with --config-backend
+func newConfigClient(provider iaas.Provider, name, namespace string) (*config.Client, error) {
+	return config.NewWithBackend(provider, name, namespace, configBackend)
+}
+
+// terraformStateBackend returns an Option which has terraform keep its state in the backend chosen with --config-backend
+func terraformStateBackend() terraform.Option {
+	return terraform.StateBackend(func(key string) (*terraform.Backend, error) {
+		backend, err := config.NewBackend(configBackend, key)
+		if err != nil || backend == nil {
+			return nil, err
+		}
+		return backend.TerraformBackend(), nil
+	})
+}
@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"io/ioutil"
+	"os"
 	"os/exec"
 
 	. "github.com/onsi/ginkgo"
@@ -37,11 +39,14 @@ var _ = Describe("commands", func() {
 				Expect(session.Out).To(Say("--tls-cert value"))
 				Expect(session.Out).To(Say("--tls-key value"))
 				Expect(session.Out).To(Say("--db-size value"))
+				Expect(session.Out).To(Say("--db-high-availability"))
+				Expect(session.Out).To(Say("--zones value"))
 				Expect(session.Out).To(Say("--vpc-network-range value\\s+\\(optional\\) VPC network CIDR to deploy into, only required if IAAS is AWS"))
 				Expect(session.Out).To(Say("--public-subnet-range value\\s+\\(optional\\) public network CIDR \\(if IAAS is AWS must be within --vpc-network-range\\)"))
 				Expect(session.Out).To(Say("--private-subnet-range value\\s+\\(optional\\) private network CIDR \\(if IAAS is AWS must be within --vpc-network-range\\)"))
 				Expect(session.Out).To(Say("--rds-subnet-range1 value\\s+\\(optional\\) first rds network CIDR \\(if IAAS is AWS must be within --vpc-network-range\\)"))
 				Expect(session.Out).To(Say("--rds-subnet-range2 value\\s+\\(optional\\) second rds network CIDR \\(if IAAS is AWS must be within --vpc-network-range\\)"))
+				Expect(session.Out).To(Say("--private\\s+\\(optional\\) Deploy every VM into private subnets"))
 			})
 		})
 
@@ -126,6 +131,39 @@ var _ = Describe("commands", func() {
 		})
 	})
 
+	Describe("deploy with a deployment file", func() {
+		var specPath string
+
+		BeforeEach(func() {
+			f, err := ioutil.TempFile("", "concourse-up.yml")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = f.WriteString("version: 1\nweb-size: huge\ndb-size: huge\n")
+			Expect(err).ToNot(HaveOccurred())
+			f.Close()
+			specPath = f.Name()
+		})
+
+		AfterEach(func() {
+			os.Remove(specPath)
+		})
+
+		It("validates the fields in the file", func() {
+			command := exec.Command(cliPath, "deploy", "abc", "-f", specPath)
+			session, err := Start(command, GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(session).Should(Exit(1))
+			Eventually(session.Err).Should(Say("unknown web node size"))
+		})
+
+		It("lets flags override the file", func() {
+			command := exec.Command(cliPath, "deploy", "abc", "-f", specPath, "--web-size", "medium")
+			session, err := Start(command, GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(session).Should(Exit(1))
+			Eventually(session.Err).Should(Say("unknown DB size"))
+		})
+	})
+
 	Describe("destroy", func() {
 		Context("When using --help", func() {
 			It("should display usage details", func() {
@@ -148,6 +186,182 @@ var _ = Describe("commands", func() {
 		})
 	})
 
+	Describe("health", func() {
+		Context("When using --help", func() {
+			It("should display usage details", func() {
+				command := exec.Command(cliPath, "health", "--help")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred(), "Error running CLI: "+cliPath)
+				Eventually(session).Should(Exit(0))
+				Expect(session.Out).To(Say("concourse-up health - Checks each layer of a deployment and reports pass, warn or fail"))
+			})
+		})
+
+		Context("When no name is passed in", func() {
+			It("should display correct usage", func() {
+				command := exec.Command(cliPath, "health")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("Usage is `concourse-up health <name>`"))
+			})
+		})
+	})
+
+	Describe("config", func() {
+		Context("When using --help", func() {
+			It("should list the subcommands", func() {
+				command := exec.Command(cliPath, "config", "--help")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred(), "Error running CLI: "+cliPath)
+				Eventually(session).Should(Exit(0))
+				Expect(session.Out).To(Say("encrypt  Encrypts the config, director state and credentials stored in the config bucket"))
+				Expect(session.Out).To(Say("get      Prints the value of a stored config field"))
+				Expect(session.Out).To(Say("history  Lists the versions of the config, director state and credentials, and terraform state"))
+				Expect(session.Out).To(Say("migrate  Migrates the stored config to the current schema version"))
+				Expect(session.Out).To(Say("restore  Restores old versions of the files listed by `config history`"))
+				Expect(session.Out).To(Say("set      Changes stored config fields, which are applied by the next deploy"))
+				Expect(session.Out).To(Say("show     Shows the stored config, with secrets redacted"))
+			})
+		})
+
+		Context("When no name is passed to encrypt", func() {
+			It("should display correct usage", func() {
+				command := exec.Command(cliPath, "config", "encrypt")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("Usage is `concourse-up config encrypt <name>`"))
+			})
+		})
+
+		Context("When no name is passed to history", func() {
+			It("should display correct usage", func() {
+				command := exec.Command(cliPath, "config", "history")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("Usage is `concourse-up config history <name>`"))
+			})
+		})
+
+		Context("When no version is passed to restore", func() {
+			It("should display correct usage", func() {
+				command := exec.Command(cliPath, "config", "restore", "happymeal")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("Usage is `concourse-up config restore --version <id> <name>`"))
+			})
+		})
+
+		Context("When no field is passed to get", func() {
+			It("should display correct usage", func() {
+				command := exec.Command(cliPath, "config", "get", "happymeal")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("Usage is `concourse-up config get <name> <field>`"))
+			})
+		})
+
+		Context("When nothing is passed to set", func() {
+			It("should display correct usage", func() {
+				command := exec.Command(cliPath, "config", "set", "happymeal")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("Usage is `concourse-up config set <name> <field>=<value>...`"))
+			})
+		})
+
+		Context("When no name is passed to show", func() {
+			It("should display correct usage", func() {
+				command := exec.Command(cliPath, "config", "show")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("Usage is `concourse-up config show <name>`"))
+			})
+		})
+
+		Context("When no name is passed to migrate", func() {
+			It("should display correct usage", func() {
+				command := exec.Command(cliPath, "config", "migrate")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("Usage is `concourse-up config migrate <name>`"))
+			})
+		})
+	})
+
+	Describe("export", func() {
+		Context("When using --help", func() {
+			It("should display usage details", func() {
+				command := exec.Command(cliPath, "export", "--help")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred(), "Error running CLI: "+cliPath)
+				Eventually(session).Should(Exit(0))
+				Expect(session.Out).To(Say("concourse-up export - Exports a deployment's config and state to an encrypted archive"))
+			})
+		})
+
+		Context("When no name is passed in", func() {
+			It("should display correct usage", func() {
+				command := exec.Command(cliPath, "export")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("Usage is `concourse-up export <name>`"))
+			})
+		})
+	})
+
+	Describe("import", func() {
+		Context("When using --help", func() {
+			It("should display usage details", func() {
+				command := exec.Command(cliPath, "import", "--help")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred(), "Error running CLI: "+cliPath)
+				Eventually(session).Should(Exit(0))
+				Expect(session.Out).To(Say("concourse-up import - Imports an archive written by export into a new config bucket"))
+			})
+		})
+
+		Context("When no archive is passed in", func() {
+			It("should display correct usage", func() {
+				command := exec.Command(cliPath, "import", "happymeal")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("Usage is `concourse-up import <name> <archive>`"))
+			})
+		})
+	})
+
+	Describe("history", func() {
+		Context("When using --help", func() {
+			It("should display usage details", func() {
+				command := exec.Command(cliPath, "history", "--help")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred(), "Error running CLI: "+cliPath)
+				Eventually(session).Should(Exit(0))
+				Expect(session.Out).To(Say("concourse-up history - Shows the audit log of commands that changed a deployment"))
+			})
+		})
+
+		Context("When no name is passed in", func() {
+			It("should display correct usage", func() {
+				command := exec.Command(cliPath, "history")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("Usage is `concourse-up history <name>`"))
+			})
+		})
+	})
+
 	Describe("info", func() {
 		Context("When using --help", func() {
 			It("should display usage details", func() {
@@ -169,4 +383,160 @@ var _ = Describe("commands", func() {
 			})
 		})
 	})
+
+	Describe("backup", func() {
+		Context("When using --help", func() {
+			It("should display usage details", func() {
+				command := exec.Command(cliPath, "backup", "--help")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred(), "Error running CLI: "+cliPath)
+				Eventually(session).Should(Exit(0))
+				Expect(session.Out).To(Say("concourse-up backup - Backs up the Concourse, UAA and CredHub databases and the director state"))
+			})
+		})
+
+		Context("When no name is passed in", func() {
+			It("should display correct usage", func() {
+				command := exec.Command(cliPath, "backup")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("Usage is `concourse-up backup <name>`"))
+			})
+		})
+	})
+
+	Describe("restore", func() {
+		Context("When using --help", func() {
+			It("should display usage details", func() {
+				command := exec.Command(cliPath, "restore", "--help")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred(), "Error running CLI: "+cliPath)
+				Eventually(session).Should(Exit(0))
+				Expect(session.Out).To(Say("concourse-up restore - Restores a backup into a deployment"))
+			})
+		})
+
+		Context("When no backup is passed in", func() {
+			It("should display correct usage", func() {
+				command := exec.Command(cliPath, "restore", "abc")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("Usage is `concourse-up restore <name> <backup>`"))
+			})
+		})
+	})
+
+	Describe("ssh", func() {
+		Context("When using --help", func() {
+			It("should display usage details", func() {
+				command := exec.Command(cliPath, "ssh", "--help")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred(), "Error running CLI: "+cliPath)
+				Eventually(session).Should(Exit(0))
+				Expect(session.Out).To(Say("concourse-up ssh - Opens a shell on the director or a Concourse VM"))
+			})
+		})
+
+		Context("When no name is passed in", func() {
+			It("should display correct usage", func() {
+				command := exec.Command(cliPath, "ssh")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("Usage is `concourse-up ssh <name> \\[director|web/0|worker/N\\]`"))
+			})
+		})
+	})
+
+	Describe("unlock", func() {
+		Context("When using --help", func() {
+			It("should display usage details", func() {
+				command := exec.Command(cliPath, "unlock", "--help")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred(), "Error running CLI: "+cliPath)
+				Eventually(session).Should(Exit(0))
+				Expect(session.Out).To(Say("concourse-up unlock - Removes the lock held on a deployment by a command that did not finish"))
+			})
+		})
+
+		Context("When no name is passed in", func() {
+			It("should display correct usage", func() {
+				command := exec.Command(cliPath, "unlock", "--force")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("Usage is `concourse-up unlock --force <name>`"))
+			})
+		})
+	})
+
+	Describe("list", func() {
+		Context("When using --help", func() {
+			It("should display usage details", func() {
+				command := exec.Command(cliPath, "list", "--help")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred(), "Error running CLI: "+cliPath)
+				Eventually(session).Should(Exit(0))
+				Expect(session.Out).To(Say("concourse-up list - Lists all deployments visible to the current credentials"))
+			})
+		})
+	})
+
+	Describe("logs", func() {
+		Context("When using --help", func() {
+			It("should display usage details", func() {
+				command := exec.Command(cliPath, "logs", "--help")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred(), "Error running CLI: "+cliPath)
+				Eventually(session).Should(Exit(0))
+				Expect(session.Out).To(Say("concourse-up logs - Tails or downloads logs from the Concourse VMs"))
+			})
+		})
+
+		Context("When an unknown job is passed in", func() {
+			It("should display the supported jobs", func() {
+				command := exec.Command(cliPath, "logs", "abc", "--job", "atc")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say(`unknown job "atc", choose from web, worker, credhub, uaa, grafana`))
+			})
+		})
+	})
+
+	Describe("plan", func() {
+		Context("When using --help", func() {
+			It("should display usage details", func() {
+				command := exec.Command(cliPath, "plan", "--help")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred(), "Error running CLI: "+cliPath)
+				Eventually(session).Should(Exit(0))
+				Expect(session.Out).To(Say("concourse-up plan - Previews the changes a deploy would make"))
+				Expect(session.Out).To(Say("--json"))
+				Expect(session.Out).To(Say("--db-size value"))
+			})
+		})
+
+		Context("When no name is passed in", func() {
+			It("should display correct usage", func() {
+				command := exec.Command(cliPath, "plan")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("Usage is `concourse-up plan <name>`"))
+			})
+		})
+
+		Context("When --json is passed with deploy flags", func() {
+			It("validates the deploy flags", func() {
+				command := exec.Command(cliPath, "plan", "abc", "--json", "--db-size", "huge")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Eventually(session.Err).Should(Say("unknown DB size"))
+			})
+		})
+	})
 })
@@ -0,0 +1,675 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EngineerBetter/concourse-up/commands/deploy"
+	"github.com/EngineerBetter/concourse-up/concourse"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var initialEncryptArgs struct {
+	Region    string
+	IAAS      string
+	Namespace string
+	KMSKey    string
+}
+
+var encryptFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "region",
+		Usage:       "(optional) AWS region",
+		EnvVar:      "AWS_REGION",
+		Destination: &initialEncryptArgs.Region,
+	},
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(optional) IAAS, can be AWS, GCP, AZURE or OPENSTACK",
+		EnvVar:      "IAAS",
+		Value:       "AWS",
+		Destination: &initialEncryptArgs.IAAS,
+	},
+	cli.StringFlag{
+		Name:        "namespace",
+		Usage:       "(optional) Specify a namespace for deployments in order to group them in a meaningful way",
+		EnvVar:      "NAMESPACE",
+		Destination: &initialEncryptArgs.Namespace,
+	},
+	cli.StringFlag{
+		Name:        "kms-key",
+		Usage:       "(optional) AWS KMS key ID, alias or ARN, or GCP Cloud KMS key name, to encrypt with. Without it the passphrase in $" + config.PassphraseEnvVar + " is used",
+		EnvVar:      "KMS_KEY",
+		Destination: &initialEncryptArgs.KMSKey,
+	},
+}
+
+func encryptAction(c *cli.Context, kmsKey, namespace string, provider iaas.Provider) error {
+	name := c.Args().Get(0)
+	if name == "" {
+		return errors.New("Usage is `concourse-up config encrypt <name>`")
+	}
+
+	encryption := config.Encryption{Type: config.EncryptionPassphrase}
+	if kmsKey != "" {
+		encryption = config.Encryption{Type: config.EncryptionKMS, KeyID: kmsKey}
+	}
+	if err := encryption.Validate(); err != nil {
+		return err
+	}
+
+	configClient, err := newConfigClient(provider, name, namespace)
+	if err != nil {
+		return err
+	}
+	filenames := append(append([]string{}, concourse.Assets...), config.AuditLogFilename)
+
+	return withDeploymentLock(configClient, name, "config encrypt", func() error {
+		return withAuditEntry(c, provider, configClient, "config encrypt", func() error {
+			if err := configClient.EnableEncryption(encryption, filenames...); err != nil {
+				return err
+			}
+			fmt.Printf("The config bucket for %s is encrypted with %s\n", name, describeEncryption(encryption))
+			return nil
+		})
+	})
+}
+
+func describeEncryption(encryption config.Encryption) string {
+	if encryption.Type == config.EncryptionKMS {
+		return fmt.Sprintf("KMS key %s", encryption.KeyID)
+	}
+	return fmt.Sprintf("the passphrase in $%s", config.PassphraseEnvVar)
+}
+
+var configEncryptCmd = cli.Command{
+	Name:      "encrypt",
+	Usage:     "Encrypts the config, director state and credentials stored in the config bucket",
+	ArgsUsage: "<name>",
+	Flags:     encryptFlags,
+	Action: func(c *cli.Context) error {
+		iaasName, err := iaas.Assosiate(initialEncryptArgs.IAAS)
+		if err != nil {
+			return err
+		}
+		provider, err := iaas.New(iaasName, initialEncryptArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on config encrypt: [%v]", err)
+		}
+		return encryptAction(c, initialEncryptArgs.KMSKey, initialEncryptArgs.Namespace, provider)
+	},
+}
+
+var initialMigrateArgs struct {
+	Region    string
+	IAAS      string
+	Namespace string
+	DryRun    bool
+}
+
+var migrateFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "region",
+		Usage:       "(optional) AWS region",
+		EnvVar:      "AWS_REGION",
+		Destination: &initialMigrateArgs.Region,
+	},
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(optional) IAAS, can be AWS, GCP, AZURE or OPENSTACK",
+		EnvVar:      "IAAS",
+		Value:       "AWS",
+		Destination: &initialMigrateArgs.IAAS,
+	},
+	cli.StringFlag{
+		Name:        "namespace",
+		Usage:       "(optional) Specify a namespace for deployments in order to group them in a meaningful way",
+		EnvVar:      "NAMESPACE",
+		Destination: &initialMigrateArgs.Namespace,
+	},
+	cli.BoolFlag{
+		Name:        "dry-run",
+		Usage:       "(optional) Show the migrations that would be run without storing the migrated config",
+		Destination: &initialMigrateArgs.DryRun,
+	},
+}
+
+func migrateAction(c *cli.Context, dryRun bool, namespace string, provider iaas.Provider) error {
+	name := c.Args().Get(0)
+	if name == "" {
+		return errors.New("Usage is `concourse-up config migrate <name>`")
+	}
+
+	configClient, err := newConfigClient(provider, name, namespace)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		plan, err := configClient.MigrateConfig(true)
+		if err != nil {
+			return err
+		}
+		return writeMigrationPlan(os.Stdout, plan, true)
+	}
+
+	return withDeploymentLock(configClient, name, "config migrate", func() error {
+		plan, err := configClient.MigrateConfig(false)
+		// The audit entry loads the config already migrated in memory, so record the changes the migrations made instead
+		a := startAudit(c, provider, configClient, "config migrate")
+		a.before = nil
+		a.entry.Changes = plan.Changes
+		if err = a.finish(err); err != nil {
+			return err
+		}
+		return writeMigrationPlan(os.Stdout, plan, false)
+	})
+}
+
+// migrateStoredConfig stores the deployment's config migrated to the current schema version, if it has a config.
+// The caller must hold the deployment lock
+func migrateStoredConfig(configClient *config.Client) error {
+	exists, err := configClient.ConfigExists()
+	if err != nil || !exists {
+		return err
+	}
+	_, err = configClient.MigrateConfig(false)
+	return err
+}
+
+func writeMigrationPlan(out io.Writer, plan config.MigrationPlan, dryRun bool) error {
+	if !plan.Pending() {
+		_, err := fmt.Fprintf(out, "The config is already at schema version %d\n", plan.To)
+		return err
+	}
+
+	verb := "Migrated"
+	if dryRun {
+		verb = "Would migrate"
+	}
+	fmt.Fprintf(out, "%s the config from schema version %d to %d\n", verb, plan.From, plan.To)
+	for _, m := range plan.Migrations {
+		fmt.Fprintf(out, "  %d: %s\n", m.Version, m.Description)
+	}
+	for _, change := range plan.Changes {
+		fmt.Fprintf(out, "  changed:  %s %q -> %q\n", change.Field, change.From, change.To)
+	}
+	if plan.Backup != "" {
+		fmt.Fprintf(out, "The previous config was backed up to %s\n", plan.Backup)
+	}
+	return nil
+}
+
+var configMigrateCmd = cli.Command{
+	Name:      "migrate",
+	Usage:     "Migrates the stored config to the current schema version",
+	ArgsUsage: "<name>",
+	Flags:     migrateFlags,
+	Action: func(c *cli.Context) error {
+		iaasName, err := iaas.Assosiate(initialMigrateArgs.IAAS)
+		if err != nil {
+			return err
+		}
+		provider, err := iaas.New(iaasName, initialMigrateArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on config migrate: [%v]", err)
+		}
+		return migrateAction(c, initialMigrateArgs.DryRun, initialMigrateArgs.Namespace, provider)
+	},
+}
+
+var initialConfigHistoryArgs struct {
+	Region    string
+	IAAS      string
+	Namespace string
+}
+
+var configHistoryFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "region",
+		Usage:       "(optional) AWS region",
+		EnvVar:      "AWS_REGION",
+		Destination: &initialConfigHistoryArgs.Region,
+	},
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(optional) IAAS, can be AWS, GCP, AZURE or OPENSTACK",
+		EnvVar:      "IAAS",
+		Value:       "AWS",
+		Destination: &initialConfigHistoryArgs.IAAS,
+	},
+	cli.StringFlag{
+		Name:        "namespace",
+		Usage:       "(optional) Specify a namespace for deployments in order to group them in a meaningful way",
+		EnvVar:      "NAMESPACE",
+		Destination: &initialConfigHistoryArgs.Namespace,
+	},
+}
+
+func configHistoryAction(c *cli.Context, namespace string, provider iaas.Provider) error {
+	name := c.Args().Get(0)
+	if name == "" {
+		return errors.New("Usage is `concourse-up config history <name>`")
+	}
+
+	configClient, err := newConfigClient(provider, name, namespace)
+	if err != nil {
+		return err
+	}
+	history, err := configClient.History()
+	if err != nil {
+		return err
+	}
+	return writeConfigHistory(os.Stdout, history)
+}
+
+func writeConfigHistory(out io.Writer, history []config.FileVersions) error {
+	for _, file := range history {
+		fmt.Fprintln(out, file.Filename)
+		if len(file.Versions) == 0 {
+			fmt.Fprintln(out, "  no versions")
+		}
+		for _, version := range file.Versions {
+			latest := ""
+			if version.Latest {
+				latest = "  latest"
+			}
+			fmt.Fprintf(out, "  %s  %s  %d bytes%s\n", version.ID, version.Modified.UTC().Format(time.RFC3339), version.Size, latest)
+		}
+		if _, err := fmt.Fprintln(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var configHistoryCmd = cli.Command{
+	Name:      "history",
+	Usage:     "Lists the versions of the config, director state and credentials, and terraform state",
+	ArgsUsage: "<name>",
+	Flags:     configHistoryFlags,
+	Action: func(c *cli.Context) error {
+		iaasName, err := iaas.Assosiate(initialConfigHistoryArgs.IAAS)
+		if err != nil {
+			return err
+		}
+		provider, err := iaas.New(iaasName, initialConfigHistoryArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on config history: [%v]", err)
+		}
+		return configHistoryAction(c, initialConfigHistoryArgs.Namespace, provider)
+	},
+}
+
+var initialConfigRestoreArgs struct {
+	Region    string
+	IAAS      string
+	Namespace string
+	Version   string
+	All       bool
+}
+
+var configRestoreFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "region",
+		Usage:       "(optional) AWS region",
+		EnvVar:      "AWS_REGION",
+		Destination: &initialConfigRestoreArgs.Region,
+	},
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(optional) IAAS, can be AWS, GCP, AZURE or OPENSTACK",
+		EnvVar:      "IAAS",
+		Value:       "AWS",
+		Destination: &initialConfigRestoreArgs.IAAS,
+	},
+	cli.StringFlag{
+		Name:        "namespace",
+		Usage:       "(optional) Specify a namespace for deployments in order to group them in a meaningful way",
+		EnvVar:      "NAMESPACE",
+		Destination: &initialConfigRestoreArgs.Namespace,
+	},
+	cli.StringFlag{
+		Name:        "version",
+		Usage:       "ID of the version to restore, as listed by `config history`",
+		Destination: &initialConfigRestoreArgs.Version,
+	},
+	cli.BoolFlag{
+		Name:        "all",
+		Usage:       "(optional) Restore every file to the version which was latest when --version was written",
+		Destination: &initialConfigRestoreArgs.All,
+	},
+}
+
+func configRestoreAction(c *cli.Context, versionID string, all bool, namespace string, provider iaas.Provider) error {
+	name := c.Args().Get(0)
+	if name == "" || versionID == "" {
+		return errors.New("Usage is `concourse-up config restore --version <id> <name>`")
+	}
+
+	configClient, err := newConfigClient(provider, name, namespace)
+	if err != nil {
+		return err
+	}
+
+	// The history is read holding the lock, so that no other command writes a newer version while restoring
+	return withDeploymentLock(configClient, name, "config restore", func() error {
+		history, err := configClient.History()
+		if err != nil {
+			return err
+		}
+		filename, version, err := config.FindVersion(history, versionID)
+		if err != nil {
+			return err
+		}
+		versions := config.RestoreVersions(history, filename, version, all)
+
+		return withAuditEntry(c, provider, configClient, "config restore", func() error {
+			if config.SecretsInConfig(versions) {
+				exists, err := configClient.HasAsset(config.SecretsFilename)
+				if err != nil {
+					return err
+				}
+				if exists {
+					if err = configClient.DeleteAsset(config.SecretsFilename); err != nil {
+						return err
+					}
+					fmt.Printf("Deleted %s, as the restored config.json holds the secrets\n", config.SecretsFilename)
+				}
+			}
+			for _, filename := range configClient.VersionedFiles() {
+				version, ok := versions[filename]
+				if !ok || version.Latest {
+					continue
+				}
+				if err := configClient.RestoreVersion(filename, version.ID); err != nil {
+					return err
+				}
+				fmt.Printf("Restored %s to version %s from %s\n", filename, version.ID, version.Modified.UTC().Format(time.RFC3339))
+			}
+			return nil
+		})
+	})
+}
+
+var configRestoreCmd = cli.Command{
+	Name:      "restore",
+	Usage:     "Restores old versions of the files listed by `config history`",
+	ArgsUsage: "<name>",
+	Flags:     configRestoreFlags,
+	Action: func(c *cli.Context) error {
+		iaasName, err := iaas.Assosiate(initialConfigRestoreArgs.IAAS)
+		if err != nil {
+			return err
+		}
+		provider, err := iaas.New(iaasName, initialConfigRestoreArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on config restore: [%v]", err)
+		}
+		return configRestoreAction(c, initialConfigRestoreArgs.Version, initialConfigRestoreArgs.All, initialConfigRestoreArgs.Namespace, provider)
+	},
+}
+
+var initialConfigFieldArgs struct {
+	Region      string
+	IAAS        string
+	Namespace   string
+	ShowSecrets bool
+}
+
+var configFieldFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "region",
+		Usage:       "(optional) AWS region",
+		EnvVar:      "AWS_REGION",
+		Destination: &initialConfigFieldArgs.Region,
+	},
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(optional) IAAS, can be AWS, GCP, AZURE or OPENSTACK",
+		EnvVar:      "IAAS",
+		Value:       "AWS",
+		Destination: &initialConfigFieldArgs.IAAS,
+	},
+	cli.StringFlag{
+		Name:        "namespace",
+		Usage:       "(optional) Specify a namespace for deployments in order to group them in a meaningful way",
+		EnvVar:      "NAMESPACE",
+		Destination: &initialConfigFieldArgs.Namespace,
+	},
+}
+
+var configShowFlags = append([]cli.Flag{
+	cli.BoolFlag{
+		Name:        "show-secrets",
+		Usage:       "(optional) Show the values of passwords, keys and other secrets",
+		Destination: &initialConfigFieldArgs.ShowSecrets,
+	},
+}, configFieldFlags...)
+
+func loadConfigForFields(namespace string, provider iaas.Provider, name string) (config.Config, error) {
+	configClient, err := newConfigClient(provider, name, namespace)
+	if err != nil {
+		return config.Config{}, err
+	}
+	return configClient.Load()
+}
+
+func configShowAction(c *cli.Context, showSecrets bool, namespace string, provider iaas.Provider) error {
+	name := c.Args().Get(0)
+	if name == "" {
+		return errors.New("Usage is `concourse-up config show <name>`")
+	}
+
+	conf, err := loadConfigForFields(namespace, provider, name)
+	if err != nil {
+		return err
+	}
+	return writeConfigFields(os.Stdout, config.Fields(conf), showSecrets)
+}
+
+func writeConfigFields(out io.Writer, fields []config.Field, showSecrets bool) error {
+	for _, field := range fields {
+		value := fieldValue(field, showSecrets)
+		if strings.Contains(value, "\n") {
+			value = strconv.Quote(value)
+		}
+		if _, err := fmt.Fprintf(out, "%s: %s\n", field.Name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldValue returns the value of field, redacted if it is a secret unless showSecrets is set
+func fieldValue(field config.Field, showSecrets bool) string {
+	if field.Secret && field.Value != "" && !showSecrets {
+		return config.Redacted
+	}
+	return field.Value
+}
+
+func configGetAction(c *cli.Context, showSecrets bool, namespace string, provider iaas.Provider) error {
+	name := c.Args().Get(0)
+	fieldName := c.Args().Get(1)
+	if name == "" || fieldName == "" {
+		return errors.New("Usage is `concourse-up config get <name> <field>`")
+	}
+
+	conf, err := loadConfigForFields(namespace, provider, name)
+	if err != nil {
+		return err
+	}
+	field, err := config.GetField(conf, fieldName)
+	if err != nil {
+		return err
+	}
+	fmt.Println(fieldValue(field, showSecrets))
+	return nil
+}
+
+func configSetAction(c *cli.Context, namespace string, provider iaas.Provider) error {
+	name := c.Args().Get(0)
+	assignments := c.Args().Tail()
+	if name == "" || len(assignments) == 0 {
+		return errors.New("Usage is `concourse-up config set <name> <field>=<value>...`")
+	}
+
+	configClient, err := newConfigClient(provider, name, namespace)
+	if err != nil {
+		return err
+	}
+
+	return withDeploymentLock(configClient, name, "config set", func() error {
+		return withAuditEntry(c, provider, configClient, "config set", func() error {
+			conf, err := configClient.Load()
+			if err != nil {
+				return err
+			}
+			conf, err = setConfigFields(conf, assignments)
+			if err != nil {
+				return err
+			}
+			if err = validateConfig(conf, provider); err != nil {
+				return err
+			}
+			if err = configClient.Update(conf); err != nil {
+				return err
+			}
+			fmt.Printf("Updated the config for %s. The changes are applied by the next `concourse-up deploy %s`\n", name, name)
+			return nil
+		})
+	})
+}
+
+// setConfigFields applies assignments of the form field=value, taking values in the same form as the deploy flags
+func setConfigFields(conf config.Config, assignments []string) (config.Config, error) {
+	for _, assignment := range assignments {
+		parts := strings.SplitN(assignment, "=", 2)
+		if len(parts) != 2 {
+			return config.Config{}, fmt.Errorf("expected <field>=<value>, got %q", assignment)
+		}
+		fieldName, value := parts[0], parts[1]
+
+		if fieldName == "allow_ips" {
+			var err error
+			if value, err = concourse.FormatAllowIPs(value); err != nil {
+				return config.Config{}, err
+			}
+		}
+
+		var err error
+		if conf, err = config.SetField(conf, fieldName, value); err != nil {
+			return config.Config{}, err
+		}
+	}
+	conf.GithubAuthIsSet = conf.GithubClientID != "" && conf.GithubClientSecret != ""
+
+	return conf, nil
+}
+
+// validateConfig checks the stored values against the rules deploy applies to its flags
+func validateConfig(conf config.Config, provider iaas.Provider) error {
+	dbSize := ""
+	for _, size := range deploy.AllowedDBSizes {
+		if provider.DBType(size) == conf.RDSInstanceClass {
+			dbSize = size
+			break
+		}
+	}
+	if dbSize == "" {
+		return fmt.Errorf("unknown RDS instance class: `%s`", conf.RDSInstanceClass)
+	}
+
+	args := deploy.Args{
+		Domain:                 conf.Domain,
+		WorkerCount:            conf.ConcourseWorkerCount,
+		WorkerSize:             conf.ConcourseWorkerSize,
+		WebSize:                conf.ConcourseWebSize,
+		DBSize:                 dbSize,
+		GithubAuthClientID:     conf.GithubClientID,
+		GithubAuthClientSecret: conf.GithubClientSecret,
+		Tags:                   conf.Tags,
+	}
+	if err := args.Validate(); err != nil {
+		return err
+	}
+
+	return validateCidrRanges(provider, conf.NetworkCIDR, conf.PublicCIDR, conf.PrivateCIDR, conf.RDS1CIDR, conf.RDS2CIDR)
+}
+
+func configFieldProvider(command string) (iaas.Provider, error) {
+	iaasName, err := iaas.Assosiate(initialConfigFieldArgs.IAAS)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := iaas.New(iaasName, initialConfigFieldArgs.Region)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating IAAS provider on config %s: [%v]", command, err)
+	}
+	return provider, nil
+}
+
+var configShowCmd = cli.Command{
+	Name:      "show",
+	Usage:     "Shows the stored config, with secrets redacted",
+	ArgsUsage: "<name>",
+	Flags:     configShowFlags,
+	Action: func(c *cli.Context) error {
+		provider, err := configFieldProvider("show")
+		if err != nil {
+			return err
+		}
+		return configShowAction(c, initialConfigFieldArgs.ShowSecrets, initialConfigFieldArgs.Namespace, provider)
+	},
+}
+
+var configGetCmd = cli.Command{
+	Name:      "get",
+	Usage:     "Prints the value of a stored config field",
+	ArgsUsage: "<name> <field>",
+	Flags:     configShowFlags,
+	Action: func(c *cli.Context) error {
+		provider, err := configFieldProvider("get")
+		if err != nil {
+			return err
+		}
+		return configGetAction(c, initialConfigFieldArgs.ShowSecrets, initialConfigFieldArgs.Namespace, provider)
+	},
+}
+
+var configSetCmd = cli.Command{
+	Name:      "set",
+	Usage:     "Changes stored config fields, which are applied by the next deploy",
+	ArgsUsage: "<name> <field>=<value>...",
+	Flags:     configFieldFlags,
+	Action: func(c *cli.Context) error {
+		provider, err := configFieldProvider("set")
+		if err != nil {
+			return err
+		}
+		return configSetAction(c, initialConfigFieldArgs.Namespace, provider)
+	},
+}
+
+var configCmd = cli.Command{
+	Name:    "config",
+	Aliases: []string{"c"},
+	Usage:   "Manages the settings and state stored in a deployment's config bucket",
+	Subcommands: []cli.Command{
+		configEncryptCmd,
+		configGetCmd,
+		configHistoryCmd,
+		configMigrateCmd,
+		configRestoreCmd,
+		configSetCmd,
+		configShowCmd,
+	},
+}
@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/iaas/iaasfakes"
+)
+
+func Test_setConfigFields(t *testing.T) {
+	provider := &iaasfakes.FakeProvider{}
+	provider.IAASReturns(iaas.AWS)
+	provider.DBTypeStub = func(size string) string {
+		return iaas.AWSDBSizes[size]
+	}
+
+	stored := config.Config{
+		AllowIPs:             "\"0.0.0.0/0\"",
+		ConcourseWebSize:     "small",
+		ConcourseWorkerCount: 1,
+		ConcourseWorkerSize:  "xlarge",
+		RDSInstanceClass:     "db.t2.medium",
+	}
+	stored = config.SetDefaultCIDRs(stored, iaas.AWS)
+
+	tests := []struct {
+		name        string
+		assignments []string
+		check       func(config.Config) bool
+		wantErr     string
+	}{
+		{
+			name:        "allowed IPs are stored in the same form as --allow-ips",
+			assignments: []string{"allow_ips=10.0.0.1, 192.168.0.0/16"},
+			check: func(c config.Config) bool {
+				return c.AllowIPs == `"10.0.0.1/32", "192.168.0.0/16"`
+			},
+		},
+		{
+			name:        "several fields can be set at once",
+			assignments: []string{"concourse_worker_count=3", "spot=false", "tags=team=ci,env=prod"},
+			check: func(c config.Config) bool {
+				return c.ConcourseWorkerCount == 3 && !c.Spot && len(c.Tags) == 2
+			},
+		},
+		{
+			name:        "github auth is enabled when both the ID and secret are set",
+			assignments: []string{"github_client_id=id", "github_client_secret=secret"},
+			check: func(c config.Config) bool {
+				return c.GithubAuthIsSet
+			},
+		},
+		{
+			name:        "values are validated like the deploy flags",
+			assignments: []string{"concourse_worker_size=huge"},
+			wantErr:     "unknown worker size: `huge`. Valid sizes are: [medium large xlarge 2xlarge 4xlarge 12xlarge 24xlarge]",
+		},
+		{
+			name:        "github auth needs both the ID and secret",
+			assignments: []string{"github_client_id=id"},
+			wantErr:     "--github-auth-client-id requires --github-auth-client-secret to also be provided",
+		},
+		{
+			name:        "the RDS instance class must be one deploy would choose",
+			assignments: []string{"rds_instance_class=db.x1.huge"},
+			wantErr:     "unknown RDS instance class: `db.x1.huge`",
+		},
+		{
+			name:        "fields which deploy manages cannot be set",
+			assignments: []string{"deployment=other"},
+			wantErr:     `config field "deployment" cannot be set`,
+		},
+		{
+			name:        "assignments need a value",
+			assignments: []string{"spot"},
+			wantErr:     `expected <field>=<value>, got "spot"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := setConfigFields(stored, tt.assignments)
+			if err == nil {
+				err = validateConfig(got, provider)
+			}
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("setConfigFields() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("setConfigFields() error = %v", err)
+			}
+			if !tt.check(got) {
+				t.Errorf("setConfigFields() = %+v", got)
+			}
+		})
+	}
+}
+
+func Test_fieldValue(t *testing.T) {
+	tests := []struct {
+		name        string
+		field       config.Field
+		showSecrets bool
+		want        string
+	}{
+		{"settings are shown", config.Field{Name: "concourse_worker_count", Value: "3"}, false, "3"},
+		{"secrets are redacted", config.Field{Name: "concourse_password", Value: "s3cret", Secret: true}, false, config.Redacted},
+		{"secrets are shown with --show-secrets", config.Field{Name: "concourse_password", Value: "s3cret", Secret: true}, true, "s3cret"},
+		{"empty secrets are left empty", config.Field{Name: "github_client_secret", Secret: true}, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fieldValue(tt.field, tt.showSecrets); got != tt.want {
+				t.Errorf("fieldValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
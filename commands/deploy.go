@@ -3,6 +3,7 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"net"
 	"os"
@@ -23,11 +24,14 @@ import (
 	cli "gopkg.in/urfave/cli.v1"
 )
 
-const maxAllowedNameLength = 12
-
 var initialDeployArgs deploy.Args
 
 var deployFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "file, f",
+		Usage:       "(optional) YAML or JSON deployment file with the same fields as these flags. Flags take precedence over the file",
+		Destination: &initialDeployArgs.SpecFile,
+	},
 	cli.StringFlag{
 		Name:        "region",
 		Usage:       "(optional) AWS region",
@@ -82,7 +86,7 @@ var deployFlags = []cli.Flag{
 	},
 	cli.StringFlag{
 		Name:        "iaas",
-		Usage:       "(optional) IAAS, can be AWS or GCP",
+		Usage:       "(optional) IAAS, can be AWS, GCP, AZURE or OPENSTACK",
 		EnvVar:      "IAAS",
 		Value:       "AWS",
 		Destination: &initialDeployArgs.IAAS,
@@ -101,6 +105,12 @@ var deployFlags = []cli.Flag{
 		Value:       "small",
 		Destination: &initialDeployArgs.DBSize,
 	},
+	cli.BoolFlag{
+		Name:        "db-high-availability",
+		Usage:       "(optional) Keep a standby of the database in another zone, using multi-AZ RDS on AWS or regional Cloud SQL on GCP",
+		EnvVar:      "DB_HIGH_AVAILABILITY",
+		Destination: &initialDeployArgs.DBHighAvailability,
+	},
 	cli.BoolTFlag{
 		Name:        "spot",
 		Usage:       "(optional) Use spot instances for workers. Can be true/false (default: true)",
@@ -149,6 +159,12 @@ var deployFlags = []cli.Flag{
 		EnvVar:      "ZONE",
 		Destination: &initialDeployArgs.Zone,
 	},
+	cli.StringFlag{
+		Name:        "zones",
+		Usage:       "(optional) Comma separated list of availability zones to spread workers across, starting with the zone of the director and web nodes",
+		EnvVar:      "ZONES",
+		Destination: &initialDeployArgs.Zones,
+	},
 	cli.StringFlag{
 		Name:        "vpc-network-range",
 		Usage:       "(optional) VPC network CIDR to deploy into, only required if IAAS is AWS",
@@ -179,6 +195,54 @@ var deployFlags = []cli.Flag{
 		EnvVar:      "RDS_SUBNET_RANGE2",
 		Destination: &initialDeployArgs.RDS2CIDR,
 	},
+	cli.BoolFlag{
+		Name:        "private",
+		Usage:       "(optional) Deploy every VM into private subnets and reach the director through a jumpbox. Only supported on AWS, on the first deploy",
+		EnvVar:      "PRIVATE",
+		Destination: &initialDeployArgs.Private,
+	},
+	cli.StringFlag{
+		Name:        "vpc-id",
+		Usage:       "(optional) Existing VPC to deploy into instead of creating one, only on AWS and on the first deploy. Requires --public-subnet-id, --private-subnet-id and --rds-subnet-ids",
+		EnvVar:      "VPC_ID",
+		Destination: &initialDeployArgs.VPCID,
+	},
+	cli.StringFlag{
+		Name:        "public-subnet-id",
+		Usage:       "(optional) Existing subnet of --vpc-id for the director and web node, with a NAT gateway for the private subnet",
+		EnvVar:      "PUBLIC_SUBNET_ID",
+		Destination: &initialDeployArgs.PublicSubnetID,
+	},
+	cli.StringFlag{
+		Name:        "private-subnet-id",
+		Usage:       "(optional) Existing subnet of --vpc-id for the workers, in the same zone as --public-subnet-id",
+		EnvVar:      "PRIVATE_SUBNET_ID",
+		Destination: &initialDeployArgs.PrivateSubnetID,
+	},
+	cli.StringFlag{
+		Name:        "rds-subnet-ids",
+		Usage:       "(optional) Comma separated list of at least two existing subnets of --vpc-id, in different zones, for the RDS instance",
+		EnvVar:      "RDS_SUBNET_IDS",
+		Destination: &initialDeployArgs.RDSSubnetIDs,
+	},
+	cli.StringFlag{
+		Name:        "network",
+		Usage:       "(optional) Existing network to deploy into instead of creating one, only on GCP and on the first deploy. Requires --public-subnetwork and --private-subnetwork",
+		EnvVar:      "NETWORK",
+		Destination: &initialDeployArgs.Network,
+	},
+	cli.StringFlag{
+		Name:        "public-subnetwork",
+		Usage:       "(optional) Existing subnetwork of --network for the director and web node",
+		EnvVar:      "PUBLIC_SUBNETWORK",
+		Destination: &initialDeployArgs.PublicSubnetwork,
+	},
+	cli.StringFlag{
+		Name:        "private-subnetwork",
+		Usage:       "(optional) Existing subnetwork of --network for the workers",
+		EnvVar:      "PRIVATE_SUBNETWORK",
+		Destination: &initialDeployArgs.PrivateSubnetwork,
+	},
 }
 
 func deployAction(c *cli.Context, deployArgs deploy.Args, provider iaas.Provider) error {
@@ -189,7 +253,7 @@ func deployAction(c *cli.Context, deployArgs deploy.Args, provider iaas.Provider
 
 	version := c.App.Version
 
-	deployArgs, err := validateDeployArgs(c, deployArgs)
+	err := deployArgs.Validate()
 	if err != nil {
 		return err
 	}
@@ -204,28 +268,63 @@ func deployAction(c *cli.Context, deployArgs deploy.Args, provider iaas.Provider
 		return err
 	}
 
+	err = validatePrivate(deployArgs.Private, provider.IAAS())
+	if err != nil {
+		return err
+	}
+
+	err = validateSingleZone(deployArgs, provider.IAAS())
+	if err != nil {
+		return err
+	}
+
+	err = validateExistingNetwork(deployArgs, provider.IAAS())
+	if err != nil {
+		return err
+	}
+
 	err = validateCidrRanges(provider, deployArgs.NetworkCIDR, deployArgs.PublicCIDR, deployArgs.PrivateCIDR, deployArgs.RDS1CIDR, deployArgs.RDS2CIDR)
 	if err != nil {
 		return err
 	}
 
-	client, err := buildClient(name, version, deployArgs, provider)
+	configClient, err := newConfigClient(provider, name, deployArgs.Namespace)
+	if err != nil {
+		return err
+	}
+	// Config buckets created before `config history` existed are not versioned
+	if err = configClient.EnableVersioning(); err != nil {
+		return err
+	}
+	client, err := buildClient(name, version, deployArgs, provider, configClient, os.Stdout)
 	if err != nil {
 		return err
 	}
 
-	return client.Deploy()
+	return withDeploymentLock(configClient, name, "deploy", func() error {
+		if err := migrateStoredConfig(configClient); err != nil {
+			return err
+		}
+		return withAuditEntry(c, provider, configClient, "deploy", client.Deploy)
+	})
 }
 
-func validateDeployArgs(c *cli.Context, deployArgs deploy.Args) (deploy.Args, error) {
+// mergeDeploySpec marks the flags which were set and merges in the deployment file, if one was given
+func mergeDeploySpec(c *cli.Context, deployArgs deploy.Args) (deploy.Args, error) {
 	err := deployArgs.MarkSetFlags(c)
 	if err != nil {
 		return deployArgs, err
 	}
 
-	if err = deployArgs.Validate(); err != nil {
+	if deployArgs.SpecFile == "" {
+		return deployArgs, nil
+	}
+
+	spec, err := deploy.LoadSpec(deployArgs.SpecFile)
+	if err != nil {
 		return deployArgs, err
 	}
+	deployArgs.MergeSpec(spec)
 
 	return deployArgs, nil
 }
@@ -235,6 +334,18 @@ func setZoneAndRegion(providerRegion string, deployArgs deploy.Args) (deploy.Arg
 		deployArgs.Region = providerRegion
 	}
 
+	if deployArgs.ZonesIsSet {
+		deployArgs.Zone = deployArgs.ZoneList()[0]
+		deployArgs.ZoneIsSet = true
+	}
+
+	// Zones which are not named after their region cannot be matched to it
+	if iaasName, err := iaas.Assosiate(deployArgs.IAAS); err == nil {
+		if r, err := iaas.Lookup(iaasName); err == nil && !r.RegionalZones {
+			return deployArgs, nil
+		}
+	}
+
 	if deployArgs.ZoneIsSet && deployArgs.RegionIsSet {
 		if err := zoneBelongsToRegion(deployArgs.Zone, deployArgs.Region); err != nil {
 			return deployArgs, err
@@ -249,6 +360,12 @@ func setZoneAndRegion(providerRegion string, deployArgs deploy.Args) (deploy.Arg
 		}
 	}
 
+	for _, zone := range deployArgs.ZoneList() {
+		if err := zoneBelongsToRegion(zone, deployArgs.Region); err != nil {
+			return deployArgs, err
+		}
+	}
+
 	return deployArgs, nil
 }
 
@@ -269,12 +386,42 @@ func zoneBelongsToRegion(zone, region string) error {
 }
 
 func validateNameLength(name string, providerName iaas.Name) error {
-	if providerName == iaas.GCP {
-		if len(name) > maxAllowedNameLength {
-			return fmt.Errorf("deployment name %s is too long. %d character limit", name, maxAllowedNameLength)
-		}
+	if r, _ := iaas.Lookup(providerName); r.MaxNameLength != 0 && len(name) > r.MaxNameLength {
+		return fmt.Errorf("deployment name %s is too long. %d character limit", name, r.MaxNameLength)
+	}
+	return nil
+}
+
+func validatePrivate(private bool, providerName iaas.Name) error {
+	if r, _ := iaas.Lookup(providerName); private && !r.Private {
+		return fmt.Errorf("--private is not supported on %s", providerName)
 	}
+	return nil
+}
 
+// validateSingleZone rejects the flags which need more than one zone on the IAASs which deploy into a single zone
+func validateSingleZone(deployArgs deploy.Args, providerName iaas.Name) error {
+	if r, _ := iaas.Lookup(providerName); !r.SingleZone {
+		return nil
+	}
+	if deployArgs.DBHighAvailability {
+		return fmt.Errorf("--db-high-availability is not supported on %s", providerName)
+	}
+	if len(deployArgs.ZoneList()) > 1 {
+		return fmt.Errorf("--zones with more than one zone is not supported on %s", providerName)
+	}
+	return nil
+}
+
+// validateExistingNetwork rejects the flags which name an existing network of another IAAS
+func validateExistingNetwork(deployArgs deploy.Args, providerName iaas.Name) error {
+	r, _ := iaas.Lookup(providerName)
+	if deployArgs.VPCID != "" && r.NetworkFlag != "--vpc-id" {
+		return fmt.Errorf("--vpc-id is not supported on %s", providerName)
+	}
+	if deployArgs.Network != "" && r.NetworkFlag != "--network" {
+		return fmt.Errorf("--network is not supported on %s", providerName)
+	}
 	return nil
 }
 
@@ -286,9 +433,12 @@ func validateCidrRanges(provider iaas.Provider, networkCIDR, publicCIDR, private
 		return nil
 	}
 
-	if provider.IAAS() == iaas.AWS {
+	// The subnets are ranges of the network on IAASs which have a default network range
+	r, _ := iaas.Lookup(provider.IAAS())
+	hasNetwork := r.Ranges.Network != ""
+	if hasNetwork {
 		if (privateCIDR != "" || publicCIDR != "" || RDS1CIDR != "" || RDS2CIDR != "") && networkCIDR == "" {
-			return errors.New("error validating CIDR ranges - vpc-network-range must be provided when using AWS")
+			return fmt.Errorf("error validating CIDR ranges - vpc-network-range must be provided when using %s", provider.IAAS())
 		}
 		_, parsedNetworkCidr, err = net.ParseCIDR(networkCIDR)
 		if err != nil {
@@ -336,7 +486,7 @@ func validateCidrRanges(provider iaas.Provider, networkCIDR, publicCIDR, private
 		return errors.New("error validating CIDR ranges - private-subnet-range is not big enough, at least /28 needed.")
 	}
 
-	if provider.IAAS() == iaas.AWS {
+	if hasNetwork {
 		if !parsedNetworkCidr.Contains(parsedPublicCidr.IP) {
 			return errors.New("error validating CIDR ranges - public-subnet-range must be within vpc-network-range")
 		}
@@ -381,8 +531,8 @@ func validateRDSSubnetSize(cidr *net.IPNet) bool {
 	return size > 4
 }
 
-func buildClient(name, version string, deployArgs deploy.Args, provider iaas.Provider) (*concourse.Client, error) {
-	terraformClient, err := terraform.New(provider.IAAS(), terraform.DownloadTerraform())
+func buildClient(name, version string, deployArgs deploy.Args, provider iaas.Provider, configClient config.IClient, stdout io.Writer) (*concourse.Client, error) {
+	terraformClient, err := terraform.New(provider.IAAS(), terraform.DownloadTerraform(), terraformStateBackend(), terraform.LockTables(provider))
 	if err != nil {
 		return nil, err
 	}
@@ -399,9 +549,9 @@ func buildClient(name, version string, deployArgs deploy.Args, provider iaas.Pro
 		bosh.New,
 		fly.New,
 		certs.Generate,
-		config.New(provider, name, deployArgs.Namespace),
+		configClient,
 		&deployArgs,
-		os.Stdout,
+		stdout,
 		os.Stderr,
 		util.FindUserIP,
 		certs.NewAcmeClient,
@@ -414,21 +564,33 @@ func buildClient(name, version string, deployArgs deploy.Args, provider iaas.Pro
 	return client, nil
 }
 
+var deployCmdFlags = append([]cli.Flag{
+	cli.BoolFlag{
+		Name:        "resume",
+		Usage:       "(optional) Resume the last deploy from the phase where it failed",
+		Destination: &initialDeployArgs.Resume,
+	},
+}, deployFlags...)
+
 var deployCmd = cli.Command{
 	Name:      "deploy",
 	Aliases:   []string{"d"},
 	Usage:     "Deploys or updates a Concourse",
 	ArgsUsage: "<name>",
-	Flags:     deployFlags,
+	Flags:     deployCmdFlags,
 	Action: func(c *cli.Context) error {
-		iaasName, err := iaas.Assosiate(initialDeployArgs.IAAS)
+		deployArgs, err := mergeDeploySpec(c, initialDeployArgs)
+		if err != nil {
+			return err
+		}
+		iaasName, err := iaas.Assosiate(deployArgs.IAAS)
 		if err != nil {
 			return err
 		}
-		provider, err := iaas.New(iaasName, initialDeployArgs.Region)
+		provider, err := iaas.New(iaasName, deployArgs.Region)
 		if err != nil {
 			return fmt.Errorf("Error creating IAAS provider on deploy: [%v]", err)
 		}
-		return deployAction(c, initialDeployArgs, provider)
+		return deployAction(c, deployArgs, provider)
 	},
 }
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strings"
 
 	"gopkg.in/urfave/cli.v1"
 )
@@ -31,6 +32,8 @@ type Args struct {
 	DBSize           string
 	// DBSizeIsSet is true if the user has manually specified the db-size (ie, it's not the default)
 	DBSizeIsSet                 bool
+	DBHighAvailability          bool
+	DBHighAvailabilityIsSet     bool
 	Namespace                   string
 	NamespaceIsSet              bool
 	AllowIPs                    string
@@ -49,6 +52,8 @@ type Args struct {
 	Preemptible      bool
 	Zone             string
 	ZoneIsSet        bool
+	Zones            string
+	ZonesIsSet       bool
 	WorkerType       string
 	WorkerTypeIsSet  bool
 	NetworkCIDR      string
@@ -61,6 +66,28 @@ type Args struct {
 	RDS1CIDRIsSet    bool
 	RDS2CIDR         string
 	RDS2CIDRIsSet    bool
+	Private          bool
+	PrivateIsSet     bool
+	// VPCID, PublicSubnetID, PrivateSubnetID and RDSSubnetIDs name an existing AWS VPC and subnets to deploy into
+	VPCID                string
+	VPCIDIsSet           bool
+	PublicSubnetID       string
+	PublicSubnetIDIsSet  bool
+	PrivateSubnetID      string
+	PrivateSubnetIDIsSet bool
+	RDSSubnetIDs         string
+	RDSSubnetIDsIsSet    bool
+	// Network, PublicSubnetwork and PrivateSubnetwork name an existing GCP network and subnetworks to deploy into
+	Network                string
+	NetworkIsSet           bool
+	PublicSubnetwork       string
+	PublicSubnetworkIsSet  bool
+	PrivateSubnetwork      string
+	PrivateSubnetworkIsSet bool
+	// SpecFile is the path to a deployment file passed with --file
+	SpecFile string
+	// Resume is true when the deploy should continue from the phase where the last one failed
+	Resume bool
 }
 
 // MarkSetFlags is marking the IsSet DeployArgs
@@ -102,6 +129,10 @@ func (a *Args) MarkSetFlags(c FlagSetChecker) error {
 				a.NamespaceIsSet = true
 			case "zone":
 				a.ZoneIsSet = true
+			case "zones":
+				a.ZonesIsSet = true
+			case "db-high-availability":
+				a.DBHighAvailabilityIsSet = true
 			case "worker-type":
 				a.WorkerTypeIsSet = true
 			case "vpc-network-range":
@@ -114,6 +145,28 @@ func (a *Args) MarkSetFlags(c FlagSetChecker) error {
 				a.RDS1CIDRIsSet = true
 			case "rds-subnet-range2":
 				a.RDS2CIDRIsSet = true
+			case "private":
+				a.PrivateIsSet = true
+			case "vpc-id":
+				a.VPCIDIsSet = true
+			case "public-subnet-id":
+				a.PublicSubnetIDIsSet = true
+			case "private-subnet-id":
+				a.PrivateSubnetIDIsSet = true
+			case "rds-subnet-ids":
+				a.RDSSubnetIDsIsSet = true
+			case "network":
+				a.NetworkIsSet = true
+			case "public-subnetwork":
+				a.PublicSubnetworkIsSet = true
+			case "private-subnetwork":
+				a.PrivateSubnetworkIsSet = true
+			case "file":
+				// Fields from the deployment file are marked as set by MergeSpec
+			case "json":
+				// Only used by plan to choose its output format
+			case "resume":
+				// Only used by deploy to pick up from a failed phase
 			default:
 				return fmt.Errorf("flag %q is not supported by deployment flags", f)
 			}
@@ -166,10 +219,80 @@ func (a Args) Validate() error {
 		return err
 	}
 
+	if err := a.validateZones(); err != nil {
+		return err
+	}
+
+	if err := a.validateExistingNetwork(); err != nil {
+		return err
+	}
+
 	if err := a.validateTags(); err != nil {
 		return err
 	}
 
+	if err := a.validateResume(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateResume rejects settings given with --resume, which would otherwise be ignored
+// because a resumed deploy uses the config stored by the one that failed
+func (a Args) validateResume() error {
+	if !a.Resume {
+		return nil
+	}
+	if a.SelfUpdate {
+		return errors.New("--resume cannot be used with --self-update")
+	}
+
+	set := []struct {
+		isSet bool
+		flag  string
+	}{
+		{a.SpecFile != "", "--file"},
+		{a.DomainIsSet, "--domain"},
+		{a.TLSCertIsSet, "--tls-cert"},
+		{a.TLSKeyIsSet, "--tls-key"},
+		{a.WorkerCountIsSet, "--workers"},
+		{a.WorkerSizeIsSet, "--worker-size"},
+		{a.WebSizeIsSet, "--web-size"},
+		{a.SelfUpdateIsSet, "--self-update"},
+		{a.DBSizeIsSet, "--db-size"},
+		{a.DBHighAvailabilityIsSet, "--db-high-availability"},
+		{a.AllowIPsIsSet, "--allow-ips"},
+		{a.GithubAuthClientIDIsSet, "--github-auth-client-id"},
+		{a.GithubAuthClientSecretIsSet, "--github-auth-client-secret"},
+		{a.TagsIsSet, "--add-tag"},
+		{a.SpotIsSet, "--spot"},
+		{a.ZoneIsSet, "--zone"},
+		{a.ZonesIsSet, "--zones"},
+		{a.WorkerTypeIsSet, "--worker-type"},
+		{a.NetworkCIDRIsSet, "--vpc-network-range"},
+		{a.PublicCIDRIsSet, "--public-subnet-range"},
+		{a.PrivateCIDRIsSet, "--private-subnet-range"},
+		{a.RDS1CIDRIsSet, "--rds-subnet-range1"},
+		{a.RDS2CIDRIsSet, "--rds-subnet-range2"},
+		{a.PrivateIsSet, "--private"},
+		{a.VPCIDIsSet, "--vpc-id"},
+		{a.PublicSubnetIDIsSet, "--public-subnet-id"},
+		{a.PrivateSubnetIDIsSet, "--private-subnet-id"},
+		{a.RDSSubnetIDsIsSet, "--rds-subnet-ids"},
+		{a.NetworkIsSet, "--network"},
+		{a.PublicSubnetworkIsSet, "--public-subnetwork"},
+		{a.PrivateSubnetworkIsSet, "--private-subnetwork"},
+	}
+	var flags []string
+	for _, s := range set {
+		if s.isSet {
+			flags = append(flags, s.flag)
+		}
+	}
+	if len(flags) > 0 {
+		return fmt.Errorf("--resume cannot be used with %s, a resumed deploy uses the settings of the deploy that failed", strings.Join(flags, ", "))
+	}
 	return nil
 }
 
@@ -239,6 +362,90 @@ func (a Args) validateNetworkRanges() error {
 	return nil
 }
 
+func (a Args) validateZones() error {
+	if !a.ZonesIsSet {
+		return nil
+	}
+	zones := a.ZoneList()
+	if len(zones) == 0 {
+		return errors.New("--zones requires at least one zone")
+	}
+	if a.ZoneIsSet && a.Zone != zones[0] {
+		return fmt.Errorf("--zone %s must be the first of --zones", a.Zone)
+	}
+	seen := map[string]bool{}
+	for _, zone := range zones {
+		if seen[zone] {
+			return fmt.Errorf("zone %s is given more than once in --zones", zone)
+		}
+		seen[zone] = true
+	}
+	return nil
+}
+
+// ZoneList returns the zones given with --zones
+func (a Args) ZoneList() []string {
+	return splitList(a.Zones)
+}
+
+// validateExistingNetwork checks that an existing network is given with the subnets to deploy into, and without
+// the ranges and extra zones concourse-up would otherwise create subnets for
+func (a Args) validateExistingNetwork() error {
+	existingVPC := a.VPCIDIsSet || a.PublicSubnetIDIsSet || a.PrivateSubnetIDIsSet || a.RDSSubnetIDsIsSet
+	if existingVPC {
+		if a.VPCID == "" || a.PublicSubnetID == "" || a.PrivateSubnetID == "" || a.RDSSubnetIDs == "" {
+			return errors.New("--vpc-id, --public-subnet-id, --private-subnet-id and --rds-subnet-ids are required when any of them is provided")
+		}
+		if len(a.RDSSubnetIDList()) < 2 {
+			return errors.New("--rds-subnet-ids needs at least two subnets, in different zones")
+		}
+	}
+	existingNetwork := a.NetworkIsSet || a.PublicSubnetworkIsSet || a.PrivateSubnetworkIsSet
+	if existingNetwork {
+		if a.Network == "" || a.PublicSubnetwork == "" || a.PrivateSubnetwork == "" {
+			return errors.New("--network, --public-subnetwork and --private-subnetwork are required when any of them is provided")
+		}
+	}
+	if !existingVPC && !existingNetwork {
+		return nil
+	}
+
+	ranges := []struct {
+		isSet bool
+		flag  string
+	}{
+		{a.NetworkCIDRIsSet, "--vpc-network-range"},
+		{a.PublicCIDRIsSet, "--public-subnet-range"},
+		{a.PrivateCIDRIsSet, "--private-subnet-range"},
+		{a.RDS1CIDRIsSet, "--rds-subnet-range1"},
+		{a.RDS2CIDRIsSet, "--rds-subnet-range2"},
+	}
+	for _, r := range ranges {
+		if r.isSet {
+			return fmt.Errorf("%s cannot be used when deploying into an existing network, the ranges of its subnets are used", r.flag)
+		}
+	}
+	if len(a.ZoneList()) > 1 {
+		return errors.New("--zones with more than one zone cannot be used when deploying into an existing network")
+	}
+	return nil
+}
+
+// RDSSubnetIDList returns the subnets given with --rds-subnet-ids
+func (a Args) RDSSubnetIDList() []string {
+	return splitList(a.RDSSubnetIDs)
+}
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func (a Args) validateTags() error {
 	for _, tag := range a.Tags {
 		m, err := regexp.MatchString(`\w+=\w+`, tag)
@@ -171,6 +171,125 @@ func TestDeployArgs_Validate(t *testing.T) {
 			},
 			wantErr:     true,
 			expectedErr: "both --public-subnet-range and --private-subnet-range are required when either is provided",
+		},
+		{
+			name: "Zone must be the first of zones",
+			modification: func() Args {
+				args := defaultFields
+				args.Zone, args.ZoneIsSet = "eu-west-1b", true
+				args.Zones, args.ZonesIsSet = "eu-west-1a,eu-west-1b", true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--zone eu-west-1b must be the first of --zones",
+		},
+		{
+			name: "Zones cannot be repeated",
+			modification: func() Args {
+				args := defaultFields
+				args.Zones, args.ZonesIsSet = "eu-west-1a, eu-west-1b, eu-west-1a", true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "zone eu-west-1a is given more than once in --zones",
+		},
+		{
+			name: "Resume cannot be combined with self-update",
+			modification: func() Args {
+				args := defaultFields
+				args.Resume = true
+				args.SelfUpdate = true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--resume cannot be used with --self-update",
+		},
+		{
+			name: "Resume cannot be combined with other settings",
+			modification: func() Args {
+				args := defaultFields
+				args.Resume = true
+				args.WorkerCount, args.WorkerCountIsSet = 3, true
+				args.Domain, args.DomainIsSet = "ci.example.com", true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--resume cannot be used with --domain, --workers",
+		},
+		{
+			name: "Resume can be given the deployment to resume",
+			modification: func() Args {
+				args := defaultFields
+				args.Resume = true
+				args.Region, args.RegionIsSet = "us-east-1", true
+				args.Namespace, args.NamespaceIsSet = "team-a", true
+				args.IAASIsSet = true
+				return args
+			},
+			wantErr: false,
+		},
+		{
+			name: "An existing VPC can be given with its subnets",
+			modification: func() Args {
+				args := defaultFields
+				args.VPCID, args.VPCIDIsSet = "vpc-123", true
+				args.PublicSubnetID, args.PublicSubnetIDIsSet = "subnet-public", true
+				args.PrivateSubnetID, args.PrivateSubnetIDIsSet = "subnet-private", true
+				args.RDSSubnetIDs, args.RDSSubnetIDsIsSet = "subnet-rds-a, subnet-rds-b", true
+				return args
+			},
+			wantErr: false,
+		},
+		{
+			name: "An existing VPC needs its subnets",
+			modification: func() Args {
+				args := defaultFields
+				args.VPCID, args.VPCIDIsSet = "vpc-123", true
+				args.PublicSubnetID, args.PublicSubnetIDIsSet = "subnet-public", true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--vpc-id, --public-subnet-id, --private-subnet-id and --rds-subnet-ids are required when any of them is provided",
+		},
+		{
+			name: "An existing VPC needs two RDS subnets",
+			modification: func() Args {
+				args := defaultFields
+				args.VPCID, args.VPCIDIsSet = "vpc-123", true
+				args.PublicSubnetID, args.PublicSubnetIDIsSet = "subnet-public", true
+				args.PrivateSubnetID, args.PrivateSubnetIDIsSet = "subnet-private", true
+				args.RDSSubnetIDs, args.RDSSubnetIDsIsSet = "subnet-rds-a", true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--rds-subnet-ids needs at least two subnets, in different zones",
+		},
+		{
+			name: "An existing network cannot be given ranges",
+			modification: func() Args {
+				args := defaultFields
+				args.Network, args.NetworkIsSet = "shared", true
+				args.PublicSubnetwork, args.PublicSubnetworkIsSet = "shared-public", true
+				args.PrivateSubnetwork, args.PrivateSubnetworkIsSet = "shared-private", true
+				args.PublicCIDR, args.PublicCIDRIsSet = "10.0.0.0/24", true
+				args.PrivateCIDR, args.PrivateCIDRIsSet = "10.0.1.0/24", true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--public-subnet-range cannot be used when deploying into an existing network",
+		},
+		{
+			name: "An existing network cannot spread workers across zones",
+			modification: func() Args {
+				args := defaultFields
+				args.Network, args.NetworkIsSet = "shared", true
+				args.PublicSubnetwork, args.PublicSubnetworkIsSet = "shared-public", true
+				args.PrivateSubnetwork, args.PrivateSubnetworkIsSet = "shared-private", true
+				args.Zones, args.ZonesIsSet = "europe-west1-b,europe-west1-c", true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--zones with more than one zone cannot be used when deploying into an existing network",
 		}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,154 @@
+package deploy
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"gopkg.in/urfave/cli.v1"
+	"gopkg.in/yaml.v2"
+)
+
+// SpecVersion is the version of the deployment file format understood by this release
+const SpecVersion = 1
+
+// Spec is a declarative deployment file, an alternative to passing deploy flags
+// Keys match the names of the deploy flags. JSON files are also accepted
+type Spec struct {
+	Version                int      `yaml:"version"`
+	IAAS                   *string  `yaml:"iaas"`
+	Region                 *string  `yaml:"region"`
+	Domain                 *string  `yaml:"domain"`
+	TLSCert                *string  `yaml:"tls-cert"`
+	TLSKey                 *string  `yaml:"tls-key"`
+	WorkerCount            *int     `yaml:"workers"`
+	WorkerSize             *string  `yaml:"worker-size"`
+	WorkerType             *string  `yaml:"worker-type"`
+	WebSize                *string  `yaml:"web-size"`
+	DBSize                 *string  `yaml:"db-size"`
+	DBHighAvailability     *bool    `yaml:"db-high-availability"`
+	Spot                   *bool    `yaml:"spot"`
+	Preemptible            *bool    `yaml:"preemptible"`
+	AllowIPs               *string  `yaml:"allow-ips"`
+	GithubAuthClientID     *string  `yaml:"github-auth-client-id"`
+	GithubAuthClientSecret *string  `yaml:"github-auth-client-secret"`
+	Tags                   []string `yaml:"tags"`
+	Namespace              *string  `yaml:"namespace"`
+	Zone                   *string  `yaml:"zone"`
+	Zones                  []string `yaml:"zones"`
+	NetworkCIDR            *string  `yaml:"vpc-network-range"`
+	PublicCIDR             *string  `yaml:"public-subnet-range"`
+	PrivateCIDR            *string  `yaml:"private-subnet-range"`
+	RDS1CIDR               *string  `yaml:"rds-subnet-range1"`
+	RDS2CIDR               *string  `yaml:"rds-subnet-range2"`
+	Private                *bool    `yaml:"private"`
+	VPCID                  *string  `yaml:"vpc-id"`
+	PublicSubnetID         *string  `yaml:"public-subnet-id"`
+	PrivateSubnetID        *string  `yaml:"private-subnet-id"`
+	RDSSubnetIDs           []string `yaml:"rds-subnet-ids"`
+	Network                *string  `yaml:"network"`
+	PublicSubnetwork       *string  `yaml:"public-subnetwork"`
+	PrivateSubnetwork      *string  `yaml:"private-subnetwork"`
+}
+
+// LoadSpec reads a deployment file from path
+func LoadSpec(path string) (Spec, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Spec{}, fmt.Errorf("error reading deployment file: [%v]", err)
+	}
+	return ParseSpec(contents)
+}
+
+// ParseSpec parses a YAML or JSON deployment file, rejecting unknown keys
+func ParseSpec(contents []byte) (Spec, error) {
+	var s Spec
+	if err := yaml.UnmarshalStrict(contents, &s); err != nil {
+		return Spec{}, fmt.Errorf("error parsing deployment file: [%v]", err)
+	}
+	if s.Version != SpecVersion {
+		return Spec{}, fmt.Errorf("unsupported deployment file version %d, expected `version: %d`", s.Version, SpecVersion)
+	}
+	return s, nil
+}
+
+// MergeSpec copies every field present in the deployment file into the args and marks it as set
+// Flags provided on the command line take precedence over the file
+func (a *Args) MergeSpec(s Spec) {
+	mergeString(&a.IAAS, &a.IAASIsSet, s.IAAS)
+	mergeString(&a.Region, &a.RegionIsSet, s.Region)
+	mergeString(&a.Domain, &a.DomainIsSet, s.Domain)
+	mergeString(&a.TLSCert, &a.TLSCertIsSet, s.TLSCert)
+	mergeString(&a.TLSKey, &a.TLSKeyIsSet, s.TLSKey)
+	mergeString(&a.WorkerSize, &a.WorkerSizeIsSet, s.WorkerSize)
+	mergeString(&a.WorkerType, &a.WorkerTypeIsSet, s.WorkerType)
+	mergeString(&a.WebSize, &a.WebSizeIsSet, s.WebSize)
+	mergeString(&a.DBSize, &a.DBSizeIsSet, s.DBSize)
+	mergeString(&a.AllowIPs, &a.AllowIPsIsSet, s.AllowIPs)
+	mergeString(&a.GithubAuthClientID, &a.GithubAuthClientIDIsSet, s.GithubAuthClientID)
+	mergeString(&a.GithubAuthClientSecret, &a.GithubAuthClientSecretIsSet, s.GithubAuthClientSecret)
+	mergeString(&a.Namespace, &a.NamespaceIsSet, s.Namespace)
+	mergeString(&a.Zone, &a.ZoneIsSet, s.Zone)
+	mergeString(&a.NetworkCIDR, &a.NetworkCIDRIsSet, s.NetworkCIDR)
+	mergeString(&a.PublicCIDR, &a.PublicCIDRIsSet, s.PublicCIDR)
+	mergeString(&a.PrivateCIDR, &a.PrivateCIDRIsSet, s.PrivateCIDR)
+	mergeString(&a.RDS1CIDR, &a.RDS1CIDRIsSet, s.RDS1CIDR)
+	mergeString(&a.RDS2CIDR, &a.RDS2CIDRIsSet, s.RDS2CIDR)
+	mergeString(&a.VPCID, &a.VPCIDIsSet, s.VPCID)
+	mergeString(&a.PublicSubnetID, &a.PublicSubnetIDIsSet, s.PublicSubnetID)
+	mergeString(&a.PrivateSubnetID, &a.PrivateSubnetIDIsSet, s.PrivateSubnetID)
+	mergeString(&a.Network, &a.NetworkIsSet, s.Network)
+	mergeString(&a.PublicSubnetwork, &a.PublicSubnetworkIsSet, s.PublicSubnetwork)
+	mergeString(&a.PrivateSubnetwork, &a.PrivateSubnetworkIsSet, s.PrivateSubnetwork)
+
+	if s.WorkerCount != nil && !a.WorkerCountIsSet {
+		a.WorkerCount = *s.WorkerCount
+		a.WorkerCountIsSet = true
+	}
+
+	if s.DBHighAvailability != nil && !a.DBHighAvailabilityIsSet {
+		a.DBHighAvailability = *s.DBHighAvailability
+		a.DBHighAvailabilityIsSet = true
+	}
+
+	if s.Private != nil && !a.PrivateIsSet {
+		a.Private = *s.Private
+		a.PrivateIsSet = true
+	}
+
+	if s.Zones != nil && !a.ZonesIsSet {
+		a.Zones = strings.Join(s.Zones, ",")
+		a.ZonesIsSet = true
+	}
+
+	if s.RDSSubnetIDs != nil && !a.RDSSubnetIDsIsSet {
+		a.RDSSubnetIDs = strings.Join(s.RDSSubnetIDs, ",")
+		a.RDSSubnetIDsIsSet = true
+	}
+
+	if s.Tags != nil && !a.TagsIsSet {
+		a.Tags = cli.StringSlice(s.Tags)
+		a.TagsIsSet = true
+	}
+
+	// spot and preemptible share SpotIsSet, so the file only applies when neither flag was passed
+	if (s.Spot != nil || s.Preemptible != nil) && !a.SpotIsSet {
+		if s.Spot != nil {
+			a.Spot = *s.Spot
+		}
+		if s.Preemptible != nil {
+			a.Preemptible = *s.Preemptible
+		}
+		a.SpotIsSet = true
+	}
+
+	a.GithubAuthIsSet = a.GithubAuthClientIDIsSet && a.GithubAuthClientSecretIsSet
+}
+
+func mergeString(field *string, isSet *bool, value *string) {
+	if value == nil || *isSet {
+		return
+	}
+	*field = *value
+	*isSet = true
+}
@@ -0,0 +1,148 @@
+package deploy_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/EngineerBetter/concourse-up/commands/deploy"
+	"gopkg.in/urfave/cli.v1"
+)
+
+func TestParseSpec(t *testing.T) {
+	tests := []struct {
+		name        string
+		contents    string
+		wantErr     bool
+		expectedErr string
+	}{
+		{
+			name: "YAML file",
+			contents: `version: 1
+workers: 3
+db-size: large
+tags:
+- team=ops
+`,
+		},
+		{
+			name:     "JSON file",
+			contents: `{"version": 1, "workers": 3, "db-size": "large", "tags": ["team=ops"]}`,
+		},
+		{
+			name:        "Missing version",
+			contents:    `workers: 3`,
+			wantErr:     true,
+			expectedErr: "unsupported deployment file version 0",
+		},
+		{
+			name: "Unknown field",
+			contents: `version: 1
+worker-count: 3
+`,
+			wantErr:     true,
+			expectedErr: "field worker-count not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseSpec([]byte(tt.contents))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Errorf("ParseSpec() error = %v, expected to contain %v", err, tt.expectedErr)
+				}
+				return
+			}
+			if *s.WorkerCount != 3 || *s.DBSize != "large" || !reflect.DeepEqual(s.Tags, []string{"team=ops"}) {
+				t.Errorf("ParseSpec() = %+v", s)
+			}
+			if s.WebSize != nil {
+				t.Errorf("ParseSpec() set WebSize, which is not in the file")
+			}
+		})
+	}
+}
+
+func TestArgs_MergeSpec(t *testing.T) {
+	workers := 3
+	dbSize := "large"
+	webSize := "medium"
+	spot := false
+	clientID := "id"
+	clientSecret := "secret"
+	spec := Spec{
+		Version:                1,
+		WorkerCount:            &workers,
+		DBSize:                 &dbSize,
+		WebSize:                &webSize,
+		Spot:                   &spot,
+		GithubAuthClientID:     &clientID,
+		GithubAuthClientSecret: &clientSecret,
+		Tags:                   []string{"team=ops"},
+	}
+
+	defaultArgs := Args{
+		DBSize:      "small",
+		Spot:        true,
+		Preemptible: true,
+		WebSize:     "small",
+		WorkerCount: 1,
+		WorkerSize:  "xlarge",
+	}
+
+	tests := []struct {
+		name         string
+		modification func() Args
+		outcomeCheck func(Args) bool
+	}{
+		{
+			name: "Fields in the file are used and marked as set",
+			modification: func() Args {
+				return defaultArgs
+			},
+			outcomeCheck: func(a Args) bool {
+				return a.WorkerCount == 3 && a.WorkerCountIsSet &&
+					a.DBSize == "large" && a.DBSizeIsSet &&
+					!a.Spot && a.Preemptible && a.SpotIsSet &&
+					a.GithubAuthIsSet &&
+					reflect.DeepEqual(a.Tags, cli.StringSlice{"team=ops"}) && a.TagsIsSet
+			},
+		},
+		{
+			name: "Fields not in the file are left alone",
+			modification: func() Args {
+				return defaultArgs
+			},
+			outcomeCheck: func(a Args) bool {
+				return a.WorkerSize == "xlarge" && !a.WorkerSizeIsSet && !a.DomainIsSet
+			},
+		},
+		{
+			name: "Flags take precedence over the file",
+			modification: func() Args {
+				args := defaultArgs
+				args.WebSize = "large"
+				args.WebSizeIsSet = true
+				return args
+			},
+			outcomeCheck: func(a Args) bool {
+				return a.WebSize == "large" && a.WebSizeIsSet
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.modification()
+			args.MergeSpec(spec)
+			if !tt.outcomeCheck(args) {
+				t.Errorf("Args.MergeSpec() produced unexpected args %+v", args)
+			}
+			if err := args.Validate(); err != nil {
+				t.Errorf("Args.Validate() error = %v", err)
+			}
+		})
+	}
+}
@@ -105,6 +105,28 @@ func Test_setZoneAndRegion(t *testing.T) {
 			providerRegion: "europe-west1",
 			expectedRegion: "europe-west1",
 		},
+		{
+			name: "zones should not be matched to the region when iaas is AZURE",
+			args: deploy.Args{
+				IAAS:        "AZURE",
+				Region:      "westeurope",
+				RegionIsSet: true,
+				Zones:       "1",
+				ZonesIsSet:  true,
+			},
+			providerRegion: "westeurope",
+			expectedRegion: "westeurope",
+		},
+		{
+			name: "zones should not be matched to the region when iaas is OPENSTACK",
+			args: deploy.Args{
+				IAAS:      "OPENSTACK",
+				Zone:      "nova",
+				ZoneIsSet: true,
+			},
+			providerRegion: "RegionOne",
+			expectedRegion: "RegionOne",
+		},
 		{
 			name: "region should change if user provided it",
 			args: deploy.Args{
@@ -115,6 +137,27 @@ func Test_setZoneAndRegion(t *testing.T) {
 			providerRegion: "eu-west-1",
 			expectedRegion: "us-east-1",
 		},
+		{
+			name: "region should be taken from the first of the zones",
+			args: deploy.Args{
+				IAAS:       "AWS",
+				Zones:      "us-east-1a,us-east-1b",
+				ZonesIsSet: true,
+			},
+			providerRegion: "eu-west-1",
+			expectedRegion: "us-east-1",
+		},
+		{
+			name: "zones should all be in the region",
+			args: deploy.Args{
+				IAAS:       "AWS",
+				Zones:      "eu-west-1a,us-east-1b",
+				ZonesIsSet: true,
+			},
+			providerRegion: "eu-west-1",
+			wantErr:        true,
+			expectedRegion: "eu-west-1",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -199,6 +242,88 @@ func Test_validateNameLength(t *testing.T) {
 	}
 }
 
+func Test_validatePrivate(t *testing.T) {
+	tests := []struct {
+		name         string
+		private      bool
+		providerName iaas.Name
+		wantErr      bool
+	}{
+		{
+			name:         "It is private on AWS",
+			private:      true,
+			providerName: iaas.AWS,
+			wantErr:      false,
+		},
+		{
+			name:         "It is private on GCP",
+			private:      true,
+			providerName: iaas.GCP,
+			wantErr:      true,
+		},
+		{
+			name:         "It is public on GCP",
+			private:      false,
+			providerName: iaas.GCP,
+			wantErr:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validatePrivate(tt.private, tt.providerName); (err != nil) != tt.wantErr {
+				t.Errorf("validatePrivate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_validateSingleZone(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         deploy.Args
+		providerName iaas.Name
+		wantErr      bool
+	}{
+		{
+			name:         "It has one zone on Azure",
+			args:         deploy.Args{Zones: "1"},
+			providerName: iaas.Azure,
+			wantErr:      false,
+		},
+		{
+			name:         "It has many zones on Azure",
+			args:         deploy.Args{Zones: "1,2"},
+			providerName: iaas.Azure,
+			wantErr:      true,
+		},
+		{
+			name:         "It has a highly available database on Azure",
+			args:         deploy.Args{DBHighAvailability: true},
+			providerName: iaas.Azure,
+			wantErr:      true,
+		},
+		{
+			name:         "It has many zones on OpenStack",
+			args:         deploy.Args{Zones: "nova,nova-2"},
+			providerName: iaas.OpenStack,
+			wantErr:      true,
+		},
+		{
+			name:         "It has a highly available database on GCP",
+			args:         deploy.Args{DBHighAvailability: true},
+			providerName: iaas.GCP,
+			wantErr:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateSingleZone(tt.args, tt.providerName); (err != nil) != tt.wantErr {
+				t.Errorf("validateSingleZone() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func Test_validateCidrRanges(t *testing.T) {
 	testsupport.SetupFakeCredsForGCPProvider(t)
 	gcpProvider, err := iaas.New(iaas.GCP, "europe-west1")
@@ -30,7 +30,7 @@ var destroyFlags = []cli.Flag{
 	},
 	cli.StringFlag{
 		Name:        "iaas",
-		Usage:       "(optional) IAAS, can be AWS or GCP",
+		Usage:       "(optional) IAAS, can be AWS, GCP, AZURE or OPENSTACK",
 		EnvVar:      "IAAS",
 		Value:       "AWS",
 		Destination: &initialDestroyArgs.IAAS,
@@ -67,11 +67,38 @@ func destroyAction(c *cli.Context, destroyArgs destroy.Args, provider iaas.Provi
 	if err != nil {
 		return err
 	}
-	client, err := buildDestroyClient(name, version, destroyArgs, provider)
+	configClient, err := newConfigClient(provider, name, destroyArgs.Namespace)
 	if err != nil {
 		return err
 	}
-	return client.Destroy()
+	client, err := buildDestroyClient(name, version, provider, configClient)
+	if err != nil {
+		return err
+	}
+
+	lock, err := acquireDeploymentLock(configClient, name, "destroy")
+	if err != nil {
+		return err
+	}
+	audit := startAudit(c, provider, configClient, "destroy")
+	// The lock and audit log go with the config bucket when destroy succeeds, so keep the log to write out afterwards
+	entries, err := config.LoadAuditLog(configClient)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: the audit log could not be read, only the destroy will be kept: %v\n", err)
+	}
+	stopRefreshing := refreshDeploymentLock(configClient, lock, config.LockRefreshInterval)
+	err = client.Destroy()
+	stopRefreshing()
+	if err != nil {
+		err = audit.finish(err)
+		config.ReleaseLock(configClient, lock)
+		return err
+	}
+	path, err := deletedAuditLogPath(name, destroyArgs.Namespace)
+	if err != nil {
+		return fmt.Errorf("destroy succeeded but the audit log could not be kept: [%v]", err)
+	}
+	return audit.finishDeleted(entries, path, os.Stdout)
 }
 func markSetFlags(c *cli.Context, destroyArgs destroy.Args) (destroy.Args, error) {
 	err := destroyArgs.MarkSetFlags(c)
@@ -81,8 +108,8 @@ func markSetFlags(c *cli.Context, destroyArgs destroy.Args) (destroy.Args, error
 	return destroyArgs, nil
 }
 
-func buildDestroyClient(name, version string, destroyArgs destroy.Args, provider iaas.Provider) (*concourse.Client, error) {
-	terraformClient, err := terraform.New(provider.IAAS(), terraform.DownloadTerraform())
+func buildDestroyClient(name, version string, provider iaas.Provider, configClient config.IClient) (*concourse.Client, error) {
+	terraformClient, err := terraform.New(provider.IAAS(), terraform.DownloadTerraform(), terraformStateBackend())
 	if err != nil {
 		return nil, err
 	}
@@ -99,7 +126,7 @@ func buildDestroyClient(name, version string, destroyArgs destroy.Args, provider
 		bosh.New,
 		fly.New,
 		certs.Generate,
-		config.New(provider, name, destroyArgs.Namespace),
+		configClient,
 		nil,
 		os.Stdout,
 		os.Stderr,
@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/concourse"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var initialExportArgs struct {
+	Region       string
+	IAAS         string
+	Namespace    string
+	Output       string
+	WithoutState bool
+}
+
+var exportFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "region",
+		Usage:       "(optional) AWS region",
+		EnvVar:      "AWS_REGION",
+		Destination: &initialExportArgs.Region,
+	},
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(optional) IAAS, can be AWS, GCP, AZURE or OPENSTACK",
+		EnvVar:      "IAAS",
+		Value:       "AWS",
+		Destination: &initialExportArgs.IAAS,
+	},
+	cli.StringFlag{
+		Name:        "namespace",
+		Usage:       "(optional) Specify a namespace for deployments in order to group them in a meaningful way",
+		EnvVar:      "NAMESPACE",
+		Destination: &initialExportArgs.Namespace,
+	},
+	cli.StringFlag{
+		Name:        "output, o",
+		Usage:       "(optional) File to write the archive to, defaults to <name>.concourse-up",
+		Destination: &initialExportArgs.Output,
+	},
+}
+
+var importFlags = append(append([]cli.Flag{}, exportFlags[:3]...),
+	cli.BoolFlag{
+		Name:        "without-state",
+		Usage:       "(optional) Leave out the director and terraform state, so that the next deploy builds new infrastructure",
+		Destination: &initialExportArgs.WithoutState,
+	},
+)
+
+func exportAction(c *cli.Context, output, namespace string, provider iaas.Provider) error {
+	name := c.Args().Get(0)
+	if name == "" {
+		return errors.New("Usage is `concourse-up export <name>`")
+	}
+	if output == "" {
+		output = name + ".concourse-up"
+	}
+
+	configClient, err := newConfigClient(provider, name, namespace)
+	if err != nil {
+		return err
+	}
+	archive, err := configClient.Export(concourse.Assets)
+	if err != nil {
+		return err
+	}
+	contents, err := config.SealArchive(archive)
+	if err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(output, contents, 0600); err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported %s to %s\n", name, output)
+	return nil
+}
+
+func importAction(c *cli.Context, withoutState bool, namespace string, provider iaas.Provider) error {
+	name := c.Args().Get(0)
+	path := c.Args().Get(1)
+	if name == "" || path == "" {
+		return errors.New("Usage is `concourse-up import <name> <archive>`")
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	archive, err := config.OpenArchive(contents)
+	if err != nil {
+		return fmt.Errorf("error opening %s: [%v]", path, err)
+	}
+	if withoutState {
+		delete(archive.Files, bosh.StateFilename)
+		archive.TerraformState = nil
+	}
+
+	configClient, err := newConfigClient(provider, name, namespace)
+	if err != nil {
+		return err
+	}
+
+	return withDeploymentLock(configClient, name, "import", func() error {
+		return withAuditEntry(c, provider, configClient, "import", func() error {
+			conf, err := configClient.Import(archive, withoutState)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Imported %s into config bucket %s\n", name, conf.ConfigBucket)
+			return nil
+		})
+	})
+}
+
+var exportCmd = cli.Command{
+	Name:      "export",
+	Aliases:   []string{"e"},
+	Usage:     "Exports a deployment's config and state to an encrypted archive",
+	ArgsUsage: "<name>",
+	Flags:     exportFlags,
+	Action: func(c *cli.Context) error {
+		iaasName, err := iaas.Assosiate(initialExportArgs.IAAS)
+		if err != nil {
+			return err
+		}
+		provider, err := iaas.New(iaasName, initialExportArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on export: [%v]", err)
+		}
+		return exportAction(c, initialExportArgs.Output, initialExportArgs.Namespace, provider)
+	},
+}
+
+var importCmd = cli.Command{
+	Name:      "import",
+	Aliases:   []string{"im"},
+	Usage:     "Imports an archive written by export into a new config bucket",
+	ArgsUsage: "<name> <archive>",
+	Flags:     importFlags,
+	Action: func(c *cli.Context) error {
+		iaasName, err := iaas.Assosiate(initialExportArgs.IAAS)
+		if err != nil {
+			return err
+		}
+		provider, err := iaas.New(iaasName, initialExportArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on import: [%v]", err)
+		}
+		return importAction(c, initialExportArgs.WithoutState, initialExportArgs.Namespace, provider)
+	},
+}
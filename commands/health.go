@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/certs"
+	"github.com/EngineerBetter/concourse-up/commands/info"
+	"github.com/EngineerBetter/concourse-up/concourse"
+	"github.com/EngineerBetter/concourse-up/fly"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/terraform"
+	"github.com/EngineerBetter/concourse-up/util"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// healthUnknownExitCode is returned when the health of a deployment could not be determined at all
+const healthUnknownExitCode = 3
+
+var initialHealthArgs info.Args
+
+var healthFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "region",
+		Usage:       "(optional) AWS region",
+		EnvVar:      "AWS_REGION",
+		Destination: &initialHealthArgs.Region,
+	},
+	cli.BoolFlag{
+		Name:        "json",
+		Usage:       "(optional) Output as json",
+		EnvVar:      "JSON",
+		Destination: &initialHealthArgs.JSON,
+	},
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(optional) IAAS, can be AWS, GCP, AZURE or OPENSTACK",
+		EnvVar:      "IAAS",
+		Value:       "AWS",
+		Destination: &initialHealthArgs.IAAS,
+	},
+	cli.StringFlag{
+		Name:        "namespace",
+		Usage:       "(optional) Specify a namespace for deployments in order to group them in a meaningful way",
+		EnvVar:      "NAMESPACE",
+		Destination: &initialHealthArgs.Namespace,
+	},
+}
+
+func healthAction(c *cli.Context, healthArgs info.Args, provider iaas.Provider) error {
+	name := c.Args().Get(0)
+	if name == "" {
+		return errors.New("Usage is `concourse-up health <name>`")
+	}
+
+	err := healthArgs.MarkSetFlags(c)
+	if err != nil {
+		return err
+	}
+
+	client, err := buildHealthClient(name, c.App.Version, healthArgs, provider)
+	if err != nil {
+		return err
+	}
+	health, err := client.Health()
+	if err != nil {
+		return cli.NewExitError(err.Error(), healthUnknownExitCode)
+	}
+
+	if healthArgs.JSON {
+		err = json.NewEncoder(os.Stdout).Encode(health)
+	} else {
+		_, err = fmt.Fprint(os.Stdout, health)
+	}
+	if err != nil {
+		return err
+	}
+
+	if code := health.ExitCode(); code != 0 {
+		return cli.NewExitError("", code)
+	}
+	return nil
+}
+
+// buildHealthClient sends tool output to stderr so that stdout only carries the report
+func buildHealthClient(name, version string, healthArgs info.Args, provider iaas.Provider) (*concourse.Client, error) {
+	terraformClient, err := terraform.New(provider.IAAS(), terraform.DownloadTerraform(), terraformStateBackend())
+	if err != nil {
+		return nil, err
+	}
+
+	tfInputVarsFactory, err := concourse.NewTFInputVarsFactory(provider)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating TFInputVarsFactory [%v]", err)
+	}
+
+	configClient, err := newConfigClient(provider, name, healthArgs.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	client := concourse.NewClient(
+		provider,
+		terraformClient,
+		tfInputVarsFactory,
+		bosh.New,
+		fly.New,
+		certs.Generate,
+		configClient,
+		nil,
+		os.Stderr,
+		os.Stderr,
+		util.FindUserIP,
+		certs.NewAcmeClient,
+		util.GeneratePasswordWithLength,
+		util.EightRandomLetters,
+		util.GenerateSSHKeyPair,
+		version,
+	)
+
+	return client, nil
+}
+
+var healthCmd = cli.Command{
+	Name:      "health",
+	Aliases:   []string{"hc"},
+	Usage:     "Checks each layer of a deployment and reports pass, warn or fail",
+	ArgsUsage: "<name>",
+	Flags:     healthFlags,
+	Action: func(c *cli.Context) error {
+		iaasName, err := iaas.Assosiate(initialHealthArgs.IAAS)
+		if err != nil {
+			return err
+		}
+		provider, err := iaas.New(iaasName, initialHealthArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on health: [%v]", err)
+		}
+		return healthAction(c, initialHealthArgs, provider)
+	},
+}
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/EngineerBetter/concourse-up/commands/info"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var initialHistoryArgs info.Args
+
+var historyFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "region",
+		Usage:       "(optional) AWS region",
+		EnvVar:      "AWS_REGION",
+		Destination: &initialHistoryArgs.Region,
+	},
+	cli.BoolFlag{
+		Name:        "json",
+		Usage:       "(optional) Output as json",
+		EnvVar:      "JSON",
+		Destination: &initialHistoryArgs.JSON,
+	},
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(optional) IAAS, can be AWS, GCP, AZURE or OPENSTACK",
+		EnvVar:      "IAAS",
+		Value:       "AWS",
+		Destination: &initialHistoryArgs.IAAS,
+	},
+	cli.StringFlag{
+		Name:        "namespace",
+		Usage:       "(optional) Specify a namespace for deployments in order to group them in a meaningful way",
+		EnvVar:      "NAMESPACE",
+		Destination: &initialHistoryArgs.Namespace,
+	},
+}
+
+func historyAction(c *cli.Context, historyArgs info.Args, provider iaas.Provider) error {
+	name := c.Args().Get(0)
+	if name == "" {
+		return errors.New("Usage is `concourse-up history <name>`")
+	}
+
+	configClient, err := newConfigClient(provider, name, historyArgs.Namespace)
+	if err != nil {
+		return err
+	}
+	entries, err := config.LoadAuditLog(configClient)
+	if err != nil {
+		return err
+	}
+
+	if historyArgs.JSON {
+		if entries == nil {
+			entries = []config.AuditEntry{}
+		}
+		return json.NewEncoder(os.Stdout).Encode(entries)
+	}
+	return writeHistory(os.Stdout, entries)
+}
+
+func writeHistory(out io.Writer, entries []config.AuditEntry) error {
+	if len(entries) == 0 {
+		_, err := fmt.Fprintln(out, "No commands have been recorded for this deployment")
+		return err
+	}
+
+	for _, e := range entries {
+		fmt.Fprintf(out, "%s %s %s in %s\n", e.Time.Format(time.RFC3339), e.Command, e.Outcome, e.Duration)
+		fmt.Fprintf(out, "  operator: %s\n", e.Operator)
+		fmt.Fprintf(out, "  version:  %s\n", orNone(e.Version))
+		if len(e.Flags) != 0 {
+			var flags []string
+			for _, f := range e.Flags {
+				flags = append(flags, fmt.Sprintf("--%s=%s", f.Field, f.To))
+			}
+			fmt.Fprintf(out, "  flags:    %s\n", strings.Join(flags, " "))
+		}
+		for _, change := range e.Changes {
+			fmt.Fprintf(out, "  changed:  %s %q -> %q\n", change.Field, change.From, change.To)
+		}
+		if e.Error != "" {
+			fmt.Fprintf(out, "  error:    %s\n", e.Error)
+		}
+		if _, err := fmt.Fprintln(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var historyCmd = cli.Command{
+	Name:      "history",
+	Aliases:   []string{"hi"},
+	Usage:     "Shows the audit log of commands that changed a deployment",
+	ArgsUsage: "<name>",
+	Flags:     historyFlags,
+	Action: func(c *cli.Context) error {
+		iaasName, err := iaas.Assosiate(initialHistoryArgs.IAAS)
+		if err != nil {
+			return err
+		}
+		provider, err := iaas.New(iaasName, initialHistoryArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on history: [%v]", err)
+		}
+		return historyAction(c, initialHistoryArgs, provider)
+	},
+}
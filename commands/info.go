@@ -8,7 +8,6 @@ import (
 	"github.com/EngineerBetter/concourse-up/certs"
 	"github.com/EngineerBetter/concourse-up/commands/info"
 	"github.com/EngineerBetter/concourse-up/concourse"
-	"github.com/EngineerBetter/concourse-up/config"
 	"github.com/EngineerBetter/concourse-up/fly"
 	"github.com/EngineerBetter/concourse-up/iaas"
 	"github.com/EngineerBetter/concourse-up/terraform"
@@ -34,7 +33,7 @@ var infoFlags = []cli.Flag{
 	},
 	cli.BoolFlag{
 		Name:        "env",
-		Usage:       "(optional) Output environment variables",
+		Usage:       "(optional) Output environment variables, including the secrets needed to log into bosh and credhub",
 		Destination: &initialInfoArgs.Env,
 	},
 	cli.BoolFlag{
@@ -42,9 +41,19 @@ var infoFlags = []cli.Flag{
 		Usage:       "(optional) Output only the expiration date of the director nats certificate",
 		Destination: &initialInfoArgs.CertExpiry,
 	},
+	cli.BoolFlag{
+		Name:        "show-secrets",
+		Usage:       "(optional) Read the passwords and keys to list the instances, and output them rather than redacting them",
+		Destination: &initialInfoArgs.ShowSecrets,
+	},
+	cli.BoolFlag{
+		Name:        "credhub-only",
+		Usage:       "(optional) Output only what is needed to log into credhub",
+		Destination: &initialInfoArgs.CredhubOnly,
+	},
 	cli.StringFlag{
 		Name:        "iaas",
-		Usage:       "(optional) IAAS, can be AWS or GCP",
+		Usage:       "(optional) IAAS, can be AWS, GCP, AZURE or OPENSTACK",
 		EnvVar:      "IAAS",
 		Value:       "AWS",
 		Destination: &initialInfoArgs.IAAS,
@@ -74,11 +83,22 @@ func infoAction(c *cli.Context, infoArgs info.Args, provider iaas.Provider) erro
 	if err != nil {
 		return err
 	}
-	i, err := client.FetchInfo()
+	// The secrets are only read when they are needed, so that info works for users who cannot read them
+	fetchInfo := client.FetchInfoWithoutSecrets
+	if infoArgs.ShowSecrets || infoArgs.Env || infoArgs.CertExpiry {
+		fetchInfo = client.FetchInfo
+	}
+	i, err := fetchInfo()
 	if err != nil {
 		return err
 	}
+	if infoArgs.ShowSecrets || infoArgs.Env {
+		i.Secrets = &i.Config.Secrets
+	}
+	i.CredhubOnly = infoArgs.CredhubOnly
 	switch {
+	case infoArgs.JSON && infoArgs.CredhubOnly:
+		return json.NewEncoder(os.Stdout).Encode(i.Credhub())
 	case infoArgs.JSON:
 		return json.NewEncoder(os.Stdout).Encode(i)
 	case infoArgs.Env:
@@ -100,7 +120,7 @@ func infoAction(c *cli.Context, infoArgs info.Args, provider iaas.Provider) erro
 }
 
 func buildInfoClient(name, version string, infoArgs info.Args, provider iaas.Provider) (*concourse.Client, error) {
-	terraformClient, err := terraform.New(provider.IAAS(), terraform.DownloadTerraform())
+	terraformClient, err := terraform.New(provider.IAAS(), terraform.DownloadTerraform(), terraformStateBackend())
 	if err != nil {
 		return nil, err
 	}
@@ -110,6 +130,11 @@ func buildInfoClient(name, version string, infoArgs info.Args, provider iaas.Pro
 		return nil, fmt.Errorf("Error creating TFInputVarsFactory [%v]", err)
 	}
 
+	configClient, err := newConfigClient(provider, name, infoArgs.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
 	client := concourse.NewClient(
 		provider,
 		terraformClient,
@@ -117,7 +142,7 @@ func buildInfoClient(name, version string, infoArgs info.Args, provider iaas.Pro
 		bosh.New,
 		fly.New,
 		certs.Generate,
-		config.New(provider, name, infoArgs.Namespace),
+		configClient,
 		nil,
 		os.Stdout,
 		os.Stderr,
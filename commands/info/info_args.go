@@ -16,6 +16,8 @@ type Args struct {
 	NamespaceIsSet bool
 	IAAS           string
 	CertExpiry     bool
+	ShowSecrets    bool
+	CredhubOnly    bool
 }
 
 //MarkSetFlags is marking which info Args have been set
@@ -27,7 +29,7 @@ func (a *Args) MarkSetFlags(c FlagSetChecker) error {
 				a.RegionIsSet = true
 			case "namespace":
 				a.NamespaceIsSet = true
-			case "iaas", "json", "env", "cert-expiry":
+			case "iaas", "json", "env", "cert-expiry", "show-secrets", "credhub-only":
 				//do nothing
 			default:
 				return fmt.Errorf("flag %q is not supported by info flags", f)
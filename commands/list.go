@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var initialListArgs struct {
+	Region string
+	IAAS   string
+	JSON   bool
+}
+
+var listFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "region",
+		Usage:       "(optional) AWS region",
+		EnvVar:      "AWS_REGION",
+		Destination: &initialListArgs.Region,
+	},
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(optional) IAAS, can be AWS, GCP, AZURE or OPENSTACK",
+		EnvVar:      "IAAS",
+		Value:       "AWS",
+		Destination: &initialListArgs.IAAS,
+	},
+	cli.BoolFlag{
+		Name:        "json",
+		Usage:       "(optional) Output as json",
+		EnvVar:      "JSON",
+		Destination: &initialListArgs.JSON,
+	},
+}
+
+func listAction(provider iaas.Provider, asJSON bool) error {
+	deployments, err := config.List(provider, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		if deployments == nil {
+			deployments = []config.Deployment{}
+		}
+		return json.NewEncoder(os.Stdout).Encode(deployments)
+	}
+
+	return writeDeploymentTable(os.Stdout, deployments)
+}
+
+func writeDeploymentTable(out io.Writer, deployments []config.Deployment) error {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tNAMESPACE\tREGION\tIAAS\tVERSION\tDOMAIN\tWORKERS\tLAST DEPLOYED")
+	for _, d := range deployments {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d x %s\t%s\n",
+			d.Project,
+			d.Namespace,
+			d.Region,
+			d.IAAS,
+			orNone(d.Version),
+			orNone(d.Domain),
+			d.WorkerCount,
+			d.WorkerSize,
+			orNone(d.LastDeployed),
+		)
+	}
+	return w.Flush()
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+var listCmd = cli.Command{
+	Name:    "list",
+	Aliases: []string{"l"},
+	Usage:   "Lists all deployments visible to the current credentials",
+	Flags:   listFlags,
+	Action: func(c *cli.Context) error {
+		iaasName, err := iaas.Assosiate(initialListArgs.IAAS)
+		if err != nil {
+			return err
+		}
+		provider, err := iaas.New(iaasName, initialListArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on list: [%v]", err)
+		}
+		return listAction(provider, initialListArgs.JSON)
+	},
+}
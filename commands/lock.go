@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/EngineerBetter/concourse-up/config"
+)
+
+// withDeploymentLock holds the deployment lock in the config bucket while action runs
+func withDeploymentLock(configClient config.IClient, name, command string, action func() error) error {
+	lock, err := acquireDeploymentLock(configClient, name, command)
+	if err != nil {
+		return err
+	}
+
+	stopRefreshing := refreshDeploymentLock(configClient, lock, config.LockRefreshInterval)
+	err = action()
+	stopRefreshing()
+	err1 := config.ReleaseLock(configClient, lock)
+	if err == nil {
+		err = err1
+	}
+	return err
+}
+
+func acquireDeploymentLock(configClient config.IClient, name, command string) (*config.Lock, error) {
+	lock, err := config.AcquireLock(configClient, command, config.DefaultLockTTL)
+	if _, ok := err.(*config.LockHeldError); ok {
+		return nil, fmt.Errorf("%v\nIf that command is no longer running, remove the lock with `concourse-up unlock --force %s`", err, name)
+	}
+	return lock, err
+}
+
+// refreshDeploymentLock refreshes lock every interval until the func it returns is called, so that the lock does not
+// expire under a deploy that runs for longer than its TTL. A failed refresh is reported but leaves the command running
+func refreshDeploymentLock(configClient config.IClient, lock *config.Lock, interval time.Duration) func() {
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := config.RefreshLock(configClient, lock, config.DefaultLockTTL); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: the deployment lock could not be refreshed: %v\n", err)
+				}
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-stopped
+	}
+}
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/config/configfakes"
+)
+
+func Test_refreshDeploymentLock(t *testing.T) {
+	assets := map[string][]byte{}
+	configClient := &configfakes.FakeIClient{}
+	configClient.HasAssetStub = func(name string) (bool, error) {
+		_, ok := assets[name]
+		return ok, nil
+	}
+	configClient.LoadAssetStub = func(name string) ([]byte, error) {
+		return assets[name], nil
+	}
+	configClient.StoreAssetStub = func(name string, contents []byte) error {
+		assets[name] = contents
+		return nil
+	}
+	configClient.CreateAssetStub = configClient.StoreAssetStub
+
+	lock, err := config.AcquireLock(configClient, "deploy", time.Minute)
+	if err != nil {
+		t.Fatalf("AcquireLock() error = %v", err)
+	}
+
+	stop := refreshDeploymentLock(configClient, lock, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	stop()
+	refreshes := configClient.StoreAssetCallCount()
+	if refreshes == 0 {
+		t.Fatal("the lock was not refreshed")
+	}
+
+	var stored config.Lock
+	if err = json.Unmarshal(assets[config.LockFilename], &stored); err != nil {
+		t.Fatal(err)
+	}
+	if stored.ID != lock.ID || stored.Expires.Before(time.Now().Add(config.DefaultLockTTL-time.Minute)) {
+		t.Errorf("stored lock = %+v, want %s refreshed to expire in %s", stored, lock.ID, config.DefaultLockTTL)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if configClient.StoreAssetCallCount() != refreshes {
+		t.Error("the lock was refreshed after the refresher was stopped")
+	}
+}
@@ -0,0 +1,142 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/certs"
+	"github.com/EngineerBetter/concourse-up/commands/logs"
+	"github.com/EngineerBetter/concourse-up/concourse"
+	"github.com/EngineerBetter/concourse-up/fly"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/terraform"
+	"github.com/EngineerBetter/concourse-up/util"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var initialLogsArgs logs.Args
+
+var logsFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "region",
+		Usage:       "(optional) AWS region",
+		EnvVar:      "AWS_REGION",
+		Destination: &initialLogsArgs.Region,
+	},
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(optional) IAAS, can be AWS, GCP, AZURE or OPENSTACK",
+		EnvVar:      "IAAS",
+		Value:       "AWS",
+		Destination: &initialLogsArgs.IAAS,
+	},
+	cli.StringFlag{
+		Name:        "namespace",
+		Usage:       "(optional) Specify a namespace for deployments in order to group them in a meaningful way",
+		EnvVar:      "NAMESPACE",
+		Destination: &initialLogsArgs.Namespace,
+	},
+	cli.StringSliceFlag{
+		Name:  "job, j",
+		Usage: "(optional) Only include logs for this job, can be web, worker, credhub, uaa or grafana. Can be repeated",
+		Value: &initialLogsArgs.Jobs,
+	},
+	cli.BoolFlag{
+		Name:        "follow, f",
+		Usage:       "(optional) Tail the logs instead of downloading them",
+		Destination: &initialLogsArgs.Follow,
+	},
+	cli.StringFlag{
+		Name:        "dir, d",
+		Usage:       "(optional) Directory to download the logs tarball to",
+		Value:       ".",
+		Destination: &initialLogsArgs.Dir,
+	},
+}
+
+func logsAction(c *cli.Context, logsArgs logs.Args, provider iaas.Provider) error {
+	name := c.Args().Get(0)
+	if name == "" {
+		return errors.New("Usage is `concourse-up logs <name> [instance]`")
+	}
+
+	err := logsArgs.MarkSetFlags(c)
+	if err != nil {
+		return err
+	}
+
+	opts := bosh.LogsOptions{
+		Instance: c.Args().Get(1),
+		Jobs:     logsArgs.Jobs,
+		Follow:   logsArgs.Follow,
+		Dir:      logsArgs.Dir,
+	}
+	if err = opts.Validate(); err != nil {
+		return err
+	}
+
+	client, err := buildLogsClient(name, c.App.Version, logsArgs, provider)
+	if err != nil {
+		return err
+	}
+	return client.Logs(opts)
+}
+
+func buildLogsClient(name, version string, logsArgs logs.Args, provider iaas.Provider) (*concourse.Client, error) {
+	terraformClient, err := terraform.New(provider.IAAS(), terraform.DownloadTerraform(), terraformStateBackend())
+	if err != nil {
+		return nil, err
+	}
+
+	tfInputVarsFactory, err := concourse.NewTFInputVarsFactory(provider)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating TFInputVarsFactory [%v]", err)
+	}
+
+	configClient, err := newConfigClient(provider, name, logsArgs.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	client := concourse.NewClient(
+		provider,
+		terraformClient,
+		tfInputVarsFactory,
+		bosh.New,
+		fly.New,
+		certs.Generate,
+		configClient,
+		nil,
+		os.Stdout,
+		os.Stderr,
+		util.FindUserIP,
+		certs.NewAcmeClient,
+		util.GeneratePasswordWithLength,
+		util.EightRandomLetters,
+		util.GenerateSSHKeyPair,
+		version,
+	)
+
+	return client, nil
+}
+
+var logsCmd = cli.Command{
+	Name:      "logs",
+	Aliases:   []string{"g"},
+	Usage:     "Tails or downloads logs from the Concourse VMs",
+	ArgsUsage: "<name> [instance]",
+	Flags:     logsFlags,
+	Action: func(c *cli.Context) error {
+		iaasName, err := iaas.Assosiate(initialLogsArgs.IAAS)
+		if err != nil {
+			return err
+		}
+		provider, err := iaas.New(iaasName, initialLogsArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on logs: [%v]", err)
+		}
+		return logsAction(c, initialLogsArgs, provider)
+	},
+}
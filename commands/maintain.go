@@ -34,7 +34,7 @@ var maintainFlags = []cli.Flag{
 	},
 	cli.StringFlag{
 		Name:        "iaas",
-		Usage:       "(optional) IAAS, can be AWS or GCP",
+		Usage:       "(optional) IAAS, can be AWS, GCP, AZURE or OPENSTACK",
 		EnvVar:      "IAAS",
 		Value:       "AWS",
 		Destination: &initialMaintainArgs.IAAS,
@@ -66,11 +66,19 @@ func maintainAction(c *cli.Context, maintainArgs maintain.Args, provider iaas.Pr
 		return err
 	}
 
-	client, err := buildMaintainClient(name, version, maintainArgs, provider)
+	configClient, err := newConfigClient(provider, name, maintainArgs.Namespace)
 	if err != nil {
 		return err
 	}
-	err = client.Maintain(maintainArgs)
+	client, err := buildMaintainClient(name, version, provider, configClient)
+	if err != nil {
+		return err
+	}
+	err = withDeploymentLock(configClient, name, "maintain", func() error {
+		return withAuditEntry(c, provider, configClient, "maintain", func() error {
+			return client.Maintain(maintainArgs)
+		})
+	})
 	if err != nil {
 		return err
 	}
@@ -78,8 +86,8 @@ func maintainAction(c *cli.Context, maintainArgs maintain.Args, provider iaas.Pr
 	return nil
 }
 
-func buildMaintainClient(name, version string, maintainArgs maintain.Args, provider iaas.Provider) (*concourse.Client, error) {
-	terraformClient, err := terraform.New(provider.IAAS(), terraform.DownloadTerraform())
+func buildMaintainClient(name, version string, provider iaas.Provider, configClient config.IClient) (*concourse.Client, error) {
+	terraformClient, err := terraform.New(provider.IAAS(), terraform.DownloadTerraform(), terraformStateBackend())
 	if err != nil {
 		return nil, err
 	}
@@ -96,7 +104,7 @@ func buildMaintainClient(name, version string, maintainArgs maintain.Args, provi
 		bosh.New,
 		fly.New,
 		certs.Generate,
-		config.New(provider, name, maintainArgs.Namespace),
+		configClient,
 		nil,
 		os.Stdout,
 		os.Stderr,
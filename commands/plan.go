@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/EngineerBetter/concourse-up/commands/deploy"
+	"github.com/EngineerBetter/concourse-up/iaas"
+
+	cli "gopkg.in/urfave/cli.v1"
+)
+
+var planJSON bool
+
+var planFlags = append([]cli.Flag{
+	cli.BoolFlag{
+		Name:        "json",
+		Usage:       "(optional) Output as json",
+		EnvVar:      "JSON",
+		Destination: &planJSON,
+	},
+}, deployFlags...)
+
+func planAction(c *cli.Context, deployArgs deploy.Args, provider iaas.Provider) error {
+	name := c.Args().Get(0)
+	if name == "" {
+		return errors.New("Usage is `concourse-up plan <name>`")
+	}
+
+	version := c.App.Version
+
+	err := deployArgs.Validate()
+	if err != nil {
+		return err
+	}
+
+	deployArgs, err = setZoneAndRegion(provider.Region(), deployArgs)
+	if err != nil {
+		return err
+	}
+
+	err = validateNameLength(name, provider.IAAS())
+	if err != nil {
+		return err
+	}
+
+	err = validatePrivate(deployArgs.Private, provider.IAAS())
+	if err != nil {
+		return err
+	}
+
+	err = validateSingleZone(deployArgs, provider.IAAS())
+	if err != nil {
+		return err
+	}
+
+	err = validateCidrRanges(provider, deployArgs.NetworkCIDR, deployArgs.PublicCIDR, deployArgs.PrivateCIDR, deployArgs.RDS1CIDR, deployArgs.RDS2CIDR)
+	if err != nil {
+		return err
+	}
+
+	// Progress messages go to stderr so that stdout only contains the plan
+	configClient, err := newConfigClient(provider, name, deployArgs.Namespace)
+	if err != nil {
+		return err
+	}
+	client, err := buildClient(name, version, deployArgs, provider, configClient, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	plan, err := client.Plan()
+	if err != nil {
+		return err
+	}
+
+	if planJSON {
+		return json.NewEncoder(os.Stdout).Encode(plan)
+	}
+	_, err = fmt.Fprint(os.Stdout, plan)
+	return err
+}
+
+var planCmd = cli.Command{
+	Name:      "plan",
+	Aliases:   []string{"p"},
+	Usage:     "Previews the changes a deploy would make",
+	ArgsUsage: "<name>",
+	Flags:     planFlags,
+	Action: func(c *cli.Context) error {
+		deployArgs, err := mergeDeploySpec(c, initialDeployArgs)
+		if err != nil {
+			return err
+		}
+		iaasName, err := iaas.Assosiate(deployArgs.IAAS)
+		if err != nil {
+			return err
+		}
+		provider, err := iaas.New(iaasName, deployArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on plan: [%v]", err)
+		}
+		return planAction(c, deployArgs, provider)
+	},
+}
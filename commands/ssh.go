@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/commands/info"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var initialSSHArgs info.Args
+
+var sshFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "region",
+		Usage:       "(optional) AWS region",
+		EnvVar:      "AWS_REGION",
+		Destination: &initialSSHArgs.Region,
+	},
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(optional) IAAS, can be AWS, GCP, AZURE or OPENSTACK",
+		EnvVar:      "IAAS",
+		Value:       "AWS",
+		Destination: &initialSSHArgs.IAAS,
+	},
+	cli.StringFlag{
+		Name:        "namespace",
+		Usage:       "(optional) Specify a namespace for deployments in order to group them in a meaningful way",
+		EnvVar:      "NAMESPACE",
+		Destination: &initialSSHArgs.Namespace,
+	},
+}
+
+func sshAction(c *cli.Context, sshArgs info.Args, provider iaas.Provider) error {
+	name := c.Args().Get(0)
+	if name == "" {
+		return errors.New("Usage is `concourse-up ssh <name> [director|web/0|worker/N]`")
+	}
+	target := c.Args().Get(1)
+	if target == "" {
+		target = bosh.DirectorInstance
+	}
+
+	err := sshArgs.MarkSetFlags(c)
+	if err != nil {
+		return err
+	}
+
+	client, err := buildInfoClient(name, c.App.Version, sshArgs, provider)
+	if err != nil {
+		return err
+	}
+	return client.SSH(target)
+}
+
+var sshCmd = cli.Command{
+	Name:      "ssh",
+	Aliases:   []string{"s"},
+	Usage:     "Opens a shell on the director or a Concourse VM",
+	ArgsUsage: "<name> [director|web/0|worker/N]",
+	Flags:     sshFlags,
+	Action: func(c *cli.Context) error {
+		iaasName, err := iaas.Assosiate(initialSSHArgs.IAAS)
+		if err != nil {
+			return err
+		}
+		provider, err := iaas.New(iaasName, initialSSHArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on ssh: [%v]", err)
+		}
+		return sshAction(c, initialSSHArgs, provider)
+	},
+}
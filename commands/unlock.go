@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/EngineerBetter/concourse-up/commands/info"
+	"github.com/EngineerBetter/concourse-up/concourse"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/terraform"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var initialUnlockArgs info.Args
+
+var unlockForce bool
+
+var unlockTerraformLockID string
+
+var unlockFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "region",
+		Usage:       "(optional) AWS region",
+		EnvVar:      "AWS_REGION",
+		Destination: &initialUnlockArgs.Region,
+	},
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(optional) IAAS, can be AWS, GCP, AZURE or OPENSTACK",
+		EnvVar:      "IAAS",
+		Value:       "AWS",
+		Destination: &initialUnlockArgs.IAAS,
+	},
+	cli.StringFlag{
+		Name:        "namespace",
+		Usage:       "(optional) Specify a namespace for deployments in order to group them in a meaningful way",
+		EnvVar:      "NAMESPACE",
+		Destination: &initialUnlockArgs.Namespace,
+	},
+	cli.BoolFlag{
+		Name:        "force",
+		Usage:       "(required) Remove the lock even though another command may still be running",
+		Destination: &unlockForce,
+	},
+	cli.StringFlag{
+		Name:        "terraform-lock-id",
+		Usage:       "(optional) Release the lock on the terraform state with this ID instead, as shown when terraform could not acquire it",
+		Destination: &unlockTerraformLockID,
+	},
+}
+
+func unlockAction(c *cli.Context, unlockArgs info.Args, provider iaas.Provider, force bool, terraformLockID string) error {
+	name := c.Args().Get(0)
+	if name == "" {
+		return errors.New("Usage is `concourse-up unlock --force <name>`")
+	}
+
+	configClient, err := newConfigClient(provider, name, unlockArgs.Namespace)
+	if err != nil {
+		return err
+	}
+
+	if terraformLockID != "" {
+		return forceUnlockTerraform(configClient, provider, terraformLockID)
+	}
+
+	if !force {
+		lock, err := config.CurrentLock(configClient)
+		if err != nil {
+			return err
+		}
+		if lock == nil {
+			fmt.Printf("Deployment %s is not locked\n", name)
+			return nil
+		}
+		return fmt.Errorf("deployment is locked by %s, pass --force to remove the lock", lock)
+	}
+
+	lock, err := config.ForceUnlock(configClient)
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		fmt.Printf("Deployment %s is not locked\n", name)
+		return nil
+	}
+	fmt.Printf("Removed the lock held by %s\n", lock)
+	return nil
+}
+
+var unlockCmd = cli.Command{
+	Name:      "unlock",
+	Aliases:   []string{"u"},
+	Usage:     "Removes the lock held on a deployment by a command that did not finish",
+	ArgsUsage: "<name>",
+	Flags:     unlockFlags,
+	Action: func(c *cli.Context) error {
+		iaasName, err := iaas.Assosiate(initialUnlockArgs.IAAS)
+		if err != nil {
+			return err
+		}
+		provider, err := iaas.New(iaasName, initialUnlockArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on unlock: [%v]", err)
+		}
+		return unlockAction(c, initialUnlockArgs, provider, unlockForce, unlockTerraformLockID)
+	},
+}
+
+// forceUnlockTerraform releases a lock on the terraform state left behind by a terraform run which did not finish
+func forceUnlockTerraform(configClient config.IClient, provider iaas.Provider, lockID string) error {
+	conf, err := configClient.Load()
+	if err != nil {
+		return err
+	}
+	tfInputVarsFactory, err := concourse.NewTFInputVarsFactory(provider)
+	if err != nil {
+		return fmt.Errorf("Error creating TFInputVarsFactory [%v]", err)
+	}
+	terraformClient, err := terraform.New(provider.IAAS(), terraform.DownloadTerraform(), terraformStateBackend())
+	if err != nil {
+		return err
+	}
+
+	if err = terraformClient.ForceUnlock(tfInputVarsFactory.NewInputVars(conf), lockID); err != nil {
+		return err
+	}
+	fmt.Printf("Released terraform lock %s\n", lockID)
+	return nil
+}
@@ -0,0 +1,106 @@
+package concourse
+
+import (
+	"fmt"
+
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/terraform"
+)
+
+var awsVersionFile = MustAsset("../../concourse-up-ops/director-versions-aws.json")
+
+func init() {
+	Register(iaas.AWS, IAAS{
+		VersionFile:           awsVersionFile,
+		NewTFInputVarsFactory: newAWSInputVarsFactory,
+		DeleteVMs:             deleteAWSVMs,
+	})
+}
+
+func newAWSInputVarsFactory(iaas.Provider) (TFInputVarsFactory, error) {
+	return &AWSInputVarsFactory{}, nil
+}
+
+type AWSInputVarsFactory struct{}
+
+func (f *AWSInputVarsFactory) NewInputVars(c config.Config) terraform.InputVars {
+	return &terraform.AWSInputVars{
+		NetworkCIDR:            c.NetworkCIDR,
+		PublicCIDR:             c.PublicCIDR,
+		PrivateCIDR:            c.PrivateCIDR,
+		PublicSubnetID:         c.PublicSubnetID,
+		PrivateSubnetID:        c.PrivateSubnetID,
+		RDSSubnetIDs:           c.RDSSubnetIDs,
+		VPCID:                  c.VPCID,
+		AllowIPs:               c.AllowIPs,
+		AvailabilityZone:       c.AvailabilityZone,
+		ConfigBucket:           c.ConfigBucket,
+		DBHighAvailability:     c.DBHighAvailability,
+		Deployment:             c.Deployment,
+		ExtraZones:             extraAWSZones(c),
+		HostedZoneID:           c.HostedZoneID,
+		HostedZoneRecordPrefix: c.HostedZoneRecordPrefix,
+		LockTable:              c.TerraformLockTable,
+		Namespace:              c.Namespace,
+		Private:                c.Private,
+		Project:                c.Project,
+		PublicKey:              c.PublicKey,
+		RDSDefaultDatabaseName: c.RDSDefaultDatabaseName,
+		RDSInstanceClass:       c.RDSInstanceClass,
+		RDSPassword:            c.RDSPassword,
+		RDSUsername:            c.RDSUsername,
+		RDS1CIDR:               c.RDS1CIDR,
+		RDS2CIDR:               c.RDS2CIDR,
+		Region:                 c.Region,
+		SourceAccessIP:         c.SourceAccessIP,
+		TFStatePath:            c.TFStatePath,
+	}
+}
+
+// extraAWSZones returns the zones of c after its availability zone, with the ranges of their private subnets
+func extraAWSZones(c config.Config) []terraform.AWSZone {
+	var zones []terraform.AWSZone
+	for i, cidr := range c.ZonePrivateCIDRs {
+		if i+1 >= len(c.Zones) {
+			break
+		}
+		zones = append(zones, terraform.AWSZone{Name: c.Zones[i+1], PrivateCIDR: cidr})
+	}
+	return zones
+}
+
+func deleteAWSVMs(provider iaas.Provider, conf config.Config, outputs func() (terraform.Outputs, error)) (func() error, error) {
+	teardown, ok := provider.(iaas.VPCTeardown)
+	if !ok {
+		return nil, fmt.Errorf("cannot delete the VMs of a VPC on %s", provider.IAAS())
+	}
+	tfOutputs, err := outputs()
+	if err != nil {
+		return nil, err
+	}
+	vpcID, err := tfOutputs.Get("VPCID")
+	if err != nil {
+		return nil, err
+	}
+
+	// Other VMs may share an existing VPC, so only those in the deployment's security groups are deleted
+	var securityGroupIDs []string
+	if conf.VPCID != "" {
+		for _, key := range []string{"DirectorSecurityGroupID", "VMsSecurityGroupID", "ATCSecurityGroupID"} {
+			id, err := tfOutputs.Get(key)
+			if err != nil {
+				return nil, err
+			}
+			securityGroupIDs = append(securityGroupIDs, id)
+		}
+	}
+	volumes, err := teardown.DeleteVMsInVPC(vpcID, securityGroupIDs...)
+	if err != nil || len(volumes) == 0 {
+		return nil, err
+	}
+	return func() error {
+		fmt.Printf("Scheduling to delete %v volumes\n", len(volumes))
+		return teardown.DeleteVolumes(volumes, iaas.DeleteVolume)
+	}, nil
+}
@@ -0,0 +1,84 @@
+package concourse
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/terraform"
+)
+
+var azureVersionFile = MustAsset("../../concourse-up-ops/director-versions-azure.json")
+
+func init() {
+	Register(iaas.Azure, IAAS{
+		VersionFile:           azureVersionFile,
+		NewTFInputVarsFactory: newAzureInputVarsFactory,
+		DeleteVMs:             deleteVMsInDeployment,
+	})
+}
+
+func newAzureInputVarsFactory(provider iaas.Provider) (TFInputVarsFactory, error) {
+	storageAccount, err := provider.Attr("storage_account")
+	if err != nil {
+		return &AzureInputVarsFactory{}, fmt.Errorf("Error finding attribute [storage_account]: [%v]", err)
+	}
+
+	storageResourceGroup, err := provider.Attr("storage_resource_group")
+	if err != nil {
+		return &AzureInputVarsFactory{}, fmt.Errorf("Error finding attribute [storage_resource_group]: [%v]", err)
+	}
+
+	return &AzureInputVarsFactory{
+		region:               provider.Region(),
+		storageAccount:       storageAccount,
+		storageResourceGroup: storageResourceGroup,
+	}, nil
+}
+
+type AzureInputVarsFactory struct {
+	region               string
+	storageAccount       string
+	storageResourceGroup string
+}
+
+func (f *AzureInputVarsFactory) NewInputVars(c config.Config) terraform.InputVars {
+	zoneName, zoneResourceGroup := azureDNSZone(c.HostedZoneID)
+	recordPrefix := c.HostedZoneRecordPrefix
+	if recordPrefix == zoneName {
+		// The domain is the apex of the zone
+		recordPrefix = "@"
+	}
+
+	return &terraform.AzureInputVars{
+		AllowIPs:             c.AllowIPs,
+		ConfigBucket:         c.ConfigBucket,
+		DBName:               c.RDSDefaultDatabaseName,
+		DBPassword:           c.RDSPassword,
+		DBSKU:                c.RDSInstanceClass,
+		DBUsername:           c.RDSUsername,
+		Deployment:           c.Deployment,
+		DNSRecordPrefix:      recordPrefix,
+		DNSZoneName:          zoneName,
+		DNSZoneResourceGroup: zoneResourceGroup,
+		Namespace:            c.Namespace,
+		PrivateCIDR:          c.PrivateCIDR,
+		PublicCIDR:           c.PublicCIDR,
+		PublicKey:            c.PublicKey,
+		Region:               f.region,
+		SourceAccessIP:       c.SourceAccessIP,
+		StorageAccount:       f.storageAccount,
+		StorageResourceGroup: f.storageResourceGroup,
+	}
+}
+
+// azureDNSZone returns the name and resource group of the DNS zone with the given resource ID, which looks like
+// /subscriptions/<subscription>/resourceGroups/<group>/providers/Microsoft.Network/dnszones/<name>
+func azureDNSZone(id string) (string, string) {
+	parts := strings.Split(strings.Trim(id, "/"), "/")
+	if len(parts) != 8 || !strings.EqualFold(parts[2], "resourceGroups") {
+		return "", ""
+	}
+	return parts[7], parts[3]
+}
@@ -0,0 +1,340 @@
+package concourse
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/config"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	backupConfigFilename = "config.json"
+	backupDatabasesDir   = "databases"
+	backupDumpExtension  = ".dump"
+	backupsPrefix        = "backups/"
+)
+
+// encryptionKeyCredentials are the director-creds.yml variables that encrypt data held in the databases.
+// They must match the databases, so they are restored along with them
+var encryptionKeyCredentials = []string{"credhub-encryption-password", "uaa-passphrase"}
+
+// backupArchive is the contents of a backup
+type backupArchive struct {
+	config    config.Config
+	state     []byte
+	creds     []byte
+	databases map[string][]byte
+}
+
+// Backup archives the config, BOSH director state and the Concourse, UAA and CredHub databases.
+// The archive is written to path, or stored in the config bucket when path is empty.
+// It returns where the archive was written
+func (client *Client) Backup(path string) (string, error) {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return "", fmt.Errorf("error loading existing config [%v]", err)
+	}
+
+	state, err := loadDirectorState(client.configClient)
+	if err != nil {
+		return "", err
+	}
+	if state == nil {
+		return "", errors.New("no BOSH director state was found, this deployment cannot be backed up")
+	}
+	creds, err := loadDirectorCreds(client.configClient)
+	if err != nil {
+		return "", err
+	}
+
+	boshClient, err := client.buildBoshClientFromConfig(conf)
+	if err != nil {
+		return "", err
+	}
+	defer boshClient.Cleanup()
+
+	databases, err := boshClient.Backup()
+	if err != nil {
+		return "", err
+	}
+
+	archive, err := writeBackupArchive(backupArchive{
+		config:    conf,
+		state:     state,
+		creds:     creds,
+		databases: databases,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if path != "" {
+		return path, ioutil.WriteFile(path, archive, 0600)
+	}
+
+	name := fmt.Sprintf("%s%s-%s.tar.gz", backupsPrefix, conf.Deployment, time.Now().UTC().Format("20060102T150405Z"))
+	err = client.configClient.StoreAsset(name, archive)
+	if err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// Restore restores the databases and their encryption keys from a backup into this deployment, then redeploys
+// so that Concourse and CredHub use the restored keys. path is a local archive, or the name of a backup
+// in the config bucket
+func (client *Client) Restore(path string) error {
+	archiveBytes, err := client.loadBackup(path)
+	if err != nil {
+		return err
+	}
+
+	archive, err := readBackupArchive(archiveBytes)
+	if err != nil {
+		return fmt.Errorf("error reading backup %s: [%v]", path, err)
+	}
+
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return fmt.Errorf("error loading existing config [%v]", err)
+	}
+
+	state, err := loadDirectorState(client.configClient)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return errors.New("no BOSH director state was found, deploy before restoring a backup")
+	}
+	creds, err := loadDirectorCreds(client.configClient)
+	if err != nil {
+		return err
+	}
+
+	creds, err = restoreCredentials(creds, archive.creds, encryptionKeyCredentials)
+	if err != nil {
+		return err
+	}
+	conf.EncryptionKey = archive.config.EncryptionKey
+
+	boshClient, err := client.buildBoshClientFromConfig(conf)
+	if err != nil {
+		return err
+	}
+	defer boshClient.Cleanup()
+
+	if err = boshClient.Restore(archive.databases); err != nil {
+		return err
+	}
+
+	if err = client.configClient.Update(conf); err != nil {
+		return err
+	}
+	if err = client.configClient.StoreAsset(bosh.CredsFilename, creds); err != nil {
+		return err
+	}
+
+	state, creds, err = boshClient.Deploy(state, creds, false)
+	err1 := client.configClient.StoreAsset(bosh.StateFilename, state)
+	if err == nil {
+		err = err1
+	}
+	err1 = client.configClient.StoreAsset(bosh.CredsFilename, creds)
+	if err == nil {
+		err = err1
+	}
+	return err
+}
+
+func (client *Client) buildBoshClientFromConfig(conf config.Config) (bosh.IClient, error) {
+	tfInputVars := client.tfInputVarsFactory.NewInputVars(conf)
+	tfOutputs, err := client.readOutputs(conf, tfInputVars)
+	if err != nil {
+		return nil, err
+	}
+	return client.buildBoshClient(conf, tfOutputs)
+}
+
+func (client *Client) loadBackup(path string) ([]byte, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err == nil {
+		return contents, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	name := path
+	if !strings.HasPrefix(name, backupsPrefix) {
+		name = backupsPrefix + name
+	}
+	exists, err := client.configClient.HasAsset(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("backup %s was not found locally or in the config bucket", path)
+	}
+	return client.configClient.LoadAsset(name)
+}
+
+func writeBackupArchive(archive backupArchive) ([]byte, error) {
+	configBytes, err := json.Marshal(archive.config)
+	if err != nil {
+		return nil, err
+	}
+	secretsBytes, err := json.Marshal(archive.config.Secrets)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []struct {
+		name     string
+		contents []byte
+	}{
+		{backupConfigFilename, configBytes},
+		{config.SecretsFilename, secretsBytes},
+		{bosh.StateFilename, archive.state},
+		{bosh.CredsFilename, archive.creds},
+	}
+	for name, dump := range archive.databases {
+		files = append(files, struct {
+			name     string
+			contents []byte
+		}{path.Join(backupDatabasesDir, name+backupDumpExtension), dump})
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	modTime := time.Now()
+	for _, f := range files {
+		err = tw.WriteHeader(&tar.Header{
+			Name:    f.name,
+			Mode:    0600,
+			Size:    int64(len(f.contents)),
+			ModTime: modTime,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err = tw.Write(f.contents); err != nil {
+			return nil, err
+		}
+	}
+	if err = tw.Close(); err != nil {
+		return nil, err
+	}
+	if err = gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func readBackupArchive(contents []byte) (backupArchive, error) {
+	archive := backupArchive{databases: make(map[string][]byte)}
+
+	gz, err := gzip.NewReader(bytes.NewReader(contents))
+	if err != nil {
+		return archive, err
+	}
+	defer gz.Close()
+
+	var configBytes, secretsBytes []byte
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return archive, err
+		}
+		contents, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return archive, err
+		}
+		switch {
+		case header.Name == backupConfigFilename:
+			configBytes = contents
+		case header.Name == config.SecretsFilename:
+			secretsBytes = contents
+		case header.Name == bosh.StateFilename:
+			archive.state = contents
+		case header.Name == bosh.CredsFilename:
+			archive.creds = contents
+		case path.Dir(header.Name) == backupDatabasesDir && path.Ext(header.Name) == backupDumpExtension:
+			archive.databases[strings.TrimSuffix(path.Base(header.Name), backupDumpExtension)] = contents
+		}
+	}
+
+	if configBytes == nil {
+		return archive, fmt.Errorf("%s is missing", backupConfigFilename)
+	}
+	if archive.creds == nil {
+		return archive, fmt.Errorf("%s is missing", bosh.CredsFilename)
+	}
+	if len(archive.databases) == 0 {
+		return archive, errors.New("no database dumps were found")
+	}
+	if err = json.Unmarshal(configBytes, &archive.config); err != nil {
+		return archive, err
+	}
+	// Backups taken before the secrets were moved out of the config keep them in config.json
+	if secretsBytes == nil {
+		secretsBytes = configBytes
+	}
+	err = json.Unmarshal(secretsBytes, &archive.config.Secrets)
+	return archive, err
+}
+
+// restoreCredentials copies the named variables from the backed up creds into the current creds
+func restoreCredentials(current, backup []byte, names []string) ([]byte, error) {
+	var currentVars, backupVars yaml.MapSlice
+	if err := yaml.Unmarshal(current, &currentVars); err != nil {
+		return nil, fmt.Errorf("error parsing %s: [%v]", bosh.CredsFilename, err)
+	}
+	if err := yaml.Unmarshal(backup, &backupVars); err != nil {
+		return nil, fmt.Errorf("error parsing %s from backup: [%v]", bosh.CredsFilename, err)
+	}
+
+	for _, name := range names {
+		value, ok := lookupVar(backupVars, name)
+		if !ok {
+			continue
+		}
+		replaced := false
+		for i := range currentVars {
+			if currentVars[i].Key == name {
+				currentVars[i].Value = value
+				replaced = true
+			}
+		}
+		if !replaced {
+			currentVars = append(currentVars, yaml.MapItem{Key: name, Value: value})
+		}
+	}
+
+	return yaml.Marshal(currentVars)
+}
+
+func lookupVar(vars yaml.MapSlice, name string) (interface{}, bool) {
+	for _, item := range vars {
+		if item.Key == name {
+			return item.Value, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,184 @@
+package concourse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+)
+
+const deployCheckpointFilename = "deploy-checkpoint.json"
+
+// Phases of a deploy that run outside of the director deploy
+const (
+	PhaseConfig    = "config"
+	PhaseTerraform = "terraform"
+	PhaseCerts     = "certs"
+	PhasePipeline  = "pipeline"
+)
+
+// DeployPhases lists every phase of a deploy, in the order they run
+var DeployPhases = append(append([]string{PhaseConfig, PhaseTerraform, PhaseCerts}, bosh.DeployPhases...), PhasePipeline)
+
+// DeployCheckpoint is stored in the config bucket to record how far the last deploy got
+type DeployCheckpoint struct {
+	// Phase is the last phase that completed
+	Phase       string `json:"phase"`
+	FailedPhase string `json:"failed_phase,omitempty"`
+	Error       string `json:"error,omitempty"`
+	// DomainUpdated carries over whether the config phase changed the domain, so that resuming regenerates certs
+	DomainUpdated bool `json:"domain_updated,omitempty"`
+}
+
+// resumeFrom returns the phase a resumed deploy should start from
+func (c DeployCheckpoint) resumeFrom() (string, error) {
+	if c.FailedPhase != "" {
+		return c.FailedPhase, nil
+	}
+	i := phaseIndex(c.Phase)
+	if i == -1 || i == len(DeployPhases)-1 {
+		return "", errors.New("the last deploy completed, there is nothing to resume")
+	}
+	return DeployPhases[i+1], nil
+}
+
+func phaseIndex(phase string) int {
+	for i, p := range DeployPhases {
+		if p == phase {
+			return i
+		}
+	}
+	return -1
+}
+
+// deployProgress tracks the phases of a single deploy and records them in the config bucket
+type deployProgress struct {
+	client     *Client
+	checkpoint DeployCheckpoint
+	from       int
+	current    string
+}
+
+// newDeployProgress starts from the first phase, or from where the last deploy stopped when resuming
+func (client *Client) newDeployProgress(resume bool) (*deployProgress, error) {
+	progress := &deployProgress{client: client}
+	if !resume {
+		return progress, nil
+	}
+
+	checkpoint, err := client.retrieveDeployCheckpoint()
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint == nil {
+		return nil, errors.New("no previous deploy was found to resume")
+	}
+	from, err := checkpoint.resumeFrom()
+	if err != nil {
+		return nil, err
+	}
+
+	progress.checkpoint = *checkpoint
+	progress.from = phaseIndex(from)
+	if progress.from == -1 {
+		return nil, fmt.Errorf("unknown deploy phase %q", from)
+	}
+	return progress, nil
+}
+
+// resuming reports whether phases are being skipped because an earlier deploy completed them
+func (p *deployProgress) resuming() bool {
+	return p.from > 0
+}
+
+// resumePhase returns the phase being resumed from, or an empty string
+func (p *deployProgress) resumePhase() string {
+	if !p.resuming() {
+		return ""
+	}
+	return DeployPhases[p.from]
+}
+
+// resumeBoshPhase returns the director deploy phase being resumed from, or an empty string when the director deploy runs in full
+func (p *deployProgress) resumeBoshPhase() string {
+	phase := p.resumePhase()
+	for _, boshPhase := range bosh.DeployPhases {
+		if phase == boshPhase {
+			return phase
+		}
+	}
+	return ""
+}
+
+// boshPhase returns the director deploy phase that runs first
+func (p *deployProgress) boshPhase() string {
+	if phase := p.resumeBoshPhase(); phase != "" {
+		return phase
+	}
+	return bosh.PhaseCreateEnv
+}
+
+// skip reports whether phase was completed by the deploy being resumed
+func (p *deployProgress) skip(phase string) bool {
+	return phaseIndex(phase) < p.from
+}
+
+// start marks phase as the one in progress
+func (p *deployProgress) start(phase string) {
+	p.current = phase
+}
+
+// done records phase as completed
+func (p *deployProgress) done(phase string) error {
+	p.checkpoint.Phase = phase
+	p.checkpoint.FailedPhase = ""
+	p.checkpoint.Error = ""
+	return p.client.storeDeployCheckpoint(p.checkpoint)
+}
+
+// fail records the phase in progress, or the director deploy phase reported by err, as failed and returns err
+func (p *deployProgress) fail(err error) error {
+	phase := p.current
+	if phaseErr, ok := err.(*bosh.PhaseError); ok {
+		phase = phaseErr.Phase
+	}
+	// Nothing has been changed before the config is stored, so there is nothing to resume
+	if phase == PhaseConfig {
+		return err
+	}
+	p.checkpoint.FailedPhase = phase
+	p.checkpoint.Error = err.Error()
+	if err1 := p.client.storeDeployCheckpoint(p.checkpoint); err1 != nil {
+		return fmt.Errorf("%v (also failed to record the failed deploy phase: %v)", err, err1)
+	}
+	return err
+}
+
+// retrieveDeployCheckpoint returns the checkpoint of the last deploy, or nil if there is none
+func (client *Client) retrieveDeployCheckpoint() (*DeployCheckpoint, error) {
+	exists, err := client.configClient.HasAsset(deployCheckpointFilename)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+	contents, err := client.configClient.LoadAsset(deployCheckpointFilename)
+	if err != nil {
+		return nil, err
+	}
+	var checkpoint DeployCheckpoint
+	if err = json.Unmarshal(contents, &checkpoint); err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+func (client *Client) storeDeployCheckpoint(checkpoint DeployCheckpoint) error {
+	contents, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	return client.configClient.StoreAsset(deployCheckpointFilename, contents)
+}
@@ -1,6 +1,7 @@
 package concourse
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/EngineerBetter/concourse-up/commands/maintain"
@@ -41,13 +42,26 @@ type Client struct {
 type IClient interface {
 	Deploy() error
 	Destroy() error
+	Plan() (*Plan, error)
 	FetchInfo() (*Info, error)
+	FetchInfoWithoutSecrets() (*Info, error)
 	Maintain(maintain.Args) error
+	Backup(path string) (string, error)
+	Restore(path string) error
+	SSH(target string) error
+	Logs(bosh.LogsOptions) error
+	Health() (*Health, error)
 }
 
-//go:generate go-bindata -pkg $GOPACKAGE ../../concourse-up-ops/director-versions-aws.json ../../concourse-up-ops/director-versions-gcp.json
-var awsVersionFile = MustAsset("../../concourse-up-ops/director-versions-aws.json")
-var gcpVersionFile = MustAsset("../../concourse-up-ops/director-versions-gcp.json")
+// Assets lists the files the client keeps in the config bucket, besides the config itself
+var Assets = []string{
+	bosh.StateFilename,
+	bosh.CredsFilename,
+	deployCheckpointFilename,
+	maintenanceFilename,
+}
+
+//go:generate go-bindata -pkg $GOPACKAGE ../../concourse-up-ops/director-versions-aws.json ../../concourse-up-ops/director-versions-gcp.json ../../concourse-up-ops/director-versions-azure.json ../../concourse-up-ops/director-versions-openstack.json
 
 // New returns a new client
 func NewClient(
@@ -66,10 +80,7 @@ func NewClient(
 	eightRandomLetters func() string,
 	sshGenerator func() ([]byte, []byte, string, error),
 	version string) *Client {
-	v, _ := provider.Choose(iaas.Choice{
-		AWS: awsVersionFile,
-		GCP: gcpVersionFile,
-	}).([]byte)
+	i, _ := lookup(provider.IAAS())
 	return &Client{
 		acmeClientConstructor: acmeClientConstructor,
 		boshClientFactory:     boshClientFactory,
@@ -87,7 +98,7 @@ func NewClient(
 		tfCLI:                 tfCLI,
 		tfInputVarsFactory:    tfInputVarsFactory,
 		version:               version,
-		versionFile:           v,
+		versionFile:           i.VersionFile,
 	}
 }
 
@@ -102,3 +113,41 @@ func (client *Client) buildBoshClient(config config.Config, tfOutputs terraform.
 		client.versionFile,
 	)
 }
+
+// accessSecurityGroupID returns the security group which has to allow the user's IP for concourse-up to
+// reach the director, which is the jumpbox's for a private deployment
+func accessSecurityGroupID(conf config.Config, tfOutputs terraform.Outputs) (string, error) {
+	if conf.Private {
+		return tfOutputs.Get("JumpboxSecurityGroupID")
+	}
+	return tfOutputs.Get("DirectorSecurityGroupID")
+}
+
+// accessFirewall names the firewall accessSecurityGroupID returns, after the deployment name
+func accessFirewall(conf config.Config) string {
+	if conf.Private {
+		return "jumpbox"
+	}
+	return "director"
+}
+
+// readOutputs returns the terraform outputs recorded in the terraform state in the config bucket,
+// running terraform output only if the state is missing or in a format which cannot be read.
+// Either way the outputs are checked, so that a missing output fails here rather than as an empty value later
+func (client *Client) readOutputs(conf config.Config, tfInputVars terraform.InputVars) (terraform.Outputs, error) {
+	state, err := client.configClient.LoadTerraformState(conf)
+	if err != nil {
+		return nil, err
+	}
+	tfOutputs, err := terraform.OutputsFromState(client.provider.IAAS(), state)
+	if err == terraform.ErrUnknownStateFormat {
+		tfOutputs, err = client.tfCLI.BuildOutput(tfInputVars)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = tfOutputs.AssertValid(); err != nil {
+		return nil, fmt.Errorf("the terraform outputs are incomplete: %s", err)
+	}
+	return tfOutputs, nil
+}
@@ -0,0 +1,239 @@
+package concourse_test
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/bosh/boshfakes"
+	"github.com/EngineerBetter/concourse-up/certs"
+	"github.com/EngineerBetter/concourse-up/certs/certsfakes"
+	"github.com/EngineerBetter/concourse-up/commands/deploy"
+	"github.com/EngineerBetter/concourse-up/concourse"
+	"github.com/EngineerBetter/concourse-up/concourse/concoursefakes"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/config/configfakes"
+	"github.com/EngineerBetter/concourse-up/fly"
+	"github.com/EngineerBetter/concourse-up/fly/flyfakes"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/iaas/iaasfakes"
+	"github.com/EngineerBetter/concourse-up/terraform"
+	"github.com/EngineerBetter/concourse-up/terraform/terraformfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	. "github.com/tjarratt/gcounterfeiter"
+	"github.com/xenolf/lego/lego"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var _ = Describe("client", func() {
+	var configInBucket config.Config
+	var assets map[string][]byte
+	var databases map[string][]byte
+	var tempDir string
+
+	var buildClient func() concourse.IClient
+	var configClient *configfakes.FakeIClient
+	var boshClient *boshfakes.FakeIClient
+
+	BeforeEach(func() {
+		directorStateFixture, err := ioutil.ReadFile("fixtures/director-state.json")
+		Expect(err).ToNot(HaveOccurred())
+		directorCredsFixture, err := ioutil.ReadFile("fixtures/director-creds.yml")
+		Expect(err).ToNot(HaveOccurred())
+
+		tempDir, err = ioutil.TempDir("", "backup")
+		Expect(err).ToNot(HaveOccurred())
+
+		configInBucket = config.Config{
+			Deployment: "concourse-up-happymeal",
+			Project:    "happymeal",
+			Region:     "eu-west-1",
+			Secrets: config.Secrets{
+				DirectorPassword: "secret123",
+				EncryptionKey:    "original-encryption-key",
+			},
+		}
+		assets = map[string][]byte{
+			bosh.StateFilename: directorStateFixture,
+			bosh.CredsFilename: directorCredsFixture,
+		}
+		databases = map[string][]byte{
+			"concourse_atc": []byte("atc dump"),
+			"credhub":       []byte("credhub dump"),
+			"uaa":           []byte("uaa dump"),
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	JustBeforeEach(func() {
+		provider := &iaasfakes.FakeProvider{}
+		provider.IAASReturns(iaas.AWS)
+
+		terraformCLI := &terraformfakes.FakeCLIInterface{}
+		terraformCLI.BuildOutputReturns(&terraform.AWSOutputs{
+			ATCPublicIP:              terraform.MetadataStringValue{Value: "77.77.77.77"},
+			ATCSecurityGroupID:       terraform.MetadataStringValue{Value: "sg-999"},
+			BlobstoreBucket:          terraform.MetadataStringValue{Value: "blobs.aws.com"},
+			BlobstoreSecretAccessKey: terraform.MetadataStringValue{Value: "abc123"},
+			BlobstoreUserAccessKeyID: terraform.MetadataStringValue{Value: "abc123"},
+			BoshDBAddress:            terraform.MetadataStringValue{Value: "rds.aws.com"},
+			BoshDBPort:               terraform.MetadataStringValue{Value: "5432"},
+			BoshSecretAccessKey:      terraform.MetadataStringValue{Value: "abc123"},
+			BoshUserAccessKeyID:      terraform.MetadataStringValue{Value: "abc123"},
+			DirectorKeyPair:          terraform.MetadataStringValue{Value: "-- KEY --"},
+			DirectorPublicIP:         terraform.MetadataStringValue{Value: "99.99.99.99"},
+			DirectorSecurityGroupID:  terraform.MetadataStringValue{Value: "sg-123"},
+			NatGatewayIP:             terraform.MetadataStringValue{Value: "88.88.88.88"},
+			PrivateSubnetID:          terraform.MetadataStringValue{Value: "sn-private-123"},
+			PublicSubnetID:           terraform.MetadataStringValue{Value: "sn-public-123"},
+			VMsSecurityGroupID:       terraform.MetadataStringValue{Value: "sg-456"},
+			VPCID:                    terraform.MetadataStringValue{Value: "vpc-112233"},
+		}, nil)
+
+		configClient = &configfakes.FakeIClient{}
+		configClient.LoadStub = func() (config.Config, error) {
+			return configInBucket, nil
+		}
+		configClient.HasAssetStub = func(name string) (bool, error) {
+			_, ok := assets[name]
+			return ok, nil
+		}
+		configClient.LoadAssetStub = func(name string) ([]byte, error) {
+			return assets[name], nil
+		}
+		configClient.StoreAssetStub = func(name string, contents []byte) error {
+			assets[name] = contents
+			return nil
+		}
+
+		boshClientFactory := func(config config.Config, outputs terraform.Outputs, stdout, stderr io.Writer, provider iaas.Provider, versionFile []byte) (bosh.IClient, error) {
+			boshClient = &boshfakes.FakeIClient{}
+			boshClient.BackupReturns(databases, nil)
+			boshClient.DeployStub = func(state, creds []byte, detach bool) ([]byte, []byte, error) {
+				return state, creds, nil
+			}
+			return boshClient, nil
+		}
+
+		buildClient = func() concourse.IClient {
+			return concourse.NewClient(
+				provider,
+				terraformCLI,
+				&concoursefakes.FakeTFInputVarsFactory{},
+				boshClientFactory,
+				func(iaas.Provider, fly.Credentials, io.Writer, io.Writer, []byte) (fly.IClient, error) {
+					return &flyfakes.FakeIClient{}, nil
+				},
+				func(c func(u *certs.User) (*lego.Client, error), caName string, provider iaas.Provider, ip ...string) (*certs.Certs, error) {
+					return &certs.Certs{}, nil
+				},
+				configClient,
+				&deploy.Args{},
+				gbytes.NewBuffer(),
+				gbytes.NewBuffer(),
+				func() (string, error) { return "192.0.2.0", nil },
+				certsfakes.NewFakeAcmeClient,
+				func(size int) string { return fmt.Sprintf("generatedPassword%d", size) },
+				func() string { return "8letters" },
+				func() ([]byte, []byte, string, error) { return []byte("private"), []byte("public"), "fingerprint", nil },
+				"some version",
+			)
+		}
+	})
+
+	Describe("Backup", func() {
+		It("stores a timestamped archive in the config bucket", func() {
+			client := buildClient()
+			name, err := client.Backup("")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(name).To(MatchRegexp(`^backups/concourse-up-happymeal-\d{8}T\d{6}Z\.tar\.gz$`))
+			Expect(assets).To(HaveKey(name))
+			Expect(boshClient).To(HaveReceived("Backup"))
+			Expect(boshClient).To(HaveReceived("Cleanup"))
+		})
+
+		It("writes the archive to a local path", func() {
+			path := filepath.Join(tempDir, "backup.tar.gz")
+			client := buildClient()
+			name, err := client.Backup(path)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(name).To(Equal(path))
+			Expect(path).To(BeAnExistingFile())
+			Expect(configClient).ToNot(HaveReceived("StoreAsset"))
+		})
+
+		Context("when there is no director", func() {
+			BeforeEach(func() {
+				delete(assets, bosh.StateFilename)
+			})
+
+			It("returns an error", func() {
+				client := buildClient()
+				_, err := client.Backup("")
+				Expect(err).To(MatchError(ContainSubstring("no BOSH director state was found")))
+			})
+		})
+	})
+
+	Describe("Restore", func() {
+		var path string
+
+		JustBeforeEach(func() {
+			path = filepath.Join(tempDir, "backup.tar.gz")
+			_, err := buildClient().Backup(path)
+			Expect(err).ToNot(HaveOccurred())
+
+			// Restore into a deployment with different encryption keys
+			configInBucket.EncryptionKey = "new-encryption-key"
+			assets[bosh.CredsFilename] = []byte("credhub-encryption-password: new-credhub-key\nuaa-passphrase: new-uaa-passphrase\nadmin_password: new-admin\n")
+		})
+
+		It("restores the databases and their encryption keys then redeploys", func() {
+			client := buildClient()
+			err := client.Restore(path)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(boshClient).To(HaveReceived("Restore").With(databases))
+			Expect(configClient.UpdateCallCount()).To(Equal(1))
+			Expect(configClient.UpdateArgsForCall(0).EncryptionKey).To(Equal("original-encryption-key"))
+
+			var creds map[string]interface{}
+			Expect(yaml.Unmarshal(assets[bosh.CredsFilename], &creds)).To(Succeed())
+			Expect(creds["credhub-encryption-password"]).To(Equal("mc7e07e1eef3y1xfo7sq"))
+			Expect(creds["uaa-passphrase"]).To(Equal("0r747wo7mkm2ykotxgcy"))
+			Expect(creds["admin_password"]).To(Equal("new-admin"))
+
+			Expect(boshClient.DeployCallCount()).To(Equal(1))
+			_, deployedCreds, detach := boshClient.DeployArgsForCall(0)
+			Expect(deployedCreds).To(Equal(assets[bosh.CredsFilename]))
+			Expect(detach).To(BeFalse())
+		})
+
+		It("finds backups in the config bucket by name", func() {
+			contents, err := ioutil.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			assets["backups/concourse-up-happymeal-20190301T100000Z.tar.gz"] = contents
+
+			client := buildClient()
+			err = client.Restore("concourse-up-happymeal-20190301T100000Z.tar.gz")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(boshClient).To(HaveReceived("Restore").With(databases))
+		})
+
+		It("returns an error when the backup cannot be found", func() {
+			client := buildClient()
+			err := client.Restore("missing.tar.gz")
+			Expect(err).To(MatchError("backup missing.tar.gz was not found locally or in the config bucket"))
+		})
+	})
+})
@@ -0,0 +1,333 @@
+package concourse_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/bosh/boshfakes"
+	"github.com/EngineerBetter/concourse-up/certs"
+	"github.com/EngineerBetter/concourse-up/certs/certsfakes"
+	"github.com/EngineerBetter/concourse-up/commands/deploy"
+	"github.com/EngineerBetter/concourse-up/concourse"
+	"github.com/EngineerBetter/concourse-up/concourse/concoursefakes"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/config/configfakes"
+	"github.com/EngineerBetter/concourse-up/fly"
+	"github.com/EngineerBetter/concourse-up/fly/flyfakes"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/iaas/iaasfakes"
+	"github.com/EngineerBetter/concourse-up/terraform"
+	"github.com/EngineerBetter/concourse-up/terraform/terraformfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/xenolf/lego/lego"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func selfSignedCert(validFor time.Duration) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).ToNot(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "health"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(validFor),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).ToNot(HaveOccurred())
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+var _ = Describe("client", func() {
+	Describe("Health", func() {
+		var server *httptest.Server
+		var configInBucket config.Config
+		var tfOutputs terraform.AWSOutputs
+		var natsCA string
+		var instances []bosh.Instance
+		var locks string
+		var workers []fly.Worker
+		var whitelisted bool
+
+		var buildClient func() concourse.IClient
+
+		checkStatus := func(health *concourse.Health, name string) concourse.CheckStatus {
+			for _, check := range health.Checks {
+				if check.Name == name {
+					return check.Status
+				}
+			}
+			return ""
+		}
+
+		BeforeEach(func() {
+			server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/v1/info":
+					fmt.Fprint(w, `{"version":"5.0.1"}`)
+				case "/info":
+					fmt.Fprint(w, `{"app":{"name":"CredHub"}}`)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			serverCA := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+			configInBucket = config.Config{
+				Deployment:           "concourse-up-happymeal",
+				Region:               "eu-west-1",
+				Domain:               strings.TrimPrefix(server.URL, "https://"),
+				ConcourseCACert:      serverCA,
+				ConcourseCert:        selfSignedCert(90 * 24 * time.Hour),
+				ConcourseWorkerCount: 1,
+				CredhubURL:           server.URL,
+				DirectorCert:         selfSignedCert(90 * 24 * time.Hour),
+			}
+			tfOutputs = terraform.AWSOutputs{
+				ATCPublicIP:              terraform.MetadataStringValue{Value: "77.77.77.77"},
+				ATCSecurityGroupID:       terraform.MetadataStringValue{Value: "sg-999"},
+				BlobstoreBucket:          terraform.MetadataStringValue{Value: "blobs.aws.com"},
+				BlobstoreSecretAccessKey: terraform.MetadataStringValue{Value: "abc123"},
+				BlobstoreUserAccessKeyID: terraform.MetadataStringValue{Value: "abc123"},
+				BoshDBAddress:            terraform.MetadataStringValue{Value: "rds.aws.com"},
+				BoshDBPort:               terraform.MetadataStringValue{Value: "5432"},
+				BoshSecretAccessKey:      terraform.MetadataStringValue{Value: "abc123"},
+				BoshUserAccessKeyID:      terraform.MetadataStringValue{Value: "abc123"},
+				DirectorKeyPair:          terraform.MetadataStringValue{Value: "-- KEY --"},
+				DirectorPublicIP:         terraform.MetadataStringValue{Value: "99.99.99.99"},
+				DirectorSecurityGroupID:  terraform.MetadataStringValue{Value: "sg-123"},
+				NatGatewayIP:             terraform.MetadataStringValue{Value: "88.88.88.88"},
+				PrivateSubnetID:          terraform.MetadataStringValue{Value: "sn-private-123"},
+				PublicSubnetID:           terraform.MetadataStringValue{Value: "sn-public-123"},
+				VMsSecurityGroupID:       terraform.MetadataStringValue{Value: "sg-456"},
+				VPCID:                    terraform.MetadataStringValue{Value: "vpc-112233"},
+			}
+			natsCA = selfSignedCert(90 * 24 * time.Hour)
+			instances = []bosh.Instance{
+				{Name: "web/0", IP: "10.0.0.1", State: "running"},
+				{Name: "worker/0", IP: "10.0.1.1", State: "running"},
+			}
+			locks = `{"Tables":[{"Content":"locks","Rows":[]}]}`
+			workers = []fly.Worker{{Name: "worker-0", State: "running"}}
+			whitelisted = true
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		JustBeforeEach(func() {
+			provider := &iaasfakes.FakeProvider{}
+			provider.IAASReturns(iaas.AWS)
+			provider.CheckForWhitelistedIPReturns(whitelisted, nil)
+
+			terraformCLI := &terraformfakes.FakeCLIInterface{}
+			terraformCLI.BuildOutputReturns(&tfOutputs, nil)
+
+			creds, err := yaml.Marshal(map[string]interface{}{
+				"nats_server_tls": map[string]string{"ca": natsCA},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			configClient := &configfakes.FakeIClient{}
+			configClient.LoadReturns(configInBucket, nil)
+			configClient.HasAssetReturns(true, nil)
+			configClient.LoadAssetReturns(creds, nil)
+
+			boshClientFactory := func(config config.Config, outputs terraform.Outputs, stdout, stderr io.Writer, provider iaas.Provider, versionFile []byte) (bosh.IClient, error) {
+				boshClient := &boshfakes.FakeIClient{}
+				boshClient.LocksReturns([]byte(locks), nil)
+				boshClient.InstancesReturns(instances, nil)
+				return boshClient, nil
+			}
+
+			flyClient := &flyfakes.FakeIClient{}
+			flyClient.WorkersReturns(workers, nil)
+
+			buildClient = func() concourse.IClient {
+				return concourse.NewClient(
+					provider,
+					terraformCLI,
+					&concoursefakes.FakeTFInputVarsFactory{},
+					boshClientFactory,
+					func(iaas.Provider, fly.Credentials, io.Writer, io.Writer, []byte) (fly.IClient, error) {
+						return flyClient, nil
+					},
+					func(c func(u *certs.User) (*lego.Client, error), caName string, provider iaas.Provider, ip ...string) (*certs.Certs, error) {
+						return &certs.Certs{}, nil
+					},
+					configClient,
+					&deploy.Args{},
+					gbytes.NewBuffer(),
+					gbytes.NewBuffer(),
+					func() (string, error) { return "192.0.2.0", nil },
+					certsfakes.NewFakeAcmeClient,
+					func(size int) string { return fmt.Sprintf("generatedPassword%d", size) },
+					func() string { return "8letters" },
+					func() ([]byte, []byte, string, error) { return []byte("private"), []byte("public"), "fingerprint", nil },
+					"some version",
+				)
+			}
+		})
+
+		It("checks every layer of the deployment", func() {
+			health, err := buildClient().Health()
+			Expect(err).ToNot(HaveOccurred())
+
+			var names []string
+			for _, check := range health.Checks {
+				names = append(names, check.Name)
+			}
+			Expect(names).To(Equal([]string{"terraform", "director", "instances", "atc", "workers", "credhub", "concourse-cert", "director-cert", "nats-cert"}))
+
+			for _, name := range []string{"terraform", "director", "instances", "workers", "credhub", "concourse-cert", "director-cert", "nats-cert"} {
+				Expect(checkStatus(health, name)).To(Equal(concourse.CheckPass), name)
+			}
+			// The bundled release version is not available to the test build, so the ATC check can at best warn
+			Expect(checkStatus(health, "atc")).To(Equal(concourse.CheckWarn))
+			Expect(health.Status).To(Equal(concourse.CheckWarn))
+			Expect(health.ExitCode()).To(Equal(1))
+		})
+
+		Context("when the terraform outputs are incomplete", func() {
+			BeforeEach(func() {
+				tfOutputs.DirectorPublicIP = terraform.MetadataStringValue{}
+			})
+
+			It("fails without checking the other layers", func() {
+				health, err := buildClient().Health()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(health.Checks).To(HaveLen(1))
+				Expect(health.Status).To(Equal(concourse.CheckFail))
+				Expect(health.ExitCode()).To(Equal(2))
+			})
+		})
+
+		Context("when the user's IP is not whitelisted", func() {
+			BeforeEach(func() {
+				whitelisted = false
+			})
+
+			It("fails the director check and skips the instances", func() {
+				health, err := buildClient().Health()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(checkStatus(health, "director")).To(Equal(concourse.CheckFail))
+				Expect(checkStatus(health, "instances")).To(BeEmpty())
+				Expect(checkStatus(health, "workers")).To(Equal(concourse.CheckPass))
+			})
+		})
+
+		Context("when the director holds locks", func() {
+			BeforeEach(func() {
+				locks = `{"Tables":[{"Content":"locks","Rows":[{"type":"deployment"}]}]}`
+			})
+
+			It("warns", func() {
+				health, err := buildClient().Health()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(checkStatus(health, "director")).To(Equal(concourse.CheckWarn))
+			})
+		})
+
+		Context("when an instance is not running", func() {
+			BeforeEach(func() {
+				instances[1].State = "failing"
+			})
+
+			It("fails", func() {
+				health, err := buildClient().Health()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(checkStatus(health, "instances")).To(Equal(concourse.CheckFail))
+				Expect(health.Status).To(Equal(concourse.CheckFail))
+			})
+		})
+
+		Context("when a worker has stalled", func() {
+			BeforeEach(func() {
+				workers = append(workers, fly.Worker{Name: "worker-1", State: "stalled"})
+			})
+
+			It("warns", func() {
+				health, err := buildClient().Health()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(checkStatus(health, "workers")).To(Equal(concourse.CheckWarn))
+			})
+		})
+
+		Context("when no workers are running", func() {
+			BeforeEach(func() {
+				workers = []fly.Worker{{Name: "worker-0", State: "stalled"}}
+			})
+
+			It("fails", func() {
+				health, err := buildClient().Health()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(checkStatus(health, "workers")).To(Equal(concourse.CheckFail))
+			})
+		})
+
+		Context("when CredHub is not reachable", func() {
+			BeforeEach(func() {
+				configInBucket.CredhubURL = server.URL + "/missing"
+			})
+
+			It("fails", func() {
+				health, err := buildClient().Health()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(checkStatus(health, "credhub")).To(Equal(concourse.CheckFail))
+			})
+		})
+
+		Context("when certificates are close to expiry", func() {
+			BeforeEach(func() {
+				configInBucket.ConcourseCert = selfSignedCert(14 * 24 * time.Hour)
+				natsCA = selfSignedCert(2 * 24 * time.Hour)
+			})
+
+			It("warns, then fails as expiry approaches", func() {
+				health, err := buildClient().Health()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(checkStatus(health, "concourse-cert")).To(Equal(concourse.CheckWarn))
+				Expect(checkStatus(health, "nats-cert")).To(Equal(concourse.CheckFail))
+			})
+		})
+
+		Context("when the config cannot be loaded", func() {
+			It("returns an error", func() {
+				client := concourse.NewClient(
+					&iaasfakes.FakeProvider{},
+					&terraformfakes.FakeCLIInterface{},
+					&concoursefakes.FakeTFInputVarsFactory{},
+					nil,
+					nil,
+					nil,
+					&configfakes.FakeIClient{LoadStub: func() (config.Config, error) { return config.Config{}, errors.New("no config") }},
+					&deploy.Args{},
+					gbytes.NewBuffer(),
+					gbytes.NewBuffer(),
+					nil,
+					nil,
+					nil,
+					nil,
+					nil,
+					"some version",
+				)
+				_, err := client.Health()
+				Expect(err).To(MatchError("no config"))
+			})
+		})
+	})
+})
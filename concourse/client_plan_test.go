@@ -0,0 +1,225 @@
+package concourse_test
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/bosh/boshfakes"
+	"github.com/EngineerBetter/concourse-up/certs"
+	"github.com/EngineerBetter/concourse-up/certs/certsfakes"
+	"github.com/EngineerBetter/concourse-up/commands/deploy"
+	"github.com/EngineerBetter/concourse-up/concourse"
+	"github.com/EngineerBetter/concourse-up/concourse/concoursefakes"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/config/configfakes"
+	"github.com/EngineerBetter/concourse-up/fly"
+	"github.com/EngineerBetter/concourse-up/fly/flyfakes"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/iaas/iaasfakes"
+	"github.com/EngineerBetter/concourse-up/terraform"
+	"github.com/EngineerBetter/concourse-up/terraform/terraformfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	. "github.com/tjarratt/gcounterfeiter"
+	"github.com/xenolf/lego/lego"
+)
+
+var _ = Describe("client", func() {
+	var args *deploy.Args
+	var configInBucket config.Config
+	var directorStateFixture, directorCredsFixture []byte
+	var certGenerationActions []string
+
+	var buildClient func() concourse.IClient
+	var terraformCLI *terraformfakes.FakeCLIInterface
+	var configClient *configfakes.FakeIClient
+	var boshClient *boshfakes.FakeIClient
+
+	BeforeEach(func() {
+		var err error
+		directorStateFixture, err = ioutil.ReadFile("fixtures/director-state.json")
+		Expect(err).ToNot(HaveOccurred())
+		directorCredsFixture, err = ioutil.ReadFile("fixtures/director-creds.yml")
+		Expect(err).ToNot(HaveOccurred())
+
+		args = &deploy.Args{
+			AllowIPs:    "0.0.0.0/0",
+			DBSize:      "small",
+			IAAS:        "AWS",
+			Preemptible: true,
+			Spot:        true,
+			WebSize:     "small",
+			WorkerCount: 1,
+			WorkerSize:  "xlarge",
+			WorkerType:  "m4",
+		}
+
+		configInBucket = config.Config{
+			AllowIPs:          "\"0.0.0.0/0\"",
+			ConcourseUsername: "admin",
+			Deployment:        "concourse-up-happymeal",
+			DirectorUsername:  "admin",
+			NetworkCIDR:       "10.0.0.0/16",
+			PrivateCIDR:       "10.0.1.0/24",
+			Project:           "happymeal",
+			PublicCIDR:        "10.0.0.0/24",
+			RDS1CIDR:          "10.0.4.0/24",
+			RDS2CIDR:          "10.0.5.0/24",
+			RDSInstanceClass:  "db.t2.small",
+			Region:            "eu-west-1",
+			SourceAccessIP:    "192.0.2.0",
+			Tags:              []string{"concourse-up-version=some version"},
+			Version:           "some version",
+			Secrets: config.Secrets{
+				ConcoursePassword: "s3cret",
+				DirectorPassword:  "secret123",
+				RDSPassword:       "s3cret",
+			},
+		}
+
+		certGenerationActions = []string{}
+	})
+
+	JustBeforeEach(func() {
+		provider := &iaasfakes.FakeProvider{}
+		provider.DBTypeStub = func(size string) string {
+			return "db.t2." + size
+		}
+		provider.RegionReturns("eu-west-1")
+		provider.IAASReturns(iaas.AWS)
+
+		awsProvider, err := iaas.New(iaas.AWS, "eu-west-1")
+		Expect(err).ToNot(HaveOccurred())
+		awsInputVarsFactory, err := concourse.NewTFInputVarsFactory(awsProvider)
+		Expect(err).ToNot(HaveOccurred())
+		tfInputVarsFactory := &concoursefakes.FakeTFInputVarsFactory{}
+		tfInputVarsFactory.NewInputVarsStub = func(i config.Config) terraform.InputVars {
+			return awsInputVarsFactory.NewInputVars(i)
+		}
+
+		terraformCLI = &terraformfakes.FakeCLIInterface{}
+		terraformCLI.BuildOutputReturns(&terraform.AWSOutputs{
+			ATCPublicIP:      terraform.MetadataStringValue{Value: "77.77.77.77"},
+			DirectorPublicIP: terraform.MetadataStringValue{Value: "99.99.99.99"},
+		}, nil)
+		terraformCLI.PlanReturns(terraform.Plan{
+			Changes: []terraform.Change{
+				{Action: "update", Address: "aws_db_instance.default"},
+				{Action: "replace", Address: "aws_eip.director"},
+			},
+		}, nil)
+
+		configClient = &configfakes.FakeIClient{}
+
+		boshClientFactory := func(config config.Config, outputs terraform.Outputs, stdout, stderr io.Writer, provider iaas.Provider, versionFile []byte) (bosh.IClient, error) {
+			boshClient = &boshfakes.FakeIClient{}
+			boshClient.DiffReturns(bosh.Diff{Concourse: "  instance_groups:\n-   instances: 1\n+   instances: 2"}, nil)
+			return boshClient, nil
+		}
+
+		certGenerator := func(c func(u *certs.User) (*lego.Client, error), caName string, provider iaas.Provider, ip ...string) (*certs.Certs, error) {
+			certGenerationActions = append(certGenerationActions, caName)
+			return &certs.Certs{}, nil
+		}
+
+		buildClient = func() concourse.IClient {
+			return concourse.NewClient(
+				provider,
+				terraformCLI,
+				tfInputVarsFactory,
+				boshClientFactory,
+				func(iaas.Provider, fly.Credentials, io.Writer, io.Writer, []byte) (fly.IClient, error) {
+					return &flyfakes.FakeIClient{}, nil
+				},
+				certGenerator,
+				configClient,
+				args,
+				gbytes.NewBuffer(),
+				gbytes.NewBuffer(),
+				func() (string, error) { return "192.0.2.0", nil },
+				certsfakes.NewFakeAcmeClient,
+				func(size int) string { return fmt.Sprintf("generatedPassword%d", size) },
+				func() string { return "8letters" },
+				func() ([]byte, []byte, string, error) { return []byte("private"), []byte("public"), "fingerprint", nil },
+				"some version",
+			)
+		}
+	})
+
+	Describe("Plan", func() {
+		Context("when there is an existing deployment", func() {
+			BeforeEach(func() {
+				args.DBSize = "large"
+				args.DBSizeIsSet = true
+			})
+
+			JustBeforeEach(func() {
+				configClient.LoadReturns(configInBucket, nil)
+				configClient.ConfigExistsReturns(true, nil)
+				configClient.HasAssetReturns(true, nil)
+				configClient.LoadAssetReturnsOnCall(0, directorStateFixture, nil)
+				configClient.LoadAssetReturnsOnCall(1, directorCredsFixture, nil)
+			})
+
+			It("reports the config, terraform and BOSH changes", func() {
+				client := buildClient()
+				plan, err := client.Plan()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(plan.NewDeployment).To(BeFalse())
+				Expect(plan.Config).To(ConsistOf(config.FieldChange{
+					Field: "rds_instance_class",
+					From:  "db.t2.small",
+					To:    "db.t2.large",
+				}))
+				Expect(plan.Terraform.Changes).To(HaveLen(2))
+				Expect(plan.Concourse).To(ContainSubstring("+   instances: 2"))
+				Expect(plan.CloudConfig).To(BeEmpty())
+				Expect(plan.Notes).To(ContainElement(ContainSubstring("BOSH diff is computed against the current terraform outputs")))
+				Expect(boshClient).To(HaveReceived("Diff").With(directorCredsFixture))
+				Expect(boshClient).To(HaveReceived("Cleanup"))
+			})
+
+			It("does not apply or persist anything", func() {
+				client := buildClient()
+				_, err := client.Plan()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(terraformCLI).ToNot(HaveReceived("Apply"))
+				Expect(configClient).ToNot(HaveReceived("Update"))
+				Expect(configClient).ToNot(HaveReceived("StoreAsset"))
+				Expect(boshClient).ToNot(HaveReceived("Deploy"))
+				Expect(certGenerationActions).To(BeEmpty())
+			})
+
+			It("warns about destructive changes", func() {
+				client := buildClient()
+				plan, err := client.Plan()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(plan.String()).To(ContainSubstring("WARNING: the following resources will be destroyed or replaced"))
+				Expect(plan.String()).To(ContainSubstring("aws_eip.director"))
+			})
+		})
+
+		Context("when there is no existing deployment", func() {
+			JustBeforeEach(func() {
+				configClient.ConfigExistsReturns(false, nil)
+			})
+
+			It("reports a new deployment without contacting the director", func() {
+				client := buildClient()
+				plan, err := client.Plan()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(plan.NewDeployment).To(BeTrue())
+				Expect(configClient).ToNot(HaveReceived("Update"))
+				Expect(terraformCLI).ToNot(HaveReceived("BuildOutput"))
+				Expect(plan.String()).To(ContainSubstring("a new deployment will be created"))
+			})
+		})
+	})
+})
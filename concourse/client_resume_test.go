@@ -0,0 +1,237 @@
+package concourse_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/bosh/boshfakes"
+	"github.com/EngineerBetter/concourse-up/certs"
+	"github.com/EngineerBetter/concourse-up/certs/certsfakes"
+	"github.com/EngineerBetter/concourse-up/commands/deploy"
+	"github.com/EngineerBetter/concourse-up/concourse"
+	"github.com/EngineerBetter/concourse-up/concourse/concoursefakes"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/config/configfakes"
+	"github.com/EngineerBetter/concourse-up/fly"
+	"github.com/EngineerBetter/concourse-up/fly/flyfakes"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/iaas/iaasfakes"
+	"github.com/EngineerBetter/concourse-up/terraform"
+	"github.com/EngineerBetter/concourse-up/terraform/terraformfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	. "github.com/tjarratt/gcounterfeiter"
+	"github.com/xenolf/lego/lego"
+)
+
+var _ = Describe("client", func() {
+	Describe("Deploy checkpoints", func() {
+		var configInBucket config.Config
+		var assets map[string][]byte
+		var deployErr error
+		var args *deploy.Args
+		var certsGenerated int
+		var stdout *gbytes.Buffer
+
+		var terraformCLI *terraformfakes.FakeCLIInterface
+		var configClient *configfakes.FakeIClient
+		var boshClient *boshfakes.FakeIClient
+		var flyClient *flyfakes.FakeIClient
+		var buildClient func() concourse.IClient
+
+		checkpoint := func() concourse.DeployCheckpoint {
+			var c concourse.DeployCheckpoint
+			Expect(json.Unmarshal(assets["deploy-checkpoint.json"], &c)).To(Succeed())
+			return c
+		}
+
+		BeforeEach(func() {
+			directorStateFixture, err := ioutil.ReadFile("fixtures/director-state.json")
+			Expect(err).ToNot(HaveOccurred())
+			directorCredsFixture, err := ioutil.ReadFile("fixtures/director-creds.yml")
+			Expect(err).ToNot(HaveOccurred())
+
+			configInBucket = config.Config{
+				Deployment:        "concourse-up-happymeal",
+				Project:           "happymeal",
+				Region:            "eu-west-1",
+				ConcourseUsername: "admin",
+				PublicCIDR:        "10.0.0.0/24",
+				RDSInstanceClass:  "db.t2.small",
+				Secrets: config.Secrets{
+					ConcoursePassword: "s3cret",
+				},
+			}
+			assets = map[string][]byte{
+				bosh.StateFilename: directorStateFixture,
+				bosh.CredsFilename: directorCredsFixture,
+			}
+			deployErr = nil
+			args = &deploy.Args{AllowIPs: "0.0.0.0/0", DBSize: "small"}
+			certsGenerated = 0
+			stdout = gbytes.NewBuffer()
+			boshClient = nil
+		})
+
+		JustBeforeEach(func() {
+			provider := &iaasfakes.FakeProvider{}
+			provider.IAASReturns(iaas.AWS)
+			provider.RegionReturns("eu-west-1")
+			provider.DBTypeReturns("db.t2.small")
+
+			terraformCLI = &terraformfakes.FakeCLIInterface{}
+			terraformCLI.BuildOutputReturns(&terraform.AWSOutputs{
+				ATCPublicIP:      terraform.MetadataStringValue{Value: "77.77.77.77"},
+				DirectorPublicIP: terraform.MetadataStringValue{Value: "99.99.99.99"},
+			}, nil)
+
+			configClient = &configfakes.FakeIClient{}
+			configClient.ConfigExistsReturns(true, nil)
+			configClient.LoadStub = func() (config.Config, error) {
+				return configInBucket, nil
+			}
+			configClient.UpdateStub = func(conf config.Config) error {
+				configInBucket = conf
+				return nil
+			}
+			configClient.HasAssetStub = func(name string) (bool, error) {
+				_, ok := assets[name]
+				return ok, nil
+			}
+			configClient.LoadAssetStub = func(name string) ([]byte, error) {
+				return assets[name], nil
+			}
+			configClient.StoreAssetStub = func(name string, contents []byte) error {
+				assets[name] = contents
+				return nil
+			}
+
+			boshClientFactory := func(config config.Config, outputs terraform.Outputs, stdout, stderr io.Writer, provider iaas.Provider, versionFile []byte) (bosh.IClient, error) {
+				boshClient = &boshfakes.FakeIClient{}
+				boshClient.DeployStub = func(state, creds []byte, detach bool) ([]byte, []byte, error) {
+					return state, creds, deployErr
+				}
+				boshClient.DeployFromStub = func(state, creds []byte, detach bool, from string) ([]byte, []byte, error) {
+					return state, creds, deployErr
+				}
+				return boshClient, nil
+			}
+
+			flyClient = &flyfakes.FakeIClient{}
+
+			buildClient = func() concourse.IClient {
+				return concourse.NewClient(
+					provider,
+					terraformCLI,
+					&concoursefakes.FakeTFInputVarsFactory{},
+					boshClientFactory,
+					func(iaas.Provider, fly.Credentials, io.Writer, io.Writer, []byte) (fly.IClient, error) {
+						return flyClient, nil
+					},
+					func(c func(u *certs.User) (*lego.Client, error), caName string, provider iaas.Provider, ip ...string) (*certs.Certs, error) {
+						certsGenerated++
+						return &certs.Certs{CACert: []byte("----EXAMPLE CERT----")}, nil
+					},
+					configClient,
+					args,
+					stdout,
+					gbytes.NewBuffer(),
+					func() (string, error) { return "192.0.2.0", nil },
+					certsfakes.NewFakeAcmeClient,
+					func(size int) string { return fmt.Sprintf("generatedPassword%d", size) },
+					func() string { return "8letters" },
+					func() ([]byte, []byte, string, error) { return []byte("private"), []byte("public"), "fingerprint", nil },
+					"some version",
+				)
+			}
+		})
+
+		It("records every phase of a successful deploy", func() {
+			Expect(buildClient().Deploy()).To(Succeed())
+			Expect(checkpoint()).To(Equal(concourse.DeployCheckpoint{Phase: "pipeline"}))
+		})
+
+		Context("when the director deploy fails", func() {
+			BeforeEach(func() {
+				deployErr = &bosh.PhaseError{Phase: bosh.PhaseStemcell, Err: errors.New("upload failed")}
+			})
+
+			It("records the phase that failed", func() {
+				err := buildClient().Deploy()
+				Expect(err).To(MatchError("upload failed"))
+				Expect(checkpoint()).To(Equal(concourse.DeployCheckpoint{
+					Phase:       "certs",
+					FailedPhase: "stemcell",
+					Error:       "upload failed",
+				}))
+				Expect(flyClient).ToNot(HaveReceived("SetDefaultPipeline"))
+			})
+		})
+
+		Context("when resuming from a failed phase", func() {
+			BeforeEach(func() {
+				args.Resume = true
+				assets["deploy-checkpoint.json"] = []byte(`{"phase":"certs","failed_phase":"stemcell","error":"upload failed"}`)
+			})
+
+			It("skips the phases that completed", func() {
+				Expect(buildClient().Deploy()).To(Succeed())
+
+				Eventually(stdout).Should(gbytes.Say("RESUMING DEPLOY FROM PHASE stemcell"))
+				Expect(configClient).ToNot(HaveReceived("ConfigExists"))
+				Expect(terraformCLI).ToNot(HaveReceived("Apply"))
+				Expect(terraformCLI).To(HaveReceived("BuildOutput"))
+				Expect(certsGenerated).To(BeZero())
+				Expect(boshClient).ToNot(HaveReceived("Deploy"))
+				Expect(boshClient.DeployFromCallCount()).To(Equal(1))
+				_, _, _, from := boshClient.DeployFromArgsForCall(0)
+				Expect(from).To(Equal("stemcell"))
+				Expect(flyClient).To(HaveReceived("SetDefaultPipeline"))
+				Expect(checkpoint()).To(Equal(concourse.DeployCheckpoint{Phase: "pipeline"}))
+			})
+		})
+
+		Context("when resuming after the director deploy", func() {
+			BeforeEach(func() {
+				args.Resume = true
+				assets["deploy-checkpoint.json"] = []byte(`{"phase":"bosh-deploy","failed_phase":"pipeline","error":"fly failed"}`)
+			})
+
+			It("only sets the pipeline", func() {
+				Expect(buildClient().Deploy()).To(Succeed())
+
+				Expect(boshClient).To(BeNil())
+				Expect(flyClient).To(HaveReceived("SetDefaultPipeline"))
+				Expect(configInBucket.CredhubUsername).To(Equal("credhub-cli"))
+			})
+		})
+
+		Context("when resuming a deploy that completed", func() {
+			BeforeEach(func() {
+				args.Resume = true
+				assets["deploy-checkpoint.json"] = []byte(`{"phase":"pipeline"}`)
+			})
+
+			It("returns an error", func() {
+				err := buildClient().Deploy()
+				Expect(err).To(MatchError("the last deploy completed, there is nothing to resume"))
+			})
+		})
+
+		Context("when resuming without a previous deploy", func() {
+			BeforeEach(func() {
+				args.Resume = true
+			})
+
+			It("returns an error", func() {
+				err := buildClient().Deploy()
+				Expect(err).To(MatchError("no previous deploy was found to resume"))
+			})
+		})
+	})
+})
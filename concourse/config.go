@@ -9,6 +9,7 @@ import (
 	"github.com/EngineerBetter/concourse-up/commands/deploy"
 	"github.com/EngineerBetter/concourse-up/config"
 	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/apparentlymart/go-cidr/cidr"
 	"github.com/asaskevich/govalidator"
 )
 
@@ -18,8 +19,27 @@ func (client *Client) getInitialConfig() (config.Config, bool, error) {
 		return config.Config{}, false, fmt.Errorf("error determining if config already exists [%v]", err)
 	}
 
+	conf, isDomainUpdated, err := client.mergeConfig(priorConfigExists)
+	if err != nil {
+		return config.Config{}, false, err
+	}
+
+	if !priorConfigExists {
+		err = client.configClient.Update(conf)
+		if err != nil {
+			return config.Config{}, false, fmt.Errorf("error persisting new config after setting values [%v]", err)
+		}
+	}
+
+	return conf, isDomainUpdated, nil
+}
+
+// mergeConfig combines the deploy arguments with the existing config, or with a newly generated one
+// if no prior config exists. Nothing is persisted
+func (client *Client) mergeConfig(priorConfigExists bool) (config.Config, bool, error) {
 	var isDomainUpdated bool
 	var conf config.Config
+	var err error
 	if priorConfigExists {
 		if client.deployArgs.NetworkCIDRIsSet || client.deployArgs.PrivateCIDRIsSet || client.deployArgs.PublicCIDRIsSet {
 			return config.Config{}, false, fmt.Errorf("custom CIDRs cannot be applied after intial deploy")
@@ -28,6 +48,9 @@ func (client *Client) getInitialConfig() (config.Config, bool, error) {
 		if err != nil {
 			return config.Config{}, false, fmt.Errorf("error loading existing config [%v]", err)
 		}
+		if err = checkExistingNetworkUnchanged(conf, client.deployArgs); err != nil {
+			return config.Config{}, false, err
+		}
 		err = writeConfigLoadedSuccessMessage(client.stdout)
 		if err != nil {
 			return config.Config{}, false, fmt.Errorf("error writing config loaded success message [%v]", err)
@@ -44,11 +67,6 @@ func (client *Client) getInitialConfig() (config.Config, bool, error) {
 			return config.Config{}, false, fmt.Errorf("error generating new config: [%v]", err)
 		}
 
-		err = client.configClient.Update(conf)
-		if err != nil {
-			return config.Config{}, false, fmt.Errorf("error persisting new config after setting values [%v]", err)
-		}
-
 		isDomainUpdated = true
 	}
 
@@ -68,21 +86,98 @@ func newConfig(configClient config.IClient, deployArgs *deploy.Args, provider ia
 	}
 
 	// Stuff from concourse.Deploy()
-	switch provider.IAAS() {
-	case iaas.AWS: // nolint
-		conf.RDSDefaultDatabaseName = fmt.Sprintf("bosh_%s", eightRandomLetters())
-	case iaas.GCP: // nolint
-		conf.RDSDefaultDatabaseName = fmt.Sprintf("bosh-%s", eightRandomLetters())
+	r, err := iaas.Lookup(provider.IAAS())
+	if err != nil {
+		return config.Config{}, err
 	}
+	conf.RDSDefaultDatabaseName = fmt.Sprintf(r.DBNameFormat, eightRandomLetters())
+	conf.RDSPassword = r.DBPasswordPrefix + conf.RDSPassword
 
 	// Why do we do this here?
 	provider.WorkerType(conf.ConcourseWorkerSize)
 	conf.AvailabilityZone = provider.Zone(deployArgs.Zone)
+	if len(conf.Zones) == 0 && conf.AvailabilityZone != "" {
+		conf.Zones = []string{conf.AvailabilityZone}
+	}
 	// End stuff from concourse.Deploy()
 
+	conf, err = useExistingNetwork(conf, deployArgs, provider)
+	if err != nil {
+		return config.Config{}, err
+	}
+
+	return conf, nil
+}
+
+// useExistingNetwork records the existing network given to the first deploy, and replaces the default ranges with
+// those of its subnets. On AWS the deployment also moves to the zone of the subnets, which cannot span zones
+func useExistingNetwork(conf config.Config, deployArgs *deploy.Args, provider iaas.Provider) (config.Config, error) {
+	switch {
+	case deployArgs.VPCID != "":
+		subnets := append([]string{deployArgs.PublicSubnetID, deployArgs.PrivateSubnetID}, deployArgs.RDSSubnetIDList()...)
+		network, err := provider.FindNetwork(deployArgs.VPCID, subnets...)
+		if err != nil {
+			return config.Config{}, err
+		}
+		public, private, rds := network.Subnets[0], network.Subnets[1], network.Subnets[2:]
+		if public.Zone != private.Zone {
+			return config.Config{}, fmt.Errorf("public subnet %s is in zone %s and private subnet %s is in zone %s, they must be in the same zone", public.ID, public.Zone, private.ID, private.Zone)
+		}
+		if deployArgs.ZoneIsSet && deployArgs.Zone != private.Zone {
+			return config.Config{}, fmt.Errorf("--zone %s does not match zone %s of the subnets", deployArgs.Zone, private.Zone)
+		}
+		conf.AvailabilityZone = private.Zone
+		conf.Zones = []string{private.Zone}
+		conf.VPCID = deployArgs.VPCID
+		conf.PublicSubnetID = public.ID
+		conf.PrivateSubnetID = private.ID
+		conf.NetworkCIDR = network.CIDR
+		conf.PublicCIDR = public.CIDR
+		conf.PrivateCIDR = private.CIDR
+		conf.RDS1CIDR = rds[0].CIDR
+		conf.RDS2CIDR = rds[1].CIDR
+		for _, subnet := range rds {
+			conf.RDSSubnetIDs = append(conf.RDSSubnetIDs, subnet.ID)
+		}
+	case deployArgs.Network != "":
+		network, err := provider.FindNetwork(deployArgs.Network, deployArgs.PublicSubnetwork, deployArgs.PrivateSubnetwork)
+		if err != nil {
+			return config.Config{}, err
+		}
+		conf.Network = deployArgs.Network
+		conf.PublicSubnetwork = deployArgs.PublicSubnetwork
+		conf.PrivateSubnetwork = deployArgs.PrivateSubnetwork
+		conf.PublicCIDR = network.Subnets[0].CIDR
+		conf.PrivateCIDR = network.Subnets[1].CIDR
+	}
 	return conf, nil
 }
 
+// checkExistingNetworkUnchanged rejects giving an existing deployment another network than the one it is in,
+// since moving it would mean recreating it
+func checkExistingNetworkUnchanged(conf config.Config, deployArgs *deploy.Args) error {
+	given := []struct {
+		isSet    bool
+		flag     string
+		value    string
+		existing string
+	}{
+		{deployArgs.VPCIDIsSet, "--vpc-id", deployArgs.VPCID, conf.VPCID},
+		{deployArgs.PublicSubnetIDIsSet, "--public-subnet-id", deployArgs.PublicSubnetID, conf.PublicSubnetID},
+		{deployArgs.PrivateSubnetIDIsSet, "--private-subnet-id", deployArgs.PrivateSubnetID, conf.PrivateSubnetID},
+		{deployArgs.RDSSubnetIDsIsSet, "--rds-subnet-ids", strings.Join(deployArgs.RDSSubnetIDList(), ","), strings.Join(conf.RDSSubnetIDs, ",")},
+		{deployArgs.NetworkIsSet, "--network", deployArgs.Network, conf.Network},
+		{deployArgs.PublicSubnetworkIsSet, "--public-subnetwork", deployArgs.PublicSubnetwork, conf.PublicSubnetwork},
+		{deployArgs.PrivateSubnetworkIsSet, "--private-subnetwork", deployArgs.PrivateSubnetwork, conf.PrivateSubnetwork},
+	}
+	for _, g := range given {
+		if g.isSet && g.value != g.existing {
+			return fmt.Errorf("Existing deployment has %s %q and cannot be moved to %q", g.flag, g.existing, g.value)
+		}
+	}
+	return nil
+}
+
 func populateConfigWithDefaults(conf config.Config, provider iaas.Provider, passwordGenerator func(int) string, sshGenerator func() ([]byte, []byte, string, error)) (config.Config, error) {
 	const defaultPasswordLength = 20
 
@@ -113,14 +208,17 @@ func populateConfigWithDefaults(conf config.Config, provider iaas.Provider, pass
 }
 
 func populateConfigWithDefaultsOrProvidedArguments(conf config.Config, newConfigCreated bool, deployArgs *deploy.Args, provider iaas.Provider) (config.Config, bool, error) {
-	allow, err := parseAllowedIPsCIDRs(deployArgs.AllowIPs)
-	if err != nil {
-		return config.Config{}, false, err
-	}
+	// Keep the allowed IPs stored by an earlier deploy or `config set` unless they are given again
+	if newConfigCreated || deployArgs.AllowIPsIsSet || conf.AllowIPs == "" {
+		allow, err := parseAllowedIPsCIDRs(deployArgs.AllowIPs)
+		if err != nil {
+			return config.Config{}, false, err
+		}
 
-	conf, err = updateAllowedIPs(conf, allow)
-	if err != nil {
-		return config.Config{}, false, err
+		conf, err = updateAllowedIPs(conf, allow)
+		if err != nil {
+			return config.Config{}, false, err
+		}
 	}
 
 	if newConfigCreated {
@@ -170,10 +268,27 @@ func populateConfigWithDefaultsOrProvidedArguments(conf config.Config, newConfig
 		}
 	} else {
 		// Existing config, these values are mandatory but did not exist in older versions
-		if isMissingCIDRs(conf, provider) {
+		if config.MissingCIDRs(conf, provider.IAAS()) {
 			conf = populateConfigWithDefaultCIDRs(conf, provider)
 		}
 	}
+	if deployArgs.ZonesIsSet {
+		var err error
+		conf, err = addZones(conf, deployArgs.ZoneList(), provider.IAAS())
+		if err != nil {
+			return config.Config{}, false, err
+		}
+	}
+	if newConfigCreated || deployArgs.DBHighAvailabilityIsSet {
+		conf.DBHighAvailability = deployArgs.DBHighAvailability
+	}
+	if deployArgs.PrivateIsSet {
+		// Moving the director and web node between public and private subnets would mean recreating the deployment
+		if !newConfigCreated && deployArgs.Private != conf.Private {
+			return config.Config{}, false, fmt.Errorf("Existing deployment has private set to %t and cannot be changed", conf.Private)
+		}
+		conf.Private = deployArgs.Private
+	}
 
 	var isDomainUpdated bool
 	if newConfigCreated || deployArgs.DomainIsSet {
@@ -191,55 +306,118 @@ func populateConfigWithDefaultsOrProvidedArguments(conf config.Config, newConfig
 }
 
 func hasCIDRFlagsSet(deployArgs *deploy.Args, provider iaas.Provider) bool {
-	switch provider.IAAS() {
-	case iaas.AWS:
-		return deployArgs.NetworkCIDRIsSet && deployArgs.PublicCIDRIsSet && deployArgs.PrivateCIDRIsSet
-	case iaas.GCP:
-		return deployArgs.PublicCIDRIsSet && deployArgs.PrivateCIDRIsSet
-	default:
-		return false
-	}
-}
-
-func isMissingCIDRs(conf config.Config, provider iaas.Provider) bool {
-	switch provider.IAAS() {
-	case iaas.AWS:
-		return conf.NetworkCIDR == "" || conf.PrivateCIDR == "" || conf.PublicCIDR == "" || conf.RDS1CIDR == "" || conf.RDS2CIDR == ""
-	case iaas.GCP:
-		return conf.PrivateCIDR == "" || conf.PublicCIDR == ""
-	default:
+	r, err := iaas.Lookup(provider.IAAS())
+	if err != nil {
 		return false
 	}
+	return (r.Ranges.Network == "" || deployArgs.NetworkCIDRIsSet) && deployArgs.PublicCIDRIsSet && deployArgs.PrivateCIDRIsSet
 }
 
 func populateConfigWithDeployArgsCIDRs(conf config.Config, deployArgs *deploy.Args, provider iaas.Provider) config.Config {
-	switch provider.IAAS() {
-	case iaas.AWS:
+	r, _ := iaas.Lookup(provider.IAAS())
+	conf.PublicCIDR = deployArgs.PublicCIDR
+	conf.PrivateCIDR = deployArgs.PrivateCIDR
+	if r.Ranges.Network != "" {
 		conf.NetworkCIDR = deployArgs.NetworkCIDR
-		conf.PublicCIDR = deployArgs.PublicCIDR
-		conf.PrivateCIDR = deployArgs.PrivateCIDR
 		conf.RDS1CIDR = deployArgs.RDS1CIDR
 		conf.RDS2CIDR = deployArgs.RDS2CIDR
-	case iaas.GCP:
-		conf.PublicCIDR = deployArgs.PublicCIDR
-		conf.PrivateCIDR = deployArgs.PrivateCIDR
 	}
 	return conf
 }
 
-func populateConfigWithDefaultCIDRs(conf config.Config, provider iaas.Provider) config.Config {
-	switch provider.IAAS() {
-	case iaas.AWS:
-		conf.NetworkCIDR = "10.0.0.0/16"
-		conf.PrivateCIDR = "10.0.1.0/24"
-		conf.PublicCIDR = "10.0.0.0/24"
-		conf.RDS1CIDR = "10.0.4.0/24"
-		conf.RDS2CIDR = "10.0.5.0/24"
-	case iaas.GCP:
-		conf.PrivateCIDR = "10.0.1.0/24"
-		conf.PublicCIDR = "10.0.0.0/24"
+// addZones spreads the workers of conf across zones, which start with its availability zone. Zones can be added
+// to an existing deployment but not removed from it. On IAASs whose subnets are ranges of a network, each new zone
+// gets a private subnet in a free range of it
+func addZones(conf config.Config, zones []string, iaasName iaas.Name) (config.Config, error) {
+	existing := conf.Zones
+	if len(existing) == 0 {
+		existing = []string{conf.AvailabilityZone}
 	}
-	return conf
+	for _, zone := range existing {
+		if !containsString(zones, zone) {
+			return config.Config{}, fmt.Errorf("Existing deployment has workers in zone %s, which cannot be removed", zone)
+		}
+	}
+
+	conf.Zones = existing
+	for _, zone := range zones {
+		if containsString(conf.Zones, zone) {
+			continue
+		}
+		conf.Zones = append(conf.Zones, zone)
+		if r, _ := iaas.Lookup(iaasName); r.Ranges.Network == "" {
+			continue
+		}
+		subnet, err := freePrivateSubnet(conf)
+		if err != nil {
+			return config.Config{}, fmt.Errorf("error choosing the private subnet range for zone %s: [%v]", zone, err)
+		}
+		conf.ZonePrivateCIDRs = append(conf.ZonePrivateCIDRs, subnet)
+	}
+	return conf, nil
+}
+
+// freePrivateSubnet returns the first range of the network, the size of the private subnet, which no other subnet uses
+func freePrivateSubnet(conf config.Config) (string, error) {
+	_, network, err := net.ParseCIDR(conf.NetworkCIDR)
+	if err != nil {
+		return "", err
+	}
+	_, private, err := net.ParseCIDR(conf.PrivateCIDR)
+	if err != nil {
+		return "", err
+	}
+
+	var used []*net.IPNet
+	for _, r := range append([]string{conf.PublicCIDR, conf.PrivateCIDR, conf.RDS1CIDR, conf.RDS2CIDR}, conf.ZonePrivateCIDRs...) {
+		if r == "" {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(r)
+		if err != nil {
+			return "", err
+		}
+		used = append(used, ipNet)
+	}
+
+	networkBits, _ := network.Mask.Size()
+	subnetBits, _ := private.Mask.Size()
+	newBits := subnetBits - networkBits
+	if newBits < 0 {
+		return "", fmt.Errorf("private subnet %s is larger than the network %s", conf.PrivateCIDR, conf.NetworkCIDR)
+	}
+	for i := 0; i < 1<<uint(newBits); i++ {
+		subnet, err := cidr.Subnet(network, newBits, i)
+		if err != nil {
+			return "", err
+		}
+		if !overlapsAny(subnet, used) {
+			return subnet.String(), nil
+		}
+	}
+	return "", fmt.Errorf("no range of %s is free", conf.NetworkCIDR)
+}
+
+func overlapsAny(subnet *net.IPNet, others []*net.IPNet) bool {
+	for _, other := range others {
+		if subnet.Contains(other.IP) || other.Contains(subnet.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func populateConfigWithDefaultCIDRs(conf config.Config, provider iaas.Provider) config.Config {
+	return config.SetDefaultCIDRs(conf, provider.IAAS())
 }
 
 func updateAllowedIPs(c config.Config, ingressAddresses cidrBlocks) (config.Config, error) {
@@ -251,6 +429,16 @@ func updateAllowedIPs(c config.Config, ingressAddresses cidrBlocks) (config.Conf
 	return c, nil
 }
 
+// FormatAllowIPs parses a comma separated list of IPs and CIDR ranges, as given to --allow-ips,
+// into the form it is stored in the config
+func FormatAllowIPs(s string) (string, error) {
+	allow, err := parseAllowedIPsCIDRs(s)
+	if err != nil {
+		return "", err
+	}
+	return allow.String()
+}
+
 type cidrBlocks []*net.IPNet
 
 func parseAllowedIPsCIDRs(s string) (cidrBlocks, error) {
@@ -17,6 +17,7 @@ import (
 	"github.com/EngineerBetter/concourse-up/certs"
 	"github.com/EngineerBetter/concourse-up/config"
 	"github.com/EngineerBetter/concourse-up/fly"
+	"github.com/EngineerBetter/concourse-up/jumpbox"
 	"github.com/EngineerBetter/concourse-up/terraform"
 	"github.com/xenolf/lego/lego"
 	"gopkg.in/yaml.v2"
@@ -47,28 +48,70 @@ func stripVersion(tags []string) []string {
 	return output
 }
 
-// Deploy deploys a concourse instance
+// Deploy deploys a concourse instance, recording each phase in the config bucket so that a failed deploy can be resumed
 func (client *Client) Deploy() error {
-	conf, isDomainUpdated, err := client.getInitialConfig()
+	progress, err := client.newDeployProgress(client.deployArgs.Resume)
 	if err != nil {
-		return fmt.Errorf("error getting initial config before deploy: [%v]", err)
+		return err
+	}
+	if progress.resuming() {
+		fmt.Fprintf(client.stdout, "\nRESUMING DEPLOY FROM PHASE %s\n", progress.resumePhase())
 	}
 
-	r, err := client.checkPreTerraformConfigRequirements(conf, client.deployArgs.SelfUpdate)
-	if err != nil {
-		return err
+	if err = client.deploy(progress); err != nil {
+		return progress.fail(err)
+	}
+	return nil
+}
+
+func (client *Client) deploy(progress *deployProgress) error {
+	var conf config.Config
+	var err error
+
+	progress.start(PhaseConfig)
+	if progress.skip(PhaseConfig) {
+		conf, err = client.configClient.Load()
+		if err != nil {
+			return err
+		}
+	} else {
+		var isDomainUpdated bool
+		conf, isDomainUpdated, err = client.getInitialConfig()
+		if err != nil {
+			return fmt.Errorf("error getting initial config before deploy: [%v]", err)
+		}
+
+		r, err := client.checkPreTerraformConfigRequirements(conf, client.deployArgs.SelfUpdate)
+		if err != nil {
+			return err
+		}
+		conf = r.applyTo(conf)
+
+		// The lock table is created once, under the deployment lock, rather than whenever terraform is initialised.
+		// Terraform only locks the state with it once it is recorded in the config
+		if conf.TerraformLockTable == "" {
+			conf.TerraformLockTable, err = client.tfCLI.CreateLockTable(client.tfInputVarsFactory.NewInputVars(conf))
+			if err != nil {
+				return err
+			}
+		}
+
+		if err = client.configClient.Update(conf); err != nil {
+			return err
+		}
+		progress.checkpoint.DomainUpdated = isDomainUpdated
+		if err = progress.done(PhaseConfig); err != nil {
+			return err
+		}
 	}
-	conf.Region = r.Region
-	conf.SourceAccessIP = r.SourceAccessIP
-	conf.HostedZoneID = r.HostedZoneID
-	conf.HostedZoneRecordPrefix = r.HostedZoneRecordPrefix
-	conf.Domain = r.Domain
 
 	tfInputVars := client.tfInputVarsFactory.NewInputVars(conf)
 
-	err = client.tfCLI.Apply(tfInputVars)
-	if err != nil {
-		return err
+	progress.start(PhaseTerraform)
+	if !progress.skip(PhaseTerraform) {
+		if err = client.tfCLI.Apply(tfInputVars); err != nil {
+			return err
+		}
 	}
 
 	tfOutputs, err := client.tfCLI.BuildOutput(tfInputVars)
@@ -76,35 +119,52 @@ func (client *Client) Deploy() error {
 		return err
 	}
 
-	err = client.configClient.Update(conf)
-	if err != nil {
-		return err
+	if !progress.skip(PhaseTerraform) {
+		if err = progress.done(PhaseTerraform); err != nil {
+			return err
+		}
 	}
-	conf.Tags = stripVersion(conf.Tags)
-	conf.Tags = append([]string{fmt.Sprintf("concourse-up-version=%s", client.version)}, conf.Tags...)
+	conf = client.withVersion(conf)
 
-	conf.Version = client.version
-
-	cr, err := client.checkPreDeployConfigRequirements(client.acmeClientConstructor, isDomainUpdated, conf, tfOutputs)
+	// The addresses are stored so that info can show them without reading the terraform state
+	terraformInfo, err := newTerraformInfo(conf, tfOutputs)
 	if err != nil {
 		return err
 	}
+	conf.NatGatewayIP = terraformInfo.NatGatewayIP
+	conf.JumpboxPublicIP = terraformInfo.JumpboxPublicIP
 
-	conf.Domain = cr.Domain
-	conf.DirectorPublicIP = cr.DirectorPublicIP
-	conf.DirectorCACert = cr.DirectorCerts.DirectorCACert
-	conf.DirectorCert = cr.DirectorCerts.DirectorCert
-	conf.DirectorKey = cr.DirectorCerts.DirectorKey
-	conf.ConcourseCert = cr.Certs.ConcourseCert
-	conf.ConcourseKey = cr.Certs.ConcourseKey
-	conf.ConcourseUserProvidedCert = cr.Certs.ConcourseUserProvidedCert
-	conf.ConcourseCACert = cr.Certs.ConcourseCACert
+	progress.start(PhaseCerts)
+	if !progress.skip(PhaseCerts) {
+		cr, err := client.checkPreDeployConfigRequirements(client.acmeClientConstructor, progress.checkpoint.DomainUpdated, conf, tfOutputs)
+		if err != nil {
+			return err
+		}
+
+		conf.Domain = cr.Domain
+		conf.DirectorPublicIP = cr.DirectorPublicIP
+		conf.DirectorCACert = cr.DirectorCerts.DirectorCACert
+		conf.DirectorCert = cr.DirectorCerts.DirectorCert
+		conf.DirectorKey = cr.DirectorCerts.DirectorKey
+		conf.ConcourseCert = cr.Certs.ConcourseCert
+		conf.ConcourseKey = cr.Certs.ConcourseKey
+		conf.ConcourseUserProvidedCert = cr.Certs.ConcourseUserProvidedCert
+		conf.ConcourseCACert = cr.Certs.ConcourseCACert
+
+		// The certs are stored now so that a resumed deploy does not have to generate them again
+		if err = client.configClient.Update(conf); err != nil {
+			return err
+		}
+		if err = progress.done(PhaseCerts); err != nil {
+			return err
+		}
+	}
 
 	var bp BoshParams
 	if client.deployArgs.SelfUpdate {
-		bp, err = client.updateBoshAndPipeline(conf, tfOutputs)
+		bp, err = client.updateBoshAndPipeline(conf, tfOutputs, progress)
 	} else {
-		bp, err = client.deployBoshAndPipeline(conf, tfOutputs)
+		bp, err = client.deployBoshAndPipeline(conf, tfOutputs, progress)
 	}
 
 	conf.CredhubPassword = bp.CredhubPassword
@@ -118,6 +178,9 @@ func (client *Client) Deploy() error {
 	conf.DirectorUsername = bp.DirectorUsername
 	conf.DirectorPassword = bp.DirectorPassword
 	conf.DirectorCACert = bp.DirectorCACert
+	if err == nil {
+		conf.LastDeployed = time.Now().UTC().Format(time.RFC3339)
+	}
 
 	err1 := client.configClient.Update(conf)
 	if err == nil {
@@ -126,7 +189,7 @@ func (client *Client) Deploy() error {
 	return err
 }
 
-func (client *Client) deployBoshAndPipeline(c config.Config, tfOutputs terraform.Outputs) (BoshParams, error) {
+func (client *Client) deployBoshAndPipeline(c config.Config, tfOutputs terraform.Outputs, progress *deployProgress) (BoshParams, error) {
 	// When we are deploying for the first time rather than updating
 	// ensure that the pipeline is set _after_ the concourse is deployed
 
@@ -144,27 +207,53 @@ func (client *Client) deployBoshAndPipeline(c config.Config, tfOutputs terraform
 		DirectorCACert:           c.DirectorCACert,
 	}
 
-	bp, err := client.deployBosh(c, tfOutputs, false)
+	var err error
+	if progress.skip(bosh.PhaseDeploy) {
+		bp, err = client.boshParamsFromStoredCreds(c, bp)
+	} else {
+		progress.start(progress.boshPhase())
+		bp, err = client.deployBosh(c, tfOutputs, false, progress.resumeBoshPhase())
+		if err == nil {
+			err = progress.done(bosh.PhaseDeploy)
+		}
+	}
 	if err != nil {
 		return bp, err
 	}
 
-	flyClient, err := client.flyClientFactory(client.provider, fly.Credentials{
-		Target:   c.Deployment,
-		API:      fmt.Sprintf("https://%s", c.Domain),
-		Username: bp.ConcourseUsername,
-		Password: bp.ConcoursePassword,
-	},
-		client.stdout,
-		client.stderr,
-		client.versionFile,
-	)
+	progress.start(PhasePipeline)
+	pipelineEnv, setPipeline, err := client.pipelineEnv()
 	if err != nil {
 		return bp, err
 	}
-	defer flyClient.Cleanup()
+	if setPipeline {
+		tunnel, err := jumpbox.Open(c, tfOutputs)
+		if err != nil {
+			return bp, err
+		}
+		defer tunnel.Close()
+		flyClient, err := client.flyClientFactory(client.provider, fly.Credentials{
+			Target:      c.Deployment,
+			API:         fmt.Sprintf("https://%s", c.Domain),
+			Username:    bp.ConcourseUsername,
+			Password:    bp.ConcoursePassword,
+			Proxy:       tunnel.URL(),
+			PipelineEnv: pipelineEnv,
+		},
+			client.stdout,
+			client.stderr,
+			client.versionFile,
+		)
+		if err != nil {
+			return bp, err
+		}
+		defer flyClient.Cleanup()
 
-	if err := flyClient.SetDefaultPipeline(c, false); err != nil {
+		if err := flyClient.SetDefaultPipeline(c, false); err != nil {
+			return bp, err
+		}
+	}
+	if err := progress.done(PhasePipeline); err != nil {
 		return bp, err
 	}
 
@@ -176,7 +265,7 @@ func (client *Client) deployBoshAndPipeline(c config.Config, tfOutputs terraform
 	return bp, writeDeploySuccessMessage(c, client.stdout)
 }
 
-func (client *Client) updateBoshAndPipeline(c config.Config, tfOutputs terraform.Outputs) (BoshParams, error) {
+func (client *Client) updateBoshAndPipeline(c config.Config, tfOutputs terraform.Outputs, progress *deployProgress) (BoshParams, error) {
 	// If concourse is already running this is an update rather than a fresh deploy
 	// When updating we need to deploy the BOSH as the final step in order to
 	// Detach from the update, so the update job can exit
@@ -195,11 +284,23 @@ func (client *Client) updateBoshAndPipeline(c config.Config, tfOutputs terraform
 		DirectorCACert:           c.DirectorCACert,
 	}
 
+	progress.start(PhasePipeline)
+	tunnel, err := jumpbox.Open(c, tfOutputs)
+	if err != nil {
+		return bp, err
+	}
+	defer tunnel.Close()
+	pipelineEnv, setPipeline, err := client.pipelineEnv()
+	if err != nil {
+		return bp, err
+	}
 	flyClient, err := client.flyClientFactory(client.provider, fly.Credentials{
-		Target:   c.Deployment,
-		API:      fmt.Sprintf("https://%s", c.Domain),
-		Username: c.ConcourseUsername,
-		Password: c.ConcoursePassword,
+		Target:      c.Deployment,
+		API:         fmt.Sprintf("https://%s", c.Domain),
+		Username:    c.ConcourseUsername,
+		Password:    c.ConcoursePassword,
+		Proxy:       tunnel.URL(),
+		PipelineEnv: pipelineEnv,
 	},
 		client.stdout,
 		client.stderr,
@@ -219,15 +320,22 @@ func (client *Client) updateBoshAndPipeline(c config.Config, tfOutputs terraform
 		return bp, fmt.Errorf("In detach mode but it seems that concourse is not currently running")
 	}
 
-	// Allow a fly version discrepancy since we might be targetting an older Concourse
-	if err = flyClient.SetDefaultPipeline(c, true); err != nil {
-		return bp, err
+	if setPipeline {
+		// Allow a fly version discrepancy since we might be targetting an older Concourse
+		if err = flyClient.SetDefaultPipeline(c, true); err != nil {
+			return bp, err
+		}
 	}
 
-	bp, err = client.deployBosh(c, tfOutputs, true)
+	progress.start(bosh.PhaseCreateEnv)
+	bp, err = client.deployBosh(c, tfOutputs, true, "")
 	if err != nil {
 		return bp, err
 	}
+	// The pipeline was set before the director deploy, so the deploy is complete once it has started
+	if err = progress.done(PhasePipeline); err != nil {
+		return bp, err
+	}
 
 	_, err = client.stdout.Write([]byte("\nUPGRADE RUNNING IN BACKGROUND\n\n"))
 
@@ -243,6 +351,34 @@ type TerraformRequirements struct {
 	Domain                 string
 }
 
+func (r TerraformRequirements) applyTo(conf config.Config) config.Config {
+	conf.Region = r.Region
+	conf.SourceAccessIP = r.SourceAccessIP
+	conf.HostedZoneID = r.HostedZoneID
+	conf.HostedZoneRecordPrefix = r.HostedZoneRecordPrefix
+	conf.Domain = r.Domain
+	return conf
+}
+
+// withVersion stamps the config and its tags with the version of concourse-up being run
+func (client *Client) withVersion(conf config.Config) config.Config {
+	conf.Tags = stripVersion(conf.Tags)
+	conf.Tags = append([]string{fmt.Sprintf("concourse-up-version=%s", client.version)}, conf.Tags...)
+	conf.Version = client.version
+	return conf
+}
+
+// pipelineEnv returns the environment the self-update pipeline needs to read the config. It returns false, after
+// warning on stderr, when the pipeline cannot reach the config backend, as it would deploy from an empty config
+func (client *Client) pipelineEnv() (map[string]string, bool, error) {
+	env, err := client.configClient.PipelineEnv()
+	if err == config.ErrBackendUnreachable {
+		fmt.Fprintf(client.stderr, "WARNING: not setting the self-update pipeline, as %s\n", err)
+		return nil, false, nil
+	}
+	return env, err == nil, err
+}
+
 func (client *Client) checkPreTerraformConfigRequirements(conf config.Config, selfUpdate bool) (TerraformRequirements, error) {
 	r := TerraformRequirements{
 		Region:                 conf.Region,
@@ -435,7 +571,8 @@ func (client *Client) ensureConcourseCerts(c func(u *certs.User) (*lego.Client,
 	return certs, nil
 }
 
-func (client *Client) deployBosh(config config.Config, tfOutputs terraform.Outputs, detach bool) (BoshParams, error) {
+// deployBosh deploys the director and Concourse, starting from the director deploy phase from unless it is empty
+func (client *Client) deployBosh(config config.Config, tfOutputs terraform.Outputs, detach bool, from string) (BoshParams, error) {
 	bp := BoshParams{
 		CredhubPassword:          config.CredhubPassword,
 		CredhubAdminClientSecret: config.CredhubAdminClientSecret,
@@ -465,7 +602,11 @@ func (client *Client) deployBosh(config config.Config, tfOutputs terraform.Outpu
 		return bp, err
 	}
 
-	boshStateBytes, boshCredsBytes, err = boshClient.Deploy(boshStateBytes, boshCredsBytes, detach)
+	if from == "" {
+		boshStateBytes, boshCredsBytes, err = boshClient.Deploy(boshStateBytes, boshCredsBytes, detach)
+	} else {
+		boshStateBytes, boshCredsBytes, err = boshClient.DeployFrom(boshStateBytes, boshCredsBytes, detach, from)
+	}
 	err1 := client.configClient.StoreAsset(bosh.StateFilename, boshStateBytes)
 	if err == nil {
 		err = err1
@@ -478,6 +619,19 @@ func (client *Client) deployBosh(config config.Config, tfOutputs terraform.Outpu
 		return bp, err
 	}
 
+	return applyDirectorCreds(bp, config.Domain, boshCredsBytes)
+}
+
+// boshParamsFromStoredCreds reads the params a director deploy would produce from the stored director creds
+func (client *Client) boshParamsFromStoredCreds(config config.Config, bp BoshParams) (BoshParams, error) {
+	boshCredsBytes, err := loadDirectorCreds(client.configClient)
+	if err != nil {
+		return bp, err
+	}
+	return applyDirectorCreds(bp, config.Domain, boshCredsBytes)
+}
+
+func applyDirectorCreds(bp BoshParams, domain string, boshCredsBytes []byte) (BoshParams, error) {
 	var cc struct {
 		CredhubPassword          string `yaml:"credhub_cli_password"`
 		CredhubAdminClientSecret string `yaml:"credhub_admin_client_secret"`
@@ -487,7 +641,7 @@ func (client *Client) deployBosh(config config.Config, tfOutputs terraform.Outpu
 		AtcPassword string `yaml:"atc_password"`
 	}
 
-	err = yaml.Unmarshal(boshCredsBytes, &cc)
+	err := yaml.Unmarshal(boshCredsBytes, &cc)
 	if err != nil {
 		return bp, err
 	}
@@ -495,7 +649,7 @@ func (client *Client) deployBosh(config config.Config, tfOutputs terraform.Outpu
 	bp.CredhubPassword = cc.CredhubPassword
 	bp.CredhubAdminClientSecret = cc.CredhubAdminClientSecret
 	bp.CredhubCACert = cc.InternalTLS.CA
-	bp.CredhubURL = fmt.Sprintf("https://%s:8844/", config.Domain)
+	bp.CredhubURL = fmt.Sprintf("https://%s:8844/", domain)
 	bp.CredhubUsername = "credhub-cli"
 	bp.ConcourseUsername = "admin"
 	if len(cc.AtcPassword) > 0 {
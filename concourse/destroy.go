@@ -3,8 +3,11 @@ package concourse
 import (
 	"fmt"
 	"io"
+	"os"
 
+	"github.com/EngineerBetter/concourse-up/config"
 	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/terraform"
 )
 
 // Destroy destroys a concourse instance
@@ -17,34 +20,15 @@ func (client *Client) Destroy() error {
 
 	tfInputVars := client.tfInputVarsFactory.NewInputVars(conf)
 
-	var volumesToDelete []string
-
-	switch client.provider.IAAS() {
-
-	case iaas.AWS: // nolint
-		tfOutputs, err1 := client.tfCLI.BuildOutput(tfInputVars)
-		if err1 != nil {
-			return err1
-		}
-		vpcID, err2 := tfOutputs.Get("VPCID")
-		if err2 != nil {
-			return err2
-		}
-		volumesToDelete, err1 = client.provider.DeleteVMsInVPC(vpcID)
-		if err1 != nil {
-			return err1
-		}
-
-	case iaas.GCP: // nolint
-		project, err1 := client.provider.Attr("project")
-		if err1 != nil {
-			return err1
-		}
-		zone := client.provider.Zone("")
-		err1 = client.provider.DeleteVMsInDeployment(zone, project, conf.Deployment)
-		if err1 != nil {
-			return err1
-		}
+	i, err := lookup(client.provider.IAAS())
+	if err != nil {
+		return err
+	}
+	deleteRemains, err := i.DeleteVMs(client.provider, conf, func() (terraform.Outputs, error) {
+		return client.readOutputs(conf, tfInputVars)
+	})
+	if err != nil {
+		return err
 	}
 
 	err = client.tfCLI.Destroy(tfInputVars)
@@ -52,18 +36,25 @@ func (client *Client) Destroy() error {
 		return err
 	}
 
-	if client.provider.IAAS() == iaas.AWS { // nolint
-		if len(volumesToDelete) > 0 {
-			fmt.Printf("Scheduling to delete %v volumes\n", len(volumesToDelete))
-		}
-		if err1 := client.provider.DeleteVolumes(volumesToDelete, iaas.DeleteVolume); err1 != nil {
-			return err1
+	if deleteRemains != nil {
+		if err = deleteRemains(); err != nil {
+			return err
 		}
 	}
 
 	if err = client.configClient.DeleteAll(conf); err != nil {
 		return err
 	}
+	if err = client.provider.DeleteLockTable(terraform.LockTableName(conf.ConfigBucket)); err != nil {
+		return err
+	}
+
+	// Remove the key written by info --env, it no longer grants access to anything
+	if keyPath, err := gatewayKeyPath(conf); err == nil {
+		if err = os.Remove(keyPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
 
 	return writeDestroySuccessMessage(client.stdout)
 }
@@ -72,3 +63,13 @@ func writeDestroySuccessMessage(stdout io.Writer) error {
 
 	return err
 }
+
+// deleteVMsInDeployment is for providers which find the VMs by the resource group or network of the deployment,
+// so need no zone or project
+func deleteVMsInDeployment(provider iaas.Provider, conf config.Config, outputs func() (terraform.Outputs, error)) (func() error, error) {
+	teardown, ok := provider.(iaas.DeploymentTeardown)
+	if !ok {
+		return nil, fmt.Errorf("cannot delete the VMs of a deployment on %s", provider.IAAS())
+	}
+	return nil, teardown.DeleteVMsInDeployment("", "", conf.Deployment)
+}
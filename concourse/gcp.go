@@ -0,0 +1,89 @@
+package concourse
+
+import (
+	"fmt"
+
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/terraform"
+)
+
+var gcpVersionFile = MustAsset("../../concourse-up-ops/director-versions-gcp.json")
+
+func init() {
+	Register(iaas.GCP, IAAS{
+		VersionFile:           gcpVersionFile,
+		NewTFInputVarsFactory: newGCPInputVarsFactory,
+		DeleteVMs:             deleteGCPVMs,
+	})
+}
+
+func newGCPInputVarsFactory(provider iaas.Provider) (TFInputVarsFactory, error) {
+	credentialsPath, err := provider.Attr("credentials_path")
+	if err != nil {
+		return &GCPInputVarsFactory{}, fmt.Errorf("Error finding attribute [credentials_path]: [%v]", err)
+	}
+
+	project, err := provider.Attr("project")
+	if err != nil {
+		return &GCPInputVarsFactory{}, fmt.Errorf("Error finding attribute [project]: [%v]", err)
+	}
+
+	return &GCPInputVarsFactory{
+		credentialsPath: credentialsPath,
+		project:         project,
+		region:          provider.Region(),
+		zone:            provider.Zone(""),
+	}, nil
+}
+
+type GCPInputVarsFactory struct {
+	credentialsPath string
+	project         string
+	region          string
+	zone            string
+}
+
+func (f *GCPInputVarsFactory) NewInputVars(c config.Config) terraform.InputVars {
+	return &terraform.GCPInputVars{
+		AllowIPs:           c.AllowIPs,
+		ConfigBucket:       c.ConfigBucket,
+		DBHighAvailability: c.DBHighAvailability,
+		DBName:             c.RDSDefaultDatabaseName,
+		DBPassword:         c.RDSPassword,
+		DBTier:             c.RDSInstanceClass,
+		DBUsername:         c.RDSUsername,
+		Deployment:         c.Deployment,
+		DNSManagedZoneName: c.HostedZoneID,
+		DNSRecordSetPrefix: c.HostedZoneRecordPrefix,
+		ExternalIP:         c.SourceAccessIP,
+		GCPCredentialsJSON: f.credentialsPath,
+		Namespace:          c.Namespace,
+		Project:            f.project,
+		Region:             f.region,
+		Tags:               "",
+		Zone:               f.zone,
+		PublicCIDR:         c.PublicCIDR,
+		PrivateCIDR:        c.PrivateCIDR,
+		Network:            c.Network,
+		PublicSubnetwork:   c.PublicSubnetwork,
+		PrivateSubnetwork:  c.PrivateSubnetwork,
+	}
+}
+
+func deleteGCPVMs(provider iaas.Provider, conf config.Config, outputs func() (terraform.Outputs, error)) (func() error, error) {
+	teardown, ok := provider.(iaas.LabelTeardown)
+	if !ok {
+		return nil, fmt.Errorf("cannot delete the VMs of a deployment on %s", provider.IAAS())
+	}
+	project, err := provider.Attr("project")
+	if err != nil {
+		return nil, err
+	}
+	zone := provider.Zone("")
+	if conf.Network != "" {
+		// The VMs of an existing network are not all the deployment's, but BOSH labels each of its VMs with the project
+		return nil, teardown.DeleteVMsWithLabel(zone, project, "concourse-up-project", conf.Project)
+	}
+	return nil, teardown.DeleteVMsInDeployment(zone, project, conf.Deployment)
+}
@@ -0,0 +1,391 @@
+package concourse
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/fly"
+	"github.com/EngineerBetter/concourse-up/jumpbox"
+	"github.com/EngineerBetter/concourse-up/terraform"
+	"github.com/EngineerBetter/concourse-up/util/yaml"
+	"github.com/fatih/color"
+	yamlv2 "gopkg.in/yaml.v2"
+)
+
+// CheckStatus is the outcome of a single health check
+type CheckStatus string
+
+// Possible outcomes of a health check, in order of severity
+const (
+	CheckPass CheckStatus = "pass"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+)
+
+const (
+	certWarnThreshold = 28 * 24 * time.Hour
+	certFailThreshold = 7 * 24 * time.Hour
+)
+
+// HealthCheck is the result of checking one layer of a deployment
+type HealthCheck struct {
+	Name    string      `json:"name"`
+	Status  CheckStatus `json:"status"`
+	Message string      `json:"message"`
+}
+
+// Health is the overall result of checking a deployment
+type Health struct {
+	Status CheckStatus   `json:"status"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+func (h *Health) add(name string, status CheckStatus, format string, a ...interface{}) {
+	h.Checks = append(h.Checks, HealthCheck{
+		Name:    name,
+		Status:  status,
+		Message: fmt.Sprintf(format, a...),
+	})
+	if severity(status) > severity(h.Status) {
+		h.Status = status
+	}
+}
+
+func severity(status CheckStatus) int {
+	switch status {
+	case CheckWarn:
+		return 1
+	case CheckFail:
+		return 2
+	}
+	return 0
+}
+
+// ExitCode returns 0, 1 or 2 for an overall pass, warn or fail, following the Nagios plugin convention
+func (h *Health) ExitCode() int {
+	return severity(h.Status)
+}
+
+const healthTemplate = `{{range .Checks}}{{.Status | status}} {{.Name}}: {{.Message}}
+{{end}}
+Overall: {{.Status | status}}
+`
+
+func (h *Health) String() string {
+	t := template.Must(template.New("health").Funcs(template.FuncMap{
+		"status": func(status CheckStatus) string {
+			label := fmt.Sprintf("[%s]", strings.ToUpper(string(status)))
+			switch status {
+			case CheckPass:
+				return color.New(color.FgGreen, color.Bold).Sprint(label)
+			case CheckWarn:
+				return color.New(color.FgYellow, color.Bold).Sprint(label)
+			}
+			return color.New(color.FgRed, color.Bold).Sprint(label)
+		},
+	}).Parse(healthTemplate))
+	var buf bytes.Buffer
+	err := t.Execute(&buf, h)
+	if err != nil {
+		panic(err)
+	}
+	return buf.String()
+}
+
+// Health checks each layer of the deployment in turn, from the terraform outputs up to the Concourse workers
+func (client *Client) Health() (*Health, error) {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	health := &Health{Status: CheckPass}
+
+	tfOutputs, err := client.readOutputs(conf, client.tfInputVarsFactory.NewInputVars(conf))
+	if err != nil {
+		health.add("terraform", CheckFail, "terraform outputs are not valid: %s", err)
+		return health, nil
+	}
+	health.add("terraform", CheckPass, "terraform outputs are valid")
+
+	tunnel, err := jumpbox.Open(conf, tfOutputs)
+	if err != nil {
+		health.add("jumpbox", CheckFail, "could not open a tunnel through the jumpbox: %s", err)
+		return health, nil
+	}
+	defer tunnel.Close()
+
+	httpClient := newHealthHTTPClient(conf, tunnel)
+
+	if client.checkDirector(health, conf, tfOutputs) {
+		client.checkInstances(health, conf, tfOutputs)
+	}
+	client.checkATC(health, httpClient, conf, tfOutputs)
+	client.checkWorkers(health, conf, tunnel)
+	checkCredhub(health, httpClient, conf)
+	client.checkCerts(health, conf)
+
+	return health, nil
+}
+
+// checkDirector reports whether the director could be reached, as the instance check depends on it
+func (client *Client) checkDirector(health *Health, conf config.Config, tfOutputs terraform.Outputs) bool {
+	const name = "director"
+
+	userIP, err := client.ipChecker()
+	if err != nil {
+		health.add(name, CheckFail, "could not determine your public IP: %s", err)
+		return false
+	}
+	securityGroupID, err := accessSecurityGroupID(conf, tfOutputs)
+	if err != nil {
+		health.add(name, CheckFail, "%s", err)
+		return false
+	}
+	whitelisted, err := client.provider.CheckForWhitelistedIP(userIP, securityGroupID)
+	if err != nil {
+		health.add(name, CheckFail, "could not check the director firewall: %s", err)
+		return false
+	}
+	if !whitelisted {
+		health.add(name, CheckFail, "your IP %s is not allowed through the %s-%s firewall", userIP, conf.Deployment, accessFirewall(conf))
+		return false
+	}
+
+	boshClient, err := client.buildBoshClient(conf, tfOutputs)
+	if err != nil {
+		health.add(name, CheckFail, "%s", err)
+		return false
+	}
+	defer boshClient.Cleanup()
+
+	lockBytes, err := boshClient.Locks()
+	if err != nil {
+		health.add(name, CheckFail, "director is not reachable: %s", err)
+		return false
+	}
+	var tables Tables
+	if err = json.Unmarshal(lockBytes, &tables); err != nil {
+		health.add(name, CheckFail, "could not parse director locks: %s", err)
+		return true
+	}
+	for _, table := range tables.Tables {
+		if table.Content == "locks" && len(table.Rows) != 0 {
+			health.add(name, CheckWarn, "director is reachable but holds %d lock(s)", len(table.Rows))
+			return true
+		}
+	}
+	health.add(name, CheckPass, "director is reachable and holds no locks")
+	return true
+}
+
+func (client *Client) checkInstances(health *Health, conf config.Config, tfOutputs terraform.Outputs) {
+	const name = "instances"
+
+	boshClient, err := client.buildBoshClient(conf, tfOutputs)
+	if err != nil {
+		health.add(name, CheckFail, "%s", err)
+		return
+	}
+	defer boshClient.Cleanup()
+
+	instances, err := boshClient.Instances()
+	if err != nil {
+		health.add(name, CheckFail, "could not list instances: %s", err)
+		return
+	}
+	if len(instances) == 0 {
+		health.add(name, CheckFail, "no instances are deployed")
+		return
+	}
+	var notRunning []string
+	for _, instance := range instances {
+		if instance.State != "running" {
+			notRunning = append(notRunning, fmt.Sprintf("%s is %s", instance.Name, instance.State))
+		}
+	}
+	if len(notRunning) != 0 {
+		health.add(name, CheckFail, "%s", strings.Join(notRunning, ", "))
+		return
+	}
+	health.add(name, CheckPass, "all %d instances are running", len(instances))
+}
+
+func (client *Client) checkATC(health *Health, httpClient *http.Client, conf config.Config, tfOutputs terraform.Outputs) {
+	const name = "atc"
+
+	domain := conf.Domain
+	if domain == "" {
+		var err error
+		domain, err = tfOutputs.Get("ATCPublicIP")
+		if err != nil {
+			health.add(name, CheckFail, "%s", err)
+			return
+		}
+	}
+
+	resp, err := httpClient.Get(fmt.Sprintf("https://%s/api/v1/info", domain))
+	if err != nil {
+		health.add(name, CheckFail, "ATC is not reachable: %s", err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		health.add(name, CheckFail, "ATC responded with %s", resp.Status)
+		return
+	}
+	var info struct {
+		Version string `json:"version"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		health.add(name, CheckFail, "could not parse the ATC info: %s", err)
+		return
+	}
+
+	bundled, err := bosh.ConcourseVersion(client.provider)
+	if err != nil {
+		health.add(name, CheckWarn, "ATC is running %s but the bundled version is unknown: %s", info.Version, err)
+		return
+	}
+	if info.Version != bundled {
+		health.add(name, CheckWarn, "ATC is running %s but this concourse-up deploys %s", info.Version, bundled)
+		return
+	}
+	health.add(name, CheckPass, "ATC is running %s", info.Version)
+}
+
+func (client *Client) checkWorkers(health *Health, conf config.Config, tunnel *jumpbox.Tunnel) {
+	const name = "workers"
+
+	flyClient, err := client.flyClientFactory(client.provider, fly.Credentials{
+		Target:   conf.Deployment,
+		API:      fmt.Sprintf("https://%s", conf.Domain),
+		Username: conf.ConcourseUsername,
+		Password: conf.ConcoursePassword,
+		Proxy:    tunnel.URL(),
+	},
+		client.stdout,
+		client.stderr,
+		client.versionFile,
+	)
+	if err != nil {
+		health.add(name, CheckFail, "%s", err)
+		return
+	}
+	defer flyClient.Cleanup()
+
+	workers, err := flyClient.Workers()
+	if err != nil {
+		health.add(name, CheckFail, "could not list workers: %s", err)
+		return
+	}
+
+	var running int
+	var unhealthy []string
+	for _, worker := range workers {
+		if worker.State == "running" {
+			running++
+		} else {
+			unhealthy = append(unhealthy, fmt.Sprintf("%s is %s", worker.Name, worker.State))
+		}
+	}
+	switch {
+	case running == 0:
+		health.add(name, CheckFail, "no workers are running")
+	case len(unhealthy) != 0:
+		health.add(name, CheckWarn, "%d worker(s) running, %s", running, strings.Join(unhealthy, ", "))
+	case running < conf.ConcourseWorkerCount:
+		health.add(name, CheckWarn, "%d of %d workers are registered", running, conf.ConcourseWorkerCount)
+	default:
+		health.add(name, CheckPass, "%d worker(s) running", running)
+	}
+}
+
+func checkCredhub(health *Health, httpClient *http.Client, conf config.Config) {
+	const name = "credhub"
+
+	if conf.CredhubURL == "" {
+		health.add(name, CheckFail, "no CredHub URL is configured")
+		return
+	}
+	resp, err := httpClient.Get(strings.TrimSuffix(conf.CredhubURL, "/") + "/info")
+	if err != nil {
+		health.add(name, CheckFail, "CredHub is not reachable: %s", err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		health.add(name, CheckFail, "CredHub responded with %s", resp.Status)
+		return
+	}
+	health.add(name, CheckPass, "CredHub is responding")
+}
+
+func (client *Client) checkCerts(health *Health, conf config.Config) {
+	checkCertExpiry(health, "concourse-cert", conf.ConcourseCert)
+	checkCertExpiry(health, "director-cert", conf.DirectorCert)
+
+	directorCreds, err := loadDirectorCreds(client.configClient)
+	if err != nil {
+		health.add("nats-cert", CheckFail, "could not load director credentials: %s", err)
+		return
+	}
+	natsCAYAML, err := yaml.Path(directorCreds, "nats_server_tls/ca")
+	if err != nil {
+		health.add("nats-cert", CheckFail, "could not find the NATS CA: %s", err)
+		return
+	}
+	// yaml.Path returns the value still encoded as YAML
+	var natsCA string
+	if err = yamlv2.Unmarshal([]byte(natsCAYAML), &natsCA); err != nil {
+		health.add("nats-cert", CheckFail, "could not parse the NATS CA: %s", err)
+		return
+	}
+	checkCertExpiry(health, "nats-cert", natsCA)
+}
+
+func checkCertExpiry(health *Health, name, cert string) {
+	expiry := timeTillExpiry(cert)
+	days := int(expiry.Hours() / 24)
+	switch {
+	case expiry <= 0:
+		health.add(name, CheckFail, "certificate is missing, invalid or has expired")
+	case expiry < certFailThreshold:
+		health.add(name, CheckFail, "certificate expires in %d day(s)", days)
+	case expiry < certWarnThreshold:
+		health.add(name, CheckWarn, "certificate expires in %d days", days)
+	default:
+		health.add(name, CheckPass, "certificate expires in %d days", days)
+	}
+}
+
+// newHealthHTTPClient trusts the system roots plus the CAs concourse-up generated for the deployment,
+// and connects through tunnel when it is not nil
+func newHealthHTTPClient(conf config.Config, tunnel *jumpbox.Tunnel) *http.Client {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	for _, ca := range []string{conf.ConcourseCACert, conf.CredhubCACert, conf.DirectorCACert} {
+		pool.AppendCertsFromPEM([]byte(ca))
+	}
+	transport := &http.Transport{
+		TLSClientConfig: &tls.Config{RootCAs: pool},
+	}
+	if tunnel != nil {
+		transport.Dial = tunnel.Dial
+	}
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: transport,
+	}
+}
@@ -7,12 +7,15 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"text/template"
 
 	"github.com/EngineerBetter/concourse-up/bosh"
 	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/jumpbox"
+	"github.com/EngineerBetter/concourse-up/terraform"
 	"github.com/EngineerBetter/concourse-up/util/yaml"
 	"github.com/fatih/color"
 )
@@ -24,17 +27,36 @@ type Info struct {
 	Instances   []bosh.Instance `json:"instances"`
 	CertExpiry  string          `json:"cert_expiry"`
 	GatewayUser string
+	// LastFailedPhase is the phase in which the last deploy failed, if it did not complete
+	LastFailedPhase string `json:"last_failed_phase,omitempty"`
+	// Secrets are the passwords and keys of the deployment. They are only output when set,
+	// otherwise the text output shows them redacted and the json and env outputs leave them out
+	Secrets *config.Secrets `json:"secrets,omitempty"`
+	// CredhubOnly limits the output to what is needed to log into credhub
+	CredhubOnly bool `json:"-"`
+	// WithoutSecrets is set when the info was built without reading the secrets, so it has no instances or cert expiry
+	WithoutSecrets bool `json:"-"`
+}
+
+// CredhubInfo is the part of the info needed to log into credhub
+type CredhubInfo struct {
+	URL               string `json:"url"`
+	CACert            string `json:"ca_cert"`
+	Username          string `json:"username"`
+	Password          string `json:"password,omitempty"`
+	AdminClientSecret string `json:"admin_client_secret,omitempty"`
 }
 
 // TerraformInfo represents the terraform output fields needed for the info templates
 type TerraformInfo struct {
 	DirectorPublicIP string
 	NatGatewayIP     string
+	// JumpboxPublicIP is only set for private deployments, whose director is reached through the jumpbox
+	JumpboxPublicIP string
 }
 
-// FetchInfo fetches and builds the info
+// FetchInfo fetches and builds the info, including the instances, which needs the secrets of the deployment
 func (client *Client) FetchInfo() (*Info, error) {
-	var gatewayUser string
 	conf, err := client.configClient.Load()
 	if err != nil {
 		return nil, err
@@ -71,49 +93,37 @@ func (client *Client) FetchInfo() (*Info, error) {
 
 	tfInputVars := client.tfInputVarsFactory.NewInputVars(conf)
 
-	switch client.provider.IAAS() {
-	case iaas.AWS: // nolint
-		gatewayUser = "vcap"
-	case iaas.GCP: // nolint
-		gatewayUser = "jumpbox"
-	}
-
-	tfOutputs, err := client.tfCLI.BuildOutput(tfInputVars)
-	if err != nil {
-		return nil, err
-	}
-
-	directorPublicIP, err := tfOutputs.Get("DirectorPublicIP")
+	tfOutputs, err := client.readOutputs(conf, tfInputVars)
 	if err != nil {
 		return nil, err
 	}
 
-	natGatewayIP, err := tfOutputs.Get("NatGatewayIP")
+	terraformInfo, err := newTerraformInfo(conf, tfOutputs)
 	if err != nil {
 		return nil, err
 	}
 
-	terraformInfo := TerraformInfo{
-		DirectorPublicIP: directorPublicIP,
-		NatGatewayIP:     natGatewayIP,
-	}
-
 	userIP, err1 := client.ipChecker()
 	if err1 != nil {
 		return nil, err1
 	}
 
-	directorSecurityGroupID, err1 := tfOutputs.Get("DirectorSecurityGroupID")
+	securityGroupID, err1 := accessSecurityGroupID(conf, tfOutputs)
 	if err1 != nil {
 		return nil, err1
 	}
-	whitelisted, err1 := client.provider.CheckForWhitelistedIP(userIP, directorSecurityGroupID)
+	whitelisted, err1 := client.provider.CheckForWhitelistedIP(userIP, securityGroupID)
 	if err1 != nil {
 		return nil, err1
 	}
 
 	if !whitelisted {
-		err1 = fmt.Errorf("Do you need to add your IP %s to the %s-director security group/source range entry for director firewall (for ports 22, 6868, and 25555)?", userIP, conf.Deployment)
+		ports := "ports 22, 6868, and 25555"
+		if conf.Private {
+			ports = "port 22"
+		}
+		firewall := accessFirewall(conf)
+		err1 = fmt.Errorf("Do you need to add your IP %s to the %s-%s security group/source range entry for %s firewall (for %s)?", userIP, conf.Deployment, firewall, firewall, ports)
 		return nil, err1
 	}
 
@@ -128,15 +138,84 @@ func (client *Client) FetchInfo() (*Info, error) {
 		return nil, fmt.Errorf("Error getting BOSH instances: %s", err)
 	}
 
+	lastFailedPhase, err := client.lastFailedPhase()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Info{
+		Terraform:       terraformInfo,
+		Config:          conf,
+		Instances:       instances,
+		GatewayUser:     client.gatewayUser(conf),
+		CertExpiry:      certExpiry,
+		LastFailedPhase: lastFailedPhase,
+	}, nil
+}
+
+// FetchInfoWithoutSecrets builds the info from the config alone. It reads neither the secrets nor the terraform
+// state, which holds the database password, so it has no instances or cert expiry, and the addresses are those
+// stored by the last deploy
+func (client *Client) FetchInfoWithoutSecrets() (*Info, error) {
+	conf, err := client.configClient.LoadWithoutSecrets()
+	if err != nil {
+		return nil, err
+	}
+
+	lastFailedPhase, err := client.lastFailedPhase()
+	if err != nil {
+		return nil, err
+	}
+
 	return &Info{
-		Terraform:   terraformInfo,
-		Config:      conf,
-		Instances:   instances,
-		GatewayUser: gatewayUser,
-		CertExpiry:  certExpiry,
+		Terraform: TerraformInfo{
+			DirectorPublicIP: conf.DirectorPublicIP,
+			NatGatewayIP:     conf.NatGatewayIP,
+			JumpboxPublicIP:  conf.JumpboxPublicIP,
+		},
+		Config:          conf,
+		GatewayUser:     client.gatewayUser(conf),
+		LastFailedPhase: lastFailedPhase,
+		WithoutSecrets:  true,
 	}, nil
 }
 
+// newTerraformInfo reads the addresses the info shows from the terraform outputs
+func newTerraformInfo(conf config.Config, tfOutputs terraform.Outputs) (TerraformInfo, error) {
+	var info TerraformInfo
+	var err error
+	if info.DirectorPublicIP, err = tfOutputs.Get("DirectorPublicIP"); err != nil {
+		return TerraformInfo{}, err
+	}
+	if info.NatGatewayIP, err = tfOutputs.Get("NatGatewayIP"); err != nil {
+		return TerraformInfo{}, err
+	}
+	if conf.Private {
+		if info.JumpboxPublicIP, err = tfOutputs.Get("JumpboxPublicIP"); err != nil {
+			return TerraformInfo{}, err
+		}
+	}
+	return info, nil
+}
+
+func (client *Client) gatewayUser(conf config.Config) string {
+	if conf.Private {
+		return jumpbox.User
+	}
+	if r, err := iaas.Lookup(client.provider.IAAS()); err == nil {
+		return r.GatewayUser
+	}
+	return ""
+}
+
+func (client *Client) lastFailedPhase() (string, error) {
+	checkpoint, err := client.retrieveDeployCheckpoint()
+	if err != nil || checkpoint == nil {
+		return "", err
+	}
+	return checkpoint.FailedPhase, nil
+}
+
 const infoTemplate = `Deployment:
 	Namespace: {{.Config.Namespace}}
 	IAAS:      {{.Config.IAAS}}
@@ -148,93 +227,168 @@ Workers:
 	Outbound Public IP: {{.Terraform.NatGatewayIP}}
 
 Instances:
-{{range .Instances}}
+{{if .WithoutSecrets}}
+	Listing the instances needs the director password, run info with --show-secrets to see them
+{{else}}{{range .Instances}}
 	{{.Name}} {{.IP | replace "\n" ","}} {{.State}}
-{{end}}
+{{end}}{{end}}
 
 Concourse credentials:
 	username: {{.Config.ConcourseUsername}}
-	password: {{.Config.ConcoursePassword}}
+	password: {{.Secrets.ConcoursePassword}}
 	URL:      https://{{.Config.Domain}}
 
-Credhub credentials:
-	username: {{.Config.CredhubUsername}}
-	password: {{.Config.CredhubPassword}}
-	URL:      {{.Config.CredhubURL}}
-	CA Cert:
-		{{ .Config.CredhubCACert | replace "\n" "\n\t\t"}}
-
+{{template "credhub" .}}
 Grafana credentials:
 	username: {{.Config.ConcourseUsername}}
-	password: {{.Config.ConcoursePassword}}
+	password: {{.Secrets.ConcoursePassword}}
 	URL:      https://{{.Config.Domain}}:3000
 
 Bosh credentials:
 	username: {{.Config.DirectorUsername}}
-	password: {{.Config.DirectorPassword}}
+	password: {{.Secrets.DirectorPassword}}
 	IP:       {{.Terraform.DirectorPublicIP}}
+{{- if .Terraform.JumpboxPublicIP}}
+	Jumpbox:  {{.Terraform.JumpboxPublicIP}}
+{{- end}}
 	CA Cert:
 		{{ .Config.DirectorCACert | replace "\n" "\n\t\t"}}
 
-BOSH-generated NAT certs will expire on: {{ .CertExpiry }}
-
+{{if not .WithoutSecrets}}BOSH-generated NAT certs will expire on: {{ .CertExpiry }}{{end}}
+{{if .LastFailedPhase}}
+{{printf "The last deploy failed during the %s phase, continue it with concourse-up deploy --resume" .LastFailedPhase | red}}
+{{end}}
 Uses Concourse-Up version {{.Config.Version}}
 
 Built by {{"EngineerBetter http://engineerbetter.com" | blue}}
 `
 
+const credhubTemplate = `Credhub credentials:
+	username: {{.Config.CredhubUsername}}
+	password: {{.Secrets.CredhubPassword}}
+	URL:      {{.Config.CredhubURL}}
+	CA Cert:
+		{{ .Config.CredhubCACert | replace "\n" "\n\t\t"}}
+`
+
 func (info *Info) String() string {
 	t := template.Must(template.New("info").Funcs(template.FuncMap{
 		"replace": func(old, new, s string) string {
 			return strings.Replace(s, old, new, -1)
 		},
 		"blue": color.New(color.FgCyan, color.Bold).Sprint,
+		"red":  color.New(color.FgRed, color.Bold).Sprint,
 	}).Parse(infoTemplate))
+	template.Must(t.New("credhub").Parse(credhubTemplate))
+
+	i := *info
+	if i.Secrets == nil {
+		redacted := config.RedactSecrets(info.Config.Secrets)
+		i.Secrets = &redacted
+	}
+	name := "info"
+	if i.CredhubOnly {
+		name = "credhub"
+	}
+
 	var buf bytes.Buffer
-	err := t.Execute(&buf, info)
+	err := t.ExecuteTemplate(&buf, name, i)
 	if err != nil {
 		panic(err)
 	}
 	return buf.String()
 }
 
-func writeTempFile(data string) (name string, err error) {
-	f, err := ioutil.TempFile("", "")
+// Credhub returns the part of the info needed to log into credhub, with the secrets if they were asked for
+func (info *Info) Credhub() CredhubInfo {
+	credhub := CredhubInfo{
+		URL:      info.Config.CredhubURL,
+		CACert:   info.Config.CredhubCACert,
+		Username: info.Config.CredhubUsername,
+	}
+	if info.Secrets != nil {
+		credhub.Password = info.Secrets.CredhubPassword
+		credhub.AdminClientSecret = info.Secrets.CredhubAdminClientSecret
+	}
+	return credhub
+}
+
+// gatewayKeyPath is where Env writes the director's private key. It is in the user's cache dir, which only they can
+// read, rather than the shared temp dir. The path is fixed per deployment so that evaluating the env repeatedly
+// replaces one file instead of leaving a new one behind each time
+func gatewayKeyPath(conf config.Config) (string, error) {
+	path, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	name := conf.Deployment
+	if conf.Namespace != "" {
+		name = fmt.Sprintf("%s-%s", name, conf.Namespace)
+	}
+	return filepath.Join(path, "concourse-up", "keys", name+"-gateway.pem"), nil
+}
+
+// writeGatewayKey writes the key to a new file and renames it into place, so that it never writes through
+// a file or symlink which is already there
+func writeGatewayKey(conf config.Config) (string, error) {
+	path, err := gatewayKeyPath(conf)
 	if err != nil {
 		return "", err
 	}
-	name = f.Name()
-	_, err = f.WriteString(data)
-	if err1 := f.Close(); err == nil {
-		err = err1
+	dir := filepath.Dir(path)
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	// MkdirAll keeps the permissions of an existing dir
+	if err = os.Chmod(dir, 0700); err != nil {
+		return "", err
 	}
+
+	// TempFile creates the file exclusively, readable only by the user
+	f, err := ioutil.TempFile(dir, ".gateway-key")
 	if err != nil {
-		os.Remove(name)
+		return "", err
 	}
-	return name, err
+	defer os.Remove(f.Name())
+	if _, err = f.WriteString(conf.PrivateKey); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err = f.Close(); err != nil {
+		return "", err
+	}
+	return path, os.Rename(f.Name(), path)
 }
 
 var envTemplate = template.Must(template.New("env").Funcs(template.FuncMap{
-	"to_file": writeTempFile,
-}).Parse(`
+	"key_file":  writeGatewayKey,
+	"all_proxy": jumpbox.AllProxy,
+}).Parse(`{{if not .CredhubOnly}}
 export BOSH_ENVIRONMENT={{.Terraform.DirectorPublicIP}}
-export BOSH_GW_HOST={{.Terraform.DirectorPublicIP}}
+export BOSH_GW_HOST={{if .Terraform.JumpboxPublicIP}}{{.Terraform.JumpboxPublicIP}}{{else}}{{.Terraform.DirectorPublicIP}}{{end}}
 export BOSH_CA_CERT='{{.Config.DirectorCACert}}'
 export BOSH_DEPLOYMENT=concourse
 export BOSH_CLIENT={{.Config.DirectorUsername}}
-export BOSH_CLIENT_SECRET={{.Config.DirectorPassword}}
+export BOSH_CLIENT_SECRET={{.Secrets.DirectorPassword}}
 export BOSH_GW_USER={{.GatewayUser}}
-export BOSH_GW_PRIVATE_KEY={{.Config.PrivateKey | to_file}}
+export BOSH_GW_PRIVATE_KEY={{.Config | key_file}}
+{{- if .Terraform.JumpboxPublicIP}}
+export BOSH_ALL_PROXY={{all_proxy .Terraform.JumpboxPublicIP (.Config | key_file)}}
+{{- end}}
+{{- end}}
 export CREDHUB_SERVER={{.Config.CredhubURL}}
 export CREDHUB_CA_CERT='{{.Config.CredhubCACert}}'
 export CREDHUB_CLIENT=credhub_admin
-export CREDHUB_SECRET={{.Config.CredhubAdminClientSecret}}
+export CREDHUB_SECRET={{.Secrets.CredhubAdminClientSecret}}
 export NAMESPACE={{.Config.Namespace}}
 `))
 
 // Env returns a string that is suitable for a shell to evaluate that sets environment
-// varibles which are used to log into bosh and credhub
+// varibles which are used to log into bosh and credhub. It fails without the secrets those need
 func (info *Info) Env() (string, error) {
+	if info.Secrets == nil || info.Secrets.CredhubAdminClientSecret == "" || (!info.CredhubOnly && info.Secrets.DirectorPassword == "") {
+		return "", fmt.Errorf("could not read the secrets of %s, which are needed to log into bosh and credhub", info.Config.Deployment)
+	}
 	var buf bytes.Buffer
 	var i Info
 	i = *info
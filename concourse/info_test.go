@@ -1,6 +1,9 @@
 package concourse
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -10,11 +13,13 @@ import (
 
 func TestInfo_String(t *testing.T) {
 	type fields struct {
-		Terraform   TerraformInfo
-		Config      config.Config
-		Instances   []bosh.Instance
-		CertExpiry  string
-		GatewayUser string
+		Terraform       TerraformInfo
+		Config          config.Config
+		Instances       []bosh.Instance
+		CertExpiry      string
+		GatewayUser     string
+		LastFailedPhase string
+		WithoutSecrets  bool
 	}
 	defaultFields := fields{
 		Terraform: TerraformInfo{
@@ -49,20 +54,246 @@ func TestInfo_String(t *testing.T) {
 			},
 			want: "IAAS:      aCloudProvider",
 		},
+		{
+			name:   "failed deploy templating",
+			fields: defaultFields,
+			init: func(f fields) fields {
+				f.LastFailedPhase = "stemcell"
+				return f
+			},
+			want: "The last deploy failed during the stemcell phase, continue it with concourse-up deploy --resume",
+		},
+		{
+			name:   "secrets are redacted unless asked for",
+			fields: defaultFields,
+			init: func(f fields) fields {
+				f.Config.DirectorPassword = "s3cret"
+				return f
+			},
+			want: "password: (redacted)",
+		},
+		{
+			name:   "info without secrets templating",
+			fields: defaultFields,
+			init: func(f fields) fields {
+				f.WithoutSecrets = true
+				return f
+			},
+			want: "Listing the instances needs the director password, run info with --show-secrets to see them",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.fields = tt.init(tt.fields)
 			info := &Info{
-				Terraform:   tt.fields.Terraform,
-				Config:      tt.fields.Config,
-				Instances:   tt.fields.Instances,
-				CertExpiry:  tt.fields.CertExpiry,
-				GatewayUser: tt.fields.GatewayUser,
+				Terraform:       tt.fields.Terraform,
+				Config:          tt.fields.Config,
+				Instances:       tt.fields.Instances,
+				CertExpiry:      tt.fields.CertExpiry,
+				GatewayUser:     tt.fields.GatewayUser,
+				LastFailedPhase: tt.fields.LastFailedPhase,
+				WithoutSecrets:  tt.fields.WithoutSecrets,
 			}
-			if got := info.String(); !strings.Contains(got, tt.want) {
+			got := info.String()
+			if !strings.Contains(got, tt.want) {
 				t.Errorf("Info.String() = %v, want %v", got, tt.want)
 			}
+			if tt.fields.Config.DirectorPassword != "" && strings.Contains(got, tt.fields.Config.DirectorPassword) {
+				t.Errorf("Info.String() = %v, want no secrets", got)
+			}
 		})
 	}
 }
+
+func TestInfo_Env(t *testing.T) {
+	info := &Info{
+		Config: config.Config{
+			Deployment: "concourse-up-happymeal",
+			Namespace:  "eu-west-1",
+			Secrets: config.Secrets{
+				CredhubAdminClientSecret: "credhub secret",
+				DirectorPassword:         "director password",
+				PrivateKey:               "private key",
+			},
+		},
+	}
+	info.Secrets = &info.Config.Secrets
+	cacheDir, cleanup := useTempCacheDir(t)
+	defer cleanup()
+	keyPath, err := gatewayKeyPath(info.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(keyPath, cacheDir) {
+		t.Fatalf("gatewayKeyPath() = %v, want a path in %v", keyPath, cacheDir)
+	}
+
+	for i := 0; i < 2; i++ {
+		env, err := info.Env()
+		if err != nil {
+			t.Fatalf("Info.Env() error = %v", err)
+		}
+		if want := "export BOSH_GW_PRIVATE_KEY=" + keyPath + "\n"; !strings.Contains(env, want) {
+			t.Errorf("Info.Env() = %v, want %v", env, want)
+		}
+	}
+
+	stat, err := os.Stat(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.Mode().Perm() != 0600 {
+		t.Errorf("key file mode = %v, want 0600", stat.Mode().Perm())
+	}
+	stat, err = os.Stat(filepath.Dir(keyPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.Mode().Perm() != 0700 {
+		t.Errorf("key dir mode = %v, want 0700", stat.Mode().Perm())
+	}
+	contents, err := ioutil.ReadFile(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(contents) != "private key" {
+		t.Errorf("key file contents = %q, want %q", contents, "private key")
+	}
+}
+
+func TestInfo_EnvWithoutSecrets(t *testing.T) {
+	info := &Info{
+		Config: config.Config{
+			Deployment: "concourse-up-happymeal",
+			Secrets: config.Secrets{
+				CredhubAdminClientSecret: "credhub secret",
+			},
+		},
+	}
+	want := "could not read the secrets of concourse-up-happymeal, which are needed to log into bosh and credhub"
+	if _, err := info.Env(); err == nil || err.Error() != want {
+		t.Errorf("Info.Env() error = %v, want %v", err, want)
+	}
+
+	info.Secrets = &info.Config.Secrets
+	if _, err := info.Env(); err == nil || err.Error() != want {
+		t.Errorf("Info.Env() without the director password error = %v, want %v", err, want)
+	}
+
+	info.CredhubOnly = true
+	env, err := info.Env()
+	if err != nil {
+		t.Fatalf("Info.Env() error = %v", err)
+	}
+	if !strings.Contains(env, "export CREDHUB_SECRET=credhub secret\n") {
+		t.Errorf("Info.Env() = %v, want the credhub secret", env)
+	}
+}
+
+func TestInfo_EnvPrivate(t *testing.T) {
+	info := &Info{
+		Terraform: TerraformInfo{
+			DirectorPublicIP: "10.0.1.6",
+			JumpboxPublicIP:  "1.2.3.4",
+		},
+		Config: config.Config{
+			Deployment: "concourse-up-happymeal",
+			Namespace:  "eu-west-1",
+			Private:    true,
+			Secrets: config.Secrets{
+				CredhubAdminClientSecret: "credhub secret",
+				DirectorPassword:         "director password",
+				PrivateKey:               "private key",
+			},
+		},
+		GatewayUser: "ubuntu",
+	}
+	info.Secrets = &info.Config.Secrets
+	_, cleanup := useTempCacheDir(t)
+	defer cleanup()
+	keyPath, err := gatewayKeyPath(info.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env, err := info.Env()
+	if err != nil {
+		t.Fatalf("Info.Env() error = %v", err)
+	}
+	for _, want := range []string{
+		"export BOSH_ENVIRONMENT=10.0.1.6\n",
+		"export BOSH_GW_HOST=1.2.3.4\n",
+		"export BOSH_ALL_PROXY=ssh+socks5://ubuntu@1.2.3.4:22?private-key=" + keyPath + "\n",
+	} {
+		if !strings.Contains(env, want) {
+			t.Errorf("Info.Env() = %v, want %v", env, want)
+		}
+	}
+}
+
+func TestInfo_EnvReplacesSymlink(t *testing.T) {
+	conf := config.Config{
+		Deployment: "concourse-up-happymeal",
+		Secrets: config.Secrets{
+			PrivateKey: "private key",
+		},
+	}
+	cacheDir, cleanup := useTempCacheDir(t)
+	defer cleanup()
+	keyPath, err := gatewayKeyPath(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.MkdirAll(filepath.Dir(keyPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(cacheDir, "target")
+	if err = ioutil.WriteFile(target, []byte("untouched"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Symlink(target, keyPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = writeGatewayKey(conf); err != nil {
+		t.Fatalf("writeGatewayKey() error = %v", err)
+	}
+	contents, err := ioutil.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(contents) != "untouched" {
+		t.Errorf("writeGatewayKey() wrote through a symlink, target contents = %q", contents)
+	}
+	stat, err := os.Lstat(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.Mode()&os.ModeSymlink != 0 {
+		t.Error("writeGatewayKey() left the symlink in place")
+	}
+}
+
+// useTempCacheDir points os.UserCacheDir at a new temporary directory, which it returns with a func to undo it
+func useTempCacheDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "concourse-up-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var restore []func()
+	for _, env := range []string{"XDG_CACHE_HOME", "HOME"} {
+		env := env
+		if old, ok := os.LookupEnv(env); ok {
+			restore = append(restore, func() { os.Setenv(env, old) })
+		} else {
+			restore = append(restore, func() { os.Unsetenv(env) })
+		}
+		os.Setenv(env, dir)
+	}
+	return dir, func() {
+		for _, r := range restore {
+			r()
+		}
+		os.RemoveAll(dir)
+	}
+}
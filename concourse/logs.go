@@ -0,0 +1,21 @@
+package concourse
+
+import (
+	"github.com/EngineerBetter/concourse-up/bosh"
+)
+
+// Logs tails or downloads the logs of the Concourse deployment
+func (client *Client) Logs(opts bosh.LogsOptions) error {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+
+	boshClient, err := client.buildBoshClientFromConfig(conf)
+	if err != nil {
+		return err
+	}
+	defer boshClient.Cleanup()
+
+	return boshClient.Logs(opts)
+}
@@ -101,7 +101,7 @@ func (client *Client) constructBoshClient() (*bosh.IClient, error) {
 
 	tfInputVars := client.tfInputVarsFactory.NewInputVars(conf)
 
-	tfOutputs, err := client.tfCLI.BuildOutput(tfInputVars)
+	tfOutputs, err := client.readOutputs(conf, tfInputVars)
 	if err != nil {
 		return nil, err
 	}
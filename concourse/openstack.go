@@ -0,0 +1,71 @@
+package concourse
+
+import (
+	"fmt"
+
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/terraform"
+)
+
+var openstackVersionFile = MustAsset("../../concourse-up-ops/director-versions-openstack.json")
+
+func init() {
+	Register(iaas.OpenStack, IAAS{
+		VersionFile:           openstackVersionFile,
+		NewTFInputVarsFactory: newOpenStackInputVarsFactory,
+		DeleteVMs:             deleteVMsInDeployment,
+	})
+}
+
+func newOpenStackInputVarsFactory(provider iaas.Provider) (TFInputVarsFactory, error) {
+	externalNetwork, err := provider.Attr("external_network")
+	if err != nil {
+		return &OpenStackInputVarsFactory{}, fmt.Errorf("Error finding attribute [external_network]: [%v]", err)
+	}
+
+	dbImage, err := provider.Attr("db_image")
+	if err != nil {
+		return &OpenStackInputVarsFactory{}, fmt.Errorf("Error finding attribute [db_image]: [%v]", err)
+	}
+
+	return &OpenStackInputVarsFactory{
+		dbImage:         dbImage,
+		externalNetwork: externalNetwork,
+		region:          provider.Region(),
+	}, nil
+}
+
+type OpenStackInputVarsFactory struct {
+	dbImage         string
+	externalNetwork string
+	region          string
+}
+
+func (f *OpenStackInputVarsFactory) NewInputVars(c config.Config) terraform.InputVars {
+	var recordName string
+	if c.HostedZoneID != "" {
+		// Designate takes fully qualified names, and the domain of the ATC is in the zone
+		recordName = c.Domain + "."
+	}
+
+	return &terraform.OpenStackInputVars{
+		AllowIPs:        c.AllowIPs,
+		ConfigBucket:    c.ConfigBucket,
+		DBFlavor:        c.RDSInstanceClass,
+		DBImage:         f.dbImage,
+		DBName:          c.RDSDefaultDatabaseName,
+		DBPassword:      c.RDSPassword,
+		DBUsername:      c.RDSUsername,
+		Deployment:      c.Deployment,
+		DNSRecordName:   recordName,
+		DNSZoneID:       c.HostedZoneID,
+		ExternalNetwork: f.externalNetwork,
+		Namespace:       c.Namespace,
+		PrivateCIDR:     c.PrivateCIDR,
+		PublicCIDR:      c.PublicCIDR,
+		PublicKey:       c.PublicKey,
+		Region:          f.region,
+		SourceAccessIP:  c.SourceAccessIP,
+	}
+}
@@ -0,0 +1,181 @@
+package concourse
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/terraform"
+	"github.com/fatih/color"
+)
+
+// Plan represents the changes a deploy would make, without applying any of them
+type Plan struct {
+	NewDeployment bool                 `json:"new_deployment"`
+	Config        []config.FieldChange `json:"config"`
+	Terraform     terraform.Plan       `json:"terraform"`
+	CloudConfig   string               `json:"cloud_config"`
+	Concourse     string               `json:"concourse"`
+	Notes         []string             `json:"notes"`
+}
+
+// Plan merges the deploy arguments into the stored config and reports the terraform
+// and BOSH changes that a deploy with the same arguments would make
+func (client *Client) Plan() (*Plan, error) {
+	priorConfigExists, err := client.configClient.ConfigExists()
+	if err != nil {
+		return nil, fmt.Errorf("error determining if config already exists [%v]", err)
+	}
+
+	var priorConf config.Config
+	if priorConfigExists {
+		priorConf, err = client.configClient.Load()
+		if err != nil {
+			return nil, fmt.Errorf("error loading existing config [%v]", err)
+		}
+	}
+
+	conf, isDomainUpdated, err := client.mergeConfig(priorConfigExists)
+	if err != nil {
+		return nil, fmt.Errorf("error getting initial config before plan: [%v]", err)
+	}
+
+	r, err := client.checkPreTerraformConfigRequirements(conf, client.deployArgs.SelfUpdate)
+	if err != nil {
+		return nil, err
+	}
+	conf = r.applyTo(conf)
+	conf = client.withVersion(conf)
+
+	tfInputVars := client.tfInputVarsFactory.NewInputVars(conf)
+	tfPlan, err := client.tfCLI.Plan(tfInputVars)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{
+		NewDeployment: !priorConfigExists,
+		Config:        config.Diff(priorConf, conf),
+		Terraform:     tfPlan,
+	}
+
+	if plan.NewDeployment {
+		return plan, nil
+	}
+
+	switch {
+	case client.deployArgs.TLSCert != "":
+		plan.Notes = append(plan.Notes, "the provided TLS certificate will replace the current Concourse certificate")
+	case isDomainUpdated:
+		plan.Notes = append(plan.Notes, "the domain has changed so a new Concourse certificate will be generated")
+	case conf.ConcourseCert != "" && timeTillExpiry(conf.ConcourseCert) <= 28*24*time.Hour:
+		plan.Notes = append(plan.Notes, "the Concourse certificate expires within 28 days and will be regenerated")
+	}
+
+	boshStateBytes, err := loadDirectorState(client.configClient)
+	if err != nil {
+		return nil, err
+	}
+	if boshStateBytes == nil {
+		plan.Notes = append(plan.Notes, "no BOSH director state was found so the director will be created")
+		return plan, nil
+	}
+
+	boshCredsBytes, err := loadDirectorCreds(client.configClient)
+	if err != nil {
+		return nil, err
+	}
+
+	tfOutputs, err := client.tfCLI.BuildOutput(tfInputVars)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.Domain == "" {
+		conf.Domain, err = tfOutputs.Get("ATCPublicIP")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	boshClient, err := client.buildBoshClient(conf, tfOutputs)
+	if err != nil {
+		return nil, err
+	}
+	defer boshClient.Cleanup()
+
+	diff, err := boshClient.Diff(boshCredsBytes)
+	if err != nil {
+		return nil, err
+	}
+	plan.CloudConfig = diff.CloudConfig
+	plan.Concourse = diff.Concourse
+
+	if tfPlan.HasChanges() {
+		plan.Notes = append(plan.Notes, "the BOSH diff is computed against the current terraform outputs and may change once terraform has been applied")
+	}
+
+	return plan, nil
+}
+
+const planTemplate = `{{if .NewDeployment}}No existing deployment was found, a new deployment will be created
+{{end}}
+Config changes:
+{{- range .Config}}
+	{{.Field}}: {{.From | printf "%q"}} => {{.To | printf "%q"}}
+{{- else}}
+	none
+{{- end}}
+
+Infrastructure changes:
+{{- range .Terraform.Changes}}
+	{{.Action | printf "%-8s"}} {{.Address}}
+{{- else}}
+	none
+{{- end}}
+{{- with .Terraform.Destructive}}
+
+{{"WARNING: the following resources will be destroyed or replaced:" | red}}
+{{- range .}}
+	{{.Address}}
+{{- end}}
+{{- end}}
+{{if not .NewDeployment}}
+Cloud config changes:
+{{- if .CloudConfig}}
+	{{.CloudConfig | replace "\n" "\n\t"}}
+{{- else}}
+	none
+{{- end}}
+
+Concourse deployment changes:
+{{- if .Concourse}}
+	{{.Concourse | replace "\n" "\n\t"}}
+{{- else}}
+	none
+{{- end}}
+{{end}}
+{{- with .Notes}}
+Notes:
+{{- range .}}
+	{{.}}
+{{- end}}
+{{end}}`
+
+func (plan *Plan) String() string {
+	t := template.Must(template.New("plan").Funcs(template.FuncMap{
+		"replace": func(old, new, s string) string {
+			return strings.Replace(s, old, new, -1)
+		},
+		"red": color.New(color.FgRed, color.Bold).Sprint,
+	}).Parse(planTemplate))
+	var buf bytes.Buffer
+	err := t.Execute(&buf, plan)
+	if err != nil {
+		panic(err)
+	}
+	return buf.String()
+}
@@ -0,0 +1,60 @@
+package concourse
+
+import (
+	"fmt"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/fly"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/terraform"
+)
+
+// IAAS holds what the client needs to deploy Concourse on an IAAS and destroy it again
+type IAAS struct {
+	// VersionFile holds the versions of the director, its CPI and stemcell
+	VersionFile []byte
+	// NewTFInputVarsFactory creates the factory of terraform input vars for the provider
+	NewTFInputVarsFactory func(provider iaas.Provider) (TFInputVarsFactory, error)
+	// DeleteVMs deletes the VMs BOSH created, which terraform does not know about, before the network is destroyed.
+	// It may return a func which deletes what is left once terraform has destroyed everything else
+	DeleteVMs func(provider iaas.Provider, conf config.Config, outputs func() (terraform.Outputs, error)) (func() error, error)
+}
+
+var iaases = make(map[iaas.Name]IAAS)
+
+// Register makes an IAAS available to the client
+func Register(name iaas.Name, i IAAS) {
+	if _, ok := iaases[name]; ok {
+		panic("concourse: Register called twice for " + name.String())
+	}
+	iaases[name] = i
+}
+
+func lookup(name iaas.Name) (IAAS, error) {
+	i, ok := iaases[name]
+	if !ok {
+		return IAAS{}, fmt.Errorf("IAAS not supported [%s]", name)
+	}
+	return i, nil
+}
+
+// Registration holds everything concourse-up needs to deploy onto an IAAS
+type Registration struct {
+	IAAS      iaas.Registration
+	Terraform terraform.IAAS
+	BOSH      bosh.IAAS
+	Pipeline  func(provider iaas.Provider) (fly.Pipeline, error)
+	Concourse IAAS
+}
+
+// RegisterIAAS registers an IAAS with every package that deploys onto it, and returns the Name it is known by.
+// It lets an IAAS from outside this repository be added in one step
+func RegisterIAAS(name string, r Registration) iaas.Name {
+	n := iaas.Register(name, r.IAAS)
+	terraform.Register(n, r.Terraform)
+	bosh.Register(n, r.BOSH)
+	fly.Register(n, r.Pipeline)
+	Register(n, r.Concourse)
+	return n
+}
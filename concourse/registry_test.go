@@ -0,0 +1,32 @@
+package concourse
+
+import (
+	"testing"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/fly"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/terraform"
+)
+
+func TestRegisterIAAS(t *testing.T) {
+	name := RegisterIAAS("on-premises", Registration{
+		IAAS:      iaas.Registration{DefaultRegion: func() string { return "dc1" }},
+		Terraform: terraform.IAAS{Config: "# on-premises"},
+		BOSH:      bosh.IAAS{},
+		Pipeline:  func(iaas.Provider) (fly.Pipeline, error) { return nil, nil },
+		Concourse: IAAS{VersionFile: []byte("{}")},
+	})
+
+	if got, err := iaas.Assosiate("on-premises"); err != nil || got != name {
+		t.Errorf("Assosiate() = %v, %v, want %v", got, err, name)
+	}
+	r, err := iaas.Lookup(name)
+	if err != nil || r.DefaultRegion() != "dc1" {
+		t.Errorf("iaas.Lookup() = %v, want the registered IAAS", err)
+	}
+	i, err := lookup(name)
+	if err != nil || string(i.VersionFile) != "{}" {
+		t.Errorf("lookup() = %v, want the registered IAAS", err)
+	}
+}
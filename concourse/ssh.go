@@ -0,0 +1,47 @@
+package concourse
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+)
+
+// SSH opens an interactive session on the director, or on a Concourse VM such as web/0 or worker/1
+func (client *Client) SSH(target string) error {
+	info, err := client.FetchInfo()
+	if err != nil {
+		return err
+	}
+
+	if target != bosh.DirectorInstance && !hasInstanceGroup(info.Instances, target) {
+		return fmt.Errorf("no instance matches %q, choose one of %s", target, strings.Join(sshTargets(info.Instances), ", "))
+	}
+
+	boshClient, err := client.buildBoshClientFromConfig(info.Config)
+	if err != nil {
+		return err
+	}
+	defer boshClient.Cleanup()
+
+	return boshClient.SSH(target)
+}
+
+// hasInstanceGroup reports whether target, given as group or group/index, names a group with deployed instances
+func hasInstanceGroup(instances []bosh.Instance, target string) bool {
+	group := strings.SplitN(target, "/", 2)[0]
+	for _, instance := range instances {
+		if strings.SplitN(instance.Name, "/", 2)[0] == group {
+			return true
+		}
+	}
+	return false
+}
+
+func sshTargets(instances []bosh.Instance) []string {
+	targets := []string{bosh.DirectorInstance}
+	for _, instance := range instances {
+		targets = append(targets, instance.Name)
+	}
+	return targets
+}
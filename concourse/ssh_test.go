@@ -0,0 +1,32 @@
+package concourse
+
+import (
+	"testing"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+)
+
+func TestHasInstanceGroup(t *testing.T) {
+	instances := []bosh.Instance{
+		{Name: "web/6f0d3ea5-1b4c-4a4f-8c3c-1b9e2d0e5f11"},
+		{Name: "worker/2c2d8b4e-9a8f-4d4e-a1c2-6b3a7d9e8f22"},
+	}
+	tests := []struct {
+		target string
+		want   bool
+	}{
+		{"web", true},
+		{"web/0", true},
+		{"worker/1", true},
+		{"worker/2c2d8b4e-9a8f-4d4e-a1c2-6b3a7d9e8f22", true},
+		{"db/0", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			if got := hasInstanceGroup(instances, tt.target); got != tt.want {
+				t.Errorf("hasInstanceGroup(%q) = %v, want %v", tt.target, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package config
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// archiveTerraformStateFilename is the name the terraform state has in an archive, wherever it was stored
+const archiveTerraformStateFilename = "terraform.tfstate"
+
+// Archive is the complete state of a deployment, for moving it to another account or project
+type Archive struct {
+	Config Config
+	// Files are the assets stored alongside the config, by filename
+	Files          map[string][]byte
+	TerraformState []byte
+}
+
+// Export returns the config, those of filenames which exist and the terraform state
+func (client *Client) Export(filenames []string) (Archive, error) {
+	conf, err := client.Load()
+	if err != nil {
+		return Archive{}, err
+	}
+
+	archive := Archive{Config: conf, Files: map[string][]byte{}}
+	for _, filename := range filenames {
+		exists, err := client.HasAsset(filename)
+		if err != nil {
+			return Archive{}, err
+		}
+		if !exists {
+			continue
+		}
+		if archive.Files[filename], err = client.LoadAsset(filename); err != nil {
+			return Archive{}, fmt.Errorf("error reading %s: [%v]", filename, err)
+		}
+	}
+
+	if archive.TerraformState, err = client.LoadTerraformState(conf); err != nil {
+		return Archive{}, fmt.Errorf("error reading terraform state: [%v]", err)
+	}
+
+	return archive, nil
+}
+
+// Import seeds a deployment which has no config yet with an archive exported from another deployment of the same name
+// The fields which locate the deployment's state are rewritten for the new config bucket. The archive must be of a
+// deployment on the same IaaS and, unless withoutState is set, in the same region. With withoutState the deployment
+// is moved to the client's region and zone, as the next deploy builds new infrastructure there
+func (client *Client) Import(archive Archive, withoutState bool) (Config, error) {
+	if client.BucketError != nil {
+		return Config{}, client.BucketError
+	}
+	if archive.Config.Project != client.Project {
+		return Config{}, fmt.Errorf("the archive is of deployment %s, import it with that name", archive.Config.Project)
+	}
+	iaasName, err := configIAAS(archive.Config)
+	if err != nil {
+		return Config{}, err
+	}
+	if iaasName != client.Iaas.IAAS() {
+		return Config{}, fmt.Errorf("the archive is of a deployment on %s, import it with --iaas %s", iaasName, iaasName)
+	}
+	exists, err := client.ConfigExists()
+	if err != nil {
+		return Config{}, err
+	}
+	if exists {
+		return Config{}, fmt.Errorf("deployment %s already has a config, destroy it before importing", client.Project)
+	}
+
+	conf := archive.Config
+	conf.ConfigBucket = client.configBucket()
+	conf.Namespace = client.Namespace
+	conf.TFStatePath = terraformStateFileName
+
+	if region := client.Iaas.Region(); conf.Region != "" && conf.Region != region {
+		if !withoutState {
+			return Config{}, fmt.Errorf("the archive is of a deployment in %s, import it with --region %s or pass --without-state to move it to %s", conf.Region, conf.Region, region)
+		}
+		conf.Region = region
+		conf.AvailabilityZone = client.Iaas.Zone("")
+		conf.Zones = nil
+		conf.ZonePrivateCIDRs = nil
+		if conf.AvailabilityZone != "" {
+			conf.Zones = []string{conf.AvailabilityZone}
+		}
+	}
+
+	for filename, contents := range archive.Files {
+		if err = client.StoreAsset(filename, contents); err != nil {
+			return Config{}, fmt.Errorf("error storing %s: [%v]", filename, err)
+		}
+	}
+	if archive.TerraformState != nil {
+		if err = client.storeTerraformState(conf, archive.TerraformState); err != nil {
+			return Config{}, fmt.Errorf("error storing terraform state: [%v]", err)
+		}
+	}
+
+	// The config is stored last, so that a failed import leaves no config and can be run again
+	return conf, client.Update(conf)
+}
+
+// SealArchive bundles an archive into a tarball encrypted with the passphrase in $CONCOURSE_UP_PASSPHRASE
+func SealArchive(archive Archive) ([]byte, error) {
+	configBytes, err := json.Marshal(archive.Config)
+	if err != nil {
+		return nil, err
+	}
+	secretsBytes, err := json.Marshal(archive.Config.Secrets)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]byte{configFilePath: configBytes, SecretsFilename: secretsBytes}
+	for filename, contents := range archive.Files {
+		files[filename] = contents
+	}
+	if archive.TerraformState != nil {
+		files[archiveTerraformStateFilename] = archive.TerraformState
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for filename, contents := range files {
+		if err = tw.WriteHeader(&tar.Header{Name: filename, Mode: 0600, Size: int64(len(contents))}); err != nil {
+			return nil, err
+		}
+		if _, err = tw.Write(contents); err != nil {
+			return nil, err
+		}
+	}
+	if err = tw.Close(); err != nil {
+		return nil, err
+	}
+	if err = gz.Close(); err != nil {
+		return nil, err
+	}
+
+	encryption := Encryption{Type: EncryptionPassphrase}
+	if err = encryption.Validate(); err != nil {
+		return nil, err
+	}
+	return encrypt(nil, encryption, buf.Bytes())
+}
+
+// OpenArchive decrypts and unpacks an archive written by SealArchive
+func OpenArchive(contents []byte) (Archive, error) {
+	if !IsEncrypted(contents) {
+		return Archive{}, errors.New("not an archive exported by concourse-up")
+	}
+	tarball, err := decrypt(nil, contents)
+	if err != nil {
+		return Archive{}, err
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(tarball))
+	if err != nil {
+		return Archive{}, err
+	}
+	tr := tar.NewReader(gz)
+
+	archive := Archive{Files: map[string][]byte{}}
+	var configBytes, secretsBytes []byte
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Archive{}, err
+		}
+		contents, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return Archive{}, err
+		}
+		switch header.Name {
+		case configFilePath:
+			configBytes = contents
+		case SecretsFilename:
+			secretsBytes = contents
+		case archiveTerraformStateFilename:
+			archive.TerraformState = contents
+		default:
+			archive.Files[header.Name] = contents
+		}
+	}
+
+	for filename, contents := range map[string][]byte{configFilePath: configBytes, SecretsFilename: secretsBytes} {
+		if contents == nil {
+			return Archive{}, fmt.Errorf("the archive has no %s", filename)
+		}
+	}
+	if err = json.Unmarshal(configBytes, &archive.Config); err != nil {
+		return Archive{}, err
+	}
+	if err = json.Unmarshal(secretsBytes, &archive.Config.Secrets); err != nil {
+		return Archive{}, err
+	}
+	return archive, nil
+}
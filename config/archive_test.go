@@ -0,0 +1,172 @@
+package config_test
+
+import (
+	"encoding/json"
+	"os"
+
+	. "github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/iaas/iaasfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Archive", func() {
+	var buckets map[string]map[string][]byte
+
+	newProvider := func(region string) *iaasfakes.FakeProvider {
+		provider := &iaasfakes.FakeProvider{}
+		provider.IAASReturns(iaas.AWS)
+		provider.RegionReturns(region)
+		provider.HasFileStub = func(bucket, path string) (bool, error) {
+			_, ok := buckets[bucket][path]
+			return ok, nil
+		}
+		provider.LoadFileStub = func(bucket, path string) ([]byte, error) {
+			return buckets[bucket][path], nil
+		}
+		provider.WriteFileStub = func(bucket, path string, contents []byte) error {
+			if buckets[bucket] == nil {
+				buckets[bucket] = map[string][]byte{}
+			}
+			buckets[bucket][path] = contents
+			return nil
+		}
+		return provider
+	}
+
+	BeforeEach(func() {
+		os.Setenv(PassphraseEnvVar, "correct horse battery staple")
+
+		conf := Config{
+			AvailabilityZone: "eu-west-1a",
+			ConfigBucket:     "concourse-up-test-eu-west-1-config",
+			Deployment:       "concourse-up-test",
+			IAAS:             "AWS",
+			Namespace:        "eu-west-1",
+			Project:          "test",
+			Region:           "eu-west-1",
+			SchemaVersion:    CurrentSchemaVersion,
+			TFStatePath:      "terraform.tfstate",
+			Zones:            []string{"eu-west-1a", "eu-west-1b"},
+			ZonePrivateCIDRs: []string{"10.0.2.0/24"},
+			Secrets: Secrets{
+				RDSPassword: "s3cret",
+			},
+		}
+		configBytes, err := json.Marshal(conf)
+		Expect(err).ToNot(HaveOccurred())
+		secretsBytes, err := json.Marshal(conf.Secrets)
+		Expect(err).ToNot(HaveOccurred())
+		buckets = map[string]map[string][]byte{
+			"concourse-up-test-eu-west-1-config": {
+				"config.json":        configBytes,
+				"secrets.json":       secretsBytes,
+				"director-creds.yml": []byte("creds"),
+				"terraform.tfstate":  []byte("tfstate"),
+			},
+		}
+	})
+
+	AfterEach(func() {
+		os.Unsetenv(PassphraseEnvVar)
+	})
+
+	It("moves a deployment to a new config bucket", func() {
+		from := &Client{Iaas: newProvider("eu-west-1"), Project: "test", Namespace: "eu-west-1", BucketName: "concourse-up-test-eu-west-1-config"}
+		archive, err := from.Export([]string{"director-creds.yml", "maintenance.json"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(archive.Files).To(Equal(map[string][]byte{"director-creds.yml": []byte("creds")}))
+
+		sealed, err := SealArchive(archive)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(sealed)).ToNot(ContainSubstring("s3cret"))
+
+		opened, err := OpenArchive(sealed)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(opened).To(Equal(archive))
+
+		to := &Client{Iaas: newProvider("eu-west-1"), Project: "test", Namespace: "moved", BucketName: "concourse-up-moved-test-eu-west-1-config"}
+		conf, err := to.Import(opened, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(conf.ConfigBucket).To(Equal("concourse-up-moved-test-eu-west-1-config"))
+		Expect(conf.Namespace).To(Equal("moved"))
+		Expect(conf.RDSPassword).To(Equal("s3cret"))
+		Expect(conf.Zones).To(Equal([]string{"eu-west-1a", "eu-west-1b"}))
+
+		bucket := buckets["concourse-up-moved-test-eu-west-1-config"]
+		Expect(bucket).To(HaveKeyWithValue("director-creds.yml", []byte("creds")))
+		Expect(bucket).To(HaveKeyWithValue("terraform.tfstate", []byte("tfstate")))
+		Expect(bucket).To(HaveKey("config.json"))
+		Expect(string(bucket["config.json"])).ToNot(ContainSubstring("s3cret"))
+
+		_, err = to.Import(opened, false)
+		Expect(err).To(MatchError("deployment test already has a config, destroy it before importing"))
+	})
+
+	It("only imports an archive under the deployment's own name", func() {
+		from := &Client{Iaas: newProvider("eu-west-1"), Project: "test", BucketName: "concourse-up-test-eu-west-1-config"}
+		archive, err := from.Export(nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		to := &Client{Iaas: newProvider("eu-west-1"), Project: "other", BucketName: "concourse-up-other-eu-west-1-config"}
+		_, err = to.Import(archive, false)
+		Expect(err).To(MatchError("the archive is of deployment test, import it with that name"))
+	})
+
+	It("only imports an archive on the same IaaS", func() {
+		from := &Client{Iaas: newProvider("eu-west-1"), Project: "test", BucketName: "concourse-up-test-eu-west-1-config"}
+		archive, err := from.Export(nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		provider := newProvider("europe-west1")
+		provider.IAASReturns(iaas.GCP)
+		to := &Client{Iaas: provider, Project: "test", BucketName: "concourse-up-test-europe-west1-config"}
+		_, err = to.Import(archive, true)
+		Expect(err).To(MatchError("the archive is of a deployment on AWS, import it with --iaas AWS"))
+		Expect(buckets).ToNot(HaveKey("concourse-up-test-europe-west1-config"))
+	})
+
+	It("refuses to change the region of a deployment whose state is imported", func() {
+		from := &Client{Iaas: newProvider("eu-west-1"), Project: "test", BucketName: "concourse-up-test-eu-west-1-config"}
+		archive, err := from.Export(nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		to := &Client{Iaas: newProvider("us-east-1"), Project: "test", BucketName: "concourse-up-test-us-east-1-config"}
+		_, err = to.Import(archive, false)
+		Expect(err).To(MatchError("the archive is of a deployment in eu-west-1, import it with --region eu-west-1 or pass --without-state to move it to us-east-1"))
+		Expect(buckets).ToNot(HaveKey("concourse-up-test-us-east-1-config"))
+	})
+
+	It("moves a deployment imported without its state to the new region", func() {
+		from := &Client{Iaas: newProvider("eu-west-1"), Project: "test", BucketName: "concourse-up-test-eu-west-1-config"}
+		archive, err := from.Export(nil)
+		Expect(err).ToNot(HaveOccurred())
+		archive.TerraformState = nil
+
+		provider := newProvider("us-east-1")
+		provider.ZoneReturns("us-east-1c")
+		to := &Client{Iaas: provider, Project: "test", BucketName: "concourse-up-test-us-east-1-config"}
+		conf, err := to.Import(archive, true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(conf.Region).To(Equal("us-east-1"))
+		Expect(conf.AvailabilityZone).To(Equal("us-east-1c"))
+		Expect(conf.Zones).To(Equal([]string{"us-east-1c"}))
+		Expect(conf.ZonePrivateCIDRs).To(BeEmpty())
+		Expect(provider.ZoneArgsForCall(0)).To(Equal(""))
+	})
+
+	It("needs the passphrase to open an archive", func() {
+		from := &Client{Iaas: newProvider("eu-west-1"), Project: "test", BucketName: "concourse-up-test-eu-west-1-config"}
+		archive, err := from.Export(nil)
+		Expect(err).ToNot(HaveOccurred())
+		sealed, err := SealArchive(archive)
+		Expect(err).ToNot(HaveOccurred())
+
+		os.Setenv(PassphraseEnvVar, "wrong")
+		_, err = OpenArchive(sealed)
+		Expect(err).To(HaveOccurred())
+		_, err = OpenArchive([]byte("not an archive"))
+		Expect(err).To(MatchError("not an archive exported by concourse-up"))
+	})
+})
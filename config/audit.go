@@ -0,0 +1,77 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AuditLogFilename is the name of the audit log in the config bucket, holding one JSON entry per line
+const AuditLogFilename = "audit-log.jsonl"
+
+// Outcomes of an audited command
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+// AuditEntry records a single run of a command which changed a deployment
+type AuditEntry struct {
+	Time     time.Time     `json:"time"`
+	Operator string        `json:"operator"`
+	Version  string        `json:"version"`
+	Command  string        `json:"command"`
+	Flags    []FieldChange `json:"flags,omitempty"`
+	Changes  []FieldChange `json:"changes,omitempty"`
+	Duration string        `json:"duration"`
+	Outcome  string        `json:"outcome"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// AppendAuditEntry adds entry to the end of the audit log
+func AppendAuditEntry(client IClient, entry AuditEntry) error {
+	contents, err := loadAuditLog(client)
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	contents = append(contents, line...)
+	contents = append(contents, '\n')
+	return client.StoreAsset(AuditLogFilename, contents)
+}
+
+// LoadAuditLog returns every entry in the audit log, oldest first
+func LoadAuditLog(client IClient) ([]AuditEntry, error) {
+	contents, err := loadAuditLog(client)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	scanner.Buffer(nil, 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var entry AuditEntry
+		if err = json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("error parsing line %d of %s: [%v]", line, AuditLogFilename, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+func loadAuditLog(client IClient) ([]byte, error) {
+	exists, err := client.HasAsset(AuditLogFilename)
+	if err != nil || !exists {
+		return nil, err
+	}
+	return client.LoadAsset(AuditLogFilename)
+}
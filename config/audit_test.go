@@ -0,0 +1,71 @@
+package config_test
+
+import (
+	"time"
+
+	. "github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/config/configfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Audit log", func() {
+	var client *configfakes.FakeIClient
+	var assets map[string][]byte
+
+	BeforeEach(func() {
+		assets = map[string][]byte{}
+		client = &configfakes.FakeIClient{}
+		client.HasAssetStub = func(name string) (bool, error) {
+			_, ok := assets[name]
+			return ok, nil
+		}
+		client.LoadAssetStub = func(name string) ([]byte, error) {
+			return assets[name], nil
+		}
+		client.StoreAssetStub = func(name string, contents []byte) error {
+			assets[name] = contents
+			return nil
+		}
+	})
+
+	It("is empty when nothing has been recorded", func() {
+		entries, err := LoadAuditLog(client)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+	})
+
+	It("appends entries in order", func() {
+		first := AuditEntry{
+			Time:     time.Date(2019, 3, 1, 10, 0, 0, 0, time.UTC),
+			Operator: "arn:aws:iam::123456789012:user/alice",
+			Version:  "0.20.0",
+			Command:  "deploy",
+			Flags:    []FieldChange{{Field: "workers", To: "3"}},
+			Changes:  []FieldChange{{Field: "concourse_worker_count", From: "1", To: "3"}},
+			Duration: "5m0s",
+			Outcome:  AuditSuccess,
+		}
+		second := AuditEntry{
+			Time:     time.Date(2019, 3, 2, 10, 0, 0, 0, time.UTC),
+			Operator: "arn:aws:iam::123456789012:user/bob",
+			Version:  "0.20.0",
+			Command:  "maintain",
+			Duration: "1m0s",
+			Outcome:  AuditFailure,
+			Error:    "director is unreachable",
+		}
+		Expect(AppendAuditEntry(client, first)).To(Succeed())
+		Expect(AppendAuditEntry(client, second)).To(Succeed())
+
+		entries, err := LoadAuditLog(client)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(Equal([]AuditEntry{first, second}))
+	})
+
+	It("reports the line of a corrupt entry", func() {
+		assets[AuditLogFilename] = []byte("{\"command\":\"deploy\"}\nnot json\n")
+		_, err := LoadAuditLog(client)
+		Expect(err).To(MatchError(ContainSubstring("error parsing line 2 of audit-log.jsonl")))
+	})
+})
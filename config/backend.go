@@ -0,0 +1,171 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/terraform"
+)
+
+// BackendEnvVar selects where the config is stored, see NewBackend
+const BackendEnvVar = "CONCOURSE_UP_CONFIG_BACKEND"
+
+// ErrBackendUnreachable is returned by PipelineEnv for a backend on the machine running concourse-up,
+// which the self-update pipeline cannot reach
+var ErrBackendUnreachable = errors.New("the self-update pipeline cannot reach a config backend in a local directory")
+
+//go:generate counterfeiter . Backend
+// Backend stores the config and the other files which make up a deployment's state
+type Backend interface {
+	Exists(filename string) (bool, error)
+	Read(filename string) ([]byte, error)
+	Write(filename string, contents []byte) error
+	// Create writes filename only if it does not exist, atomically, and returns iaas.ErrFileExists if it does
+	Create(filename string, contents []byte) error
+	Delete(filename string) error
+	// DeleteAll deletes every file stored for the deployment
+	DeleteAll() error
+	// TerraformBackend returns the terraform backend which keeps the state in the backend's terraform.tfstate,
+	// or nil to keep it in the config bucket
+	TerraformBackend() *terraform.Backend
+}
+
+// splitBackend is implemented by backends which cannot hold the terraform state, so keep it in another backend,
+// or in the config bucket when StateBackend returns nil
+type splitBackend interface {
+	StateBackend() Backend
+}
+
+// NewBackend returns the Backend described by backendURL, keeping the deployment's files under key.
+// An empty URL or "bucket" returns nil, meaning the IaaS config bucket. Otherwise the URL is one of
+//  dir:///path/to/directory
+//  vault://host:8200/mount/path, or vault+http:// for a Vault without TLS. The terraform state is kept in the
+//    config bucket, or in the s3:// backend given as ?terraform-state=s3://host:9000/bucket/path
+//  s3://host:9000/bucket/path, or s3+http:// for an endpoint without TLS
+func NewBackend(backendURL, key string) (Backend, error) {
+	if backendURL == "" || backendURL == "bucket" {
+		return nil, nil
+	}
+
+	u, err := url.Parse(backendURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config backend %q: [%v]", backendURL, err)
+	}
+
+	switch u.Scheme {
+	case "dir":
+		root := u.Path
+		if root == "" {
+			root = u.Opaque
+		}
+		if root == "" {
+			return nil, fmt.Errorf("invalid config backend %q: a directory is required", backendURL)
+		}
+		return NewDirBackend(joinPath(root, key)), nil
+	case "vault", "vault+http":
+		mount, path := splitPath(u.Path)
+		if u.Host == "" || mount == "" {
+			return nil, fmt.Errorf("invalid config backend %q: expected vault://host:port/mount[/path]", backendURL)
+		}
+		vault, err := NewVaultBackend(endpoint(u), mount, joinPath(path, key))
+		if err != nil {
+			return nil, err
+		}
+		if stateURL := queryValue(u, "terraform-state"); stateURL != "" {
+			state, err := NewBackend(stateURL, key)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := state.(*S3Backend); !ok {
+				return nil, fmt.Errorf("invalid config backend %q: the terraform state can only be kept in an s3:// backend", backendURL)
+			}
+			vault.state = state
+		}
+		return vault, nil
+	case "s3", "s3+http":
+		bucket, path := splitPath(u.Path)
+		if u.Host == "" || bucket == "" {
+			return nil, fmt.Errorf("invalid config backend %q: expected s3://host:port/bucket[/path]", backendURL)
+		}
+		return NewS3Backend(endpoint(u), bucket, joinPath(path, key))
+	}
+	return nil, fmt.Errorf("invalid config backend %q: unknown scheme %q", backendURL, u.Scheme)
+}
+
+// endpoint returns the https URL of the backend's host, or http for the +http schemes
+func endpoint(u *url.URL) string {
+	if strings.HasSuffix(u.Scheme, "+http") {
+		return "http://" + u.Host
+	}
+	return "https://" + u.Host
+}
+
+// queryValue returns the value of key in the query of u. Unlike url.Values it keeps a + as it is,
+// since the value is itself a backend URL such as s3+http://
+func queryValue(u *url.URL, key string) string {
+	for _, param := range strings.Split(u.RawQuery, "&") {
+		if strings.HasPrefix(param, key+"=") {
+			value, err := url.PathUnescape(strings.TrimPrefix(param, key+"="))
+			if err != nil {
+				return ""
+			}
+			return value
+		}
+	}
+	return ""
+}
+
+func splitPath(path string) (string, string) {
+	parts := strings.SplitN(strings.Trim(path, "/"), "/", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+func joinPath(elems ...string) string {
+	var parts []string
+	for _, e := range elems {
+		if e = strings.TrimSuffix(e, "/"); e != "" {
+			parts = append(parts, e)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// bucketBackend stores files in the IaaS config bucket, which concourse-up creates for each deployment
+type bucketBackend struct {
+	provider iaas.Provider
+	bucket   string
+}
+
+func (b bucketBackend) Exists(filename string) (bool, error) {
+	return b.provider.HasFile(b.bucket, filename)
+}
+
+func (b bucketBackend) Read(filename string) ([]byte, error) {
+	return b.provider.LoadFile(b.bucket, filename)
+}
+
+func (b bucketBackend) Write(filename string, contents []byte) error {
+	return b.provider.WriteFile(b.bucket, filename, contents)
+}
+
+func (b bucketBackend) Create(filename string, contents []byte) error {
+	return b.provider.CreateFile(b.bucket, filename, contents)
+}
+
+func (b bucketBackend) Delete(filename string) error {
+	return b.provider.DeleteFile(b.bucket, filename)
+}
+
+func (b bucketBackend) DeleteAll() error {
+	return b.provider.DeleteVersionedBucket(b.bucket)
+}
+
+func (b bucketBackend) TerraformBackend() *terraform.Backend {
+	return nil
+}
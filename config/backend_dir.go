@@ -0,0 +1,89 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/terraform"
+)
+
+// DirBackend stores files in a local directory, e.g. for integration tests
+type DirBackend struct {
+	root string
+}
+
+// NewDirBackend returns a DirBackend rooted at root, which is created when the first file is written
+func NewDirBackend(root string) *DirBackend {
+	return &DirBackend{root}
+}
+
+// Exists returns true if filename exists
+func (d *DirBackend) Exists(filename string) (bool, error) {
+	_, err := os.Stat(d.path(filename))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Read returns the contents of filename
+func (d *DirBackend) Read(filename string) ([]byte, error) {
+	return ioutil.ReadFile(d.path(filename))
+}
+
+// Write creates or replaces filename, which is only readable by the current user
+func (d *DirBackend) Write(filename string, contents []byte) error {
+	path := d.path(filename)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, contents, 0600)
+}
+
+// Create writes filename unless it exists, which is checked as the file is opened
+func (d *DirBackend) Create(filename string, contents []byte) error {
+	path := d.path(filename)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if os.IsExist(err) {
+		return iaas.ErrFileExists
+	}
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(contents); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Delete removes filename
+func (d *DirBackend) Delete(filename string) error {
+	return os.Remove(d.path(filename))
+}
+
+// DeleteAll removes the directory
+func (d *DirBackend) DeleteAll() error {
+	return os.RemoveAll(d.root)
+}
+
+// TerraformBackend keeps the terraform state in the directory
+func (d *DirBackend) TerraformBackend() *terraform.Backend {
+	path, err := filepath.Abs(d.path(terraformStateFileName))
+	if err != nil {
+		path = d.path(terraformStateFileName)
+	}
+	return &terraform.Backend{
+		Type:   "local",
+		Config: map[string]string{"path": path},
+	}
+}
+
+func (d *DirBackend) path(filename string) string {
+	return filepath.Join(d.root, filepath.FromSlash(filename))
+}
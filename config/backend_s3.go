@@ -0,0 +1,144 @@
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/terraform"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// Credentials for an S3-compatible backend, so that they can differ from the AWS credentials used to deploy
+const (
+	S3AccessKeyIDEnvVar     = "CONCOURSE_UP_S3_ACCESS_KEY_ID"
+	S3SecretAccessKeyEnvVar = "CONCOURSE_UP_S3_SECRET_ACCESS_KEY"
+	S3RegionEnvVar          = "CONCOURSE_UP_S3_REGION"
+)
+
+// S3Backend stores files under a prefix in an existing bucket on an S3-compatible endpoint such as MinIO
+type S3Backend struct {
+	client   *s3.S3
+	bucket   string
+	prefix   string
+	endpoint string
+	region   string
+}
+
+// NewS3Backend returns an S3Backend storing files under prefix in bucket
+func NewS3Backend(endpoint, bucket, prefix string) (*S3Backend, error) {
+	region := os.Getenv(S3RegionEnvVar)
+	if region == "" {
+		region = "us-east-1"
+	}
+	awsConfig := &aws.Config{
+		Endpoint:         aws.String(endpoint),
+		Region:           aws.String(region),
+		S3ForcePathStyle: aws.Bool(true),
+	}
+	if accessKeyID := os.Getenv(S3AccessKeyIDEnvVar); accessKeyID != "" {
+		awsConfig.Credentials = credentials.NewStaticCredentials(accessKeyID, os.Getenv(S3SecretAccessKeyEnvVar), "")
+	}
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &S3Backend{s3.New(sess), bucket, prefix, endpoint, region}, nil
+}
+
+// TerraformBackend keeps the terraform state under the prefix, with the same endpoint and credentials. Terraform reads
+// the credentials from the AWS environment variables, which its AWS provider reads too, so an AWS deployment with an
+// S3-compatible backend uses the same credentials for both
+func (b *S3Backend) TerraformBackend() *terraform.Backend {
+	backend := &terraform.Backend{
+		Type: "s3",
+		Config: map[string]string{
+			"bucket":                      b.bucket,
+			"key":                         joinPath(b.prefix, terraformStateFileName),
+			"region":                      b.region,
+			"endpoint":                    b.endpoint,
+			"force_path_style":            "true",
+			"skip_credentials_validation": "true",
+			"skip_region_validation":      "true",
+		},
+	}
+	if accessKeyID := os.Getenv(S3AccessKeyIDEnvVar); accessKeyID != "" {
+		backend.Env = map[string]string{
+			"AWS_ACCESS_KEY_ID":     accessKeyID,
+			"AWS_SECRET_ACCESS_KEY": os.Getenv(S3SecretAccessKeyEnvVar),
+		}
+	}
+	return backend
+}
+
+// Exists returns true if filename exists
+func (b *S3Backend) Exists(filename string) (bool, error) {
+	_, err := b.client.HeadObject(&s3.HeadObjectInput{Bucket: &b.bucket, Key: b.key(filename)})
+	if aerr, ok := err.(awserr.Error); ok && (aerr.Code() == "NotFound" || aerr.Code() == s3.ErrCodeNoSuchKey) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Read returns the contents of filename
+func (b *S3Backend) Read(filename string) ([]byte, error) {
+	output, err := b.client.GetObject(&s3.GetObjectInput{Bucket: &b.bucket, Key: b.key(filename)})
+	if err != nil {
+		return nil, err
+	}
+	defer output.Body.Close()
+	return ioutil.ReadAll(output.Body)
+}
+
+// Write creates or replaces filename
+func (b *S3Backend) Write(filename string, contents []byte) error {
+	_, err := b.client.PutObject(&s3.PutObjectInput{
+		Bucket: &b.bucket,
+		Key:    b.key(filename),
+		Body:   bytes.NewReader(contents),
+	})
+	return err
+}
+
+// Create writes filename unless it exists, on endpoints which support conditional writes
+func (b *S3Backend) Create(filename string, contents []byte) error {
+	return iaas.CreateS3Object(b.client, b.bucket, *b.key(filename), contents)
+}
+
+// Delete removes filename
+func (b *S3Backend) Delete(filename string) error {
+	_, err := b.client.DeleteObject(&s3.DeleteObjectInput{Bucket: &b.bucket, Key: b.key(filename)})
+	return err
+}
+
+// DeleteAll removes every file under the prefix, leaving the bucket in place
+func (b *S3Backend) DeleteAll() error {
+	var keys []*string
+	err := b.client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: &b.bucket,
+		Prefix: aws.String(b.prefix + "/"),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			keys = append(keys, object.Key)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if _, err = b.client.DeleteObject(&s3.DeleteObjectInput{Bucket: &b.bucket, Key: key}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *S3Backend) key(filename string) *string {
+	return aws.String(joinPath(b.prefix, filename))
+}
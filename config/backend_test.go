@@ -0,0 +1,348 @@
+package config_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	. "github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/config/configfakes"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/iaas/iaasfakes"
+	"github.com/EngineerBetter/concourse-up/terraform"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Backends", func() {
+	Describe("NewBackend", func() {
+		BeforeEach(func() {
+			os.Setenv(VaultTokenEnvVar, "s.token")
+		})
+
+		AfterEach(func() {
+			os.Unsetenv(VaultTokenEnvVar)
+		})
+
+		It("uses the config bucket by default", func() {
+			for _, backendURL := range []string{"", "bucket"} {
+				backend, err := NewBackend(backendURL, "concourse-up-test-eu-west-1-config")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(backend).To(BeNil())
+			}
+		})
+
+		It("parses each kind of backend", func() {
+			backend, err := NewBackend("dir:///var/concourse-up", "key")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(backend).To(BeAssignableToTypeOf(&DirBackend{}))
+
+			backend, err = NewBackend("vault://vault.example.com:8200/secret/ci", "key")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(backend).To(BeAssignableToTypeOf(&VaultBackend{}))
+
+			backend, err = NewBackend("s3+http://minio.local:9000/configs", "key")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(backend).To(BeAssignableToTypeOf(&S3Backend{}))
+		})
+
+		It("rejects incomplete URLs", func() {
+			_, err := NewBackend("vault://vault.example.com:8200", "key")
+			Expect(err).To(MatchError(ContainSubstring("expected vault://host:port/mount[/path]")))
+			_, err = NewBackend("s3:///configs", "key")
+			Expect(err).To(MatchError(ContainSubstring("expected s3://host:port/bucket[/path]")))
+			_, err = NewBackend("ftp://example.com/configs", "key")
+			Expect(err).To(MatchError(ContainSubstring(`unknown scheme "ftp"`)))
+		})
+
+		It("requires a Vault token", func() {
+			os.Unsetenv(VaultTokenEnvVar)
+			_, err := NewBackend("vault://vault.example.com:8200/secret", "key")
+			Expect(err).To(MatchError("VAULT_TOKEN must be set to store config in Vault"))
+		})
+
+		It("keeps the terraform state of Vault in the bucket or an s3 backend", func() {
+			backend, err := NewBackend("vault://vault.example.com:8200/secret/ci", "concourse-up-test-eu-west-1-config")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(backend.TerraformBackend()).To(BeNil())
+
+			backend, err = NewBackend("vault://vault.example.com:8200/secret/ci?terraform-state=s3+http://minio.local:9000/states", "concourse-up-test-eu-west-1-config")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(backend.TerraformBackend().Type).To(Equal("s3"))
+			Expect(backend.TerraformBackend().Config).To(HaveKeyWithValue("key", "concourse-up-test-eu-west-1-config/terraform.tfstate"))
+
+			_, err = NewBackend("vault://vault.example.com:8200/secret/ci?terraform-state=dir:///var/concourse-up", "key")
+			Expect(err).To(MatchError(ContainSubstring("the terraform state can only be kept in an s3:// backend")))
+		})
+	})
+
+	Describe("DirBackend", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "config-backend")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("stores files under the deployment's key", func() {
+			backend, err := NewBackend("dir://"+dir, "concourse-up-test-eu-west-1-config")
+			Expect(err).ToNot(HaveOccurred())
+
+			exists, err := backend.Exists("backups/one.tar.gz")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(exists).To(BeFalse())
+
+			Expect(backend.Write("backups/one.tar.gz", []byte("archive"))).To(Succeed())
+			Expect(filepath.Join(dir, "concourse-up-test-eu-west-1-config", "backups", "one.tar.gz")).To(BeAnExistingFile())
+			contents, err := backend.Read("backups/one.tar.gz")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal("archive"))
+
+			Expect(backend.DeleteAll()).To(Succeed())
+			Expect(filepath.Join(dir, "concourse-up-test-eu-west-1-config")).ToNot(BeADirectory())
+		})
+
+		It("creates a file only if it does not exist", func() {
+			backend, err := NewBackend("dir://"+dir, "concourse-up-test-eu-west-1-config")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(backend.Create("deployment.lock", []byte("mine"))).To(Succeed())
+			Expect(backend.Create("deployment.lock", []byte("theirs"))).To(Equal(iaas.ErrFileExists))
+			contents, err := backend.Read("deployment.lock")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal("mine"))
+		})
+
+		It("has terraform keep its state in the directory", func() {
+			backend, err := NewBackend("dir://"+dir, "concourse-up-test-eu-west-1-config")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(backend.TerraformBackend()).To(Equal(&terraform.Backend{
+				Type:   "local",
+				Config: map[string]string{"path": filepath.Join(dir, "concourse-up-test-eu-west-1-config", "terraform.tfstate")},
+			}))
+		})
+	})
+
+	Describe("S3Backend", func() {
+		AfterEach(func() {
+			os.Unsetenv(S3AccessKeyIDEnvVar)
+			os.Unsetenv(S3SecretAccessKeyEnvVar)
+		})
+
+		It("has terraform keep its state under the prefix, passing the credentials in its environment", func() {
+			os.Setenv(S3AccessKeyIDEnvVar, "access")
+			os.Setenv(S3SecretAccessKeyEnvVar, "secret")
+			backend, err := NewBackend("s3+http://minio.local:9000/configs/ci", "concourse-up-test-eu-west-1-config")
+			Expect(err).ToNot(HaveOccurred())
+
+			tfBackend := backend.TerraformBackend()
+			Expect(tfBackend.Type).To(Equal("s3"))
+			Expect(tfBackend.Config).To(HaveKeyWithValue("bucket", "configs"))
+			Expect(tfBackend.Config).To(HaveKeyWithValue("key", "ci/concourse-up-test-eu-west-1-config/terraform.tfstate"))
+			Expect(tfBackend.Config).To(HaveKeyWithValue("endpoint", "http://minio.local:9000"))
+			Expect(tfBackend.Env).To(Equal(map[string]string{"AWS_ACCESS_KEY_ID": "access", "AWS_SECRET_ACCESS_KEY": "secret"}))
+		})
+	})
+
+	Describe("VaultBackend", func() {
+		var server *httptest.Server
+		var secrets map[string]string
+
+		BeforeEach(func() {
+			secrets = map[string]string{}
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.Header.Get("X-Vault-Token")).To(Equal("s.token"))
+				switch {
+				case strings.HasPrefix(r.URL.Path, "/v1/secret/data/"):
+					path := strings.TrimPrefix(r.URL.Path, "/v1/secret/data/")
+					if r.Method == http.MethodPost {
+						body, _ := ioutil.ReadAll(r.Body)
+						var write struct {
+							Options map[string]int `json:"options"`
+						}
+						Expect(json.Unmarshal(body, &write)).To(Succeed())
+						if cas, ok := write.Options["cas"]; ok && cas == 0 && secrets[path] != "" {
+							w.WriteHeader(http.StatusBadRequest)
+							w.Write([]byte(`{"errors":["check-and-set parameter did not match the current version"]}`))
+							return
+						}
+						secrets[path] = string(body)
+						return
+					}
+					secret, ok := secrets[path]
+					if !ok {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					// The stored request body has the same shape as the data in the response
+					w.Write([]byte(`{"data":` + secret + `}`))
+				case strings.HasPrefix(r.URL.Path, "/v1/secret/metadata/"):
+					path := strings.TrimPrefix(r.URL.Path, "/v1/secret/metadata/")
+					if r.Method == http.MethodDelete {
+						delete(secrets, path)
+						return
+					}
+					Expect(r.Method).To(Equal("LIST"))
+					keys := map[string]bool{}
+					for secretPath := range secrets {
+						if strings.HasPrefix(secretPath, path+"/") {
+							rest := strings.TrimPrefix(secretPath, path+"/")
+							if i := strings.Index(rest, "/"); i != -1 {
+								rest = rest[:i+1]
+							}
+							keys[rest] = true
+						}
+					}
+					if len(keys) == 0 {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					var list []string
+					for key := range keys {
+						list = append(list, key)
+					}
+					sort.Strings(list)
+					json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"keys": list}})
+				default:
+					w.WriteHeader(http.StatusBadRequest)
+				}
+			}))
+			os.Setenv(VaultTokenEnvVar, "s.token")
+		})
+
+		AfterEach(func() {
+			server.Close()
+			os.Unsetenv(VaultTokenEnvVar)
+		})
+
+		It("stores each file as a secret", func() {
+			backend, err := NewBackend(strings.Replace(server.URL, "http://", "vault+http://", 1)+"/secret/ci", "concourse-up-test")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(backend.Write("config.json", []byte(`{"deployment":"concourse-up-test"}`))).To(Succeed())
+			Expect(backend.Write("backups/one.tar.gz", []byte("archive"))).To(Succeed())
+			Expect(secrets).To(HaveKey("ci/concourse-up-test/config.json"))
+
+			exists, err := backend.Exists("config.json")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(exists).To(BeTrue())
+			contents, err := backend.Read("config.json")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal(`{"deployment":"concourse-up-test"}`))
+
+			exists, err = backend.Exists("director-creds.yml")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(exists).To(BeFalse())
+
+			Expect(backend.DeleteAll()).To(Succeed())
+			Expect(secrets).To(BeEmpty())
+		})
+
+		It("creates a secret only if it has no version", func() {
+			backend, err := NewBackend(strings.Replace(server.URL, "http://", "vault+http://", 1)+"/secret/ci", "concourse-up-test")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(backend.Create("deployment.lock", []byte("mine"))).To(Succeed())
+			Expect(backend.Create("deployment.lock", []byte("theirs"))).To(Equal(iaas.ErrFileExists))
+			contents, err := backend.Read("deployment.lock")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal("mine"))
+
+			Expect(backend.Delete("deployment.lock")).To(Succeed())
+			Expect(backend.Create("deployment.lock", []byte("theirs"))).To(Succeed())
+		})
+	})
+
+	Describe("Client", func() {
+		It("stores files and the terraform state in the backend, and leaves the bucket alone", func() {
+			provider := &iaasfakes.FakeProvider{}
+			backend := &configfakes.FakeBackend{}
+			backend.ExistsReturns(false, nil)
+			client := &Client{Iaas: provider, BucketName: "concourse-up-test-eu-west-1-config", Backend: backend}
+
+			Expect(client.StoreAsset("director-creds.yml", []byte("creds"))).To(Succeed())
+			Expect(backend.WriteCallCount()).To(Equal(1))
+			Expect(provider.WriteFileCallCount()).To(BeZero())
+
+			backend.ExistsReturns(true, nil)
+			backend.ReadReturns([]byte(`{"version": 3}`), nil)
+			state, err := client.LoadTerraformState(Config{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(state)).To(Equal(`{"version": 3}`))
+			Expect(backend.ReadArgsForCall(0)).To(Equal("terraform.tfstate"))
+			Expect(provider.LoadFileCallCount()).To(BeZero())
+
+			Expect(client.EnableVersioning()).To(Succeed())
+			Expect(provider.EnableVersioningCallCount()).To(BeZero())
+
+			Expect(client.DeleteAll(Config{ConfigBucket: "concourse-up-test-eu-west-1-config"})).To(Succeed())
+			Expect(backend.DeleteAllCallCount()).To(Equal(1))
+			Expect(provider.DeleteVersionedBucketCallCount()).To(BeZero())
+		})
+
+		It("does not create the config bucket for another backend", func() {
+			dir, err := ioutil.TempDir("", "config-backend")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+			provider := &iaasfakes.FakeProvider{}
+			provider.RegionReturns("eu-west-1")
+
+			client, err := NewWithBackend(provider, "test", "", "dir://"+dir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.BucketName).To(Equal("concourse-up-test-eu-west-1-config"))
+			Expect(client.Backend).To(BeAssignableToTypeOf(&DirBackend{}))
+			Expect(provider.CreateBucketCallCount()).To(BeZero())
+		})
+
+		It("creates the config bucket for the terraform state of a Vault backend, and deletes it with the rest", func() {
+			os.Setenv(VaultTokenEnvVar, "s.token")
+			defer os.Unsetenv(VaultTokenEnvVar)
+			provider := &iaasfakes.FakeProvider{}
+			provider.RegionReturns("eu-west-1")
+
+			client, err := NewWithBackend(provider, "test", "", "vault://vault.example.com:8200/secret/ci")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.Backend).To(BeAssignableToTypeOf(&VaultBackend{}))
+			Expect(provider.CreateBucketArgsForCall(0)).To(Equal("concourse-up-test-eu-west-1-config"))
+
+			provider.HasFileReturns(true, nil)
+			provider.LoadFileReturns([]byte(`{"version": 3}`), nil)
+			state, err := client.LoadTerraformState(Config{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(state)).To(Equal(`{"version": 3}`))
+			bucket, _ := provider.LoadFileArgsForCall(0)
+			Expect(bucket).To(Equal("concourse-up-test-eu-west-1-config"))
+		})
+
+		It("gives the self-update pipeline the backend and its credentials, unless it cannot reach it", func() {
+			os.Setenv(S3AccessKeyIDEnvVar, "access")
+			defer os.Unsetenv(S3AccessKeyIDEnvVar)
+			provider := &iaasfakes.FakeProvider{}
+			provider.RegionReturns("eu-west-1")
+
+			client := &Client{Iaas: provider, Backend: &configfakes.FakeBackend{}, BackendURL: "s3://minio.local:9000/configs"}
+			env, err := client.PipelineEnv()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(env).To(Equal(map[string]string{
+				BackendEnvVar:       "s3://minio.local:9000/configs",
+				S3AccessKeyIDEnvVar: "access",
+			}))
+
+			client, err = NewWithBackend(provider, "test", "", "dir:///var/concourse-up")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = client.PipelineEnv()
+			Expect(err).To(Equal(ErrBackendUnreachable))
+		})
+	})
+})
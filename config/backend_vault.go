@@ -0,0 +1,207 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/terraform"
+)
+
+// VaultTokenEnvVar holds the token used to reach Vault
+const VaultTokenEnvVar = "VAULT_TOKEN"
+
+// VaultBackend stores each file as a secret in a Vault KV version 2 secrets engine. Terraform cannot keep
+// its state in Vault, so it is kept in the config bucket, or in an S3-compatible backend when one is given
+type VaultBackend struct {
+	address    string
+	mount      string
+	path       string
+	token      string
+	httpClient *http.Client
+	state      Backend
+}
+
+// NewVaultBackend returns a VaultBackend storing files under path in the KV engine mounted at mount
+func NewVaultBackend(address, mount, path string) (*VaultBackend, error) {
+	token := os.Getenv(VaultTokenEnvVar)
+	if token == "" {
+		return nil, fmt.Errorf("%s must be set to store config in Vault", VaultTokenEnvVar)
+	}
+	return &VaultBackend{
+		address:    strings.TrimSuffix(address, "/"),
+		mount:      mount,
+		path:       path,
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// TerraformBackend keeps the terraform state in the backend given for it, or returns nil to keep it in the config bucket
+func (v *VaultBackend) TerraformBackend() *terraform.Backend {
+	if v.state == nil {
+		return nil
+	}
+	return v.state.TerraformBackend()
+}
+
+// StateBackend returns the backend the terraform state is kept in, or nil for the config bucket
+func (v *VaultBackend) StateBackend() Backend {
+	return v.state
+}
+
+type vaultSecret struct {
+	Data struct {
+		Data struct {
+			Contents []byte `json:"contents"`
+		} `json:"data"`
+		Keys []string `json:"keys"`
+	} `json:"data"`
+}
+
+// Exists returns true if filename exists
+func (v *VaultBackend) Exists(filename string) (bool, error) {
+	_, found, err := v.read(filename)
+	return found, err
+}
+
+// Read returns the contents of filename
+func (v *VaultBackend) Read(filename string) ([]byte, error) {
+	contents, found, err := v.read(filename)
+	if err == nil && !found {
+		err = fmt.Errorf("%s not found in Vault at %s", filename, v.url("data", filename))
+	}
+	return contents, err
+}
+
+func (v *VaultBackend) read(filename string) ([]byte, bool, error) {
+	var secret vaultSecret
+	found, err := v.do(http.MethodGet, v.url("data", filename), nil, &secret)
+	return secret.Data.Data.Contents, found, err
+}
+
+// Write creates a new version of filename
+func (v *VaultBackend) Write(filename string, contents []byte) error {
+	body := map[string]interface{}{
+		"data": map[string][]byte{"contents": contents},
+	}
+	_, err := v.do(http.MethodPost, v.url("data", filename), body, nil)
+	return err
+}
+
+// Create writes the first version of filename, using check-and-set so that it fails if there is a version already
+func (v *VaultBackend) Create(filename string, contents []byte) error {
+	body := map[string]interface{}{
+		"options": map[string]int{"cas": 0},
+		"data":    map[string][]byte{"contents": contents},
+	}
+	_, err := v.do(http.MethodPost, v.url("data", filename), body, nil)
+	if vaultErr, ok := err.(*vaultError); ok && vaultErr.StatusCode == http.StatusBadRequest && vaultErr.checkAndSetFailed() {
+		return iaas.ErrFileExists
+	}
+	return err
+}
+
+// Delete removes every version of filename
+func (v *VaultBackend) Delete(filename string) error {
+	_, err := v.do(http.MethodDelete, v.url("metadata", filename), nil, nil)
+	return err
+}
+
+// DeleteAll removes every file stored for the deployment
+func (v *VaultBackend) DeleteAll() error {
+	return v.deleteTree("")
+}
+
+func (v *VaultBackend) deleteTree(dir string) error {
+	var secret vaultSecret
+	found, err := v.do("LIST", v.url("metadata", dir), nil, &secret)
+	if err != nil || !found {
+		return err
+	}
+	for _, key := range secret.Data.Keys {
+		name := joinPath(dir, key)
+		if strings.HasSuffix(key, "/") {
+			err = v.deleteTree(name)
+		} else {
+			err = v.Delete(name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *VaultBackend) url(kind, filename string) string {
+	return fmt.Sprintf("%s/v1/%s/%s/%s", v.address, v.mount, kind, joinPath(v.path, filename))
+}
+
+// do returns false if Vault has nothing at url
+func (v *VaultBackend) do(method, url string, input, output interface{}) (bool, error) {
+	var body io.Reader
+	if input != nil {
+		contents, err := json.Marshal(input)
+		if err != nil {
+			return false, err
+		}
+		body = bytes.NewReader(contents)
+	}
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.StatusCode >= 300:
+		vaultErr := &vaultError{Method: method, URL: url, StatusCode: resp.StatusCode, Status: resp.Status}
+		json.Unmarshal(respBody, vaultErr)
+		return false, vaultErr
+	case output != nil && len(respBody) != 0:
+		return true, json.Unmarshal(respBody, output)
+	}
+	return true, nil
+}
+
+// vaultError is the error returned by a Vault request which fails
+type vaultError struct {
+	Method     string   `json:"-"`
+	URL        string   `json:"-"`
+	StatusCode int      `json:"-"`
+	Status     string   `json:"-"`
+	Errors     []string `json:"errors"`
+}
+
+func (e *vaultError) Error() string {
+	return fmt.Sprintf("Vault %s %s failed: %s %s", e.Method, e.URL, e.Status, strings.Join(e.Errors, ", "))
+}
+
+// checkAndSetFailed returns true if the write was refused because the secret has a version already
+func (e *vaultError) checkAndSetFailed() bool {
+	for _, message := range e.Errors {
+		if strings.Contains(message, "check-and-set") {
+			return true
+		}
+	}
+	return false
+}
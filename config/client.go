@@ -4,22 +4,33 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/EngineerBetter/concourse-up/iaas"
+	"os"
+	"sync"
+	"time"
 )
 
 const terraformStateFileName = "terraform.tfstate"
 const configFilePath = "config.json"
 
+// SecretsFilename is where the passwords and keys in Config.Secrets are stored, apart from the rest of the config
+const SecretsFilename = "secrets.json"
+
 //go:generate counterfeiter . IClient
 type IClient interface {
 	Load() (Config, error)
+	LoadWithoutSecrets() (Config, error)
 	DeleteAll(config Config) error
 	Update(Config) error
 	StoreAsset(filename string, contents []byte) error
+	CreateAsset(filename string, contents []byte) error
 	HasAsset(filename string) (bool, error)
 	ConfigExists() (bool, error)
 	LoadAsset(filename string) ([]byte, error)
 	DeleteAsset(filename string) error
+	LoadTerraformState(conf Config) ([]byte, error)
 	NewConfig() Config
+	Encryption() (*Encryption, error)
+	PipelineEnv() (map[string]string, error)
 }
 
 // Client is a client for loading the config file  from S3
@@ -30,6 +41,17 @@ type Client struct {
 	BucketName   string
 	BucketExists bool
 	BucketError  error
+	// Backend stores the config and other files, or is nil to store them in the config bucket
+	Backend Backend
+	// BackendURL describes Backend, see NewBackend
+	BackendURL string
+
+	// encryptionMutex guards the encryption, as the deployment lock is refreshed while a command runs
+	encryptionMutex  sync.Mutex
+	encryption       *Encryption
+	encryptionLoaded bool
+	// plaintextChecked is set once the config bucket is known to hold no encrypted files, see checkPlaintext
+	plaintextChecked bool
 }
 
 // New instantiates a new client
@@ -42,45 +64,79 @@ func New(iaas iaas.Provider, project, namespace string) *Client {
 	}
 
 	return &Client{
-		iaas,
-		project,
-		namespace,
-		bucketName,
-		exists,
-		err,
+		Iaas:         iaas,
+		Project:      project,
+		Namespace:    namespace,
+		BucketName:   bucketName,
+		BucketExists: exists,
+		BucketError:  err,
+	}
+}
+
+// NewWithBackend instantiates a new client which stores files, and has terraform keep its state, in the backend
+// described by backendURL, see NewBackend. The config bucket is only created when it keeps the config or the state
+func NewWithBackend(iaas iaas.Provider, project, namespace, backendURL string) (*Client, error) {
+	if backendURL == "" || backendURL == "bucket" {
+		client := New(iaas, project, namespace)
+		if client.BucketError != nil {
+			return nil, client.BucketError
+		}
+		return client, nil
+	}
+
+	namespace = determineNamespace(namespace, iaas.Region())
+	bucketName, exists, err := determineBucketName(iaas, namespace, project)
+	if err != nil {
+		return nil, err
+	}
+	backend, err := NewBackend(backendURL, bucketName)
+	if err != nil {
+		return nil, err
+	}
+	client := &Client{
+		Iaas:         iaas,
+		Project:      project,
+		Namespace:    namespace,
+		BucketName:   bucketName,
+		BucketExists: exists,
+		Backend:      backend,
+		BackendURL:   backendURL,
+	}
+	if client.stateBackend() == nil && !exists {
+		if err = iaas.CreateBucket(bucketName); err != nil {
+			return nil, err
+		}
 	}
+	return client, nil
 }
 
 // StoreAsset stores an associated configuration file
 func (client *Client) StoreAsset(filename string, contents []byte) error {
-	return client.Iaas.WriteFile(client.configBucket(),
-		filename,
-		contents,
-	)
+	return client.write(filename, contents)
+}
+
+// CreateAsset stores an associated configuration file unless it exists, returning iaas.ErrFileExists if it does
+func (client *Client) CreateAsset(filename string, contents []byte) error {
+	contents, err := client.encrypt(contents)
+	if err != nil {
+		return err
+	}
+	return client.backend().Create(filename, contents)
 }
 
 // LoadAsset loads an associated configuration file
 func (client *Client) LoadAsset(filename string) ([]byte, error) {
-	return client.Iaas.LoadFile(
-		client.configBucket(),
-		filename,
-	)
+	return client.read(filename)
 }
 
 // DeleteAsset deletes an associated configuration file
 func (client *Client) DeleteAsset(filename string) error {
-	return client.Iaas.DeleteFile(
-		client.configBucket(),
-		filename,
-	)
+	return client.backend().Delete(filename)
 }
 
 // HasAsset returns true if an associated configuration file exists
 func (client *Client) HasAsset(filename string) (bool, error) {
-	return client.Iaas.HasFile(
-		client.configBucket(),
-		filename,
-	)
+	return client.backend().Exists(filename)
 }
 
 // ConfigExists returns true if the configuration file exists
@@ -89,51 +145,323 @@ func (client *Client) ConfigExists() (bool, error) {
 }
 
 // Update stores the conconcourse up config file to S3
+// The secrets are stored first, so that config.json is never newer than them
 func (client *Client) Update(config Config) error {
+	secretsBytes, err := json.Marshal(config.Secrets)
+	if err != nil {
+		return err
+	}
+	if err = client.write(SecretsFilename, secretsBytes); err != nil {
+		return err
+	}
+
 	bytes, err := json.Marshal(config)
 	if err != nil {
 		return err
 	}
 
-	return client.Iaas.WriteFile(client.configBucket(), configFilePath, bytes)
+	return client.write(configFilePath, bytes)
 }
 
-// DeleteAll deletes the entire configuration bucket
+// DeleteAll deletes everything in the backend and wherever it keeps the terraform state, or the entire configuration
+// bucket if that is the backend
 func (client *Client) DeleteAll(config Config) error {
+	if client.Backend == nil {
+		return client.Iaas.DeleteVersionedBucket(config.ConfigBucket)
+	}
+	if err := client.Backend.DeleteAll(); err != nil {
+		return err
+	}
+	if _, ok := client.Backend.(splitBackend); !ok {
+		return nil
+	}
+	if state := client.stateBackend(); state != nil {
+		return state.DeleteAll()
+	}
 	return client.Iaas.DeleteVersionedBucket(config.ConfigBucket)
 }
 
-// Load loads an existing config file from S3
+// Load loads an existing config file from S3, migrated in memory to the current schema version.
+// The stored config is left as it is, only MigrateConfig stores the migrated config
 func (client *Client) Load() (Config, error) {
+	conf, _, err := client.migrate(true, true)
+	return conf, err
+}
+
+// LoadWithoutSecrets loads the config like Load, but leaves its secrets empty without reading secrets.json,
+// so that it works for users who can only read the rest of the config
+func (client *Client) LoadWithoutSecrets() (Config, error) {
+	conf, _, err := client.migrate(true, false)
+	return conf, err
+}
+
+// MigrateConfig brings the stored config up to the current schema version, unless dryRun is set,
+// and describes the migrations that were or would be run. Callers which store it must hold the deployment lock
+func (client *Client) MigrateConfig(dryRun bool) (MigrationPlan, error) {
+	_, plan, err := client.migrate(dryRun, true)
+	return plan, err
+}
+
+// migrate loads the config and migrates it, storing the result unless dryRun is set. The secrets are only read
+// when withSecrets is set, which it must be for a config that is stored
+func (client *Client) migrate(dryRun, withSecrets bool) (Config, MigrationPlan, error) {
 	if client.BucketError != nil {
-		return Config{}, client.BucketError
+		return Config{}, MigrationPlan{}, client.BucketError
 	}
 
-	configBytes, err := client.Iaas.LoadFile(
-		client.configBucket(),
-		configFilePath,
-	)
+	configBytes, err := client.read(configFilePath)
 	if err != nil {
-		return Config{}, err
+		return Config{}, MigrationPlan{}, err
 	}
 
 	conf := Config{}
 	if err := json.Unmarshal(configBytes, &conf); err != nil {
-		return Config{}, err
+		return Config{}, MigrationPlan{}, err
+	}
+	if withSecrets {
+		if conf.Secrets, err = client.loadSecrets(configBytes); err != nil {
+			return Config{}, MigrationPlan{}, err
+		}
+	}
+
+	migrated, applied, err := Migrate(conf)
+	if err != nil {
+		return Config{}, MigrationPlan{}, err
+	}
+	plan := MigrationPlan{
+		From:       conf.SchemaVersion,
+		To:         migrated.SchemaVersion,
+		Migrations: applied,
+		Changes:    Diff(conf, migrated),
+	}
+	if !plan.Pending() || dryRun {
+		return migrated, plan, nil
 	}
 
-	return conf, nil
+	plan.Backup = fmt.Sprintf("%sconfig-schema-%d-%s.json", configBackupsPrefix, conf.SchemaVersion, time.Now().UTC().Format("20060102T150405Z"))
+	if err = client.write(plan.Backup, configBytes); err != nil {
+		return Config{}, MigrationPlan{}, fmt.Errorf("error backing up config before migrating it: [%v]", err)
+	}
+	if err = client.Update(migrated); err != nil {
+		return Config{}, MigrationPlan{}, fmt.Errorf("error storing migrated config: [%v]", err)
+	}
+	return migrated, plan, nil
+}
+
+// loadSecrets reads secrets.json, or the secrets in configBytes if the config was stored before they were moved out of it
+func (client *Client) loadSecrets(configBytes []byte) (Secrets, error) {
+	exists, err := client.HasAsset(SecretsFilename)
+	if err != nil {
+		return Secrets{}, err
+	}
+	if exists {
+		if configBytes, err = client.read(SecretsFilename); err != nil {
+			return Secrets{}, err
+		}
+	}
+
+	var secrets Secrets
+	if err = json.Unmarshal(configBytes, &secrets); err != nil {
+		return Secrets{}, fmt.Errorf("error parsing %s: [%v]", SecretsFilename, err)
+	}
+	return secrets, nil
+}
+
+// Encryption returns the key the config bucket is encrypted with, or nil if it is not encrypted
+func (client *Client) Encryption() (*Encryption, error) {
+	client.encryptionMutex.Lock()
+	defer client.encryptionMutex.Unlock()
+	if client.encryptionLoaded {
+		return client.encryption, nil
+	}
+
+	exists, err := client.HasAsset(EncryptionFilename)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		contents, err := client.backend().Read(EncryptionFilename)
+		if err != nil {
+			return nil, err
+		}
+		var encryption Encryption
+		if err = json.Unmarshal(contents, &encryption); err != nil {
+			return nil, fmt.Errorf("error parsing %s: [%v]", EncryptionFilename, err)
+		}
+		client.encryption = &encryption
+	}
+	client.encryptionLoaded = true
+	return client.encryption, nil
+}
+
+// PipelineEnv returns the environment the self-update pipeline needs to read and update the config: the backend
+// and its credentials, and the passphrase the config is encrypted with. It returns ErrBackendUnreachable when the
+// pipeline cannot reach the backend
+func (client *Client) PipelineEnv() (map[string]string, error) {
+	env := make(map[string]string)
+	if client.Backend != nil {
+		if _, ok := client.Backend.(*DirBackend); ok {
+			return nil, ErrBackendUnreachable
+		}
+		env[BackendEnvVar] = client.BackendURL
+		for _, name := range []string{VaultTokenEnvVar, S3AccessKeyIDEnvVar, S3SecretAccessKeyEnvVar, S3RegionEnvVar} {
+			if value := os.Getenv(name); value != "" {
+				env[name] = value
+			}
+		}
+	}
+
+	encryption, err := client.Encryption()
+	if err != nil {
+		return nil, err
+	}
+	if encryption != nil && encryption.Type == EncryptionPassphrase {
+		env[PassphraseEnvVar] = os.Getenv(PassphraseEnvVar)
+	}
+	return env, nil
+}
+
+// EnableEncryption encrypts every file written from now on with encryption, and rewrites the
+// config, the deployment lock and those of filenames which exist so that they are encrypted too.
+// Files that were encrypted with a different KMS key are re-encrypted with the new one.
+// Once it has run, reading any file which is not encrypted fails
+func (client *Client) EnableEncryption(encryption Encryption, filenames ...string) error {
+	if err := encryption.Validate(); err != nil {
+		return err
+	}
+
+	filenames = append([]string{configFilePath, SecretsFilename, LockFilename}, filenames...)
+	contents := make(map[string][]byte)
+	for _, filename := range filenames {
+		exists, err := client.HasAsset(filename)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		if contents[filename], err = client.read(filename); err != nil {
+			return fmt.Errorf("error reading %s: [%v]", filename, err)
+		}
+	}
+
+	encryptionBytes, err := json.Marshal(encryption)
+	if err != nil {
+		return err
+	}
+	if err = client.backend().Write(EncryptionFilename, encryptionBytes); err != nil {
+		return err
+	}
+	client.encryptionMutex.Lock()
+	client.encryption = &encryption
+	client.encryptionLoaded = true
+	client.encryptionMutex.Unlock()
+
+	for _, filename := range filenames {
+		if plaintext, ok := contents[filename]; ok {
+			if err = client.write(filename, plaintext); err != nil {
+				return fmt.Errorf("error encrypting %s: [%v]", filename, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (client *Client) write(filename string, contents []byte) error {
+	contents, err := client.encrypt(contents)
+	if err != nil {
+		return err
+	}
+	return client.backend().Write(filename, contents)
+}
+
+// encrypt returns contents encrypted as the deployment's files are, if they are
+func (client *Client) encrypt(contents []byte) ([]byte, error) {
+	encryption, err := client.Encryption()
+	if err != nil {
+		return nil, err
+	}
+	if encryption == nil {
+		if err = client.checkPlaintext(); err != nil {
+			return nil, err
+		}
+		return contents, nil
+	}
+	return encrypt(client.Iaas, *encryption, contents)
+}
+
+// checkPlaintext returns an error if the config or secrets are encrypted although encryption.json is missing,
+// rather than letting files be stored unencrypted beside them
+func (client *Client) checkPlaintext() error {
+	client.encryptionMutex.Lock()
+	defer client.encryptionMutex.Unlock()
+	if client.plaintextChecked {
+		return nil
+	}
+
+	for _, filename := range []string{configFilePath, SecretsFilename} {
+		exists, err := client.backend().Exists(filename)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		contents, err := client.backend().Read(filename)
+		if err != nil {
+			return err
+		}
+		if IsEncrypted(contents) {
+			return fmt.Errorf("%s is encrypted but %s is missing, so files would be stored unencrypted. Run `concourse-up config encrypt` to restore it", filename, EncryptionFilename)
+		}
+	}
+	client.plaintextChecked = true
+	return nil
+}
+
+// read returns filename decrypted, and fails if it is not encrypted in a config bucket that is
+func (client *Client) read(filename string) ([]byte, error) {
+	contents, err := client.backend().Read(filename)
+	if err != nil {
+		return nil, err
+	}
+	if !IsEncrypted(contents) {
+		encryption, err := client.Encryption()
+		if err != nil {
+			return nil, err
+		}
+		if encryption != nil {
+			return nil, fmt.Errorf("%s is not encrypted, but the config bucket is", filename)
+		}
+	}
+	return decrypt(client.Iaas, contents)
 }
 
 func (client *Client) NewConfig() Config {
 	return Config{
-		ConfigBucket: client.configBucket(),
-		Deployment:   deployment(client.Project),
-		Namespace:    client.Namespace,
-		Project:      client.Project,
-		Region:       client.Iaas.Region(),
-		TFStatePath:  terraformStateFileName,
+		ConfigBucket:  client.configBucket(),
+		Deployment:    deployment(client.Project),
+		Namespace:     client.Namespace,
+		Project:       client.Project,
+		Region:        client.Iaas.Region(),
+		SchemaVersion: CurrentSchemaVersion,
+		TFStatePath:   terraformStateFileName,
+	}
+}
+
+func (client *Client) backend() Backend {
+	if client.Backend != nil {
+		return client.Backend
+	}
+	return bucketBackend{client.Iaas, client.configBucket()}
+}
+
+// stateBackend returns the backend terraform keeps its state in, or nil for the config bucket
+func (client *Client) stateBackend() Backend {
+	if split, ok := client.Backend.(splitBackend); ok {
+		return split.StateBackend()
 	}
+	return client.Backend
 }
 
 func (client *Client) configBucket() string {
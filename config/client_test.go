@@ -182,7 +182,7 @@ func TestClient_Load(t *testing.T) {
 		return defaultContents, true, nil
 	}
 	provider.LoadFileStub = func(bucket, path string) ([]byte, error) {
-		bytes, _ := json.Marshal(Config{})
+		bytes, _ := json.Marshal(Config{SchemaVersion: CurrentSchemaVersion})
 		return bytes, nil
 	}
 
@@ -219,7 +219,7 @@ func TestClient_Load(t *testing.T) {
 					BucketError:  nil,
 				}
 			},
-			want:    Config{},
+			want:    Config{SchemaVersion: CurrentSchemaVersion},
 			wantErr: false,
 		},
 	}
@@ -247,7 +247,7 @@ func TestClient_HasConfig(t *testing.T) {
 		return defaultContents, true, nil
 	}
 	provider.LoadFileStub = func(bucket, path string) ([]byte, error) {
-		bytes, _ := json.Marshal(Config{})
+		bytes, _ := json.Marshal(Config{SchemaVersion: CurrentSchemaVersion})
 		return bytes, nil
 	}
 
@@ -284,7 +284,7 @@ func TestClient_HasConfig(t *testing.T) {
 					BucketError:  nil,
 				}
 			},
-			want:    Config{},
+			want:    Config{SchemaVersion: CurrentSchemaVersion},
 			wantErr: false,
 		},
 	}
@@ -302,3 +302,41 @@ func TestClient_HasConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_LoadWithoutSecrets(t *testing.T) {
+	configBytes, _ := json.Marshal(Config{Deployment: "concourse-up-test", SchemaVersion: CurrentSchemaVersion})
+	secretsBytes, _ := json.Marshal(Secrets{DirectorPassword: "s3cret"})
+	files := map[string][]byte{"config.json": configBytes, "secrets.json": secretsBytes}
+	var read []string
+
+	provider := &iaasfakes.FakeProvider{}
+	provider.RegionReturns("eu-west-1")
+	provider.HasFileStub = func(bucket, path string) (bool, error) {
+		_, ok := files[path]
+		return ok, nil
+	}
+	provider.LoadFileStub = func(bucket, path string) ([]byte, error) {
+		read = append(read, path)
+		return files[path], nil
+	}
+	client := &Client{Iaas: provider, Project: "test", BucketName: "concourse-up-test-eu-west-1-config"}
+
+	got, err := client.LoadWithoutSecrets()
+	if err != nil {
+		t.Fatalf("Client.LoadWithoutSecrets() error = %v", err)
+	}
+	if got.Deployment != "concourse-up-test" || got.Secrets != (Secrets{}) {
+		t.Errorf("Client.LoadWithoutSecrets() = %v, want the config without secrets", got)
+	}
+	if !reflect.DeepEqual(read, []string{"config.json"}) {
+		t.Errorf("Client.LoadWithoutSecrets() read %v, want only config.json", read)
+	}
+
+	got, err = client.Load()
+	if err != nil {
+		t.Fatalf("Client.Load() error = %v", err)
+	}
+	if got.DirectorPassword != "s3cret" {
+		t.Errorf("Client.Load() = %v, want the secrets", got)
+	}
+}
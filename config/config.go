@@ -1,56 +1,51 @@
 package config
 
 // Config represents a concourse-up configuration file
+// Fields tagged as settable can be changed with `config set`
 type Config struct {
-	AllowIPs                  string   `json:"allow_ips"`
+	// Secrets are stored apart from the rest of the config, in SecretsFilename
+	Secrets `json:"-"`
+
+	AllowIPs                  string   `json:"allow_ips" settable:"true"`
 	AvailabilityZone          string   `json:"availability_zone"`
 	ConcourseCACert           string   `json:"concourse_ca_cert"`
 	ConcourseCert             string   `json:"concourse_cert"`
-	ConcourseKey              string   `json:"concourse_key"`
-	ConcoursePassword         string   `json:"concourse_password"`
-	ConcourseUsername         string   `json:"concourse_username"`
+	ConcourseUsername         string   `json:"concourse_username" settable:"true"`
 	ConcourseUserProvidedCert bool     `json:"concourse_user_provided_cert"`
-	ConcourseWebSize          string   `json:"concourse_web_size"`
-	ConcourseWorkerCount      int      `json:"concourse_worker_count"`
-	ConcourseWorkerSize       string   `json:"concourse_worker_size"`
+	ConcourseWebSize          string   `json:"concourse_web_size" settable:"true"`
+	ConcourseWorkerCount      int      `json:"concourse_worker_count" settable:"true"`
+	ConcourseWorkerSize       string   `json:"concourse_worker_size" settable:"true"`
 	ConfigBucket              string   `json:"config_bucket"`
-	CredhubAdminClientSecret  string   `json:"credhub_admin_client_secret"`
 	CredhubCACert             string   `json:"credhub_ca_cert"`
-	CredhubPassword           string   `json:"credhub_password"`
 	CredhubURL                string   `json:"credhub_url"`
 	CredhubUsername           string   `json:"credhub_username"`
+	DBHighAvailability        bool     `json:"db_high_availability" settable:"true"`
 	Deployment                string   `json:"deployment"`
 	DirectorCACert            string   `json:"director_ca_cert"`
 	DirectorCert              string   `json:"director_cert"`
-	DirectorHMUserPassword    string   `json:"director_hm_user_password"`
-	DirectorKey               string   `json:"director_key"`
-	DirectorMbusPassword      string   `json:"director_mbus_password"`
-	DirectorNATSPassword      string   `json:"director_nats_password"`
-	DirectorPassword          string   `json:"director_password"`
 	DirectorPublicIP          string   `json:"director_public_ip"`
-	DirectorRegistryPassword  string   `json:"director_registry_password"`
 	DirectorUsername          string   `json:"director_username"`
 	Domain                    string   `json:"domain"`
-	EncryptionKey             string   `json:"encryption_key"`
 	GithubAuthIsSet           bool     `json:"github_auth_is_set"`
-	GithubClientID            string   `json:"github_client_id"`
-	GithubClientSecret        string   `json:"github_client_secret"`
-	GrafanaPassword           string   `json:"grafana_password"`
+	GithubClientID            string   `json:"github_client_id" settable:"true"`
 	HostedZoneID              string   `json:"hosted_zone_id"`
 	HostedZoneRecordPrefix    string   `json:"hosted_zone_record_prefix"`
 	IAAS                      string   `json:"iaas"`
+	JumpboxPublicIP           string   `json:"jumpbox_public_ip"`
+	LastDeployed              string   `json:"last_deployed"`
 	Namespace                 string   `json:"namespace"`
-	PrivateKey                string   `json:"private_key"`
+	NatGatewayIP              string   `json:"nat_gateway_ip"`
+	Private                   bool     `json:"private"`
 	Project                   string   `json:"project"`
 	PublicKey                 string   `json:"public_key"`
 	RDSDefaultDatabaseName    string   `json:"rds_default_database_name"`
-	RDSInstanceClass          string   `json:"rds_instance_class"`
-	RDSPassword               string   `json:"rds_password"`
+	RDSInstanceClass          string   `json:"rds_instance_class" settable:"true"`
 	RDSUsername               string   `json:"rds_username"`
 	Region                    string   `json:"region"`
+	SchemaVersion             int      `json:"schema_version"`
 	SourceAccessIP            string   `json:"source_access_ip"`
-	Spot                      bool     `json:"spot"`
-	Tags                      []string `json:"tags"`
+	Spot                      bool     `json:"spot" settable:"true"`
+	Tags                      []string `json:"tags" settable:"true"`
 	TFStatePath               string   `json:"tf_state_path"`
 	Version                   string   `json:"version"`
 	WorkerType                string   `json:"worker_type"`
@@ -59,4 +54,42 @@ type Config struct {
 	NetworkCIDR               string   `json:"network_cidr"`
 	RDS1CIDR                  string   `json:"rds1_cidr"`
 	RDS2CIDR                  string   `json:"rds2_cidr"`
+	// Zones are the availability zones workers are spread across, starting with AvailabilityZone
+	Zones []string `json:"zones"`
+	// ZonePrivateCIDRs are the ranges of the private subnets of Zones after the first, which uses PrivateCIDR.
+	// They are only used on AWS, where a subnet cannot span zones
+	ZonePrivateCIDRs []string `json:"zone_private_cidrs"`
+	// TerraformLockTable is the DynamoDB table terraform locks the state with, once deploy has created it
+	TerraformLockTable string `json:"terraform_lock_table"`
+	// VPCID, PublicSubnetID, PrivateSubnetID and RDSSubnetIDs are the existing AWS VPC and subnets the deployment
+	// was placed in. They are empty when it created its own
+	VPCID           string   `json:"vpc_id"`
+	PublicSubnetID  string   `json:"public_subnet_id"`
+	PrivateSubnetID string   `json:"private_subnet_id"`
+	RDSSubnetIDs    []string `json:"rds_subnet_ids"`
+	// Network, PublicSubnetwork and PrivateSubnetwork are the names of the existing GCP network and subnetworks
+	// the deployment was placed in
+	Network           string `json:"network"`
+	PublicSubnetwork  string `json:"public_subnetwork"`
+	PrivateSubnetwork string `json:"private_subnetwork"`
+}
+
+// Secrets are the passwords and keys of a deployment. They are kept in their own asset so that access
+// to them can be granted separately, and they are redacted whenever the config is reported
+type Secrets struct {
+	ConcourseKey             string `json:"concourse_key"`
+	ConcoursePassword        string `json:"concourse_password" settable:"true"`
+	CredhubAdminClientSecret string `json:"credhub_admin_client_secret"`
+	CredhubPassword          string `json:"credhub_password"`
+	DirectorHMUserPassword   string `json:"director_hm_user_password"`
+	DirectorKey              string `json:"director_key"`
+	DirectorMbusPassword     string `json:"director_mbus_password"`
+	DirectorNATSPassword     string `json:"director_nats_password"`
+	DirectorPassword         string `json:"director_password"`
+	DirectorRegistryPassword string `json:"director_registry_password"`
+	EncryptionKey            string `json:"encryption_key"`
+	GithubClientSecret       string `json:"github_client_secret" settable:"true"`
+	GrafanaPassword          string `json:"grafana_password"`
+	PrivateKey               string `json:"private_key"`
+	RDSPassword              string `json:"rds_password"`
 }
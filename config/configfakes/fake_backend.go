@@ -0,0 +1,555 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package configfakes
+
+import (
+	"sync"
+
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/terraform"
+)
+
+type FakeBackend struct {
+	CreateStub        func(string, []byte) error
+	createMutex       sync.RWMutex
+	createArgsForCall []struct {
+		arg1 string
+		arg2 []byte
+	}
+	createReturns struct {
+		result1 error
+	}
+	createReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteStub        func(string) error
+	deleteMutex       sync.RWMutex
+	deleteArgsForCall []struct {
+		arg1 string
+	}
+	deleteReturns struct {
+		result1 error
+	}
+	deleteReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteAllStub        func() error
+	deleteAllMutex       sync.RWMutex
+	deleteAllArgsForCall []struct {
+	}
+	deleteAllReturns struct {
+		result1 error
+	}
+	deleteAllReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ExistsStub        func(string) (bool, error)
+	existsMutex       sync.RWMutex
+	existsArgsForCall []struct {
+		arg1 string
+	}
+	existsReturns struct {
+		result1 bool
+		result2 error
+	}
+	existsReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	ReadStub        func(string) ([]byte, error)
+	readMutex       sync.RWMutex
+	readArgsForCall []struct {
+		arg1 string
+	}
+	readReturns struct {
+		result1 []byte
+		result2 error
+	}
+	readReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
+	TerraformBackendStub        func() *terraform.Backend
+	terraformBackendMutex       sync.RWMutex
+	terraformBackendArgsForCall []struct {
+	}
+	terraformBackendReturns struct {
+		result1 *terraform.Backend
+	}
+	terraformBackendReturnsOnCall map[int]struct {
+		result1 *terraform.Backend
+	}
+	WriteStub        func(string, []byte) error
+	writeMutex       sync.RWMutex
+	writeArgsForCall []struct {
+		arg1 string
+		arg2 []byte
+	}
+	writeReturns struct {
+		result1 error
+	}
+	writeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeBackend) Create(arg1 string, arg2 []byte) error {
+	var arg2Copy []byte
+	if arg2 != nil {
+		arg2Copy = make([]byte, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.createMutex.Lock()
+	ret, specificReturn := fake.createReturnsOnCall[len(fake.createArgsForCall)]
+	fake.createArgsForCall = append(fake.createArgsForCall, struct {
+		arg1 string
+		arg2 []byte
+	}{arg1, arg2Copy})
+	fake.recordInvocation("Create", []interface{}{arg1, arg2Copy})
+	fake.createMutex.Unlock()
+	if fake.CreateStub != nil {
+		return fake.CreateStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.createReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeBackend) CreateCallCount() int {
+	fake.createMutex.RLock()
+	defer fake.createMutex.RUnlock()
+	return len(fake.createArgsForCall)
+}
+
+func (fake *FakeBackend) CreateCalls(stub func(string, []byte) error) {
+	fake.createMutex.Lock()
+	defer fake.createMutex.Unlock()
+	fake.CreateStub = stub
+}
+
+func (fake *FakeBackend) CreateArgsForCall(i int) (string, []byte) {
+	fake.createMutex.RLock()
+	defer fake.createMutex.RUnlock()
+	argsForCall := fake.createArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeBackend) CreateReturns(result1 error) {
+	fake.createMutex.Lock()
+	defer fake.createMutex.Unlock()
+	fake.CreateStub = nil
+	fake.createReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeBackend) CreateReturnsOnCall(i int, result1 error) {
+	fake.createMutex.Lock()
+	defer fake.createMutex.Unlock()
+	fake.CreateStub = nil
+	if fake.createReturnsOnCall == nil {
+		fake.createReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.createReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeBackend) Delete(arg1 string) error {
+	fake.deleteMutex.Lock()
+	ret, specificReturn := fake.deleteReturnsOnCall[len(fake.deleteArgsForCall)]
+	fake.deleteArgsForCall = append(fake.deleteArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("Delete", []interface{}{arg1})
+	fake.deleteMutex.Unlock()
+	if fake.DeleteStub != nil {
+		return fake.DeleteStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.deleteReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeBackend) DeleteCallCount() int {
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	return len(fake.deleteArgsForCall)
+}
+
+func (fake *FakeBackend) DeleteCalls(stub func(string) error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
+	fake.DeleteStub = stub
+}
+
+func (fake *FakeBackend) DeleteArgsForCall(i int) string {
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	argsForCall := fake.deleteArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeBackend) DeleteReturns(result1 error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
+	fake.DeleteStub = nil
+	fake.deleteReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeBackend) DeleteReturnsOnCall(i int, result1 error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
+	fake.DeleteStub = nil
+	if fake.deleteReturnsOnCall == nil {
+		fake.deleteReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeBackend) DeleteAll() error {
+	fake.deleteAllMutex.Lock()
+	ret, specificReturn := fake.deleteAllReturnsOnCall[len(fake.deleteAllArgsForCall)]
+	fake.deleteAllArgsForCall = append(fake.deleteAllArgsForCall, struct {
+	}{})
+	fake.recordInvocation("DeleteAll", []interface{}{})
+	fake.deleteAllMutex.Unlock()
+	if fake.DeleteAllStub != nil {
+		return fake.DeleteAllStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.deleteAllReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeBackend) DeleteAllCallCount() int {
+	fake.deleteAllMutex.RLock()
+	defer fake.deleteAllMutex.RUnlock()
+	return len(fake.deleteAllArgsForCall)
+}
+
+func (fake *FakeBackend) DeleteAllCalls(stub func() error) {
+	fake.deleteAllMutex.Lock()
+	defer fake.deleteAllMutex.Unlock()
+	fake.DeleteAllStub = stub
+}
+
+func (fake *FakeBackend) DeleteAllReturns(result1 error) {
+	fake.deleteAllMutex.Lock()
+	defer fake.deleteAllMutex.Unlock()
+	fake.DeleteAllStub = nil
+	fake.deleteAllReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeBackend) DeleteAllReturnsOnCall(i int, result1 error) {
+	fake.deleteAllMutex.Lock()
+	defer fake.deleteAllMutex.Unlock()
+	fake.DeleteAllStub = nil
+	if fake.deleteAllReturnsOnCall == nil {
+		fake.deleteAllReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteAllReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeBackend) Exists(arg1 string) (bool, error) {
+	fake.existsMutex.Lock()
+	ret, specificReturn := fake.existsReturnsOnCall[len(fake.existsArgsForCall)]
+	fake.existsArgsForCall = append(fake.existsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("Exists", []interface{}{arg1})
+	fake.existsMutex.Unlock()
+	if fake.ExistsStub != nil {
+		return fake.ExistsStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.existsReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeBackend) ExistsCallCount() int {
+	fake.existsMutex.RLock()
+	defer fake.existsMutex.RUnlock()
+	return len(fake.existsArgsForCall)
+}
+
+func (fake *FakeBackend) ExistsCalls(stub func(string) (bool, error)) {
+	fake.existsMutex.Lock()
+	defer fake.existsMutex.Unlock()
+	fake.ExistsStub = stub
+}
+
+func (fake *FakeBackend) ExistsArgsForCall(i int) string {
+	fake.existsMutex.RLock()
+	defer fake.existsMutex.RUnlock()
+	argsForCall := fake.existsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeBackend) ExistsReturns(result1 bool, result2 error) {
+	fake.existsMutex.Lock()
+	defer fake.existsMutex.Unlock()
+	fake.ExistsStub = nil
+	fake.existsReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeBackend) ExistsReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.existsMutex.Lock()
+	defer fake.existsMutex.Unlock()
+	fake.ExistsStub = nil
+	if fake.existsReturnsOnCall == nil {
+		fake.existsReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.existsReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeBackend) Read(arg1 string) ([]byte, error) {
+	fake.readMutex.Lock()
+	ret, specificReturn := fake.readReturnsOnCall[len(fake.readArgsForCall)]
+	fake.readArgsForCall = append(fake.readArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("Read", []interface{}{arg1})
+	fake.readMutex.Unlock()
+	if fake.ReadStub != nil {
+		return fake.ReadStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.readReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeBackend) ReadCallCount() int {
+	fake.readMutex.RLock()
+	defer fake.readMutex.RUnlock()
+	return len(fake.readArgsForCall)
+}
+
+func (fake *FakeBackend) ReadCalls(stub func(string) ([]byte, error)) {
+	fake.readMutex.Lock()
+	defer fake.readMutex.Unlock()
+	fake.ReadStub = stub
+}
+
+func (fake *FakeBackend) ReadArgsForCall(i int) string {
+	fake.readMutex.RLock()
+	defer fake.readMutex.RUnlock()
+	argsForCall := fake.readArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeBackend) ReadReturns(result1 []byte, result2 error) {
+	fake.readMutex.Lock()
+	defer fake.readMutex.Unlock()
+	fake.ReadStub = nil
+	fake.readReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeBackend) ReadReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.readMutex.Lock()
+	defer fake.readMutex.Unlock()
+	fake.ReadStub = nil
+	if fake.readReturnsOnCall == nil {
+		fake.readReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.readReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeBackend) TerraformBackend() *terraform.Backend {
+	fake.terraformBackendMutex.Lock()
+	ret, specificReturn := fake.terraformBackendReturnsOnCall[len(fake.terraformBackendArgsForCall)]
+	fake.terraformBackendArgsForCall = append(fake.terraformBackendArgsForCall, struct {
+	}{})
+	fake.recordInvocation("TerraformBackend", []interface{}{})
+	fake.terraformBackendMutex.Unlock()
+	if fake.TerraformBackendStub != nil {
+		return fake.TerraformBackendStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.terraformBackendReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeBackend) TerraformBackendCallCount() int {
+	fake.terraformBackendMutex.RLock()
+	defer fake.terraformBackendMutex.RUnlock()
+	return len(fake.terraformBackendArgsForCall)
+}
+
+func (fake *FakeBackend) TerraformBackendCalls(stub func() *terraform.Backend) {
+	fake.terraformBackendMutex.Lock()
+	defer fake.terraformBackendMutex.Unlock()
+	fake.TerraformBackendStub = stub
+}
+
+func (fake *FakeBackend) TerraformBackendReturns(result1 *terraform.Backend) {
+	fake.terraformBackendMutex.Lock()
+	defer fake.terraformBackendMutex.Unlock()
+	fake.TerraformBackendStub = nil
+	fake.terraformBackendReturns = struct {
+		result1 *terraform.Backend
+	}{result1}
+}
+
+func (fake *FakeBackend) TerraformBackendReturnsOnCall(i int, result1 *terraform.Backend) {
+	fake.terraformBackendMutex.Lock()
+	defer fake.terraformBackendMutex.Unlock()
+	fake.TerraformBackendStub = nil
+	if fake.terraformBackendReturnsOnCall == nil {
+		fake.terraformBackendReturnsOnCall = make(map[int]struct {
+			result1 *terraform.Backend
+		})
+	}
+	fake.terraformBackendReturnsOnCall[i] = struct {
+		result1 *terraform.Backend
+	}{result1}
+}
+
+func (fake *FakeBackend) Write(arg1 string, arg2 []byte) error {
+	var arg2Copy []byte
+	if arg2 != nil {
+		arg2Copy = make([]byte, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.writeMutex.Lock()
+	ret, specificReturn := fake.writeReturnsOnCall[len(fake.writeArgsForCall)]
+	fake.writeArgsForCall = append(fake.writeArgsForCall, struct {
+		arg1 string
+		arg2 []byte
+	}{arg1, arg2Copy})
+	fake.recordInvocation("Write", []interface{}{arg1, arg2Copy})
+	fake.writeMutex.Unlock()
+	if fake.WriteStub != nil {
+		return fake.WriteStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.writeReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeBackend) WriteCallCount() int {
+	fake.writeMutex.RLock()
+	defer fake.writeMutex.RUnlock()
+	return len(fake.writeArgsForCall)
+}
+
+func (fake *FakeBackend) WriteCalls(stub func(string, []byte) error) {
+	fake.writeMutex.Lock()
+	defer fake.writeMutex.Unlock()
+	fake.WriteStub = stub
+}
+
+func (fake *FakeBackend) WriteArgsForCall(i int) (string, []byte) {
+	fake.writeMutex.RLock()
+	defer fake.writeMutex.RUnlock()
+	argsForCall := fake.writeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeBackend) WriteReturns(result1 error) {
+	fake.writeMutex.Lock()
+	defer fake.writeMutex.Unlock()
+	fake.WriteStub = nil
+	fake.writeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeBackend) WriteReturnsOnCall(i int, result1 error) {
+	fake.writeMutex.Lock()
+	defer fake.writeMutex.Unlock()
+	fake.WriteStub = nil
+	if fake.writeReturnsOnCall == nil {
+		fake.writeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.writeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeBackend) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.createMutex.RLock()
+	defer fake.createMutex.RUnlock()
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	fake.deleteAllMutex.RLock()
+	defer fake.deleteAllMutex.RUnlock()
+	fake.existsMutex.RLock()
+	defer fake.existsMutex.RUnlock()
+	fake.readMutex.RLock()
+	defer fake.readMutex.RUnlock()
+	fake.terraformBackendMutex.RLock()
+	defer fake.terraformBackendMutex.RUnlock()
+	fake.writeMutex.RLock()
+	defer fake.writeMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeBackend) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ config.Backend = new(FakeBackend)
@@ -20,6 +20,18 @@ type FakeIClient struct {
 		result1 bool
 		result2 error
 	}
+	CreateAssetStub        func(string, []byte) error
+	createAssetMutex       sync.RWMutex
+	createAssetArgsForCall []struct {
+		arg1 string
+		arg2 []byte
+	}
+	createAssetReturns struct {
+		result1 error
+	}
+	createAssetReturnsOnCall map[int]struct {
+		result1 error
+	}
 	DeleteAllStub        func(config.Config) error
 	deleteAllMutex       sync.RWMutex
 	deleteAllArgsForCall []struct {
@@ -42,6 +54,18 @@ type FakeIClient struct {
 	deleteAssetReturnsOnCall map[int]struct {
 		result1 error
 	}
+	EncryptionStub        func() (*config.Encryption, error)
+	encryptionMutex       sync.RWMutex
+	encryptionArgsForCall []struct {
+	}
+	encryptionReturns struct {
+		result1 *config.Encryption
+		result2 error
+	}
+	encryptionReturnsOnCall map[int]struct {
+		result1 *config.Encryption
+		result2 error
+	}
 	HasAssetStub        func(string) (bool, error)
 	hasAssetMutex       sync.RWMutex
 	hasAssetArgsForCall []struct {
@@ -80,6 +104,31 @@ type FakeIClient struct {
 		result1 []byte
 		result2 error
 	}
+	LoadTerraformStateStub        func(config.Config) ([]byte, error)
+	loadTerraformStateMutex       sync.RWMutex
+	loadTerraformStateArgsForCall []struct {
+		arg1 config.Config
+	}
+	loadTerraformStateReturns struct {
+		result1 []byte
+		result2 error
+	}
+	loadTerraformStateReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
+	LoadWithoutSecretsStub        func() (config.Config, error)
+	loadWithoutSecretsMutex       sync.RWMutex
+	loadWithoutSecretsArgsForCall []struct {
+	}
+	loadWithoutSecretsReturns struct {
+		result1 config.Config
+		result2 error
+	}
+	loadWithoutSecretsReturnsOnCall map[int]struct {
+		result1 config.Config
+		result2 error
+	}
 	NewConfigStub        func() config.Config
 	newConfigMutex       sync.RWMutex
 	newConfigArgsForCall []struct {
@@ -90,6 +139,18 @@ type FakeIClient struct {
 	newConfigReturnsOnCall map[int]struct {
 		result1 config.Config
 	}
+	PipelineEnvStub        func() (map[string]string, error)
+	pipelineEnvMutex       sync.RWMutex
+	pipelineEnvArgsForCall []struct {
+	}
+	pipelineEnvReturns struct {
+		result1 map[string]string
+		result2 error
+	}
+	pipelineEnvReturnsOnCall map[int]struct {
+		result1 map[string]string
+		result2 error
+	}
 	StoreAssetStub        func(string, []byte) error
 	storeAssetMutex       sync.RWMutex
 	storeAssetArgsForCall []struct {
@@ -172,6 +233,72 @@ func (fake *FakeIClient) ConfigExistsReturnsOnCall(i int, result1 bool, result2
 	}{result1, result2}
 }
 
+func (fake *FakeIClient) CreateAsset(arg1 string, arg2 []byte) error {
+	var arg2Copy []byte
+	if arg2 != nil {
+		arg2Copy = make([]byte, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.createAssetMutex.Lock()
+	ret, specificReturn := fake.createAssetReturnsOnCall[len(fake.createAssetArgsForCall)]
+	fake.createAssetArgsForCall = append(fake.createAssetArgsForCall, struct {
+		arg1 string
+		arg2 []byte
+	}{arg1, arg2Copy})
+	fake.recordInvocation("CreateAsset", []interface{}{arg1, arg2Copy})
+	fake.createAssetMutex.Unlock()
+	if fake.CreateAssetStub != nil {
+		return fake.CreateAssetStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.createAssetReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeIClient) CreateAssetCallCount() int {
+	fake.createAssetMutex.RLock()
+	defer fake.createAssetMutex.RUnlock()
+	return len(fake.createAssetArgsForCall)
+}
+
+func (fake *FakeIClient) CreateAssetCalls(stub func(string, []byte) error) {
+	fake.createAssetMutex.Lock()
+	defer fake.createAssetMutex.Unlock()
+	fake.CreateAssetStub = stub
+}
+
+func (fake *FakeIClient) CreateAssetArgsForCall(i int) (string, []byte) {
+	fake.createAssetMutex.RLock()
+	defer fake.createAssetMutex.RUnlock()
+	argsForCall := fake.createAssetArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeIClient) CreateAssetReturns(result1 error) {
+	fake.createAssetMutex.Lock()
+	defer fake.createAssetMutex.Unlock()
+	fake.CreateAssetStub = nil
+	fake.createAssetReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIClient) CreateAssetReturnsOnCall(i int, result1 error) {
+	fake.createAssetMutex.Lock()
+	defer fake.createAssetMutex.Unlock()
+	fake.CreateAssetStub = nil
+	if fake.createAssetReturnsOnCall == nil {
+		fake.createAssetReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.createAssetReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeIClient) DeleteAll(arg1 config.Config) error {
 	fake.deleteAllMutex.Lock()
 	ret, specificReturn := fake.deleteAllReturnsOnCall[len(fake.deleteAllArgsForCall)]
@@ -292,6 +419,61 @@ func (fake *FakeIClient) DeleteAssetReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeIClient) Encryption() (*config.Encryption, error) {
+	fake.encryptionMutex.Lock()
+	ret, specificReturn := fake.encryptionReturnsOnCall[len(fake.encryptionArgsForCall)]
+	fake.encryptionArgsForCall = append(fake.encryptionArgsForCall, struct {
+	}{})
+	fake.recordInvocation("Encryption", []interface{}{})
+	fake.encryptionMutex.Unlock()
+	if fake.EncryptionStub != nil {
+		return fake.EncryptionStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.encryptionReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIClient) EncryptionCallCount() int {
+	fake.encryptionMutex.RLock()
+	defer fake.encryptionMutex.RUnlock()
+	return len(fake.encryptionArgsForCall)
+}
+
+func (fake *FakeIClient) EncryptionCalls(stub func() (*config.Encryption, error)) {
+	fake.encryptionMutex.Lock()
+	defer fake.encryptionMutex.Unlock()
+	fake.EncryptionStub = stub
+}
+
+func (fake *FakeIClient) EncryptionReturns(result1 *config.Encryption, result2 error) {
+	fake.encryptionMutex.Lock()
+	defer fake.encryptionMutex.Unlock()
+	fake.EncryptionStub = nil
+	fake.encryptionReturns = struct {
+		result1 *config.Encryption
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) EncryptionReturnsOnCall(i int, result1 *config.Encryption, result2 error) {
+	fake.encryptionMutex.Lock()
+	defer fake.encryptionMutex.Unlock()
+	fake.EncryptionStub = nil
+	if fake.encryptionReturnsOnCall == nil {
+		fake.encryptionReturnsOnCall = make(map[int]struct {
+			result1 *config.Encryption
+			result2 error
+		})
+	}
+	fake.encryptionReturnsOnCall[i] = struct {
+		result1 *config.Encryption
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeIClient) HasAsset(arg1 string) (bool, error) {
 	fake.hasAssetMutex.Lock()
 	ret, specificReturn := fake.hasAssetReturnsOnCall[len(fake.hasAssetArgsForCall)]
@@ -473,6 +655,124 @@ func (fake *FakeIClient) LoadAssetReturnsOnCall(i int, result1 []byte, result2 e
 	}{result1, result2}
 }
 
+func (fake *FakeIClient) LoadTerraformState(arg1 config.Config) ([]byte, error) {
+	fake.loadTerraformStateMutex.Lock()
+	ret, specificReturn := fake.loadTerraformStateReturnsOnCall[len(fake.loadTerraformStateArgsForCall)]
+	fake.loadTerraformStateArgsForCall = append(fake.loadTerraformStateArgsForCall, struct {
+		arg1 config.Config
+	}{arg1})
+	fake.recordInvocation("LoadTerraformState", []interface{}{arg1})
+	fake.loadTerraformStateMutex.Unlock()
+	if fake.LoadTerraformStateStub != nil {
+		return fake.LoadTerraformStateStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.loadTerraformStateReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIClient) LoadTerraformStateCallCount() int {
+	fake.loadTerraformStateMutex.RLock()
+	defer fake.loadTerraformStateMutex.RUnlock()
+	return len(fake.loadTerraformStateArgsForCall)
+}
+
+func (fake *FakeIClient) LoadTerraformStateCalls(stub func(config.Config) ([]byte, error)) {
+	fake.loadTerraformStateMutex.Lock()
+	defer fake.loadTerraformStateMutex.Unlock()
+	fake.LoadTerraformStateStub = stub
+}
+
+func (fake *FakeIClient) LoadTerraformStateArgsForCall(i int) config.Config {
+	fake.loadTerraformStateMutex.RLock()
+	defer fake.loadTerraformStateMutex.RUnlock()
+	argsForCall := fake.loadTerraformStateArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIClient) LoadTerraformStateReturns(result1 []byte, result2 error) {
+	fake.loadTerraformStateMutex.Lock()
+	defer fake.loadTerraformStateMutex.Unlock()
+	fake.LoadTerraformStateStub = nil
+	fake.loadTerraformStateReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) LoadTerraformStateReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.loadTerraformStateMutex.Lock()
+	defer fake.loadTerraformStateMutex.Unlock()
+	fake.LoadTerraformStateStub = nil
+	if fake.loadTerraformStateReturnsOnCall == nil {
+		fake.loadTerraformStateReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.loadTerraformStateReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) LoadWithoutSecrets() (config.Config, error) {
+	fake.loadWithoutSecretsMutex.Lock()
+	ret, specificReturn := fake.loadWithoutSecretsReturnsOnCall[len(fake.loadWithoutSecretsArgsForCall)]
+	fake.loadWithoutSecretsArgsForCall = append(fake.loadWithoutSecretsArgsForCall, struct {
+	}{})
+	fake.recordInvocation("LoadWithoutSecrets", []interface{}{})
+	fake.loadWithoutSecretsMutex.Unlock()
+	if fake.LoadWithoutSecretsStub != nil {
+		return fake.LoadWithoutSecretsStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.loadWithoutSecretsReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIClient) LoadWithoutSecretsCallCount() int {
+	fake.loadWithoutSecretsMutex.RLock()
+	defer fake.loadWithoutSecretsMutex.RUnlock()
+	return len(fake.loadWithoutSecretsArgsForCall)
+}
+
+func (fake *FakeIClient) LoadWithoutSecretsCalls(stub func() (config.Config, error)) {
+	fake.loadWithoutSecretsMutex.Lock()
+	defer fake.loadWithoutSecretsMutex.Unlock()
+	fake.LoadWithoutSecretsStub = stub
+}
+
+func (fake *FakeIClient) LoadWithoutSecretsReturns(result1 config.Config, result2 error) {
+	fake.loadWithoutSecretsMutex.Lock()
+	defer fake.loadWithoutSecretsMutex.Unlock()
+	fake.LoadWithoutSecretsStub = nil
+	fake.loadWithoutSecretsReturns = struct {
+		result1 config.Config
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) LoadWithoutSecretsReturnsOnCall(i int, result1 config.Config, result2 error) {
+	fake.loadWithoutSecretsMutex.Lock()
+	defer fake.loadWithoutSecretsMutex.Unlock()
+	fake.LoadWithoutSecretsStub = nil
+	if fake.loadWithoutSecretsReturnsOnCall == nil {
+		fake.loadWithoutSecretsReturnsOnCall = make(map[int]struct {
+			result1 config.Config
+			result2 error
+		})
+	}
+	fake.loadWithoutSecretsReturnsOnCall[i] = struct {
+		result1 config.Config
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeIClient) NewConfig() config.Config {
 	fake.newConfigMutex.Lock()
 	ret, specificReturn := fake.newConfigReturnsOnCall[len(fake.newConfigArgsForCall)]
@@ -525,6 +825,61 @@ func (fake *FakeIClient) NewConfigReturnsOnCall(i int, result1 config.Config) {
 	}{result1}
 }
 
+func (fake *FakeIClient) PipelineEnv() (map[string]string, error) {
+	fake.pipelineEnvMutex.Lock()
+	ret, specificReturn := fake.pipelineEnvReturnsOnCall[len(fake.pipelineEnvArgsForCall)]
+	fake.pipelineEnvArgsForCall = append(fake.pipelineEnvArgsForCall, struct {
+	}{})
+	fake.recordInvocation("PipelineEnv", []interface{}{})
+	fake.pipelineEnvMutex.Unlock()
+	if fake.PipelineEnvStub != nil {
+		return fake.PipelineEnvStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.pipelineEnvReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIClient) PipelineEnvCallCount() int {
+	fake.pipelineEnvMutex.RLock()
+	defer fake.pipelineEnvMutex.RUnlock()
+	return len(fake.pipelineEnvArgsForCall)
+}
+
+func (fake *FakeIClient) PipelineEnvCalls(stub func() (map[string]string, error)) {
+	fake.pipelineEnvMutex.Lock()
+	defer fake.pipelineEnvMutex.Unlock()
+	fake.PipelineEnvStub = stub
+}
+
+func (fake *FakeIClient) PipelineEnvReturns(result1 map[string]string, result2 error) {
+	fake.pipelineEnvMutex.Lock()
+	defer fake.pipelineEnvMutex.Unlock()
+	fake.PipelineEnvStub = nil
+	fake.pipelineEnvReturns = struct {
+		result1 map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) PipelineEnvReturnsOnCall(i int, result1 map[string]string, result2 error) {
+	fake.pipelineEnvMutex.Lock()
+	defer fake.pipelineEnvMutex.Unlock()
+	fake.PipelineEnvStub = nil
+	if fake.pipelineEnvReturnsOnCall == nil {
+		fake.pipelineEnvReturnsOnCall = make(map[int]struct {
+			result1 map[string]string
+			result2 error
+		})
+	}
+	fake.pipelineEnvReturnsOnCall[i] = struct {
+		result1 map[string]string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeIClient) StoreAsset(arg1 string, arg2 []byte) error {
 	var arg2Copy []byte
 	if arg2 != nil {
@@ -656,18 +1011,28 @@ func (fake *FakeIClient) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.configExistsMutex.RLock()
 	defer fake.configExistsMutex.RUnlock()
+	fake.createAssetMutex.RLock()
+	defer fake.createAssetMutex.RUnlock()
 	fake.deleteAllMutex.RLock()
 	defer fake.deleteAllMutex.RUnlock()
 	fake.deleteAssetMutex.RLock()
 	defer fake.deleteAssetMutex.RUnlock()
+	fake.encryptionMutex.RLock()
+	defer fake.encryptionMutex.RUnlock()
 	fake.hasAssetMutex.RLock()
 	defer fake.hasAssetMutex.RUnlock()
 	fake.loadMutex.RLock()
 	defer fake.loadMutex.RUnlock()
 	fake.loadAssetMutex.RLock()
 	defer fake.loadAssetMutex.RUnlock()
+	fake.loadTerraformStateMutex.RLock()
+	defer fake.loadTerraformStateMutex.RUnlock()
+	fake.loadWithoutSecretsMutex.RLock()
+	defer fake.loadWithoutSecretsMutex.RUnlock()
 	fake.newConfigMutex.RLock()
 	defer fake.newConfigMutex.RUnlock()
+	fake.pipelineEnvMutex.RLock()
+	defer fake.pipelineEnvMutex.RUnlock()
 	fake.storeAssetMutex.RLock()
 	defer fake.storeAssetMutex.RUnlock()
 	fake.updateMutex.RLock()
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"reflect"
+)
+
+// Redacted replaces the value of secret fields when they are reported
+const Redacted = "(redacted)"
+
+// FieldChange describes a config field which differs between two configs
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// Diff returns the fields which differ between two configs, named by their json key
+// The values of secrets are redacted
+func Diff(from, to Config) []FieldChange {
+	var changes []FieldChange
+
+	before := Fields(from)
+	after := Fields(to)
+	for i := range before {
+		if before[i].Value == after[i].Value {
+			continue
+		}
+		change := FieldChange{Field: before[i].Name, From: before[i].Value, To: after[i].Value}
+		if before[i].Secret {
+			change.From, change.To = Redacted, Redacted
+		}
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+// RedactSecrets returns secrets with every value which is set replaced by Redacted
+func RedactSecrets(secrets Secrets) Secrets {
+	v := reflect.ValueOf(&secrets).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if v.Field(i).String() != "" {
+			v.Field(i).SetString(Redacted)
+		}
+	}
+	return secrets
+}
@@ -0,0 +1,34 @@
+package config_test
+
+import (
+	. "github.com/EngineerBetter/concourse-up/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Diff", func() {
+	It("returns nothing when the configs are the same", func() {
+		conf := Config{ConcourseWorkerCount: 1, Tags: []string{"a=b"}}
+		Expect(Diff(conf, conf)).To(BeEmpty())
+	})
+
+	It("does not treat a nil slice as different from an empty one", func() {
+		Expect(Diff(Config{}, Config{Tags: []string{}})).To(BeEmpty())
+	})
+
+	It("reports changed fields by their json name", func() {
+		from := Config{ConcourseWorkerCount: 1, ConcourseWebSize: "small"}
+		to := Config{ConcourseWorkerCount: 3, ConcourseWebSize: "small"}
+		Expect(Diff(from, to)).To(Equal([]FieldChange{
+			{Field: "concourse_worker_count", From: "1", To: "3"},
+		}))
+	})
+
+	It("redacts the values of secret fields", func() {
+		from := Config{Secrets: Secrets{DirectorPassword: "old-password"}}
+		to := Config{Secrets: Secrets{DirectorPassword: "new-password"}}
+		Expect(Diff(from, to)).To(Equal([]FieldChange{
+			{Field: "director_password", From: Redacted, To: Redacted},
+		}))
+	})
+})
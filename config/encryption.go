@@ -0,0 +1,195 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// EncryptionFilename records how the other files in the config bucket are encrypted. It is never encrypted itself
+const EncryptionFilename = "encryption.json"
+
+// PassphraseEnvVar holds the passphrase for config buckets encrypted with a passphrase
+const PassphraseEnvVar = "CONCOURSE_UP_PASSPHRASE"
+
+// Key types that can encrypt the config bucket
+const (
+	EncryptionKMS        = "kms"
+	EncryptionPassphrase = "passphrase"
+)
+
+const (
+	dataKeySize      = 32
+	pbkdf2Iterations = 100000
+)
+
+// envelopeHeader marks an encrypted file, so that files written before encryption was enabled can still be read
+var envelopeHeader = []byte("concourse-up-encrypted:v1\n")
+
+// Encryption describes the key used to encrypt the config bucket
+type Encryption struct {
+	Type string `json:"type"`
+	// KeyID is the AWS KMS key ID, alias or ARN, or the Cloud KMS key resource name
+	KeyID string `json:"key_id,omitempty"`
+}
+
+// Validate returns an error if the key cannot be used
+func (e Encryption) Validate() error {
+	switch e.Type {
+	case EncryptionKMS:
+		if e.KeyID == "" {
+			return errors.New("a KMS key is required")
+		}
+	case EncryptionPassphrase:
+		if os.Getenv(PassphraseEnvVar) == "" {
+			return fmt.Errorf("%s must be set to encrypt with a passphrase", PassphraseEnvVar)
+		}
+	default:
+		return fmt.Errorf("unknown encryption type %q", e.Type)
+	}
+	return nil
+}
+
+// envelope holds a file encrypted with a data key, and the data key encrypted with the bucket's key
+type envelope struct {
+	Type       string `json:"type"`
+	KeyID      string `json:"key_id,omitempty"`
+	Salt       []byte `json:"salt,omitempty"`
+	WrappedKey []byte `json:"wrapped_key"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// IsEncrypted returns true if contents were written by encrypt
+func IsEncrypted(contents []byte) bool {
+	return bytes.HasPrefix(contents, envelopeHeader)
+}
+
+func encrypt(provider iaas.Provider, encryption Encryption, plaintext []byte) ([]byte, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+
+	e := envelope{Type: encryption.Type, KeyID: encryption.KeyID}
+	var err error
+	switch encryption.Type {
+	case EncryptionKMS:
+		e.WrappedKey, err = provider.EncryptKey(encryption.KeyID, dataKey)
+	case EncryptionPassphrase:
+		e.Salt = make([]byte, 16)
+		if _, err = rand.Read(e.Salt); err != nil {
+			return nil, err
+		}
+		var kek []byte
+		kek, err = passphraseKey(e.Salt)
+		if err == nil {
+			e.WrappedKey, err = seal(kek, dataKey)
+		}
+	default:
+		err = fmt.Errorf("unknown encryption type %q", encryption.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := seal(dataKey, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	e.Nonce, e.Ciphertext = sealed[:gcmNonceSize], sealed[gcmNonceSize:]
+
+	contents, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, envelopeHeader...), contents...), nil
+}
+
+// decrypt returns contents unchanged unless they were written by encrypt. Callers refuse unencrypted files
+// from config buckets that are encrypted
+func decrypt(provider iaas.Provider, contents []byte) ([]byte, error) {
+	if !IsEncrypted(contents) {
+		return contents, nil
+	}
+
+	var e envelope
+	if err := json.Unmarshal(contents[len(envelopeHeader):], &e); err != nil {
+		return nil, fmt.Errorf("error parsing encrypted file: [%v]", err)
+	}
+
+	var dataKey []byte
+	var err error
+	switch e.Type {
+	case EncryptionKMS:
+		dataKey, err = provider.DecryptKey(e.KeyID, e.WrappedKey)
+	case EncryptionPassphrase:
+		var kek []byte
+		kek, err = passphraseKey(e.Salt)
+		if err == nil {
+			dataKey, err = open(kek, e.WrappedKey)
+		}
+	default:
+		err = fmt.Errorf("unknown encryption type %q", e.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting data key: [%v]", err)
+	}
+
+	return open(dataKey, append(append([]byte{}, e.Nonce...), e.Ciphertext...))
+}
+
+func passphraseKey(salt []byte) ([]byte, error) {
+	passphrase := os.Getenv(PassphraseEnvVar)
+	if passphrase == "" {
+		return nil, fmt.Errorf("the config bucket is encrypted with a passphrase, set %s", PassphraseEnvVar)
+	}
+	return pbkdf2.Key([]byte(passphrase), salt, pbkdf2Iterations, dataKeySize, sha256.New), nil
+}
+
+const gcmNonceSize = 12
+
+// seal encrypts plaintext with AES-GCM, returning the nonce followed by the ciphertext
+func seal(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcmNonceSize)
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(key, sealed []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcmNonceSize {
+		return nil, errors.New("encrypted file is truncated")
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcmNonceSize], sealed[gcmNonceSize:], nil)
+	if err != nil {
+		return nil, errors.New("could not decrypt, the key or passphrase is wrong or the file has been tampered with")
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
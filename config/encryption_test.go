@@ -0,0 +1,179 @@
+package config_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+
+	. "github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas/iaasfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Encryption", func() {
+	var provider *iaasfakes.FakeProvider
+	var files map[string][]byte
+	var client *Client
+
+	newClient := func() *Client {
+		return &Client{Iaas: provider, BucketName: "concourse-up-test-eu-west-1-config", BucketExists: true}
+	}
+
+	BeforeEach(func() {
+		files = map[string][]byte{}
+		provider = &iaasfakes.FakeProvider{}
+		provider.HasFileStub = func(bucket, path string) (bool, error) {
+			_, ok := files[path]
+			return ok, nil
+		}
+		provider.LoadFileStub = func(bucket, path string) ([]byte, error) {
+			return files[path], nil
+		}
+		provider.WriteFileStub = func(bucket, path string, contents []byte) error {
+			files[path] = contents
+			return nil
+		}
+		// Stands in for KMS by tagging the data key with the key that wrapped it
+		provider.EncryptKeyStub = func(keyID string, key []byte) ([]byte, error) {
+			return append([]byte(keyID+":"), key...), nil
+		}
+		provider.DecryptKeyStub = func(keyID string, wrapped []byte) ([]byte, error) {
+			return bytes.TrimPrefix(wrapped, []byte(keyID+":")), nil
+		}
+
+		conf, err := json.Marshal(Config{Deployment: "concourse-up-test", SchemaVersion: CurrentSchemaVersion})
+		Expect(err).ToNot(HaveOccurred())
+		files["config.json"] = conf
+		secrets, err := json.Marshal(Secrets{RDSPassword: "s3cret"})
+		Expect(err).ToNot(HaveOccurred())
+		files[SecretsFilename] = secrets
+		files["director-creds.yml"] = []byte("admin_password: s3cret\n")
+
+		client = newClient()
+	})
+
+	AfterEach(func() {
+		os.Unsetenv(PassphraseEnvVar)
+	})
+
+	It("leaves unencrypted buckets alone", func() {
+		Expect(client.StoreAsset("director-state.json", []byte("{}"))).To(Succeed())
+		Expect(files["director-state.json"]).To(Equal([]byte("{}")))
+
+		encryption, err := client.Encryption()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(encryption).To(BeNil())
+	})
+
+	It("encrypts the deployment lock held while encrypting", func() {
+		os.Setenv(PassphraseEnvVar, "correct horse battery staple")
+		files[LockFilename] = []byte(`{"id":"abc"}`)
+		Expect(client.EnableEncryption(Encryption{Type: EncryptionPassphrase})).To(Succeed())
+		Expect(IsEncrypted(files[LockFilename])).To(BeTrue())
+
+		lock, err := newClient().LoadAsset(LockFilename)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(lock)).To(Equal(`{"id":"abc"}`))
+	})
+
+	Context("with a passphrase", func() {
+		BeforeEach(func() {
+			os.Setenv(PassphraseEnvVar, "correct horse battery staple")
+			Expect(client.EnableEncryption(Encryption{Type: EncryptionPassphrase}, "director-creds.yml", "director-state.json")).To(Succeed())
+		})
+
+		It("encrypts the existing files", func() {
+			Expect(IsEncrypted(files["config.json"])).To(BeTrue())
+			Expect(IsEncrypted(files["director-creds.yml"])).To(BeTrue())
+			Expect(IsEncrypted(files[SecretsFilename])).To(BeTrue())
+			Expect(string(files[SecretsFilename])).ToNot(ContainSubstring("s3cret"))
+			Expect(files).ToNot(HaveKey("director-state.json"))
+			Expect(IsEncrypted(files[EncryptionFilename])).To(BeFalse())
+		})
+
+		It("decrypts transparently", func() {
+			conf, err := newClient().Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conf.RDSPassword).To(Equal("s3cret"))
+
+			creds, err := newClient().LoadAsset("director-creds.yml")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(creds)).To(Equal("admin_password: s3cret\n"))
+		})
+
+		It("encrypts files written later by another client", func() {
+			Expect(newClient().StoreAsset("director-state.json", []byte("{}"))).To(Succeed())
+			Expect(IsEncrypted(files["director-state.json"])).To(BeTrue())
+		})
+
+		It("fails to read a file which is not encrypted", func() {
+			files["director-creds.yml"] = []byte("admin_password: replaced\n")
+			_, err := newClient().LoadAsset("director-creds.yml")
+			Expect(err).To(MatchError("director-creds.yml is not encrypted, but the config bucket is"))
+		})
+
+		It("refuses to store files unencrypted when encryption.json is missing", func() {
+			delete(files, EncryptionFilename)
+			err := newClient().StoreAsset("director-state.json", []byte("{}"))
+			Expect(err).To(MatchError(ContainSubstring("config.json is encrypted but encryption.json is missing")))
+			Expect(files).ToNot(HaveKey("director-state.json"))
+		})
+
+		It("restores encryption.json when encryption is enabled again", func() {
+			delete(files, EncryptionFilename)
+			Expect(newClient().EnableEncryption(Encryption{Type: EncryptionPassphrase})).To(Succeed())
+			Expect(files).To(HaveKey(EncryptionFilename))
+
+			conf, err := newClient().Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conf.RDSPassword).To(Equal("s3cret"))
+		})
+
+		It("fails with the wrong passphrase", func() {
+			os.Setenv(PassphraseEnvVar, "wrong")
+			_, err := newClient().Load()
+			Expect(err).To(MatchError(ContainSubstring("the key or passphrase is wrong")))
+		})
+
+		It("fails without a passphrase", func() {
+			os.Unsetenv(PassphraseEnvVar)
+			_, err := newClient().Load()
+			Expect(err).To(MatchError(ContainSubstring("set CONCOURSE_UP_PASSPHRASE")))
+		})
+	})
+
+	Context("with a KMS key", func() {
+		BeforeEach(func() {
+			Expect(client.EnableEncryption(Encryption{Type: EncryptionKMS, KeyID: "alias/concourse-up"})).To(Succeed())
+		})
+
+		It("wraps the data key with KMS", func() {
+			Expect(IsEncrypted(files["config.json"])).To(BeTrue())
+			Expect(provider.EncryptKeyCallCount()).To(Equal(2))
+			keyID, _ := provider.EncryptKeyArgsForCall(0)
+			Expect(keyID).To(Equal("alias/concourse-up"))
+
+			conf, err := newClient().Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conf.RDSPassword).To(Equal("s3cret"))
+			Expect(provider.DecryptKeyCallCount()).To(Equal(2))
+		})
+
+		It("re-encrypts with a new key", func() {
+			Expect(client.EnableEncryption(Encryption{Type: EncryptionKMS, KeyID: "alias/rotated"})).To(Succeed())
+
+			conf, err := newClient().Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conf.RDSPassword).To(Equal("s3cret"))
+			keyID, _ := provider.DecryptKeyArgsForCall(provider.DecryptKeyCallCount() - 1)
+			Expect(keyID).To(Equal("alias/rotated"))
+		})
+	})
+
+	It("requires a KMS key", func() {
+		err := client.EnableEncryption(Encryption{Type: EncryptionKMS})
+		Expect(err).To(MatchError("a KMS key is required"))
+		Expect(files).ToNot(HaveKey(EncryptionFilename))
+	})
+})
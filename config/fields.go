@@ -0,0 +1,121 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Field is a config field, named by its json key
+type Field struct {
+	Name     string
+	Value    string
+	Secret   bool
+	Settable bool
+}
+
+// Fields returns every field of conf in the order they are declared, followed by the secrets
+func Fields(conf Config) []Field {
+	var fields []Field
+	walkFields(reflect.ValueOf(conf), func(field reflect.StructField, value reflect.Value, secret bool) {
+		fields = append(fields, newField(field, value, secret))
+	})
+	return fields
+}
+
+// walkFields calls fn with each field of a config, and then with each of its secrets
+func walkFields(conf reflect.Value, fn func(field reflect.StructField, value reflect.Value, secret bool)) {
+	walk := func(v reflect.Value, secret bool) {
+		for i := 0; i < v.NumField(); i++ {
+			if field := v.Type().Field(i); !field.Anonymous {
+				fn(field, v.Field(i), secret)
+			}
+		}
+	}
+	walk(conf, false)
+	walk(conf.FieldByName("Secrets"), true)
+}
+
+// GetField returns the field of conf with the json key name
+func GetField(conf Config, name string) (Field, error) {
+	for _, field := range Fields(conf) {
+		if field.Name == name {
+			return field, nil
+		}
+	}
+	return Field{}, fmt.Errorf("unknown config field %q", name)
+}
+
+// SetField returns a copy of conf with the settable field name set to value
+// Lists are given as comma separated values
+func SetField(conf Config, name, value string) (Config, error) {
+	field, err := GetField(conf, name)
+	if err != nil {
+		return Config{}, err
+	}
+	if !field.Settable {
+		return Config{}, fmt.Errorf("config field %q cannot be set", name)
+	}
+
+	walkFields(reflect.ValueOf(&conf).Elem(), func(f reflect.StructField, v reflect.Value, _ bool) {
+		if jsonName(f) == name && err == nil {
+			if err = setValue(v, value); err != nil {
+				err = fmt.Errorf("invalid value for %s: [%v]", name, err)
+			}
+		}
+	})
+	if err != nil {
+		return Config{}, err
+	}
+
+	return conf, nil
+}
+
+func newField(field reflect.StructField, value reflect.Value, secret bool) Field {
+	f := Field{
+		Name:     jsonName(field),
+		Secret:   secret,
+		Settable: field.Tag.Get("settable") == "true",
+	}
+	if list, ok := value.Interface().([]string); ok {
+		f.Value = strings.Join(list, ",")
+	} else {
+		f.Value = fmt.Sprint(value.Interface())
+	}
+	return f
+}
+
+func setValue(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(i))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		var list []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		field.Set(reflect.ValueOf(list))
+	default:
+		return fmt.Errorf("fields of kind %s cannot be set", field.Kind())
+	}
+	return nil
+}
+
+func jsonName(field reflect.StructField) string {
+	return strings.Split(field.Tag.Get("json"), ",")[0]
+}
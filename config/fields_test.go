@@ -0,0 +1,51 @@
+package config_test
+
+import (
+	. "github.com/EngineerBetter/concourse-up/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fields", func() {
+	conf := Config{
+		ConcourseWorkerCount: 2,
+		Deployment:           "concourse-up-test",
+		Tags:                 []string{"team=ci", "env=prod"},
+		Secrets: Secrets{
+			ConcoursePassword: "s3cret",
+		},
+	}
+
+	It("lists the fields by their json keys", func() {
+		fields := Fields(conf)
+		Expect(fields[0].Name).To(Equal("allow_ips"))
+		Expect(fields).To(ContainElement(Field{Name: "concourse_password", Value: "s3cret", Secret: true, Settable: true}))
+		Expect(fields).To(ContainElement(Field{Name: "deployment", Value: "concourse-up-test"}))
+		Expect(fields).To(ContainElement(Field{Name: "tags", Value: "team=ci,env=prod", Settable: true}))
+	})
+
+	It("gets a field", func() {
+		field, err := GetField(conf, "concourse_worker_count")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(field.Value).To(Equal("2"))
+
+		_, err = GetField(conf, "worker_count")
+		Expect(err).To(MatchError(`unknown config field "worker_count"`))
+	})
+
+	It("sets settable fields, parsing the value", func() {
+		updated, err := SetField(conf, "concourse_worker_count", "4")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updated.ConcourseWorkerCount).To(Equal(4))
+		Expect(conf.ConcourseWorkerCount).To(Equal(2))
+
+		updated, err = SetField(updated, "tags", "team=ops, ")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updated.Tags).To(Equal([]string{"team=ops"}))
+
+		_, err = SetField(conf, "spot", "sometimes")
+		Expect(err).To(MatchError(ContainSubstring("invalid value for spot")))
+		_, err = SetField(conf, "deployment", "other")
+		Expect(err).To(MatchError(`config field "deployment" cannot be set`))
+	})
+})
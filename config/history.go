@@ -0,0 +1,176 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
+)
+
+// errNoVersions is returned when the files are kept somewhere other than the versioned config bucket
+var errNoVersions = errors.New("old versions are only kept when the config is stored in the config bucket")
+
+// FileVersions are the versions of one file in the config bucket, newest first
+type FileVersions struct {
+	Filename string
+	Versions []iaas.FileVersion
+}
+
+// VersionedFiles returns the files in the config bucket whose old versions can be listed and restored
+func (client *Client) VersionedFiles() []string {
+	return []string{configFilePath, SecretsFilename, "director-state.json", "director-creds.yml", client.terraformStatePath(Config{})}
+}
+
+// terraformStatePath returns where terraform keeps its state in the config bucket
+func (client *Client) terraformStatePath(conf Config) string {
+	if r, err := iaas.Lookup(client.Iaas.IAAS()); err == nil && r.TerraformStatePath != "" {
+		return r.TerraformStatePath
+	}
+	if conf.TFStatePath != "" {
+		return conf.TFStatePath
+	}
+	return terraformStateFileName
+}
+
+// LoadTerraformState returns the terraform state of conf's deployment, or nil if terraform has not stored any yet
+// Terraform keeps its state alongside the rest of the config. It is never encrypted, as terraform writes it
+func (client *Client) LoadTerraformState(conf Config) ([]byte, error) {
+	if state := client.stateBackend(); state != nil {
+		exists, err := state.Exists(terraformStateFileName)
+		if err != nil || !exists {
+			return nil, err
+		}
+		return state.Read(terraformStateFileName)
+	}
+	if client.BucketError != nil {
+		return nil, client.BucketError
+	}
+	path := client.terraformStatePath(conf)
+	exists, err := client.Iaas.HasFile(client.BucketName, path)
+	if err != nil || !exists {
+		return nil, err
+	}
+	return client.Iaas.LoadFile(client.BucketName, path)
+}
+
+// storeTerraformState replaces the terraform state of conf's deployment
+func (client *Client) storeTerraformState(conf Config, state []byte) error {
+	if backend := client.stateBackend(); backend != nil {
+		return backend.Write(terraformStateFileName, state)
+	}
+	return client.Iaas.WriteFile(client.BucketName, client.terraformStatePath(conf), state)
+}
+
+// EnableVersioning turns on versioning for config buckets created before it was enabled by default.
+// Other backends are left as they are
+func (client *Client) EnableVersioning() error {
+	if client.Backend != nil {
+		return nil
+	}
+	if client.BucketError != nil {
+		return client.BucketError
+	}
+	return client.Iaas.EnableVersioning(client.BucketName)
+}
+
+// History returns the versions of each of the VersionedFiles
+func (client *Client) History() ([]FileVersions, error) {
+	if client.Backend != nil {
+		return nil, errNoVersions
+	}
+
+	var history []FileVersions
+	for _, filename := range client.VersionedFiles() {
+		versions, err := client.Iaas.ListFileVersions(client.BucketName, filename)
+		if err != nil {
+			return nil, fmt.Errorf("error listing versions of %s: [%v]", filename, err)
+		}
+		history = append(history, FileVersions{filename, versions})
+	}
+	return history, nil
+}
+
+// FindVersion returns the file which has the version versionID, and that version
+func FindVersion(history []FileVersions, versionID string) (string, iaas.FileVersion, error) {
+	for _, file := range history {
+		for _, version := range file.Versions {
+			if version.ID == versionID {
+				return file.Filename, version, nil
+			}
+		}
+	}
+	return "", iaas.FileVersion{}, fmt.Errorf("no file in the config bucket has version %s", versionID)
+}
+
+// RestoreVersions returns the version of each file to restore so that filename is at version. config.json and
+// secrets.json are written together, so both are restored to the versions written together with version.
+// With all, every file is restored to the version which was current then.
+// secrets.json is left out if it did not exist then, when the secrets were still kept in config.json
+func RestoreVersions(history []FileVersions, filename string, version iaas.FileVersion, all bool) map[string]iaas.FileVersion {
+	t := version.Modified
+	// Update stores secrets.json just before config.json, so find the config.json written with it
+	if filename == SecretsFilename {
+		for _, file := range history {
+			if file.Filename != configFilePath {
+				continue
+			}
+			for i := len(file.Versions) - 1; i >= 0; i-- {
+				if !file.Versions[i].Modified.Before(t) {
+					t = file.Versions[i].Modified
+					break
+				}
+			}
+		}
+	}
+
+	current := VersionsAt(history, t)
+	if all {
+		return current
+	}
+	versions := map[string]iaas.FileVersion{filename: version}
+	if filename == configFilePath || filename == SecretsFilename {
+		for _, paired := range []string{configFilePath, SecretsFilename} {
+			if v, ok := current[paired]; ok {
+				versions[paired] = v
+			}
+		}
+	}
+	return versions
+}
+
+// SecretsInConfig returns true if versions restores a config.json written before the secrets were moved out of it
+// into secrets.json, which must then be deleted so that they are read from config.json again
+func SecretsInConfig(versions map[string]iaas.FileVersion) bool {
+	_, restoresConfig := versions[configFilePath]
+	_, restoresSecrets := versions[SecretsFilename]
+	return restoresConfig && !restoresSecrets
+}
+
+// VersionsAt returns the version of each file which was current at t, leaving out files which did not exist then
+func VersionsAt(history []FileVersions, t time.Time) map[string]iaas.FileVersion {
+	versions := map[string]iaas.FileVersion{}
+	for _, file := range history {
+		for _, version := range file.Versions {
+			if !version.Modified.After(t) {
+				versions[file.Filename] = version
+				break
+			}
+		}
+	}
+	return versions
+}
+
+// RestoreVersion makes a copy of an old version of filename its latest version.
+// The contents are copied as they are stored, so an encrypted version stays encrypted.
+func (client *Client) RestoreVersion(filename, versionID string) error {
+	if client.Backend != nil {
+		return errNoVersions
+	}
+
+	contents, err := client.Iaas.LoadFileVersion(client.BucketName, filename, versionID)
+	if err != nil {
+		return fmt.Errorf("error loading version %s of %s: [%v]", versionID, filename, err)
+	}
+	return client.Iaas.WriteFile(client.BucketName, filename, contents)
+}
@@ -0,0 +1,131 @@
+package config_test
+
+import (
+	"time"
+
+	. "github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/config/configfakes"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/iaas/iaasfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("History", func() {
+	var provider *iaasfakes.FakeProvider
+	var client *Client
+	var monday, tuesday, wednesday time.Time
+
+	BeforeEach(func() {
+		monday = time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+		tuesday = monday.AddDate(0, 0, 1)
+		wednesday = monday.AddDate(0, 0, 2)
+
+		provider = &iaasfakes.FakeProvider{}
+		provider.IAASReturns(iaas.AWS)
+		provider.ListFileVersionsStub = func(bucket, path string) ([]iaas.FileVersion, error) {
+			switch path {
+			case "config.json":
+				return []iaas.FileVersion{{ID: "c3", Modified: wednesday, Latest: true}, {ID: "c1", Modified: monday}}, nil
+			case "director-creds.yml":
+				return []iaas.FileVersion{{ID: "d2", Modified: tuesday, Latest: true}}, nil
+			}
+			return nil, nil
+		}
+		client = &Client{Iaas: provider, BucketName: "concourse-up-test-eu-west-1-config"}
+	})
+
+	It("lists the versions of the config, director and terraform state", func() {
+		Expect(client.VersionedFiles()).To(Equal([]string{"config.json", "secrets.json", "director-state.json", "director-creds.yml", "terraform.tfstate"}))
+		provider.IAASReturns(iaas.GCP)
+		Expect(client.VersionedFiles()).To(ContainElement("default.tfstate"))
+
+		history, err := client.History()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(history).To(HaveLen(5))
+		Expect(history[0].Filename).To(Equal("config.json"))
+		Expect(history[0].Versions).To(HaveLen(2))
+	})
+
+	It("finds the versions which were latest at a point in time", func() {
+		history, err := client.History()
+		Expect(err).ToNot(HaveOccurred())
+
+		filename, version, err := FindVersion(history, "c1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(filename).To(Equal("config.json"))
+		Expect(version.Modified).To(Equal(monday))
+
+		Expect(VersionsAt(history, tuesday)).To(Equal(map[string]iaas.FileVersion{
+			"config.json":        {ID: "c1", Modified: monday},
+			"director-creds.yml": {ID: "d2", Modified: tuesday, Latest: true},
+		}))
+
+		_, _, err = FindVersion(history, "missing")
+		Expect(err).To(MatchError("no file in the config bucket has version missing"))
+	})
+
+	It("restores config.json and secrets.json together", func() {
+		provider.ListFileVersionsStub = func(bucket, path string) ([]iaas.FileVersion, error) {
+			switch path {
+			case "config.json":
+				return []iaas.FileVersion{
+					{ID: "c3", Modified: wednesday, Latest: true},
+					{ID: "c2", Modified: tuesday.Add(time.Second)},
+					{ID: "c1", Modified: monday},
+				}, nil
+			case "secrets.json":
+				return []iaas.FileVersion{
+					{ID: "s3", Modified: wednesday.Add(-time.Second), Latest: true},
+					{ID: "s2", Modified: tuesday},
+				}, nil
+			case "director-creds.yml":
+				return []iaas.FileVersion{{ID: "d2", Modified: tuesday, Latest: true}}, nil
+			}
+			return nil, nil
+		}
+		history, err := client.History()
+		Expect(err).ToNot(HaveOccurred())
+
+		paired := map[string]iaas.FileVersion{
+			"config.json":  {ID: "c2", Modified: tuesday.Add(time.Second)},
+			"secrets.json": {ID: "s2", Modified: tuesday},
+		}
+		_, c2, err := FindVersion(history, "c2")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(RestoreVersions(history, "config.json", c2, false)).To(Equal(paired))
+		_, s2, err := FindVersion(history, "s2")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(RestoreVersions(history, "secrets.json", s2, false)).To(Equal(paired))
+		Expect(SecretsInConfig(paired)).To(BeFalse())
+
+		all := RestoreVersions(history, "secrets.json", s2, true)
+		Expect(all).To(HaveKeyWithValue("config.json", paired["config.json"]))
+		Expect(all).To(HaveKeyWithValue("director-creds.yml", iaas.FileVersion{ID: "d2", Modified: tuesday, Latest: true}))
+
+		_, c1, err := FindVersion(history, "c1")
+		Expect(err).ToNot(HaveOccurred())
+		versions := RestoreVersions(history, "config.json", c1, false)
+		Expect(versions).To(Equal(map[string]iaas.FileVersion{"config.json": c1}))
+		Expect(SecretsInConfig(versions)).To(BeTrue())
+	})
+
+	It("restores a version by copying it over the latest one", func() {
+		provider.LoadFileVersionReturns([]byte("old creds"), nil)
+
+		Expect(client.RestoreVersion("director-creds.yml", "d1")).To(Succeed())
+		bucket, path, versionID := provider.LoadFileVersionArgsForCall(0)
+		Expect([]string{bucket, path, versionID}).To(Equal([]string{"concourse-up-test-eu-west-1-config", "director-creds.yml", "d1"}))
+		bucket, path, contents := provider.WriteFileArgsForCall(0)
+		Expect(bucket).To(Equal("concourse-up-test-eu-west-1-config"))
+		Expect(path).To(Equal("director-creds.yml"))
+		Expect(string(contents)).To(Equal("old creds"))
+	})
+
+	It("needs the config to be kept in the config bucket", func() {
+		client.Backend = &configfakes.FakeBackend{}
+		_, err := client.History()
+		Expect(err).To(MatchError(ContainSubstring("only kept when the config is stored in the config bucket")))
+		Expect(client.RestoreVersion("config.json", "c1")).ToNot(Succeed())
+	})
+})
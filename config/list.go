@@ -0,0 +1,96 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
+)
+
+var configBucketPattern = regexp.MustCompile(`^concourse-up-.+-config$`)
+
+// Deployment summarises a deployment found by List
+type Deployment struct {
+	Project      string `json:"project"`
+	Namespace    string `json:"namespace"`
+	Region       string `json:"region"`
+	IAAS         string `json:"iaas"`
+	Version      string `json:"version"`
+	Domain       string `json:"domain"`
+	WorkerCount  int    `json:"worker_count"`
+	WorkerSize   string `json:"worker_size"`
+	LastDeployed string `json:"last_deployed"`
+}
+
+// List finds every config bucket visible to the provider and summarises the deployment it describes.
+// A bucket whose config cannot be read is skipped with a warning written to stderr
+func List(provider iaas.Provider, stderr io.Writer) ([]Deployment, error) {
+	buckets, err := provider.ListBuckets()
+	if err != nil {
+		return nil, fmt.Errorf("error listing buckets: [%v]", err)
+	}
+
+	var deployments []Deployment
+	for _, bucket := range buckets {
+		if !configBucketPattern.MatchString(bucket) {
+			continue
+		}
+
+		conf, err := loadListedConfig(provider, bucket)
+		if err != nil {
+			fmt.Fprintf(stderr, "Warning: skipping bucket %s: %v\n", bucket, err)
+			continue
+		}
+
+		deployments = append(deployments, Deployment{
+			Project:      conf.Project,
+			Namespace:    conf.Namespace,
+			Region:       conf.Region,
+			IAAS:         conf.IAAS,
+			Version:      conf.Version,
+			Domain:       conf.Domain,
+			WorkerCount:  conf.ConcourseWorkerCount,
+			WorkerSize:   conf.ConcourseWorkerSize,
+			LastDeployed: conf.LastDeployed,
+		})
+	}
+
+	sort.Slice(deployments, func(i, j int) bool {
+		if deployments[i].Namespace != deployments[j].Namespace {
+			return deployments[i].Namespace < deployments[j].Namespace
+		}
+		return deployments[i].Project < deployments[j].Project
+	})
+
+	return deployments, nil
+}
+
+func loadListedConfig(provider iaas.Provider, bucket string) (Config, error) {
+	var conf Config
+	configBytes, err := provider.LoadFile(bucket, configFilePath)
+	if err != nil {
+		return conf, fmt.Errorf("error loading config: [%v]", err)
+	}
+
+	if !IsEncrypted(configBytes) {
+		encrypted, err := provider.HasFile(bucket, EncryptionFilename)
+		if err != nil {
+			return conf, err
+		}
+		if encrypted {
+			return conf, fmt.Errorf("%s is not encrypted, but the config bucket is", configFilePath)
+		}
+	}
+
+	if configBytes, err = decrypt(provider, configBytes); err != nil {
+		return conf, fmt.Errorf("error decrypting config: [%v]", err)
+	}
+
+	if err := json.Unmarshal(configBytes, &conf); err != nil {
+		return conf, fmt.Errorf("error parsing config: [%v]", err)
+	}
+	return conf, nil
+}
@@ -0,0 +1,118 @@
+package config_test
+
+import (
+	"bytes"
+	"errors"
+
+	. "github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas/iaasfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("List", func() {
+	var provider *iaasfakes.FakeProvider
+	var stderr *bytes.Buffer
+
+	BeforeEach(func() {
+		stderr = new(bytes.Buffer)
+		provider = &iaasfakes.FakeProvider{}
+		provider.ListBucketsReturns([]string{
+			"concourse-up-ci-eu-west-1-config",
+			"unrelated-bucket",
+			"concourse-up-prod-team-a-config",
+		}, nil)
+		provider.LoadFileStub = func(bucket, path string) ([]byte, error) {
+			switch bucket {
+			case "concourse-up-ci-eu-west-1-config":
+				return []byte(`{"project":"ci","namespace":"eu-west-1","region":"eu-west-1","iaas":"AWS","concourse_worker_count":2,"concourse_worker_size":"large","director_password":"secret"}`), nil
+			case "concourse-up-prod-team-a-config":
+				return []byte(`{"project":"prod","namespace":"team-a","region":"us-east-1","iaas":"AWS","version":"0.20.0","last_deployed":"2019-03-01T10:00:00Z"}`), nil
+			}
+			return nil, errors.New("unexpected bucket")
+		}
+	})
+
+	It("summarises each deployment with a config bucket", func() {
+		deployments, err := List(provider, stderr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deployments).To(Equal([]Deployment{
+			{
+				Project:     "ci",
+				Namespace:   "eu-west-1",
+				Region:      "eu-west-1",
+				IAAS:        "AWS",
+				WorkerCount: 2,
+				WorkerSize:  "large",
+			},
+			{
+				Project:      "prod",
+				Namespace:    "team-a",
+				Region:       "us-east-1",
+				IAAS:         "AWS",
+				Version:      "0.20.0",
+				LastDeployed: "2019-03-01T10:00:00Z",
+			},
+		}))
+	})
+
+	It("only loads config from config buckets", func() {
+		_, err := List(provider, stderr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(provider.LoadFileCallCount()).To(Equal(2))
+		bucket, path := provider.LoadFileArgsForCall(0)
+		Expect(bucket).To(Equal("concourse-up-ci-eu-west-1-config"))
+		Expect(path).To(Equal("config.json"))
+	})
+
+	Context("when a config cannot be loaded", func() {
+		BeforeEach(func() {
+			provider.LoadFileStub = func(bucket, path string) ([]byte, error) {
+				if bucket == "concourse-up-ci-eu-west-1-config" {
+					return nil, errors.New("access denied")
+				}
+				return []byte(`{"project":"prod","namespace":"team-a","region":"us-east-1","iaas":"AWS"}`), nil
+			}
+		})
+
+		It("warns about the bucket and lists the others", func() {
+			deployments, err := List(provider, stderr)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deployments).To(HaveLen(1))
+			Expect(deployments[0].Project).To(Equal("prod"))
+			Expect(stderr.String()).To(ContainSubstring("concourse-up-ci-eu-west-1-config"))
+			Expect(stderr.String()).To(ContainSubstring("access denied"))
+		})
+	})
+
+	Context("when a config is not encrypted in an encrypted bucket", func() {
+		BeforeEach(func() {
+			provider.HasFileStub = func(bucket, path string) (bool, error) {
+				return bucket == "concourse-up-ci-eu-west-1-config" && path == "encryption.json", nil
+			}
+		})
+
+		It("warns about the bucket and lists the others", func() {
+			deployments, err := List(provider, stderr)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deployments).To(HaveLen(1))
+			Expect(deployments[0].Project).To(Equal("prod"))
+			Expect(stderr.String()).To(ContainSubstring("config.json is not encrypted, but the config bucket is"))
+		})
+	})
+
+	Context("when a config is not valid json", func() {
+		BeforeEach(func() {
+			provider.ListBucketsReturns([]string{"concourse-up-ci-eu-west-1-config"}, nil)
+			provider.LoadFileStub = nil
+			provider.LoadFileReturns([]byte("not json"), nil)
+		})
+
+		It("warns about the bucket and returns no deployments", func() {
+			deployments, err := List(provider, stderr)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deployments).To(BeEmpty())
+			Expect(stderr.String()).To(ContainSubstring("error parsing config"))
+		})
+	})
+})
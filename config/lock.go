@@ -0,0 +1,197 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
+)
+
+// LockFilename is the name of the lock object in the config bucket
+const LockFilename = "deployment.lock"
+
+// DefaultLockTTL is how long a lock is honoured if the command holding it never releases it
+const DefaultLockTTL = 4 * time.Hour
+
+// LockRefreshInterval is how often a running command refreshes its lock, well within DefaultLockTTL
+const LockRefreshInterval = DefaultLockTTL / 8
+
+// LockOwnerEnvVar overrides the owner recorded in a lock, e.g. to name the self-update pipeline
+const LockOwnerEnvVar = "CONCOURSE_UP_LOCK_OWNER"
+
+// Lock is an advisory lock held in the config bucket while a command changes a deployment
+type Lock struct {
+	ID       string    `json:"id"`
+	Owner    string    `json:"owner"`
+	Host     string    `json:"host"`
+	Command  string    `json:"command"`
+	Acquired time.Time `json:"acquired"`
+	Expires  time.Time `json:"expires"`
+}
+
+func (l Lock) String() string {
+	return fmt.Sprintf("%s on %s running `%s` since %s", l.Owner, l.Host, l.Command, l.Acquired.Format(time.RFC3339))
+}
+
+// LockHeldError is returned when another command holds the lock
+type LockHeldError struct {
+	Lock Lock
+}
+
+func (e *LockHeldError) Error() string {
+	return fmt.Sprintf("deployment is locked by %s (expires %s)", e.Lock, e.Lock.Expires.Format(time.RFC3339))
+}
+
+// AcquireLock takes the lock for command, unless another command holds an unexpired lock. The lock is created
+// with a conditional write, so of two commands which find the deployment unlocked only one gets it.
+// An expired lock is deleted before the lock is created, which is not atomic, but a lock only expires when the
+// command which held it stopped without releasing it, as a running command refreshes its lock
+func AcquireLock(client IClient, command string, ttl time.Duration) (*Lock, error) {
+	id, err := newLockID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	lock := &Lock{
+		ID:       id,
+		Owner:    lockOwner(),
+		Host:     lockHost(),
+		Command:  command,
+		Acquired: now,
+		Expires:  now.Add(ttl),
+	}
+	contents, err := json.Marshal(lock)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < 3; attempt++ {
+		err = client.CreateAsset(LockFilename, contents)
+		if err != iaas.ErrFileExists {
+			if err != nil {
+				return nil, err
+			}
+			return lock, nil
+		}
+
+		existing, err := loadLock(client)
+		switch {
+		case err != nil:
+			return nil, err
+		case existing == nil:
+			// Released since the write failed
+			continue
+		case existing.ID == lock.ID:
+			// The write succeeded, but was retried after its response was lost
+			return lock, nil
+		case time.Now().Before(existing.Expires):
+			return nil, &LockHeldError{*existing}
+		}
+		if err = client.DeleteAsset(LockFilename); err != nil {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("could not acquire %s, as other commands keep taking it", LockFilename)
+}
+
+// RefreshLock extends lock to expire ttl from now, so that it does not expire while a long command runs.
+// It returns an error if the lock has been forced or taken over
+func RefreshLock(client IClient, lock *Lock, ttl time.Duration) error {
+	stored, err := loadLock(client)
+	if err != nil {
+		return err
+	}
+	if stored == nil || stored.ID != lock.ID {
+		return fmt.Errorf("the deployment lock held since %s has been removed", lock.Acquired.Format(time.RFC3339))
+	}
+	refreshed := *lock
+	refreshed.Expires = time.Now().UTC().Add(ttl)
+	contents, err := json.Marshal(refreshed)
+	if err != nil {
+		return err
+	}
+	if err = client.StoreAsset(LockFilename, contents); err != nil {
+		return err
+	}
+	lock.Expires = refreshed.Expires
+	return nil
+}
+
+// ReleaseLock removes the lock if it is still held by lock, leaving a lock that was forced or taken over alone
+func ReleaseLock(client IClient, lock *Lock) error {
+	if lock == nil {
+		return nil
+	}
+	stored, err := loadLock(client)
+	if err != nil {
+		return err
+	}
+	if stored == nil || stored.ID != lock.ID {
+		return nil
+	}
+	return client.DeleteAsset(LockFilename)
+}
+
+// ForceUnlock removes the lock whoever holds it and returns it, or nil if the deployment was not locked
+func ForceUnlock(client IClient) (*Lock, error) {
+	stored, err := loadLock(client)
+	if err != nil || stored == nil {
+		return nil, err
+	}
+	return stored, client.DeleteAsset(LockFilename)
+}
+
+// CurrentLock returns the lock on the deployment, or nil if there is none
+func CurrentLock(client IClient) (*Lock, error) {
+	return loadLock(client)
+}
+
+func loadLock(client IClient) (*Lock, error) {
+	exists, err := client.HasAsset(LockFilename)
+	if err != nil || !exists {
+		return nil, err
+	}
+	contents, err := client.LoadAsset(LockFilename)
+	if err != nil {
+		return nil, err
+	}
+	var lock Lock
+	if err = json.Unmarshal(contents, &lock); err != nil {
+		return nil, fmt.Errorf("error parsing %s: [%v]", LockFilename, err)
+	}
+	return &lock, nil
+}
+
+func newLockID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func lockOwner() string {
+	if owner := os.Getenv(LockOwnerEnvVar); owner != "" {
+		return owner
+	}
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if owner := os.Getenv("USER"); owner != "" {
+		return owner
+	}
+	return "unknown"
+}
+
+func lockHost() string {
+	host, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+	return host
+}
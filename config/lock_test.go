@@ -0,0 +1,168 @@
+package config_test
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	. "github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/config/configfakes"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Lock", func() {
+	var client *configfakes.FakeIClient
+	var assets map[string][]byte
+
+	storeLock := func(lock Lock) {
+		contents, err := json.Marshal(lock)
+		Expect(err).ToNot(HaveOccurred())
+		assets[LockFilename] = contents
+	}
+
+	BeforeEach(func() {
+		assets = map[string][]byte{}
+		client = &configfakes.FakeIClient{}
+		client.HasAssetStub = func(name string) (bool, error) {
+			_, ok := assets[name]
+			return ok, nil
+		}
+		client.LoadAssetStub = func(name string) ([]byte, error) {
+			return assets[name], nil
+		}
+		client.StoreAssetStub = func(name string, contents []byte) error {
+			assets[name] = contents
+			return nil
+		}
+		client.CreateAssetStub = func(name string, contents []byte) error {
+			if _, ok := assets[name]; ok {
+				return iaas.ErrFileExists
+			}
+			assets[name] = contents
+			return nil
+		}
+		client.DeleteAssetStub = func(name string) error {
+			delete(assets, name)
+			return nil
+		}
+		os.Setenv(LockOwnerEnvVar, "alice")
+	})
+
+	AfterEach(func() {
+		os.Unsetenv(LockOwnerEnvVar)
+	})
+
+	It("acquires and releases the lock", func() {
+		lock, err := AcquireLock(client, "deploy", time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lock.Owner).To(Equal("alice"))
+		Expect(lock.Command).To(Equal("deploy"))
+		Expect(lock.Expires).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+		Expect(assets).To(HaveKey(LockFilename))
+
+		Expect(ReleaseLock(client, lock)).To(Succeed())
+		Expect(assets).ToNot(HaveKey(LockFilename))
+	})
+
+	It("creates the lock only if there is none, rather than overwriting one", func() {
+		_, err := AcquireLock(client, "deploy", time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.CreateAssetCallCount()).To(Equal(1))
+		Expect(client.StoreAssetCallCount()).To(BeZero())
+	})
+
+	It("does not take a lock another command created first", func() {
+		client.CreateAssetStub = func(name string, contents []byte) error {
+			// Another command wrote its lock between this command finding no lock and writing its own
+			storeLock(Lock{ID: "other", Owner: "bob", Host: "ci", Command: "deploy", Expires: time.Now().Add(time.Hour)})
+			return iaas.ErrFileExists
+		}
+		_, err := AcquireLock(client, "deploy", time.Hour)
+		Expect(err).To(BeAssignableToTypeOf(&LockHeldError{}))
+		Expect(err).To(MatchError(ContainSubstring("locked by bob on ci")))
+	})
+
+	It("holds the lock when a retried write finds its own lock", func() {
+		client.CreateAssetStub = func(name string, contents []byte) error {
+			assets[name] = contents
+			return iaas.ErrFileExists
+		}
+		lock, err := AcquireLock(client, "deploy", time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lock.Command).To(Equal("deploy"))
+	})
+
+	It("refreshes the lock it holds", func() {
+		lock, err := AcquireLock(client, "deploy", time.Minute)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(RefreshLock(client, lock, time.Hour)).To(Succeed())
+		Expect(lock.Expires).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+		var stored Lock
+		Expect(json.Unmarshal(assets[LockFilename], &stored)).To(Succeed())
+		Expect(stored.ID).To(Equal(lock.ID))
+		Expect(stored.Expires).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+	})
+
+	It("does not refresh a lock which was forced", func() {
+		lock, err := AcquireLock(client, "deploy", time.Minute)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = ForceUnlock(client)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(RefreshLock(client, lock, time.Hour)).To(MatchError(ContainSubstring("has been removed")))
+		Expect(assets).ToNot(HaveKey(LockFilename))
+	})
+
+	Context("when another command holds the lock", func() {
+		BeforeEach(func() {
+			storeLock(Lock{
+				ID:       "other",
+				Owner:    "bob",
+				Host:     "laptop",
+				Command:  "destroy",
+				Acquired: time.Date(2019, 3, 1, 10, 0, 0, 0, time.UTC),
+				Expires:  time.Now().Add(time.Hour),
+			})
+		})
+
+		It("names the holder", func() {
+			_, err := AcquireLock(client, "deploy", time.Hour)
+			Expect(err).To(BeAssignableToTypeOf(&LockHeldError{}))
+			Expect(err).To(MatchError(ContainSubstring("deployment is locked by bob on laptop running `destroy` since 2019-03-01T10:00:00Z")))
+		})
+
+		It("can be forced", func() {
+			lock, err := ForceUnlock(client)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lock.Owner).To(Equal("bob"))
+			Expect(assets).ToNot(HaveKey(LockFilename))
+		})
+
+		It("is not released by another command", func() {
+			Expect(ReleaseLock(client, &Lock{ID: "mine"})).To(Succeed())
+			Expect(assets).To(HaveKey(LockFilename))
+		})
+	})
+
+	Context("when the lock has expired", func() {
+		BeforeEach(func() {
+			storeLock(Lock{ID: "stale", Owner: "bob", Expires: time.Now().Add(-time.Minute)})
+		})
+
+		It("takes it over", func() {
+			lock, err := AcquireLock(client, "deploy", time.Hour)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lock.ID).ToNot(Equal("stale"))
+		})
+	})
+
+	It("reports that there is no lock to force", func() {
+		lock, err := ForceUnlock(client)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lock).To(BeNil())
+		Expect(client.DeleteAssetCallCount()).To(BeZero())
+	})
+})
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
+)
+
+// Migration upgrades a config from the previous schema version to Version
+type Migration struct {
+	Version     int
+	Description string
+	Migrate     func(Config) (Config, error)
+}
+
+// Migrations are run in order on configs written with an older schema version, in memory by Load
+// and stored by MigrateConfig
+// Add a migration here rather than patching old configs wherever they are used
+var Migrations = []Migration{
+	{
+		Version:     1,
+		Description: "set the default network CIDRs, which older versions did not store",
+		Migrate:     addDefaultCIDRs,
+	},
+	{
+		Version:     2,
+		Description: "move the passwords and keys out of config.json into secrets.json",
+		Migrate:     moveSecrets,
+	},
+	{
+		Version:     3,
+		Description: "record the availability zone as the only zone workers are deployed to",
+		Migrate:     addZones,
+	},
+}
+
+// CurrentSchemaVersion is the schema version of configs written by this version of concourse-up
+var CurrentSchemaVersion = Migrations[len(Migrations)-1].Version
+
+// configBackupsPrefix is where config.json is copied before it is migrated
+const configBackupsPrefix = "config-backups/"
+
+// MigrationPlan describes the migrations needed to bring a config up to the current schema version
+type MigrationPlan struct {
+	From       int
+	To         int
+	Migrations []Migration
+	Changes    []FieldChange
+	// Backup is the file the config was copied to before it was migrated, if it was
+	Backup string
+}
+
+// Pending returns true if the config is not at the current schema version
+func (p MigrationPlan) Pending() bool {
+	return len(p.Migrations) != 0
+}
+
+// Migrate runs the pending migrations on conf, returning the migrated config and the migrations which ran
+func Migrate(conf Config) (Config, []Migration, error) {
+	if conf.SchemaVersion > CurrentSchemaVersion {
+		return Config{}, nil, fmt.Errorf("config has schema version %d but this concourse-up only supports up to %d, upgrade concourse-up", conf.SchemaVersion, CurrentSchemaVersion)
+	}
+
+	var applied []Migration
+	for _, m := range Migrations {
+		if m.Version <= conf.SchemaVersion {
+			continue
+		}
+		migrated, err := m.Migrate(conf)
+		if err != nil {
+			return Config{}, nil, fmt.Errorf("error migrating config to schema version %d: [%v]", m.Version, err)
+		}
+		migrated.SchemaVersion = m.Version
+		conf = migrated
+		applied = append(applied, m)
+	}
+	return conf, applied, nil
+}
+
+// SetDefaultCIDRs sets the network ranges used when none are given on the first deploy
+func SetDefaultCIDRs(conf Config, iaasName iaas.Name) Config {
+	r, _ := iaas.Lookup(iaasName)
+	for cidr, defaultCIDR := range cidrs(&conf, r.Ranges) {
+		if defaultCIDR != "" {
+			*cidr = defaultCIDR
+		}
+	}
+	return conf
+}
+
+// MissingCIDRs returns true if conf lacks any of the network ranges a deployment to iaasName needs
+func MissingCIDRs(conf Config, iaasName iaas.Name) bool {
+	r, _ := iaas.Lookup(iaasName)
+	for cidr, defaultCIDR := range cidrs(&conf, r.Ranges) {
+		if defaultCIDR != "" && *cidr == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// cidrs maps the fields of conf which hold network ranges to the ranges in ranges
+func cidrs(conf *Config, ranges iaas.Ranges) map[*string]string {
+	return map[*string]string{
+		&conf.NetworkCIDR: ranges.Network,
+		&conf.PublicCIDR:  ranges.Public,
+		&conf.PrivateCIDR: ranges.Private,
+		&conf.RDS1CIDR:    ranges.RDS1,
+		&conf.RDS2CIDR:    ranges.RDS2,
+	}
+}
+
+func addDefaultCIDRs(conf Config) (Config, error) {
+	iaasName, err := configIAAS(conf)
+	if err != nil {
+		return Config{}, err
+	}
+	if MissingCIDRs(conf, iaasName) {
+		conf = SetDefaultCIDRs(conf, iaasName)
+	}
+	return conf, nil
+}
+
+// configIAAS returns the IAAS a config was deployed to. Configs written before GCP was supported have none, and are AWS
+func configIAAS(conf Config) (iaas.Name, error) {
+	if conf.IAAS == "" {
+		return iaas.AWS, nil
+	}
+	return iaas.Assosiate(conf.IAAS)
+}
+
+// moveSecrets changes nothing in the config itself, Load reads the secrets from config.json until Update
+// stores them in secrets.json and drops them from config.json
+func moveSecrets(conf Config) (Config, error) {
+	return conf, nil
+}
+
+// addZones records the single zone of a deployment made before workers could be spread across zones,
+// so that more zones can be added to it by a later deploy
+func addZones(conf Config) (Config, error) {
+	if len(conf.Zones) == 0 && conf.AvailabilityZone != "" {
+		conf.Zones = []string{conf.AvailabilityZone}
+	}
+	return conf, nil
+}
@@ -0,0 +1,144 @@
+package config_test
+
+import (
+	"encoding/json"
+	"strings"
+
+	. "github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas/iaasfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Migrations", func() {
+	Describe("Migrate", func() {
+		It("sets the default CIDRs on configs written before GCP was supported", func() {
+			migrated, applied, err := Migrate(Config{Deployment: "concourse-up-test"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(applied).To(HaveLen(len(Migrations)))
+			Expect(migrated.SchemaVersion).To(Equal(CurrentSchemaVersion))
+			Expect(migrated.NetworkCIDR).To(Equal("10.0.0.0/16"))
+			Expect(migrated.RDS2CIDR).To(Equal("10.0.5.0/24"))
+		})
+
+		It("only sets the CIDRs GCP uses", func() {
+			migrated, _, err := Migrate(Config{IAAS: "GCP"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(migrated.PublicCIDR).To(Equal("10.0.0.0/24"))
+			Expect(migrated.PrivateCIDR).To(Equal("10.0.1.0/24"))
+			Expect(migrated.NetworkCIDR).To(BeEmpty())
+		})
+
+		It("keeps CIDRs which were already set", func() {
+			conf := Config{IAAS: "AWS", NetworkCIDR: "10.1.0.0/16", PrivateCIDR: "10.1.1.0/24", PublicCIDR: "10.1.0.0/24", RDS1CIDR: "10.1.4.0/24", RDS2CIDR: "10.1.5.0/24"}
+			migrated, _, err := Migrate(conf)
+			Expect(err).ToNot(HaveOccurred())
+			conf.SchemaVersion = CurrentSchemaVersion
+			Expect(migrated).To(Equal(conf))
+		})
+
+		It("records the availability zone of single zone deployments as their only zone", func() {
+			migrated, _, err := Migrate(Config{SchemaVersion: 2, AvailabilityZone: "eu-west-1a"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(migrated.Zones).To(Equal([]string{"eu-west-1a"}))
+		})
+
+		It("does nothing to current configs", func() {
+			migrated, applied, err := Migrate(Config{SchemaVersion: CurrentSchemaVersion})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(applied).To(BeEmpty())
+			Expect(migrated).To(Equal(Config{SchemaVersion: CurrentSchemaVersion}))
+		})
+
+		It("refuses configs written by a newer concourse-up", func() {
+			_, _, err := Migrate(Config{SchemaVersion: CurrentSchemaVersion + 1})
+			Expect(err).To(MatchError(ContainSubstring("upgrade concourse-up")))
+		})
+	})
+
+	Describe("Client", func() {
+		var provider *iaasfakes.FakeProvider
+		var files map[string][]byte
+		var client *Client
+
+		BeforeEach(func() {
+			files = map[string][]byte{}
+			provider = &iaasfakes.FakeProvider{}
+			provider.RegionReturns("eu-west-1")
+			provider.HasFileStub = func(bucket, path string) (bool, error) {
+				_, ok := files[path]
+				return ok, nil
+			}
+			provider.LoadFileStub = func(bucket, path string) ([]byte, error) {
+				return files[path], nil
+			}
+			provider.WriteFileStub = func(bucket, path string, contents []byte) error {
+				files[path] = contents
+				return nil
+			}
+			client = New(provider, "test", "")
+
+			contents, err := json.Marshal(Config{Deployment: "concourse-up-test"})
+			Expect(err).ToNot(HaveOccurred())
+			files["config.json"] = contents
+		})
+
+		It("describes the migrations without running them on a dry run", func() {
+			plan, err := client.MigrateConfig(true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plan.Pending()).To(BeTrue())
+			Expect(plan.From).To(Equal(0))
+			Expect(plan.To).To(Equal(CurrentSchemaVersion))
+			Expect(plan.Changes).To(ContainElement(FieldChange{Field: "network_cidr", From: "", To: "10.0.0.0/16"}))
+			Expect(plan.Backup).To(BeEmpty())
+			Expect(provider.WriteFileCallCount()).To(BeZero())
+		})
+
+		It("migrates in memory on load, leaving the stored config alone", func() {
+			original := files["config.json"]
+			conf, err := client.Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conf.SchemaVersion).To(Equal(CurrentSchemaVersion))
+			Expect(conf.NetworkCIDR).To(Equal("10.0.0.0/16"))
+			Expect(provider.WriteFileCallCount()).To(BeZero())
+			Expect(files["config.json"]).To(Equal(original))
+		})
+
+		It("backs up the config before storing the migrated one", func() {
+			original := files["config.json"]
+			plan, err := client.MigrateConfig(false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plan.To).To(Equal(CurrentSchemaVersion))
+
+			var backups []string
+			for path := range files {
+				if strings.HasPrefix(path, "config-backups/config-schema-0-") {
+					backups = append(backups, path)
+				}
+			}
+			Expect(backups).To(HaveLen(1))
+			Expect(files[backups[0]]).To(Equal(original))
+
+			var stored Config
+			Expect(json.Unmarshal(files["config.json"], &stored)).To(Succeed())
+			Expect(stored.Deployment).To(Equal("concourse-up-test"))
+			Expect(stored.SchemaVersion).To(Equal(CurrentSchemaVersion))
+		})
+
+		It("moves the secrets of older configs out of config.json", func() {
+			files["config.json"] = []byte(`{"deployment":"concourse-up-test","rds_password":"s3cret"}`)
+			conf, err := client.Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conf.RDSPassword).To(Equal("s3cret"))
+
+			_, err = client.MigrateConfig(false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(files["config.json"])).ToNot(ContainSubstring("s3cret"))
+			Expect(string(files[SecretsFilename])).To(ContainSubstring("s3cret"))
+
+			conf, err = client.Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conf.RDSPassword).To(Equal("s3cret"))
+		})
+	})
+})
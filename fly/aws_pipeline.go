@@ -3,6 +3,7 @@ package fly
 import (
 	"strings"
 
+	"github.com/EngineerBetter/concourse-up/iaas"
 	"github.com/aws/aws-sdk-go/aws/session"
 )
 
@@ -14,6 +15,12 @@ type AWSPipeline struct {
 	credsGetter AWSCredsGetter
 }
 
+func init() {
+	Register(iaas.AWS, func(iaas.Provider) (Pipeline, error) {
+		return NewAWSPipeline(getCredsFromSession), nil
+	})
+}
+
 // NewAWSPipeline return AWSPipeline
 func NewAWSPipeline(getter AWSCredsGetter) Pipeline {
 	return AWSPipeline{credsGetter: getter}
@@ -35,7 +42,7 @@ var getCredsFromSession = func() (string, string, error) {
 }
 
 //BuildPipelineParams builds params for AWS concourse-up self update pipeline
-func (a AWSPipeline) BuildPipelineParams(deployment, namespace, region, domain string) (Pipeline, error) {
+func (a AWSPipeline) BuildPipelineParams(deployment, namespace, region, domain string, env map[string]string) (Pipeline, error) {
 	accessKeyID, secretAccessKey, err := a.credsGetter()
 	if err != nil {
 		return nil, err
@@ -48,6 +55,7 @@ func (a AWSPipeline) BuildPipelineParams(deployment, namespace, region, domain s
 			Domain:             domain,
 			Namespace:          namespace,
 			Region:             region,
+			Env:                env,
 		},
 		AWSAccessKeyID:     accessKeyID,
 		AWSSecretAccessKey: secretAccessKey,
@@ -76,7 +84,8 @@ jobs:
       AWS_ACCESS_KEY_ID: "{{ .AWSAccessKeyID }}"
       AWS_SECRET_ACCESS_KEY: "{{ .AWSSecretAccessKey }}"
       SELF_UPDATE: true
-      NAMESPACE: {{ .Namespace }}
+      CONCOURSE_UP_LOCK_OWNER: self-update pipeline
+      NAMESPACE: {{ .Namespace }}` + envParams + `
     config:
       platform: linux
       image_resource:
@@ -110,7 +119,8 @@ jobs:
       AWS_ACCESS_KEY_ID: "{{ .AWSAccessKeyID }}"
       AWS_SECRET_ACCESS_KEY: "{{ .AWSSecretAccessKey }}"
       SELF_UPDATE: true
-      NAMESPACE: {{ .Namespace }}
+      CONCOURSE_UP_LOCK_OWNER: self-update pipeline
+      NAMESPACE: {{ .Namespace }}` + envParams + `
     config:
       platform: linux
       image_resource:
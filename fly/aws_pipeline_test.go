@@ -1,6 +1,8 @@
 package fly_test
 
 import (
+	"strings"
+
 	. "github.com/EngineerBetter/concourse-up/fly"
 	"github.com/EngineerBetter/concourse-up/util"
 	. "github.com/onsi/ginkgo"
@@ -36,6 +38,7 @@ jobs:
       AWS_ACCESS_KEY_ID: "access-key"
       AWS_SECRET_ACCESS_KEY: "secret-key"
       SELF_UPDATE: true
+      CONCOURSE_UP_LOCK_OWNER: self-update pipeline
       NAMESPACE: prod
     config:
       platform: linux
@@ -70,6 +73,7 @@ jobs:
       AWS_ACCESS_KEY_ID: "access-key"
       AWS_SECRET_ACCESS_KEY: "secret-key"
       SELF_UPDATE: true
+      CONCOURSE_UP_LOCK_OWNER: self-update pipeline
       NAMESPACE: prod
     config:
       platform: linux
@@ -110,7 +114,7 @@ jobs:
 
 			pipeline := NewAWSPipeline(fakeCredsGetter)
 
-			params, err := pipeline.BuildPipelineParams("my-deployment", "prod", "eu-west-1", "ci.engineerbetter.com")
+			params, err := pipeline.BuildPipelineParams("my-deployment", "prod", "eu-west-1", "ci.engineerbetter.com", nil)
 			Expect(err).ToNot(HaveOccurred())
 
 			yamlBytes, err := util.RenderTemplate("self-update pipeline", pipeline.GetConfigTemplate(), params)
@@ -119,6 +123,26 @@ jobs:
 			actual := string(yamlBytes)
 			Expect(actual).To(Equal(expected))
 		})
+
+		It("Passes what the jobs need to read the config to both of them", func() {
+			fakeCredsGetter := func() (string, string, error) {
+				return "access-key", "secret-key", nil
+			}
+
+			pipeline := NewAWSPipeline(fakeCredsGetter)
+
+			params, err := pipeline.BuildPipelineParams("my-deployment", "prod", "eu-west-1", "ci.engineerbetter.com", map[string]string{
+				"CONCOURSE_UP_PASSPHRASE":     `pass"phrase`,
+				"CONCOURSE_UP_CONFIG_BACKEND": "s3://minio.local:9000/configs",
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			yamlBytes, err := util.RenderTemplate("self-update pipeline", pipeline.GetConfigTemplate(), params)
+			Expect(err).ToNot(HaveOccurred())
+
+			actual := string(yamlBytes)
+			Expect(strings.Count(actual, "      NAMESPACE: prod\n      CONCOURSE_UP_CONFIG_BACKEND: \"s3://minio.local:9000/configs\"\n      CONCOURSE_UP_PASSPHRASE: \"pass\\\"phrase\"\n")).To(Equal(2))
+		})
 	})
 })
 
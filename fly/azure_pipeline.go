@@ -0,0 +1,150 @@
+package fly
+
+import (
+	"strings"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
+)
+
+// AzurePipeline is Azure specific implementation of Pipeline interface
+type AzurePipeline struct {
+	PipelineTemplateParams
+	SubscriptionID string
+	TenantID       string
+	ClientID       string
+	ClientSecret   string
+}
+
+func init() {
+	Register(iaas.Azure, func(provider iaas.Provider) (Pipeline, error) {
+		subscriptionID, err := provider.Attr("subscription_id")
+		if err != nil {
+			return nil, err
+		}
+		tenantID, err := provider.Attr("tenant_id")
+		if err != nil {
+			return nil, err
+		}
+		clientID, err := provider.Attr("client_id")
+		if err != nil {
+			return nil, err
+		}
+		clientSecret, err := provider.Attr("client_secret")
+		if err != nil {
+			return nil, err
+		}
+		return NewAzurePipeline(subscriptionID, tenantID, clientID, clientSecret), nil
+	})
+}
+
+// NewAzurePipeline return AzurePipeline
+func NewAzurePipeline(subscriptionID, tenantID, clientID, clientSecret string) Pipeline {
+	return AzurePipeline{
+		SubscriptionID: subscriptionID,
+		TenantID:       tenantID,
+		ClientID:       clientID,
+		ClientSecret:   clientSecret,
+	}
+}
+
+//BuildPipelineParams builds params for Azure concourse-up self update pipeline
+func (a AzurePipeline) BuildPipelineParams(deployment, namespace, region, domain string, env map[string]string) (Pipeline, error) {
+	return AzurePipeline{
+		PipelineTemplateParams: PipelineTemplateParams{
+			ConcourseUpVersion: ConcourseUpVersion,
+			Deployment:         strings.TrimPrefix(deployment, "concourse-up-"),
+			Domain:             domain,
+			Namespace:          namespace,
+			Region:             region,
+			Env:                env,
+		},
+		SubscriptionID: a.SubscriptionID,
+		TenantID:       a.TenantID,
+		ClientID:       a.ClientID,
+		ClientSecret:   a.ClientSecret,
+	}, nil
+}
+
+// GetConfigTemplate returns template for Azure Concourse Up self update pipeline
+func (a AzurePipeline) GetConfigTemplate() string {
+	return azurePipelineTemplate
+}
+
+const azurePipelineTemplate = `
+---` + selfUpdateResources + `
+jobs:
+- name: self-update
+  serial_groups: [cup]
+  serial: true
+  plan:
+  - get: concourse-up-release
+    trigger: true
+  - task: update
+    params:
+      AWS_REGION: "{{ .Region }}"
+      DEPLOYMENT: "{{ .Deployment }}"
+      IAAS: AZURE
+      SELF_UPDATE: true
+      CONCOURSE_UP_LOCK_OWNER: self-update pipeline
+      NAMESPACE: {{ .Namespace }}` + envParams + `
+      ARM_SUBSCRIPTION_ID: "{{ .SubscriptionID }}"
+      ARM_TENANT_ID: "{{ .TenantID }}"
+      ARM_CLIENT_ID: "{{ .ClientID }}"
+      ARM_CLIENT_SECRET: "{{ .ClientSecret }}"
+    config:
+      platform: linux
+      image_resource:
+        type: docker-image
+        source:
+          repository: engineerbetter/pcf-ops
+      inputs:
+      - name: concourse-up-release
+      run:
+        path: bash
+        args:
+        - -c
+        - |
+          set -eux
+          cd concourse-up-release
+          chmod +x concourse-up-linux-amd64
+          ./concourse-up-linux-amd64 deploy $DEPLOYMENT
+- name: renew-https-cert
+  serial_groups: [cup]
+  serial: true
+  plan:
+  - get: concourse-up-release
+    version: {tag: "{{ .ConcourseUpVersion }}" }
+  - get: every-day
+    trigger: true
+  - task: update
+    params:
+      AWS_REGION: "{{ .Region }}"
+      DEPLOYMENT: "{{ .Deployment }}"
+      IAAS: AZURE
+      SELF_UPDATE: true
+      CONCOURSE_UP_LOCK_OWNER: self-update pipeline
+      NAMESPACE: "{{ .Namespace }}"` + envParams + `
+      ARM_SUBSCRIPTION_ID: "{{ .SubscriptionID }}"
+      ARM_TENANT_ID: "{{ .TenantID }}"
+      ARM_CLIENT_ID: "{{ .ClientID }}"
+      ARM_CLIENT_SECRET: "{{ .ClientSecret }}"
+    config:
+      platform: linux
+      image_resource:
+        type: docker-image
+        source:
+          repository: engineerbetter/pcf-ops
+      inputs:
+      - name: concourse-up-release
+      run:
+        path: bash
+        args:
+        - -c
+        - |
+          set -euxo pipefail
+          cd concourse-up-release
+          chmod +x concourse-up-linux-amd64
+` + renewCertsDateCheck + `
+          echo Certificates expire in $days_until_expiry days, redeploying to renew them
+          ./concourse-up-linux-amd64 deploy $DEPLOYMENT
+`
@@ -0,0 +1,126 @@
+package fly_test
+
+import (
+	. "github.com/EngineerBetter/concourse-up/fly"
+	"github.com/EngineerBetter/concourse-up/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AzurePipeline", func() {
+	Describe("Generating a pipeline YAML", func() {
+		var expected = `
+---
+resources:
+- name: concourse-up-release
+  type: github-release
+  source:
+    user: engineerbetter
+    repository: concourse-up
+    pre_release: true
+- name: every-day
+  type: time
+  source: {interval: 24h}
+
+jobs:
+- name: self-update
+  serial_groups: [cup]
+  serial: true
+  plan:
+  - get: concourse-up-release
+    trigger: true
+  - task: update
+    params:
+      AWS_REGION: "westeurope"
+      DEPLOYMENT: "my-deployment"
+      IAAS: AZURE
+      SELF_UPDATE: true
+      CONCOURSE_UP_LOCK_OWNER: self-update pipeline
+      NAMESPACE: prod
+      ARM_SUBSCRIPTION_ID: "sub-id"
+      ARM_TENANT_ID: "tenant-id"
+      ARM_CLIENT_ID: "client-id"
+      ARM_CLIENT_SECRET: "client-secret"
+    config:
+      platform: linux
+      image_resource:
+        type: docker-image
+        source:
+          repository: engineerbetter/pcf-ops
+      inputs:
+      - name: concourse-up-release
+      run:
+        path: bash
+        args:
+        - -c
+        - |
+          set -eux
+          cd concourse-up-release
+          chmod +x concourse-up-linux-amd64
+          ./concourse-up-linux-amd64 deploy $DEPLOYMENT
+- name: renew-https-cert
+  serial_groups: [cup]
+  serial: true
+  plan:
+  - get: concourse-up-release
+    version: {tag: "COMPILE_TIME_VARIABLE_fly_concourse_up_version" }
+  - get: every-day
+    trigger: true
+  - task: update
+    params:
+      AWS_REGION: "westeurope"
+      DEPLOYMENT: "my-deployment"
+      IAAS: AZURE
+      SELF_UPDATE: true
+      CONCOURSE_UP_LOCK_OWNER: self-update pipeline
+      NAMESPACE: "prod"
+      ARM_SUBSCRIPTION_ID: "sub-id"
+      ARM_TENANT_ID: "tenant-id"
+      ARM_CLIENT_ID: "client-id"
+      ARM_CLIENT_SECRET: "client-secret"
+    config:
+      platform: linux
+      image_resource:
+        type: docker-image
+        source:
+          repository: engineerbetter/pcf-ops
+      inputs:
+      - name: concourse-up-release
+      run:
+        path: bash
+        args:
+        - -c
+        - |
+          set -euxo pipefail
+          cd concourse-up-release
+          chmod +x concourse-up-linux-amd64
+
+          now_seconds=$(date +%s)
+          not_after=$(echo | openssl s_client -connect ci.engineerbetter.com:443 2>/dev/null | openssl x509 -noout -enddate)
+          expires_on=${not_after#'notAfter='}
+          expires_on_seconds=$(date --date="$expires_on" +%s)
+          let "seconds_until_expiry = $expires_on_seconds - $now_seconds"
+          let "days_until_expiry = $seconds_until_expiry / 60 / 60 / 24"
+          if [ $days_until_expiry -gt 2 ]; then
+            echo Not renewing HTTPS cert, as they do not expire in the next two days.
+            exit 0
+          fi
+
+          echo Certificates expire in $days_until_expiry days, redeploying to renew them
+          ./concourse-up-linux-amd64 deploy $DEPLOYMENT
+`
+
+		It("Generates something sensible", func() {
+			pipeline := NewAzurePipeline("sub-id", "tenant-id", "client-id", "client-secret")
+
+			params, err := pipeline.BuildPipelineParams("my-deployment", "prod", "westeurope", "ci.engineerbetter.com", nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			yamlBytes, err := util.RenderTemplate("self-update pipeline", pipeline.GetConfigTemplate(), params)
+			Expect(err).ToNot(HaveOccurred())
+
+			actual := string(yamlBytes)
+			Expect(actual).To(Equal(expected))
+		})
+	})
+})
@@ -3,7 +3,6 @@ package fly
 import (
 	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -27,9 +26,16 @@ var ConcourseUpVersion = "COMPILE_TIME_VARIABLE_fly_concourse_up_version"
 type IClient interface {
 	CanConnect() (bool, error)
 	SetDefaultPipeline(config config.Config, allowFlyVersionDiscrepancy bool) error
+	Workers() ([]Worker, error)
 	Cleanup() error
 }
 
+// Worker represents a worker registered with Concourse
+type Worker struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
 // Client represents a low-level wrapper for fly
 type Client struct {
 	pipeline    Pipeline
@@ -47,6 +53,10 @@ type Credentials struct {
 	Username string
 	Password string
 	CACert   string
+	// Proxy is the URL of the proxy to reach Concourse through, or empty to connect directly
+	Proxy string
+	// PipelineEnv is given to the self-update pipeline, so that it can read the config wherever it is kept
+	PipelineEnv map[string]string
 }
 
 // New returns a new fly client
@@ -85,23 +95,9 @@ func New(provider iaas.Provider, creds Credentials, stdout, stderr io.Writer, ve
 		return nil, err
 	}
 
-	var pipeline Pipeline
-
-	switch provider.IAAS() {
-	case iaas.AWS:
-		pipeline = NewAWSPipeline(getCredsFromSession)
-	case iaas.GCP:
-		GCPCreds, err := provider.Attr("credentials_path")
-		if err != nil {
-			return nil, err
-		}
-		pipeline, err = NewGCPPipeline(GCPCreds)
-		if err != nil {
-			return nil, errors.New("fly.go: failed to read credentials file")
-		}
-	default:
-		return nil, errors.New("fly.go: IAAS not recognised")
-
+	pipeline, err := newPipeline(provider)
+	if err != nil {
+		return nil, err
 	}
 	return &Client{
 		pipeline,
@@ -118,7 +114,14 @@ var (
 )
 
 func (client *Client) runFly(args ...string) *exec.Cmd {
-	return execCommand(client.tempDir.Path("fly"), args...)
+	cmd := execCommand(client.tempDir.Path("fly"), args...)
+	if client.creds.Proxy != "" {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, "HTTPS_PROXY="+client.creds.Proxy)
+	}
+	return cmd
 }
 
 // CanConnect returns true if it can connect to the concourse
@@ -206,7 +209,7 @@ func (client *Client) writePipelineConfig(pipelinePath string, config config.Con
 	}
 	defer fileHandler.Close()
 
-	params, err := client.pipeline.BuildPipelineParams(config.Deployment, config.Namespace, config.Region, config.Domain)
+	params, err := client.pipeline.BuildPipelineParams(config.Deployment, config.Namespace, config.Region, config.Domain, client.creds.PipelineEnv)
 	if err != nil {
 		return err
 	}
@@ -227,6 +230,31 @@ func (client *Client) writePipelineConfig(pipelinePath string, config config.Con
 	return nil
 }
 
+// Workers logs in once, without waiting for Concourse to start, and lists the registered workers
+func (client *Client) Workers() ([]Worker, error) {
+	canConnect, err := client.CanConnect()
+	if err != nil {
+		return nil, err
+	}
+	if !canConnect {
+		return nil, fmt.Errorf("could not reach the Concourse server at %s", client.creds.API)
+	}
+
+	var stdout bytes.Buffer
+	cmd := client.runFly("--target", client.creds.Target, "workers", "--json")
+	cmd.Stdout = &stdout
+	cmd.Stderr = client.stderr
+	if err = cmd.Run(); err != nil {
+		return nil, err
+	}
+
+	var workers []Worker
+	if err = json.Unmarshal(stdout.Bytes(), &workers); err != nil {
+		return nil, fmt.Errorf("error parsing fly workers output: [%v]", err)
+	}
+	return workers, nil
+}
+
 // Cleanup removes tempfiles
 func (client *Client) Cleanup() error {
 	return client.tempDir.Cleanup()
@@ -3,6 +3,7 @@ package fly
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"reflect"
@@ -82,3 +83,49 @@ func TestClient_runFly(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_runFly_Proxy(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	tmpDir, _ := util.NewTempDir()
+	defer tmpDir.Cleanup()
+	client := &Client{
+		tempDir: tmpDir,
+		creds:   Credentials{Proxy: "socks5://127.0.0.1:1080"},
+	}
+	cmd := client.runFly("sync")
+	var proxied, helper bool
+	for _, env := range cmd.Env {
+		proxied = proxied || env == "HTTPS_PROXY=socks5://127.0.0.1:1080"
+		helper = helper || env == "GO_WANT_HELPER_PROCESS=1"
+	}
+	if !proxied || !helper {
+		t.Errorf("Client.runFly() environment = %v, want it to keep GO_WANT_HELPER_PROCESS and add HTTPS_PROXY", cmd.Env)
+	}
+}
+
+func TestClient_Workers(t *testing.T) {
+	tmpDir, _ := util.NewTempDir()
+	defer tmpDir.Cleanup()
+
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	os.Setenv("TEST_HELPER_OUTPUT", `[{"name":"worker-1","state":"running"},{"name":"worker-2","state":"stalled"}]`)
+	defer os.Unsetenv("TEST_HELPER_OUTPUT")
+
+	client := &Client{
+		tempDir: tmpDir,
+		creds:   Credentials{Target: "test"},
+		stdout:  ioutil.Discard,
+		stderr:  ioutil.Discard,
+	}
+	got, err := client.Workers()
+	if err != nil {
+		t.Fatalf("Client.Workers() error = %v", err)
+	}
+	want := []Worker{{Name: "worker-1", State: "running"}, {Name: "worker-2", State: "stalled"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Client.Workers() = %v, want %v", got, want)
+	}
+}
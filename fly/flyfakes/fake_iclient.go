@@ -43,6 +43,18 @@ type FakeIClient struct {
 	setDefaultPipelineReturnsOnCall map[int]struct {
 		result1 error
 	}
+	WorkersStub        func() ([]fly.Worker, error)
+	workersMutex       sync.RWMutex
+	workersArgsForCall []struct {
+	}
+	workersReturns struct {
+		result1 []fly.Worker
+		result2 error
+	}
+	workersReturnsOnCall map[int]struct {
+		result1 []fly.Worker
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -215,6 +227,61 @@ func (fake *FakeIClient) SetDefaultPipelineReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeIClient) Workers() ([]fly.Worker, error) {
+	fake.workersMutex.Lock()
+	ret, specificReturn := fake.workersReturnsOnCall[len(fake.workersArgsForCall)]
+	fake.workersArgsForCall = append(fake.workersArgsForCall, struct {
+	}{})
+	fake.recordInvocation("Workers", []interface{}{})
+	fake.workersMutex.Unlock()
+	if fake.WorkersStub != nil {
+		return fake.WorkersStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.workersReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIClient) WorkersCallCount() int {
+	fake.workersMutex.RLock()
+	defer fake.workersMutex.RUnlock()
+	return len(fake.workersArgsForCall)
+}
+
+func (fake *FakeIClient) WorkersCalls(stub func() ([]fly.Worker, error)) {
+	fake.workersMutex.Lock()
+	defer fake.workersMutex.Unlock()
+	fake.WorkersStub = stub
+}
+
+func (fake *FakeIClient) WorkersReturns(result1 []fly.Worker, result2 error) {
+	fake.workersMutex.Lock()
+	defer fake.workersMutex.Unlock()
+	fake.WorkersStub = nil
+	fake.workersReturns = struct {
+		result1 []fly.Worker
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) WorkersReturnsOnCall(i int, result1 []fly.Worker, result2 error) {
+	fake.workersMutex.Lock()
+	defer fake.workersMutex.Unlock()
+	fake.WorkersStub = nil
+	if fake.workersReturnsOnCall == nil {
+		fake.workersReturnsOnCall = make(map[int]struct {
+			result1 []fly.Worker
+			result2 error
+		})
+	}
+	fake.workersReturnsOnCall[i] = struct {
+		result1 []fly.Worker
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeIClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -224,6 +291,8 @@ func (fake *FakeIClient) Invocations() map[string][][]interface{} {
 	defer fake.cleanupMutex.RUnlock()
 	fake.setDefaultPipelineMutex.RLock()
 	defer fake.setDefaultPipelineMutex.RUnlock()
+	fake.workersMutex.RLock()
+	defer fake.workersMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
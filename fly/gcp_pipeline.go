@@ -1,8 +1,11 @@
 package fly
 
 import (
+	"errors"
 	"io/ioutil"
 	"strings"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
 )
 
 // GCPPipeline is GCP specific implementation of Pipeline interface
@@ -11,6 +14,20 @@ type GCPPipeline struct {
 	GCPCreds string
 }
 
+func init() {
+	Register(iaas.GCP, func(provider iaas.Provider) (Pipeline, error) {
+		credsPath, err := provider.Attr("credentials_path")
+		if err != nil {
+			return nil, err
+		}
+		pipeline, err := NewGCPPipeline(credsPath)
+		if err != nil {
+			return nil, errors.New("fly.go: failed to read credentials file")
+		}
+		return pipeline, nil
+	})
+}
+
 // NewGCPPipeline return GCPPipeline
 func NewGCPPipeline(credsPath string) (Pipeline, error) {
 	creds, err := readFileContents(credsPath)
@@ -23,7 +40,7 @@ func NewGCPPipeline(credsPath string) (Pipeline, error) {
 }
 
 //BuildPipelineParams builds params for AWS concourse-up self update pipeline
-func (a GCPPipeline) BuildPipelineParams(deployment, namespace, region, domain string) (Pipeline, error) {
+func (a GCPPipeline) BuildPipelineParams(deployment, namespace, region, domain string, env map[string]string) (Pipeline, error) {
 	return GCPPipeline{
 		PipelineTemplateParams: PipelineTemplateParams{
 			ConcourseUpVersion: ConcourseUpVersion,
@@ -31,6 +48,7 @@ func (a GCPPipeline) BuildPipelineParams(deployment, namespace, region, domain s
 			Domain:             domain,
 			Namespace:          namespace,
 			Region:             region,
+			Env:                env,
 		},
 		GCPCreds: a.GCPCreds,
 	}, nil
@@ -65,7 +83,8 @@ jobs:
       DEPLOYMENT: "{{ .Deployment }}"
       IAAS: GCP
       SELF_UPDATE: true
-      NAMESPACE: {{ .Namespace }}
+      CONCOURSE_UP_LOCK_OWNER: self-update pipeline
+      NAMESPACE: {{ .Namespace }}` + envParams + `
       GCPCreds: '{{ .GCPCreds }}'
     config:
       platform: linux
@@ -100,7 +119,8 @@ jobs:
       DEPLOYMENT: "{{ .Deployment }}"
       IAAS: GCP
       SELF_UPDATE: true
-      NAMESPACE: "{{ .Namespace }}"
+      CONCOURSE_UP_LOCK_OWNER: self-update pipeline
+      NAMESPACE: "{{ .Namespace }}"` + envParams + `
       GCPCreds: '{{ .GCPCreds }}'
     config:
       platform: linux
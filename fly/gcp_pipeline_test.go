@@ -38,6 +38,7 @@ jobs:
       DEPLOYMENT: "my-deployment"
       IAAS: GCP
       SELF_UPDATE: true
+      CONCOURSE_UP_LOCK_OWNER: self-update pipeline
       NAMESPACE: prod
       GCPCreds: 'creds-content'
     config:
@@ -73,6 +74,7 @@ jobs:
       DEPLOYMENT: "my-deployment"
       IAAS: GCP
       SELF_UPDATE: true
+      CONCOURSE_UP_LOCK_OWNER: self-update pipeline
       NAMESPACE: "prod"
       GCPCreds: 'creds-content'
     config:
@@ -121,7 +123,7 @@ jobs:
 			pipeline, err := NewGCPPipeline(tempFile.Name())
 			Expect(err).ToNot(HaveOccurred())
 
-			params, err := pipeline.BuildPipelineParams("my-deployment", "prod", "europe-west1", "ci.engineerbetter.com")
+			params, err := pipeline.BuildPipelineParams("my-deployment", "prod", "europe-west1", "ci.engineerbetter.com", nil)
 			Expect(err).ToNot(HaveOccurred())
 
 			yamlBytes, err := util.RenderTemplate("self-update pipeline", pipeline.GetConfigTemplate(), params)
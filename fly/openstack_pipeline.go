@@ -0,0 +1,182 @@
+package fly
+
+import (
+	"strings"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
+)
+
+// OpenStackPipeline is OpenStack specific implementation of Pipeline interface
+type OpenStackPipeline struct {
+	PipelineTemplateParams
+	AuthURL           string
+	Username          string
+	Password          string
+	ProjectName       string
+	UserDomainName    string
+	ProjectDomainName string
+	ExternalNetwork   string
+	DBImage           string
+	WebFlavors        string
+	WorkerFlavors     string
+	DBFlavors         string
+}
+
+// OpenStackAttrs are the attributes of the OpenStack provider NewOpenStackPipeline passes on to the pipeline
+var OpenStackAttrs = []string{
+	"auth_url",
+	"username",
+	"password",
+	"project_name",
+	"user_domain_name",
+	"project_domain_name",
+	"external_network",
+	"db_image",
+	"web_flavors",
+	"worker_flavors",
+	"db_flavors",
+}
+
+func init() {
+	Register(iaas.OpenStack, func(provider iaas.Provider) (Pipeline, error) {
+		attrs := make(map[string]string)
+		for _, attr := range OpenStackAttrs {
+			value, err := provider.Attr(attr)
+			if err != nil {
+				return nil, err
+			}
+			attrs[attr] = value
+		}
+		return NewOpenStackPipeline(attrs), nil
+	})
+}
+
+// NewOpenStackPipeline return OpenStackPipeline, given the OpenStackAttrs of the provider
+func NewOpenStackPipeline(attrs map[string]string) Pipeline {
+	return OpenStackPipeline{
+		AuthURL:           attrs["auth_url"],
+		Username:          attrs["username"],
+		Password:          attrs["password"],
+		ProjectName:       attrs["project_name"],
+		UserDomainName:    attrs["user_domain_name"],
+		ProjectDomainName: attrs["project_domain_name"],
+		ExternalNetwork:   attrs["external_network"],
+		DBImage:           attrs["db_image"],
+		WebFlavors:        attrs["web_flavors"],
+		WorkerFlavors:     attrs["worker_flavors"],
+		DBFlavors:         attrs["db_flavors"],
+	}
+}
+
+//BuildPipelineParams builds params for OpenStack concourse-up self update pipeline
+func (o OpenStackPipeline) BuildPipelineParams(deployment, namespace, region, domain string, env map[string]string) (Pipeline, error) {
+	o.PipelineTemplateParams = PipelineTemplateParams{
+		ConcourseUpVersion: ConcourseUpVersion,
+		Deployment:         strings.TrimPrefix(deployment, "concourse-up-"),
+		Domain:             domain,
+		Namespace:          namespace,
+		Region:             region,
+		Env:                env,
+	}
+	return o, nil
+}
+
+// GetConfigTemplate returns template for OpenStack Concourse Up self update pipeline
+func (o OpenStackPipeline) GetConfigTemplate() string {
+	return openstackPipelineTemplate
+}
+
+const openstackPipelineTemplate = `
+---` + selfUpdateResources + `
+jobs:
+- name: self-update
+  serial_groups: [cup]
+  serial: true
+  plan:
+  - get: concourse-up-release
+    trigger: true
+  - task: update
+    params:
+      AWS_REGION: "{{ .Region }}"
+      DEPLOYMENT: "{{ .Deployment }}"
+      IAAS: OPENSTACK
+      SELF_UPDATE: true
+      CONCOURSE_UP_LOCK_OWNER: self-update pipeline
+      NAMESPACE: {{ .Namespace }}` + envParams + `
+      OS_AUTH_URL: "{{ .AuthURL }}"
+      OS_USERNAME: "{{ .Username }}"
+      OS_PASSWORD: "{{ .Password }}"
+      OS_PROJECT_NAME: "{{ .ProjectName }}"
+      OS_USER_DOMAIN_NAME: "{{ .UserDomainName }}"
+      OS_PROJECT_DOMAIN_NAME: "{{ .ProjectDomainName }}"
+      OS_REGION_NAME: "{{ .Region }}"
+      OS_EXTERNAL_NETWORK: "{{ .ExternalNetwork }}"
+      OS_DB_IMAGE: "{{ .DBImage }}"
+      OS_WEB_FLAVORS: "{{ .WebFlavors }}"
+      OS_WORKER_FLAVORS: "{{ .WorkerFlavors }}"
+      OS_DB_FLAVORS: "{{ .DBFlavors }}"
+    config:
+      platform: linux
+      image_resource:
+        type: docker-image
+        source:
+          repository: engineerbetter/pcf-ops
+      inputs:
+      - name: concourse-up-release
+      run:
+        path: bash
+        args:
+        - -c
+        - |
+          set -eux
+          cd concourse-up-release
+          chmod +x concourse-up-linux-amd64
+          ./concourse-up-linux-amd64 deploy $DEPLOYMENT
+- name: renew-https-cert
+  serial_groups: [cup]
+  serial: true
+  plan:
+  - get: concourse-up-release
+    version: {tag: "{{ .ConcourseUpVersion }}" }
+  - get: every-day
+    trigger: true
+  - task: update
+    params:
+      AWS_REGION: "{{ .Region }}"
+      DEPLOYMENT: "{{ .Deployment }}"
+      IAAS: OPENSTACK
+      SELF_UPDATE: true
+      CONCOURSE_UP_LOCK_OWNER: self-update pipeline
+      NAMESPACE: "{{ .Namespace }}"` + envParams + `
+      OS_AUTH_URL: "{{ .AuthURL }}"
+      OS_USERNAME: "{{ .Username }}"
+      OS_PASSWORD: "{{ .Password }}"
+      OS_PROJECT_NAME: "{{ .ProjectName }}"
+      OS_USER_DOMAIN_NAME: "{{ .UserDomainName }}"
+      OS_PROJECT_DOMAIN_NAME: "{{ .ProjectDomainName }}"
+      OS_REGION_NAME: "{{ .Region }}"
+      OS_EXTERNAL_NETWORK: "{{ .ExternalNetwork }}"
+      OS_DB_IMAGE: "{{ .DBImage }}"
+      OS_WEB_FLAVORS: "{{ .WebFlavors }}"
+      OS_WORKER_FLAVORS: "{{ .WorkerFlavors }}"
+      OS_DB_FLAVORS: "{{ .DBFlavors }}"
+    config:
+      platform: linux
+      image_resource:
+        type: docker-image
+        source:
+          repository: engineerbetter/pcf-ops
+      inputs:
+      - name: concourse-up-release
+      run:
+        path: bash
+        args:
+        - -c
+        - |
+          set -euxo pipefail
+          cd concourse-up-release
+          chmod +x concourse-up-linux-amd64
+` + renewCertsDateCheck + `
+          echo Certificates expire in $days_until_expiry days, redeploying to renew them
+          ./concourse-up-linux-amd64 deploy $DEPLOYMENT
+`
@@ -0,0 +1,152 @@
+package fly_test
+
+import (
+	. "github.com/EngineerBetter/concourse-up/fly"
+	"github.com/EngineerBetter/concourse-up/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OpenStackPipeline", func() {
+	Describe("Generating a pipeline YAML", func() {
+		var expected = `
+---
+resources:
+- name: concourse-up-release
+  type: github-release
+  source:
+    user: engineerbetter
+    repository: concourse-up
+    pre_release: true
+- name: every-day
+  type: time
+  source: {interval: 24h}
+
+jobs:
+- name: self-update
+  serial_groups: [cup]
+  serial: true
+  plan:
+  - get: concourse-up-release
+    trigger: true
+  - task: update
+    params:
+      AWS_REGION: "RegionOne"
+      DEPLOYMENT: "my-deployment"
+      IAAS: OPENSTACK
+      SELF_UPDATE: true
+      CONCOURSE_UP_LOCK_OWNER: self-update pipeline
+      NAMESPACE: prod
+      OS_AUTH_URL: "https://keystone.example.com:5000/v3"
+      OS_USERNAME: "user"
+      OS_PASSWORD: "password"
+      OS_PROJECT_NAME: "project"
+      OS_USER_DOMAIN_NAME: "Default"
+      OS_PROJECT_DOMAIN_NAME: "Default"
+      OS_REGION_NAME: "RegionOne"
+      OS_EXTERNAL_NETWORK: "public"
+      OS_DB_IMAGE: "ubuntu-18.04"
+      OS_WEB_FLAVORS: ""
+      OS_WORKER_FLAVORS: "xlarge=c4.large"
+      OS_DB_FLAVORS: ""
+    config:
+      platform: linux
+      image_resource:
+        type: docker-image
+        source:
+          repository: engineerbetter/pcf-ops
+      inputs:
+      - name: concourse-up-release
+      run:
+        path: bash
+        args:
+        - -c
+        - |
+          set -eux
+          cd concourse-up-release
+          chmod +x concourse-up-linux-amd64
+          ./concourse-up-linux-amd64 deploy $DEPLOYMENT
+- name: renew-https-cert
+  serial_groups: [cup]
+  serial: true
+  plan:
+  - get: concourse-up-release
+    version: {tag: "COMPILE_TIME_VARIABLE_fly_concourse_up_version" }
+  - get: every-day
+    trigger: true
+  - task: update
+    params:
+      AWS_REGION: "RegionOne"
+      DEPLOYMENT: "my-deployment"
+      IAAS: OPENSTACK
+      SELF_UPDATE: true
+      CONCOURSE_UP_LOCK_OWNER: self-update pipeline
+      NAMESPACE: "prod"
+      OS_AUTH_URL: "https://keystone.example.com:5000/v3"
+      OS_USERNAME: "user"
+      OS_PASSWORD: "password"
+      OS_PROJECT_NAME: "project"
+      OS_USER_DOMAIN_NAME: "Default"
+      OS_PROJECT_DOMAIN_NAME: "Default"
+      OS_REGION_NAME: "RegionOne"
+      OS_EXTERNAL_NETWORK: "public"
+      OS_DB_IMAGE: "ubuntu-18.04"
+      OS_WEB_FLAVORS: ""
+      OS_WORKER_FLAVORS: "xlarge=c4.large"
+      OS_DB_FLAVORS: ""
+    config:
+      platform: linux
+      image_resource:
+        type: docker-image
+        source:
+          repository: engineerbetter/pcf-ops
+      inputs:
+      - name: concourse-up-release
+      run:
+        path: bash
+        args:
+        - -c
+        - |
+          set -euxo pipefail
+          cd concourse-up-release
+          chmod +x concourse-up-linux-amd64
+
+          now_seconds=$(date +%s)
+          not_after=$(echo | openssl s_client -connect ci.engineerbetter.com:443 2>/dev/null | openssl x509 -noout -enddate)
+          expires_on=${not_after#'notAfter='}
+          expires_on_seconds=$(date --date="$expires_on" +%s)
+          let "seconds_until_expiry = $expires_on_seconds - $now_seconds"
+          let "days_until_expiry = $seconds_until_expiry / 60 / 60 / 24"
+          if [ $days_until_expiry -gt 2 ]; then
+            echo Not renewing HTTPS cert, as they do not expire in the next two days.
+            exit 0
+          fi
+
+          echo Certificates expire in $days_until_expiry days, redeploying to renew them
+          ./concourse-up-linux-amd64 deploy $DEPLOYMENT
+`
+
+		It("Generates something sensible", func() {
+			pipeline := NewOpenStackPipeline(map[string]string{
+				"auth_url":            "https://keystone.example.com:5000/v3",
+				"username":            "user",
+				"password":            "password",
+				"project_name":        "project",
+				"user_domain_name":    "Default",
+				"project_domain_name": "Default",
+				"external_network":    "public",
+				"db_image":            "ubuntu-18.04",
+				"worker_flavors":      "xlarge=c4.large",
+			})
+
+			params, err := pipeline.BuildPipelineParams("my-deployment", "prod", "RegionOne", "ci.engineerbetter.com", nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			yamlBytes, err := util.RenderTemplate("self-update pipeline", pipeline.GetConfigTemplate(), params)
+			Expect(err).ToNot(HaveOccurred())
+
+			actual := string(yamlBytes)
+			Expect(actual).To(Equal(expected))
+		})
+	})
+})
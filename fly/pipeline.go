@@ -1,19 +1,50 @@
 package fly
 
+import (
+	"errors"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
+)
+
 // Pipeline is interface for self update pipeline
 type Pipeline interface {
-	BuildPipelineParams(deployment, namespace, region, domain string) (Pipeline, error)
+	BuildPipelineParams(deployment, namespace, region, domain string, env map[string]string) (Pipeline, error)
 	GetConfigTemplate() string
 }
 
+var pipelines = make(map[iaas.Name]func(provider iaas.Provider) (Pipeline, error))
+
+// Register makes the self update pipeline of an IAAS available to New. newPipeline is given the provider,
+// so that it can pass the credentials concourse-up is running with to the pipeline
+func Register(name iaas.Name, newPipeline func(provider iaas.Provider) (Pipeline, error)) {
+	if _, ok := pipelines[name]; ok {
+		panic("fly: Register called twice for " + name.String())
+	}
+	pipelines[name] = newPipeline
+}
+
+func newPipeline(provider iaas.Provider) (Pipeline, error) {
+	newPipeline, ok := pipelines[provider.IAAS()]
+	if !ok {
+		return nil, errors.New("fly.go: IAAS not recognised")
+	}
+	return newPipeline(provider)
+}
+
 type PipelineTemplateParams struct {
 	ConcourseUpVersion string
 	Deployment         string
 	Domain             string
 	Namespace          string
 	Region             string
+	// Env is what the tasks need in their environment to read the config, such as its backend and passphrase
+	Env map[string]string
 }
 
+// envParams passes Env to a task
+const envParams = `{{ range $name, $value := .Env }}
+      {{ $name }}: {{ printf "%q" $value }}{{ end }}`
+
 const selfUpdateResources = `
 resources:
 - name: concourse-up-release
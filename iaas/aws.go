@@ -10,6 +10,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/sts"
 )
 
 // AWSDBSizes maps user set size to RDS instance classes
@@ -35,6 +36,32 @@ type IEC2 interface {
 	DeleteVolume(input *ec2.DeleteVolumeInput) (*ec2.DeleteVolumeOutput, error)
 }
 
+//go:generate counterfeiter . VPCTeardown
+// VPCTeardown deletes the VMs BOSH created in a VPC, and then their volumes once terraform has destroyed the rest
+type VPCTeardown interface {
+	DeleteVMsInVPC(vpcID string, securityGroupIDs ...string) ([]string, error)
+	DeleteVolumes(volumesToDelete []string, deleteVolume func(ec2Client IEC2, volumeID *string) error) error
+}
+
+// AWS is the Name of Amazon Web Services
+var AWS = Register("AWS", Registration{
+	DefaultRegion: func() string { return "eu-west-1" },
+	New:           newAWS,
+	GatewayUser:   "vcap",
+	Ranges: Ranges{
+		Network: "10.0.0.0/16",
+		Public:  "10.0.0.0/24",
+		Private: "10.0.1.0/24",
+		RDS1:    "10.0.4.0/24",
+		RDS2:    "10.0.5.0/24",
+	},
+	DBNameFormat:  "bosh_%s",
+	Private:       true,
+	RegionalZones: true,
+	NetworkFlag:   "--vpc-id",
+	DNSProvider:   "route53",
+})
+
 func newAWS(region string) (Provider, error) {
 	sess, err := session.NewSession(&aws.Config{
 		Region: aws.String(region),
@@ -55,11 +82,6 @@ func (a *AWSProvider) DBType(name string) string {
 	return AWSDBSizes[name]
 }
 
-// Choose for the consumer the appropriate output based on the provider
-func (a *AWSProvider) Choose(c Choice) interface{} {
-	return c.AWS
-}
-
 // Zone is a placeholder for Zone()
 func (a *AWSProvider) Zone(input string) string {
 	if input != "" {
@@ -105,6 +127,15 @@ func (a *AWSProvider) IAAS() Name {
 	return AWS
 }
 
+// Identity returns the ARN of the caller whose credentials are in use
+func (a *AWSProvider) Identity() (string, error) {
+	output, err := sts.New(a.sess).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(output.Arn), nil
+}
+
 func (a *AWSProvider) listZones() ([]string, error) {
 	ec2Client := ec2.New(a.sess)
 	zones := []string{}
@@ -276,26 +307,30 @@ func DeleteVolume(ec2Client IEC2, volumeID *string) error {
 	return err
 }
 
-// DeleteVMsInDeployment is a placeholder for a function used with GCP deployments
-func (a *AWSProvider) DeleteVMsInDeployment(zone, project, deployment string) error {
-	return nil
-}
-
-// DeleteVMsInVPC deletes all the VMs in the given VPC
-func (a *AWSProvider) DeleteVMsInVPC(vpcID string) ([]string, error) {
+// DeleteVMsInVPC deletes the VMs in the given VPC. When securityGroupIDs are given only the VMs in one of those
+// groups are deleted, which leaves alone the other VMs of a VPC that concourse-up did not create
+func (a *AWSProvider) DeleteVMsInVPC(vpcID string, securityGroupIDs ...string) ([]string, error) {
 
 	filterName := "vpc-id"
 	ec2Client := ec2.New(a.sess)
 
-	resp, err := ec2Client.DescribeInstances(&ec2.DescribeInstancesInput{
-		Filters: []*ec2.Filter{
-			&ec2.Filter{
-				Name: &filterName,
-				Values: []*string{
-					&vpcID,
-				},
+	filters := []*ec2.Filter{
+		&ec2.Filter{
+			Name: &filterName,
+			Values: []*string{
+				&vpcID,
 			},
 		},
+	}
+	if len(securityGroupIDs) > 0 {
+		filters = append(filters, &ec2.Filter{
+			Name:   aws.String("instance.group-id"),
+			Values: aws.StringSlice(securityGroupIDs),
+		})
+	}
+
+	resp, err := ec2Client.DescribeInstances(&ec2.DescribeInstancesInput{
+		Filters: filters,
 	})
 	if err != nil {
 		return nil, err
@@ -327,6 +362,60 @@ func (a *AWSProvider) DeleteVMsInVPC(vpcID string) ([]string, error) {
 	return volumesToDelete, nil
 }
 
+// networkDescriber only implements the functions FindNetwork uses
+type networkDescriber interface {
+	DescribeVpcs(input *ec2.DescribeVpcsInput) (*ec2.DescribeVpcsOutput, error)
+	DescribeSubnets(input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error)
+}
+
+// FindNetwork returns the range of the VPC with the ID network, and the ranges and zones of the subnets of it
+// with the IDs subnets, in the order they are given
+func (a *AWSProvider) FindNetwork(network string, subnets ...string) (Network, error) {
+	return findVPC(ec2.New(a.sess), network, subnets)
+}
+
+func findVPC(ec2Client networkDescriber, vpcID string, subnetIDs []string) (Network, error) {
+	vpcs, err := ec2Client.DescribeVpcs(&ec2.DescribeVpcsInput{
+		VpcIds: []*string{aws.String(vpcID)},
+	})
+	if err != nil {
+		return Network{}, fmt.Errorf("error finding VPC %s: [%v]", vpcID, err)
+	}
+	if len(vpcs.Vpcs) != 1 {
+		return Network{}, fmt.Errorf("could not find VPC %s", vpcID)
+	}
+	network := Network{CIDR: aws.StringValue(vpcs.Vpcs[0].CidrBlock)}
+	if len(subnetIDs) == 0 {
+		return network, nil
+	}
+
+	output, err := ec2Client.DescribeSubnets(&ec2.DescribeSubnetsInput{
+		SubnetIds: aws.StringSlice(subnetIDs),
+	})
+	if err != nil {
+		return Network{}, fmt.Errorf("error finding the subnets of VPC %s: [%v]", vpcID, err)
+	}
+	found := make(map[string]*ec2.Subnet)
+	for _, subnet := range output.Subnets {
+		found[aws.StringValue(subnet.SubnetId)] = subnet
+	}
+	for _, id := range subnetIDs {
+		subnet, ok := found[id]
+		if !ok {
+			return Network{}, fmt.Errorf("could not find subnet %s", id)
+		}
+		if aws.StringValue(subnet.VpcId) != vpcID {
+			return Network{}, fmt.Errorf("subnet %s is in VPC %s, not %s", id, aws.StringValue(subnet.VpcId), vpcID)
+		}
+		network.Subnets = append(network.Subnets, Subnet{
+			ID:   id,
+			CIDR: aws.StringValue(subnet.CidrBlock),
+			Zone: aws.StringValue(subnet.AvailabilityZone),
+		})
+	}
+	return network, nil
+}
+
 // ListHostedZones returns a list of hosted zones
 func (a *AWSProvider) ListHostedZones() ([]*route53.HostedZone, error) {
 
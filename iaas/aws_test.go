@@ -0,0 +1,85 @@
+package iaas
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeNetworkDescriber struct {
+	vpcs    []*ec2.Vpc
+	subnets []*ec2.Subnet
+}
+
+func (f *fakeNetworkDescriber) DescribeVpcs(input *ec2.DescribeVpcsInput) (*ec2.DescribeVpcsOutput, error) {
+	return &ec2.DescribeVpcsOutput{Vpcs: f.vpcs}, nil
+}
+
+func (f *fakeNetworkDescriber) DescribeSubnets(input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+	return &ec2.DescribeSubnetsOutput{Subnets: f.subnets}, nil
+}
+
+func TestFindVPC(t *testing.T) {
+	describer := &fakeNetworkDescriber{
+		vpcs: []*ec2.Vpc{{VpcId: aws.String("vpc-1"), CidrBlock: aws.String("10.10.0.0/16")}},
+		subnets: []*ec2.Subnet{
+			{SubnetId: aws.String("subnet-private"), VpcId: aws.String("vpc-1"), CidrBlock: aws.String("10.10.1.0/24"), AvailabilityZone: aws.String("eu-west-1b")},
+			{SubnetId: aws.String("subnet-public"), VpcId: aws.String("vpc-1"), CidrBlock: aws.String("10.10.0.0/24"), AvailabilityZone: aws.String("eu-west-1b")},
+			{SubnetId: aws.String("subnet-other"), VpcId: aws.String("vpc-2"), CidrBlock: aws.String("10.20.0.0/24"), AvailabilityZone: aws.String("eu-west-1a")},
+		},
+	}
+
+	network, err := findVPC(describer, "vpc-1", []string{"subnet-public", "subnet-private"})
+	require.NoError(t, err)
+	require.Equal(t, Network{
+		CIDR: "10.10.0.0/16",
+		Subnets: []Subnet{
+			{ID: "subnet-public", CIDR: "10.10.0.0/24", Zone: "eu-west-1b"},
+			{ID: "subnet-private", CIDR: "10.10.1.0/24", Zone: "eu-west-1b"},
+		},
+	}, network)
+
+	_, err = findVPC(describer, "vpc-1", []string{"subnet-public", "subnet-other"})
+	require.EqualError(t, err, "subnet subnet-other is in VPC vpc-2, not vpc-1")
+
+	_, err = findVPC(describer, "vpc-1", []string{"subnet-missing"})
+	require.EqualError(t, err, "could not find subnet subnet-missing")
+
+	_, err = findVPC(&fakeNetworkDescriber{}, "vpc-1", nil)
+	require.EqualError(t, err, "could not find VPC vpc-1")
+}
+
+func TestCreateS3Object(t *testing.T) {
+	objects := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+		require.Equal(t, "*", r.Header.Get("If-None-Match"))
+		if objects[r.URL.Path] {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			w.Write([]byte(`<Error><Code>PreconditionFailed</Code><Message>At least one of the pre-conditions you specified did not hold</Message></Error>`))
+			return
+		}
+		objects[r.URL.Path] = true
+	}))
+	defer server.Close()
+
+	sess, err := session.NewSession(&aws.Config{
+		Endpoint:         aws.String(server.URL),
+		Region:           aws.String("eu-west-1"),
+		Credentials:      credentials.NewStaticCredentials("access", "secret", ""),
+		S3ForcePathStyle: aws.Bool(true),
+	})
+	require.NoError(t, err)
+	client := s3.New(sess)
+
+	require.NoError(t, CreateS3Object(client, "bucket", "deployment.lock", []byte("mine")))
+	require.Equal(t, ErrFileExists, CreateS3Object(client, "bucket", "deployment.lock", []byte("theirs")))
+	require.Equal(t, map[string]bool{"/bucket/deployment.lock": true}, objects)
+}
@@ -0,0 +1,384 @@
+package iaas
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	azureResourcesAPIVersion = "2019-10-01"
+	azureStorageAPIVersion   = "2019-06-01"
+	azureComputeAPIVersion   = "2019-07-01"
+	azureNetworkAPIVersion   = "2019-11-01"
+	azureDNSAPIVersion       = "2018-05-01"
+)
+
+// azurePollInterval is how long to wait between checks on Azure operations which finish asynchronously
+var azurePollInterval = 10 * time.Second
+
+// azureCredentialsEnv maps the attributes of the Azure provider to the environment variables of the service
+// principal concourse-up runs as, which are the same ones terraform reads
+var azureCredentialsEnv = map[string]string{
+	"subscription_id": "ARM_SUBSCRIPTION_ID",
+	"tenant_id":       "ARM_TENANT_ID",
+	"client_id":       "ARM_CLIENT_ID",
+	"client_secret":   "ARM_CLIENT_SECRET",
+}
+
+// azureEndpoints are the endpoints of the Azure cloud in use
+type azureEndpoints struct {
+	login      string
+	management string
+	vault      string
+	// blob is a format for the Blob Storage endpoint of a storage account
+	blob string
+}
+
+var azurePublicCloud = azureEndpoints{
+	login:      "https://login.microsoftonline.com",
+	management: "https://management.azure.com",
+	vault:      "https://vault.azure.net",
+	blob:       "https://%s.blob.core.windows.net",
+}
+
+// AzureProvider is the concrete implementation of Provider for Microsoft Azure
+type AzureProvider struct {
+	region     string
+	attrs      map[string]string
+	endpoints  azureEndpoints
+	arm        *http.Client
+	vault      *http.Client
+	blob       *http.Client
+	storageKey []byte
+}
+
+// Azure is the Name of Microsoft Azure
+var Azure = Register("AZURE", Registration{
+	DefaultRegion: func() string { return "westeurope" },
+	New:           newAzure,
+	GatewayUser:   "vcap",
+	Ranges: Ranges{
+		Public:  "10.0.0.0/24",
+		Private: "10.0.1.0/24",
+	},
+	DBNameFormat: "bosh-%s",
+	// Azure Database for PostgreSQL needs upper case letters, lower case letters and numbers in passwords
+	DBPasswordPrefix: "Az1",
+	SingleZone:       true,
+})
+
+func newAzure(region string) (Provider, error) {
+	attrs := make(map[string]string)
+	for attr, env := range azureCredentialsEnv {
+		value := os.Getenv(env)
+		if value == "" {
+			return nil, fmt.Errorf("%s is not set", env)
+		}
+		attrs[attr] = value
+	}
+	// Config buckets are Blob Storage containers in a storage account shared by the deployments in the region.
+	// Storage account names are unique across Azure, so the name is derived from the subscription
+	sum := sha256.Sum256([]byte(attrs["subscription_id"] + "/" + region))
+	attrs["storage_account"] = "concourseup" + hex.EncodeToString(sum[:])[:13]
+	attrs["storage_resource_group"] = "concourse-up-" + region
+
+	return newAzureProvider(region, attrs, azurePublicCloud), nil
+}
+
+func newAzureProvider(region string, attrs map[string]string, endpoints azureEndpoints) *AzureProvider {
+	a := &AzureProvider{
+		region:    region,
+		attrs:     attrs,
+		endpoints: endpoints,
+	}
+	a.arm = a.oauthClient(endpoints.management + "/")
+	a.vault = a.oauthClient(endpoints.vault)
+	a.blob = &http.Client{Transport: sharedKeyTransport{account: attrs["storage_account"], key: a.storageAccountKey}}
+	return a
+}
+
+// AzureWebSizes maps user set web sizes to Azure VM sizes
+var AzureWebSizes = map[string]string{
+	"small":   "Standard_B1ms",
+	"medium":  "Standard_B2s",
+	"large":   "Standard_B2ms",
+	"xlarge":  "Standard_B4ms",
+	"2xlarge": "Standard_B8ms",
+}
+
+// AzureWorkerSizes maps user set worker sizes to Azure VM sizes
+var AzureWorkerSizes = map[string]string{
+	"medium":   "Standard_B2s",
+	"large":    "Standard_D2s_v3",
+	"xlarge":   "Standard_D4s_v3",
+	"2xlarge":  "Standard_D8s_v3",
+	"4xlarge":  "Standard_D16s_v3",
+	"12xlarge": "Standard_D48s_v3",
+	"24xlarge": "Standard_D64s_v3",
+}
+
+// AzureDBSizes maps user set size to Azure Database for PostgreSQL SKUs
+var AzureDBSizes = map[string]string{
+	"small":   "B_Gen5_1",
+	"medium":  "B_Gen5_2",
+	"large":   "GP_Gen5_2",
+	"xlarge":  "GP_Gen5_4",
+	"2xlarge": "GP_Gen5_8",
+	"4xlarge": "GP_Gen5_16",
+}
+
+// DBType gets the correct Azure Database for PostgreSQL SKU
+func (a *AzureProvider) DBType(name string) string {
+	return AzureDBSizes[name]
+}
+
+// WorkerType is a nil setter for workerType
+func (a *AzureProvider) WorkerType(w string) {}
+
+// Attr returns Azure specific attribute
+func (a *AzureProvider) Attr(key string) (string, error) {
+	v, ok := a.attrs[key]
+	if !ok {
+		return "", fmt.Errorf("iaas:azure: key %s not found", key)
+	}
+	return v, nil
+}
+
+// Identity returns the client ID of the service principal in use
+func (a *AzureProvider) Identity() (string, error) {
+	return a.Attr("client_id")
+}
+
+// Region returns the region used by the Provider
+func (a *AzureProvider) Region() string {
+	return a.region
+}
+
+// Zone returns the availability zone used by the Provider. Azure zones are numbered within the region,
+// and not every region has them, so none is used unless one is given
+func (a *AzureProvider) Zone(input string) string {
+	return input
+}
+
+// IAAS returns the name of the Provider
+func (a *AzureProvider) IAAS() Name {
+	return Azure
+}
+
+// CreateDatabases is not used on Azure, where the databases are created through the director
+func (a *AzureProvider) CreateDatabases(name, username, password string) error {
+	return fmt.Errorf("Not implemented yet")
+}
+
+// FindNetwork is not supported on Azure, where concourse-up always creates the network
+func (a *AzureProvider) FindNetwork(network string, subnets ...string) (Network, error) {
+	return Network{}, errors.New("deploying into an existing network is not supported on Azure")
+}
+
+// CreateLockTable does nothing, as terraform locks its state in Blob Storage with a lease
+func (a *AzureProvider) CreateLockTable(name string) error {
+	return nil
+}
+
+// DeleteLockTable does nothing, as terraform locks its state in Blob Storage with a lease
+func (a *AzureProvider) DeleteLockTable(name string) error {
+	return nil
+}
+
+// CheckForWhitelistedIP checks if the specified IP is allowed in by the network security group with the resource ID securityGroup
+func (a *AzureProvider) CheckForWhitelistedIP(ip, securityGroup string) (bool, error) {
+	parsedIP := net.ParseIP(ip)
+
+	var nsg struct {
+		Properties struct {
+			SecurityRules []struct {
+				Properties struct {
+					Access                string   `json:"access"`
+					Direction             string   `json:"direction"`
+					SourceAddressPrefix   string   `json:"sourceAddressPrefix"`
+					SourceAddressPrefixes []string `json:"sourceAddressPrefixes"`
+				} `json:"properties"`
+			} `json:"securityRules"`
+		} `json:"properties"`
+	}
+	if err := a.armRequest(http.MethodGet, securityGroup, azureNetworkAPIVersion, nil, &nsg); err != nil {
+		return false, err
+	}
+
+	for _, rule := range nsg.Properties.SecurityRules {
+		if rule.Properties.Access != "Allow" || rule.Properties.Direction != "Inbound" {
+			continue
+		}
+		for _, prefix := range append(rule.Properties.SourceAddressPrefixes, rule.Properties.SourceAddressPrefix) {
+			if !strings.Contains(prefix, "/") {
+				prefix += "/32"
+			}
+			// Service tags such as VirtualNetwork are not address ranges
+			_, parsedCIDR, err := net.ParseCIDR(prefix)
+			if err != nil {
+				continue
+			}
+			if parsedCIDR.Contains(parsedIP) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// DeleteVMsInDeployment deletes the VMs BOSH created in the deployment's resource group, apart from the nat instance,
+// and then their network interfaces so that terraform can delete the subnets
+func (a *AzureProvider) DeleteVMsInDeployment(zone, project, deployment string) error {
+	vmsPath := a.resourceGroupPath(deployment) + "/providers/Microsoft.Compute/virtualMachines"
+	vms, err := a.listResources(vmsPath, azureComputeAPIVersion)
+	if err != nil {
+		return err
+	}
+	for _, vm := range vms {
+		if strings.HasSuffix(vm.Name, "nat-instance") {
+			continue
+		}
+		fmt.Printf("Deleting instance %+v\n", vm.Name)
+		if err = a.armRequest(http.MethodDelete, vm.ID, azureComputeAPIVersion, nil, nil); err != nil {
+			return err
+		}
+	}
+
+	start := time.Now().UTC()
+	for {
+		vms, err = a.listResources(vmsPath, azureComputeAPIVersion)
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, vm := range vms {
+			if !strings.HasSuffix(vm.Name, "nat-instance") {
+				found = true
+				fmt.Printf("Waiting for instance %s to be deleted\n", vm.Name)
+			}
+		}
+		if !found {
+			break
+		}
+		if time.Since(start) > time.Minute*10 {
+			return fmt.Errorf("Instances not deleted after 10 minutes")
+		}
+		time.Sleep(azurePollInterval)
+	}
+
+	nics, err := a.listResources(a.resourceGroupPath(deployment)+"/providers/Microsoft.Network/networkInterfaces", azureNetworkAPIVersion)
+	if err != nil {
+		return err
+	}
+	for _, nic := range nics {
+		if nic.Properties.VirtualMachine != nil {
+			continue
+		}
+		fmt.Printf("Deleting network interface %s\n", nic.Name)
+		if err = a.armRequest(http.MethodDelete, nic.ID, azureNetworkAPIVersion, nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindLongestMatchingHostedZone finds the longest Azure DNS zone that matches the given subdomain,
+// returning its name and resource ID
+func (a *AzureProvider) FindLongestMatchingHostedZone(subdomain string) (string, string, error) {
+	zones, err := a.listResources(a.subscriptionPath()+"/providers/Microsoft.Network/dnszones", azureDNSAPIVersion)
+	if err != nil {
+		return "", "", err
+	}
+
+	var nameFound, idFound string
+	for _, zone := range zones {
+		if strings.HasSuffix(subdomain, zone.Name) && len(zone.Name) > len(nameFound) {
+			nameFound = zone.Name
+			idFound = zone.ID
+		}
+	}
+	if nameFound == "" {
+		return "", "", fmt.Errorf("dns zone for domain '%s' was not found in Azure DNS", subdomain)
+	}
+	return nameFound, idFound, nil
+}
+
+// SetTXTRecord creates or replaces the TXT record fqdn in the longest Azure DNS zone matching it
+func (a *AzureProvider) SetTXTRecord(fqdn, value string, ttl int) error {
+	path, err := a.txtRecordPath(fqdn)
+	if err != nil {
+		return err
+	}
+	return a.armRequest(http.MethodPut, path, azureDNSAPIVersion, map[string]interface{}{
+		"properties": map[string]interface{}{
+			"TTL":        ttl,
+			"TXTRecords": []map[string][]string{{"value": {value}}},
+		},
+	}, nil)
+}
+
+// DeleteTXTRecord deletes the TXT record fqdn from the longest Azure DNS zone matching it
+func (a *AzureProvider) DeleteTXTRecord(fqdn string) error {
+	path, err := a.txtRecordPath(fqdn)
+	if err != nil {
+		return err
+	}
+	return a.armRequest(http.MethodDelete, path, azureDNSAPIVersion, nil, nil)
+}
+
+func (a *AzureProvider) txtRecordPath(fqdn string) (string, error) {
+	fqdn = strings.TrimSuffix(fqdn, ".")
+	zoneName, zoneID, err := a.FindLongestMatchingHostedZone(fqdn)
+	if err != nil {
+		return "", err
+	}
+	name := strings.TrimSuffix(strings.TrimSuffix(fqdn, zoneName), ".")
+	if name == "" {
+		name = "@"
+	}
+	return zoneID + "/TXT/" + name, nil
+}
+
+func (a *AzureProvider) subscriptionPath() string {
+	return "/subscriptions/" + a.attrs["subscription_id"]
+}
+
+func (a *AzureProvider) resourceGroupPath(name string) string {
+	return a.subscriptionPath() + "/resourceGroups/" + name
+}
+
+// azureResource holds the fields of Azure resources which concourse-up reads from lists
+type azureResource struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Properties struct {
+		VirtualMachine *struct {
+			ID string `json:"id"`
+		} `json:"virtualMachine"`
+	} `json:"properties"`
+}
+
+// listResources returns every page of a list of resources
+func (a *AzureProvider) listResources(path, apiVersion string) ([]azureResource, error) {
+	var resources []azureResource
+	for path != "" {
+		var page struct {
+			Value    []azureResource `json:"value"`
+			NextLink string          `json:"nextLink"`
+		}
+		if err := a.armRequest(http.MethodGet, path, apiVersion, nil, &page); err != nil {
+			return nil, err
+		}
+		resources = append(resources, page.Value...)
+		// The next link has the API version already
+		path, apiVersion = page.NextLink, ""
+	}
+	return resources, nil
+}
@@ -0,0 +1,301 @@
+package iaas
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const azureBlobAPIVersion = "2019-12-12"
+
+// ensureStorageAccount creates the resource group and storage account which hold the config buckets in the region,
+// if they do not exist yet
+func (a *AzureProvider) ensureStorageAccount() error {
+	err := a.armRequest(http.MethodPut, a.resourceGroupPath(a.attrs["storage_resource_group"]), azureResourcesAPIVersion, map[string]interface{}{
+		"location": a.region,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	state, err := a.storageAccountState()
+	if err != nil {
+		return err
+	}
+	if state == "" {
+		err = a.armRequest(http.MethodPut, a.storageAccountPath(), azureStorageAPIVersion, map[string]interface{}{
+			"location": a.region,
+			"kind":     "StorageV2",
+			"sku":      map[string]string{"name": "Standard_LRS"},
+			"properties": map[string]interface{}{
+				"supportsHttpsTrafficOnly": true,
+			},
+		}, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	for start := time.Now(); state != "Succeeded"; {
+		if time.Since(start) > 5*time.Minute {
+			return fmt.Errorf("storage account %s was not created after 5 minutes", a.attrs["storage_account"])
+		}
+		time.Sleep(azurePollInterval)
+		if state, err = a.storageAccountState(); err != nil {
+			return err
+		}
+	}
+
+	return a.EnableVersioning("")
+}
+
+// storageAccountState returns the provisioning state of the storage account, or "" if it does not exist
+func (a *AzureProvider) storageAccountState() (string, error) {
+	var account struct {
+		Properties struct {
+			ProvisioningState string `json:"provisioningState"`
+		} `json:"properties"`
+	}
+	err := a.armRequest(http.MethodGet, a.storageAccountPath(), azureStorageAPIVersion, nil, &account)
+	if isNotFound(err) {
+		return "", nil
+	}
+	return account.Properties.ProvisioningState, err
+}
+
+func (a *AzureProvider) storageAccountPath() string {
+	return a.resourceGroupPath(a.attrs["storage_resource_group"]) + "/providers/Microsoft.Storage/storageAccounts/" + a.attrs["storage_account"]
+}
+
+// storageAccountKey returns the access key of the storage account, which signs requests to Blob Storage
+func (a *AzureProvider) storageAccountKey() ([]byte, error) {
+	if a.storageKey != nil {
+		return a.storageKey, nil
+	}
+	var keys struct {
+		Keys []struct {
+			Value string `json:"value"`
+		} `json:"keys"`
+	}
+	if err := a.armRequest(http.MethodPost, a.storageAccountPath()+"/listKeys", azureStorageAPIVersion, nil, &keys); err != nil {
+		return nil, err
+	}
+	if len(keys.Keys) == 0 {
+		return nil, fmt.Errorf("storage account %s has no access keys", a.attrs["storage_account"])
+	}
+	key, err := base64.StdEncoding.DecodeString(keys.Keys[0].Value)
+	if err != nil {
+		return nil, err
+	}
+	a.storageKey = key
+	return key, nil
+}
+
+// blobRequest calls the Blob Storage API of the storage account. resource is the path of a container or blob.
+// It returns an *azureAPIError if the API returns an error
+func (a *AzureProvider) blobRequest(method, resource string, query url.Values, contents []byte, headers map[string]string) ([]byte, error) {
+	u := fmt.Sprintf(a.endpoints.blob, a.attrs["storage_account"]) + (&url.URL{Path: resource}).EscapedPath()
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	versioned := map[string]string{"x-ms-version": azureBlobAPIVersion}
+	for name, value := range headers {
+		versioned[name] = value
+	}
+	return send(a.blob, method, u, contents, versioned, xmlAPIError)
+}
+
+// CreateBucket creates a Blob Storage container in the region's storage account, creating the account first if needed
+func (a *AzureProvider) CreateBucket(name string) error {
+	if err := a.ensureStorageAccount(); err != nil {
+		return err
+	}
+	_, err := a.blobRequest(http.MethodPut, "/"+name, url.Values{"restype": {"container"}}, nil, nil)
+	return err
+}
+
+// BucketExists checks if the named container exists
+func (a *AzureProvider) BucketExists(name string) (bool, error) {
+	_, err := a.blobRequest(http.MethodHead, "/"+name, url.Values{"restype": {"container"}}, nil, nil)
+	if isNotFound(err) {
+		// Either the container or the whole storage account is missing
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ListBuckets returns the names of all the containers in the region's storage account
+func (a *AzureProvider) ListBuckets() ([]string, error) {
+	var names []string
+	marker := ""
+	for {
+		query := url.Values{"comp": {"list"}}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		body, err := a.blobRequest(http.MethodGet, "/", query, nil, nil)
+		if isNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Containers []struct {
+				Name string `xml:"Name"`
+			} `xml:"Containers>Container"`
+			NextMarker string `xml:"NextMarker"`
+		}
+		if err = xml.Unmarshal(body, &page); err != nil {
+			return nil, err
+		}
+		for _, container := range page.Containers {
+			names = append(names, container.Name)
+		}
+		if marker = page.NextMarker; marker == "" {
+			return names, nil
+		}
+	}
+}
+
+// DeleteVersionedBucket deletes a container, which deletes its blobs and their versions with it
+func (a *AzureProvider) DeleteVersionedBucket(name string) error {
+	_, err := a.blobRequest(http.MethodDelete, "/"+name, url.Values{"restype": {"container"}}, nil, nil)
+	return err
+}
+
+// HasFile returns true if the specified blob exists
+func (a *AzureProvider) HasFile(bucket, path string) (bool, error) {
+	_, err := a.blobRequest(http.MethodHead, "/"+bucket+"/"+path, nil, nil, nil)
+	if isNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// LoadFile loads a blob from a container
+func (a *AzureProvider) LoadFile(bucket, path string) ([]byte, error) {
+	body, err := a.blobRequest(http.MethodGet, "/"+bucket+"/"+path, nil, nil, nil)
+	return body, err
+}
+
+// WriteFile writes the specified blob to a container
+func (a *AzureProvider) WriteFile(bucket, path string, contents []byte) error {
+	_, err := a.blobRequest(http.MethodPut, "/"+bucket+"/"+path, nil, contents, map[string]string{
+		"x-ms-blob-type": "BlockBlob",
+	})
+	return err
+}
+
+// CreateFile writes a blob to a container, unless it exists already
+func (a *AzureProvider) CreateFile(bucket, path string, contents []byte) error {
+	_, err := a.blobRequest(http.MethodPut, "/"+bucket+"/"+path, nil, contents, map[string]string{
+		"x-ms-blob-type": "BlockBlob",
+		"If-None-Match":  "*",
+	})
+	if apiErr, ok := err.(*azureAPIError); ok && apiErr.StatusCode == http.StatusConflict {
+		return ErrFileExists
+	}
+	return err
+}
+
+// DeleteFile deletes a blob from a container
+func (a *AzureProvider) DeleteFile(bucket, path string) error {
+	_, err := a.blobRequest(http.MethodDelete, "/"+bucket+"/"+path, nil, nil, nil)
+	return err
+}
+
+// EnsureFileExists checks for the named blob and creates it if it doesn't exist
+// Second argument is true if new file was created
+func (a *AzureProvider) EnsureFileExists(bucket, path string, defaultContents []byte) ([]byte, bool, error) {
+	contents, err := a.LoadFile(bucket, path)
+	if err == nil {
+		return contents, false, nil
+	}
+	if !isNotFound(err) {
+		return nil, false, err
+	}
+
+	if err = a.WriteFile(bucket, path, defaultContents); err != nil {
+		return nil, false, err
+	}
+	return defaultContents, true, nil
+}
+
+// EnableVersioning turns on blob versioning for the region's storage account, which holds every Azure config bucket
+func (a *AzureProvider) EnableVersioning(bucket string) error {
+	return a.armRequest(http.MethodPut, a.storageAccountPath()+"/blobServices/default", azureStorageAPIVersion, map[string]interface{}{
+		"properties": map[string]interface{}{
+			"isVersioningEnabled": true,
+		},
+	}, nil)
+}
+
+// ListFileVersions returns the versions of a blob, newest first
+func (a *AzureProvider) ListFileVersions(bucket, path string) ([]FileVersion, error) {
+	var versions []FileVersion
+	marker := ""
+	for {
+		query := url.Values{
+			"restype": {"container"},
+			"comp":    {"list"},
+			"include": {"versions"},
+			"prefix":  {path},
+		}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		body, err := a.blobRequest(http.MethodGet, "/"+bucket, query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Blobs []struct {
+				Name             string `xml:"Name"`
+				VersionID        string `xml:"VersionId"`
+				IsCurrentVersion bool   `xml:"IsCurrentVersion"`
+				LastModified     string `xml:"Properties>Last-Modified"`
+				Size             int64  `xml:"Properties>Content-Length"`
+			} `xml:"Blobs>Blob"`
+			NextMarker string `xml:"NextMarker"`
+		}
+		if err = xml.Unmarshal(body, &page); err != nil {
+			return nil, err
+		}
+		for _, blob := range page.Blobs {
+			if blob.Name != path {
+				continue
+			}
+			modified, err := http.ParseTime(blob.LastModified)
+			if err != nil {
+				return nil, err
+			}
+			versions = append(versions, FileVersion{
+				ID:       blob.VersionID,
+				Modified: modified,
+				Size:     blob.Size,
+				Latest:   blob.IsCurrentVersion,
+			})
+		}
+		if marker = page.NextMarker; marker == "" {
+			return sortVersions(versions), nil
+		}
+	}
+}
+
+// LoadFileVersion loads a version of a blob
+func (a *AzureProvider) LoadFileVersion(bucket, path, versionID string) ([]byte, error) {
+	body, err := a.blobRequest(http.MethodGet, "/"+bucket+"/"+path, url.Values{"versionid": {versionID}}, nil, nil)
+	return body, err
+}
@@ -0,0 +1,46 @@
+package iaas
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const keyVaultAPIVersion = "7.0"
+
+// EncryptKey wraps a data key with the Key Vault key keyID, given as
+// https://VAULT.vault.azure.net/keys/NAME/VERSION
+func (a *AzureProvider) EncryptKey(keyID string, key []byte) ([]byte, error) {
+	return a.keyVaultRequest(keyID, "wrapkey", key)
+}
+
+// DecryptKey unwraps a data key which was wrapped with the Key Vault key keyID
+func (a *AzureProvider) DecryptKey(keyID string, wrapped []byte) ([]byte, error) {
+	return a.keyVaultRequest(keyID, "unwrapkey", wrapped)
+}
+
+func (a *AzureProvider) keyVaultRequest(keyID, action string, value []byte) ([]byte, error) {
+	body, err := json.Marshal(map[string]string{
+		"alg":   "RSA-OAEP-256",
+		"value": base64.RawURLEncoding.EncodeToString(value),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	u := fmt.Sprintf("%s/%s?api-version=%s", strings.TrimSuffix(keyID, "/"), action, keyVaultAPIVersion)
+	respBody, err := send(a.vault, http.MethodPost, u, body, map[string]string{"Content-Type": "application/json"}, jsonAPIError)
+	if err != nil {
+		return nil, fmt.Errorf("Key Vault %s with key %s failed: %s", action, keyID, err)
+	}
+
+	var output struct {
+		Value string `json:"value"`
+	}
+	if err = json.Unmarshal(respBody, &output); err != nil {
+		return nil, err
+	}
+	return base64.RawURLEncoding.DecodeString(output.Value)
+}
@@ -0,0 +1,286 @@
+package iaas
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// The Azure SDK is not vendored, so the Azure APIs are called directly. Every request goes through send, which retries
+// it, with clients whose transports sign it: OAuth tokens for Resource Manager and Key Vault, and the storage account
+// key for Blob Storage
+
+// azureMaxRetries is how many times a request which Azure throttles or fails with a server error is retried
+const azureMaxRetries = 4
+
+// azureRetryDelay is how long to wait before the first retry when Azure does not say, doubling for each retry after
+var azureRetryDelay = 2 * time.Second
+
+// azureAPIError is the error returned by an Azure API
+type azureAPIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *azureAPIError) Error() string {
+	return fmt.Sprintf("%s %s", e.Code, e.Message)
+}
+
+func (e *azureAPIError) notFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+// send makes a request with client, retrying it while it is throttled or fails with a server error.
+// It returns the body of the response, or the error apiError reads from a response which is not successful
+func send(client *http.Client, method, u string, body []byte, headers map[string]string, apiError func(*http.Response, []byte) *azureAPIError) ([]byte, error) {
+	delay := azureRetryDelay
+	for retry := 0; ; retry++ {
+		req, err := http.NewRequest(method, u, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		respBody, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode < http.StatusMultipleChoices {
+			return respBody, nil
+		}
+		if retry == azureMaxRetries || !isRetryable(resp.StatusCode) {
+			return nil, apiError(resp, respBody)
+		}
+		time.Sleep(retryAfter(resp, delay))
+		delay *= 2
+	}
+}
+
+func isRetryable(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter returns how long the Retry-After header of resp asks to wait, or delay if it has none
+func retryAfter(resp *http.Response, delay time.Duration) time.Duration {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	return delay
+}
+
+// jsonAPIError reads the error returned by Resource Manager and Key Vault
+func jsonAPIError(resp *http.Response, body []byte) *azureAPIError {
+	var jsonErr struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	json.Unmarshal(body, &jsonErr)
+	if jsonErr.Error.Code == "" {
+		jsonErr.Error.Code = resp.Status
+	}
+	return &azureAPIError{StatusCode: resp.StatusCode, Code: jsonErr.Error.Code, Message: jsonErr.Error.Message}
+}
+
+// xmlAPIError reads the error returned by Blob Storage, which has no body for HEAD requests
+func xmlAPIError(resp *http.Response, body []byte) *azureAPIError {
+	var blobErr struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	xml.Unmarshal(body, &blobErr)
+	code := resp.Header.Get("x-ms-error-code")
+	if code == "" {
+		code = resp.Status
+	}
+	return &azureAPIError{StatusCode: resp.StatusCode, Code: code, Message: blobErr.Message}
+}
+
+// armRequest calls the Azure Resource Manager API. path is a resource ID or a full URL.
+// It returns an *azureAPIError if the API returns an error
+func (a *AzureProvider) armRequest(method, path, apiVersion string, input, output interface{}) error {
+	var body []byte
+	headers := map[string]string{}
+	if input != nil {
+		var err error
+		if body, err = json.Marshal(input); err != nil {
+			return err
+		}
+		headers["Content-Type"] = "application/json"
+	}
+
+	u, err := url.Parse(path)
+	if err != nil {
+		return err
+	}
+	if !u.IsAbs() {
+		if u, err = url.Parse(a.endpoints.management + path); err != nil {
+			return err
+		}
+	}
+	if apiVersion != "" {
+		query := u.Query()
+		query.Set("api-version", apiVersion)
+		u.RawQuery = query.Encode()
+	}
+
+	respBody, err := send(a.arm, method, u.String(), body, headers, jsonAPIError)
+	if err != nil {
+		return err
+	}
+	if output == nil || len(respBody) == 0 {
+		return nil
+	}
+	return json.Unmarshal(respBody, output)
+}
+
+// oauthClient returns an HTTP client which authenticates its requests with tokens for resource
+func (a *AzureProvider) oauthClient(resource string) *http.Client {
+	return oauth2.NewClient(context.Background(), oauth2.ReuseTokenSource(nil, azureTokenSource{
+		endpoint: fmt.Sprintf("%s/%s/oauth2/token", a.endpoints.login, a.attrs["tenant_id"]),
+		form: url.Values{
+			"grant_type":    {"client_credentials"},
+			"client_id":     {a.attrs["client_id"]},
+			"client_secret": {a.attrs["client_secret"]},
+			"resource":      {resource},
+		},
+	}))
+}
+
+// azureTokenSource gets tokens for the service principal with the client credentials grant
+type azureTokenSource struct {
+	endpoint string
+	form     url.Values
+}
+
+func (s azureTokenSource) Token() (*oauth2.Token, error) {
+	resp, err := http.PostForm(s.endpoint, s.form)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		AccessToken      string      `json:"access_token"`
+		TokenType        string      `json:"token_type"`
+		ExpiresIn        json.Number `json:"expires_in"`
+		ErrorDescription string      `json:"error_description"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&token); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to authenticate with Azure: %s %s", resp.Status, token.ErrorDescription)
+	}
+	seconds, err := token.ExpiresIn.Int64()
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{
+		AccessToken: token.AccessToken,
+		TokenType:   token.TokenType,
+		Expiry:      time.Now().Add(time.Duration(seconds) * time.Second),
+	}, nil
+}
+
+// sharedKeyTransport signs Blob Storage requests with the access key of the storage account, which key returns
+type sharedKeyTransport struct {
+	account string
+	key     func() ([]byte, error)
+}
+
+func (t sharedKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, err := t.key()
+	if err != nil {
+		return nil, err
+	}
+	// A RoundTripper must not modify the request it is given
+	signed := new(http.Request)
+	*signed = *req
+	signed.Header = make(http.Header, len(req.Header))
+	for name, values := range req.Header {
+		signed.Header[name] = values
+	}
+	signed.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	signature := sharedKeySignature(key, signed, int(req.ContentLength), "/"+t.account+req.URL.EscapedPath(), req.URL.Query())
+	signed.Header.Set("Authorization", fmt.Sprintf("SharedKey %s:%s", t.account, signature))
+	return http.DefaultTransport.RoundTrip(signed)
+}
+
+// sharedKeySignature signs a Blob Storage request with the storage account key
+// as described in https://docs.microsoft.com/en-us/rest/api/storageservices/authorize-with-shared-key
+func sharedKeySignature(key []byte, req *http.Request, contentLength int, canonicalResource string, query url.Values) string {
+	length := ""
+	if contentLength > 0 {
+		length = strconv.Itoa(contentLength)
+	}
+
+	var msHeaders []string
+	for name := range req.Header {
+		if name = strings.ToLower(name); strings.HasPrefix(name, "x-ms-") {
+			msHeaders = append(msHeaders, name)
+		}
+	}
+	sort.Strings(msHeaders)
+	var canonicalHeaders strings.Builder
+	for _, name := range msHeaders {
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, strings.TrimSpace(req.Header.Get(name)))
+	}
+
+	var params []string
+	for name := range query {
+		params = append(params, name)
+	}
+	sort.Strings(params)
+	resource := canonicalResource
+	for _, name := range params {
+		values := append([]string{}, query[name]...)
+		sort.Strings(values)
+		resource += fmt.Sprintf("\n%s:%s", strings.ToLower(name), strings.Join(values, ","))
+	}
+
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		length,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date, which is sent as x-ms-date instead
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+	}, "\n") + "\n" + canonicalHeaders.String() + resource
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,187 @@
+package iaas
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeAzure serves the Azure endpoints concourse-up calls from one server, with the ARM resources given by path
+func fakeAzure(t *testing.T, resources map[string]string) (*AzureProvider, *httptest.Server) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/oauth2/token"):
+			fmt.Fprint(w, `{"access_token": "fake-token", "token_type": "Bearer", "expires_in": "3600"}`)
+		case strings.HasSuffix(r.URL.Path, "/listKeys"):
+			fmt.Fprintf(w, `{"keys": [{"value": "%s"}]}`, base64.StdEncoding.EncodeToString([]byte("fake-key")))
+		case strings.HasPrefix(r.URL.Path, "/blob/"):
+			if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey concourseupfake:") {
+				t.Errorf("blob request was not signed with the storage account key: %q", r.Header.Get("Authorization"))
+			}
+			w.Header().Set("x-ms-error-code", "BlobNotFound")
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			if r.Header.Get("Authorization") != "Bearer fake-token" {
+				t.Errorf("ARM request was not authorised: %q", r.Header.Get("Authorization"))
+			}
+			body, ok := resources[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"error": {"code": "NotFound", "message": "not found"}}`)
+				return
+			}
+			fmt.Fprint(w, body)
+		}
+	}))
+
+	a := newAzureProvider("westeurope", map[string]string{
+		"subscription_id":        "sub",
+		"tenant_id":              "tenant",
+		"client_id":              "client",
+		"client_secret":          "secret",
+		"storage_account":        "concourseupfake",
+		"storage_resource_group": "concourse-up-westeurope",
+	}, azureEndpoints{
+		login:      server.URL,
+		management: server.URL,
+		vault:      server.URL,
+		blob:       server.URL + "/blob/%s",
+	})
+	return a, server
+}
+
+func TestAzureProvider_CheckForWhitelistedIP(t *testing.T) {
+	const nsgID = "/subscriptions/sub/resourceGroups/dep/providers/Microsoft.Network/networkSecurityGroups/director"
+	a, server := fakeAzure(t, map[string]string{
+		nsgID: `{"properties": {"securityRules": [
+			{"properties": {"access": "Allow", "direction": "Inbound", "sourceAddressPrefix": "VirtualNetwork"}},
+			{"properties": {"access": "Deny", "direction": "Inbound", "sourceAddressPrefix": "10.0.0.1"}},
+			{"properties": {"access": "Allow", "direction": "Inbound", "sourceAddressPrefixes": ["1.2.3.4", "5.6.7.0/24"]}}
+		]}}`,
+	})
+	defer server.Close()
+
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{ip: "1.2.3.4", want: true},
+		{ip: "5.6.7.8", want: true},
+		{ip: "10.0.0.1", want: false},
+		{ip: "9.9.9.9", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			got, err := a.CheckForWhitelistedIP(tt.ip, nsgID)
+			if err != nil {
+				t.Fatalf("CheckForWhitelistedIP() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CheckForWhitelistedIP() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := a.CheckForWhitelistedIP("1.2.3.4", nsgID+"-missing"); !isNotFound(err) {
+		t.Errorf("CheckForWhitelistedIP() error = %v, want not found", err)
+	}
+}
+
+func TestAzureProvider_txtRecordPath(t *testing.T) {
+	a, server := fakeAzure(t, map[string]string{
+		"/subscriptions/sub/providers/Microsoft.Network/dnszones": `{"value": [
+			{"id": "/subscriptions/sub/resourceGroups/dns/providers/Microsoft.Network/dnszones/example.com", "name": "example.com"},
+			{"id": "/subscriptions/sub/resourceGroups/dns/providers/Microsoft.Network/dnszones/ci.example.com", "name": "ci.example.com"}
+		]}`,
+	})
+	defer server.Close()
+
+	tests := []struct {
+		fqdn string
+		want string
+	}{
+		{fqdn: "_acme-challenge.ci.example.com.", want: "/subscriptions/sub/resourceGroups/dns/providers/Microsoft.Network/dnszones/ci.example.com/TXT/_acme-challenge"},
+		{fqdn: "_acme-challenge.www.example.com.", want: "/subscriptions/sub/resourceGroups/dns/providers/Microsoft.Network/dnszones/example.com/TXT/_acme-challenge.www"},
+		{fqdn: "example.com.", want: "/subscriptions/sub/resourceGroups/dns/providers/Microsoft.Network/dnszones/example.com/TXT/@"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fqdn, func(t *testing.T) {
+			got, err := a.txtRecordPath(tt.fqdn)
+			if err != nil {
+				t.Fatalf("txtRecordPath() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("txtRecordPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAzureProvider_HasFile(t *testing.T) {
+	a, server := fakeAzure(t, nil)
+	defer server.Close()
+
+	got, err := a.HasFile("bucket", "config.json")
+	if err != nil {
+		t.Fatalf("HasFile() error = %v", err)
+	}
+	if got {
+		t.Error("HasFile() = true, want false")
+	}
+}
+
+func Test_sharedKeySignature(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://account.blob.core.windows.net/bucket?restype=container&comp=list", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("x-ms-date", "Mon, 02 Jan 2006 15:04:05 GMT")
+	req.Header.Set("x-ms-version", azureBlobAPIVersion)
+
+	got := sharedKeySignature([]byte("key"), req, 0, "/account/bucket", req.URL.Query())
+	// Query parameters are signed sorted by name, so reordering them must not change the signature
+	req.URL.RawQuery = "comp=list&restype=container"
+	if again := sharedKeySignature([]byte("key"), req, 0, "/account/bucket", req.URL.Query()); again != got {
+		t.Errorf("sharedKeySignature() = %v, then %v for the same request", got, again)
+	}
+	if other := sharedKeySignature([]byte("other"), req, 0, "/account/bucket", req.URL.Query()); other == got {
+		t.Error("sharedKeySignature() does not depend on the key")
+	}
+}
+
+func Test_send(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch {
+		case r.URL.Path == "/throttled" && requests == 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case r.URL.Path == "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": {"code": "NotFound", "message": "not found"}}`)
+		default:
+			fmt.Fprint(w, "ok")
+		}
+	}))
+	defer server.Close()
+
+	body, err := send(server.Client(), http.MethodGet, server.URL+"/throttled", nil, nil, jsonAPIError)
+	if err != nil {
+		t.Fatalf("send() error = %v", err)
+	}
+	if string(body) != "ok" || requests != 2 {
+		t.Errorf("send() = %q after %d requests, want %q after the throttled request was retried", body, requests, "ok")
+	}
+
+	requests = 0
+	if _, err = send(server.Client(), http.MethodGet, server.URL+"/missing", nil, nil, jsonAPIError); !isNotFound(err) {
+		t.Errorf("send() error = %v, want not found", err)
+	}
+	if requests != 1 {
+		t.Errorf("send() made %d requests for a missing resource, want 1", requests)
+	}
+}
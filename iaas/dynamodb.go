@@ -0,0 +1,61 @@
+package iaas
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// CreateLockTable creates the DynamoDB table terraform locks its state with, unless it exists already,
+// and waits for it to become active. Terraform's locking needs very little capacity
+func (a *AWSProvider) CreateLockTable(name string) error {
+	client := dynamodb.New(a.sess)
+	_, err := client.CreateTable(&dynamodb.CreateTableInput{
+		TableName: aws.String(name),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{AttributeName: aws.String("LockID"), AttributeType: aws.String(dynamodb.ScalarAttributeTypeS)},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{AttributeName: aws.String("LockID"), KeyType: aws.String(dynamodb.KeyTypeHash)},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(1),
+			WriteCapacityUnits: aws.Int64(1),
+		},
+	})
+	if err != nil && !isAWSError(err, dynamodb.ErrCodeResourceInUseException) {
+		return fmt.Errorf("error creating DynamoDB table %s: [%v]", name, err)
+	}
+
+	if err = client.WaitUntilTableExists(&dynamodb.DescribeTableInput{TableName: aws.String(name)}); err != nil {
+		return fmt.Errorf("error waiting for DynamoDB table %s to become active: [%v]", name, err)
+	}
+	return nil
+}
+
+// DeleteLockTable deletes the DynamoDB table terraform locks its state with, if it exists
+func (a *AWSProvider) DeleteLockTable(name string) error {
+	_, err := dynamodb.New(a.sess).DeleteTable(&dynamodb.DeleteTableInput{TableName: aws.String(name)})
+	if err != nil && !isAWSError(err, dynamodb.ErrCodeResourceNotFoundException) {
+		return fmt.Errorf("error deleting DynamoDB table %s: [%v]", name, err)
+	}
+	return nil
+}
+
+// isAWSError returns true if err is an AWS API error with the code given
+func isAWSError(err error, code string) bool {
+	awsErr, ok := err.(awserr.Error)
+	return ok && awsErr.Code() == code
+}
+
+// CreateLockTable does nothing, as terraform locks its state in GCS with a lock file
+func (g *GCPProvider) CreateLockTable(name string) error {
+	return nil
+}
+
+// DeleteLockTable does nothing, as terraform locks its state in GCS with a lock file
+func (g *GCPProvider) DeleteLockTable(name string) error {
+	return nil
+}
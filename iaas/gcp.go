@@ -3,11 +3,11 @@ package iaas
 import (
 	"database/sql"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"strings"
 	"time"
@@ -17,6 +17,7 @@ import (
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/compute/v1"
 	clouddns "google.golang.org/api/dns/v1"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/iterator"
 
 	// PostgreSQL driver required at runtime
@@ -52,6 +53,26 @@ func GCPStorage() GCPOption {
 	}
 }
 
+// GCP is the Name of Google Cloud Platform
+var GCP = Register("GCP", Registration{
+	DefaultRegion: func() string { return "europe-west1" },
+	New: func(region string) (Provider, error) {
+		return newGCP(region, GCPStorage())
+	},
+	GatewayUser: "jumpbox",
+	Ranges: Ranges{
+		Public:  "10.0.0.0/24",
+		Private: "10.0.1.0/24",
+	},
+	// The terraform GCS backend keeps its state at a fixed path
+	TerraformStatePath: "default.tfstate",
+	DBNameFormat:       "bosh-%s",
+	MaxNameLength:      12,
+	RegionalZones:      true,
+	NetworkFlag:        "--network",
+	DNSProvider:        "gcloud",
+})
+
 func newGCP(region string, ops ...GCPOption) (Provider, error) {
 	project, path, err := getCredentials()
 	if err != nil {
@@ -96,6 +117,28 @@ func (g *GCPProvider) Attr(key string) (string, error) {
 	return v, nil
 }
 
+// Identity returns the service account whose credentials are in use
+func (g *GCPProvider) Identity() (string, error) {
+	path, err := g.Attr("credentials_path")
+	if err != nil {
+		return "", err
+	}
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	var creds struct {
+		ClientEmail string `json:"client_email"`
+	}
+	if err = json.Unmarshal(contents, &creds); err != nil {
+		return "", fmt.Errorf("Unable to parse %v: [%v]", path, err)
+	}
+	if creds.ClientEmail == "" {
+		return "", fmt.Errorf("client_email not found in %v", path)
+	}
+	return creds.ClientEmail, nil
+}
+
 // DeleteFile deletes a file from GCP bucket
 func (g *GCPProvider) DeleteFile(bucket, path string) error {
 	o := g.storage.Bucket(bucket).Object(path)
@@ -107,36 +150,22 @@ func (g *GCPProvider) DeleteFile(bucket, path string) error {
 	return nil
 }
 
-// Choose for the consumer the appropriate output based on the provider
-func (g *GCPProvider) Choose(c Choice) interface{} {
-	return c.GCP
-}
-
 // DeleteVersionedBucket deletes a bucket and its content from GCP
 func (g *GCPProvider) DeleteVersionedBucket(name string) error {
-	err := g.DeleteFile(name, "config.json")
-	if err != nil {
-		return err
-	}
-	err = g.DeleteFile(name, "default.tfstate")
-	if err != nil {
-		return err
-	}
-	err = g.DeleteFile(name, "director-creds.yml")
-	if err != nil {
-		return err
-	}
-	err = g.DeleteFile(name, "director-state.json")
-	if err != nil {
-		return err
-	}
-	err = g.DeleteFile(name, "maintenance.json")
-	if err != nil && err.Error() != "storage: object doesn't exist" {
-		return err
-	}
-	err = g.DeleteFile(name, "director-creds-backup.yml")
-	if err != nil && err.Error() != "storage: object doesn't exist" {
-		return err
+	// Delete every generation of every object, as a bucket cannot be deleted until they are gone
+	it := g.storage.Bucket(name).Objects(g.ctx, &storage.Query{Versions: true})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		err = g.storage.Bucket(name).Object(attrs.Name).Generation(attrs.Generation).Delete(g.ctx)
+		if err != nil && err != storage.ErrObjectNotExist {
+			return err
+		}
 	}
 	time.Sleep(time.Second)
 	if err := g.storage.Bucket(name).Delete(g.ctx); err != nil {
@@ -146,13 +175,13 @@ func (g *GCPProvider) DeleteVersionedBucket(name string) error {
 	return nil
 }
 
-// CreateBucket creates a GCP storage bucket with defaults of the US multi-regional location, and a storage class of Standard Storage
+// CreateBucket creates a versioned GCP storage bucket with defaults of the US multi-regional location, and a storage class of Standard Storage
 func (g *GCPProvider) CreateBucket(name string) error {
 	project, err := g.Attr("project")
 	if err != nil {
 		return err
 	}
-	if err := g.storage.Bucket(name).Create(g.ctx, project, nil); err != nil {
+	if err := g.storage.Bucket(name).Create(g.ctx, project, &storage.BucketAttrs{VersioningEnabled: true}); err != nil {
 		return err
 	}
 	return nil
@@ -183,6 +212,28 @@ func (g *GCPProvider) BucketExists(name string) (bool, error) {
 	return false, nil
 }
 
+// ListBuckets returns the names of all the buckets in the GCP project
+func (g *GCPProvider) ListBuckets() ([]string, error) {
+	project, err := g.Attr("project")
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	it := g.storage.Buckets(g.ctx, project)
+	for {
+		battrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, battrs.Name)
+	}
+
+	return names, nil
+}
+
 // HasFile returns true if the specified GCP file exists
 func (g *GCPProvider) HasFile(bucket, path string) (bool, error) {
 	o := g.storage.Bucket(bucket).Object(path)
@@ -227,6 +278,20 @@ func (g *GCPProvider) WriteFile(bucket, path string, contents []byte) error {
 	return nil
 }
 
+// CreateFile writes the specified GCS object, unless it exists already
+func (g *GCPProvider) CreateFile(bucket, path string, contents []byte) error {
+	wc := g.storage.Bucket(bucket).Object(path).If(storage.Conditions{DoesNotExist: true}).NewWriter(g.ctx)
+	if _, err := wc.Write(contents); err != nil {
+		wc.Close()
+		return err
+	}
+	err := wc.Close()
+	if apiErr, ok := err.(*googleapi.Error); ok && apiErr.Code == http.StatusPreconditionFailed {
+		return ErrFileExists
+	}
+	return err
+}
+
 // Region returns the region used by the Provider
 func (g *GCPProvider) Region() string {
 	return g.region
@@ -267,12 +332,6 @@ func (g *GCPProvider) EnsureFileExists(bucket, path string, defaultContents []by
 	return defaultContents, true, nil
 }
 
-// DeleteVolumes deletes the specified GCP storage volumes
-func (g *GCPProvider) DeleteVolumes(volumesToDelete []string, deleteVolume func(ec2Client IEC2, volumeID *string) error) error {
-	// @note: This will be covered in a later iteration as we need a deployment to try it
-	return errors.New("DeleteVolumes Not Implemented Yet")
-}
-
 // CheckForWhitelistedIP checks if the specified IP is whitelisted in the security group
 func (g *GCPProvider) CheckForWhitelistedIP(ip, firewallName string) (bool, error) {
 
@@ -318,13 +377,30 @@ func (g *GCPProvider) CheckForWhitelistedIP(ip, firewallName string) (bool, erro
 	return false, nil
 }
 
-// DeleteVMsInVPC is a placeholder function used with AWS deployments
-func (g *GCPProvider) DeleteVMsInVPC(vpcID string) ([]string, error) {
-	return []string{}, nil
+// LabelTeardown deletes the VMs BOSH created on GCP, by the network of the deployment or, where the network is
+// shared, by the label BOSH gives them
+type LabelTeardown interface {
+	DeploymentTeardown
+	DeleteVMsWithLabel(zone, project, key, value string) error
 }
 
 //DeleteVMsInDeployment will delete all vms in a deployment apart from nat instance
 func (g *GCPProvider) DeleteVMsInDeployment(zone, project, deployment string) error {
+	return g.deleteVMs(zone, project, func(instance *compute.Instance) bool {
+		return strings.HasSuffix(instance.NetworkInterfaces[0].Network, deployment)
+	})
+}
+
+// DeleteVMsWithLabel deletes the VMs labelled with key set to value, for deployments into a network
+// shared with VMs that concourse-up did not create
+func (g *GCPProvider) DeleteVMsWithLabel(zone, project, key, value string) error {
+	return g.deleteVMs(zone, project, func(instance *compute.Instance) bool {
+		return instance.Labels[key] == value
+	})
+}
+
+// deleteVMs deletes the VMs in the zone for which inDeployment is true, apart from the nat instance
+func (g *GCPProvider) deleteVMs(zone, project string, inDeployment func(*compute.Instance) bool) error {
 	c, err := google.DefaultClient(g.ctx, compute.CloudPlatformScope)
 	if err != nil {
 		log.Fatal(err)
@@ -340,9 +416,8 @@ func (g *GCPProvider) DeleteVMsInDeployment(zone, project, deployment string) er
 	if err := req.Pages(g.ctx, func(page *compute.InstanceList) error {
 		for _, instance := range page.Items {
 			name := instance.Name
-			networkName := instance.NetworkInterfaces[0].Network
-			// delete all instances in deployment's network apart from nat instance
-			if strings.HasSuffix(networkName, deployment) {
+			// delete all instances in deployment apart from nat instance
+			if inDeployment(instance) {
 				for _, disk := range instance.Disks {
 					fmt.Printf("Marking instance %s volume for deletion\n", name)
 					computeService.Instances.SetDiskAutoDelete(project, zone, name, true, disk.DeviceName).Context(g.ctx).Do()
@@ -369,8 +444,7 @@ func (g *GCPProvider) DeleteVMsInDeployment(zone, project, deployment string) er
 		if err := req.Pages(g.ctx, func(page *compute.InstanceList) error {
 			for _, instance := range page.Items {
 				name := instance.Name
-				networkName := instance.NetworkInterfaces[0].Network
-				if strings.HasSuffix(networkName, deployment) && !strings.HasSuffix(name, "nat-instance") {
+				if inDeployment(instance) && !strings.HasSuffix(name, "nat-instance") {
 					found = true
 					fmt.Printf("Waiting for instance %s to be deleted\n", name)
 				}
@@ -389,6 +463,40 @@ func (g *GCPProvider) DeleteVMsInDeployment(zone, project, deployment string) er
 	}
 }
 
+// FindNetwork checks that the network exists and returns the ranges of its subnetworks in the region
+// with the names subnets, in the order they are given. GCP networks have no range of their own
+func (g *GCPProvider) FindNetwork(network string, subnets ...string) (Network, error) {
+	project, err := g.Attr("project")
+	if err != nil {
+		return Network{}, err
+	}
+	c, err := google.DefaultClient(g.ctx, compute.CloudPlatformScope)
+	if err != nil {
+		return Network{}, err
+	}
+	computeService, err := compute.New(c)
+	if err != nil {
+		return Network{}, err
+	}
+
+	n, err := computeService.Networks.Get(project, network).Context(g.ctx).Do()
+	if err != nil {
+		return Network{}, fmt.Errorf("error finding network %s: [%v]", network, err)
+	}
+	var found Network
+	for _, name := range subnets {
+		subnetwork, err := computeService.Subnetworks.Get(project, g.region, name).Context(g.ctx).Do()
+		if err != nil {
+			return Network{}, fmt.Errorf("error finding subnetwork %s in %s: [%v]", name, g.region, err)
+		}
+		if subnetwork.Network != n.SelfLink {
+			return Network{}, fmt.Errorf("subnetwork %s is not in network %s", name, network)
+		}
+		found.Subnets = append(found.Subnets, Subnet{ID: name, CIDR: subnetwork.IpCidrRange})
+	}
+	return found, nil
+}
+
 // FindLongestMatchingHostedZone finds the longest hosted zone that matches the given subdomain
 func (g *GCPProvider) FindLongestMatchingHostedZone(domain string) (string, string, error) {
 	c, err := google.DefaultClient(g.ctx, compute.CloudPlatformScope)
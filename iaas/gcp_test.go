@@ -1,6 +1,9 @@
 package iaas
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"cloud.google.com/go/storage"
@@ -147,3 +150,25 @@ func TestGCPProvider_DBType(t *testing.T) {
 		})
 	}
 }
+
+func TestGCPProvider_Identity(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gcp-identity")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "creds.json")
+	err = ioutil.WriteFile(path, []byte(`{"project_id":"happymeal","client_email":"ci@happymeal.iam.gserviceaccount.com"}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := &GCPProvider{attrs: map[string]string{"credentials_path": path}}
+	got, err := g.Identity()
+	if err != nil {
+		t.Fatalf("GCPProvider.Identity() error = %v", err)
+	}
+	if want := "ci@happymeal.iam.gserviceaccount.com"; got != want {
+		t.Errorf("GCPProvider.Identity() = %v, want %v", got, want)
+	}
+}
@@ -1,29 +1,19 @@
 package iaas
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 )
 
-// Choice is an interface which can help on the abstraction of provider data
-// by defining any kind of data mapped against the available providers
-type Choice struct {
-	AWS interface{}
-	GCP interface{}
-}
-
+// Name identifies an IAAS. The Names of the IAASs are returned by Register, from the file of each IAAS
 type Name int
 
-const (
-	Unknown = iota
-	AWS
-	GCP
-)
+// Unknown is the Name of no IAAS
+const Unknown Name = 0
 
 var names = []string{
 	"Unknown",
-	"AWS",
-	"GCP",
 }
 
 func (n Name) String() string {
@@ -48,42 +38,141 @@ type Provider interface {
 	CheckForWhitelistedIP(ip, securityGroup string) (bool, error)
 	CreateBucket(name string) error
 	CreateDatabases(name, username, password string) error
+	CreateFile(bucket, path string, contents []byte) error
+	CreateLockTable(name string) error
 	DeleteFile(bucket, path string) error
+	DeleteLockTable(name string) error
 	DeleteVersionedBucket(name string) error
-	DeleteVMsInDeployment(zone, project, deployment string) error
-	DeleteVMsInVPC(vpcID string) ([]string, error)
-	DeleteVolumes(volumesToDelete []string, deleteVolume func(ec2Client IEC2, volumeID *string) error) error
 	EnsureFileExists(bucket, path string, defaultContents []byte) ([]byte, bool, error)
 	FindLongestMatchingHostedZone(subdomain string) (string, string, error)
 	HasFile(bucket, path string) (bool, error)
 	DBType(name string) string
+	DecryptKey(keyID string, wrapped []byte) ([]byte, error)
+	EnableVersioning(bucket string) error
+	EncryptKey(keyID string, key []byte) ([]byte, error)
+	FindNetwork(network string, subnets ...string) (Network, error)
 	IAAS() Name
+	Identity() (string, error)
+	ListBuckets() ([]string, error)
+	ListFileVersions(bucket, path string) ([]FileVersion, error)
 	LoadFile(bucket, path string) ([]byte, error)
+	LoadFileVersion(bucket, path, versionID string) ([]byte, error)
 	Region() string
 	WorkerType(string)
 	WriteFile(bucket, path string, contents []byte) error
 	Zone(string) string
-	Choose(Choice) interface{}
+}
+
+// DeploymentTeardown is implemented by the Providers of IAASs which find the VMs BOSH created by the deployment
+// they are in, to delete them before terraform destroys the network
+type DeploymentTeardown interface {
+	DeleteVMsInDeployment(zone, project, deployment string) error
+}
+
+// Network is an existing network, and subnets of it, which a deployment is placed in instead of creating its own
+type Network struct {
+	// CIDR is the range of the network, empty on IAASs where only subnets have ranges
+	CIDR    string
+	Subnets []Subnet
+}
+
+// Subnet is a subnet of an existing Network
+type Subnet struct {
+	ID   string
+	CIDR string
+	// Zone is empty on IAASs whose subnets span the region
+	Zone string
 }
 
 // Factory creates a new IaaS provider, defined for testability
 type Factory func(iaasName, region string) (Provider, error)
 
+// Registration describes how to create the provider of an IAAS. The other packages of concourse-up
+// keep their own registrations for the IAAS, keyed by the Name that Register returns
+type Registration struct {
+	// DefaultRegion returns the region used when none is given
+	DefaultRegion func() string
+	// New creates a provider for the region
+	New func(region string) (Provider, error)
+	// GatewayUser is the user that BOSH SSHs to the director as, on its way to other VMs
+	GatewayUser string
+	// Ranges are the network ranges used when none are given on the first deploy
+	Ranges Ranges
+	// TerraformStatePath is where the terraform backend keeps its state in the config bucket, or empty
+	// if terraform is told where to keep it
+	TerraformStatePath string
+	// DBNameFormat formats the name of the BOSH database from eight random letters
+	DBNameFormat string
+	// DBPasswordPrefix is prepended to the generated database password, for databases which insist on
+	// characters it might lack
+	DBPasswordPrefix string
+	// MaxNameLength limits the length of deployment names, when it is not 0
+	MaxNameLength int
+	// Private is true if the VMs can be deployed without public IPs, behind a jumpbox
+	Private bool
+	// RegionalZones is true if the name of each zone starts with the name of its region
+	RegionalZones bool
+	// SingleZone is true if a deployment cannot be spread across zones
+	SingleZone bool
+	// NetworkFlag is the deploy flag which names an existing network to deploy into, or empty if there is none
+	NetworkFlag string
+	// DNSProvider is the lego DNS provider which answers the ACME challenges for the domain. When it is
+	// empty the provider must create the TXT records itself
+	DNSProvider string
+}
+
+// Ranges are the CIDR ranges of the networks terraform creates. Only those the IAAS uses are set
+type Ranges struct {
+	Network string
+	Public  string
+	Private string
+	RDS1    string
+	RDS2    string
+}
+
+var registrations = make(map[Name]Registration)
+
+// Register makes an IAAS available by name, returning the Name it is known by. It is meant to initialise
+// the Name of the IAAS in its own file, so that an IAAS can be added without touching the others
+func Register(name string, r Registration) Name {
+	n, err := Assosiate(name)
+	if err != nil {
+		names = append(names, strings.ToUpper(name))
+		n = Name(len(names) - 1)
+	}
+	if _, ok := registrations[n]; ok {
+		panic("iaas: Register called twice for " + n.String())
+	}
+	registrations[n] = r
+	return n
+}
+
+// Lookup returns the registration of an IAAS
+func Lookup(iaasName Name) (Registration, error) {
+	r, ok := registrations[iaasName]
+	if !ok {
+		return Registration{}, fmt.Errorf("IAAS not supported: [%s]", iaasName)
+	}
+	return r, nil
+}
+
 // New returns a new IAAS client for a particular IAAS and region
 func New(iaasName Name, region string) (Provider, error) {
-	switch iaasName {
-	case AWS:
-		if region == "" {
-			region = "eu-west-1"
-		}
-
-		return newAWS(region)
-	case GCP:
-		if region == "" {
-			region = "europe-west1"
-		}
-		return newGCP(region, GCPStorage())
+	r, err := Lookup(iaasName)
+	if err != nil {
+		return nil, err
 	}
+	if region == "" {
+		region = r.DefaultRegion()
+	}
+	return r.New(region)
+}
+
+// ErrFileExists is returned by CreateFile when the file is already in the bucket
+var ErrFileExists = errors.New("the file already exists")
 
-	return nil, fmt.Errorf("IAAS not supported: [%s]", iaasName)
+// isNotFound returns true if err is an error from an IAAS API for something which does not exist
+func isNotFound(err error) bool {
+	apiErr, ok := err.(interface{ notFound() bool })
+	return ok && apiErr.notFound()
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/iaas/iaasfakes"
 	"github.com/EngineerBetter/concourse-up/testsupport"
 )
 
@@ -56,6 +57,34 @@ func TestNew(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "return azure provider",
+			args: args{
+				iaas:   iaas.Azure,
+				region: "aRegion",
+			},
+			want:    iaas.Azure,
+			wantErr: false,
+			setup: func(t *testing.T) string {
+				testsupport.SetupFakeCredsForAzureProvider(t)
+				return ""
+			},
+			cleanup: func(t *testing.T, s string) {},
+		},
+		{
+			name: "return openstack provider",
+			args: args{
+				iaas:   iaas.OpenStack,
+				region: "aRegion",
+			},
+			want:    iaas.OpenStack,
+			wantErr: false,
+			setup: func(t *testing.T) string {
+				testsupport.SetupFakeCredsForOpenStackProvider(t)
+				return ""
+			},
+			cleanup: func(t *testing.T, s string) {},
+		},
 		{
 			name: "does not care about case",
 			args: args{
@@ -110,6 +139,18 @@ func TestAssosiate(t *testing.T) {
 			want:    iaas.AWS,
 			wantErr: false,
 		},
+		{
+			name:    "get the Azure Name successfully case insensitive",
+			arg:     "Azure",
+			want:    iaas.Azure,
+			wantErr: false,
+		},
+		{
+			name:    "get the OpenStack Name successfully case insensitive",
+			arg:     "OpenStack",
+			want:    iaas.OpenStack,
+			wantErr: false,
+		},
 		{
 			name:    "fail on unknown iaas name",
 			arg:     "aProvider",
@@ -130,3 +171,62 @@ func TestAssosiate(t *testing.T) {
 		})
 	}
 }
+
+func TestRegister(t *testing.T) {
+	provider := &iaasfakes.FakeProvider{}
+	var gotRegion string
+	name := iaas.Register("in-house", iaas.Registration{
+		DefaultRegion: func() string { return "dc1" },
+		New: func(region string) (iaas.Provider, error) {
+			gotRegion = region
+			return provider, nil
+		},
+		GatewayUser: "ubuntu",
+	})
+
+	if name.String() != "IN-HOUSE" {
+		t.Errorf("Register() = %v, want IN-HOUSE", name)
+	}
+	if got, err := iaas.Assosiate("In-House"); err != nil || got != name {
+		t.Errorf("Assosiate() = %v, %v, want %v", got, err, name)
+	}
+
+	got, err := iaas.New(name, "")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if got != provider || gotRegion != "dc1" {
+		t.Errorf("New() = %v in %q, want the registered provider in dc1", got, gotRegion)
+	}
+
+	r, err := iaas.Lookup(name)
+	if err != nil || r.GatewayUser != "ubuntu" {
+		t.Errorf("Lookup() = %+v, %v", r, err)
+	}
+	if _, err = iaas.Lookup(iaas.Unknown); err == nil {
+		t.Error("Lookup() did not return an error for an unregistered IAAS")
+	}
+}
+
+func TestRegistrations(t *testing.T) {
+	for _, name := range []iaas.Name{iaas.AWS, iaas.GCP, iaas.Azure, iaas.OpenStack} {
+		r, err := iaas.Lookup(name)
+		if err != nil {
+			t.Errorf("Lookup(%s) error = %v", name, err)
+			continue
+		}
+		if r.Ranges.Public == "" || r.Ranges.Private == "" {
+			t.Errorf("%s has no default public and private ranges: %+v", name, r.Ranges)
+		}
+		if r.DBNameFormat == "" {
+			t.Errorf("%s has no database name format", name)
+		}
+	}
+}
+
+func TestProviderTeardown(t *testing.T) {
+	var _ iaas.VPCTeardown = &iaas.AWSProvider{}
+	var _ iaas.LabelTeardown = &iaas.GCPProvider{}
+	var _ iaas.DeploymentTeardown = &iaas.AzureProvider{}
+	var _ iaas.DeploymentTeardown = &iaas.OpenStackProvider{}
+}
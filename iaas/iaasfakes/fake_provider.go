@@ -48,17 +48,6 @@ type FakeProvider struct {
 		result1 bool
 		result2 error
 	}
-	ChooseStub        func(iaas.Choice) interface{}
-	chooseMutex       sync.RWMutex
-	chooseArgsForCall []struct {
-		arg1 iaas.Choice
-	}
-	chooseReturns struct {
-		result1 interface{}
-	}
-	chooseReturnsOnCall map[int]struct {
-		result1 interface{}
-	}
 	CreateBucketStub        func(string) error
 	createBucketMutex       sync.RWMutex
 	createBucketArgsForCall []struct {
@@ -83,6 +72,30 @@ type FakeProvider struct {
 	createDatabasesReturnsOnCall map[int]struct {
 		result1 error
 	}
+	CreateFileStub        func(string, string, []byte) error
+	createFileMutex       sync.RWMutex
+	createFileArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 []byte
+	}
+	createFileReturns struct {
+		result1 error
+	}
+	createFileReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CreateLockTableStub        func(string) error
+	createLockTableMutex       sync.RWMutex
+	createLockTableArgsForCall []struct {
+		arg1 string
+	}
+	createLockTableReturns struct {
+		result1 error
+	}
+	createLockTableReturnsOnCall map[int]struct {
+		result1 error
+	}
 	DBTypeStub        func(string) string
 	dBTypeMutex       sync.RWMutex
 	dBTypeArgsForCall []struct {
@@ -94,6 +107,20 @@ type FakeProvider struct {
 	dBTypeReturnsOnCall map[int]struct {
 		result1 string
 	}
+	DecryptKeyStub        func(string, []byte) ([]byte, error)
+	decryptKeyMutex       sync.RWMutex
+	decryptKeyArgsForCall []struct {
+		arg1 string
+		arg2 []byte
+	}
+	decryptKeyReturns struct {
+		result1 []byte
+		result2 error
+	}
+	decryptKeyReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
 	DeleteFileStub        func(string, string) error
 	deleteFileMutex       sync.RWMutex
 	deleteFileArgsForCall []struct {
@@ -106,32 +133,17 @@ type FakeProvider struct {
 	deleteFileReturnsOnCall map[int]struct {
 		result1 error
 	}
-	DeleteVMsInDeploymentStub        func(string, string, string) error
-	deleteVMsInDeploymentMutex       sync.RWMutex
-	deleteVMsInDeploymentArgsForCall []struct {
+	DeleteLockTableStub        func(string) error
+	deleteLockTableMutex       sync.RWMutex
+	deleteLockTableArgsForCall []struct {
 		arg1 string
-		arg2 string
-		arg3 string
 	}
-	deleteVMsInDeploymentReturns struct {
+	deleteLockTableReturns struct {
 		result1 error
 	}
-	deleteVMsInDeploymentReturnsOnCall map[int]struct {
+	deleteLockTableReturnsOnCall map[int]struct {
 		result1 error
 	}
-	DeleteVMsInVPCStub        func(string) ([]string, error)
-	deleteVMsInVPCMutex       sync.RWMutex
-	deleteVMsInVPCArgsForCall []struct {
-		arg1 string
-	}
-	deleteVMsInVPCReturns struct {
-		result1 []string
-		result2 error
-	}
-	deleteVMsInVPCReturnsOnCall map[int]struct {
-		result1 []string
-		result2 error
-	}
 	DeleteVersionedBucketStub        func(string) error
 	deleteVersionedBucketMutex       sync.RWMutex
 	deleteVersionedBucketArgsForCall []struct {
@@ -143,18 +155,31 @@ type FakeProvider struct {
 	deleteVersionedBucketReturnsOnCall map[int]struct {
 		result1 error
 	}
-	DeleteVolumesStub        func([]string, func(ec2Client iaas.IEC2, volumeID *string) error) error
-	deleteVolumesMutex       sync.RWMutex
-	deleteVolumesArgsForCall []struct {
-		arg1 []string
-		arg2 func(ec2Client iaas.IEC2, volumeID *string) error
+	EnableVersioningStub        func(string) error
+	enableVersioningMutex       sync.RWMutex
+	enableVersioningArgsForCall []struct {
+		arg1 string
 	}
-	deleteVolumesReturns struct {
+	enableVersioningReturns struct {
 		result1 error
 	}
-	deleteVolumesReturnsOnCall map[int]struct {
+	enableVersioningReturnsOnCall map[int]struct {
 		result1 error
 	}
+	EncryptKeyStub        func(string, []byte) ([]byte, error)
+	encryptKeyMutex       sync.RWMutex
+	encryptKeyArgsForCall []struct {
+		arg1 string
+		arg2 []byte
+	}
+	encryptKeyReturns struct {
+		result1 []byte
+		result2 error
+	}
+	encryptKeyReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
 	EnsureFileExistsStub        func(string, string, []byte) ([]byte, bool, error)
 	ensureFileExistsMutex       sync.RWMutex
 	ensureFileExistsArgsForCall []struct {
@@ -187,6 +212,20 @@ type FakeProvider struct {
 		result2 string
 		result3 error
 	}
+	FindNetworkStub        func(string, ...string) (iaas.Network, error)
+	findNetworkMutex       sync.RWMutex
+	findNetworkArgsForCall []struct {
+		arg1 string
+		arg2 []string
+	}
+	findNetworkReturns struct {
+		result1 iaas.Network
+		result2 error
+	}
+	findNetworkReturnsOnCall map[int]struct {
+		result1 iaas.Network
+		result2 error
+	}
 	HasFileStub        func(string, string) (bool, error)
 	hasFileMutex       sync.RWMutex
 	hasFileArgsForCall []struct {
@@ -211,6 +250,44 @@ type FakeProvider struct {
 	iAASReturnsOnCall map[int]struct {
 		result1 iaas.Name
 	}
+	IdentityStub        func() (string, error)
+	identityMutex       sync.RWMutex
+	identityArgsForCall []struct {
+	}
+	identityReturns struct {
+		result1 string
+		result2 error
+	}
+	identityReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	ListBucketsStub        func() ([]string, error)
+	listBucketsMutex       sync.RWMutex
+	listBucketsArgsForCall []struct {
+	}
+	listBucketsReturns struct {
+		result1 []string
+		result2 error
+	}
+	listBucketsReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
+	ListFileVersionsStub        func(string, string) ([]iaas.FileVersion, error)
+	listFileVersionsMutex       sync.RWMutex
+	listFileVersionsArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	listFileVersionsReturns struct {
+		result1 []iaas.FileVersion
+		result2 error
+	}
+	listFileVersionsReturnsOnCall map[int]struct {
+		result1 []iaas.FileVersion
+		result2 error
+	}
 	LoadFileStub        func(string, string) ([]byte, error)
 	loadFileMutex       sync.RWMutex
 	loadFileArgsForCall []struct {
@@ -225,6 +302,21 @@ type FakeProvider struct {
 		result1 []byte
 		result2 error
 	}
+	LoadFileVersionStub        func(string, string, string) ([]byte, error)
+	loadFileVersionMutex       sync.RWMutex
+	loadFileVersionArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}
+	loadFileVersionReturns struct {
+		result1 []byte
+		result2 error
+	}
+	loadFileVersionReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
 	RegionStub        func() string
 	regionMutex       sync.RWMutex
 	regionArgsForCall []struct {
@@ -458,66 +550,6 @@ func (fake *FakeProvider) CheckForWhitelistedIPReturnsOnCall(i int, result1 bool
 	}{result1, result2}
 }
 
-func (fake *FakeProvider) Choose(arg1 iaas.Choice) interface{} {
-	fake.chooseMutex.Lock()
-	ret, specificReturn := fake.chooseReturnsOnCall[len(fake.chooseArgsForCall)]
-	fake.chooseArgsForCall = append(fake.chooseArgsForCall, struct {
-		arg1 iaas.Choice
-	}{arg1})
-	fake.recordInvocation("Choose", []interface{}{arg1})
-	fake.chooseMutex.Unlock()
-	if fake.ChooseStub != nil {
-		return fake.ChooseStub(arg1)
-	}
-	if specificReturn {
-		return ret.result1
-	}
-	fakeReturns := fake.chooseReturns
-	return fakeReturns.result1
-}
-
-func (fake *FakeProvider) ChooseCallCount() int {
-	fake.chooseMutex.RLock()
-	defer fake.chooseMutex.RUnlock()
-	return len(fake.chooseArgsForCall)
-}
-
-func (fake *FakeProvider) ChooseCalls(stub func(iaas.Choice) interface{}) {
-	fake.chooseMutex.Lock()
-	defer fake.chooseMutex.Unlock()
-	fake.ChooseStub = stub
-}
-
-func (fake *FakeProvider) ChooseArgsForCall(i int) iaas.Choice {
-	fake.chooseMutex.RLock()
-	defer fake.chooseMutex.RUnlock()
-	argsForCall := fake.chooseArgsForCall[i]
-	return argsForCall.arg1
-}
-
-func (fake *FakeProvider) ChooseReturns(result1 interface{}) {
-	fake.chooseMutex.Lock()
-	defer fake.chooseMutex.Unlock()
-	fake.ChooseStub = nil
-	fake.chooseReturns = struct {
-		result1 interface{}
-	}{result1}
-}
-
-func (fake *FakeProvider) ChooseReturnsOnCall(i int, result1 interface{}) {
-	fake.chooseMutex.Lock()
-	defer fake.chooseMutex.Unlock()
-	fake.ChooseStub = nil
-	if fake.chooseReturnsOnCall == nil {
-		fake.chooseReturnsOnCall = make(map[int]struct {
-			result1 interface{}
-		})
-	}
-	fake.chooseReturnsOnCall[i] = struct {
-		result1 interface{}
-	}{result1}
-}
-
 func (fake *FakeProvider) CreateBucket(arg1 string) error {
 	fake.createBucketMutex.Lock()
 	ret, specificReturn := fake.createBucketReturnsOnCall[len(fake.createBucketArgsForCall)]
@@ -640,6 +672,133 @@ func (fake *FakeProvider) CreateDatabasesReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeProvider) CreateFile(arg1 string, arg2 string, arg3 []byte) error {
+	var arg3Copy []byte
+	if arg3 != nil {
+		arg3Copy = make([]byte, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.createFileMutex.Lock()
+	ret, specificReturn := fake.createFileReturnsOnCall[len(fake.createFileArgsForCall)]
+	fake.createFileArgsForCall = append(fake.createFileArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 []byte
+	}{arg1, arg2, arg3Copy})
+	fake.recordInvocation("CreateFile", []interface{}{arg1, arg2, arg3Copy})
+	fake.createFileMutex.Unlock()
+	if fake.CreateFileStub != nil {
+		return fake.CreateFileStub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.createFileReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeProvider) CreateFileCallCount() int {
+	fake.createFileMutex.RLock()
+	defer fake.createFileMutex.RUnlock()
+	return len(fake.createFileArgsForCall)
+}
+
+func (fake *FakeProvider) CreateFileCalls(stub func(string, string, []byte) error) {
+	fake.createFileMutex.Lock()
+	defer fake.createFileMutex.Unlock()
+	fake.CreateFileStub = stub
+}
+
+func (fake *FakeProvider) CreateFileArgsForCall(i int) (string, string, []byte) {
+	fake.createFileMutex.RLock()
+	defer fake.createFileMutex.RUnlock()
+	argsForCall := fake.createFileArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeProvider) CreateFileReturns(result1 error) {
+	fake.createFileMutex.Lock()
+	defer fake.createFileMutex.Unlock()
+	fake.CreateFileStub = nil
+	fake.createFileReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) CreateFileReturnsOnCall(i int, result1 error) {
+	fake.createFileMutex.Lock()
+	defer fake.createFileMutex.Unlock()
+	fake.CreateFileStub = nil
+	if fake.createFileReturnsOnCall == nil {
+		fake.createFileReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.createFileReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) CreateLockTable(arg1 string) error {
+	fake.createLockTableMutex.Lock()
+	ret, specificReturn := fake.createLockTableReturnsOnCall[len(fake.createLockTableArgsForCall)]
+	fake.createLockTableArgsForCall = append(fake.createLockTableArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("CreateLockTable", []interface{}{arg1})
+	fake.createLockTableMutex.Unlock()
+	if fake.CreateLockTableStub != nil {
+		return fake.CreateLockTableStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.createLockTableReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeProvider) CreateLockTableCallCount() int {
+	fake.createLockTableMutex.RLock()
+	defer fake.createLockTableMutex.RUnlock()
+	return len(fake.createLockTableArgsForCall)
+}
+
+func (fake *FakeProvider) CreateLockTableCalls(stub func(string) error) {
+	fake.createLockTableMutex.Lock()
+	defer fake.createLockTableMutex.Unlock()
+	fake.CreateLockTableStub = stub
+}
+
+func (fake *FakeProvider) CreateLockTableArgsForCall(i int) string {
+	fake.createLockTableMutex.RLock()
+	defer fake.createLockTableMutex.RUnlock()
+	argsForCall := fake.createLockTableArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeProvider) CreateLockTableReturns(result1 error) {
+	fake.createLockTableMutex.Lock()
+	defer fake.createLockTableMutex.Unlock()
+	fake.CreateLockTableStub = nil
+	fake.createLockTableReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) CreateLockTableReturnsOnCall(i int, result1 error) {
+	fake.createLockTableMutex.Lock()
+	defer fake.createLockTableMutex.Unlock()
+	fake.CreateLockTableStub = nil
+	if fake.createLockTableReturnsOnCall == nil {
+		fake.createLockTableReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.createLockTableReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeProvider) DBType(arg1 string) string {
 	fake.dBTypeMutex.Lock()
 	ret, specificReturn := fake.dBTypeReturnsOnCall[len(fake.dBTypeArgsForCall)]
@@ -700,6 +859,75 @@ func (fake *FakeProvider) DBTypeReturnsOnCall(i int, result1 string) {
 	}{result1}
 }
 
+func (fake *FakeProvider) DecryptKey(arg1 string, arg2 []byte) ([]byte, error) {
+	var arg2Copy []byte
+	if arg2 != nil {
+		arg2Copy = make([]byte, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.decryptKeyMutex.Lock()
+	ret, specificReturn := fake.decryptKeyReturnsOnCall[len(fake.decryptKeyArgsForCall)]
+	fake.decryptKeyArgsForCall = append(fake.decryptKeyArgsForCall, struct {
+		arg1 string
+		arg2 []byte
+	}{arg1, arg2Copy})
+	fake.recordInvocation("DecryptKey", []interface{}{arg1, arg2Copy})
+	fake.decryptKeyMutex.Unlock()
+	if fake.DecryptKeyStub != nil {
+		return fake.DecryptKeyStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.decryptKeyReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProvider) DecryptKeyCallCount() int {
+	fake.decryptKeyMutex.RLock()
+	defer fake.decryptKeyMutex.RUnlock()
+	return len(fake.decryptKeyArgsForCall)
+}
+
+func (fake *FakeProvider) DecryptKeyCalls(stub func(string, []byte) ([]byte, error)) {
+	fake.decryptKeyMutex.Lock()
+	defer fake.decryptKeyMutex.Unlock()
+	fake.DecryptKeyStub = stub
+}
+
+func (fake *FakeProvider) DecryptKeyArgsForCall(i int) (string, []byte) {
+	fake.decryptKeyMutex.RLock()
+	defer fake.decryptKeyMutex.RUnlock()
+	argsForCall := fake.decryptKeyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeProvider) DecryptKeyReturns(result1 []byte, result2 error) {
+	fake.decryptKeyMutex.Lock()
+	defer fake.decryptKeyMutex.Unlock()
+	fake.DecryptKeyStub = nil
+	fake.decryptKeyReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) DecryptKeyReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.decryptKeyMutex.Lock()
+	defer fake.decryptKeyMutex.Unlock()
+	fake.DecryptKeyStub = nil
+	if fake.decryptKeyReturnsOnCall == nil {
+		fake.decryptKeyReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.decryptKeyReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeProvider) DeleteFile(arg1 string, arg2 string) error {
 	fake.deleteFileMutex.Lock()
 	ret, specificReturn := fake.deleteFileReturnsOnCall[len(fake.deleteFileArgsForCall)]
@@ -761,131 +989,66 @@ func (fake *FakeProvider) DeleteFileReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakeProvider) DeleteVMsInDeployment(arg1 string, arg2 string, arg3 string) error {
-	fake.deleteVMsInDeploymentMutex.Lock()
-	ret, specificReturn := fake.deleteVMsInDeploymentReturnsOnCall[len(fake.deleteVMsInDeploymentArgsForCall)]
-	fake.deleteVMsInDeploymentArgsForCall = append(fake.deleteVMsInDeploymentArgsForCall, struct {
+func (fake *FakeProvider) DeleteLockTable(arg1 string) error {
+	fake.deleteLockTableMutex.Lock()
+	ret, specificReturn := fake.deleteLockTableReturnsOnCall[len(fake.deleteLockTableArgsForCall)]
+	fake.deleteLockTableArgsForCall = append(fake.deleteLockTableArgsForCall, struct {
 		arg1 string
-		arg2 string
-		arg3 string
-	}{arg1, arg2, arg3})
-	fake.recordInvocation("DeleteVMsInDeployment", []interface{}{arg1, arg2, arg3})
-	fake.deleteVMsInDeploymentMutex.Unlock()
-	if fake.DeleteVMsInDeploymentStub != nil {
-		return fake.DeleteVMsInDeploymentStub(arg1, arg2, arg3)
+	}{arg1})
+	fake.recordInvocation("DeleteLockTable", []interface{}{arg1})
+	fake.deleteLockTableMutex.Unlock()
+	if fake.DeleteLockTableStub != nil {
+		return fake.DeleteLockTableStub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.deleteVMsInDeploymentReturns
+	fakeReturns := fake.deleteLockTableReturns
 	return fakeReturns.result1
 }
 
-func (fake *FakeProvider) DeleteVMsInDeploymentCallCount() int {
-	fake.deleteVMsInDeploymentMutex.RLock()
-	defer fake.deleteVMsInDeploymentMutex.RUnlock()
-	return len(fake.deleteVMsInDeploymentArgsForCall)
+func (fake *FakeProvider) DeleteLockTableCallCount() int {
+	fake.deleteLockTableMutex.RLock()
+	defer fake.deleteLockTableMutex.RUnlock()
+	return len(fake.deleteLockTableArgsForCall)
 }
 
-func (fake *FakeProvider) DeleteVMsInDeploymentCalls(stub func(string, string, string) error) {
-	fake.deleteVMsInDeploymentMutex.Lock()
-	defer fake.deleteVMsInDeploymentMutex.Unlock()
-	fake.DeleteVMsInDeploymentStub = stub
+func (fake *FakeProvider) DeleteLockTableCalls(stub func(string) error) {
+	fake.deleteLockTableMutex.Lock()
+	defer fake.deleteLockTableMutex.Unlock()
+	fake.DeleteLockTableStub = stub
 }
 
-func (fake *FakeProvider) DeleteVMsInDeploymentArgsForCall(i int) (string, string, string) {
-	fake.deleteVMsInDeploymentMutex.RLock()
-	defer fake.deleteVMsInDeploymentMutex.RUnlock()
-	argsForCall := fake.deleteVMsInDeploymentArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+func (fake *FakeProvider) DeleteLockTableArgsForCall(i int) string {
+	fake.deleteLockTableMutex.RLock()
+	defer fake.deleteLockTableMutex.RUnlock()
+	argsForCall := fake.deleteLockTableArgsForCall[i]
+	return argsForCall.arg1
 }
 
-func (fake *FakeProvider) DeleteVMsInDeploymentReturns(result1 error) {
-	fake.deleteVMsInDeploymentMutex.Lock()
-	defer fake.deleteVMsInDeploymentMutex.Unlock()
-	fake.DeleteVMsInDeploymentStub = nil
-	fake.deleteVMsInDeploymentReturns = struct {
+func (fake *FakeProvider) DeleteLockTableReturns(result1 error) {
+	fake.deleteLockTableMutex.Lock()
+	defer fake.deleteLockTableMutex.Unlock()
+	fake.DeleteLockTableStub = nil
+	fake.deleteLockTableReturns = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeProvider) DeleteVMsInDeploymentReturnsOnCall(i int, result1 error) {
-	fake.deleteVMsInDeploymentMutex.Lock()
-	defer fake.deleteVMsInDeploymentMutex.Unlock()
-	fake.DeleteVMsInDeploymentStub = nil
-	if fake.deleteVMsInDeploymentReturnsOnCall == nil {
-		fake.deleteVMsInDeploymentReturnsOnCall = make(map[int]struct {
+func (fake *FakeProvider) DeleteLockTableReturnsOnCall(i int, result1 error) {
+	fake.deleteLockTableMutex.Lock()
+	defer fake.deleteLockTableMutex.Unlock()
+	fake.DeleteLockTableStub = nil
+	if fake.deleteLockTableReturnsOnCall == nil {
+		fake.deleteLockTableReturnsOnCall = make(map[int]struct {
 			result1 error
 		})
 	}
-	fake.deleteVMsInDeploymentReturnsOnCall[i] = struct {
+	fake.deleteLockTableReturnsOnCall[i] = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeProvider) DeleteVMsInVPC(arg1 string) ([]string, error) {
-	fake.deleteVMsInVPCMutex.Lock()
-	ret, specificReturn := fake.deleteVMsInVPCReturnsOnCall[len(fake.deleteVMsInVPCArgsForCall)]
-	fake.deleteVMsInVPCArgsForCall = append(fake.deleteVMsInVPCArgsForCall, struct {
-		arg1 string
-	}{arg1})
-	fake.recordInvocation("DeleteVMsInVPC", []interface{}{arg1})
-	fake.deleteVMsInVPCMutex.Unlock()
-	if fake.DeleteVMsInVPCStub != nil {
-		return fake.DeleteVMsInVPCStub(arg1)
-	}
-	if specificReturn {
-		return ret.result1, ret.result2
-	}
-	fakeReturns := fake.deleteVMsInVPCReturns
-	return fakeReturns.result1, fakeReturns.result2
-}
-
-func (fake *FakeProvider) DeleteVMsInVPCCallCount() int {
-	fake.deleteVMsInVPCMutex.RLock()
-	defer fake.deleteVMsInVPCMutex.RUnlock()
-	return len(fake.deleteVMsInVPCArgsForCall)
-}
-
-func (fake *FakeProvider) DeleteVMsInVPCCalls(stub func(string) ([]string, error)) {
-	fake.deleteVMsInVPCMutex.Lock()
-	defer fake.deleteVMsInVPCMutex.Unlock()
-	fake.DeleteVMsInVPCStub = stub
-}
-
-func (fake *FakeProvider) DeleteVMsInVPCArgsForCall(i int) string {
-	fake.deleteVMsInVPCMutex.RLock()
-	defer fake.deleteVMsInVPCMutex.RUnlock()
-	argsForCall := fake.deleteVMsInVPCArgsForCall[i]
-	return argsForCall.arg1
-}
-
-func (fake *FakeProvider) DeleteVMsInVPCReturns(result1 []string, result2 error) {
-	fake.deleteVMsInVPCMutex.Lock()
-	defer fake.deleteVMsInVPCMutex.Unlock()
-	fake.DeleteVMsInVPCStub = nil
-	fake.deleteVMsInVPCReturns = struct {
-		result1 []string
-		result2 error
-	}{result1, result2}
-}
-
-func (fake *FakeProvider) DeleteVMsInVPCReturnsOnCall(i int, result1 []string, result2 error) {
-	fake.deleteVMsInVPCMutex.Lock()
-	defer fake.deleteVMsInVPCMutex.Unlock()
-	fake.DeleteVMsInVPCStub = nil
-	if fake.deleteVMsInVPCReturnsOnCall == nil {
-		fake.deleteVMsInVPCReturnsOnCall = make(map[int]struct {
-			result1 []string
-			result2 error
-		})
-	}
-	fake.deleteVMsInVPCReturnsOnCall[i] = struct {
-		result1 []string
-		result2 error
-	}{result1, result2}
-}
-
 func (fake *FakeProvider) DeleteVersionedBucket(arg1 string) error {
 	fake.deleteVersionedBucketMutex.Lock()
 	ret, specificReturn := fake.deleteVersionedBucketReturnsOnCall[len(fake.deleteVersionedBucketArgsForCall)]
@@ -946,72 +1109,135 @@ func (fake *FakeProvider) DeleteVersionedBucketReturnsOnCall(i int, result1 erro
 	}{result1}
 }
 
-func (fake *FakeProvider) DeleteVolumes(arg1 []string, arg2 func(ec2Client iaas.IEC2, volumeID *string) error) error {
-	var arg1Copy []string
-	if arg1 != nil {
-		arg1Copy = make([]string, len(arg1))
-		copy(arg1Copy, arg1)
-	}
-	fake.deleteVolumesMutex.Lock()
-	ret, specificReturn := fake.deleteVolumesReturnsOnCall[len(fake.deleteVolumesArgsForCall)]
-	fake.deleteVolumesArgsForCall = append(fake.deleteVolumesArgsForCall, struct {
-		arg1 []string
-		arg2 func(ec2Client iaas.IEC2, volumeID *string) error
-	}{arg1Copy, arg2})
-	fake.recordInvocation("DeleteVolumes", []interface{}{arg1Copy, arg2})
-	fake.deleteVolumesMutex.Unlock()
-	if fake.DeleteVolumesStub != nil {
-		return fake.DeleteVolumesStub(arg1, arg2)
+func (fake *FakeProvider) EnableVersioning(arg1 string) error {
+	fake.enableVersioningMutex.Lock()
+	ret, specificReturn := fake.enableVersioningReturnsOnCall[len(fake.enableVersioningArgsForCall)]
+	fake.enableVersioningArgsForCall = append(fake.enableVersioningArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("EnableVersioning", []interface{}{arg1})
+	fake.enableVersioningMutex.Unlock()
+	if fake.EnableVersioningStub != nil {
+		return fake.EnableVersioningStub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.deleteVolumesReturns
+	fakeReturns := fake.enableVersioningReturns
 	return fakeReturns.result1
 }
 
-func (fake *FakeProvider) DeleteVolumesCallCount() int {
-	fake.deleteVolumesMutex.RLock()
-	defer fake.deleteVolumesMutex.RUnlock()
-	return len(fake.deleteVolumesArgsForCall)
+func (fake *FakeProvider) EnableVersioningCallCount() int {
+	fake.enableVersioningMutex.RLock()
+	defer fake.enableVersioningMutex.RUnlock()
+	return len(fake.enableVersioningArgsForCall)
 }
 
-func (fake *FakeProvider) DeleteVolumesCalls(stub func([]string, func(ec2Client iaas.IEC2, volumeID *string) error) error) {
-	fake.deleteVolumesMutex.Lock()
-	defer fake.deleteVolumesMutex.Unlock()
-	fake.DeleteVolumesStub = stub
+func (fake *FakeProvider) EnableVersioningCalls(stub func(string) error) {
+	fake.enableVersioningMutex.Lock()
+	defer fake.enableVersioningMutex.Unlock()
+	fake.EnableVersioningStub = stub
 }
 
-func (fake *FakeProvider) DeleteVolumesArgsForCall(i int) ([]string, func(ec2Client iaas.IEC2, volumeID *string) error) {
-	fake.deleteVolumesMutex.RLock()
-	defer fake.deleteVolumesMutex.RUnlock()
-	argsForCall := fake.deleteVolumesArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+func (fake *FakeProvider) EnableVersioningArgsForCall(i int) string {
+	fake.enableVersioningMutex.RLock()
+	defer fake.enableVersioningMutex.RUnlock()
+	argsForCall := fake.enableVersioningArgsForCall[i]
+	return argsForCall.arg1
 }
 
-func (fake *FakeProvider) DeleteVolumesReturns(result1 error) {
-	fake.deleteVolumesMutex.Lock()
-	defer fake.deleteVolumesMutex.Unlock()
-	fake.DeleteVolumesStub = nil
-	fake.deleteVolumesReturns = struct {
+func (fake *FakeProvider) EnableVersioningReturns(result1 error) {
+	fake.enableVersioningMutex.Lock()
+	defer fake.enableVersioningMutex.Unlock()
+	fake.EnableVersioningStub = nil
+	fake.enableVersioningReturns = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeProvider) DeleteVolumesReturnsOnCall(i int, result1 error) {
-	fake.deleteVolumesMutex.Lock()
-	defer fake.deleteVolumesMutex.Unlock()
-	fake.DeleteVolumesStub = nil
-	if fake.deleteVolumesReturnsOnCall == nil {
-		fake.deleteVolumesReturnsOnCall = make(map[int]struct {
+func (fake *FakeProvider) EnableVersioningReturnsOnCall(i int, result1 error) {
+	fake.enableVersioningMutex.Lock()
+	defer fake.enableVersioningMutex.Unlock()
+	fake.EnableVersioningStub = nil
+	if fake.enableVersioningReturnsOnCall == nil {
+		fake.enableVersioningReturnsOnCall = make(map[int]struct {
 			result1 error
 		})
 	}
-	fake.deleteVolumesReturnsOnCall[i] = struct {
+	fake.enableVersioningReturnsOnCall[i] = struct {
 		result1 error
 	}{result1}
 }
 
+func (fake *FakeProvider) EncryptKey(arg1 string, arg2 []byte) ([]byte, error) {
+	var arg2Copy []byte
+	if arg2 != nil {
+		arg2Copy = make([]byte, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.encryptKeyMutex.Lock()
+	ret, specificReturn := fake.encryptKeyReturnsOnCall[len(fake.encryptKeyArgsForCall)]
+	fake.encryptKeyArgsForCall = append(fake.encryptKeyArgsForCall, struct {
+		arg1 string
+		arg2 []byte
+	}{arg1, arg2Copy})
+	fake.recordInvocation("EncryptKey", []interface{}{arg1, arg2Copy})
+	fake.encryptKeyMutex.Unlock()
+	if fake.EncryptKeyStub != nil {
+		return fake.EncryptKeyStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.encryptKeyReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProvider) EncryptKeyCallCount() int {
+	fake.encryptKeyMutex.RLock()
+	defer fake.encryptKeyMutex.RUnlock()
+	return len(fake.encryptKeyArgsForCall)
+}
+
+func (fake *FakeProvider) EncryptKeyCalls(stub func(string, []byte) ([]byte, error)) {
+	fake.encryptKeyMutex.Lock()
+	defer fake.encryptKeyMutex.Unlock()
+	fake.EncryptKeyStub = stub
+}
+
+func (fake *FakeProvider) EncryptKeyArgsForCall(i int) (string, []byte) {
+	fake.encryptKeyMutex.RLock()
+	defer fake.encryptKeyMutex.RUnlock()
+	argsForCall := fake.encryptKeyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeProvider) EncryptKeyReturns(result1 []byte, result2 error) {
+	fake.encryptKeyMutex.Lock()
+	defer fake.encryptKeyMutex.Unlock()
+	fake.EncryptKeyStub = nil
+	fake.encryptKeyReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) EncryptKeyReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.encryptKeyMutex.Lock()
+	defer fake.encryptKeyMutex.Unlock()
+	fake.EncryptKeyStub = nil
+	if fake.encryptKeyReturnsOnCall == nil {
+		fake.encryptKeyReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.encryptKeyReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeProvider) EnsureFileExists(arg1 string, arg2 string, arg3 []byte) ([]byte, bool, error) {
 	var arg3Copy []byte
 	if arg3 != nil {
@@ -1151,6 +1377,70 @@ func (fake *FakeProvider) FindLongestMatchingHostedZoneReturnsOnCall(i int, resu
 	}{result1, result2, result3}
 }
 
+func (fake *FakeProvider) FindNetwork(arg1 string, arg2 ...string) (iaas.Network, error) {
+	fake.findNetworkMutex.Lock()
+	ret, specificReturn := fake.findNetworkReturnsOnCall[len(fake.findNetworkArgsForCall)]
+	fake.findNetworkArgsForCall = append(fake.findNetworkArgsForCall, struct {
+		arg1 string
+		arg2 []string
+	}{arg1, arg2})
+	fake.recordInvocation("FindNetwork", []interface{}{arg1, arg2})
+	fake.findNetworkMutex.Unlock()
+	if fake.FindNetworkStub != nil {
+		return fake.FindNetworkStub(arg1, arg2...)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.findNetworkReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProvider) FindNetworkCallCount() int {
+	fake.findNetworkMutex.RLock()
+	defer fake.findNetworkMutex.RUnlock()
+	return len(fake.findNetworkArgsForCall)
+}
+
+func (fake *FakeProvider) FindNetworkCalls(stub func(string, ...string) (iaas.Network, error)) {
+	fake.findNetworkMutex.Lock()
+	defer fake.findNetworkMutex.Unlock()
+	fake.FindNetworkStub = stub
+}
+
+func (fake *FakeProvider) FindNetworkArgsForCall(i int) (string, []string) {
+	fake.findNetworkMutex.RLock()
+	defer fake.findNetworkMutex.RUnlock()
+	argsForCall := fake.findNetworkArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeProvider) FindNetworkReturns(result1 iaas.Network, result2 error) {
+	fake.findNetworkMutex.Lock()
+	defer fake.findNetworkMutex.Unlock()
+	fake.FindNetworkStub = nil
+	fake.findNetworkReturns = struct {
+		result1 iaas.Network
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) FindNetworkReturnsOnCall(i int, result1 iaas.Network, result2 error) {
+	fake.findNetworkMutex.Lock()
+	defer fake.findNetworkMutex.Unlock()
+	fake.FindNetworkStub = nil
+	if fake.findNetworkReturnsOnCall == nil {
+		fake.findNetworkReturnsOnCall = make(map[int]struct {
+			result1 iaas.Network
+			result2 error
+		})
+	}
+	fake.findNetworkReturnsOnCall[i] = struct {
+		result1 iaas.Network
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeProvider) HasFile(arg1 string, arg2 string) (bool, error) {
 	fake.hasFileMutex.Lock()
 	ret, specificReturn := fake.hasFileReturnsOnCall[len(fake.hasFileArgsForCall)]
@@ -1267,6 +1557,180 @@ func (fake *FakeProvider) IAASReturnsOnCall(i int, result1 iaas.Name) {
 	}{result1}
 }
 
+func (fake *FakeProvider) Identity() (string, error) {
+	fake.identityMutex.Lock()
+	ret, specificReturn := fake.identityReturnsOnCall[len(fake.identityArgsForCall)]
+	fake.identityArgsForCall = append(fake.identityArgsForCall, struct {
+	}{})
+	fake.recordInvocation("Identity", []interface{}{})
+	fake.identityMutex.Unlock()
+	if fake.IdentityStub != nil {
+		return fake.IdentityStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.identityReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProvider) IdentityCallCount() int {
+	fake.identityMutex.RLock()
+	defer fake.identityMutex.RUnlock()
+	return len(fake.identityArgsForCall)
+}
+
+func (fake *FakeProvider) IdentityCalls(stub func() (string, error)) {
+	fake.identityMutex.Lock()
+	defer fake.identityMutex.Unlock()
+	fake.IdentityStub = stub
+}
+
+func (fake *FakeProvider) IdentityReturns(result1 string, result2 error) {
+	fake.identityMutex.Lock()
+	defer fake.identityMutex.Unlock()
+	fake.IdentityStub = nil
+	fake.identityReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) IdentityReturnsOnCall(i int, result1 string, result2 error) {
+	fake.identityMutex.Lock()
+	defer fake.identityMutex.Unlock()
+	fake.IdentityStub = nil
+	if fake.identityReturnsOnCall == nil {
+		fake.identityReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.identityReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) ListBuckets() ([]string, error) {
+	fake.listBucketsMutex.Lock()
+	ret, specificReturn := fake.listBucketsReturnsOnCall[len(fake.listBucketsArgsForCall)]
+	fake.listBucketsArgsForCall = append(fake.listBucketsArgsForCall, struct {
+	}{})
+	fake.recordInvocation("ListBuckets", []interface{}{})
+	fake.listBucketsMutex.Unlock()
+	if fake.ListBucketsStub != nil {
+		return fake.ListBucketsStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.listBucketsReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProvider) ListBucketsCallCount() int {
+	fake.listBucketsMutex.RLock()
+	defer fake.listBucketsMutex.RUnlock()
+	return len(fake.listBucketsArgsForCall)
+}
+
+func (fake *FakeProvider) ListBucketsCalls(stub func() ([]string, error)) {
+	fake.listBucketsMutex.Lock()
+	defer fake.listBucketsMutex.Unlock()
+	fake.ListBucketsStub = stub
+}
+
+func (fake *FakeProvider) ListBucketsReturns(result1 []string, result2 error) {
+	fake.listBucketsMutex.Lock()
+	defer fake.listBucketsMutex.Unlock()
+	fake.ListBucketsStub = nil
+	fake.listBucketsReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) ListBucketsReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.listBucketsMutex.Lock()
+	defer fake.listBucketsMutex.Unlock()
+	fake.ListBucketsStub = nil
+	if fake.listBucketsReturnsOnCall == nil {
+		fake.listBucketsReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.listBucketsReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) ListFileVersions(arg1 string, arg2 string) ([]iaas.FileVersion, error) {
+	fake.listFileVersionsMutex.Lock()
+	ret, specificReturn := fake.listFileVersionsReturnsOnCall[len(fake.listFileVersionsArgsForCall)]
+	fake.listFileVersionsArgsForCall = append(fake.listFileVersionsArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	fake.recordInvocation("ListFileVersions", []interface{}{arg1, arg2})
+	fake.listFileVersionsMutex.Unlock()
+	if fake.ListFileVersionsStub != nil {
+		return fake.ListFileVersionsStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.listFileVersionsReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProvider) ListFileVersionsCallCount() int {
+	fake.listFileVersionsMutex.RLock()
+	defer fake.listFileVersionsMutex.RUnlock()
+	return len(fake.listFileVersionsArgsForCall)
+}
+
+func (fake *FakeProvider) ListFileVersionsCalls(stub func(string, string) ([]iaas.FileVersion, error)) {
+	fake.listFileVersionsMutex.Lock()
+	defer fake.listFileVersionsMutex.Unlock()
+	fake.ListFileVersionsStub = stub
+}
+
+func (fake *FakeProvider) ListFileVersionsArgsForCall(i int) (string, string) {
+	fake.listFileVersionsMutex.RLock()
+	defer fake.listFileVersionsMutex.RUnlock()
+	argsForCall := fake.listFileVersionsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeProvider) ListFileVersionsReturns(result1 []iaas.FileVersion, result2 error) {
+	fake.listFileVersionsMutex.Lock()
+	defer fake.listFileVersionsMutex.Unlock()
+	fake.ListFileVersionsStub = nil
+	fake.listFileVersionsReturns = struct {
+		result1 []iaas.FileVersion
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) ListFileVersionsReturnsOnCall(i int, result1 []iaas.FileVersion, result2 error) {
+	fake.listFileVersionsMutex.Lock()
+	defer fake.listFileVersionsMutex.Unlock()
+	fake.ListFileVersionsStub = nil
+	if fake.listFileVersionsReturnsOnCall == nil {
+		fake.listFileVersionsReturnsOnCall = make(map[int]struct {
+			result1 []iaas.FileVersion
+			result2 error
+		})
+	}
+	fake.listFileVersionsReturnsOnCall[i] = struct {
+		result1 []iaas.FileVersion
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeProvider) LoadFile(arg1 string, arg2 string) ([]byte, error) {
 	fake.loadFileMutex.Lock()
 	ret, specificReturn := fake.loadFileReturnsOnCall[len(fake.loadFileArgsForCall)]
@@ -1331,6 +1795,71 @@ func (fake *FakeProvider) LoadFileReturnsOnCall(i int, result1 []byte, result2 e
 	}{result1, result2}
 }
 
+func (fake *FakeProvider) LoadFileVersion(arg1 string, arg2 string, arg3 string) ([]byte, error) {
+	fake.loadFileVersionMutex.Lock()
+	ret, specificReturn := fake.loadFileVersionReturnsOnCall[len(fake.loadFileVersionArgsForCall)]
+	fake.loadFileVersionArgsForCall = append(fake.loadFileVersionArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("LoadFileVersion", []interface{}{arg1, arg2, arg3})
+	fake.loadFileVersionMutex.Unlock()
+	if fake.LoadFileVersionStub != nil {
+		return fake.LoadFileVersionStub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.loadFileVersionReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProvider) LoadFileVersionCallCount() int {
+	fake.loadFileVersionMutex.RLock()
+	defer fake.loadFileVersionMutex.RUnlock()
+	return len(fake.loadFileVersionArgsForCall)
+}
+
+func (fake *FakeProvider) LoadFileVersionCalls(stub func(string, string, string) ([]byte, error)) {
+	fake.loadFileVersionMutex.Lock()
+	defer fake.loadFileVersionMutex.Unlock()
+	fake.LoadFileVersionStub = stub
+}
+
+func (fake *FakeProvider) LoadFileVersionArgsForCall(i int) (string, string, string) {
+	fake.loadFileVersionMutex.RLock()
+	defer fake.loadFileVersionMutex.RUnlock()
+	argsForCall := fake.loadFileVersionArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeProvider) LoadFileVersionReturns(result1 []byte, result2 error) {
+	fake.loadFileVersionMutex.Lock()
+	defer fake.loadFileVersionMutex.Unlock()
+	fake.LoadFileVersionStub = nil
+	fake.loadFileVersionReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) LoadFileVersionReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.loadFileVersionMutex.Lock()
+	defer fake.loadFileVersionMutex.Unlock()
+	fake.LoadFileVersionStub = nil
+	if fake.loadFileVersionReturnsOnCall == nil {
+		fake.loadFileVersionReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.loadFileVersionReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeProvider) Region() string {
 	fake.regionMutex.Lock()
 	ret, specificReturn := fake.regionReturnsOnCall[len(fake.regionArgsForCall)]
@@ -1550,34 +2079,48 @@ func (fake *FakeProvider) Invocations() map[string][][]interface{} {
 	defer fake.bucketExistsMutex.RUnlock()
 	fake.checkForWhitelistedIPMutex.RLock()
 	defer fake.checkForWhitelistedIPMutex.RUnlock()
-	fake.chooseMutex.RLock()
-	defer fake.chooseMutex.RUnlock()
 	fake.createBucketMutex.RLock()
 	defer fake.createBucketMutex.RUnlock()
 	fake.createDatabasesMutex.RLock()
 	defer fake.createDatabasesMutex.RUnlock()
+	fake.createFileMutex.RLock()
+	defer fake.createFileMutex.RUnlock()
+	fake.createLockTableMutex.RLock()
+	defer fake.createLockTableMutex.RUnlock()
 	fake.dBTypeMutex.RLock()
 	defer fake.dBTypeMutex.RUnlock()
+	fake.decryptKeyMutex.RLock()
+	defer fake.decryptKeyMutex.RUnlock()
 	fake.deleteFileMutex.RLock()
 	defer fake.deleteFileMutex.RUnlock()
-	fake.deleteVMsInDeploymentMutex.RLock()
-	defer fake.deleteVMsInDeploymentMutex.RUnlock()
-	fake.deleteVMsInVPCMutex.RLock()
-	defer fake.deleteVMsInVPCMutex.RUnlock()
+	fake.deleteLockTableMutex.RLock()
+	defer fake.deleteLockTableMutex.RUnlock()
 	fake.deleteVersionedBucketMutex.RLock()
 	defer fake.deleteVersionedBucketMutex.RUnlock()
-	fake.deleteVolumesMutex.RLock()
-	defer fake.deleteVolumesMutex.RUnlock()
+	fake.enableVersioningMutex.RLock()
+	defer fake.enableVersioningMutex.RUnlock()
+	fake.encryptKeyMutex.RLock()
+	defer fake.encryptKeyMutex.RUnlock()
 	fake.ensureFileExistsMutex.RLock()
 	defer fake.ensureFileExistsMutex.RUnlock()
 	fake.findLongestMatchingHostedZoneMutex.RLock()
 	defer fake.findLongestMatchingHostedZoneMutex.RUnlock()
+	fake.findNetworkMutex.RLock()
+	defer fake.findNetworkMutex.RUnlock()
 	fake.hasFileMutex.RLock()
 	defer fake.hasFileMutex.RUnlock()
 	fake.iAASMutex.RLock()
 	defer fake.iAASMutex.RUnlock()
+	fake.identityMutex.RLock()
+	defer fake.identityMutex.RUnlock()
+	fake.listBucketsMutex.RLock()
+	defer fake.listBucketsMutex.RUnlock()
+	fake.listFileVersionsMutex.RLock()
+	defer fake.listFileVersionsMutex.RUnlock()
 	fake.loadFileMutex.RLock()
 	defer fake.loadFileMutex.RUnlock()
+	fake.loadFileVersionMutex.RLock()
+	defer fake.loadFileVersionMutex.RUnlock()
 	fake.regionMutex.RLock()
 	defer fake.regionMutex.RUnlock()
 	fake.workerTypeMutex.RLock()
@@ -0,0 +1,197 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package iaasfakes
+
+import (
+	"sync"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
+)
+
+type FakeVPCTeardown struct {
+	DeleteVMsInVPCStub        func(string, ...string) ([]string, error)
+	deleteVMsInVPCMutex       sync.RWMutex
+	deleteVMsInVPCArgsForCall []struct {
+		arg1 string
+		arg2 []string
+	}
+	deleteVMsInVPCReturns struct {
+		result1 []string
+		result2 error
+	}
+	deleteVMsInVPCReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
+	DeleteVolumesStub        func([]string, func(ec2Client iaas.IEC2, volumeID *string) error) error
+	deleteVolumesMutex       sync.RWMutex
+	deleteVolumesArgsForCall []struct {
+		arg1 []string
+		arg2 func(ec2Client iaas.IEC2, volumeID *string) error
+	}
+	deleteVolumesReturns struct {
+		result1 error
+	}
+	deleteVolumesReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeVPCTeardown) DeleteVMsInVPC(arg1 string, arg2 ...string) ([]string, error) {
+	fake.deleteVMsInVPCMutex.Lock()
+	ret, specificReturn := fake.deleteVMsInVPCReturnsOnCall[len(fake.deleteVMsInVPCArgsForCall)]
+	fake.deleteVMsInVPCArgsForCall = append(fake.deleteVMsInVPCArgsForCall, struct {
+		arg1 string
+		arg2 []string
+	}{arg1, arg2})
+	fake.recordInvocation("DeleteVMsInVPC", []interface{}{arg1, arg2})
+	fake.deleteVMsInVPCMutex.Unlock()
+	if fake.DeleteVMsInVPCStub != nil {
+		return fake.DeleteVMsInVPCStub(arg1, arg2...)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.deleteVMsInVPCReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeVPCTeardown) DeleteVMsInVPCCallCount() int {
+	fake.deleteVMsInVPCMutex.RLock()
+	defer fake.deleteVMsInVPCMutex.RUnlock()
+	return len(fake.deleteVMsInVPCArgsForCall)
+}
+
+func (fake *FakeVPCTeardown) DeleteVMsInVPCCalls(stub func(string, ...string) ([]string, error)) {
+	fake.deleteVMsInVPCMutex.Lock()
+	defer fake.deleteVMsInVPCMutex.Unlock()
+	fake.DeleteVMsInVPCStub = stub
+}
+
+func (fake *FakeVPCTeardown) DeleteVMsInVPCArgsForCall(i int) (string, []string) {
+	fake.deleteVMsInVPCMutex.RLock()
+	defer fake.deleteVMsInVPCMutex.RUnlock()
+	argsForCall := fake.deleteVMsInVPCArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeVPCTeardown) DeleteVMsInVPCReturns(result1 []string, result2 error) {
+	fake.deleteVMsInVPCMutex.Lock()
+	defer fake.deleteVMsInVPCMutex.Unlock()
+	fake.DeleteVMsInVPCStub = nil
+	fake.deleteVMsInVPCReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVPCTeardown) DeleteVMsInVPCReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.deleteVMsInVPCMutex.Lock()
+	defer fake.deleteVMsInVPCMutex.Unlock()
+	fake.DeleteVMsInVPCStub = nil
+	if fake.deleteVMsInVPCReturnsOnCall == nil {
+		fake.deleteVMsInVPCReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.deleteVMsInVPCReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVPCTeardown) DeleteVolumes(arg1 []string, arg2 func(ec2Client iaas.IEC2, volumeID *string) error) error {
+	var arg1Copy []string
+	if arg1 != nil {
+		arg1Copy = make([]string, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.deleteVolumesMutex.Lock()
+	ret, specificReturn := fake.deleteVolumesReturnsOnCall[len(fake.deleteVolumesArgsForCall)]
+	fake.deleteVolumesArgsForCall = append(fake.deleteVolumesArgsForCall, struct {
+		arg1 []string
+		arg2 func(ec2Client iaas.IEC2, volumeID *string) error
+	}{arg1Copy, arg2})
+	fake.recordInvocation("DeleteVolumes", []interface{}{arg1Copy, arg2})
+	fake.deleteVolumesMutex.Unlock()
+	if fake.DeleteVolumesStub != nil {
+		return fake.DeleteVolumesStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.deleteVolumesReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeVPCTeardown) DeleteVolumesCallCount() int {
+	fake.deleteVolumesMutex.RLock()
+	defer fake.deleteVolumesMutex.RUnlock()
+	return len(fake.deleteVolumesArgsForCall)
+}
+
+func (fake *FakeVPCTeardown) DeleteVolumesCalls(stub func([]string, func(ec2Client iaas.IEC2, volumeID *string) error) error) {
+	fake.deleteVolumesMutex.Lock()
+	defer fake.deleteVolumesMutex.Unlock()
+	fake.DeleteVolumesStub = stub
+}
+
+func (fake *FakeVPCTeardown) DeleteVolumesArgsForCall(i int) ([]string, func(ec2Client iaas.IEC2, volumeID *string) error) {
+	fake.deleteVolumesMutex.RLock()
+	defer fake.deleteVolumesMutex.RUnlock()
+	argsForCall := fake.deleteVolumesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeVPCTeardown) DeleteVolumesReturns(result1 error) {
+	fake.deleteVolumesMutex.Lock()
+	defer fake.deleteVolumesMutex.Unlock()
+	fake.DeleteVolumesStub = nil
+	fake.deleteVolumesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVPCTeardown) DeleteVolumesReturnsOnCall(i int, result1 error) {
+	fake.deleteVolumesMutex.Lock()
+	defer fake.deleteVolumesMutex.Unlock()
+	fake.DeleteVolumesStub = nil
+	if fake.deleteVolumesReturnsOnCall == nil {
+		fake.deleteVolumesReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteVolumesReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeVPCTeardown) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.deleteVMsInVPCMutex.RLock()
+	defer fake.deleteVMsInVPCMutex.RUnlock()
+	fake.deleteVolumesMutex.RLock()
+	defer fake.deleteVolumesMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeVPCTeardown) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ iaas.VPCTeardown = new(FakeVPCTeardown)
@@ -0,0 +1,99 @@
+package iaas
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"golang.org/x/oauth2/google"
+)
+
+const cloudKMSScope = "https://www.googleapis.com/auth/cloudkms"
+
+// EncryptKey encrypts a data key with the AWS KMS key keyID, which may be a key ID, alias or ARN
+func (a *AWSProvider) EncryptKey(keyID string, key []byte) ([]byte, error) {
+	output, err := a.kmsClient(keyID).Encrypt(&kms.EncryptInput{
+		KeyId:     aws.String(keyID),
+		Plaintext: key,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("KMS Encrypt with key %s failed: %s", keyID, err)
+	}
+	return output.CiphertextBlob, nil
+}
+
+// DecryptKey decrypts a data key which was encrypted with the AWS KMS key keyID
+func (a *AWSProvider) DecryptKey(keyID string, wrapped []byte) ([]byte, error) {
+	output, err := a.kmsClient(keyID).Decrypt(&kms.DecryptInput{
+		CiphertextBlob: wrapped,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("KMS Decrypt with key %s failed: %s", keyID, err)
+	}
+	return output.Plaintext, nil
+}
+
+// kmsClient returns a KMS client for the region holding keyID
+func (a *AWSProvider) kmsClient(keyID string) *kms.KMS {
+	region := a.Region()
+	// Keys given by ARN may live in another region
+	if parts := strings.Split(keyID, ":"); len(parts) > 3 && parts[0] == "arn" {
+		region = parts[3]
+	}
+	return kms.New(a.sess, aws.NewConfig().WithRegion(region))
+}
+
+// EncryptKey encrypts a data key with the Cloud KMS key keyID, given as
+// projects/PROJECT/locations/LOCATION/keyRings/RING/cryptoKeys/KEY
+func (g *GCPProvider) EncryptKey(keyID string, key []byte) ([]byte, error) {
+	var output struct {
+		Ciphertext []byte `json:"ciphertext"`
+	}
+	err := g.cloudKMSRequest(keyID, "encrypt", map[string][]byte{"plaintext": key}, &output)
+	return output.Ciphertext, err
+}
+
+// DecryptKey decrypts a data key which was encrypted with the Cloud KMS key keyID
+func (g *GCPProvider) DecryptKey(keyID string, wrapped []byte) ([]byte, error) {
+	var output struct {
+		Plaintext []byte `json:"plaintext"`
+	}
+	err := g.cloudKMSRequest(keyID, "decrypt", map[string][]byte{"ciphertext": wrapped}, &output)
+	return output.Plaintext, err
+}
+
+func (g *GCPProvider) cloudKMSRequest(keyID, action string, input, output interface{}) error {
+	httpClient, err := google.DefaultClient(g.ctx, cloudKMSScope)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+
+	resp, err := httpClient.Post(fmt.Sprintf("https://cloudkms.googleapis.com/v1/%s:%s", keyID, action), "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var kmsErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		json.Unmarshal(respBody, &kmsErr)
+		return fmt.Errorf("Cloud KMS %s with key %s failed: %s %s", action, keyID, resp.Status, kmsErr.Error.Message)
+	}
+	return json.Unmarshal(respBody, output)
+}
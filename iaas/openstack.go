@@ -0,0 +1,600 @@
+package iaas
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// openstackPollInterval is how long to wait between checks on OpenStack operations which finish asynchronously
+var openstackPollInterval = 10 * time.Second
+
+// openstackCredentialsEnv maps the attributes of the OpenStack provider to the environment variables of the user
+// concourse-up runs as, which are the same ones the openstack CLI and terraform read
+var openstackCredentialsEnv = map[string]string{
+	"auth_url":     "OS_AUTH_URL",
+	"username":     "OS_USERNAME",
+	"password":     "OS_PASSWORD",
+	"project_name": "OS_PROJECT_NAME",
+}
+
+// openstackOptionalEnv maps the attributes of the OpenStack provider which have defaults to their environment variables
+var openstackOptionalEnv = map[string][2]string{
+	"user_domain_name":    {"OS_USER_DOMAIN_NAME", "Default"},
+	"project_domain_name": {"OS_PROJECT_DOMAIN_NAME", "Default"},
+	"external_network":    {"OS_EXTERNAL_NETWORK", "public"},
+	"db_image":            {"OS_DB_IMAGE", "ubuntu-18.04"},
+	"web_flavors":         {"OS_WEB_FLAVORS", ""},
+	"worker_flavors":      {"OS_WORKER_FLAVORS", ""},
+	"db_flavors":          {"OS_DB_FLAVORS", ""},
+}
+
+// OpenStackWebFlavors maps user set web sizes to the default OpenStack flavors, which can be changed with $OS_WEB_FLAVORS
+var OpenStackWebFlavors = map[string]string{
+	"small":   "m1.small",
+	"medium":  "m1.medium",
+	"large":   "m1.large",
+	"xlarge":  "m1.xlarge",
+	"2xlarge": "m1.2xlarge",
+}
+
+// OpenStackWorkerFlavors maps user set worker sizes to the default OpenStack flavors, which can be changed with $OS_WORKER_FLAVORS
+var OpenStackWorkerFlavors = map[string]string{
+	"medium":  "m1.medium",
+	"large":   "m1.large",
+	"xlarge":  "m1.xlarge",
+	"2xlarge": "m1.2xlarge",
+	"4xlarge": "m1.4xlarge",
+}
+
+// OpenStackDBFlavors maps user set DB sizes to the default flavors of the Postgres VM, which can be changed with $OS_DB_FLAVORS
+var OpenStackDBFlavors = map[string]string{
+	"small":   "m1.small",
+	"medium":  "m1.medium",
+	"large":   "m1.large",
+	"xlarge":  "m1.xlarge",
+	"2xlarge": "m1.2xlarge",
+	"4xlarge": "m1.4xlarge",
+}
+
+// OpenStackProvider is the concrete implementation of Provider for OpenStack
+type OpenStackProvider struct {
+	region        string
+	attrs         map[string]string
+	webFlavors    map[string]string
+	workerFlavors map[string]string
+	dbFlavors     map[string]string
+	token         string
+	tokenExpiry   time.Time
+	// endpoints holds the public endpoint of each service in the region, by service type
+	endpoints map[string]string
+}
+
+// OpenStack is the Name of OpenStack clouds
+var OpenStack = Register("OPENSTACK", Registration{
+	DefaultRegion: func() string {
+		if region := os.Getenv("OS_REGION_NAME"); region != "" {
+			return region
+		}
+		return "RegionOne"
+	},
+	New:         newOpenStack,
+	GatewayUser: "vcap",
+	Ranges: Ranges{
+		Public:  "10.0.0.0/24",
+		Private: "10.0.1.0/24",
+	},
+	// The terraform Swift backend keeps its state at a fixed path
+	TerraformStatePath: "tfstate.tf",
+	DBNameFormat:       "bosh_%s",
+	SingleZone:         true,
+})
+
+func newOpenStack(region string) (Provider, error) {
+	attrs := make(map[string]string)
+	for attr, env := range openstackCredentialsEnv {
+		value := os.Getenv(env)
+		if value == "" {
+			return nil, fmt.Errorf("%s is not set", env)
+		}
+		attrs[attr] = value
+	}
+	for attr, env := range openstackOptionalEnv {
+		attrs[attr] = env[1]
+		if value := os.Getenv(env[0]); value != "" {
+			attrs[attr] = value
+		}
+	}
+
+	o := &OpenStackProvider{
+		region: region,
+		attrs:  attrs,
+	}
+	var err error
+	if o.webFlavors, err = parseFlavors("OS_WEB_FLAVORS", attrs["web_flavors"], OpenStackWebFlavors); err != nil {
+		return nil, err
+	}
+	if o.workerFlavors, err = parseFlavors("OS_WORKER_FLAVORS", attrs["worker_flavors"], OpenStackWorkerFlavors); err != nil {
+		return nil, err
+	}
+	if o.dbFlavors, err = parseFlavors("OS_DB_FLAVORS", attrs["db_flavors"], OpenStackDBFlavors); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// parseFlavors returns defaults with the flavors set in value, the contents of the environment variable env,
+// which holds comma separated size=flavor pairs such as "xlarge=c4.large,2xlarge=c8.large"
+func parseFlavors(env, value string, defaults map[string]string) (map[string]string, error) {
+	flavors := make(map[string]string)
+	for size, flavor := range defaults {
+		flavors[size] = flavor
+	}
+	if value == "" {
+		return flavors, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, fmt.Errorf("%s must hold size=flavor pairs, not %q", env, pair)
+		}
+		if _, ok := defaults[parts[0]]; !ok {
+			return nil, fmt.Errorf("%s sets the flavor of unknown size %q", env, parts[0])
+		}
+		flavors[parts[0]] = parts[1]
+	}
+	return flavors, nil
+}
+
+// DBType returns the flavor of the Postgres VM
+func (o *OpenStackProvider) DBType(name string) string {
+	return o.dbFlavors[name]
+}
+
+// WebFlavors returns the flavor of each web size
+func (o *OpenStackProvider) WebFlavors() map[string]string {
+	return o.webFlavors
+}
+
+// WorkerFlavors returns the flavor of each worker size
+func (o *OpenStackProvider) WorkerFlavors() map[string]string {
+	return o.workerFlavors
+}
+
+// WorkerType is a nil setter for workerType
+func (o *OpenStackProvider) WorkerType(w string) {}
+
+// Attr returns OpenStack specific attribute
+func (o *OpenStackProvider) Attr(key string) (string, error) {
+	v, ok := o.attrs[key]
+	if !ok {
+		return "", fmt.Errorf("iaas:openstack: key %s not found", key)
+	}
+	return v, nil
+}
+
+// Identity returns the user and project in use
+func (o *OpenStackProvider) Identity() (string, error) {
+	return fmt.Sprintf("%s@%s", o.attrs["username"], o.attrs["project_name"]), nil
+}
+
+// Region returns the region used by the Provider
+func (o *OpenStackProvider) Region() string {
+	return o.region
+}
+
+// Zone returns the availability zone used by the Provider. Availability zones are named by each cloud,
+// so none is used unless one is given
+func (o *OpenStackProvider) Zone(input string) string {
+	return input
+}
+
+// IAAS returns the name of the Provider
+func (o *OpenStackProvider) IAAS() Name {
+	return OpenStack
+}
+
+// CreateDatabases is not used on OpenStack, where the databases are created through the director
+func (o *OpenStackProvider) CreateDatabases(name, username, password string) error {
+	return fmt.Errorf("Not implemented yet")
+}
+
+// FindNetwork is not supported on OpenStack, where concourse-up always creates the network
+func (o *OpenStackProvider) FindNetwork(network string, subnets ...string) (Network, error) {
+	return Network{}, errors.New("deploying into an existing network is not supported on OpenStack")
+}
+
+// CreateLockTable does nothing, as terraform locks its state in Swift with a lock object
+func (o *OpenStackProvider) CreateLockTable(name string) error {
+	return nil
+}
+
+// DeleteLockTable does nothing, as terraform locks its state in Swift with a lock object
+func (o *OpenStackProvider) DeleteLockTable(name string) error {
+	return nil
+}
+
+// EncryptKey is not supported on OpenStack, which has no key management service common to every cloud
+func (o *OpenStackProvider) EncryptKey(keyID string, key []byte) ([]byte, error) {
+	return nil, errors.New("--kms-key is not supported on OPENSTACK, encrypt with the passphrase in $CONCOURSE_UP_PASSPHRASE instead")
+}
+
+// DecryptKey is not supported on OpenStack, which has no key management service common to every cloud
+func (o *OpenStackProvider) DecryptKey(keyID string, wrapped []byte) ([]byte, error) {
+	return nil, errors.New("--kms-key is not supported on OPENSTACK, encrypt with the passphrase in $CONCOURSE_UP_PASSPHRASE instead")
+}
+
+// CheckForWhitelistedIP checks if the specified IP is allowed in by the security group with the ID securityGroup
+func (o *OpenStackProvider) CheckForWhitelistedIP(ip, securityGroup string) (bool, error) {
+	parsedIP := net.ParseIP(ip)
+
+	var group struct {
+		SecurityGroup struct {
+			Rules []struct {
+				Direction      string  `json:"direction"`
+				RemoteIPPrefix *string `json:"remote_ip_prefix"`
+			} `json:"security_group_rules"`
+		} `json:"security_group"`
+	}
+	if err := o.request("network", http.MethodGet, "/v2.0/security-groups/"+securityGroup, nil, nil, &group); err != nil {
+		return false, err
+	}
+
+	for _, rule := range group.SecurityGroup.Rules {
+		// Rules without a prefix allow members of a security group rather than addresses
+		if rule.Direction != "ingress" || rule.RemoteIPPrefix == nil {
+			continue
+		}
+		_, parsedCIDR, err := net.ParseCIDR(*rule.RemoteIPPrefix)
+		if err != nil {
+			continue
+		}
+		if parsedCIDR.Contains(parsedIP) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DeleteVMsInDeployment deletes the VMs BOSH created on the deployment's network, apart from the Postgres VM,
+// and then their ports so that terraform can delete the subnets
+func (o *OpenStackProvider) DeleteVMsInDeployment(zone, project, deployment string) error {
+	serversOnNetwork := func() ([]openstackServer, error) {
+		var page struct {
+			Servers []openstackServer `json:"servers"`
+		}
+		if err := o.request("compute", http.MethodGet, "/servers/detail", nil, nil, &page); err != nil {
+			return nil, err
+		}
+		var servers []openstackServer
+		for _, server := range page.Servers {
+			if _, ok := server.Addresses[deployment]; ok && server.Name != deployment+"-postgres" {
+				servers = append(servers, server)
+			}
+		}
+		return servers, nil
+	}
+
+	servers, err := serversOnNetwork()
+	if err != nil {
+		return err
+	}
+	for _, server := range servers {
+		fmt.Printf("Deleting instance %+v\n", server.Name)
+		if err = o.request("compute", http.MethodDelete, "/servers/"+server.ID, nil, nil, nil); err != nil && !isNotFound(err) {
+			return err
+		}
+	}
+
+	start := time.Now().UTC()
+	for len(servers) > 0 {
+		for _, server := range servers {
+			fmt.Printf("Waiting for instance %s to be deleted\n", server.Name)
+		}
+		if time.Since(start) > time.Minute*10 {
+			return fmt.Errorf("Instances not deleted after 10 minutes")
+		}
+		time.Sleep(openstackPollInterval)
+		if servers, err = serversOnNetwork(); err != nil {
+			return err
+		}
+	}
+
+	var networks struct {
+		Networks []struct {
+			ID string `json:"id"`
+		} `json:"networks"`
+	}
+	if err = o.request("network", http.MethodGet, "/v2.0/networks", url.Values{"name": {deployment}}, nil, &networks); err != nil {
+		return err
+	}
+	for _, network := range networks.Networks {
+		var ports struct {
+			Ports []struct {
+				ID          string `json:"id"`
+				Name        string `json:"name"`
+				DeviceOwner string `json:"device_owner"`
+				DeviceID    string `json:"device_id"`
+			} `json:"ports"`
+		}
+		if err = o.request("network", http.MethodGet, "/v2.0/ports", url.Values{"network_id": {network.ID}}, nil, &ports); err != nil {
+			return err
+		}
+		for _, port := range ports.Ports {
+			// Ports of routers, DHCP agents and the Postgres VM belong to terraform or to neutron itself
+			if port.DeviceOwner != "" || port.DeviceID != "" {
+				continue
+			}
+			fmt.Printf("Deleting port %s\n", port.ID)
+			if err = o.request("network", http.MethodDelete, "/v2.0/ports/"+port.ID, nil, nil, nil); err != nil && !isNotFound(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type openstackServer struct {
+	ID        string                 `json:"id"`
+	Name      string                 `json:"name"`
+	Addresses map[string]interface{} `json:"addresses"`
+}
+
+// FindLongestMatchingHostedZone finds the longest Designate zone that matches the given subdomain,
+// returning its name and ID
+func (o *OpenStackProvider) FindLongestMatchingHostedZone(subdomain string) (string, string, error) {
+	zones, err := o.listZones()
+	if err != nil {
+		return "", "", err
+	}
+
+	var nameFound, idFound string
+	for _, zone := range zones {
+		name := strings.TrimSuffix(zone.Name, ".")
+		if strings.HasSuffix(subdomain, name) && len(name) > len(nameFound) {
+			nameFound = name
+			idFound = zone.ID
+		}
+	}
+	if nameFound == "" {
+		return "", "", fmt.Errorf("dns zone for domain '%s' was not found in Designate", subdomain)
+	}
+	return nameFound, idFound, nil
+}
+
+type openstackZone struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (o *OpenStackProvider) listZones() ([]openstackZone, error) {
+	var zones []openstackZone
+	path := "/v2/zones"
+	for path != "" {
+		var page struct {
+			Zones []openstackZone `json:"zones"`
+			Links struct {
+				Next string `json:"next"`
+			} `json:"links"`
+		}
+		if err := o.request("dns", http.MethodGet, path, nil, nil, &page); err != nil {
+			return nil, err
+		}
+		zones = append(zones, page.Zones...)
+		path = page.Links.Next
+	}
+	return zones, nil
+}
+
+// SetTXTRecord creates or replaces the TXT record fqdn in the longest Designate zone matching it
+func (o *OpenStackProvider) SetTXTRecord(fqdn, value string, ttl int) error {
+	zoneID, recordsetID, err := o.findTXTRecordset(fqdn)
+	if err != nil {
+		return err
+	}
+	recordset := map[string]interface{}{
+		"records": []string{fmt.Sprintf("%q", value)},
+		"ttl":     ttl,
+	}
+	if recordsetID != "" {
+		return o.request("dns", http.MethodPut, "/v2/zones/"+zoneID+"/recordsets/"+recordsetID, nil, recordset, nil)
+	}
+	recordset["name"] = strings.TrimSuffix(fqdn, ".") + "."
+	recordset["type"] = "TXT"
+	return o.request("dns", http.MethodPost, "/v2/zones/"+zoneID+"/recordsets", nil, recordset, nil)
+}
+
+// DeleteTXTRecord deletes the TXT record fqdn, if it exists
+func (o *OpenStackProvider) DeleteTXTRecord(fqdn string) error {
+	zoneID, recordsetID, err := o.findTXTRecordset(fqdn)
+	if err != nil || recordsetID == "" {
+		return err
+	}
+	err = o.request("dns", http.MethodDelete, "/v2/zones/"+zoneID+"/recordsets/"+recordsetID, nil, nil, nil)
+	if isNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// findTXTRecordset returns the ID of the zone of the TXT record fqdn, and the ID of the record or "" if there is none
+func (o *OpenStackProvider) findTXTRecordset(fqdn string) (string, string, error) {
+	fqdn = strings.TrimSuffix(fqdn, ".")
+	_, zoneID, err := o.FindLongestMatchingHostedZone(fqdn)
+	if err != nil {
+		return "", "", err
+	}
+	var recordsets struct {
+		Recordsets []struct {
+			ID string `json:"id"`
+		} `json:"recordsets"`
+	}
+	err = o.request("dns", http.MethodGet, "/v2/zones/"+zoneID+"/recordsets", url.Values{"name": {fqdn + "."}, "type": {"TXT"}}, nil, &recordsets)
+	if err != nil {
+		return "", "", err
+	}
+	if len(recordsets.Recordsets) == 0 {
+		return zoneID, "", nil
+	}
+	return zoneID, recordsets.Recordsets[0].ID, nil
+}
+
+// openstackAPIError is the error returned by an OpenStack API
+type openstackAPIError struct {
+	StatusCode int
+	Status     string
+	Message    string
+}
+
+func (e *openstackAPIError) Error() string {
+	return fmt.Sprintf("%s %s", e.Status, e.Message)
+}
+
+func (e *openstackAPIError) notFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+// request calls the JSON API of an OpenStack service directly, as no OpenStack SDK is vendored.
+// service is the type of the service in the catalog, and path is relative to its endpoint or a full URL.
+// It returns an *openstackAPIError if the API returns an error
+func (o *OpenStackProvider) request(service, method, path string, query url.Values, input, output interface{}) error {
+	var contents []byte
+	headers := map[string]string{"Accept": "application/json"}
+	if input != nil {
+		var err error
+		if contents, err = json.Marshal(input); err != nil {
+			return err
+		}
+		headers["Content-Type"] = "application/json"
+	}
+	body, _, err := o.rawRequest(service, method, path, query, contents, headers)
+	if err != nil {
+		return err
+	}
+	if output == nil || len(body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(body, output)
+}
+
+// rawRequest calls an OpenStack service with the token of the user, and returns the body and headers of the response
+func (o *OpenStackProvider) rawRequest(service, method, path string, query url.Values, contents []byte, headers map[string]string) ([]byte, http.Header, error) {
+	if err := o.authenticate(); err != nil {
+		return nil, nil, err
+	}
+
+	u := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		endpoint, ok := o.endpoints[service]
+		if !ok {
+			return nil, nil, fmt.Errorf("the %s service has no public endpoint in region %s", service, o.region)
+		}
+		u = strings.TrimSuffix(endpoint, "/") + path
+	}
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(method, u, bytes.NewReader(contents))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("X-Auth-Token", o.token)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, nil, &openstackAPIError{StatusCode: resp.StatusCode, Status: resp.Status, Message: strings.TrimSpace(string(body))}
+	}
+	return body, resp.Header, nil
+}
+
+// authenticate gets a token for the user scoped to the project from Keystone, and the endpoints of the services
+// in the region, unless it has a token which is still valid
+func (o *OpenStackProvider) authenticate() error {
+	if o.token != "" && time.Now().Add(time.Minute).Before(o.tokenExpiry) {
+		return nil
+	}
+
+	auth := map[string]interface{}{
+		"auth": map[string]interface{}{
+			"identity": map[string]interface{}{
+				"methods": []string{"password"},
+				"password": map[string]interface{}{
+					"user": map[string]interface{}{
+						"name":     o.attrs["username"],
+						"password": o.attrs["password"],
+						"domain":   map[string]string{"name": o.attrs["user_domain_name"]},
+					},
+				},
+			},
+			"scope": map[string]interface{}{
+				"project": map[string]interface{}{
+					"name":   o.attrs["project_name"],
+					"domain": map[string]string{"name": o.attrs["project_domain_name"]},
+				},
+			},
+		},
+	}
+	body, err := json.Marshal(auth)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.Post(strings.TrimSuffix(o.attrs["auth_url"], "/")+"/auth/tokens", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		message, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("failed to authenticate with OpenStack: %s %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+
+	var token struct {
+		Token struct {
+			ExpiresAt time.Time `json:"expires_at"`
+			Catalog   []struct {
+				Type      string `json:"type"`
+				Endpoints []struct {
+					Interface string `json:"interface"`
+					Region    string `json:"region"`
+					RegionID  string `json:"region_id"`
+					URL       string `json:"url"`
+				} `json:"endpoints"`
+			} `json:"catalog"`
+		} `json:"token"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return err
+	}
+
+	o.endpoints = make(map[string]string)
+	for _, service := range token.Token.Catalog {
+		for _, endpoint := range service.Endpoints {
+			if endpoint.Interface == "public" && (endpoint.Region == o.region || endpoint.RegionID == o.region) {
+				o.endpoints[service.Type] = endpoint.URL
+			}
+		}
+	}
+	o.token = resp.Header.Get("X-Subject-Token")
+	o.tokenExpiry = token.Token.ExpiresAt
+	return nil
+}
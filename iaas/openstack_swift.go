@@ -0,0 +1,268 @@
+package iaas
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// swiftArchive is the name of the container which keeps the old versions of the objects in bucket
+func swiftArchive(bucket string) string {
+	return bucket + "-versions"
+}
+
+// CreateBucket creates a Swift container
+func (o *OpenStackProvider) CreateBucket(name string) error {
+	_, _, err := o.rawRequest("object-store", http.MethodPut, "/"+name, nil, nil, nil)
+	return err
+}
+
+// BucketExists checks if the named container exists
+func (o *OpenStackProvider) BucketExists(name string) (bool, error) {
+	_, _, err := o.rawRequest("object-store", http.MethodHead, "/"+name, nil, nil, nil)
+	if isNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ListBuckets returns the names of all the containers in the project
+func (o *OpenStackProvider) ListBuckets() ([]string, error) {
+	return o.listSwift("/")
+}
+
+// listObjects returns the objects in a container whose names start with prefix
+func (o *OpenStackProvider) listObjects(bucket, prefix string) ([]swiftObject, error) {
+	var objects []swiftObject
+	marker := ""
+	for {
+		query := url.Values{"format": {"json"}, "prefix": {prefix}}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		body, _, err := o.rawRequest("object-store", http.MethodGet, "/"+bucket, query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page []swiftObject
+		if err = json.Unmarshal(body, &page); err != nil {
+			return nil, err
+		}
+		if len(page) == 0 {
+			return objects, nil
+		}
+		objects = append(objects, page...)
+		marker = page[len(page)-1].Name
+	}
+}
+
+// listSwift returns the names of the containers of the account at path "/", or of the objects in a container
+func (o *OpenStackProvider) listSwift(path string) ([]string, error) {
+	var names []string
+	marker := ""
+	for {
+		query := url.Values{"format": {"json"}}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		body, _, err := o.rawRequest("object-store", http.MethodGet, path, query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page []struct {
+			Name string `json:"name"`
+		}
+		if err = json.Unmarshal(body, &page); err != nil {
+			return nil, err
+		}
+		if len(page) == 0 {
+			return names, nil
+		}
+		for _, entry := range page {
+			names = append(names, entry.Name)
+		}
+		marker = page[len(page)-1].Name
+	}
+}
+
+type swiftObject struct {
+	Name         string `json:"name"`
+	Bytes        int64  `json:"bytes"`
+	LastModified string `json:"last_modified"`
+}
+
+// DeleteVersionedBucket deletes a container and the container of its old versions.
+// Swift only deletes empty containers, so their objects are deleted first
+func (o *OpenStackProvider) DeleteVersionedBucket(name string) error {
+	for _, container := range []string{name, swiftArchive(name)} {
+		objects, err := o.listSwift("/" + container)
+		if isNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for _, object := range objects {
+			if err = o.deleteObject(container, object); err != nil {
+				return err
+			}
+		}
+		// Deleting the objects of the bucket archives them, so the archive is emptied after the bucket
+		if err = o.deleteObject(container, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteObject deletes an object, or a container when path is "", ignoring ones which are already gone
+func (o *OpenStackProvider) deleteObject(bucket, path string) error {
+	resource := "/" + bucket
+	if path != "" {
+		resource += "/" + path
+	}
+	_, _, err := o.rawRequest("object-store", http.MethodDelete, resource, nil, nil, nil)
+	if isNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// HasFile returns true if the specified object exists
+func (o *OpenStackProvider) HasFile(bucket, path string) (bool, error) {
+	_, _, err := o.rawRequest("object-store", http.MethodHead, "/"+bucket+"/"+path, nil, nil, nil)
+	if isNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// LoadFile loads an object from a container
+func (o *OpenStackProvider) LoadFile(bucket, path string) ([]byte, error) {
+	body, _, err := o.rawRequest("object-store", http.MethodGet, "/"+bucket+"/"+path, nil, nil, nil)
+	return body, err
+}
+
+// WriteFile writes the specified object to a container
+func (o *OpenStackProvider) WriteFile(bucket, path string, contents []byte) error {
+	_, _, err := o.rawRequest("object-store", http.MethodPut, "/"+bucket+"/"+path, nil, contents, nil)
+	return err
+}
+
+// CreateFile writes an object to a container, unless it exists already
+func (o *OpenStackProvider) CreateFile(bucket, path string, contents []byte) error {
+	_, _, err := o.rawRequest("object-store", http.MethodPut, "/"+bucket+"/"+path, nil, contents, map[string]string{
+		"If-None-Match": "*",
+	})
+	if apiErr, ok := err.(*openstackAPIError); ok && apiErr.StatusCode == http.StatusPreconditionFailed {
+		return ErrFileExists
+	}
+	return err
+}
+
+// DeleteFile deletes an object from a container
+func (o *OpenStackProvider) DeleteFile(bucket, path string) error {
+	_, _, err := o.rawRequest("object-store", http.MethodDelete, "/"+bucket+"/"+path, nil, nil, nil)
+	return err
+}
+
+// EnsureFileExists checks for the named object and creates it if it doesn't exist
+// Second argument is true if new file was created
+func (o *OpenStackProvider) EnsureFileExists(bucket, path string, defaultContents []byte) ([]byte, bool, error) {
+	contents, err := o.LoadFile(bucket, path)
+	if err == nil {
+		return contents, false, nil
+	}
+	if !isNotFound(err) {
+		return nil, false, err
+	}
+
+	if err = o.WriteFile(bucket, path, defaultContents); err != nil {
+		return nil, false, err
+	}
+	return defaultContents, true, nil
+}
+
+// swiftArchivePrefix is the prefix Swift gives the archived versions of the object path, followed by their timestamps
+func swiftArchivePrefix(path string) string {
+	return fmt.Sprintf("%03x%s/", len(path), path)
+}
+
+// EnableVersioning creates a container to archive old versions in and has Swift keep every version of the objects
+// in bucket there
+func (o *OpenStackProvider) EnableVersioning(bucket string) error {
+	if err := o.CreateBucket(swiftArchive(bucket)); err != nil {
+		return err
+	}
+	_, _, err := o.rawRequest("object-store", http.MethodPost, "/"+bucket, nil, nil, map[string]string{
+		"X-History-Location": swiftArchive(bucket),
+	})
+	return err
+}
+
+// ListFileVersions returns the versions of an object, newest first. Versions are identified by their timestamps
+func (o *OpenStackProvider) ListFileVersions(bucket, path string) ([]FileVersion, error) {
+	var versions []FileVersion
+	_, headers, err := o.rawRequest("object-store", http.MethodHead, "/"+bucket+"/"+path, nil, nil, nil)
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		modified, err := http.ParseTime(headers.Get("Last-Modified"))
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.ParseInt(headers.Get("Content-Length"), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, FileVersion{
+			ID:       headers.Get("X-Timestamp"),
+			Modified: modified,
+			Size:     size,
+			Latest:   true,
+		})
+	}
+
+	prefix := swiftArchivePrefix(path)
+	archived, err := o.listObjects(swiftArchive(bucket), prefix)
+	if isNotFound(err) {
+		return sortVersions(versions), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, object := range archived {
+		modified, err := time.Parse("2006-01-02T15:04:05.999999", object.LastModified)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, FileVersion{
+			ID:       object.Name[len(prefix):],
+			Modified: modified,
+			Size:     object.Bytes,
+		})
+	}
+	return sortVersions(versions), nil
+}
+
+// LoadFileVersion loads a version of an object, which is either the current object or one in the archive
+func (o *OpenStackProvider) LoadFileVersion(bucket, path, versionID string) ([]byte, error) {
+	_, headers, err := o.rawRequest("object-store", http.MethodHead, "/"+bucket+"/"+path, nil, nil, nil)
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+	if err == nil && headers.Get("X-Timestamp") == versionID {
+		return o.LoadFile(bucket, path)
+	}
+	return o.LoadFile(swiftArchive(bucket), swiftArchivePrefix(path)+versionID)
+}
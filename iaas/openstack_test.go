@@ -0,0 +1,196 @@
+package iaas
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeOpenStack serves Keystone and the OpenStack services concourse-up calls from one server, with the responses given by path
+func fakeOpenStack(t *testing.T, responses map[string]func(w http.ResponseWriter, r *http.Request)) (*OpenStackProvider, *httptest.Server) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v3/auth/tokens" {
+			w.Header().Set("X-Subject-Token", "fake-token")
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"token": {"expires_at": "%s", "catalog": [
+				{"type": "network", "endpoints": [
+					{"interface": "public", "region": "RegionOne", "url": "%[2]s/network"},
+					{"interface": "public", "region": "RegionTwo", "url": "%[2]s/elsewhere"}
+				]},
+				{"type": "dns", "endpoints": [{"interface": "public", "region": "RegionOne", "url": "%[2]s/dns"}]},
+				{"type": "object-store", "endpoints": [{"interface": "public", "region": "RegionOne", "url": "%[2]s/swift"}]}
+			]}}`, time.Now().Add(time.Hour).Format(time.RFC3339), server.URL)
+			return
+		}
+		if r.Header.Get("X-Auth-Token") != "fake-token" {
+			t.Errorf("request was not authorised: %q", r.Header.Get("X-Auth-Token"))
+		}
+		respond, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		respond(w, r)
+	}))
+
+	o := &OpenStackProvider{
+		region: "RegionOne",
+		attrs: map[string]string{
+			"auth_url":            server.URL + "/v3",
+			"username":            "user",
+			"password":            "password",
+			"project_name":        "project",
+			"user_domain_name":    "Default",
+			"project_domain_name": "Default",
+		},
+	}
+	return o, server
+}
+
+func respondWith(body string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}
+}
+
+func TestOpenStackProvider_CheckForWhitelistedIP(t *testing.T) {
+	o, server := fakeOpenStack(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"/network/v2.0/security-groups/director": respondWith(`{"security_group": {"security_group_rules": [
+			{"direction": "egress", "remote_ip_prefix": "0.0.0.0/0"},
+			{"direction": "ingress", "remote_ip_prefix": null},
+			{"direction": "ingress", "remote_ip_prefix": "1.2.3.4/32"},
+			{"direction": "ingress", "remote_ip_prefix": "5.6.7.0/24"}
+		]}}`),
+	})
+	defer server.Close()
+
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{ip: "1.2.3.4", want: true},
+		{ip: "5.6.7.8", want: true},
+		{ip: "9.9.9.9", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			got, err := o.CheckForWhitelistedIP(tt.ip, "director")
+			if err != nil {
+				t.Fatalf("CheckForWhitelistedIP() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CheckForWhitelistedIP() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := o.CheckForWhitelistedIP("1.2.3.4", "missing"); !isNotFound(err) {
+		t.Errorf("CheckForWhitelistedIP() error = %v, want not found", err)
+	}
+}
+
+func TestOpenStackProvider_FindLongestMatchingHostedZone(t *testing.T) {
+	o, server := fakeOpenStack(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"/dns/v2/zones": respondWith(`{"zones": [
+			{"id": "zone-1", "name": "example.com."},
+			{"id": "zone-2", "name": "ci.example.com."}
+		], "links": {}}`),
+	})
+	defer server.Close()
+
+	name, id, err := o.FindLongestMatchingHostedZone("concourse.ci.example.com")
+	if err != nil {
+		t.Fatalf("FindLongestMatchingHostedZone() error = %v", err)
+	}
+	if name != "ci.example.com" || id != "zone-2" {
+		t.Errorf("FindLongestMatchingHostedZone() = %v, %v, want ci.example.com, zone-2", name, id)
+	}
+	if _, _, err = o.FindLongestMatchingHostedZone("example.org"); err == nil {
+		t.Error("FindLongestMatchingHostedZone() did not return an error for a domain in no zone")
+	}
+}
+
+func TestOpenStackProvider_ListFileVersions(t *testing.T) {
+	o, server := fakeOpenStack(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"/swift/bucket/config.json": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Last-Modified", "Wed, 01 Jan 2020 12:00:00 GMT")
+			w.Header().Set("Content-Length", "3")
+			w.Header().Set("X-Timestamp", "1577880000.00000")
+		},
+		"/swift/bucket-versions": func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("prefix") != "00bconfig.json/" {
+				t.Errorf("archive was listed with prefix %q", r.URL.Query().Get("prefix"))
+			}
+			if r.URL.Query().Get("marker") != "" {
+				fmt.Fprint(w, `[]`)
+				return
+			}
+			fmt.Fprint(w, `[{"name": "00bconfig.json/1577793600.00000", "bytes": 2, "last_modified": "2019-12-31T12:00:00.000000"}]`)
+		},
+	})
+	defer server.Close()
+
+	got, err := o.ListFileVersions("bucket", "config.json")
+	if err != nil {
+		t.Fatalf("ListFileVersions() error = %v", err)
+	}
+	want := []FileVersion{
+		{ID: "1577880000.00000", Modified: time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC), Size: 3, Latest: true},
+		{ID: "1577793600.00000", Modified: time.Date(2019, 12, 31, 12, 0, 0, 0, time.UTC), Size: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListFileVersions() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_parseFlavors(t *testing.T) {
+	got, err := parseFlavors("OS_WORKER_FLAVORS", "xlarge=c4.large, 2xlarge=c8.large", OpenStackWorkerFlavors)
+	if err != nil {
+		t.Fatalf("parseFlavors() error = %v", err)
+	}
+	if got["xlarge"] != "c4.large" || got["2xlarge"] != "c8.large" || got["medium"] != OpenStackWorkerFlavors["medium"] {
+		t.Errorf("parseFlavors() = %v", got)
+	}
+	if OpenStackWorkerFlavors["xlarge"] != "m1.xlarge" {
+		t.Error("parseFlavors() changed the defaults")
+	}
+
+	for _, value := range []string{"xlarge", "xlarge=", "tiny=m1.tiny"} {
+		if _, err = parseFlavors("OS_WORKER_FLAVORS", value, OpenStackWorkerFlavors); err == nil {
+			t.Errorf("parseFlavors() did not return an error for %q", value)
+		}
+	}
+}
+
+func TestOpenStackProvider_CreateFile(t *testing.T) {
+	var stored []byte
+	o, server := fakeOpenStack(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"/swift/bucket/deployment.lock": func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("If-None-Match") != "*" {
+				t.Errorf("object was written with If-None-Match %q, want *", r.Header.Get("If-None-Match"))
+			}
+			if stored != nil {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			stored, _ = ioutil.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+		},
+	})
+	defer server.Close()
+
+	if err := o.CreateFile("bucket", "deployment.lock", []byte("mine")); err != nil {
+		t.Fatalf("CreateFile() error = %v", err)
+	}
+	if err := o.CreateFile("bucket", "deployment.lock", []byte("theirs")); err != ErrFileExists {
+		t.Errorf("CreateFile() of an existing object error = %v, want ErrFileExists", err)
+	}
+	if string(stored) != "mine" {
+		t.Errorf("object contents = %q, want the first write", stored)
+	}
+}
@@ -3,10 +3,13 @@ package iaas
 import (
 	"bytes"
 	"io/ioutil"
+	"net/http"
 
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 )
 
@@ -28,6 +31,9 @@ const (
 
 	// Returned when calling HEAD on non-existant bucket or object
 	awsErrCodeNotFound = "NotFound"
+
+	// Returned when a bucket is accessed from a region other than its own
+	awsErrCodeBucketRegionError = "BucketRegionError"
 )
 
 // DeleteVersionedBucket deletes and empties a versioned bucket
@@ -35,11 +41,16 @@ func (client *AWSProvider) DeleteVersionedBucket(name string) error {
 
 	s3Client := s3.New(client.sess)
 
-	// Delete all objects
-	objects := []*s3.Object{}
-	err := s3Client.ListObjectsPages(&s3.ListObjectsInput{Bucket: &name},
-		func(output *s3.ListObjectsOutput, _ bool) bool {
-			objects = append(objects, output.Contents...)
+	// Delete every version of every object, as a versioned bucket cannot be deleted until they are gone
+	objects := []*s3.ObjectIdentifier{}
+	err := s3Client.ListObjectVersionsPages(&s3.ListObjectVersionsInput{Bucket: &name},
+		func(output *s3.ListObjectVersionsOutput, _ bool) bool {
+			for _, version := range output.Versions {
+				objects = append(objects, &s3.ObjectIdentifier{Key: version.Key, VersionId: version.VersionId})
+			}
+			for _, marker := range output.DeleteMarkers {
+				objects = append(objects, &s3.ObjectIdentifier{Key: marker.Key, VersionId: marker.VersionId})
+			}
 
 			return true
 		})
@@ -49,8 +60,9 @@ func (client *AWSProvider) DeleteVersionedBucket(name string) error {
 
 	for _, object := range objects {
 		_, err = s3Client.DeleteObject(&s3.DeleteObjectInput{
-			Bucket: &name,
-			Key:    object.Key,
+			Bucket:    &name,
+			Key:       object.Key,
+			VersionId: object.VersionId,
 		})
 		if err != nil {
 			return nil
@@ -63,7 +75,7 @@ func (client *AWSProvider) DeleteVersionedBucket(name string) error {
 	return err
 }
 
-// CreateBucket checks if the named bucket exists and creates it if it doesn't
+// CreateBucket checks if the named bucket exists and creates it with versioning enabled if it doesn't
 func (client *AWSProvider) CreateBucket(name string) error {
 
 	s3Client := s3.New(client.sess)
@@ -80,7 +92,12 @@ func (client *AWSProvider) CreateBucket(name string) error {
 	}
 
 	_, err := s3Client.CreateBucket(bucketInput)
-	return err
+	if err != nil {
+		return err
+	}
+
+	// Old versions of the config and state can be listed and restored with `config history`
+	return client.EnableVersioning(name)
 }
 
 // BucketExists checks if the named bucket exists
@@ -113,6 +130,30 @@ func (client *AWSProvider) WriteFile(bucket, path string, contents []byte) error
 	return err
 }
 
+// CreateFile writes the specified S3 object, unless it exists already
+func (client *AWSProvider) CreateFile(bucket, path string, contents []byte) error {
+	return CreateS3Object(s3.New(client.sess), bucket, path, contents)
+}
+
+// CreateS3Object writes an object with s3Client only if there is no object at path, returning ErrFileExists
+// otherwise. The pinned SDK predates conditional writes, so the If-None-Match header is set on the request itself
+func CreateS3Object(s3Client *s3.S3, bucket, path string, contents []byte) error {
+	req, _ := s3Client.PutObjectRequest(&s3.PutObjectInput{
+		Bucket: &bucket,
+		Key:    &path,
+		Body:   bytes.NewReader(contents),
+	})
+	req.HTTPRequest.Header.Set("If-None-Match", "*")
+	err := req.Send()
+	if reqErr, ok := err.(awserr.RequestFailure); ok {
+		// S3 answers 409 when another conditional write of the object is in progress
+		if reqErr.StatusCode() == http.StatusPreconditionFailed || reqErr.StatusCode() == http.StatusConflict {
+			return ErrFileExists
+		}
+	}
+	return err
+}
+
 // HasFile returns true if the specified S3 object exists
 func (client *AWSProvider) HasFile(bucket, path string) (bool, error) {
 	s3Client := s3.New(client.sess)
@@ -174,6 +215,14 @@ func (client *AWSProvider) LoadFile(bucket, path string) ([]byte, error) {
 	s3Client := s3.New(client.sess)
 
 	output, err := s3Client.GetObject(&s3.GetObjectInput{Bucket: &bucket, Key: &path})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == awsErrCodeBucketRegionError {
+		// Buckets listed by ListBuckets may live in any region
+		s3Client, err = client.s3ClientForBucket(bucket)
+		if err != nil {
+			return nil, err
+		}
+		output, err = s3Client.GetObject(&s3.GetObjectInput{Bucket: &bucket, Key: &path})
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -181,6 +230,37 @@ func (client *AWSProvider) LoadFile(bucket, path string) ([]byte, error) {
 	return ioutil.ReadAll(output.Body)
 }
 
+// ListBuckets returns the names of all the S3 buckets owned by the account, in every region
+func (client *AWSProvider) ListBuckets() ([]string, error) {
+	s3Client := s3.New(client.sess)
+
+	output, err := s3Client.ListBuckets(&s3.ListBucketsInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, bucket := range output.Buckets {
+		names = append(names, aws.StringValue(bucket.Name))
+	}
+	return names, nil
+}
+
+func (client *AWSProvider) s3ClientForBucket(bucket string) (*s3.S3, error) {
+	output, err := s3.New(client.sess).GetBucketLocationWithContext(aws.BackgroundContext(), &s3.GetBucketLocationInput{Bucket: &bucket}, s3.WithNormalizeBucketLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	sess, err := session.NewSession(client.sess.Config.Copy(&aws.Config{
+		Region: output.LocationConstraint,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return s3.New(sess), nil
+}
+
 // DeleteFile deletes a file from S3
 func (client *AWSProvider) DeleteFile(bucket, path string) error {
 
@@ -0,0 +1,131 @@
+package iaas
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"google.golang.org/api/iterator"
+)
+
+// FileVersion is a version of a file in a versioned bucket
+type FileVersion struct {
+	ID       string
+	Modified time.Time
+	Size     int64
+	Latest   bool
+}
+
+func sortVersions(versions []FileVersion) []FileVersion {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].Modified.After(versions[j].Modified)
+	})
+	return versions
+}
+
+// EnableVersioning turns on object versioning for an S3 bucket
+func (client *AWSProvider) EnableVersioning(bucket string) error {
+	s3Client := s3.New(client.sess)
+
+	_, err := s3Client.PutBucketVersioning(&s3.PutBucketVersioningInput{
+		Bucket: &bucket,
+		VersioningConfiguration: &s3.VersioningConfiguration{
+			Status: aws.String(s3.BucketVersioningStatusEnabled),
+		},
+	})
+	return err
+}
+
+// ListFileVersions returns the versions of an S3 object, newest first
+func (client *AWSProvider) ListFileVersions(bucket, path string) ([]FileVersion, error) {
+	s3Client := s3.New(client.sess)
+
+	var versions []FileVersion
+	err := s3Client.ListObjectVersionsPages(&s3.ListObjectVersionsInput{Bucket: &bucket, Prefix: &path},
+		func(output *s3.ListObjectVersionsOutput, _ bool) bool {
+			for _, v := range output.Versions {
+				if aws.StringValue(v.Key) != path {
+					continue
+				}
+				versions = append(versions, FileVersion{
+					ID:       aws.StringValue(v.VersionId),
+					Modified: aws.TimeValue(v.LastModified),
+					Size:     aws.Int64Value(v.Size),
+					Latest:   aws.BoolValue(v.IsLatest),
+				})
+			}
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return sortVersions(versions), nil
+}
+
+// LoadFileVersion loads a version of an S3 object
+func (client *AWSProvider) LoadFileVersion(bucket, path, versionID string) ([]byte, error) {
+	s3Client := s3.New(client.sess)
+
+	output, err := s3Client.GetObject(&s3.GetObjectInput{Bucket: &bucket, Key: &path, VersionId: &versionID})
+	if err != nil {
+		return nil, err
+	}
+	defer output.Body.Close()
+
+	return ioutil.ReadAll(output.Body)
+}
+
+// EnableVersioning turns on object versioning for a GCP bucket
+func (g *GCPProvider) EnableVersioning(bucket string) error {
+	_, err := g.storage.Bucket(bucket).Update(g.ctx, storage.BucketAttrsToUpdate{VersioningEnabled: true})
+	return err
+}
+
+// ListFileVersions returns the generations of a GCP object, newest first
+func (g *GCPProvider) ListFileVersions(bucket, path string) ([]FileVersion, error) {
+	var versions []FileVersion
+	it := g.storage.Bucket(bucket).Objects(g.ctx, &storage.Query{Prefix: path, Versions: true})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if attrs.Name != path {
+			continue
+		}
+		versions = append(versions, FileVersion{
+			ID:       strconv.FormatInt(attrs.Generation, 10),
+			Modified: attrs.Created,
+			Size:     attrs.Size,
+			// Generations which have been replaced have the time they were replaced as their deletion time
+			Latest: attrs.Deleted.IsZero(),
+		})
+	}
+
+	return sortVersions(versions), nil
+}
+
+// LoadFileVersion loads a generation of a GCP object
+func (g *GCPProvider) LoadFileVersion(bucket, path, versionID string) ([]byte, error) {
+	generation, err := strconv.ParseInt(versionID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GCS object generation %q", versionID)
+	}
+
+	rc, err := g.storage.Bucket(bucket).Object(path).Generation(generation).NewReader(g.ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return ioutil.ReadAll(rc)
+}
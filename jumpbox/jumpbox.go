@@ -0,0 +1,167 @@
+// Package jumpbox reaches the VMs of private deployments, which have no public IPs,
+// by tunnelling connections through the jumpbox terraform creates in the public subnet
+package jumpbox
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/terraform"
+	"golang.org/x/crypto/ssh"
+)
+
+// User is the user the jumpbox accepts SSH connections for
+const User = "ubuntu"
+
+// Tunnel makes connections from the jumpbox. The bosh CLI opens its own tunnel from AllProxy, while
+// fly, which cannot, uses an HTTP CONNECT proxy listening on localhost.
+// The SSH connection to the jumpbox is only opened when the tunnel is first used
+type Tunnel struct {
+	addr    string
+	l       net.Listener
+	dial    func() (*ssh.Client, error)
+	forward func(network, addr string) (net.Conn, error)
+	once    sync.Once
+	client  *ssh.Client
+	dialErr error
+}
+
+// Open returns a Tunnel through the jumpbox of a private deployment, or nil for a public one
+func Open(conf config.Config, outputs terraform.Outputs) (*Tunnel, error) {
+	if !conf.Private {
+		return nil, nil
+	}
+	jumpboxPublicIP, err := outputs.Get("JumpboxPublicIP")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get JumpboxPublicIP from terraform outputs: [%v]", err)
+	}
+	return New(jumpboxPublicIP, conf.PrivateKey)
+}
+
+// New returns a Tunnel through the jumpbox at addr, authenticating with privateKey
+func New(addr, privateKey string) (*Tunnel, error) {
+	key, err := ssh.ParsePrivateKey([]byte(privateKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key for jumpbox: [%v]", err)
+	}
+	conf := &ssh.ClientConfig{
+		User:            User,
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(key)},
+	}
+	return newTunnel(addr, func() (*ssh.Client, error) {
+		client, err := ssh.Dial("tcp", net.JoinHostPort(addr, "22"), conf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to jumpbox: [%v]", err)
+		}
+		return client, nil
+	}, nil)
+}
+
+// newTunnel starts the proxy. forward is used instead of the SSH connection when it is not nil
+func newTunnel(addr string, dial func() (*ssh.Client, error), forward func(network, addr string) (net.Conn, error)) (*Tunnel, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	t := &Tunnel{addr: addr, l: l, dial: dial, forward: forward}
+	go http.Serve(l, http.HandlerFunc(t.serveConnect))
+	return t, nil
+}
+
+// AllProxy returns the BOSH_ALL_PROXY which makes the bosh CLI tunnel through the jumpbox at addr,
+// authenticating with the private key in keyPath
+func AllProxy(addr, keyPath string) string {
+	return fmt.Sprintf("ssh+socks5://%s@%s:22?private-key=%s", User, addr, keyPath)
+}
+
+// AllProxy returns the BOSH_ALL_PROXY for this tunnel's jumpbox
+func (t *Tunnel) AllProxy(keyPath string) string {
+	return AllProxy(t.addr, keyPath)
+}
+
+// Dial connects to addr from the jumpbox
+func (t *Tunnel) Dial(network, addr string) (net.Conn, error) {
+	if t.forward != nil {
+		return t.forward(network, addr)
+	}
+	t.once.Do(func() {
+		t.client, t.dialErr = t.dial()
+	})
+	if t.dialErr != nil {
+		return nil, t.dialErr
+	}
+	return t.client.Dial(network, addr)
+}
+
+// URL returns the address of the proxy, in the form accepted by HTTPS_PROXY.
+// It is empty for a nil Tunnel, so that connections are made directly
+func (t *Tunnel) URL() string {
+	if t == nil {
+		return ""
+	}
+	return "http://" + t.l.Addr().String()
+}
+
+// Close stops the proxy and closes the connection to the jumpbox. It is safe to call on a nil Tunnel
+func (t *Tunnel) Close() error {
+	if t == nil {
+		return nil
+	}
+	err := t.l.Close()
+	// Prevent a later Dial from connecting once the tunnel is closed
+	t.once.Do(func() {
+		t.dialErr = errors.New("jumpbox tunnel is closed")
+	})
+	if t.client != nil {
+		t.client.Close()
+	}
+	return err
+}
+
+// serveConnect answers CONNECT requests, which is all an HTTPS_PROXY is sent, by copying the
+// connection to and from the requested address
+func (t *Tunnel) serveConnect(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodConnect {
+		http.Error(w, "only CONNECT is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	target, err := t.Dial("tcp", r.Host)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer target.Close()
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "connection cannot be hijacked", http.StatusInternalServerError)
+		return
+	}
+	conn, buffered, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	if _, err = conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n")); err != nil {
+		return
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		io.Copy(conn, target)
+		wg.Done()
+	}()
+	go func(client *bufio.Reader) {
+		io.Copy(target, client)
+		wg.Done()
+	}(buffered.Reader)
+	wg.Wait()
+}
@@ -0,0 +1,137 @@
+package jumpbox
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// connect opens a connection to addr through the proxy at proxyURL, returning the proxy's response
+func connect(t *testing.T, proxyURL, addr string) (net.Conn, *bufio.Reader, *http.Response) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.Dial("tcp", u.Host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = conn.Write([]byte("CONNECT " + addr + " HTTP/1.1\r\nHost: " + addr + "\r\n\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, &http.Request{Method: http.MethodConnect})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn, r, resp
+}
+
+func TestTunnel_ForwardsConnections(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		conn.Write([]byte("echo " + line))
+	}()
+
+	var dialled []string
+	tunnel, err := newTunnel("1.2.3.4", nil, func(network, addr string) (net.Conn, error) {
+		dialled = append(dialled, addr)
+		return net.Dial(network, addr)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tunnel.Close()
+
+	u, err := url.Parse(tunnel.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Scheme != "http" {
+		t.Errorf("URL() scheme = %q, want http", u.Scheme)
+	}
+	conn, r, resp := connect(t, tunnel.URL(), l.Addr().String())
+	defer conn.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("CONNECT through the tunnel returned %s", resp.Status)
+	}
+
+	if _, err = conn.Write([]byte("hello\n")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "echo hello\n" {
+		t.Errorf("read %q through the tunnel, want %q", got, "echo hello\n")
+	}
+	if len(dialled) != 1 || dialled[0] != l.Addr().String() {
+		t.Errorf("tunnel dialled %v, want [%s]", dialled, l.Addr())
+	}
+}
+
+func TestTunnel_ReportsFailedConnections(t *testing.T) {
+	tunnel, err := newTunnel("1.2.3.4", nil, func(network, addr string) (net.Conn, error) {
+		return nil, errors.New("connection refused")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tunnel.Close()
+
+	conn, _, resp := connect(t, tunnel.URL(), "10.0.1.6:25555")
+	defer conn.Close()
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("CONNECT through the tunnel returned %s, want %d", resp.Status, http.StatusBadGateway)
+	}
+}
+
+func TestTunnel_DialsJumpboxOnce(t *testing.T) {
+	var calls int
+	tunnel, err := newTunnel("1.2.3.4", func() (*ssh.Client, error) {
+		calls++
+		return nil, errors.New("failed to connect to jumpbox")
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tunnel.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := tunnel.Dial("tcp", "10.0.1.6:22"); err == nil || err.Error() != "failed to connect to jumpbox" {
+			t.Errorf("Dial() error = %v, want the error connecting to the jumpbox", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("connected to the jumpbox %d times, want 1", calls)
+	}
+}
+
+func TestTunnel_AllProxy(t *testing.T) {
+	tunnel, err := newTunnel("1.2.3.4", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tunnel.Close()
+
+	want := "ssh+socks5://ubuntu@1.2.3.4:22?private-key=/tmp/jumpbox.pem"
+	if got := tunnel.AllProxy("/tmp/jumpbox.pem"); got != want {
+		t.Errorf("AllProxy() = %q, want %q", got, want)
+	}
+}
@@ -31,9 +31,21 @@ var _ = Describe("concourse-up", func() {
 		Expect(err).ToNot(HaveOccurred(), "Error running CLI: "+cliPath)
 		Eventually(session).Should(Exit(0))
 		Expect(session.Out).To(Say("Concourse-Up - A CLI tool to deploy Concourse CI"))
+		Expect(session.Out).To(Say("backup, b    Backs up the Concourse, UAA and CredHub databases and the director state"))
+		Expect(session.Out).To(Say("config, c    Manages the settings and state stored in a deployment's config bucket"))
 		Expect(session.Out).To(Say("deploy, d    Deploys or updates a Concourse"))
 		Expect(session.Out).To(Say("destroy, x   Destroys a Concourse"))
+		Expect(session.Out).To(Say("export, e    Exports a deployment's config and state to an encrypted archive"))
+		Expect(session.Out).To(Say("health, hc   Checks each layer of a deployment and reports pass, warn or fail"))
+		Expect(session.Out).To(Say("history, hi  Shows the audit log of commands that changed a deployment"))
+		Expect(session.Out).To(Say("import, im   Imports an archive written by export into a new config bucket"))
 		Expect(session.Out).To(Say("info, i      Fetches information on a deployed environment"))
+		Expect(session.Out).To(Say("list, l      Lists all deployments visible to the current credentials"))
+		Expect(session.Out).To(Say("logs, g      Tails or downloads logs from the Concourse VMs"))
 		Expect(session.Out).To(Say("maintain, m  Handles maintenance operations in concourse-up"))
+		Expect(session.Out).To(Say("plan, p      Previews the changes a deploy would make"))
+		Expect(session.Out).To(Say("restore, r   Restores a backup into a deployment"))
+		Expect(session.Out).To(Say("ssh, s       Opens a shell on the director or a Concourse VM"))
+		Expect(session.Out).To(Say("unlock, u    Removes the lock held on a deployment by a command that did not finish"))
 	})
 })
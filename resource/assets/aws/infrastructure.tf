@@ -3,6 +3,7 @@ terraform {
 		bucket = "{{ .ConfigBucket }}"
 		key    = "{{ .TFStatePath }}"
 		region = "{{ .Region }}"
+		{{if .LockTable }}dynamodb_table = "{{ .LockTable }}"{{end}}
 	}
 }
 
@@ -10,6 +11,42 @@ data "aws_availability_zones" "available" {
   state = "available"
 }
 
+{{if .VPCID }}
+data "aws_vpc" "default" {
+  id = "{{ .VPCID }}"
+}
+
+data "aws_subnet" "public" {
+  id = "{{ .PublicSubnetID }}"
+}
+
+data "aws_subnet" "private" {
+  id = "{{ .PrivateSubnetID }}"
+}
+
+// The private subnet of an existing VPC reaches the internet through the NAT gateway in its public subnet
+data "aws_nat_gateway" "default" {
+  subnet_id = "{{ .PublicSubnetID }}"
+  state     = "available"
+}
+
+locals {
+  vpc_id            = "${data.aws_vpc.default.id}"
+  public_subnet_id  = "${data.aws_subnet.public.id}"
+  private_subnet_id = "${data.aws_subnet.private.id}"
+  nat_ip            = "${data.aws_nat_gateway.default.public_ip}"
+  rds_subnet_ids    = [{{range $i, $id := .RDSSubnetIDs }}{{if $i}}, {{end}}"{{ $id }}"{{end}}]
+}
+{{else}}
+locals {
+  vpc_id            = "${aws_vpc.default.id}"
+  public_subnet_id  = "${aws_subnet.public.id}"
+  private_subnet_id = "${aws_subnet.private.id}"
+  nat_ip            = "${aws_nat_gateway.default.public_ip}"
+  rds_subnet_ids    = ["${aws_subnet.rds_a.id}", "${aws_subnet.rds_b.id}"]
+}
+{{end}}
+
 variable "rds_instance_class" {
   type = "string"
 	default = "{{ .RDSInstanceClass }}"
@@ -22,7 +59,6 @@ variable "rds_instance_username" {
 
 variable "rds_instance_password" {
   type = "string"
-	default = "{{ .RDSPassword }}"
 }
 
 variable "source_access_ip" {
@@ -180,6 +216,7 @@ resource "aws_iam_user_policy" "bosh" {
 EOF
 }
 
+{{if not .VPCID }}
 resource "aws_vpc" "default" {
   cidr_block = "${var.network_cidr}"
 
@@ -245,7 +282,73 @@ resource "aws_subnet" "public" {
     concourse-up-component = "bosh"
   }
 }
+{{end}}
+
+{{if .Private }}
+data "aws_ami" "jumpbox" {
+  most_recent = true
+  owners      = ["099720109477"]
+
+  filter {
+    name   = "name"
+    values = ["ubuntu/images/hvm-ssd/ubuntu-jammy-22.04-amd64-server-*"]
+  }
+}
+
+resource "aws_instance" "jumpbox" {
+  ami                    = "${data.aws_ami.jumpbox.id}"
+  instance_type          = "t2.micro"
+  key_name               = "${aws_key_pair.default.key_name}"
+  subnet_id              = "${local.public_subnet_id}"
+  vpc_security_group_ids = ["${aws_security_group.jumpbox.id}"]
+
+  tags {
+    Name = "${var.deployment}-jumpbox"
+    concourse-up-project = "${var.project}"
+    concourse-up-component = "bosh"
+  }
+}
+
+resource "aws_eip" "jumpbox" {
+  vpc = true
+  instance = "${aws_instance.jumpbox.id}"
+  {{if not .VPCID }}depends_on = ["aws_internet_gateway.default"]{{end}}
+
+    tags {
+    name = "${var.deployment}-jumpbox"
+    concourse-up-project = "${var.project}"
+  }
+}
+
+resource "aws_security_group" "jumpbox" {
+  name        = "${var.deployment}-jumpbox"
+  description = "Concourse UP jumpbox security group"
+  vpc_id      = "${local.vpc_id}"
+
+  tags {
+    Name = "${var.deployment}-jumpbox"
+    concourse-up-project = "${var.project}"
+    concourse-up-component = "bosh"
+  }
+
+  // The self-update pipeline reaches the director through the jumpbox from behind the NAT
+  ingress {
+    from_port   = 22
+    to_port     = 22
+    protocol    = "tcp"
+    cidr_blocks = ["${var.source_access_ip}/32", "${local.nat_ip}/32"]
+  }
+
+  egress {
+    from_port   = 0
+    to_port     = 0
+    protocol    = "-1"
+    cidr_blocks = ["${var.network_cidr}"]
+  }
+}
+{{end}}
 
+{{if not .VPCID }}
 resource "aws_subnet" "private" {
   vpc_id                  = "${aws_vpc.default.id}"
   availability_zone       = "${var.availability_zone}"
@@ -263,6 +366,27 @@ resource "aws_route_table_association" "private" {
   subnet_id      = "${aws_subnet.private.id}"
   route_table_id = "${aws_route_table.private.id}"
 }
+{{end}}
+
+{{range .ExtraZones }}
+resource "aws_subnet" "private_{{ .Name }}" {
+  vpc_id                  = "${aws_vpc.default.id}"
+  availability_zone       = "{{ .Name }}"
+  cidr_block              = "{{ .PrivateCIDR }}"
+  map_public_ip_on_launch = false
+
+  tags {
+    Name = "${var.deployment}-private-{{ .Name }}"
+    concourse-up-project = "${var.project}"
+    concourse-up-component = "bosh"
+  }
+}
+
+resource "aws_route_table_association" "private_{{ .Name }}" {
+  subnet_id      = "${aws_subnet.private_{{ .Name }}.id}"
+  route_table_id = "${aws_route_table.private.id}"
+}
+{{end}}
 
 {{if .HostedZoneID }}
 resource "aws_route53_record" "concourse" {
@@ -270,13 +394,14 @@ resource "aws_route53_record" "concourse" {
   name    = "${var.hosted_zone_record_prefix}"
   ttl     = "60"
   type    = "A"
-  records = ["${aws_eip.atc.public_ip}"]
+  records = [{{if .Private }}"${cidrhost(var.private_cidr, 7)}"{{else}}"${aws_eip.atc.public_ip}"{{end}}]
 }
 {{end}}
 
+{{if not .Private }}
 resource "aws_eip" "director" {
   vpc = true
-  depends_on = ["aws_internet_gateway.default"]
+  {{if not .VPCID }}depends_on = ["aws_internet_gateway.default"]{{end}}
 
     tags {
     name = "${var.deployment}-director"
@@ -286,7 +411,7 @@ resource "aws_eip" "director" {
 
 resource "aws_eip" "atc" {
   vpc = true
-  depends_on = ["aws_internet_gateway.default"]
+  {{if not .VPCID }}depends_on = ["aws_internet_gateway.default"]{{end}}
 
     tags {
     name = "${var.deployment}-atc"
@@ -294,20 +419,24 @@ resource "aws_eip" "atc" {
   }
 }
 
+{{end}}
+
+{{if not .VPCID }}
 resource "aws_eip" "nat" {
   vpc = true
-  depends_on = ["aws_internet_gateway.default"]
+  {{if not .VPCID }}depends_on = ["aws_internet_gateway.default"]{{end}}
 
     tags {
     name = "${var.deployment}-nat"
     concourse-up-project = "${var.project}"
   }
 }
+{{end}}
 
 resource "aws_security_group" "director" {
   name        = "${var.deployment}-director"
   description = "Concourse UP Default BOSH security group"
-  vpc_id      = "${aws_vpc.default.id}"
+  vpc_id      = "${local.vpc_id}"
 
   tags {
     Name = "${var.deployment}-director"
@@ -319,21 +448,21 @@ resource "aws_security_group" "director" {
     from_port   = 6868
     to_port     = 6868
     protocol    = "tcp"
-    cidr_blocks = ["${var.source_access_ip}/32", "${aws_nat_gateway.default.public_ip}/32"]
+    {{if .Private }}security_groups = ["${aws_security_group.jumpbox.id}"]{{else}}cidr_blocks = ["${var.source_access_ip}/32", "${local.nat_ip}/32"]{{end}}
   }
 
   ingress {
     from_port   = 25555
     to_port     = 25555
     protocol    = "tcp"
-    cidr_blocks = ["${var.source_access_ip}/32", "${aws_nat_gateway.default.public_ip}/32"]
+    {{if .Private }}security_groups = ["${aws_security_group.jumpbox.id}"]{{else}}cidr_blocks = ["${var.source_access_ip}/32", "${local.nat_ip}/32"]{{end}}
   }
 
   ingress {
     from_port   = 22
     to_port     = 22
     protocol    = "tcp"
-    cidr_blocks = ["${var.source_access_ip}/32", "${aws_nat_gateway.default.public_ip}/32"]
+    {{if .Private }}security_groups = ["${aws_security_group.jumpbox.id}"]{{else}}cidr_blocks = ["${var.source_access_ip}/32", "${local.nat_ip}/32"]{{end}}
   }
 
   egress {
@@ -347,7 +476,7 @@ resource "aws_security_group" "director" {
 resource "aws_security_group" "vms" {
   name        = "${var.deployment}-vms"
   description = "Concourse UP VMs security group"
-  vpc_id      = "${aws_vpc.default.id}"
+  vpc_id      = "${local.vpc_id}"
 
   tags {
     Name = "${var.deployment}-vms"
@@ -457,7 +586,7 @@ resource "aws_security_group" "vms" {
 resource "aws_security_group" "rds" {
   name        = "${var.deployment}-rds"
   description = "Concourse UP RDS security group"
-  vpc_id      = "${aws_vpc.default.id}"
+  vpc_id      = "${local.vpc_id}"
 
   tags {
     Name = "${var.deployment}-rds"
@@ -476,8 +605,8 @@ resource "aws_security_group" "rds" {
 resource "aws_security_group" "atc" {
   name        = "${var.deployment}-atc"
   description = "Concourse UP ATC security group"
-  vpc_id      = "${aws_vpc.default.id}"
-  depends_on = ["aws_eip.nat", "aws_eip.atc"]
+  vpc_id      = "${local.vpc_id}"
+  depends_on = [{{if .VPCID }}"data.aws_nat_gateway.default"{{else}}"aws_eip.nat"{{end}}{{if not .Private }}, "aws_eip.atc"{{end}}]
 
   tags {
     Name = "${var.deployment}-atc"
@@ -497,38 +626,39 @@ resource "aws_security_group" "atc" {
     to_port     = 80
     protocol    = "tcp"
     security_groups = ["${aws_security_group.vms.id}", "${aws_security_group.director.id}"]
-    cidr_blocks = ["${aws_eip.nat.public_ip}/32", "${aws_eip.atc.public_ip}/32", {{ .AllowIPs }}]
+    cidr_blocks = ["${local.nat_ip}/32", {{if .Private }}"${var.network_cidr}"{{else}}"${aws_eip.atc.public_ip}/32"{{end}}, {{ .AllowIPs }}]
   }
 
   ingress {
     from_port   = 443
     to_port     = 443
     protocol    = "tcp"
-    cidr_blocks = ["${aws_eip.nat.public_ip}/32", "${aws_eip.atc.public_ip}/32", {{ .AllowIPs }}]
+    cidr_blocks = ["${local.nat_ip}/32", {{if .Private }}"${var.network_cidr}"{{else}}"${aws_eip.atc.public_ip}/32"{{end}}, {{ .AllowIPs }}]
   }
 
   ingress {
     from_port   = 3000
     to_port     = 3000
     protocol    = "tcp"
-    cidr_blocks = ["${aws_eip.nat.public_ip}/32", {{ .AllowIPs }}]
+    cidr_blocks = ["${local.nat_ip}/32", {{ .AllowIPs }}]
   }
 
   ingress {
     from_port   = 8844
     to_port     = 8844
     protocol    = "tcp"
-    cidr_blocks = ["${aws_eip.nat.public_ip}/32", "${aws_eip.atc.public_ip}/32", {{ .AllowIPs }}]
+    cidr_blocks = ["${local.nat_ip}/32", {{if .Private }}"${var.network_cidr}"{{else}}"${aws_eip.atc.public_ip}/32"{{end}}, {{ .AllowIPs }}]
   }
 
   ingress {
     from_port   = 8443
     to_port     = 8443
     protocol    = "tcp"
-    cidr_blocks = ["${aws_eip.nat.public_ip}/32", "${aws_eip.atc.public_ip}/32", {{ .AllowIPs }}]
+    cidr_blocks = ["${local.nat_ip}/32", {{if .Private }}"${var.network_cidr}"{{else}}"${aws_eip.atc.public_ip}/32"{{end}}, {{ .AllowIPs }}]
   }
 }
 
+{{if not .VPCID }}
 resource "aws_route_table" "rds" {
   vpc_id = "${aws_vpc.default.id}"
 
@@ -572,10 +702,11 @@ resource "aws_subnet" "rds_b" {
     concourse-up-component = "rds"
   }
 }
+{{end}}
 
 resource "aws_db_subnet_group" "default" {
   name       = "${var.deployment}"
-  subnet_ids = ["${aws_subnet.rds_a.id}", "${aws_subnet.rds_b.id}"]
+  subnet_ids = ["${local.rds_subnet_ids}"]
 
   tags {
     Name = "${var.deployment}"
@@ -595,7 +726,7 @@ resource "aws_db_instance" "default" {
   username               = "${var.rds_instance_username}"
   password               = "${var.rds_instance_password}"
   publicly_accessible    = false
-  multi_az               = false
+  multi_az               = {{ .DBHighAvailability }}
   vpc_security_group_ids = ["${aws_security_group.rds.id}"]
   db_subnet_group_name   = "${aws_db_subnet_group.default.name}"
   skip_final_snapshot    = true
@@ -611,7 +742,7 @@ resource "aws_db_instance" "default" {
 }
 
 output "vpc_id" {
-  value = "${aws_vpc.default.id}"
+  value = "${local.vpc_id}"
 }
 
 output "source_access_ip" {
@@ -623,11 +754,19 @@ output "director_key_pair" {
 }
 
 output "director_public_ip" {
-  value = "${aws_eip.director.public_ip}"
+  value = "{{if .Private }}${cidrhost(var.private_cidr, 6)}{{else}}${aws_eip.director.public_ip}{{end}}"
 }
 
 output "atc_public_ip" {
-  value = "${aws_eip.atc.public_ip}"
+  value = "{{if .Private }}${cidrhost(var.private_cidr, 7)}{{else}}${aws_eip.atc.public_ip}{{end}}"
+}
+
+output "jumpbox_public_ip" {
+  value = "{{if .Private }}${aws_eip.jumpbox.public_ip}{{end}}"
+}
+
+output "jumpbox_security_group_id" {
+  value = "{{if .Private }}${aws_security_group.jumpbox.id}{{end}}"
 }
 
 output "director_security_group_id" {
@@ -643,15 +782,19 @@ output "atc_security_group_id" {
 }
 
 output "nat_gateway_ip" {
-  value = "${aws_nat_gateway.default.public_ip}"
+  value = "${local.nat_ip}"
 }
 
 output "public_subnet_id" {
-  value = "${aws_subnet.public.id}"
+  value = "${local.public_subnet_id}"
 }
 
 output "private_subnet_id" {
-  value = "${aws_subnet.private.id}"
+  value = "${local.private_subnet_id}"
+}
+
+output "zone_private_subnet_ids" {
+  value = "{{range $i, $zone := .ExtraZones }}{{if $i}},{{end}}${aws_subnet.private_{{ $zone.Name }}.id}{{end}}"
 }
 
 output "blobstore_bucket" {
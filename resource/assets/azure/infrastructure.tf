@@ -0,0 +1,412 @@
+terraform {
+	backend "azurerm" {
+		storage_account_name = "{{ .StorageAccount }}"
+		resource_group_name  = "{{ .StorageResourceGroup }}"
+		container_name       = "{{ .ConfigBucket }}"
+		key                  = "terraform.tfstate"
+	}
+}
+
+variable "deployment" {
+  type = "string"
+	default = "{{ .Deployment }}"
+}
+variable "region" {
+  type = "string"
+	default = "{{ .Region }}"
+}
+
+variable "namespace" {
+  type = "string"
+  default = "{{ .Namespace }}"
+}
+
+variable "db_name" {
+  type = "string"
+  default = "{{ .DBName }}"
+}
+
+variable "db_username" {
+  type = "string"
+	default = "{{ .DBUsername }}"
+}
+variable "db_password" {
+  type = "string"
+}
+
+variable "public_key" {
+  type = "string"
+  default = "{{ .PublicKey }}"
+}
+
+variable "source_access_ip" {
+  type = "string"
+  default = "{{ .SourceAccessIP }}"
+}
+
+variable "public_cidr" {
+  type = "string"
+  default = "{{ .PublicCIDR }}"
+}
+
+variable "private_cidr" {
+  type = "string"
+  default = "{{ .PrivateCIDR }}"
+}
+
+{{if .DNSZoneName }}
+variable "dns_zone_name" {
+  type = "string"
+  default = "{{ .DNSZoneName }}"
+}
+
+variable "dns_zone_resource_group" {
+  type = "string"
+  default = "{{ .DNSZoneResourceGroup }}"
+}
+
+variable "dns_record_prefix" {
+  type = "string"
+  default = "{{ .DNSRecordPrefix }}"
+}
+{{end}}
+
+// Credentials are read from the ARM_* environment variables
+provider "azurerm" {
+  version = "~> 1.22"
+}
+
+{{if .DNSZoneName }}
+data "azurerm_dns_zone" "dns_zone" {
+  name                = "${var.dns_zone_name}"
+  resource_group_name = "${var.dns_zone_resource_group}"
+}
+
+resource "azurerm_dns_a_record" "dns" {
+  name                = "${var.dns_record_prefix}"
+  zone_name           = "${data.azurerm_dns_zone.dns_zone.name}"
+  resource_group_name = "${data.azurerm_dns_zone.dns_zone.resource_group_name}"
+  ttl                 = 60
+  records             = ["${azurerm_public_ip.atc.ip_address}"]
+}
+{{end}}
+
+// BOSH creates its VMs, disks and network interfaces in the same resource group
+resource "azurerm_resource_group" "default" {
+  name     = "${var.deployment}"
+  location = "${var.region}"
+
+  tags {
+    concourse-up-project = "${var.deployment}"
+  }
+}
+
+resource "azurerm_virtual_network" "default" {
+  name                = "${var.deployment}"
+  location            = "${var.region}"
+  resource_group_name = "${azurerm_resource_group.default.name}"
+  address_space       = ["${var.public_cidr}", "${var.private_cidr}"]
+}
+
+resource "azurerm_subnet" "public" {
+  name                 = "${var.deployment}-${var.namespace}-public"
+  resource_group_name  = "${azurerm_resource_group.default.name}"
+  virtual_network_name = "${azurerm_virtual_network.default.name}"
+  address_prefix       = "${var.public_cidr}"
+}
+
+resource "azurerm_subnet" "private" {
+  name                 = "${var.deployment}-${var.namespace}-private"
+  resource_group_name  = "${azurerm_resource_group.default.name}"
+  virtual_network_name = "${azurerm_virtual_network.default.name}"
+  address_prefix       = "${var.private_cidr}"
+  route_table_id       = "${azurerm_route_table.private.id}"
+}
+
+// route for nat
+resource "azurerm_route_table" "private" {
+  name                = "${var.deployment}-private"
+  location            = "${var.region}"
+  resource_group_name = "${azurerm_resource_group.default.name}"
+
+  route {
+    name                   = "nat"
+    address_prefix         = "0.0.0.0/0"
+    next_hop_type          = "VirtualAppliance"
+    next_hop_in_ip_address = "${azurerm_network_interface.nat.private_ip_address}"
+  }
+}
+
+resource "azurerm_subnet_route_table_association" "private" {
+  subnet_id      = "${azurerm_subnet.private.id}"
+  route_table_id = "${azurerm_route_table.private.id}"
+}
+
+resource "azurerm_public_ip" "director" {
+  name                = "${var.deployment}-director-ip"
+  location            = "${var.region}"
+  resource_group_name = "${azurerm_resource_group.default.name}"
+  allocation_method   = "Static"
+  sku                 = "Standard"
+}
+
+resource "azurerm_public_ip" "atc" {
+  name                = "${var.deployment}-atc-ip"
+  location            = "${var.region}"
+  resource_group_name = "${azurerm_resource_group.default.name}"
+  allocation_method   = "Static"
+  sku                 = "Standard"
+}
+
+resource "azurerm_public_ip" "nat" {
+  name                = "${var.deployment}-nat-ip"
+  location            = "${var.region}"
+  resource_group_name = "${azurerm_resource_group.default.name}"
+  allocation_method   = "Static"
+  sku                 = "Standard"
+}
+
+// nat
+resource "azurerm_network_security_group" "nat" {
+  name                = "${var.deployment}-nat"
+  location            = "${var.region}"
+  resource_group_name = "${azurerm_resource_group.default.name}"
+
+  security_rule {
+    name                       = "from-private"
+    priority                   = 100
+    direction                  = "Inbound"
+    access                     = "Allow"
+    protocol                   = "*"
+    source_port_range          = "*"
+    destination_port_range     = "*"
+    source_address_prefix      = "${var.private_cidr}"
+    destination_address_prefix = "*"
+  }
+}
+
+resource "azurerm_network_interface" "nat" {
+  name                      = "${var.deployment}-nat-instance"
+  location                  = "${var.region}"
+  resource_group_name       = "${azurerm_resource_group.default.name}"
+  network_security_group_id = "${azurerm_network_security_group.nat.id}"
+  enable_ip_forwarding      = true
+
+  ip_configuration {
+    name                          = "nat"
+    subnet_id                     = "${azurerm_subnet.public.id}"
+    private_ip_address_allocation = "Dynamic"
+    public_ip_address_id          = "${azurerm_public_ip.nat.id}"
+  }
+}
+
+resource "azurerm_virtual_machine" "nat" {
+  name                             = "${var.deployment}-nat-instance"
+  location                         = "${var.region}"
+  resource_group_name              = "${azurerm_resource_group.default.name}"
+  network_interface_ids            = ["${azurerm_network_interface.nat.id}"]
+  vm_size                          = "Standard_B1s"
+  delete_os_disk_on_termination    = true
+
+  storage_image_reference {
+    publisher = "Canonical"
+    offer     = "UbuntuServer"
+    sku       = "18.04-LTS"
+    version   = "latest"
+  }
+
+  storage_os_disk {
+    name              = "${var.deployment}-nat-instance"
+    caching           = "ReadWrite"
+    create_option     = "FromImage"
+    managed_disk_type = "Standard_LRS"
+  }
+
+  os_profile {
+    computer_name  = "nat-instance"
+    admin_username = "ubuntu"
+
+    custom_data = <<EOT
+#!/bin/bash
+
+netif="$(ip r | awk '/default/ {print $5}')"
+
+echo "net.ipv4.ip_forward=1" >> /etc/sysctl.conf
+sudo sysctl -p
+
+sudo iptables -t nat -A POSTROUTING -o "$netif" -j MASQUERADE
+EOT
+  }
+
+  os_profile_linux_config {
+    disable_password_authentication = true
+
+    ssh_keys {
+      path     = "/home/ubuntu/.ssh/authorized_keys"
+      key_data = "${var.public_key}"
+    }
+  }
+}
+
+// The default rules of each group allow traffic within the virtual network and deny the rest from the internet
+resource "azurerm_network_security_group" "vms" {
+  name                = "${var.deployment}-vms"
+  location            = "${var.region}"
+  resource_group_name = "${azurerm_resource_group.default.name}"
+}
+
+resource "azurerm_network_security_group" "director" {
+  name                = "${var.deployment}-director"
+  location            = "${var.region}"
+  resource_group_name = "${azurerm_resource_group.default.name}"
+
+  security_rule {
+    name                       = "director"
+    priority                   = 100
+    direction                  = "Inbound"
+    access                     = "Allow"
+    protocol                   = "Tcp"
+    source_port_range          = "*"
+    destination_port_ranges    = ["22", "6868", "25555"]
+    source_address_prefixes    = ["${var.source_access_ip}/32", "${azurerm_public_ip.nat.ip_address}/32"]
+    destination_address_prefix = "*"
+  }
+}
+
+resource "azurerm_network_security_group" "atc" {
+  name                = "${var.deployment}-atc"
+  location            = "${var.region}"
+  resource_group_name = "${azurerm_resource_group.default.name}"
+
+  security_rule {
+    name                       = "atc-http"
+    priority                   = 100
+    direction                  = "Inbound"
+    access                     = "Allow"
+    protocol                   = "Tcp"
+    source_port_range          = "*"
+    destination_port_range     = "80"
+    source_address_prefixes    = [{{ .AllowIPs }}]
+    destination_address_prefix = "*"
+  }
+
+  security_rule {
+    name                       = "atc-https"
+    priority                   = 110
+    direction                  = "Inbound"
+    access                     = "Allow"
+    protocol                   = "Tcp"
+    source_port_range          = "*"
+    destination_port_ranges    = ["443", "8443"]
+    source_address_prefixes    = ["${azurerm_public_ip.nat.ip_address}/32", "${azurerm_public_ip.atc.ip_address}/32", {{ .AllowIPs }}]
+    destination_address_prefix = "*"
+  }
+
+  security_rule {
+    name                       = "atc-services"
+    priority                   = 120
+    direction                  = "Inbound"
+    access                     = "Allow"
+    protocol                   = "Tcp"
+    source_port_range          = "*"
+    destination_port_ranges    = ["3000", "8844"]
+    source_address_prefixes    = ["${azurerm_public_ip.nat.ip_address}/32", "${azurerm_public_ip.atc.ip_address}/32", {{ .AllowIPs }}]
+    destination_address_prefix = "*"
+  }
+}
+
+resource "azurerm_postgresql_server" "director" {
+  name                = "${var.db_name}"
+  location            = "${var.region}"
+  resource_group_name = "${azurerm_resource_group.default.name}"
+
+  sku {
+    name     = "{{ .DBSKU }}"
+    tier     = "{{ .DBTier }}"
+    family   = "{{ .DBFamily }}"
+    capacity = {{ .DBCapacity }}
+  }
+
+  storage_profile {
+    storage_mb            = 10240
+    backup_retention_days = 7
+    geo_redundant_backup  = "Disabled"
+  }
+
+  administrator_login          = "${var.db_username}"
+  administrator_login_password = "${var.db_password}"
+  version                      = "9.6"
+  ssl_enforcement              = "Enabled"
+}
+
+resource "azurerm_postgresql_firewall_rule" "director" {
+  name                = "bosh"
+  resource_group_name = "${azurerm_resource_group.default.name}"
+  server_name         = "${azurerm_postgresql_server.director.name}"
+  start_ip_address    = "${azurerm_public_ip.director.ip_address}"
+  end_ip_address      = "${azurerm_public_ip.director.ip_address}"
+}
+
+resource "azurerm_postgresql_firewall_rule" "atc" {
+  name                = "atc_conf"
+  resource_group_name = "${azurerm_resource_group.default.name}"
+  server_name         = "${azurerm_postgresql_server.director.name}"
+  start_ip_address    = "${azurerm_public_ip.atc.ip_address}"
+  end_ip_address      = "${azurerm_public_ip.atc.ip_address}"
+}
+
+resource "azurerm_postgresql_firewall_rule" "nat" {
+  name                = "nat"
+  resource_group_name = "${azurerm_resource_group.default.name}"
+  server_name         = "${azurerm_postgresql_server.director.name}"
+  start_ip_address    = "${azurerm_public_ip.nat.ip_address}"
+  end_ip_address      = "${azurerm_public_ip.nat.ip_address}"
+}
+
+output "resource_group" {
+  value = "${azurerm_resource_group.default.name}"
+}
+
+output "network" {
+  value = "${azurerm_virtual_network.default.name}"
+}
+
+output "public_subnet_name" {
+  value = "${azurerm_subnet.public.name}"
+}
+
+output "private_subnet_name" {
+  value = "${azurerm_subnet.private.name}"
+}
+
+output "director_public_ip" {
+  value = "${azurerm_public_ip.director.ip_address}"
+}
+
+output "atc_public_ip" {
+  value = "${azurerm_public_ip.atc.ip_address}"
+}
+
+output "nat_gateway_ip" {
+  value = "${azurerm_public_ip.nat.ip_address}"
+}
+
+output "director_security_group_id" {
+  value = "${azurerm_network_security_group.director.id}"
+}
+
+output "director_security_group_name" {
+  value = "${azurerm_network_security_group.director.name}"
+}
+
+output "atc_security_group_name" {
+  value = "${azurerm_network_security_group.atc.name}"
+}
+
+output "vms_security_group_name" {
+  value = "${azurerm_network_security_group.vms.name}"
+}
+
+output "bosh_db_address" {
+  value = "${azurerm_postgresql_server.director.fqdn}"
+}
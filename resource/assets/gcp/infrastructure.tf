@@ -39,7 +39,6 @@ variable "db_username" {
 }
 variable "db_password" {
   type = "string"
-	default = "{{ .DBPassword }}"
 }
 
 variable "db_name" {
@@ -109,11 +108,48 @@ resource "google_dns_record_set" "dns" {
 }
 {{end}}
 
+{{if .Network }}
+data "google_compute_network" "default" {
+  name    = "{{ .Network }}"
+  project = "${var.project}"
+}
+
+data "google_compute_subnetwork" "public" {
+  name    = "{{ .PublicSubnetwork }}"
+  region  = "${var.region}"
+  project = "${var.project}"
+}
+
+data "google_compute_subnetwork" "private" {
+  name    = "{{ .PrivateSubnetwork }}"
+  region  = "${var.region}"
+  project = "${var.project}"
+}
+
+locals {
+  network_name               = "${data.google_compute_network.default.name}"
+  network                    = "${data.google_compute_network.default.self_link}"
+  public_subnetwork_name     = "${data.google_compute_subnetwork.public.name}"
+  public_subnetwork_gateway  = "${data.google_compute_subnetwork.public.gateway_address}"
+  private_subnetwork_name    = "${data.google_compute_subnetwork.private.name}"
+  private_subnetwork_gateway = "${data.google_compute_subnetwork.private.gateway_address}"
+}
+{{else}}
+locals {
+  network_name               = "${google_compute_network.default.name}"
+  network                    = "${google_compute_network.default.self_link}"
+  public_subnetwork_name     = "${google_compute_subnetwork.public.name}"
+  public_subnetwork_gateway  = "${google_compute_subnetwork.public.gateway_address}"
+  private_subnetwork_name    = "${google_compute_subnetwork.private.name}"
+  private_subnetwork_gateway = "${google_compute_subnetwork.private.gateway_address}"
+}
+{{end}}
+
 // route for nat
 resource "google_compute_route" "nat" {
   name                   = "${var.deployment}-nat-route"
   dest_range             = "0.0.0.0/0"
-  network                = "${google_compute_network.default.name}"
+  network                = "${local.network_name}"
   next_hop_instance      = "${google_compute_instance.nat-instance.name}"
   next_hop_instance_zone = "${var.zone}"
   priority               = 800
@@ -137,7 +173,7 @@ resource "google_compute_instance" "nat-instance" {
   }
 
   network_interface {
-    subnetwork = "${google_compute_subnetwork.private.name}"
+    subnetwork = "${local.private_subnetwork_name}"
     subnetwork_project = "${var.project}"
     access_config {
       // Ephemeral IP
@@ -158,6 +194,7 @@ sudo iptables -t nat -A POSTROUTING -o "$netif" -j MASQUERADE
 EOT
 }
 
+{{if not .Network }}
 resource "google_compute_network" "default" {
   name                    = "${var.deployment}"
   project                 = "${var.project}"
@@ -176,11 +213,12 @@ resource "google_compute_subnetwork" "private" {
   network       = "${google_compute_network.default.self_link}"
   project       = "${var.project}"
 }
+{{end}}
 
 resource "google_compute_firewall" "director" {
   name = "${var.deployment}-director"
   description = "Firewall for external access to BOSH director"
-  network     = "${google_compute_network.default.self_link}"
+  network     = "${local.network}"
   target_tags = ["external"]
   source_ranges = ["${var.source_access_ip}/32", "${google_compute_instance.nat-instance.network_interface.0.access_config.0.nat_ip}/32"]
   allow {
@@ -192,7 +230,7 @@ resource "google_compute_firewall" "director" {
 resource "google_compute_firewall" "nat" {
   name = "${var.deployment}-nat"
   description = "Firewall for external access to NAT"
-  network     = "${google_compute_network.default.self_link}"
+  network     = "${local.network}"
   target_tags = ["nat"]
   source_ranges = ["0.0.0.0/0"]
   allow {
@@ -204,7 +242,7 @@ resource "google_compute_firewall" "nat" {
 resource "google_compute_firewall" "atc-http" {
   name = "${var.deployment}-atc-http"
   description = "Firewall for external access to concourse atc"
-  network     = "${google_compute_network.default.self_link}"
+  network     = "${local.network}"
   target_tags = ["web"]
   source_tags = ["web", "worker", "external", "internal"]
   source_ranges = [{{ .AllowIPs }}]
@@ -217,7 +255,7 @@ resource "google_compute_firewall" "atc-http" {
 resource "google_compute_firewall" "atc-https" {
   name = "${var.deployment}-atc-https"
   description = "Firewall for external access to concourse atc"
-  network     = "${google_compute_network.default.self_link}"
+  network     = "${local.network}"
   target_tags = ["web"]
   source_ranges = ["${google_compute_instance.nat-instance.network_interface.0.access_config.0.nat_ip}/32", "${google_compute_address.atc_ip.address}/32", {{ .AllowIPs }}]
   allow {
@@ -229,7 +267,7 @@ resource "google_compute_firewall" "atc-https" {
 resource "google_compute_firewall" "from-public" {
   name = "${var.deployment}-public"
   description = "Concourse UP VMs firewall"
-  network     = "${google_compute_network.default.self_link}"
+  network     = "${local.network}"
   target_tags = ["web", "external", "internal", "worker"]
   source_ranges = ["${var.public_cidr}"]
   allow {
@@ -248,7 +286,7 @@ resource "google_compute_firewall" "from-public" {
 resource "google_compute_firewall" "from-private" {
   name = "${var.deployment}-private"
   description = "Concourse UP VMs firewall"
-  network     = "${google_compute_network.default.self_link}"
+  network     = "${local.network}"
   target_tags = ["web", "external", "internal", "worker"]
   source_ranges = ["${var.private_cidr}"]
   allow {
@@ -267,7 +305,7 @@ resource "google_compute_firewall" "from-private" {
 resource "google_compute_firewall" "atc-services" {
   name = "${var.deployment}-atc-services"
   description = "Firewall for external access to concourse atc"
-  network     = "${google_compute_network.default.self_link}"
+  network     = "${local.network}"
   target_tags = ["web"]
   source_ranges = ["${google_compute_instance.nat-instance.network_interface.0.access_config.0.nat_ip}/32", "${google_compute_address.atc_ip.address}/32", {{ .AllowIPs }}]
   allow {
@@ -279,7 +317,7 @@ resource "google_compute_firewall" "atc-services" {
 resource "google_compute_firewall" "internal" {
   name        = "${var.deployment}-int"
   description = "BOSH CI Internal Traffic"
-  network     = "${google_compute_network.default.self_link}"
+  network     = "${local.network}"
   source_tags = ["internal"]
   target_tags = ["internal"]
 
@@ -299,7 +337,7 @@ resource "google_compute_firewall" "internal" {
 resource "google_compute_firewall" "sql" {
   name        = "${var.deployment}-sql"
   description = "BOSH CI External Traffic"
-  network     = "${google_compute_network.default.self_link}"
+  network     = "${local.network}"
   direction = "EGRESS"
   allow {
     protocol = "tcp"
@@ -339,6 +377,12 @@ resource "google_sql_database_instance" "director" {
 
   settings {
     tier = "${var.db_tier}"
+    availability_type = "{{if .DBHighAvailability }}REGIONAL{{else}}ZONAL{{end}}"
+{{if .DBHighAvailability }}
+    backup_configuration {
+      enabled = true
+    }
+{{end}}
     user_labels {
       deployment = "${var.deployment}"
     }
@@ -373,7 +417,7 @@ resource "google_sql_user" "director" {
 }
 
 output "network" {
-value = "${google_compute_network.default.name}"
+value = "${local.network_name}"
 }
 
 output "director_firewall_name" {
@@ -381,19 +425,19 @@ value = "${google_compute_firewall.director.name}"
 }
 
 output "private_subnetwork_name" {
-value = "${google_compute_subnetwork.private.name}"
+value = "${local.private_subnetwork_name}"
 }
 
 output "public_subnetwork_name" {
-value = "${google_compute_subnetwork.public.name}"
+value = "${local.public_subnetwork_name}"
 }
 
 output "private_subnetwork_internal_gw" {
-value = "${google_compute_subnetwork.private.gateway_address}"
+value = "${local.private_subnetwork_gateway}"
 }
 
 output "public_subnetwork_internal_gw" {
-value = "${google_compute_subnetwork.public.gateway_address}"
+value = "${local.public_subnetwork_gateway}"
 }
 
 output "atc_public_ip" {
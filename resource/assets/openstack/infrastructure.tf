@@ -0,0 +1,420 @@
+terraform {
+	backend "swift" {
+		container   = "{{ .ConfigBucket }}"
+		region_name = "{{ .Region }}"
+	}
+}
+
+variable "deployment" {
+  type = "string"
+	default = "{{ .Deployment }}"
+}
+variable "region" {
+  type = "string"
+	default = "{{ .Region }}"
+}
+
+variable "namespace" {
+  type = "string"
+  default = "{{ .Namespace }}"
+}
+
+variable "db_name" {
+  type = "string"
+  default = "{{ .DBName }}"
+}
+
+variable "db_username" {
+  type = "string"
+	default = "{{ .DBUsername }}"
+}
+variable "db_password" {
+  type = "string"
+}
+
+variable "db_flavor" {
+  type = "string"
+  default = "{{ .DBFlavor }}"
+}
+
+variable "db_image" {
+  type = "string"
+  default = "{{ .DBImage }}"
+}
+
+variable "external_network" {
+  type = "string"
+  default = "{{ .ExternalNetwork }}"
+}
+
+variable "public_key" {
+  type = "string"
+  default = "{{ .PublicKey }}"
+}
+
+variable "source_access_ip" {
+  type = "string"
+  default = "{{ .SourceAccessIP }}"
+}
+
+variable "allow_ips" {
+  type = "list"
+  default = [{{ .AllowIPs }}]
+}
+
+variable "public_cidr" {
+  type = "string"
+  default = "{{ .PublicCIDR }}"
+}
+
+variable "private_cidr" {
+  type = "string"
+  default = "{{ .PrivateCIDR }}"
+}
+
+{{if .DNSZoneID }}
+variable "dns_zone_id" {
+  type = "string"
+  default = "{{ .DNSZoneID }}"
+}
+
+variable "dns_record_name" {
+  type = "string"
+  default = "{{ .DNSRecordName }}"
+}
+{{end}}
+
+// Credentials are read from the OS_* environment variables
+provider "openstack" {
+  version = "~> 1.19"
+  region  = "${var.region}"
+}
+
+provider "tls" {
+  version = "~> 2.0"
+}
+
+{{if .DNSZoneID }}
+resource "openstack_dns_recordset_v2" "dns" {
+  zone_id = "${var.dns_zone_id}"
+  name    = "${var.dns_record_name}"
+  type    = "A"
+  ttl     = 60
+  records = ["${openstack_networking_floatingip_v2.atc.address}"]
+}
+{{end}}
+
+data "openstack_networking_network_v2" "external" {
+  name     = "${var.external_network}"
+  external = true
+}
+
+resource "openstack_compute_keypair_v2" "default" {
+  name       = "${var.deployment}"
+  public_key = "${var.public_key}"
+}
+
+// BOSH attaches its VMs to this network, so it is named after the deployment
+resource "openstack_networking_network_v2" "default" {
+  name           = "${var.deployment}"
+  admin_state_up = true
+}
+
+resource "openstack_networking_subnet_v2" "public" {
+  name            = "${var.deployment}-${var.namespace}-public"
+  network_id      = "${openstack_networking_network_v2.default.id}"
+  cidr            = "${var.public_cidr}"
+  ip_version      = 4
+  dns_nameservers = ["8.8.8.8", "8.8.4.4"]
+}
+
+resource "openstack_networking_subnet_v2" "private" {
+  name            = "${var.deployment}-${var.namespace}-private"
+  network_id      = "${openstack_networking_network_v2.default.id}"
+  cidr            = "${var.private_cidr}"
+  ip_version      = 4
+  dns_nameservers = ["8.8.8.8", "8.8.4.4"]
+}
+
+// The router translates the addresses of both subnets to its own on the external network, so no NAT instance is needed
+resource "openstack_networking_router_v2" "default" {
+  name                = "${var.deployment}"
+  admin_state_up      = true
+  external_network_id = "${data.openstack_networking_network_v2.external.id}"
+}
+
+resource "openstack_networking_router_interface_v2" "public" {
+  router_id = "${openstack_networking_router_v2.default.id}"
+  subnet_id = "${openstack_networking_subnet_v2.public.id}"
+}
+
+resource "openstack_networking_router_interface_v2" "private" {
+  router_id = "${openstack_networking_router_v2.default.id}"
+  subnet_id = "${openstack_networking_subnet_v2.private.id}"
+}
+
+resource "openstack_networking_floatingip_v2" "director" {
+  pool = "${var.external_network}"
+}
+
+resource "openstack_networking_floatingip_v2" "atc" {
+  pool = "${var.external_network}"
+}
+
+// The default rules of each group allow all egress, and vms allows all traffic within the network
+resource "openstack_networking_secgroup_v2" "vms" {
+  name        = "${var.deployment}-vms"
+  description = "Concourse VMs"
+}
+
+resource "openstack_networking_secgroup_rule_v2" "vms_internal" {
+  direction         = "ingress"
+  ethertype         = "IPv4"
+  remote_group_id   = "${openstack_networking_secgroup_v2.vms.id}"
+  security_group_id = "${openstack_networking_secgroup_v2.vms.id}"
+}
+
+resource "openstack_networking_secgroup_v2" "director" {
+  name        = "${var.deployment}-director"
+  description = "BOSH director"
+}
+
+resource "openstack_networking_secgroup_rule_v2" "director_ssh" {
+  direction         = "ingress"
+  ethertype         = "IPv4"
+  protocol          = "tcp"
+  port_range_min    = 22
+  port_range_max    = 22
+  remote_ip_prefix  = "${var.source_access_ip}/32"
+  security_group_id = "${openstack_networking_secgroup_v2.director.id}"
+}
+
+resource "openstack_networking_secgroup_rule_v2" "director_agent" {
+  direction         = "ingress"
+  ethertype         = "IPv4"
+  protocol          = "tcp"
+  port_range_min    = 6868
+  port_range_max    = 6868
+  remote_ip_prefix  = "${var.source_access_ip}/32"
+  security_group_id = "${openstack_networking_secgroup_v2.director.id}"
+}
+
+resource "openstack_networking_secgroup_rule_v2" "director_api" {
+  direction         = "ingress"
+  ethertype         = "IPv4"
+  protocol          = "tcp"
+  port_range_min    = 25555
+  port_range_max    = 25555
+  remote_ip_prefix  = "${var.source_access_ip}/32"
+  security_group_id = "${openstack_networking_secgroup_v2.director.id}"
+}
+
+resource "openstack_networking_secgroup_rule_v2" "director_nat" {
+  direction         = "ingress"
+  ethertype         = "IPv4"
+  protocol          = "tcp"
+  remote_ip_prefix  = "${openstack_networking_router_v2.default.external_fixed_ip.0.ip_address}/32"
+  security_group_id = "${openstack_networking_secgroup_v2.director.id}"
+}
+
+resource "openstack_networking_secgroup_v2" "atc" {
+  name        = "${var.deployment}-atc"
+  description = "Concourse web"
+}
+
+resource "openstack_networking_secgroup_rule_v2" "atc_http" {
+  count             = "${length(var.allow_ips)}"
+  direction         = "ingress"
+  ethertype         = "IPv4"
+  protocol          = "tcp"
+  port_range_min    = 80
+  port_range_max    = 80
+  remote_ip_prefix  = "${element(var.allow_ips, count.index)}"
+  security_group_id = "${openstack_networking_secgroup_v2.atc.id}"
+}
+
+resource "openstack_networking_secgroup_rule_v2" "atc_https" {
+  count             = "${length(var.allow_ips)}"
+  direction         = "ingress"
+  ethertype         = "IPv4"
+  protocol          = "tcp"
+  port_range_min    = 443
+  port_range_max    = 443
+  remote_ip_prefix  = "${element(var.allow_ips, count.index)}"
+  security_group_id = "${openstack_networking_secgroup_v2.atc.id}"
+}
+
+resource "openstack_networking_secgroup_rule_v2" "atc_credhub" {
+  count             = "${length(var.allow_ips)}"
+  direction         = "ingress"
+  ethertype         = "IPv4"
+  protocol          = "tcp"
+  port_range_min    = 8844
+  port_range_max    = 8844
+  remote_ip_prefix  = "${element(var.allow_ips, count.index)}"
+  security_group_id = "${openstack_networking_secgroup_v2.atc.id}"
+}
+
+resource "openstack_networking_secgroup_rule_v2" "atc_uaa" {
+  count             = "${length(var.allow_ips)}"
+  direction         = "ingress"
+  ethertype         = "IPv4"
+  protocol          = "tcp"
+  port_range_min    = 8443
+  port_range_max    = 8443
+  remote_ip_prefix  = "${element(var.allow_ips, count.index)}"
+  security_group_id = "${openstack_networking_secgroup_v2.atc.id}"
+}
+
+resource "openstack_networking_secgroup_rule_v2" "atc_grafana" {
+  count             = "${length(var.allow_ips)}"
+  direction         = "ingress"
+  ethertype         = "IPv4"
+  protocol          = "tcp"
+  port_range_min    = 3000
+  port_range_max    = 3000
+  remote_ip_prefix  = "${element(var.allow_ips, count.index)}"
+  security_group_id = "${openstack_networking_secgroup_v2.atc.id}"
+}
+
+// Traffic from the NAT and the ATC itself reaches the ATC's floating IP through the router
+resource "openstack_networking_secgroup_rule_v2" "atc_nat" {
+  direction         = "ingress"
+  ethertype         = "IPv4"
+  protocol          = "tcp"
+  remote_ip_prefix  = "${openstack_networking_router_v2.default.external_fixed_ip.0.ip_address}/32"
+  security_group_id = "${openstack_networking_secgroup_v2.atc.id}"
+}
+
+resource "openstack_networking_secgroup_v2" "db" {
+  name        = "${var.deployment}-db"
+  description = "Postgres for BOSH and Concourse"
+}
+
+resource "openstack_networking_secgroup_rule_v2" "db" {
+  direction         = "ingress"
+  ethertype         = "IPv4"
+  protocol          = "tcp"
+  port_range_min    = 5432
+  port_range_max    = 5432
+  remote_group_id   = "${openstack_networking_secgroup_v2.vms.id}"
+  security_group_id = "${openstack_networking_secgroup_v2.db.id}"
+}
+
+// Postgres runs on a VM with an address from the reserved range of the private subnet, as Trove is rarely available
+resource "openstack_networking_port_v2" "db" {
+  name               = "${var.deployment}-postgres"
+  network_id         = "${openstack_networking_network_v2.default.id}"
+  admin_state_up     = true
+  security_group_ids = ["${openstack_networking_secgroup_v2.db.id}"]
+
+  fixed_ip {
+    subnet_id  = "${openstack_networking_subnet_v2.private.id}"
+    ip_address = "${cidrhost(var.private_cidr, 4)}"
+  }
+}
+
+resource "tls_private_key" "db" {
+  algorithm = "RSA"
+  rsa_bits  = 2048
+}
+
+resource "tls_self_signed_cert" "db" {
+  key_algorithm         = "RSA"
+  private_key_pem       = "${tls_private_key.db.private_key_pem}"
+  validity_period_hours = 87600
+  is_ca_certificate     = true
+  allowed_uses          = ["cert_signing", "key_encipherment", "digital_signature", "server_auth"]
+  ip_addresses          = ["${cidrhost(var.private_cidr, 4)}"]
+
+  subject {
+    common_name  = "${cidrhost(var.private_cidr, 4)}"
+    organization = "concourse-up"
+  }
+}
+
+resource "openstack_compute_instance_v2" "db" {
+  name        = "${var.deployment}-postgres"
+  image_name  = "${var.db_image}"
+  flavor_name = "${var.db_flavor}"
+  key_pair    = "${openstack_compute_keypair_v2.default.name}"
+
+  network {
+    port = "${openstack_networking_port_v2.db.id}"
+  }
+
+  user_data = <<EOT
+#!/bin/bash
+set -e
+
+apt-get update
+apt-get install -y postgresql
+
+cluster="$(ls -d /etc/postgresql/*/main)"
+cat > /var/lib/postgresql/server.crt <<EOF
+${tls_self_signed_cert.db.cert_pem}
+EOF
+cat > /var/lib/postgresql/server.key <<EOF
+${tls_private_key.db.private_key_pem}
+EOF
+chown postgres:postgres /var/lib/postgresql/server.*
+chmod 600 /var/lib/postgresql/server.key
+
+cat >> "$cluster/postgresql.conf" <<EOF
+listen_addresses = '*'
+ssl = on
+ssl_cert_file = '/var/lib/postgresql/server.crt'
+ssl_key_file = '/var/lib/postgresql/server.key'
+EOF
+echo "hostssl all all ${var.public_cidr} md5" >> "$cluster/pg_hba.conf"
+echo "hostssl all all ${var.private_cidr} md5" >> "$cluster/pg_hba.conf"
+systemctl restart postgresql
+
+sudo -u postgres psql -c "CREATE ROLE \"${var.db_username}\" WITH LOGIN CREATEDB CREATEROLE PASSWORD '${var.db_password}'"
+sudo -u postgres psql -c "CREATE DATABASE \"${var.db_name}\" OWNER \"${var.db_username}\""
+EOT
+}
+
+output "network_id" {
+  value = "${openstack_networking_network_v2.default.id}"
+}
+
+output "public_subnet_id" {
+  value = "${openstack_networking_subnet_v2.public.id}"
+}
+
+output "private_subnet_id" {
+  value = "${openstack_networking_subnet_v2.private.id}"
+}
+
+output "director_public_ip" {
+  value = "${openstack_networking_floatingip_v2.director.address}"
+}
+
+output "atc_public_ip" {
+  value = "${openstack_networking_floatingip_v2.atc.address}"
+}
+
+output "nat_gateway_ip" {
+  value = "${openstack_networking_router_v2.default.external_fixed_ip.0.ip_address}"
+}
+
+output "director_security_group_id" {
+  value = "${openstack_networking_secgroup_v2.director.id}"
+}
+
+output "atc_security_group_name" {
+  value = "${openstack_networking_secgroup_v2.atc.name}"
+}
+
+output "vms_security_group_name" {
+  value = "${openstack_networking_secgroup_v2.vms.name}"
+}
+
+output "bosh_db_address" {
+  value = "${openstack_networking_port_v2.db.all_fixed_ips.0}"
+}
+
+output "db_ca_cert" {
+  value = "${tls_self_signed_cert.db.cert_pem}"
+}
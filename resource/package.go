@@ -29,6 +29,14 @@ var (
 	AWSCPI = ID{"cpi"}
 	// AWSStemcell statically defines stemcell string
 	AWSStemcell = ID{"stemcell"}
+	// AzureCPI statically defines azure-cpi string
+	AzureCPI = ID{"azure-cpi"}
+	// AzureStemcell statically defines azure-stemcell string
+	AzureStemcell = ID{"azure-stemcell"}
+	// OpenStackCPI statically defines openstack-cpi string
+	OpenStackCPI = ID{"openstack-cpi"}
+	// OpenStackStemcell statically defines openstack-stemcell string
+	OpenStackStemcell = ID{"openstack-stemcell"}
 	// BOSHRelease statically defines bosh string
 	BOSHRelease = ID{"bosh"}
 	// BPMRelease statically defines bpm string
@@ -54,12 +62,32 @@ var (
 	// GCPDirectorCustomOps statically defines custom-ops.yml contents
 	GCPDirectorCustomOps = mustAssetString("assets/gcp/custom-ops.yml")
 
+	// AzureDirectorCloudConfig statically defines azure cloud-config.yml
+	AzureDirectorCloudConfig = mustAssetString("assets/azure/cloud-config.yml")
+	// AzureCPIOps statically defines azure-cpi.yml contents
+	AzureCPIOps = mustAssetString("assets/azure/cpi.yml")
+	// AzureDirectorCustomOps statically defines custom-ops.yml contents
+	AzureDirectorCustomOps = mustAssetString("assets/azure/custom-ops.yml")
+
+	// OpenStackDirectorCloudConfig statically defines openstack cloud-config.yml
+	OpenStackDirectorCloudConfig = mustAssetString("assets/openstack/cloud-config.yml")
+	// OpenStackCPIOps statically defines openstack-cpi.yml contents
+	OpenStackCPIOps = mustAssetString("assets/openstack/cpi.yml")
+	// OpenStackDirectorCustomOps statically defines custom-ops.yml contents
+	OpenStackDirectorCustomOps = mustAssetString("assets/openstack/custom-ops.yml")
+
 	// AWSTerraformConfig holds the terraform conf for AWS
 	AWSTerraformConfig = mustAssetString("assets/aws/infrastructure.tf")
 
 	// GCPTerraformConfig holds the terraform conf for GCP
 	GCPTerraformConfig = mustAssetString("assets/gcp/infrastructure.tf")
 
+	// AzureTerraformConfig holds the terraform conf for Azure
+	AzureTerraformConfig = mustAssetString("assets/azure/infrastructure.tf")
+
+	// OpenStackTerraformConfig holds the terraform conf for OpenStack
+	OpenStackTerraformConfig = mustAssetString("assets/openstack/infrastructure.tf")
+
 	// ExternalIPOps statically defines external-ip.yml contents
 	ExternalIPOps = mustAssetString("assets/external-ip.yml")
 	// AWSDirectorCustomOps statically defines custom-ops.yml contents
@@ -71,6 +99,12 @@ var (
 	// GCPReleaseVersions carries all versions of releases
 	GCPReleaseVersions = mustAssetString("../../concourse-up-ops/ops/versions-gcp.json")
 
+	// AzureReleaseVersions carries all versions of releases
+	AzureReleaseVersions = mustAssetString("../../concourse-up-ops/ops/versions-azure.json")
+
+	// OpenStackReleaseVersions carries all versions of releases
+	OpenStackReleaseVersions = mustAssetString("../../concourse-up-ops/ops/versions-openstack.json")
+
 	// AddNewCa carries the ops file that adds a new CA required for cert rotation
 	AddNewCa = mustAssetString("assets/maintenance/add-new-ca.yml")
 
@@ -6,23 +6,41 @@ import (
 	"errors"
 	"reflect"
 
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/resource"
 	"github.com/EngineerBetter/concourse-up/util"
 	"github.com/asaskevich/govalidator"
 )
 
+func init() {
+	Register(iaas.AWS, IAAS{
+		Config:    resource.AWSTerraformConfig,
+		Outputs:   func() Outputs { return &AWSOutputs{} },
+		LockTable: true,
+	})
+}
+
 // InputVars holds all the parameters AWS IAAS needs
 type AWSInputVars struct {
 	AllowIPs               string
 	AvailabilityZone       string
 	ConfigBucket           string
+	DBHighAvailability     bool
 	Deployment             string
+	ExtraZones             []AWSZone
 	HostedZoneID           string
 	HostedZoneRecordPrefix string
-	Namespace              string
-	NetworkCIDR            string
-	PrivateCIDR            string
+	// LockTable is the DynamoDB table terraform locks its state with, or empty to run without a lock
+	LockTable   string
+	Namespace   string
+	NetworkCIDR string
+	Private     bool
+	PrivateCIDR string
+	// PrivateSubnetID, PublicSubnetID and RDSSubnetIDs are existing subnets of VPCID, used instead of creating them
+	PrivateSubnetID        string
 	Project                string
 	PublicCIDR             string
+	PublicSubnetID         string
 	PublicKey              string
 	RDSDefaultDatabaseName string
 	RDSInstanceClass       string
@@ -30,9 +48,28 @@ type AWSInputVars struct {
 	RDSUsername            string
 	RDS1CIDR               string
 	RDS2CIDR               string
+	RDSSubnetIDs           []string
 	Region                 string
 	SourceAccessIP         string
 	TFStatePath            string
+	// VPCID is the existing VPC to deploy into, or empty to create one
+	VPCID string
+}
+
+// AWSZone is an availability zone, besides AvailabilityZone, with its own private subnet for workers
+type AWSZone struct {
+	Name        string
+	PrivateCIDR string
+}
+
+// SecretVars returns the RDS instance password
+func (v *AWSInputVars) SecretVars() map[string]string {
+	return map[string]string{"rds_instance_password": v.RDSPassword}
+}
+
+// DeploymentKey returns the config bucket, which is unique to the deployment
+func (v *AWSInputVars) DeploymentKey() string {
+	return v.ConfigBucket
 }
 
 // ConfigureTerraform interpolates terraform contents and returns terraform config
@@ -63,12 +100,15 @@ type AWSOutputs struct {
 	DirectorKeyPair          MetadataStringValue `json:"director_key_pair" valid:"required"`
 	DirectorPublicIP         MetadataStringValue `json:"director_public_ip" valid:"required"`
 	DirectorSecurityGroupID  MetadataStringValue `json:"director_security_group_id" valid:"required"`
+	JumpboxPublicIP          MetadataStringValue `json:"jumpbox_public_ip"`
+	JumpboxSecurityGroupID   MetadataStringValue `json:"jumpbox_security_group_id"`
 	NatGatewayIP             MetadataStringValue `json:"nat_gateway_ip" valid:"required"`
 	PrivateSubnetID          MetadataStringValue `json:"private_subnet_id" valid:"required"`
 	PublicSubnetID           MetadataStringValue `json:"public_subnet_id" valid:"required"`
 	SourceAccessIP           MetadataStringValue `json:"source_access_ip"`
 	VMsSecurityGroupID       MetadataStringValue `json:"vms_security_group_id" valid:"required"`
 	VPCID                    MetadataStringValue `json:"vpc_id" valid:"required"`
+	ZonePrivateSubnetIDs     MetadataStringValue `json:"zone_private_subnet_ids"`
 }
 
 // AssertValid returns an error if the struct contains any missing fields
@@ -3,8 +3,10 @@ package terraform_test
 import (
 	"bytes"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/EngineerBetter/concourse-up/resource"
 	. "github.com/EngineerBetter/concourse-up/terraform"
 )
 
@@ -149,3 +151,153 @@ func TestAWSMetadata_Init(t *testing.T) {
 		})
 	}
 }
+
+func TestAWSInputVars_ConfigureTerraform_Zones(t *testing.T) {
+	v := &AWSInputVars{
+		AvailabilityZone:   "eu-west-1a",
+		DBHighAvailability: true,
+		ExtraZones: []AWSZone{
+			{Name: "eu-west-1b", PrivateCIDR: "10.0.2.0/24"},
+			{Name: "eu-west-1c", PrivateCIDR: "10.0.3.0/24"},
+		},
+	}
+	got, err := v.ConfigureTerraform(resource.AWSTerraformConfig)
+	if err != nil {
+		t.Fatalf("InputVars.ConfigureTerraform() returned error %v", err)
+	}
+	for _, want := range []string{
+		`resource "aws_subnet" "private_eu-west-1b" {`,
+		`cidr_block              = "10.0.3.0/24"`,
+		`resource "aws_route_table_association" "private_eu-west-1c" {`,
+		`value = "${aws_subnet.private_eu-west-1b.id},${aws_subnet.private_eu-west-1c.id}"`,
+		`multi_az               = true`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("InputVars.ConfigureTerraform() did not render %q", want)
+		}
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraform_Private(t *testing.T) {
+	tests := []struct {
+		name     string
+		private  bool
+		want     []string
+		dontWant []string
+	}{
+		{
+			name:    "Private deployments are reached through a jumpbox",
+			private: true,
+			want: []string{
+				`resource "aws_instance" "jumpbox" {`,
+				`security_groups = ["${aws_security_group.jumpbox.id}"]`,
+				`value = "${cidrhost(var.private_cidr, 6)}"`,
+				`value = "${aws_eip.jumpbox.public_ip}"`,
+				`cidr_blocks = ["${var.source_access_ip}/32", "${local.nat_ip}/32"]`,
+				`ubuntu-jammy-22.04-amd64-server-*`,
+			},
+			dontWant: []string{
+				`resource "aws_eip" "director" {`,
+				`resource "aws_eip" "atc" {`,
+			},
+		},
+		{
+			name:    "Public deployments give the director and ATC public IPs",
+			private: false,
+			want: []string{
+				`resource "aws_eip" "director" {`,
+				`resource "aws_eip" "atc" {`,
+				`value = "${aws_eip.director.public_ip}"`,
+			},
+			dontWant: []string{
+				`resource "aws_instance" "jumpbox" {`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &AWSInputVars{Private: tt.private}
+			got, err := v.ConfigureTerraform(resource.AWSTerraformConfig)
+			if err != nil {
+				t.Fatalf("InputVars.ConfigureTerraform() returned error %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("InputVars.ConfigureTerraform() did not render %q", want)
+				}
+			}
+			for _, dontWant := range tt.dontWant {
+				if strings.Contains(got, dontWant) {
+					t.Errorf("InputVars.ConfigureTerraform() rendered %q", dontWant)
+				}
+			}
+		})
+	}
+}
+
+func TestAWSInputVars_ConfigureTerraform_ExistingVPC(t *testing.T) {
+	tests := []struct {
+		name     string
+		vars     AWSInputVars
+		want     []string
+		dontWant []string
+	}{
+		{
+			name: "An existing VPC and its subnets are looked up",
+			vars: AWSInputVars{
+				VPCID:           "vpc-123",
+				PublicSubnetID:  "subnet-public",
+				PrivateSubnetID: "subnet-private",
+				RDSSubnetIDs:    []string{"subnet-rds-a", "subnet-rds-b"},
+			},
+			want: []string{
+				`data "aws_vpc" "default" {`,
+				`id = "vpc-123"`,
+				`subnet_id = "subnet-public"`,
+				`rds_subnet_ids    = ["subnet-rds-a", "subnet-rds-b"]`,
+				`depends_on = ["data.aws_nat_gateway.default", "aws_eip.atc"]`,
+			},
+			dontWant: []string{
+				`resource "aws_vpc" "default" {`,
+				`resource "aws_subnet" "public" {`,
+				`resource "aws_subnet" "private" {`,
+				`resource "aws_subnet" "rds_a" {`,
+				`resource "aws_nat_gateway" "default" {`,
+				`resource "aws_eip" "nat" {`,
+				`resource "aws_route_table" "private" {`,
+				`aws_internet_gateway.default`,
+			},
+		},
+		{
+			name: "Without an existing VPC one is created",
+			want: []string{
+				`resource "aws_vpc" "default" {`,
+				`resource "aws_subnet" "rds_b" {`,
+				`resource "aws_eip" "nat" {`,
+				`vpc_id            = "${aws_vpc.default.id}"`,
+			},
+			dontWant: []string{
+				`data "aws_vpc" "default" {`,
+				`data "aws_nat_gateway" "default" {`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.vars.ConfigureTerraform(resource.AWSTerraformConfig)
+			if err != nil {
+				t.Fatalf("InputVars.ConfigureTerraform() returned error %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("InputVars.ConfigureTerraform() did not render %q", want)
+				}
+			}
+			for _, dontWant := range tt.dontWant {
+				if strings.Contains(got, dontWant) {
+					t.Errorf("InputVars.ConfigureTerraform() rendered %q", dontWant)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package terraform
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/resource"
+	"github.com/EngineerBetter/concourse-up/util"
+	"github.com/asaskevich/govalidator"
+)
+
+func init() {
+	Register(iaas.Azure, IAAS{
+		Config:  resource.AzureTerraformConfig,
+		Outputs: func() Outputs { return &AzureOutputs{} },
+	})
+}
+
+// AzureInputVars holds all the parameters Azure IAAS needs
+type AzureInputVars struct {
+	AllowIPs     string
+	ConfigBucket string
+	DBName       string
+	DBPassword   string
+	// DBSKU is an Azure Database for PostgreSQL SKU such as B_Gen5_1
+	DBSKU                string
+	DBUsername           string
+	Deployment           string
+	DNSRecordPrefix      string
+	DNSZoneName          string
+	DNSZoneResourceGroup string
+	Namespace            string
+	PrivateCIDR          string
+	PublicCIDR           string
+	PublicKey            string
+	Region               string
+	SourceAccessIP       string
+	StorageAccount       string
+	StorageResourceGroup string
+}
+
+// SecretVars returns the administrator password of the PostgreSQL server
+func (v *AzureInputVars) SecretVars() map[string]string {
+	return map[string]string{"db_password": v.DBPassword}
+}
+
+// DeploymentKey returns the config bucket, which is unique to the deployment
+func (v *AzureInputVars) DeploymentKey() string {
+	return v.ConfigBucket
+}
+
+// ConfigureTerraform interpolates terraform contents and returns terraform config
+func (v *AzureInputVars) ConfigureTerraform(terraformContents string) (string, error) {
+	terraformConfig, err := util.RenderTemplate("terraform", terraformContents, v)
+	if terraformConfig == nil {
+		return "", err
+	}
+	return string(terraformConfig), err
+}
+
+// DBTier returns the pricing tier of DBSKU
+func (v *AzureInputVars) DBTier() string {
+	switch v.dbSKUPart(0) {
+	case "B":
+		return "Basic"
+	case "MO":
+		return "MemoryOptimized"
+	}
+	return "GeneralPurpose"
+}
+
+// DBFamily returns the compute generation of DBSKU
+func (v *AzureInputVars) DBFamily() string {
+	return v.dbSKUPart(1)
+}
+
+// DBCapacity returns the number of vCores of DBSKU
+func (v *AzureInputVars) DBCapacity() string {
+	return v.dbSKUPart(2)
+}
+
+func (v *AzureInputVars) dbSKUPart(i int) string {
+	parts := strings.Split(v.DBSKU, "_")
+	if len(parts) != 3 {
+		return ""
+	}
+	return parts[i]
+}
+
+// AzureOutputs represents output from terraform on Azure
+type AzureOutputs struct {
+	ATCPublicIP               MetadataStringValue `json:"atc_public_ip" valid:"required"`
+	ATCSecurityGroupName      MetadataStringValue `json:"atc_security_group_name" valid:"required"`
+	BoshDBAddress             MetadataStringValue `json:"bosh_db_address" valid:"required"`
+	DirectorPublicIP          MetadataStringValue `json:"director_public_ip" valid:"required"`
+	DirectorSecurityGroupID   MetadataStringValue `json:"director_security_group_id" valid:"required"`
+	DirectorSecurityGroupName MetadataStringValue `json:"director_security_group_name" valid:"required"`
+	NatGatewayIP              MetadataStringValue `json:"nat_gateway_ip" valid:"required"`
+	Network                   MetadataStringValue `json:"network" valid:"required"`
+	PrivateSubnetName         MetadataStringValue `json:"private_subnet_name" valid:"required"`
+	PublicSubnetName          MetadataStringValue `json:"public_subnet_name" valid:"required"`
+	ResourceGroup             MetadataStringValue `json:"resource_group" valid:"required"`
+	VMsSecurityGroupName      MetadataStringValue `json:"vms_security_group_name" valid:"required"`
+}
+
+// AssertValid returns an error if the struct contains any missing fields
+func (outputs *AzureOutputs) AssertValid() error {
+	_, err := govalidator.ValidateStruct(outputs)
+	return err
+}
+
+// Init populates outputs struct with values from the buffer
+func (outputs *AzureOutputs) Init(buffer *bytes.Buffer) error {
+	if err := json.NewDecoder(buffer).Decode(&outputs); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Get returns a the specified value from the outputs struct
+func (outputs *AzureOutputs) Get(key string) (string, error) {
+	reflectValue := reflect.ValueOf(outputs)
+	reflectStruct := reflectValue.Elem()
+	value := reflectStruct.FieldByName(key)
+	if !value.IsValid() {
+		return "", errors.New(key + " key not found")
+	}
+
+	return value.FieldByName("Value").String(), nil
+}
@@ -0,0 +1,97 @@
+package terraform_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/EngineerBetter/concourse-up/resource"
+	. "github.com/EngineerBetter/concourse-up/terraform"
+)
+
+func TestAzureInputVars_ConfigureTerraform(t *testing.T) {
+	tests := []struct {
+		name     string
+		vars     AzureInputVars
+		want     []string
+		dontWant []string
+	}{
+		{
+			name: "The database SKU is split into tier, family and capacity",
+			vars: AzureInputVars{DBSKU: "GP_Gen5_4", StorageAccount: "concourseupabc"},
+			want: []string{
+				`storage_account_name = "concourseupabc"`,
+				`name     = "GP_Gen5_4"`,
+				`tier     = "GeneralPurpose"`,
+				`family   = "Gen5"`,
+				`capacity = 4`,
+			},
+			dontWant: []string{
+				`resource "azurerm_dns_a_record" "dns" {`,
+			},
+		},
+		{
+			name: "A record is added to the DNS zone",
+			vars: AzureInputVars{
+				DBSKU:                "B_Gen5_1",
+				DNSRecordPrefix:      "ci",
+				DNSZoneName:          "example.com",
+				DNSZoneResourceGroup: "dns",
+			},
+			want: []string{
+				`tier     = "Basic"`,
+				`resource "azurerm_dns_a_record" "dns" {`,
+				`default = "example.com"`,
+				`default = "ci"`,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.vars.ConfigureTerraform(resource.AzureTerraformConfig)
+			if err != nil {
+				t.Fatalf("InputVars.ConfigureTerraform() returned error %v", err)
+			}
+			for _, want := range test.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("InputVars.ConfigureTerraform() did not render %q", want)
+				}
+			}
+			for _, dontWant := range test.dontWant {
+				if strings.Contains(got, dontWant) {
+					t.Errorf("InputVars.ConfigureTerraform() rendered %q", dontWant)
+				}
+			}
+		})
+	}
+}
+
+func TestAzureMetadata_Get(t *testing.T) {
+	outputs := &AzureOutputs{
+		ResourceGroup: MetadataStringValue{Value: "fakeResourceGroup"},
+	}
+	got, err := outputs.Get("ResourceGroup")
+	if err != nil {
+		t.Errorf("Metadata.Get() returned error %v", err)
+	}
+	if got != "fakeResourceGroup" {
+		t.Errorf("Metadata.Get() returned %q, expected %q", got, "fakeResourceGroup")
+	}
+	if _, err = outputs.Get("FakeKey"); err == nil {
+		t.Error("Metadata.Get() did not return an error for an unknown key")
+	}
+}
+
+func TestAzureMetadata_Init(t *testing.T) {
+	outputs := &AzureOutputs{}
+	buffer := bytes.NewBufferString(`{"director_public_ip":{"sensitive":false,"type": "string","value": "fakeIP"}}`)
+	if err := outputs.Init(buffer); err != nil {
+		t.Fatalf("Metadata.Init() returned error %v", err)
+	}
+	if outputs.DirectorPublicIP.Value != "fakeIP" {
+		t.Errorf("Metadata.Init() set DirectorPublicIP to %q, expected %q", outputs.DirectorPublicIP.Value, "fakeIP")
+	}
+	if err := outputs.AssertValid(); err == nil {
+		t.Error("Metadata.AssertValid() did not return an error for missing outputs")
+	}
+}
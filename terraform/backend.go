@@ -0,0 +1,62 @@
+package terraform
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Backend is a terraform backend which keeps the state somewhere other than the config bucket
+type Backend struct {
+	// Type is the terraform backend type, e.g. local or s3
+	Type string
+	// Config is written to the backend block
+	Config map[string]string
+	// Env holds the credentials of the backend, which are passed to every terraform command in its environment,
+	// so that they are neither written to the working directory nor shown on the command line
+	Env map[string]string
+}
+
+// StateBackend returns an Option which keeps the state of each deployment in the backend backendFor returns for
+// the deployment's config bucket name, instead of in the config bucket. backendFor may return nil to use the bucket
+func StateBackend(backendFor func(key string) (*Backend, error)) Option {
+	return func(c *CLI) error {
+		c.backendFor = backendFor
+		return nil
+	}
+}
+
+var backendBlock = regexp.MustCompile(`(?s)terraform\s*\{\s*backend\s+"[^"]*"\s*\{[^{}]*\}\s*\}`)
+
+// configure replaces the backend block in tfConfig with b's
+func (b *Backend) configure(tfConfig string) (string, error) {
+	if !backendBlock.MatchString(tfConfig) {
+		return "", fmt.Errorf("could not find the terraform backend block to replace with the %s backend", b.Type)
+	}
+	var block strings.Builder
+	fmt.Fprintf(&block, "terraform {\n\tbackend %q {\n", b.Type)
+	for _, key := range sortedKeys(b.Config) {
+		fmt.Fprintf(&block, "\t\t%s = %q\n", key, b.Config[key])
+	}
+	block.WriteString("\t}\n}")
+	return backendBlock.ReplaceAllLiteralString(tfConfig, block.String()), nil
+}
+
+// env returns b's Env in the form of exec.Cmd.Env
+func (b *Backend) env() []string {
+	var env []string
+	for _, name := range sortedKeys(b.Env) {
+		env = append(env, fmt.Sprintf("%s=%s", name, b.Env[name]))
+	}
+	return env
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,45 @@
+package terraform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackend_Configure(t *testing.T) {
+	tfConfig := `terraform {
+	backend "s3" {
+		bucket = "concourse-up-test-eu-west-1-config"
+		key    = "terraform.tfstate"
+		region = "eu-west-1"
+		dynamodb_table = "concourse-up-test-eu-west-1-config-terraform-lock"
+	}
+}
+
+variable "region" {
+  type = "string"
+}
+`
+	backend := &Backend{
+		Type:   "local",
+		Config: map[string]string{"path": "/state/terraform.tfstate"},
+		Env:    map[string]string{"AWS_SECRET_ACCESS_KEY": "secret", "AWS_ACCESS_KEY_ID": "access"},
+	}
+
+	configured, err := backend.configure(tfConfig)
+	require.NoError(t, err)
+	require.Equal(t, `terraform {
+	backend "local" {
+		path = "/state/terraform.tfstate"
+	}
+}
+
+variable "region" {
+  type = "string"
+}
+`, configured)
+	require.Equal(t, []string{"AWS_ACCESS_KEY_ID=access", "AWS_SECRET_ACCESS_KEY=secret"}, backend.env())
+
+	_, err = backend.configure(`variable "region" {}`)
+	require.EqualError(t, err, "could not find the terraform backend block to replace with the local backend")
+}
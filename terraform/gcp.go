@@ -6,14 +6,24 @@ import (
 	"errors"
 	"reflect"
 
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/resource"
 	"github.com/EngineerBetter/concourse-up/util"
 	"github.com/asaskevich/govalidator"
 )
 
+func init() {
+	Register(iaas.GCP, IAAS{
+		Config:  resource.GCPTerraformConfig,
+		Outputs: func() Outputs { return &GCPOutputs{} },
+	})
+}
+
 // InputVars holds all the parameters GCP IAAS needs
 type GCPInputVars struct {
 	AllowIPs           string
 	ConfigBucket       string
+	DBHighAvailability bool
 	DBName             string
 	DBPassword         string
 	DBTier             string
@@ -24,12 +34,27 @@ type GCPInputVars struct {
 	ExternalIP         string
 	GCPCredentialsJSON string
 	Namespace          string
-	PrivateCIDR        string
-	Project            string
-	PublicCIDR         string
-	Region             string
-	Tags               string
-	Zone               string
+	// Network is the existing network to deploy into, with the existing subnetworks PublicSubnetwork
+	// and PrivateSubnetwork, or empty to create them
+	Network           string
+	PrivateCIDR       string
+	PrivateSubnetwork string
+	Project           string
+	PublicCIDR        string
+	PublicSubnetwork  string
+	Region            string
+	Tags              string
+	Zone              string
+}
+
+// SecretVars returns the password of the Cloud SQL user
+func (v *GCPInputVars) SecretVars() map[string]string {
+	return map[string]string{"db_password": v.DBPassword}
+}
+
+// DeploymentKey returns the config bucket, which is unique to the deployment
+func (v *GCPInputVars) DeploymentKey() string {
+	return v.ConfigBucket
 }
 
 // ConfigureTerraform interpolates terraform contents and returns terraform config
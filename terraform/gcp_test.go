@@ -3,8 +3,10 @@ package terraform_test
 import (
 	"bytes"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/EngineerBetter/concourse-up/resource"
 	. "github.com/EngineerBetter/concourse-up/terraform"
 )
 
@@ -155,3 +157,33 @@ func TestGCPMetadata_Init(t *testing.T) {
 		})
 	}
 }
+
+func TestGCPInputVars_ConfigureTerraform_ExistingNetwork(t *testing.T) {
+	v := &GCPInputVars{
+		Network:           "shared",
+		PublicSubnetwork:  "shared-public",
+		PrivateSubnetwork: "shared-private",
+	}
+	got, err := v.ConfigureTerraform(resource.GCPTerraformConfig)
+	if err != nil {
+		t.Fatalf("InputVars.ConfigureTerraform() returned error %v", err)
+	}
+	for _, want := range []string{
+		`data "google_compute_network" "default" {`,
+		`name    = "shared"`,
+		`name    = "shared-private"`,
+		`network     = "${local.network}"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("InputVars.ConfigureTerraform() did not render %q", want)
+		}
+	}
+	for _, dontWant := range []string{
+		`resource "google_compute_network" "default" {`,
+		`resource "google_compute_subnetwork" "public" {`,
+	} {
+		if strings.Contains(got, dontWant) {
+			t.Errorf("InputVars.ConfigureTerraform() rendered %q", dontWant)
+		}
+	}
+}
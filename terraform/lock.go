@@ -0,0 +1,68 @@
+package terraform
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// LockTableCreator creates the DynamoDB table the AWS backend locks the state with
+type LockTableCreator interface {
+	CreateLockTable(name string) error
+}
+
+// LockTables returns an Option which lets CreateLockTable create the AWS lock table with creator
+func LockTables(creator LockTableCreator) Option {
+	return func(c *CLI) error {
+		c.lockTables = creator
+		return nil
+	}
+}
+
+// LockTableName returns the name of the DynamoDB table which locks the terraform state kept in configBucket
+func LockTableName(configBucket string) string {
+	return configBucket + "-terraform-lock"
+}
+
+// LockError is returned when terraform cannot run because another run holds the lock on its state
+type LockError struct {
+	ID        string
+	Operation string
+	Who       string
+	Created   string
+}
+
+func (e *LockError) Error() string {
+	return fmt.Sprintf("the terraform state is locked by %s, running %s since %s. If that is no longer running, release the lock with `concourse-up unlock --terraform-lock-id %s <name>`", e.Who, e.Operation, e.Created, e.ID)
+}
+
+var lockInfoLine = regexp.MustCompile(`(?m)^\s*(ID|Operation|Who|Created):\s*(.*?)\s*$`)
+
+// parseLockError returns a *LockError if terraform's stderr shows it failed to acquire the state lock, or nil
+func parseLockError(stderr string) *LockError {
+	if !strings.Contains(stderr, "Error acquiring the state lock") {
+		return nil
+	}
+	lockInfo := stderr
+	if i := strings.Index(stderr, "Lock Info:"); i != -1 {
+		lockInfo = stderr[i:]
+	}
+
+	lockErr := &LockError{}
+	for _, match := range lockInfoLine.FindAllStringSubmatch(lockInfo, -1) {
+		switch match[1] {
+		case "ID":
+			lockErr.ID = match[2]
+		case "Operation":
+			lockErr.Operation = strings.ToLower(strings.TrimPrefix(match[2], "OperationType"))
+		case "Who":
+			lockErr.Who = match[2]
+		case "Created":
+			lockErr.Created = match[2]
+		}
+	}
+	if lockErr.ID == "" {
+		return nil
+	}
+	return lockErr
+}
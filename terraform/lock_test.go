@@ -0,0 +1,69 @@
+package terraform
+
+import (
+	"testing"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLockError(t *testing.T) {
+	stderr := `
+Error: Error locking state: Error acquiring the state lock: ConditionalCheckFailedException: The conditional request failed
+	status code: 400, request id: 0123456789
+Lock Info:
+  ID:        2a8cd1b4-6c5c-44e1-8d65-1a2b3c4d5e6f
+  Path:      concourse-up-test-eu-west-1-config/terraform.tfstate
+  Operation: OperationTypeApply
+  Who:       ci@self-update
+  Version:   0.11.10
+  Created:   2019-03-01 12:00:00.000000 +0000 UTC
+  Info:
+
+Terraform acquires a state lock to protect the state from being written
+by multiple users at the same time.
+`
+	lockErr := parseLockError(stderr)
+	require.NotNil(t, lockErr)
+	require.Equal(t, LockError{
+		ID:        "2a8cd1b4-6c5c-44e1-8d65-1a2b3c4d5e6f",
+		Operation: "apply",
+		Who:       "ci@self-update",
+		Created:   "2019-03-01 12:00:00.000000 +0000 UTC",
+	}, *lockErr)
+	require.Contains(t, lockErr.Error(), "concourse-up unlock --terraform-lock-id 2a8cd1b4-6c5c-44e1-8d65-1a2b3c4d5e6f")
+
+	require.Nil(t, parseLockError("Error: aws_instance.director: InvalidParameterValue"))
+}
+
+type fakeLockTableCreator []string
+
+func (f *fakeLockTableCreator) CreateLockTable(name string) error {
+	*f = append(*f, name)
+	return nil
+}
+
+func TestCLI_CreateLockTable(t *testing.T) {
+	creator := &fakeLockTableCreator{}
+	cli, err := New(iaas.AWS, LockTables(creator))
+	require.NoError(t, err)
+
+	name, err := cli.CreateLockTable(&AWSInputVars{ConfigBucket: "concourse-up-test-eu-west-1-config"})
+	require.NoError(t, err)
+	require.Equal(t, "concourse-up-test-eu-west-1-config-terraform-lock", name)
+
+	gcpCLI, err := New(iaas.GCP, LockTables(creator))
+	require.NoError(t, err)
+	name, err = gcpCLI.CreateLockTable(&GCPInputVars{ConfigBucket: "concourse-up-test-europe-west1-config"})
+	require.NoError(t, err)
+	require.Empty(t, name)
+
+	cli.backendFor = func(key string) (*Backend, error) {
+		return &Backend{Type: "local"}, nil
+	}
+	name, err = cli.CreateLockTable(&AWSInputVars{ConfigBucket: "concourse-up-test-eu-west-1-config"})
+	require.NoError(t, err)
+	require.Empty(t, name)
+
+	require.Equal(t, []string{"concourse-up-test-eu-west-1-config-terraform-lock"}, []string(*creator))
+}
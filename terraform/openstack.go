@@ -0,0 +1,104 @@
+package terraform
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/resource"
+	"github.com/EngineerBetter/concourse-up/util"
+	"github.com/asaskevich/govalidator"
+)
+
+func init() {
+	Register(iaas.OpenStack, IAAS{
+		Config:  resource.OpenStackTerraformConfig,
+		Outputs: func() Outputs { return &OpenStackOutputs{} },
+	})
+}
+
+// OpenStackInputVars holds all the parameters OpenStack IAAS needs
+type OpenStackInputVars struct {
+	AllowIPs     string
+	ConfigBucket string
+	DBFlavor     string
+	// DBImage is the name of the Ubuntu image the Postgres VM boots from
+	DBImage    string
+	DBName     string
+	DBPassword string
+	DBUsername string
+	Deployment string
+	// DNSRecordName is the fully qualified name of the A record of the ATC, such as ci.example.com.
+	DNSRecordName   string
+	DNSZoneID       string
+	ExternalNetwork string
+	Namespace       string
+	PrivateCIDR     string
+	PublicCIDR      string
+	PublicKey       string
+	Region          string
+	SourceAccessIP  string
+}
+
+// SecretVars returns the password of the database role
+func (v *OpenStackInputVars) SecretVars() map[string]string {
+	return map[string]string{"db_password": v.DBPassword}
+}
+
+// DeploymentKey returns the config bucket, which is unique to the deployment
+func (v *OpenStackInputVars) DeploymentKey() string {
+	return v.ConfigBucket
+}
+
+// ConfigureTerraform interpolates terraform contents and returns terraform config
+func (v *OpenStackInputVars) ConfigureTerraform(terraformContents string) (string, error) {
+	terraformConfig, err := util.RenderTemplate("terraform", terraformContents, v)
+	if terraformConfig == nil {
+		return "", err
+	}
+	return string(terraformConfig), err
+}
+
+// OpenStackOutputs represents output from terraform on OpenStack
+type OpenStackOutputs struct {
+	ATCPublicIP             MetadataStringValue `json:"atc_public_ip" valid:"required"`
+	ATCSecurityGroupName    MetadataStringValue `json:"atc_security_group_name" valid:"required"`
+	BoshDBAddress           MetadataStringValue `json:"bosh_db_address" valid:"required"`
+	DBCACert                MetadataStringValue `json:"db_ca_cert" valid:"required"`
+	DirectorPublicIP        MetadataStringValue `json:"director_public_ip" valid:"required"`
+	DirectorSecurityGroupID MetadataStringValue `json:"director_security_group_id" valid:"required"`
+	NatGatewayIP            MetadataStringValue `json:"nat_gateway_ip" valid:"required"`
+	NetworkID               MetadataStringValue `json:"network_id" valid:"required"`
+	PrivateSubnetID         MetadataStringValue `json:"private_subnet_id" valid:"required"`
+	PublicSubnetID          MetadataStringValue `json:"public_subnet_id" valid:"required"`
+	VMsSecurityGroupName    MetadataStringValue `json:"vms_security_group_name" valid:"required"`
+}
+
+// AssertValid returns an error if the struct contains any missing fields
+func (outputs *OpenStackOutputs) AssertValid() error {
+	_, err := govalidator.ValidateStruct(outputs)
+	return err
+}
+
+// Init populates outputs struct with values from the buffer
+func (outputs *OpenStackOutputs) Init(buffer *bytes.Buffer) error {
+	if err := json.NewDecoder(buffer).Decode(&outputs); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Get returns a the specified value from the outputs struct
+func (outputs *OpenStackOutputs) Get(key string) (string, error) {
+	reflectValue := reflect.ValueOf(outputs)
+	reflectStruct := reflectValue.Elem()
+	value := reflectStruct.FieldByName(key)
+	if !value.IsValid() {
+		return "", errors.New(key + " key not found")
+	}
+
+	return value.FieldByName("Value").String(), nil
+}
@@ -0,0 +1,99 @@
+package terraform_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/EngineerBetter/concourse-up/resource"
+	. "github.com/EngineerBetter/concourse-up/terraform"
+)
+
+func TestOpenStackInputVars_ConfigureTerraform(t *testing.T) {
+	tests := []struct {
+		name     string
+		vars     OpenStackInputVars
+		want     []string
+		dontWant []string
+	}{
+		{
+			name: "State is kept in the config bucket and the ATC allows each IP",
+			vars: OpenStackInputVars{
+				AllowIPs:     `"1.2.3.4/32", "5.6.7.0/24"`,
+				ConfigBucket: "concourse-up-ci-RegionOne-config",
+				DBFlavor:     "m1.small",
+				Region:       "RegionOne",
+			},
+			want: []string{
+				`container   = "concourse-up-ci-RegionOne-config"`,
+				`region_name = "RegionOne"`,
+				`default = ["1.2.3.4/32", "5.6.7.0/24"]`,
+				`default = "m1.small"`,
+				`count             = "${length(var.allow_ips)}"`,
+			},
+			dontWant: []string{
+				`resource "openstack_dns_recordset_v2" "dns" {`,
+			},
+		},
+		{
+			name: "A record is added to the Designate zone",
+			vars: OpenStackInputVars{
+				DNSRecordName: "ci.example.com.",
+				DNSZoneID:     "zone-id",
+			},
+			want: []string{
+				`resource "openstack_dns_recordset_v2" "dns" {`,
+				`default = "zone-id"`,
+				`default = "ci.example.com."`,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.vars.ConfigureTerraform(resource.OpenStackTerraformConfig)
+			if err != nil {
+				t.Fatalf("InputVars.ConfigureTerraform() returned error %v", err)
+			}
+			for _, want := range test.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("InputVars.ConfigureTerraform() did not render %q", want)
+				}
+			}
+			for _, dontWant := range test.dontWant {
+				if strings.Contains(got, dontWant) {
+					t.Errorf("InputVars.ConfigureTerraform() rendered %q", dontWant)
+				}
+			}
+		})
+	}
+}
+
+func TestOpenStackMetadata_Get(t *testing.T) {
+	outputs := &OpenStackOutputs{
+		NetworkID: MetadataStringValue{Value: "fakeNetworkID"},
+	}
+	got, err := outputs.Get("NetworkID")
+	if err != nil {
+		t.Errorf("Metadata.Get() returned error %v", err)
+	}
+	if got != "fakeNetworkID" {
+		t.Errorf("Metadata.Get() returned %q, expected %q", got, "fakeNetworkID")
+	}
+	if _, err = outputs.Get("FakeKey"); err == nil {
+		t.Error("Metadata.Get() did not return an error for an unknown key")
+	}
+}
+
+func TestOpenStackMetadata_Init(t *testing.T) {
+	outputs := &OpenStackOutputs{}
+	buffer := bytes.NewBufferString(`{"db_ca_cert":{"sensitive":false,"type": "string","value": "fakeCert"}}`)
+	if err := outputs.Init(buffer); err != nil {
+		t.Fatalf("Metadata.Init() returned error %v", err)
+	}
+	if outputs.DBCACert.Value != "fakeCert" {
+		t.Errorf("Metadata.Init() set DBCACert to %q, expected %q", outputs.DBCACert.Value, "fakeCert")
+	}
+	if err := outputs.AssertValid(); err == nil {
+		t.Error("Metadata.AssertValid() did not return an error for missing outputs")
+	}
+}
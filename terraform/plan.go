@@ -0,0 +1,71 @@
+package terraform
+
+import (
+	"bufio"
+	"regexp"
+	"strings"
+)
+
+// Change is a single resource change proposed by terraform plan
+type Change struct {
+	Action  string `json:"action"`
+	Address string `json:"address"`
+}
+
+// Plan holds the resource changes proposed by terraform plan
+type Plan struct {
+	Changes []Change `json:"changes"`
+}
+
+// HasChanges returns true if terraform proposed any changes
+func (p Plan) HasChanges() bool {
+	return len(p.Changes) > 0
+}
+
+// Destructive returns the changes which destroy or replace existing resources
+func (p Plan) Destructive() []Change {
+	var changes []Change
+	for _, change := range p.Changes {
+		if change.Action == "destroy" || change.Action == "replace" {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+var (
+	// terraform 0.11 prefixes each resource address with a symbol for the action
+	planSymbolLine = regexp.MustCompile(`^\s{0,2}(-/\+|\+/-|<=|\+|-|~)\s+(\S+\.\S+)`)
+	// terraform 0.12 precedes each resource with a comment describing the action
+	planCommentLine = regexp.MustCompile(`^\s*# (\S+) (will be created|will be destroyed|will be updated in-place|must be replaced|will be read during apply)`)
+)
+
+var planActions = map[string]string{
+	"+":                         "create",
+	"-":                         "destroy",
+	"~":                         "update",
+	"-/+":                       "replace",
+	"+/-":                       "replace",
+	"<=":                        "read",
+	"will be created":           "create",
+	"will be destroyed":         "destroy",
+	"will be updated in-place":  "update",
+	"must be replaced":          "replace",
+	"will be read during apply": "read",
+}
+
+func parsePlan(output string) Plan {
+	var plan Plan
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := planCommentLine.FindStringSubmatch(line); m != nil {
+			plan.Changes = append(plan.Changes, Change{Action: planActions[m[2]], Address: m[1]})
+			continue
+		}
+		if m := planSymbolLine.FindStringSubmatch(line); m != nil {
+			plan.Changes = append(plan.Changes, Change{Action: planActions[m[1]], Address: m[2]})
+		}
+	}
+	return plan
+}
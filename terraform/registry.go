@@ -0,0 +1,35 @@
+package terraform
+
+import (
+	"errors"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
+)
+
+// IAAS holds the terraform config of an IAAS and the outputs it produces
+type IAAS struct {
+	// Config is the template that InputVars.ConfigureTerraform renders
+	Config string
+	// Outputs returns empty outputs to read the state into
+	Outputs func() Outputs
+	// LockTable is set when the state kept in the config bucket is locked by a table the CLI's LockTableCreator makes
+	LockTable bool
+}
+
+var iaases = make(map[iaas.Name]IAAS)
+
+// Register makes the terraform config of an IAAS available to the CLI
+func Register(name iaas.Name, i IAAS) {
+	if _, ok := iaases[name]; ok {
+		panic("terraform: Register called twice for " + name.String())
+	}
+	iaases[name] = i
+}
+
+func lookup(name iaas.Name) (IAAS, error) {
+	i, ok := iaases[name]
+	if !ok {
+		return IAAS{}, errors.New("terraform: " + name.String() + " not a valid iaas provider")
+	}
+	return i, nil
+}
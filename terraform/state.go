@@ -0,0 +1,54 @@
+package terraform
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
+)
+
+// ErrUnknownStateFormat is returned by OutputsFromState when it cannot read the state, so terraform output should be used instead
+var ErrUnknownStateFormat = errors.New("unknown terraform state format")
+
+// OutputsFromState returns the outputs of the root module recorded in a terraform state file, without running terraform.
+// States written by terraform 0.11 (version 3) and 0.12 onwards (version 4) can be read.
+func OutputsFromState(name iaas.Name, state []byte) (Outputs, error) {
+	var parsed struct {
+		Version int `json:"version"`
+		Modules []struct {
+			Path    []string        `json:"path"`
+			Outputs json.RawMessage `json:"outputs"`
+		} `json:"modules"`
+		Outputs json.RawMessage `json:"outputs"`
+	}
+	if len(state) == 0 || json.Unmarshal(state, &parsed) != nil {
+		return nil, ErrUnknownStateFormat
+	}
+
+	var rootOutputs json.RawMessage
+	switch parsed.Version {
+	case 3:
+		for _, module := range parsed.Modules {
+			if len(module.Path) == 1 && module.Path[0] == "root" {
+				rootOutputs = module.Outputs
+			}
+		}
+	case 4:
+		rootOutputs = parsed.Outputs
+	default:
+		return nil, ErrUnknownStateFormat
+	}
+	if rootOutputs == nil {
+		rootOutputs = json.RawMessage("{}")
+	}
+
+	outputs, err := outputsFor(name)
+	if err != nil {
+		return nil, err
+	}
+	if err = outputs.Init(bytes.NewBuffer(rootOutputs)); err != nil {
+		return nil, err
+	}
+	return outputs, nil
+}
@@ -0,0 +1,40 @@
+package terraform_test
+
+import (
+	"testing"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/terraform"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputsFromState(t *testing.T) {
+	tests := []struct {
+		name  string
+		state string
+	}{
+		{
+			name:  "terraform 0.11 state",
+			state: `{"version": 3, "modules": [{"path": ["root"], "outputs": {"director_public_ip": {"sensitive": false, "type": "string", "value": "1.2.3.4"}}}]}`,
+		},
+		{
+			name:  "terraform 0.12 state",
+			state: `{"version": 4, "outputs": {"director_public_ip": {"type": "string", "value": "1.2.3.4"}}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputs, err := terraform.OutputsFromState(iaas.AWS, []byte(tt.state))
+			require.NoError(t, err)
+			ip, err := outputs.Get("DirectorPublicIP")
+			require.NoError(t, err)
+			require.Equal(t, "1.2.3.4", ip)
+			require.Error(t, outputs.AssertValid())
+		})
+	}
+
+	for _, state := range []string{"", "not json", `{"version": 5}`} {
+		_, err := terraform.OutputsFromState(iaas.AWS, []byte(state))
+		require.Equal(t, terraform.ErrUnknownStateFormat, err)
+	}
+}
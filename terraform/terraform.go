@@ -3,8 +3,8 @@ package terraform
 import (
 	"bytes"
 	"crypto/rand"
-	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
@@ -17,6 +17,16 @@ import (
 // InputVars exposes ConfigureDirectorManifestCPI
 type InputVars interface {
 	ConfigureTerraform(string) (string, error)
+	// DeploymentKey returns a name unique to the deployment, or "" if the vars do not name one
+	DeploymentKey() string
+}
+
+// SecretInputVars are InputVars with secrets, which are passed to terraform in TF_VAR_ environment variables
+// rather than rendered into the config it is run with, so that they are not written to its working directory
+type SecretInputVars interface {
+	InputVars
+	// SecretVars returns the values of the secret terraform variables by name
+	SecretVars() map[string]string
 }
 
 //go:generate counterfeiter . Outputs
@@ -31,26 +41,30 @@ type Outputs interface {
 //CLIInterface is the abstraction of execCmd
 type CLIInterface interface {
 	Apply(InputVars) error
+	Plan(InputVars) (Plan, error)
 	Destroy(InputVars) error
 	BuildOutput(InputVars) (Outputs, error)
+	ForceUnlock(config InputVars, lockID string) error
+	CreateLockTable(InputVars) (string, error)
 }
 
 // CLI struct holds the abstraction of execCmd
 type CLI struct {
-	execCmd func(string, ...string) *exec.Cmd
-	Path    string
-	iaas    iaas.Name
+	execCmd    func(string, ...string) *exec.Cmd
+	Path       string
+	iaas       iaas.Name
+	lockTables LockTableCreator
+	download   func() (string, error)
+	backendFor func(key string) (*Backend, error)
 }
 
 //Factory function to return iaas-specific outputs
 func outputsFor(name iaas.Name) (Outputs, error) {
-	switch name {
-	case iaas.AWS: // nolint
-		return &AWSOutputs{}, nil
-	case iaas.GCP: // nolint
-		return &GCPOutputs{}, nil
+	i, err := lookup(name)
+	if err != nil {
+		return &NullOutputs{}, err
 	}
-	return &NullOutputs{}, errors.New("terraform: " + name.String() + " not a valid iaas provider")
+	return i.Outputs(), nil
 }
 
 // Option defines the arbitary element of Options for New
@@ -64,12 +78,12 @@ func Path(path string) Option {
 	}
 }
 
-// DownloadTerraform returns the dowloaded CLI path Option
+// DownloadTerraform returns an Option which downloads the CLI the first time it is run,
+// so that commands which only read the outputs from the state do not need it
 func DownloadTerraform() Option {
 	return func(c *CLI) error {
-		path, err := resource.TerraformCLIPath()
-		c.Path = path
-		return err
+		c.download = resource.TerraformCLIPath
+		return nil
 	}
 }
 
@@ -96,6 +110,8 @@ func (n *NullInputVars) ConfigureTerraform(string) (string, error) { return "",
 
 func (n *NullInputVars) Build(map[string]interface{}) error { return nil }
 
+func (n *NullInputVars) DeploymentKey() string { return "" }
+
 type NullOutputs struct{}
 
 func (n *NullOutputs) AssertValid() error { return nil }
@@ -104,85 +120,180 @@ func (n *NullOutputs) Init(*bytes.Buffer) error { return nil }
 
 func (n *NullOutputs) Get(string) (string, error) { return "", nil }
 
-func (c *CLI) init(config InputVars) (string, error) {
-	var (
-		tfConfig string
-		err      error
-	)
-	switch c.iaas {
-	case iaas.AWS: // nolint
-		tfConfig, err = config.ConfigureTerraform(resource.AWSTerraformConfig)
+func (c *CLI) init(config InputVars) (workingDir, error) {
+	if c.download != nil {
+		path, err := c.download()
 		if err != nil {
-			return "", err
+			return workingDir{}, err
 		}
-	case iaas.GCP: // nolint
-		tfConfig, err = config.ConfigureTerraform(resource.GCPTerraformConfig)
-		if err != nil {
-			return "", err
+		c.Path, c.download = path, nil
+	}
+
+	i, err := lookup(c.iaas)
+	if err != nil {
+		return workingDir{}, err
+	}
+	tfConfig, err := config.ConfigureTerraform(i.Config)
+	if err != nil {
+		return workingDir{}, err
+	}
+
+	var backend *Backend
+	if c.backendFor != nil {
+		if backend, err = c.backendFor(config.DeploymentKey()); err != nil {
+			return workingDir{}, err
+		}
+	}
+	if backend != nil {
+		if tfConfig, err = backend.configure(tfConfig); err != nil {
+			return workingDir{}, err
 		}
 	}
 
-	terraformConfigPath, err := writeTempFile([]byte(tfConfig))
+	dir, initialised, err := prepareWorkingDir(config, []byte(tfConfig), c.Path)
 	if err != nil {
-		return "", err
+		return workingDir{}, err
+	}
+	dir.env = secretEnv(config)
+	if backend != nil {
+		dir.env = append(dir.env, backend.env()...)
+	}
+	if initialised {
+		return dir, nil
 	}
-	cmd := c.execCmd(c.Path, "init")
-	cmd.Dir = terraformConfigPath
+	// The state is kept in the backend, so any backend configuration saved by an earlier init is replaced
+	// rather than migrated, dropping credentials earlier versions passed to it
+	cmd := c.command(dir, "init", "-reconfigure")
 	cmd.Stderr = os.Stderr
 	err = cmd.Run()
 	if err != nil {
-		os.RemoveAll(terraformConfigPath)
+		dir.release()
+		return workingDir{}, err
+	}
+	return dir, dir.markInitialised()
+}
+
+// CreateLockTable creates the DynamoDB table which locks the state of an AWS deployment kept in its config bucket,
+// unless it exists, and returns its name. It returns "" when the state needs no table, because it is kept in a
+// custom backend or on another IaaS, which lock it themselves
+func (c *CLI) CreateLockTable(config InputVars) (string, error) {
+	i, err := lookup(c.iaas)
+	if err != nil || !i.LockTable || c.lockTables == nil {
 		return "", err
 	}
-	return terraformConfigPath, nil
+	if c.backendFor != nil {
+		backend, err := c.backendFor(config.DeploymentKey())
+		if err != nil || backend != nil {
+			return "", err
+		}
+	}
+	name := LockTableName(config.DeploymentKey())
+	if err := c.lockTables.CreateLockTable(name); err != nil {
+		return "", fmt.Errorf("Error creating terraform lock table: [%v]", err)
+	}
+	return name, nil
+}
+
+// command returns a terraform command which runs in dir and shares the provider plugin cache
+func (c *CLI) command(dir workingDir, args ...string) *exec.Cmd {
+	cmd := c.execCmd(c.Path, args...)
+	cmd.Dir = dir.path
+	cmd.Env = append(pluginCacheEnv(cmd.Env), dir.env...)
+	return cmd
 }
 
 // Apply runs terraform apply for a given config
 func (c *CLI) Apply(config InputVars) error {
-	terraformConfigPath, err := c.init(config)
+	dir, err := c.init(config)
 	if err != nil {
 		return err
 	}
 
-	defer os.RemoveAll(terraformConfigPath)
+	defer dir.release()
 
-	cmd := c.execCmd(c.Path, "apply", "-input=false", "-auto-approve")
-	cmd.Dir = terraformConfigPath
+	cmd := c.command(dir, "apply", "-input=false", "-auto-approve")
 
-	cmd.Stderr = os.Stderr
 	cmd.Stdout = os.Stdout
 
-	return cmd.Run()
+	return runLocked(cmd)
+}
+
+// Plan runs terraform plan for a given config and returns the proposed changes
+func (c *CLI) Plan(config InputVars) (Plan, error) {
+	dir, err := c.init(config)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	defer dir.release()
+
+	stdoutBuffer := bytes.NewBuffer(nil)
+	cmd := c.command(dir, "plan", "-input=false", "-no-color")
+	cmd.Stdout = stdoutBuffer
+	if err = runLocked(cmd); err != nil {
+		return Plan{}, err
+	}
+
+	return parsePlan(stdoutBuffer.String()), nil
 }
 
 // Destroy destroys terraform resources specified in a config file
 func (c *CLI) Destroy(config InputVars) error {
-	terraformConfigPath, err := c.init(config)
+	dir, err := c.init(config)
 	if err != nil {
 		return err
 	}
 
-	defer os.RemoveAll(terraformConfigPath)
+	defer dir.release()
 
-	cmd := c.execCmd(c.Path, "destroy", "-auto-approve")
-	cmd.Dir = terraformConfigPath
+	cmd := c.command(dir, "destroy", "-auto-approve")
+	cmd.Stdout = os.Stdout
+	if err = runLocked(cmd); err != nil {
+		return err
+	}
+	// The deployment is gone, so its working directory will not be used again
+	return os.RemoveAll(dir.path)
+}
+
+// ForceUnlock releases the lock on the terraform state with the ID lockID, left behind by a run which did not finish
+func (c *CLI) ForceUnlock(config InputVars, lockID string) error {
+	dir, err := c.init(config)
+	if err != nil {
+		return err
+	}
+
+	defer dir.release()
+
+	cmd := c.command(dir, "force-unlock", "-force", lockID)
 	cmd.Stderr = os.Stderr
 	cmd.Stdout = os.Stdout
 	return cmd.Run()
 }
 
+// runLocked runs a terraform command which locks the state, returning a *LockError if the state was locked by another run
+func runLocked(cmd *exec.Cmd) error {
+	var stderr bytes.Buffer
+	cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
+	err := cmd.Run()
+	if err != nil {
+		if lockErr := parseLockError(stderr.String()); lockErr != nil {
+			return lockErr
+		}
+	}
+	return err
+}
+
 // BuildOutput builds the terraform output
 func (c *CLI) BuildOutput(config InputVars) (Outputs, error) {
-	terraformConfigPath, err := c.init(config)
+	dir, err := c.init(config)
 	if err != nil {
 		return nil, err
 	}
 
-	defer os.RemoveAll(terraformConfigPath)
+	defer dir.release()
 
 	stdoutBuffer := bytes.NewBuffer(nil)
-	cmd := c.execCmd(c.Path, "output", "-json")
-	cmd.Dir = terraformConfigPath
+	cmd := c.command(dir, "output", "-json")
 	cmd.Stderr = os.Stderr
 	cmd.Stdout = stdoutBuffer
 	if err = cmd.Run(); err != nil {
@@ -2,7 +2,10 @@ package terraform_test
 
 import (
 	"bytes"
+	"fmt"
 	"github.com/EngineerBetter/concourse-up/iaas"
+	"os"
+	"strconv"
 	"testing"
 
 	"github.com/EngineerBetter/concourse-up/internal/fakeexec"
@@ -10,6 +13,15 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+func TestExecCommandHelper(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	fmt.Print(os.Getenv("STDOUT"))
+	i, _ := strconv.Atoi(os.Getenv("EXIT_STATUS"))
+	os.Exit(i)
+}
+
 type mockTerraformInputVars struct{}
 type mockOutputs struct{}
 
@@ -30,6 +42,10 @@ func (mockInputVars *mockTerraformInputVars) ConfigureTerraform(terraformContent
 func (mockInputVars *mockTerraformInputVars) Build(data map[string]interface{}) error {
 	return nil
 }
+
+func (mockInputVars *mockTerraformInputVars) DeploymentKey() string {
+	return ""
+}
 func TestCLI_Apply(t *testing.T) {
 	e := fakeexec.New(t)
 	defer e.Finish()
@@ -75,6 +91,26 @@ func TestCLI_ApplyPlan(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestCLI_ForceUnlock(t *testing.T) {
+	e := fakeexec.New(t)
+	defer e.Finish()
+	mockCLIent, err := terraform.New(iaas.AWS, terraform.FakeExec(e.Cmd()))
+	require.NoError(t, err)
+
+	config := &mockTerraformInputVars{}
+
+	e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+		require.Equal(t, "terraform", command)
+		require.Equal(t, args[0], "init")
+	})
+	e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+		require.Equal(t, "terraform", command)
+		require.Equal(t, []string{"force-unlock", "-force", "a-lock-id"}, args)
+	})
+	err = mockCLIent.ForceUnlock(config, "a-lock-id")
+	require.NoError(t, err)
+}
+
 func TestCLI_Destroy(t *testing.T) {
 	e := fakeexec.New(t)
 	defer e.Finish()
@@ -97,3 +133,102 @@ func TestCLI_Destroy(t *testing.T) {
 	err = mockCLIent.Destroy(config)
 	require.NoError(t, err)
 }
+
+const terraform011PlanOutput = `Refreshing Terraform state in-memory prior to plan...
+
+------------------------------------------------------------------------
+
+An execution plan has been generated and is shown below.
+Resource actions are indicated with the following symbols:
+  + create
+  ~ update in-place
+-/+ destroy and then create replacement
+
+Terraform will perform the following actions:
+
+  ~ aws_db_instance.default
+      instance_class: "db.t2.small" => "db.t2.medium"
+
+-/+ aws_eip.director (new resource required)
+      id:             "eipalloc-123" => <computed> (forces new resource)
+
+  + aws_route53_record.concourse
+      id:             <computed>
+
+  - aws_security_group.rds
+
+
+Plan: 2 to add, 1 to change, 2 to destroy.
+`
+
+const terraform012PlanOutput = `An execution plan has been generated and is shown below.
+
+Terraform will perform the following actions:
+
+  # aws_db_instance.default will be updated in-place
+  ~ resource "aws_db_instance" "default" {
+      ~ instance_class = "db.t2.small" -> "db.t2.medium"
+    }
+
+  # aws_eip.director must be replaced
+-/+ resource "aws_eip" "director" {
+    }
+
+Plan: 1 to add, 1 to change, 1 to destroy.
+`
+
+func TestCLI_Plan(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []terraform.Change
+	}{
+		{
+			name:   "terraform 0.11",
+			output: terraform011PlanOutput,
+			want: []terraform.Change{
+				{Action: "update", Address: "aws_db_instance.default"},
+				{Action: "replace", Address: "aws_eip.director"},
+				{Action: "create", Address: "aws_route53_record.concourse"},
+				{Action: "destroy", Address: "aws_security_group.rds"},
+			},
+		},
+		{
+			name:   "terraform 0.12",
+			output: terraform012PlanOutput,
+			want: []terraform.Change{
+				{Action: "update", Address: "aws_db_instance.default"},
+				{Action: "replace", Address: "aws_eip.director"},
+			},
+		},
+		{
+			name:   "no changes",
+			output: "No changes. Infrastructure is up-to-date.\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := fakeexec.New(t)
+			defer e.Finish()
+			mockCLIent, err := terraform.New(iaas.AWS, terraform.FakeExec(e.Cmd()))
+			require.NoError(t, err)
+
+			config := &mockTerraformInputVars{}
+
+			e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+				require.Equal(t, "terraform", command)
+				require.Equal(t, args[0], "init")
+			})
+			e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+				require.Equal(t, "terraform", command)
+				require.Equal(t, args[0], "plan")
+				require.Equal(t, args[1], "-input=false")
+			}).Outputs(tt.output)
+
+			plan, err := mockCLIent.Plan(config)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, plan.Changes)
+			require.Equal(t, len(tt.want) > 0, plan.HasChanges())
+		})
+	}
+}
@@ -32,6 +32,19 @@ type FakeCLIInterface struct {
 		result1 terraform.Outputs
 		result2 error
 	}
+	CreateLockTableStub        func(terraform.InputVars) (string, error)
+	createLockTableMutex       sync.RWMutex
+	createLockTableArgsForCall []struct {
+		arg1 terraform.InputVars
+	}
+	createLockTableReturns struct {
+		result1 string
+		result2 error
+	}
+	createLockTableReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
 	DestroyStub        func(terraform.InputVars) error
 	destroyMutex       sync.RWMutex
 	destroyArgsForCall []struct {
@@ -43,6 +56,31 @@ type FakeCLIInterface struct {
 	destroyReturnsOnCall map[int]struct {
 		result1 error
 	}
+	ForceUnlockStub        func(terraform.InputVars, string) error
+	forceUnlockMutex       sync.RWMutex
+	forceUnlockArgsForCall []struct {
+		arg1 terraform.InputVars
+		arg2 string
+	}
+	forceUnlockReturns struct {
+		result1 error
+	}
+	forceUnlockReturnsOnCall map[int]struct {
+		result1 error
+	}
+	PlanStub        func(terraform.InputVars) (terraform.Plan, error)
+	planMutex       sync.RWMutex
+	planArgsForCall []struct {
+		arg1 terraform.InputVars
+	}
+	planReturns struct {
+		result1 terraform.Plan
+		result2 error
+	}
+	planReturnsOnCall map[int]struct {
+		result1 terraform.Plan
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -170,6 +208,69 @@ func (fake *FakeCLIInterface) BuildOutputReturnsOnCall(i int, result1 terraform.
 	}{result1, result2}
 }
 
+func (fake *FakeCLIInterface) CreateLockTable(arg1 terraform.InputVars) (string, error) {
+	fake.createLockTableMutex.Lock()
+	ret, specificReturn := fake.createLockTableReturnsOnCall[len(fake.createLockTableArgsForCall)]
+	fake.createLockTableArgsForCall = append(fake.createLockTableArgsForCall, struct {
+		arg1 terraform.InputVars
+	}{arg1})
+	fake.recordInvocation("CreateLockTable", []interface{}{arg1})
+	fake.createLockTableMutex.Unlock()
+	if fake.CreateLockTableStub != nil {
+		return fake.CreateLockTableStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.createLockTableReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCLIInterface) CreateLockTableCallCount() int {
+	fake.createLockTableMutex.RLock()
+	defer fake.createLockTableMutex.RUnlock()
+	return len(fake.createLockTableArgsForCall)
+}
+
+func (fake *FakeCLIInterface) CreateLockTableCalls(stub func(terraform.InputVars) (string, error)) {
+	fake.createLockTableMutex.Lock()
+	defer fake.createLockTableMutex.Unlock()
+	fake.CreateLockTableStub = stub
+}
+
+func (fake *FakeCLIInterface) CreateLockTableArgsForCall(i int) terraform.InputVars {
+	fake.createLockTableMutex.RLock()
+	defer fake.createLockTableMutex.RUnlock()
+	argsForCall := fake.createLockTableArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCLIInterface) CreateLockTableReturns(result1 string, result2 error) {
+	fake.createLockTableMutex.Lock()
+	defer fake.createLockTableMutex.Unlock()
+	fake.CreateLockTableStub = nil
+	fake.createLockTableReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCLIInterface) CreateLockTableReturnsOnCall(i int, result1 string, result2 error) {
+	fake.createLockTableMutex.Lock()
+	defer fake.createLockTableMutex.Unlock()
+	fake.CreateLockTableStub = nil
+	if fake.createLockTableReturnsOnCall == nil {
+		fake.createLockTableReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.createLockTableReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeCLIInterface) Destroy(arg1 terraform.InputVars) error {
 	fake.destroyMutex.Lock()
 	ret, specificReturn := fake.destroyReturnsOnCall[len(fake.destroyArgsForCall)]
@@ -230,6 +331,130 @@ func (fake *FakeCLIInterface) DestroyReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeCLIInterface) ForceUnlock(arg1 terraform.InputVars, arg2 string) error {
+	fake.forceUnlockMutex.Lock()
+	ret, specificReturn := fake.forceUnlockReturnsOnCall[len(fake.forceUnlockArgsForCall)]
+	fake.forceUnlockArgsForCall = append(fake.forceUnlockArgsForCall, struct {
+		arg1 terraform.InputVars
+		arg2 string
+	}{arg1, arg2})
+	fake.recordInvocation("ForceUnlock", []interface{}{arg1, arg2})
+	fake.forceUnlockMutex.Unlock()
+	if fake.ForceUnlockStub != nil {
+		return fake.ForceUnlockStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.forceUnlockReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeCLIInterface) ForceUnlockCallCount() int {
+	fake.forceUnlockMutex.RLock()
+	defer fake.forceUnlockMutex.RUnlock()
+	return len(fake.forceUnlockArgsForCall)
+}
+
+func (fake *FakeCLIInterface) ForceUnlockCalls(stub func(terraform.InputVars, string) error) {
+	fake.forceUnlockMutex.Lock()
+	defer fake.forceUnlockMutex.Unlock()
+	fake.ForceUnlockStub = stub
+}
+
+func (fake *FakeCLIInterface) ForceUnlockArgsForCall(i int) (terraform.InputVars, string) {
+	fake.forceUnlockMutex.RLock()
+	defer fake.forceUnlockMutex.RUnlock()
+	argsForCall := fake.forceUnlockArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCLIInterface) ForceUnlockReturns(result1 error) {
+	fake.forceUnlockMutex.Lock()
+	defer fake.forceUnlockMutex.Unlock()
+	fake.ForceUnlockStub = nil
+	fake.forceUnlockReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCLIInterface) ForceUnlockReturnsOnCall(i int, result1 error) {
+	fake.forceUnlockMutex.Lock()
+	defer fake.forceUnlockMutex.Unlock()
+	fake.ForceUnlockStub = nil
+	if fake.forceUnlockReturnsOnCall == nil {
+		fake.forceUnlockReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.forceUnlockReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCLIInterface) Plan(arg1 terraform.InputVars) (terraform.Plan, error) {
+	fake.planMutex.Lock()
+	ret, specificReturn := fake.planReturnsOnCall[len(fake.planArgsForCall)]
+	fake.planArgsForCall = append(fake.planArgsForCall, struct {
+		arg1 terraform.InputVars
+	}{arg1})
+	fake.recordInvocation("Plan", []interface{}{arg1})
+	fake.planMutex.Unlock()
+	if fake.PlanStub != nil {
+		return fake.PlanStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.planReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCLIInterface) PlanCallCount() int {
+	fake.planMutex.RLock()
+	defer fake.planMutex.RUnlock()
+	return len(fake.planArgsForCall)
+}
+
+func (fake *FakeCLIInterface) PlanCalls(stub func(terraform.InputVars) (terraform.Plan, error)) {
+	fake.planMutex.Lock()
+	defer fake.planMutex.Unlock()
+	fake.PlanStub = stub
+}
+
+func (fake *FakeCLIInterface) PlanArgsForCall(i int) terraform.InputVars {
+	fake.planMutex.RLock()
+	defer fake.planMutex.RUnlock()
+	argsForCall := fake.planArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCLIInterface) PlanReturns(result1 terraform.Plan, result2 error) {
+	fake.planMutex.Lock()
+	defer fake.planMutex.Unlock()
+	fake.PlanStub = nil
+	fake.planReturns = struct {
+		result1 terraform.Plan
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCLIInterface) PlanReturnsOnCall(i int, result1 terraform.Plan, result2 error) {
+	fake.planMutex.Lock()
+	defer fake.planMutex.Unlock()
+	fake.PlanStub = nil
+	if fake.planReturnsOnCall == nil {
+		fake.planReturnsOnCall = make(map[int]struct {
+			result1 terraform.Plan
+			result2 error
+		})
+	}
+	fake.planReturnsOnCall[i] = struct {
+		result1 terraform.Plan
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeCLIInterface) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -237,8 +462,14 @@ func (fake *FakeCLIInterface) Invocations() map[string][][]interface{} {
 	defer fake.applyMutex.RUnlock()
 	fake.buildOutputMutex.RLock()
 	defer fake.buildOutputMutex.RUnlock()
+	fake.createLockTableMutex.RLock()
+	defer fake.createLockTableMutex.RUnlock()
 	fake.destroyMutex.RLock()
 	defer fake.destroyMutex.RUnlock()
+	fake.forceUnlockMutex.RLock()
+	defer fake.forceUnlockMutex.RUnlock()
+	fake.planMutex.RLock()
+	defer fake.planMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
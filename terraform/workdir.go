@@ -0,0 +1,150 @@
+package terraform
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// workingDirFormat is part of the hash of each working directory, and is changed when the directories initialised
+// by earlier versions of concourse-up must be initialised again. Version 2 stopped passing backend credentials to
+// terraform init, which saved them under .terraform
+const workingDirFormat = "2"
+
+const (
+	workingDirConfigFilename = "infrastructure.tf"
+	configHashFilename       = ".config-hash"
+	lockFilename             = ".lock"
+)
+
+// workingDir is a directory terraform is run in
+type workingDir struct {
+	path string
+	// hash identifies the config and terraform binary the directory was initialised with
+	hash string
+	// temporary is set for directories which are removed after each run
+	temporary bool
+	// lock is held on a deployment's directory until it is released
+	lock *os.File
+	// env passes the secrets and backend credentials to terraform, which are not written to the directory
+	env []string
+}
+
+// release unlocks the directory, and deletes it if it is temporary
+func (w workingDir) release() {
+	if w.lock != nil {
+		w.lock.Close()
+	}
+	if w.temporary {
+		os.RemoveAll(w.path)
+	}
+}
+
+// markInitialised records that terraform init has been run with the directory's config
+func (w workingDir) markInitialised() error {
+	if w.temporary {
+		return nil
+	}
+	return ioutil.WriteFile(filepath.Join(w.path, configHashFilename), []byte(w.hash), 0600)
+}
+
+// prepareWorkingDir writes tfConfig to the deployment's working directory under the user cache dir, and returns
+// true if terraform was already initialised there with the same config and terraform binary, so init can be skipped.
+// Input vars which do not name a deployment get a temporary directory, as before.
+func prepareWorkingDir(config InputVars, tfConfig []byte, terraformPath string) (workingDir, bool, error) {
+	key := config.DeploymentKey()
+	if key == "" {
+		path, err := writeTempFile(tfConfig)
+		return workingDir{path: path, temporary: true}, false, err
+	}
+
+	path, err := cacheDir(filepath.Join("deployments", key))
+	if err != nil {
+		return workingDir{}, false, err
+	}
+	lock, err := lockDir(path)
+	if err != nil {
+		return workingDir{}, false, err
+	}
+	sum := sha256.Sum256(append([]byte(workingDirFormat+"\n"+terraformPath+"\n"), tfConfig...))
+	dir := workingDir{path: path, hash: hex.EncodeToString(sum[:]), lock: lock}
+
+	hashPath := filepath.Join(path, configHashFilename)
+	previous, err := ioutil.ReadFile(hashPath)
+	if err == nil && string(previous) == dir.hash {
+		if _, err = os.Stat(filepath.Join(path, ".terraform")); err == nil {
+			return dir, true, nil
+		}
+	}
+
+	// The hash is removed first so that an init which does not finish is run again next time
+	if err = os.Remove(hashPath); err != nil && !os.IsNotExist(err) {
+		dir.release()
+		return workingDir{}, false, err
+	}
+	if err = ioutil.WriteFile(filepath.Join(path, workingDirConfigFilename), tfConfig, 0600); err != nil {
+		dir.release()
+		return workingDir{}, false, err
+	}
+	return dir, false, nil
+}
+
+// lockDir takes an exclusive lock on the directory at path, so that concourse-up runs on this machine for the same
+// deployment do not use it at the same time. It waits for a run which holds the lock to release it
+func lockDir(path string) (*os.File, error) {
+	f, err := os.OpenFile(filepath.Join(path, lockFilename), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		fmt.Fprintf(os.Stderr, "Waiting for another concourse-up run to finish with %s\n", path)
+		err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+	}
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("error locking %s: [%v]", path, err)
+	}
+	return f, nil
+}
+
+// secretEnv returns the TF_VAR_ environment variables which pass the secrets of config to terraform
+func secretEnv(config InputVars) []string {
+	vars, ok := config.(SecretInputVars)
+	if !ok {
+		return nil
+	}
+	secrets := vars.SecretVars()
+	var env []string
+	for _, name := range sortedKeys(secrets) {
+		env = append(env, fmt.Sprintf("TF_VAR_%s=%s", name, secrets[name]))
+	}
+	return env
+}
+
+// cacheDir returns the directory name under the user cache dir where concourse-up keeps terraform files, creating it if needed
+func cacheDir(name string) (string, error) {
+	path, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	path = filepath.Join(path, "concourse-up", "terraform", name)
+	return path, os.MkdirAll(path, 0700)
+}
+
+// pluginCacheEnv returns the environment for a terraform command which shares downloaded providers between deployments,
+// or env unchanged if there is no cache dir
+func pluginCacheEnv(env []string) []string {
+	if env == nil {
+		env = os.Environ()
+	}
+	path, err := cacheDir("plugins")
+	if err != nil {
+		return env
+	}
+	return append(env, "TF_PLUGIN_CACHE_DIR="+path)
+}
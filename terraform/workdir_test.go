@@ -0,0 +1,86 @@
+package terraform
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrepareWorkingDir(t *testing.T) {
+	cache, err := ioutil.TempDir("", "concourse-up-cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(cache)
+	defer os.Setenv("XDG_CACHE_HOME", os.Getenv("XDG_CACHE_HOME"))
+	os.Setenv("XDG_CACHE_HOME", cache)
+
+	vars := &AWSInputVars{ConfigBucket: "concourse-up-test-eu-west-1-config"}
+	dir, initialised, err := prepareWorkingDir(vars, []byte("config"), "terraform")
+	require.NoError(t, err)
+	require.False(t, initialised)
+	require.False(t, dir.temporary)
+	require.Equal(t, filepath.Join(cache, "concourse-up", "terraform", "deployments", vars.ConfigBucket), dir.path)
+
+	// Stands in for terraform init
+	require.NoError(t, os.Mkdir(filepath.Join(dir.path, ".terraform"), 0700))
+	require.NoError(t, dir.markInitialised())
+	dir.release()
+
+	dir, initialised, err = prepareWorkingDir(vars, []byte("config"), "terraform")
+	require.NoError(t, err)
+	require.True(t, initialised)
+	dir.release()
+
+	dir, initialised, err = prepareWorkingDir(vars, []byte("changed config"), "terraform")
+	require.NoError(t, err)
+	require.False(t, initialised)
+	dir.release()
+	contents, err := ioutil.ReadFile(filepath.Join(dir.path, workingDirConfigFilename))
+	require.NoError(t, err)
+	require.Equal(t, "changed config", string(contents))
+
+	dir, _, err = prepareWorkingDir(&NullInputVars{}, []byte("config"), "terraform")
+	require.NoError(t, err)
+	require.True(t, dir.temporary)
+	dir.release()
+}
+
+func TestPrepareWorkingDir_Lock(t *testing.T) {
+	cache, err := ioutil.TempDir("", "concourse-up-cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(cache)
+	defer os.Setenv("XDG_CACHE_HOME", os.Getenv("XDG_CACHE_HOME"))
+	os.Setenv("XDG_CACHE_HOME", cache)
+
+	vars := &AWSInputVars{ConfigBucket: "concourse-up-test-eu-west-1-config"}
+	dir, _, err := prepareWorkingDir(vars, []byte("config"), "terraform")
+	require.NoError(t, err)
+
+	prepared := make(chan workingDir)
+	go func() {
+		other, _, err := prepareWorkingDir(vars, []byte("config"), "terraform")
+		require.NoError(t, err)
+		prepared <- other
+	}()
+	select {
+	case <-prepared:
+		t.Fatal("prepareWorkingDir() did not wait for the directory to be released")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	dir.release()
+	select {
+	case other := <-prepared:
+		other.release()
+	case <-time.After(5 * time.Second):
+		t.Fatal("prepareWorkingDir() did not take the lock once the directory was released")
+	}
+}
+
+func TestSecretEnv(t *testing.T) {
+	require.Equal(t, []string{"TF_VAR_rds_instance_password=s3cret"}, secretEnv(&AWSInputVars{RDSPassword: "s3cret"}))
+	require.Empty(t, secretEnv(&NullInputVars{}))
+}
@@ -36,3 +36,31 @@ func SetupFakeCredsForGCPProvider(t *testing.T) string {
 	}
 	return filePath.Name()
 }
+
+// SetupFakeCredsForAzureProvider sets the environment variables holding the credentials of an Azure service principal
+func SetupFakeCredsForAzureProvider(t *testing.T) {
+	for name, value := range map[string]string{
+		"ARM_SUBSCRIPTION_ID": "fake-subscription",
+		"ARM_TENANT_ID":       "fake-tenant",
+		"ARM_CLIENT_ID":       "fake-client",
+		"ARM_CLIENT_SECRET":   "fake-secret",
+	} {
+		if err := os.Setenv(name, value); err != nil {
+			t.Errorf("cannot set %v", err)
+		}
+	}
+}
+
+// SetupFakeCredsForOpenStackProvider sets the environment variables holding the credentials of an OpenStack user
+func SetupFakeCredsForOpenStackProvider(t *testing.T) {
+	for name, value := range map[string]string{
+		"OS_AUTH_URL":     "https://keystone.example.com:5000/v3",
+		"OS_USERNAME":     "fake-user",
+		"OS_PASSWORD":     "fake-password",
+		"OS_PROJECT_NAME": "fake-project",
+	} {
+		if err := os.Setenv(name, value); err != nil {
+			t.Errorf("cannot set %v", err)
+		}
+	}
+}